@@ -7,21 +7,49 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Server   ServerConfig
-	SSO      SSOConfig
-	Storage  StorageConfig
-	Security SecurityConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Server    ServerConfig
+	SSO       SSOConfig
+	Storage   StorageConfig
+	Security  SecurityConfig
+	SAML      SAMLConfig
+	RateLimit RateLimitConfig
+	Privacy   PrivacyConfig
+}
+
+// PrivacyConfig exports et effacements de données personnelles (RGPD)
+type PrivacyConfig struct {
+	ExportDir       string        // Répertoire des archives d'export
+	ExportRetention time.Duration // Durée de mise à disposition d'une archive
+}
+
+// RateLimitConfig limitation du nombre de requêtes (politiques configurables depuis l'administration)
+type RateLimitConfig struct {
+	Enabled bool
+	Store   string // postgres (partagé entre instances) ou memory (instance unique)
+}
+
+type SAMLConfig struct {
+	CertFile string // Certificat X.509 du service provider (PEM) - généré et stocké en base si vide
+	KeyFile  string // Clé privée du service provider (PEM)
 }
 
 type SecurityConfig struct {
-	BcryptCost int // Coût de hashage bcrypt (recommandé: 12 ou plus)
+	BcryptCost            int    // Coût de hashage bcrypt (recommandé: 12 ou plus)
+	BreachedPasswordsPath string // Liste hors ligne de mots de passe compromis (répertoire de plages SHA-1 ou fichier d'empreintes)
+
+	// Verrouillage après échecs de connexion (persisté en base)
+	LoginMaxAttemptsAccount int           // Échecs tolérés par compte dans la fenêtre
+	LoginMaxAttemptsIP      int           // Échecs tolérés par adresse IP dans la fenêtre
+	LoginAttemptWindow      time.Duration // Fenêtre de comptage des échecs
+	LoginLockoutDuration    time.Duration // Durée du verrouillage
 }
 
 type DatabaseConfig struct {
@@ -45,6 +73,10 @@ type ServerConfig struct {
 	Origins       []string
 	PublicURL     string // URL publique de l'application (ex: https://tools.marocpme.gov.ma)
 	SignupEnabled bool   // Activer/désactiver l'inscription
+
+	TrustedProxies []string // CIDR des reverse proxies autorisés à fournir X-Forwarded-For
+	TLSCertFile    string   // Certificat TLS du serveur (HTTPS direct, requis pour le mTLS)
+	TLSKeyFile     string   // Clé privée TLS du serveur
 }
 
 type SSOConfig struct {
@@ -54,6 +86,47 @@ type SSOConfig struct {
 	DefaultGroup  string
 	GroupMapping  map[string]string // map[AuthentikGroup]AirboardGroup
 	AdminGroups   []string          // Groupes Authentik qui ont le rôle admin
+
+	// Sources autorisées à transmettre les headers d'identité (forward-auth)
+	TrustedSources     []string // CIDR des proxies d'authentification (adresse du pair TCP)
+	SharedSecret       string   // Secret partagé attendu dans SharedSecretHeader (optionnel)
+	SharedSecretHeader string
+	ClientCAFile       string   // CA des certificats clients (mTLS, nécessite TLSCertFile/TLSKeyFile)
+	ClientCertNames    []string // CN/SAN autorisés pour le certificat client (vide = tout certificat valide)
+	Headers            SSOHeaderConfig
+
+	// SCIM reprend les comptes créés par le SSO (JIT) de même email ou externalId, hors administrateurs
+	SCIMAdoptUsers bool
+}
+
+// SSOHeaderConfig noms des headers d'identité transmis par le proxy d'authentification
+type SSOHeaderConfig struct {
+	Preset   string // authentik, oauth2-proxy, pomerium, traefik, custom
+	Email    string
+	Username string
+	Name     string
+	Groups   string
+	UID      string
+}
+
+// ssoHeaderPresets headers par défaut des proxies d'authentification courants
+var ssoHeaderPresets = map[string]SSOHeaderConfig{
+	"authentik": {
+		Email: "X-authentik-email", Username: "X-authentik-username", Name: "X-authentik-name",
+		Groups: "X-authentik-groups", UID: "X-authentik-uid",
+	},
+	"oauth2-proxy": {
+		Email: "X-Forwarded-Email", Username: "X-Forwarded-Preferred-Username", Name: "",
+		Groups: "X-Forwarded-Groups", UID: "X-Forwarded-User",
+	},
+	"pomerium": {
+		Email: "X-Pomerium-Claim-Email", Username: "X-Pomerium-Claim-Preferred-Username", Name: "X-Pomerium-Claim-Name",
+		Groups: "X-Pomerium-Claim-Groups", UID: "X-Pomerium-Claim-Sub",
+	},
+	"traefik": {
+		Email: "X-Forwarded-User", Username: "", Name: "",
+		Groups: "", UID: "",
+	},
 }
 
 type StorageConfig struct {
@@ -134,6 +207,35 @@ func LoadConfig() *Config {
 		}
 	}
 
+	// Proxies de confiance (X-Forwarded-For). Par défaut : localhost, plus les réseaux privés en production
+	trustedProxies := splitAndTrim(getEnv("TRUSTED_PROXIES", ""), ",")
+	if len(trustedProxies) == 0 {
+		trustedProxies = []string{"127.0.0.1/32", "::1/128"}
+		if getEnv("GIN_MODE", "debug") == "release" {
+			trustedProxies = append(trustedProxies, "172.16.0.0/12", "192.168.0.0/16", "10.0.0.0/8")
+		}
+	}
+
+	// Sources autorisées pour les headers SSO (par défaut : localhost et réseaux privés)
+	ssoTrustedSources := splitAndTrim(getEnv("SSO_TRUSTED_SOURCES",
+		"127.0.0.1/32,::1/128,172.16.0.0/12,192.168.0.0/16,10.0.0.0/8"), ",")
+
+	// Noms des headers SSO : preset puis surcharges individuelles
+	headerPreset := strings.ToLower(getEnv("SSO_HEADER_PRESET", "authentik"))
+	ssoHeaders, ok := ssoHeaderPresets[headerPreset]
+	if !ok {
+		ssoHeaders = ssoHeaderPresets["authentik"]
+		if headerPreset != "custom" {
+			log.Printf("⚠️ SSO_HEADER_PRESET=%s inconnu, utilisation des headers Authentik", headerPreset)
+		}
+	}
+	ssoHeaders.Preset = headerPreset
+	ssoHeaders.Email = getEnv("SSO_HEADER_EMAIL", ssoHeaders.Email)
+	ssoHeaders.Username = getEnv("SSO_HEADER_USERNAME", ssoHeaders.Username)
+	ssoHeaders.Name = getEnv("SSO_HEADER_NAME", ssoHeaders.Name)
+	ssoHeaders.Groups = getEnv("SSO_HEADER_GROUPS", ssoHeaders.Groups)
+	ssoHeaders.UID = getEnv("SSO_HEADER_UID", ssoHeaders.UID)
+
 	// Configuration Security - Bcrypt cost
 	bcryptCost, err := strconv.Atoi(getEnv("BCRYPT_COST", "12"))
 	if err != nil || bcryptCost < 10 || bcryptCost > 31 {
@@ -159,10 +261,13 @@ func LoadConfig() *Config {
 			RefreshExpirationDays: refreshExp,
 		},
 		Server: ServerConfig{
-			Port:          getEnv("PORT", "8080"),
-			Mode:          getEnv("GIN_MODE", "debug"),
-			PublicURL:     getEnv("PUBLIC_URL", "http://localhost:80"),
-			SignupEnabled: signupEnabled,
+			Port:           getEnv("PORT", "8080"),
+			Mode:           getEnv("GIN_MODE", "debug"),
+			PublicURL:      getEnv("PUBLIC_URL", "http://localhost:80"),
+			SignupEnabled:  signupEnabled,
+			TrustedProxies: trustedProxies,
+			TLSCertFile:    getEnv("SERVER_TLS_CERT_FILE", ""),
+			TLSKeyFile:     getEnv("SERVER_TLS_KEY_FILE", ""),
 			Origins: []string{
 				getEnv("FRONTEND_URL", "http://localhost:3000"),
 				"http://localhost:3001", // Vite dev server (fallback)
@@ -177,6 +282,15 @@ func LoadConfig() *Config {
 			DefaultGroup:  getEnv("SSO_DEFAULT_GROUP", "Common"),
 			GroupMapping:  make(map[string]string), // Sera peuplé par les groupes Authentik
 			AdminGroups:   adminGroups,
+
+			TrustedSources:     ssoTrustedSources,
+			SharedSecret:       getEnv("SSO_SHARED_SECRET", ""),
+			SharedSecretHeader: getEnv("SSO_SHARED_SECRET_HEADER", "X-Airboard-Proxy-Secret"),
+			ClientCAFile:       getEnv("SSO_CLIENT_CA_FILE", ""),
+			ClientCertNames:    splitAndTrim(getEnv("SSO_CLIENT_CERT_NAMES", ""), ","),
+			Headers:            ssoHeaders,
+
+			SCIMAdoptUsers: getEnv("SCIM_ADOPT_SSO_USERS", "true") == "true",
 		},
 		Storage: StorageConfig{
 			Type:        getEnv("STORAGE_TYPE", "local"),
@@ -189,7 +303,25 @@ func LoadConfig() *Config {
 			S3SecretKey: getEnv("S3_SECRET_KEY", ""),
 		},
 		Security: SecurityConfig{
-			BcryptCost: bcryptCost,
+			BcryptCost:            bcryptCost,
+			BreachedPasswordsPath: getEnv("BREACHED_PASSWORDS_PATH", ""),
+
+			LoginMaxAttemptsAccount: getEnvInt("LOGIN_MAX_ATTEMPTS_ACCOUNT", 5),
+			LoginMaxAttemptsIP:      getEnvInt("LOGIN_MAX_ATTEMPTS_IP", 20),
+			LoginAttemptWindow:      time.Duration(getEnvInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 15)) * time.Minute,
+			LoginLockoutDuration:    time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 30)) * time.Minute,
+		},
+		SAML: SAMLConfig{
+			CertFile: getEnv("SAML_SP_CERT_FILE", ""),
+			KeyFile:  getEnv("SAML_SP_KEY_FILE", ""),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: getEnv("RATE_LIMIT_ENABLED", "true") == "true",
+			Store:   strings.ToLower(getEnv("RATE_LIMIT_STORE", "postgres")),
+		},
+		Privacy: PrivacyConfig{
+			ExportDir:       getEnv("PRIVACY_EXPORT_DIR", "./data/exports"),
+			ExportRetention: time.Duration(getEnvInt("PRIVACY_EXPORT_RETENTION_HOURS", 72)) * time.Hour,
 		},
 	}
 }
@@ -213,6 +345,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt lit un entier strictement positif, valeur par défaut si absent ou invalide
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
 func splitAndTrim(s, sep string) []string {
 	var result []string
 	for _, item := range strings.Split(s, sep) {
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AcknowledgementHandler gère les contenus à lecture obligatoire : accusés de lecture, rapports de conformité et relances
+type AcknowledgementHandler struct {
+	db   *gorm.DB
+	acks *services.AcknowledgementService
+}
+
+// NewAcknowledgementHandler crée une nouvelle instance de AcknowledgementHandler
+func NewAcknowledgementHandler(db *gorm.DB, acks *services.AcknowledgementService) *AcknowledgementHandler {
+	return &AcknowledgementHandler{db: db, acks: acks}
+}
+
+// ackError traduit les erreurs du service d'accusés de lecture en réponse HTTP
+func ackError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrAckContentNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+			Code:    http.StatusNotFound,
+		})
+	case errors.Is(err, services.ErrAckNotInAudience):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: err.Error(),
+			Code:    http.StatusForbidden,
+		})
+	case errors.Is(err, services.ErrAckNotRequired), errors.Is(err, services.ErrAckReminderTooSoon):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+	default:
+		log.Printf("[Acknowledgement] %s: %v", fallback, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: fallback,
+			Code:    http.StatusInternalServerError,
+		})
+	}
+}
+
+func parseAckContentID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "ID invalide",
+			Code:    http.StatusBadRequest,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ============ UTILISATEUR ============
+
+// AcknowledgeNews enregistre l'accusé de lecture (« J'ai lu et compris ») d'un article par l'utilisateur connecté
+func (h *AcknowledgementHandler) AcknowledgeNews(c *gin.Context) {
+	h.acknowledge(c, models.AckEntityNews)
+}
+
+// AcknowledgeAnnouncement enregistre l'accusé de lecture d'une annonce par l'utilisateur connecté
+func (h *AcknowledgementHandler) AcknowledgeAnnouncement(c *gin.Context) {
+	h.acknowledge(c, models.AckEntityAnnouncement)
+}
+
+func (h *AcknowledgementHandler) acknowledge(c *gin.Context, entityType string) {
+	id, ok := parseAckContentID(c)
+	if !ok {
+		return
+	}
+	ack, err := h.acks.Acknowledge(entityType, id, c.GetUint("user_id"), c.ClientIP())
+	if err != nil {
+		ackError(c, err, "Erreur lors de l'enregistrement de l'accusé de lecture")
+		return
+	}
+	c.JSON(http.StatusOK, ack)
+}
+
+// GetPending retourne les contenus à lecture obligatoire en attente d'accusé de lecture de l'utilisateur connecté
+func (h *AcknowledgementHandler) GetPending(c *gin.Context) {
+	pending, err := h.acks.Pending(c.GetUint("user_id"))
+	if err != nil {
+		ackError(c, err, "Erreur lors du chargement des lectures obligatoires")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": pending})
+}
+
+// ============ CONFORMITÉ (ADMIN) ============
+
+// GetNewsReport retourne le rapport de conformité d'un article (status, group_id, page, page_size)
+func (h *AcknowledgementHandler) GetNewsReport(c *gin.Context) {
+	h.report(c, models.AckEntityNews)
+}
+
+// GetAnnouncementReport retourne le rapport de conformité d'une annonce (status, group_id, page, page_size)
+func (h *AcknowledgementHandler) GetAnnouncementReport(c *gin.Context) {
+	h.report(c, models.AckEntityAnnouncement)
+}
+
+// ExportNewsReport exporte au format CSV les utilisateurs ciblés par un article et leur accusé de lecture
+func (h *AcknowledgementHandler) ExportNewsReport(c *gin.Context) {
+	h.export(c, models.AckEntityNews)
+}
+
+// ExportAnnouncementReport exporte au format CSV les utilisateurs ciblés par une annonce et leur accusé de lecture
+func (h *AcknowledgementHandler) ExportAnnouncementReport(c *gin.Context) {
+	h.export(c, models.AckEntityAnnouncement)
+}
+
+// ackReportFilter lit les filtres du rapport de conformité
+func ackReportFilter(c *gin.Context) services.AckReportFilter {
+	filter := services.AckReportFilter{Status: c.Query("status")}
+	if groupID, err := strconv.ParseUint(c.Query("group_id"), 10, 64); err == nil {
+		id := uint(groupID)
+		filter.GroupID = &id
+	}
+	return filter
+}
+
+func (h *AcknowledgementHandler) report(c *gin.Context, entityType string) {
+	id, ok := parseAckContentID(c)
+	if !ok {
+		return
+	}
+	filter := ackReportFilter(c)
+	filter.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	filter.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(services.AckReportDefaultPageSize)))
+	if filter.PageSize < 1 || filter.PageSize > services.AckReportMaxPageSize {
+		filter.PageSize = services.AckReportDefaultPageSize
+	}
+
+	report, err := h.acks.Report(entityType, id, filter)
+	if err != nil {
+		ackError(c, err, "Erreur lors du chargement du rapport de lecture")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *AcknowledgementHandler) export(c *gin.Context, entityType string) {
+	id, ok := parseAckContentID(c)
+	if !ok {
+		return
+	}
+	report, err := h.acks.Report(entityType, id, ackReportFilter(c))
+	if err != nil {
+		ackError(c, err, "Erreur lors de l'export du rapport de lecture")
+		return
+	}
+
+	filename := fmt.Sprintf("acknowledgements-%s-%d-%s.csv", entityType, id, time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"user", "username", "email", "department", "groups", "status", "acknowledged_at", "ip_address"})
+	for _, user := range report.Users {
+		status, acknowledgedAt := services.AckStatusPending, ""
+		if user.AcknowledgedAt != nil {
+			status, acknowledgedAt = services.AckStatusAcknowledged, user.AcknowledgedAt.Format(time.RFC3339)
+		}
+		writer.Write([]string{
+			csvSafe(strings.TrimSpace(user.FirstName + " " + user.LastName)),
+			csvSafe(user.Username),
+			csvSafe(user.Email),
+			csvSafe(user.Department),
+			csvSafe(strings.Join(user.Groups, "; ")),
+			status,
+			acknowledgedAt,
+			user.IPAddress,
+		})
+	}
+	writer.Flush()
+}
+
+// RemindNews relance les utilisateurs ciblés n'ayant pas accusé lecture d'un article
+func (h *AcknowledgementHandler) RemindNews(c *gin.Context) {
+	h.remind(c, models.AckEntityNews)
+}
+
+// RemindAnnouncement relance les utilisateurs ciblés n'ayant pas accusé lecture d'une annonce
+func (h *AcknowledgementHandler) RemindAnnouncement(c *gin.Context) {
+	h.remind(c, models.AckEntityAnnouncement)
+}
+
+func (h *AcknowledgementHandler) remind(c *gin.Context, entityType string) {
+	id, ok := parseAckContentID(c)
+	if !ok {
+		return
+	}
+	result, err := h.acks.Remind(entityType, id)
+	if err != nil {
+		ackError(c, err, "Erreur lors de la relance")
+		return
+	}
+
+	targetType := "news"
+	if entityType == models.AckEntityAnnouncement {
+		targetType = "announcements"
+	}
+	middleware.SetAuditTarget(c, targetType, id, "")
+	middleware.SetAuditDetails(c, fmt.Sprintf("Relance lecture obligatoire: %d utilisateur(s) notifié(s)", result.Notified))
+	c.JSON(http.StatusOK, result)
+}
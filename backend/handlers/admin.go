@@ -19,13 +19,15 @@ type AdminHandler struct {
 	db                  *gorm.DB
 	bcryptCost          int
 	gamificationService *services.GamificationService
+	passwordPolicy      *services.PasswordPolicyService
 }
 
-func NewAdminHandler(db *gorm.DB, cfg *config.Config, gs *services.GamificationService) *AdminHandler {
+func NewAdminHandler(db *gorm.DB, cfg *config.Config, gs *services.GamificationService, passwordPolicy *services.PasswordPolicyService) *AdminHandler {
 	return &AdminHandler{
 		db:                  db,
 		bcryptCost:          cfg.Security.BcryptCost,
 		gamificationService: gs,
+		passwordPolicy:      passwordPolicy,
 	}
 }
 
@@ -54,8 +56,8 @@ func (h *AdminHandler) GetAppGroups(c *gin.Context) {
 	query := h.db.Model(&models.AppGroup{})
 
 	// Vérifier si l'utilisateur est admin d'au moins un groupe
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-	if len(managedGroupIDs) > 0 {
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
+	if !middleware.HasPermission(c, models.PermApplicationsManage) && len(managedGroupIDs) > 0 {
 		// Group admin voit les AppGroups des groupes qu'il administre
 		// (même logique que le dashboard pour la cohérence)
 		// Récupérer tous les AppGroups accessibles aux groupes administrés
@@ -125,8 +127,8 @@ func (h *AdminHandler) CreateAppGroup(c *gin.Context) {
 	}
 
 	// Pour les admins de groupe (utilisateurs qui administrent au moins un groupe), l'AppGroup est automatiquement privé et appartient au premier groupe administré
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-	isGroupAdmin := len(managedGroupIDs) > 0
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
+	isGroupAdmin := !middleware.HasPermission(c, models.PermApplicationsManage) && len(managedGroupIDs) > 0
 
 	if isGroupAdmin {
 		if len(managedGroupIDs) == 0 {
@@ -198,6 +200,9 @@ func (h *AdminHandler) UpdateAppGroup(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "app_groups", appGroup.ID, appGroup.Name)
+	middleware.AuditBefore(c, appGroup)
+
 	// Vérification des permissions pour les admins de groupe
 	// Un admin de groupe peut modifier un AppGroup seulement si c'est un AppGroup privé appartenant à son groupe
 	if !middleware.CanManageAppGroupWithDB(c, appGroup.ID, h.db) {
@@ -335,9 +340,8 @@ func (h *AdminHandler) GetApplications(c *gin.Context) {
 	}
 
 	// Filtrage selon le rôle pour les admins de groupe
-	role := c.GetString("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-	if role != "admin" && len(managedGroupIDs) > 0 {
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
+	if !middleware.HasPermission(c, models.PermApplicationsManage) && len(managedGroupIDs) > 0 {
 		// Admin de groupe voit les applications des AppGroups des groupes qu'il administre
 		// (même logique que le dashboard pour la cohérence)
 		if len(managedGroupIDs) > 0 {
@@ -477,6 +481,9 @@ func (h *AdminHandler) UpdateApplication(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "applications", application.ID, application.Name)
+	middleware.AuditBefore(c, application)
+
 	// Vérification des permissions pour les admins de groupe
 	// Un admin de groupe peut modifier une application seulement si elle est dans un AppGroup privé qu'il possède
 	if application.AppGroup == nil {
@@ -541,9 +548,8 @@ func (h *AdminHandler) DeleteApplication(c *gin.Context) {
 	}
 
 	// Vérification des permissions pour les admins de groupe
-	role := c.GetString("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-	if role != "admin" && len(managedGroupIDs) > 0 {
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
+	if !middleware.HasPermission(c, models.PermApplicationsManage) && len(managedGroupIDs) > 0 {
 		var application models.Application
 		if err := h.db.Preload("AppGroup").First(&application, id).Error; err != nil {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -554,6 +560,9 @@ func (h *AdminHandler) DeleteApplication(c *gin.Context) {
 			return
 		}
 
+		middleware.SetAuditTarget(c, "applications", application.ID, application.Name)
+		middleware.AuditBefore(c, application)
+
 		// Vérifier que l'application appartient à un AppGroup privé que l'admin de groupe possède
 		if application.AppGroup == nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -599,7 +608,8 @@ func (h *AdminHandler) DeleteApplication(c *gin.Context) {
 // @Router /admin/users [get]
 func (h *AdminHandler) GetUsers(c *gin.Context) {
 	var users []models.User
-	if err := h.db.Preload("Groups").Preload("AdminOfGroups").Find(&users).Error; err != nil {
+	// Les comptes de service sont gérés séparément (/admin/service-accounts)
+	if err := h.db.Preload("Groups").Preload("AdminOfGroups").Where("is_service_account = ?", false).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Erreur lors de la récupération des utilisateurs",
@@ -646,6 +656,16 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	// Seul un administrateur (toutes les permissions) peut créer un administrateur
+	if createData.Role == models.RoleAdmin && !middleware.HasPermission(c, models.PermAll) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Droits administrateur requis pour attribuer le rôle admin",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
 	// Vérifier si l'utilisateur existe déjà
 	var existingUser models.User
 	if err := h.db.Where("username = ? OR email = ?", createData.Username, createData.Email).First(&existingUser).Error; err == nil {
@@ -657,6 +677,12 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	// Valider le mot de passe selon la politique configurée
+	if err := h.passwordPolicy.Validate(createData.Password, nil); err != nil {
+		respondPasswordPolicyError(c, err)
+		return
+	}
+
 	// Hasher le mot de passe avec coût sécurisé (12 minimum - OWASP 2025)
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(createData.Password), h.bcryptCost)
 	if err != nil {
@@ -686,6 +712,9 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 		})
 		return
 	}
+	if err := h.passwordPolicy.RecordPassword(user.ID, user.Password); err != nil {
+		log.Printf("Erreur lors de l'enregistrement de l'historique des mots de passe: %v", err)
+	}
 
 	// Associer les groupes si fournis
 	if len(createData.GroupIDs) > 0 {
@@ -698,6 +727,8 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 	user.Password = ""
 	h.db.Preload("Groups").First(&user, user.ID)
 
+	go services.NewWebhookService(h.db).UserCreated(&user, "admin")
+
 	c.JSON(http.StatusCreated, user)
 }
 
@@ -732,6 +763,9 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "users", user.ID, user.Username)
+	middleware.AuditBefore(c, user)
+
 	var updateData struct {
 		Username  string `json:"username"`
 		Email     string `json:"email"`
@@ -752,6 +786,16 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	// Seul un administrateur (toutes les permissions) peut modifier un administrateur ou attribuer ce rôle
+	if (user.Role == models.RoleAdmin || updateData.Role == models.RoleAdmin) && !middleware.HasPermission(c, models.PermAll) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Droits administrateur requis pour modifier un administrateur",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
 	// Mise à jour des champs
 	if updateData.Username != "" {
 		user.Username = updateData.Username
@@ -774,6 +818,10 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 
 	// Hash du nouveau mot de passe si fourni avec coût sécurisé (12 minimum - OWASP 2025)
 	if updateData.Password != "" {
+		if err := h.passwordPolicy.Validate(updateData.Password, &user); err != nil {
+			respondPasswordPolicyError(c, err)
+			return
+		}
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updateData.Password), h.bcryptCost)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -794,6 +842,11 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		})
 		return
 	}
+	if updateData.Password != "" {
+		if err := h.passwordPolicy.RecordPassword(user.ID, user.Password); err != nil {
+			log.Printf("Erreur lors de l'enregistrement de l'historique des mots de passe: %v", err)
+		}
+	}
 
 	// Mise à jour des groupes si fournis
 	if updateData.GroupIDs != nil {
@@ -841,6 +894,9 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "users", user.ID, user.Username)
+	middleware.AuditBefore(c, user)
+
 	// Supprimer les associations avec les groupes
 	h.db.Model(&user).Association("Groups").Clear()
 
@@ -906,6 +962,9 @@ func (h *AdminHandler) RestoreUser(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "users", user.ID, user.Username)
+	middleware.AuditBefore(c, user)
+
 	// Vérifier qu'il est bien supprimé
 	if user.DeletedAt.Time.IsZero() {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -956,6 +1015,9 @@ func (h *AdminHandler) PermanentlyDeleteUser(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "users", user.ID, user.Username)
+	middleware.AuditBefore(c, user)
+
 	// Vérifier qu'il est bien supprimé
 	if user.DeletedAt.Time.IsZero() {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -1005,6 +1067,9 @@ func (h *AdminHandler) PermanentlyDeleteUser(c *gin.Context) {
 		if err := tx.Where("user_id = ?", user.ID).Delete(&models.PollVote{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.ContentAcknowledgement{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Where("user_id = ?", user.ID).Delete(&models.GamificationProfile{}).Error; err != nil {
 			return err
 		}
@@ -1014,6 +1079,12 @@ func (h *AdminHandler) PermanentlyDeleteUser(c *gin.Context) {
 		if err := tx.Where("user_id = ?", user.ID).Delete(&models.XPTransaction{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.PrivacyRequest{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("target_user_id = ? OR impersonator_id = ?", user.ID, user.ID).Delete(&models.ImpersonationSession{}).Error; err != nil {
+			return err
+		}
 
 		// 3. Nullifier les références d'auteur sur le contenu (préserver les articles/sondages)
 		if err := tx.Model(&models.News{}).Where("author_id = ?", user.ID).Update("author_id", nil).Error; err != nil {
@@ -1022,6 +1093,25 @@ func (h *AdminHandler) PermanentlyDeleteUser(c *gin.Context) {
 		if err := tx.Model(&models.Poll{}).Where("author_id = ?", user.ID).Update("author_id", nil).Error; err != nil {
 			return err
 		}
+		if err := tx.Model(&models.ContentRevision{}).Where("author_id = ?", user.ID).Update("author_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reviewer_id = ?", user.ID).Delete(&models.NewsReviewer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.NewsWorkflowEvent{}).Where("actor_id = ?", user.ID).Update("actor_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.FeedToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.ProfilePrivacy{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("manager_id = ?", user.ID).
+			Updates(map[string]interface{}{"manager_id": nil, "manager_source": ""}).Error; err != nil {
+			return err
+		}
 		if err := tx.Unscoped().Where("author_id = ?", user.ID).Delete(&models.Event{}).Error; err != nil {
 			return err
 		}
@@ -1231,6 +1321,9 @@ func (h *AdminHandler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "groups", group.ID, group.Name)
+	middleware.AuditBefore(c, group)
+
 	var updateData struct {
 		Name        string `json:"name"`
 		Description string `json:"description"`
@@ -1319,6 +1412,9 @@ func (h *AdminHandler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "groups", group.ID, group.Name)
+	middleware.AuditBefore(c, group)
+
 	// Supprimer les associations
 	h.db.Model(&group).Association("Users").Clear()
 	h.db.Model(&group).Association("AppGroups").Clear()
@@ -1434,6 +1530,18 @@ func (h *AdminHandler) ResetDatabase(c *gin.Context) {
 		"feedbacks",
 		"notifications",
 		"email_notification_logs",
+		"webhook_deliveries",
+		"password_histories",
+		"login_lockouts",
+		"login_devices",
+		"impersonation_sessions",
+		"privacy_requests",
+		"content_revisions",
+		"news_reviewers",
+		"news_workflow_events",
+		"feed_tokens",
+		"profile_privacies",
+		"content_acknowledgements",
 
 		// Tables avec relations
 		"poll_options",
@@ -1452,6 +1560,7 @@ func (h *AdminHandler) ResetDatabase(c *gin.Context) {
 		"smtp_configs",
 		"email_oauth_configs",
 		"oauth_providers",
+		"webhooks",
 
 		// Tables principales
 		"users",
@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"airboard/config"
+	"airboard/middleware"
 	"airboard/models"
 	"airboard/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreateEvent - Créer un événement (Admin/Editor)
@@ -21,6 +23,12 @@ func (h *EventsHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
+	// Vérifier le périmètre des groupes ciblés
+	if !middleware.CanTargetGroups(c, models.PermEventsCreate, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez cibler que les groupes autorisés"})
+		return
+	}
+
 	// Récupérer l'utilisateur connecté
 	userID := c.GetUint("user_id")
 
@@ -54,27 +62,43 @@ func (h *EventsHandler) CreateEvent(c *gin.Context) {
 		event.PublishedAt = &now
 	}
 
-	// Créer l'événement en BDD
-	if err := h.db.Create(&event).Error; err != nil {
+	// Créer l'événement, ses associations et sa première version dans la même transaction
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+
+		// Associer les tags
+		if len(req.TagIDs) > 0 {
+			var tags []models.Tag
+			if err := tx.Where("id IN ?", req.TagIDs).Find(&tags).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("Tags").Append(tags); err != nil {
+				return err
+			}
+		}
+
+		// Associer les groupes cibles
+		if len(req.TargetGroupIDs) > 0 {
+			var groups []models.Group
+			if err := tx.Where("id IN ?", req.TargetGroupIDs).Find(&groups).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("TargetGroups").Append(groups); err != nil {
+				return err
+			}
+		}
+
+		// Historique des versions
+		return h.recordRevision(tx, &event, userID, nil)
+	})
+	if err != nil {
 		log.Printf("[ERROR] CreateEvent DB Error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création de l'événement"})
 		return
 	}
 
-	// Associer les tags
-	if len(req.TagIDs) > 0 {
-		var tags []models.Tag
-		h.db.Where("id IN ?", req.TagIDs).Find(&tags)
-		h.db.Model(&event).Association("Tags").Append(tags)
-	}
-
-	// Associer les groupes cibles
-	if len(req.TargetGroupIDs) > 0 {
-		var groups []models.Group
-		h.db.Where("id IN ?", req.TargetGroupIDs).Find(&groups)
-		h.db.Model(&event).Association("TargetGroups").Append(groups)
-	}
-
 	// Recharger avec les relations
 	h.db.Preload("Author").
 		Preload("Category").
@@ -94,6 +118,9 @@ func (h *EventsHandler) CreateEvent(c *gin.Context) {
 				log.Printf("[Email] Échec de l'envoi de la notification événement: %v", err)
 			}
 		}()
+
+		// Webhooks sortants
+		go services.NewWebhookService(h.db).EventCreated(&event)
 	}
 
 	// Award Contributor XP
@@ -125,9 +152,8 @@ func (h *EventsHandler) UpdateEvent(c *gin.Context) {
 
 	// Vérification des permissions
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
 
-	if userRole != "admin" && event.AuthorID != userID {
+	if !middleware.HasPermission(c, models.PermEventsManage) && event.AuthorID != userID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez modifier que vos propres événements"})
 		return
 	}
@@ -168,26 +194,47 @@ func (h *EventsHandler) UpdateEvent(c *gin.Context) {
 		event.PublishedAt = &now
 	}
 
-	// Sauvegarder
-	if err := h.db.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour"})
-		return
-	}
+	// Sauvegarder l'événement, ses associations et la nouvelle version dans la même transaction
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&event).Error; err != nil {
+			return err
+		}
 
-	// Mettre à jour les tags
-	h.db.Model(&event).Association("Tags").Clear()
-	if len(req.TagIDs) > 0 {
-		var tags []models.Tag
-		h.db.Where("id IN ?", req.TagIDs).Find(&tags)
-		h.db.Model(&event).Association("Tags").Append(tags)
-	}
+		// Mettre à jour les tags
+		if err := tx.Model(&event).Association("Tags").Clear(); err != nil {
+			return err
+		}
+		if len(req.TagIDs) > 0 {
+			var tags []models.Tag
+			if err := tx.Where("id IN ?", req.TagIDs).Find(&tags).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("Tags").Append(tags); err != nil {
+				return err
+			}
+		}
 
-	// Mettre à jour les groupes cibles
-	h.db.Model(&event).Association("TargetGroups").Clear()
-	if len(req.TargetGroupIDs) > 0 {
-		var groups []models.Group
-		h.db.Where("id IN ?", req.TargetGroupIDs).Find(&groups)
-		h.db.Model(&event).Association("TargetGroups").Append(groups)
+		// Mettre à jour les groupes cibles
+		if err := tx.Model(&event).Association("TargetGroups").Clear(); err != nil {
+			return err
+		}
+		if len(req.TargetGroupIDs) > 0 {
+			var groups []models.Group
+			if err := tx.Where("id IN ?", req.TargetGroupIDs).Find(&groups).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("TargetGroups").Append(groups); err != nil {
+				return err
+			}
+		}
+
+		// Historique des versions
+		return h.recordRevision(tx, &event, userID, nil)
+	})
+	if err != nil {
+		log.Printf("[ERROR] UpdateEvent DB Error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour"})
+		return
 	}
 
 	// Recharger avec les relations
@@ -223,9 +270,8 @@ func (h *EventsHandler) DeleteEvent(c *gin.Context) {
 
 	// Vérification des permissions
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
 
-	if userRole != "admin" && event.AuthorID != userID {
+	if !middleware.HasPermission(c, models.PermEventsManage) && event.AuthorID != userID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez supprimer que vos propres événements"})
 		return
 	}
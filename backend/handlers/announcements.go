@@ -58,6 +58,26 @@ func (h *AnnouncementHandler) GetActiveAnnouncements(c *gin.Context) {
 		return
 	}
 
+	// Lecture obligatoire : dates des accusés de lecture de l'utilisateur
+	var ackIDs []uint
+	for _, announcement := range announcements {
+		if announcement.RequiresAck {
+			ackIDs = append(ackIDs, announcement.ID)
+		}
+	}
+	if len(ackIDs) > 0 {
+		var acks []models.ContentAcknowledgement
+		h.db.Where("entity_type = ? AND entity_id IN ? AND user_id = ?", models.AckEntityAnnouncement, ackIDs, c.GetUint("user_id")).Find(&acks)
+		for _, ack := range acks {
+			for i := range announcements {
+				if announcements[i].ID == ack.EntityID {
+					acknowledgedAt := ack.AcknowledgedAt
+					announcements[i].AcknowledgedAt = &acknowledgedAt
+				}
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, announcements)
 }
 
@@ -100,13 +120,15 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
 	}
 
 	announcement := models.Announcement{
-		Title:     req.Title,
-		Content:   req.Content,
-		Type:      req.Type,
-		Priority:  req.Priority,
-		IsActive:  req.IsActive,
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
+		Title:       req.Title,
+		Content:     req.Content,
+		Type:        req.Type,
+		Priority:    req.Priority,
+		IsActive:    req.IsActive,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		RequiresAck: req.RequiresAck,
+		AckDueAt:    req.AckDueAt,
 	}
 
 	if err := h.db.Create(&announcement).Error; err != nil {
@@ -127,6 +149,9 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
 				log.Printf("[Email] Échec de l'envoi de la notification annonce: %v", err)
 			}
 		}()
+
+		// Webhooks sortants
+		go services.NewWebhookService(h.db).AnnouncementCreated(&announcement)
 	}
 
 	c.JSON(http.StatusCreated, announcement)
@@ -172,6 +197,8 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
 	announcement.IsActive = req.IsActive
 	announcement.StartDate = req.StartDate
 	announcement.EndDate = req.EndDate
+	announcement.RequiresAck = req.RequiresAck
+	announcement.AckDueAt = req.AckDueAt
 
 	if err := h.db.Save(&announcement).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -0,0 +1,490 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Domaine des adresses email techniques attribuées aux comptes de service
+const serviceAccountEmailDomain = "service-account.invalid"
+
+// APITokenHandler gère les jetons d'accès personnels et les comptes de service
+type APITokenHandler struct {
+	db     *gorm.DB
+	tokens *services.APITokenService
+}
+
+// NewAPITokenHandler crée une nouvelle instance de APITokenHandler
+func NewAPITokenHandler(db *gorm.DB) *APITokenHandler {
+	return &APITokenHandler{
+		db:     db,
+		tokens: services.NewAPITokenService(db),
+	}
+}
+
+// ============ JETONS D'ACCÈS PERSONNELS ============
+
+// ListMyTokens retourne les jetons de l'utilisateur connecté
+func (h *APITokenHandler) ListMyTokens(c *gin.Context) {
+	var tokens []models.APIToken
+	if err := h.db.Where("user_id = ?", c.GetUint("user_id")).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des jetons",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateMyToken crée un jeton d'accès personnel limité aux permissions détenues par l'utilisateur
+func (h *APITokenHandler) CreateMyToken(c *gin.Context) {
+	var req models.APITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Les jetons personnels expirent obligatoirement
+	if req.ExpiresInDays == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Une durée de validité (expires_in_days) est requise",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	scopes, ok := h.validateScopes(c, req.Scopes)
+	if !ok {
+		return
+	}
+
+	// Un utilisateur ne peut déléguer que les permissions qu'il détient
+	for _, scope := range scopes {
+		if !middleware.HasAnyPermission(c, scope) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Vous ne disposez pas de la permission " + scope,
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+	}
+
+	userID := c.GetUint("user_id")
+	h.issue(c, userID, req, scopes)
+}
+
+// RevokeMyToken révoque un jeton de l'utilisateur connecté
+func (h *APITokenHandler) RevokeMyToken(c *gin.Context) {
+	var token models.APIToken
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), c.GetUint("user_id")).First(&token).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Jeton introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	h.revoke(c, &token)
+}
+
+// ============ ADMINISTRATION DES JETONS ============
+
+// ListAllTokens retourne tous les jetons (filtrables par user_id et état)
+func (h *APITokenHandler) ListAllTokens(c *gin.Context) {
+	query := h.db.Preload("User").Order("created_at DESC")
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if c.Query("active") == "true" {
+		query = query.Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())")
+	}
+
+	var tokens []models.APIToken
+	if err := query.Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des jetons",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	for i := range tokens {
+		if tokens[i].User != nil {
+			tokens[i].User.Password = ""
+		}
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeToken révoque n'importe quel jeton
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	var token models.APIToken
+	if err := h.db.First(&token, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Jeton introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	h.revoke(c, &token)
+}
+
+// ============ COMPTES DE SERVICE ============
+
+// ListServiceAccounts retourne les comptes de service avec leurs groupes
+func (h *APITokenHandler) ListServiceAccounts(c *gin.Context) {
+	var accounts []models.User
+	if err := h.db.Preload("Groups").Where("is_service_account = ?", true).Order("username ASC").Find(&accounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des comptes de service",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// CreateServiceAccount crée un compte technique sans mot de passe
+func (h *APITokenHandler) CreateServiceAccount(c *gin.Context) {
+	var req models.ServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if !h.checkRole(c, req.Role) {
+		return
+	}
+
+	username := strings.ToLower(strings.TrimSpace(req.Username))
+	var count int64
+	h.db.Unscoped().Model(&models.User{}).Where("username = ?", username).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "conflict",
+			Message: "Ce nom d'utilisateur est déjà utilisé",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	account := models.User{
+		Username:         username,
+		Email:            username + "@" + serviceAccountEmailDomain,
+		FirstName:        defaultString(req.DisplayName, username),
+		Role:             defaultString(req.Role, models.RoleUser),
+		IsActive:         req.IsActive == nil || *req.IsActive,
+		IsServiceAccount: true,
+		SSOProvider:      "service_account",
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&account).Error; err != nil {
+			return err
+		}
+		return h.replaceGroups(tx, &account, req.GroupIDs)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la création du compte de service",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	middleware.SetAuditTarget(c, "service_accounts", account.ID, account.Username)
+	c.JSON(http.StatusCreated, account)
+}
+
+// UpdateServiceAccount met à jour un compte de service
+func (h *APITokenHandler) UpdateServiceAccount(c *gin.Context) {
+	account, ok := h.findServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	var req models.ServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if !h.checkRole(c, account.Role) || !h.checkRole(c, req.Role) {
+		return
+	}
+
+	account.FirstName = defaultString(req.DisplayName, account.FirstName)
+	account.Role = defaultString(req.Role, account.Role)
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Groups").Save(account).Error; err != nil {
+			return err
+		}
+		if req.GroupIDs == nil {
+			return nil
+		}
+		return h.replaceGroups(tx, account, req.GroupIDs)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la mise à jour du compte de service",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// DeleteServiceAccount supprime un compte de service et révoque ses jetons
+func (h *APITokenHandler) DeleteServiceAccount(c *gin.Context) {
+	account, ok := h.findServiceAccount(c)
+	if !ok {
+		return
+	}
+	if !h.checkRole(c, account.Role) {
+		return
+	}
+
+	if err := h.tokens.RevokeAllForUser(account.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la révocation des jetons",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if err := h.db.Delete(account).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la suppression du compte de service",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Compte de service supprimé et jetons révoqués",
+	})
+}
+
+// ListServiceAccountTokens retourne les jetons d'un compte de service
+func (h *APITokenHandler) ListServiceAccountTokens(c *gin.Context) {
+	account, ok := h.findServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	var tokens []models.APIToken
+	if err := h.db.Where("user_id = ?", account.ID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des jetons",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateServiceAccountToken émet un jeton pour un compte de service
+func (h *APITokenHandler) CreateServiceAccountToken(c *gin.Context) {
+	account, ok := h.findServiceAccount(c)
+	if !ok {
+		return
+	}
+	if !h.checkRole(c, account.Role) {
+		return
+	}
+
+	var req models.APITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	scopes, ok := h.validateScopes(c, req.Scopes)
+	if !ok {
+		return
+	}
+
+	h.issue(c, account.ID, req, scopes)
+}
+
+// RevokeServiceAccountToken révoque un jeton d'un compte de service
+func (h *APITokenHandler) RevokeServiceAccountToken(c *gin.Context) {
+	account, ok := h.findServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	var token models.APIToken
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("tokenId"), account.ID).First(&token).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Jeton introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	h.revoke(c, &token)
+}
+
+// ============ HELPERS ============
+
+func (h *APITokenHandler) issue(c *gin.Context, ownerID uint, req models.APITokenRequest, scopes []string) {
+	plain, token, err := h.tokens.Issue(ownerID, req.Name, scopes, req.ExpiresInDays, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la création du jeton",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	middleware.SetAuditTarget(c, "api_tokens", token.ID, token.Name)
+	// Le corps de la réponse contient le jeton en clair : l'audit ne conserve que ses métadonnées
+	middleware.AuditAfter(c, token)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":   plain,
+		"details": token,
+		"message": "Conservez ce jeton : il ne sera plus affiché",
+	})
+}
+
+func (h *APITokenHandler) revoke(c *gin.Context, token *models.APIToken) {
+	middleware.SetAuditTarget(c, "api_tokens", token.ID, token.Name)
+	if err := h.tokens.Revoke(token); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la révocation du jeton",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Jeton révoqué",
+	})
+}
+
+// validateScopes vérifie que les scopes sont des permissions connues ; "*" est réservé aux administrateurs
+func (h *APITokenHandler) validateScopes(c *gin.Context, requested []string) ([]string, bool) {
+	scopes := make([]string, 0, len(requested))
+	seen := make(map[string]bool)
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		if !services.IsKnownPermission(scope) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "validation_error",
+				Message: "Permission inconnue: " + scope,
+				Code:    http.StatusBadRequest,
+			})
+			return nil, false
+		}
+		if scope == models.PermAll && !middleware.HasPermission(c, models.PermAll) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Droits administrateur requis pour un jeton sans restriction",
+				Code:    http.StatusForbidden,
+			})
+			return nil, false
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+
+	if len(scopes) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Au moins une permission est requise",
+			Code:    http.StatusBadRequest,
+		})
+		return nil, false
+	}
+	return scopes, true
+}
+
+// checkRole empêche un non-administrateur de gérer un compte de service administrateur
+func (h *APITokenHandler) checkRole(c *gin.Context, role string) bool {
+	if role == models.RoleAdmin && !middleware.HasPermission(c, models.PermAll) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Droits administrateur requis pour un compte de service administrateur",
+			Code:    http.StatusForbidden,
+		})
+		return false
+	}
+	return true
+}
+
+func (h *APITokenHandler) findServiceAccount(c *gin.Context) (*models.User, bool) {
+	var account models.User
+	if err := h.db.Preload("Groups").Where("id = ? AND is_service_account = ?", c.Param("id"), true).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Compte de service introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+
+	middleware.SetAuditTarget(c, "service_accounts", account.ID, account.Username)
+	middleware.AuditBefore(c, account)
+	return &account, true
+}
+
+func (h *APITokenHandler) replaceGroups(tx *gorm.DB, account *models.User, groupIDs []uint) error {
+	var groups []models.Group
+	if len(groupIDs) > 0 {
+		if err := tx.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(account).Association("Groups").Replace(groups)
+}
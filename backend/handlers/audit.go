@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Nombre maximal d'entrées par export CSV
+const auditExportLimit = 100000
+
+// AuditHandler expose le journal d'audit aux administrateurs
+type AuditHandler struct {
+	db    *gorm.DB
+	audit *services.AuditService
+}
+
+// NewAuditHandler crée une nouvelle instance de AuditHandler
+func NewAuditHandler(db *gorm.DB, audit *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		db:    db,
+		audit: audit,
+	}
+}
+
+// ListAuditLogs retourne le journal d'audit filtré et paginé
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	filter, ok := parseAuditFilter(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	logs, total, err := h.audit.Query(filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération du journal d'audit",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       logs,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// GetAuditLog retourne une entrée du journal avec ses snapshots
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	var entry models.AuditLog
+	if err := h.db.First(&entry, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Entrée du journal introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ExportAuditLogs exporte le journal filtré au format CSV
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	filter, ok := parseAuditFilter(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{
+		"id", "created_at", "actor_id", "actor_username", "actor_role", "action",
+		"target_type", "target_id", "target_label", "method", "path", "status_code",
+		"success", "changes", "details", "ip_address", "user_agent",
+	})
+
+	err := h.audit.Each(filter, auditExportLimit, func(entry models.AuditLog) error {
+		actorID := ""
+		if entry.ActorID != nil {
+			actorID = strconv.FormatUint(uint64(*entry.ActorID), 10)
+		}
+		return writer.Write([]string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			entry.CreatedAt.Format(time.RFC3339),
+			actorID,
+			csvSafe(entry.ActorUsername),
+			entry.ActorRole,
+			entry.Action,
+			entry.TargetType,
+			csvSafe(entry.TargetID),
+			csvSafe(entry.TargetLabel),
+			entry.Method,
+			entry.Path,
+			strconv.Itoa(entry.StatusCode),
+			strconv.FormatBool(entry.Success),
+			entry.Changes,
+			csvSafe(entry.Details),
+			entry.IPAddress,
+			csvSafe(entry.UserAgent),
+		})
+	})
+	writer.Flush()
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// parseAuditFilter lit les critères de filtrage depuis la query string
+func parseAuditFilter(c *gin.Context) (models.AuditLogFilter, bool) {
+	filter := models.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		IPAddress:  c.Query("ip"),
+		Search:     c.Query("q"),
+	}
+
+	if value := c.Query("actor_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			auditFilterError(c, "actor_id invalide")
+			return filter, false
+		}
+		actorID := uint(id)
+		filter.ActorID = &actorID
+	}
+	if value := c.Query("success"); value != "" {
+		success, err := strconv.ParseBool(value)
+		if err != nil {
+			auditFilterError(c, "success invalide")
+			return filter, false
+		}
+		filter.Success = &success
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			// Date seule : "to" inclut toute la journée
+			parsed, err = time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				auditFilterError(c, param+" invalide (format attendu: 2006-01-02 ou RFC 3339)")
+				return filter, false
+			}
+			if param == "to" {
+				parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+			}
+		}
+		*target = &parsed
+	}
+
+	return filter, true
+}
+
+func auditFilterError(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:   "validation_error",
+		Message: message,
+		Code:    http.StatusBadRequest,
+	})
+}
+
+// csvSafe neutralise les valeurs interprétées comme formules par les tableurs
+func csvSafe(value string) string {
+	if value != "" && (value[0] == '=' || value[0] == '+' || value[0] == '-' || value[0] == '@') {
+		return "'" + value
+	}
+	return value
+}
+
+// recordAuthEvent journalise un événement d'authentification (connexion, échec, rafraîchissement de jeton)
+func recordAuthEvent(audit *services.AuditService, c *gin.Context, action string, user *models.User, identifier, details string) {
+	entry := middleware.NewAuditEntry(c, action)
+	entry.TargetType = "users"
+	entry.TargetLabel = identifier
+	entry.Details = details
+	entry.Success = action == models.AuditActionLogin || action == models.AuditActionTokenRefresh ||
+		action == models.AuditActionPasswordChange
+	entry.StatusCode = http.StatusOK
+	if !entry.Success {
+		entry.StatusCode = http.StatusUnauthorized
+	}
+	if user != nil && user.ID != 0 {
+		entry.TargetID = strconv.FormatUint(uint64(user.ID), 10)
+		entry.TargetLabel = user.Username
+		// Une tentative échouée n'est pas attribuée au compte visé
+		if entry.ActorID == nil && entry.Success {
+			id := user.ID
+			entry.ActorID = &id
+			entry.ActorUsername = user.Username
+			entry.ActorRole = user.Role
+		}
+	}
+	audit.Record(entry)
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -23,20 +25,26 @@ type AuthHandler struct {
 	authMiddleware      *middleware.AuthMiddleware
 	signupEnabled       bool
 	notificationService *services.NotificationService
-	authSecurity        *utils.AuthSecurityManager
+	loginSecurity       *services.LoginSecurityService
 	bcryptCost          int
 	gamificationService *services.GamificationService
+	ldapService         *services.LDAPService
+	audit               *services.AuditService
+	passwordPolicy      *services.PasswordPolicyService
 }
 
-func NewAuthHandler(db *gorm.DB, authMiddleware *middleware.AuthMiddleware, signupEnabled bool, cfg *config.Config, gs *services.GamificationService) *AuthHandler {
+func NewAuthHandler(db *gorm.DB, authMiddleware *middleware.AuthMiddleware, signupEnabled bool, cfg *config.Config, gs *services.GamificationService, ldapService *services.LDAPService, passwordPolicy *services.PasswordPolicyService) *AuthHandler {
 	return &AuthHandler{
 		db:                  db,
 		authMiddleware:      authMiddleware,
 		signupEnabled:       signupEnabled,
 		notificationService: services.NewNotificationService(db),
-		authSecurity:        utils.NewAuthSecurityManager(),
+		loginSecurity:       services.NewLoginSecurityService(db, cfg),
 		bcryptCost:          cfg.Security.BcryptCost,
 		gamificationService: gs,
+		ldapService:         ldapService,
+		audit:               services.NewAuditService(db),
+		passwordPolicy:      passwordPolicy,
 	}
 }
 
@@ -61,23 +69,60 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Vérifier le lockout avant de tenter la connexion
 	clientIP := c.ClientIP()
-	identifier := fmt.Sprintf("%s:%s", clientIP, req.Username)
 
-	// Vérifier si l'identifiant est locké
-	if isLocked, remaining := h.authSecurity.CheckFailedLogin(identifier); isLocked {
+	// Rechercher l'utilisateur avec ses relations
+	var user models.User
+	userErr := h.db.Preload("Groups").Preload("AdminOfGroups").Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error
+
+	// Vérifier le verrouillage (adresse IP ou compte) avant de tenter la connexion
+	var target *models.User
+	if userErr == nil {
+		target = &user
+	}
+	if isLocked, remaining := h.loginSecurity.CheckLocked(clientIP, req.Username, target); isLocked {
+		recordAuthEvent(h.audit, c, models.AuditActionLoginFailed, target, req.Username, "Identifiant verrouillé")
 		c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
 			Error:   "Too Many Requests",
-			Message: fmt.Sprintf("Trop de tentatives échouées. Réessayez dans %.0f minutes", remaining.Minutes()),
+			Message: fmt.Sprintf("Trop de tentatives échouées. Réessayez dans %.0f minutes", math.Ceil(remaining.Minutes())),
 			Code:    http.StatusTooManyRequests,
 		})
 		return
 	}
 
-	// Rechercher l'utilisateur avec ses relations
-	var user models.User
-	if err := h.db.Preload("Groups").Preload("AdminOfGroups").Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error; err != nil {
+	// Vérifier le mot de passe local, puis l'annuaire LDAP/AD en repli. Pour les comptes rattachés
+	// à l'annuaire, seul le bind LDAP fait foi (un ancien mot de passe local ne doit plus suffire)
+	loginMethod := "local"
+	authenticated := userErr == nil && user.Password != "" && user.SSOProvider != services.LDAPProvider &&
+		bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) == nil
+	if !authenticated && (userErr != nil || user.Password == "" || user.SSOProvider == services.LDAPProvider) {
+		ldapUser, err := h.ldapService.Authenticate(req.Username, req.Password)
+		if err == nil {
+			user = *ldapUser
+			userErr = nil
+			authenticated = true
+			loginMethod = "ldap"
+		} else if !errors.Is(err, services.ErrLDAPDisabled) && !errors.Is(err, services.ErrLDAPInvalidCredentials) {
+			log.Printf("[Auth] Erreur LDAP pour %s: %v", req.Username, err)
+		}
+	}
+
+	// Les comptes de service ne s'authentifient que par jeton d'API
+	if userErr == nil && user.IsServiceAccount {
+		authenticated = false
+	}
+
+	if userErr != nil {
+		recordAuthEvent(h.audit, c, models.AuditActionLoginFailed, nil, req.Username, "Utilisateur inconnu")
+		// Les identifiants inconnus sont comptabilisés comme les autres (pas d'énumération des comptes)
+		if isLocked, remaining := h.loginSecurity.RecordFailure(clientIP, req.Username, nil); isLocked {
+			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+				Error:   "Too Many Requests",
+				Message: fmt.Sprintf("Compte verrouillé après trop de tentatives échouées. Réessayez dans %.0f minutes", remaining.Minutes()),
+				Code:    http.StatusTooManyRequests,
+			})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Nom d'utilisateur ou mot de passe incorrect",
@@ -88,6 +133,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// Vérifier le compte actif
 	if !user.IsActive {
+		recordAuthEvent(h.audit, c, models.AuditActionLoginFailed, &user, req.Username, "Compte désactivé")
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Compte désactivé",
@@ -97,10 +143,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Vérifier le mot de passe
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		// Enregistrer la tentative échouée
-		if isLocked, remaining := h.authSecurity.RecordFailedLogin(identifier); isLocked {
-			log.Printf("[Auth] Account locked for %s after failed attempts: %v", identifier, remaining)
+	if !authenticated {
+		recordAuthEvent(h.audit, c, models.AuditActionLoginFailed, &user, req.Username, "Mot de passe incorrect")
+
+		// Enregistrer la tentative échouée (compteurs par adresse IP et par compte)
+		if isLocked, remaining := h.loginSecurity.RecordFailure(clientIP, req.Username, &user); isLocked {
+			log.Printf("[Auth] Login locked for %s from IP %s after failed attempts: %v", req.Username, clientIP, remaining)
+			recordAuthEvent(h.audit, c, models.AuditActionAccountLocked, &user, req.Username, "Verrouillage après échecs répétés")
 			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
 				Error:   "Too Many Requests",
 				Message: fmt.Sprintf("Compte verrouillé après trop de tentatives échouées. Réessayez dans %.0f minutes", remaining.Minutes()),
@@ -117,9 +166,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Enregistrer la connexion réussie et nettoyer les tentatives échouées
-	h.authSecurity.RecordSuccessfulLogin(identifier)
+	// Enregistrer la connexion réussie : remise à zéro du compteur du compte, alerte si nouvel appareil
+	h.loginSecurity.RecordSuccess(clientIP, req.Username, c.Request.UserAgent(), &user)
 	log.Printf("[Auth] Successful login for %s from IP %s", req.Username, clientIP)
+	recordAuthEvent(h.audit, c, models.AuditActionLogin, &user, req.Username, loginMethod)
 
 	// Mettre à jour la date de dernière connexion
 	now := time.Now()
@@ -141,7 +191,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	*/
 
 	// Générer les tokens
-	token, err := h.authMiddleware.GenerateToken(&user)
+	sessionID := middleware.NewSessionID()
+	token, err := h.authMiddleware.GenerateToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -151,7 +202,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	refreshToken, err := h.authMiddleware.GenerateRefreshToken(&user)
+	refreshToken, err := h.authMiddleware.GenerateRefreshToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -175,6 +226,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		user.AdminOfGroups = adminGroups
 	}
 
+	// Signaler un mot de passe expiré (le frontend invite à le changer)
+	user.PasswordExpired = h.passwordPolicy.IsExpired(&user)
+	user.Impersonation = middleware.GetImpersonation(c)
+
 	// Masquer le mot de passe
 	user.Password = ""
 
@@ -230,13 +285,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Valider la force du mot de passe
-	if err := h.authSecurity.ValidatePassword(req.Password); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:   "Weak Password",
-			Message: fmt.Sprintf("Mot de passe trop faible: %v", err),
-			Code:    http.StatusBadRequest,
-		})
+	// Valider le mot de passe selon la politique configurée
+	if err := h.passwordPolicy.Validate(req.Password, nil); err != nil {
+		respondPasswordPolicyError(c, err)
 		return
 	}
 
@@ -281,6 +332,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		})
 		return
 	}
+	if err := h.passwordPolicy.RecordPassword(user.ID, user.Password); err != nil {
+		log.Printf("Erreur lors de l'enregistrement de l'historique des mots de passe: %v", err)
+	}
 
 	// Ajouter l'utilisateur au groupe par défaut configuré
 	defaultGroup := GetDefaultGroupFromDB(h.db)
@@ -318,8 +372,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// Recharger l'utilisateur avec ses relations
 	h.db.Preload("Groups").Preload("AdminOfGroups").First(&user, user.ID)
 
+	go services.NewWebhookService(h.db).UserCreated(&user, "register")
+
 	// Générer les tokens
-	token, err := h.authMiddleware.GenerateToken(&user)
+	sessionID := middleware.NewSessionID()
+	token, err := h.authMiddleware.GenerateToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -329,7 +386,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	refreshToken, err := h.authMiddleware.GenerateRefreshToken(&user)
+	refreshToken, err := h.authMiddleware.GenerateRefreshToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -376,6 +433,8 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// Vérifier le refresh token
 	claims, err := h.authMiddleware.VerifyRefreshToken(req.RefreshToken)
 	if err != nil {
+		recordAuthEvent(h.audit, c, models.AuditActionTokenRefreshFailed, nil, "", "Refresh token invalide ou expiré")
+
 		// Si c'est une erreur de secret JWT changé, c'est probablement un redémarrage du serveur
 		if strings.Contains(err.Error(), "secret_jwt_changed") {
 			log.Printf("[Auth] Refresh token signé avec un ancien secret JWT (redémarrage serveur détecté)")
@@ -395,9 +454,20 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	if h.authMiddleware.SessionEnded(claims.SessionID) {
+		recordAuthEvent(h.audit, c, models.AuditActionTokenRefreshFailed, nil, claims.Username, "Session fermée par le fournisseur d'identité")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Session fermée par le fournisseur d'identité",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
 	// Récupérer l'utilisateur avec ses relations
 	var user models.User
 	if err := h.db.Preload("Groups").Preload("AdminOfGroups").First(&user, claims.UserID).Error; err != nil {
+		recordAuthEvent(h.audit, c, models.AuditActionTokenRefreshFailed, nil, claims.Username, "Utilisateur non trouvé")
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Utilisateur non trouvé",
@@ -408,6 +478,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 	// Vérifier que le compte est actif
 	if !user.IsActive {
+		recordAuthEvent(h.audit, c, models.AuditActionTokenRefreshFailed, &user, "", "Compte désactivé")
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Compte désactivé",
@@ -417,7 +488,12 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	// Générer de nouveaux tokens
-	newToken, err := h.authMiddleware.GenerateToken(&user)
+	// La session de connexion est conservée : les tokens CSRF déjà émis restent valides
+	sessionID := claims.SessionID
+	if sessionID == "" {
+		sessionID = middleware.NewSessionID()
+	}
+	newToken, err := h.authMiddleware.GenerateToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -427,7 +503,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	newRefreshToken, err := h.authMiddleware.GenerateRefreshToken(&user)
+	newRefreshToken, err := h.authMiddleware.GenerateRefreshToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -437,6 +513,8 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	recordAuthEvent(h.audit, c, models.AuditActionTokenRefresh, &user, "", "")
+
 	// Masquer le mot de passe
 	user.Password = ""
 
@@ -490,6 +568,8 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		user.AdminOfGroups = adminGroups
 	}
 
+	user.PasswordExpired = h.passwordPolicy.IsExpired(&user)
+
 	// Masquer le mot de passe
 	user.Password = ""
 
@@ -541,6 +621,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	// Vérifier l'ancien mot de passe
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+		recordAuthEvent(h.audit, c, models.AuditActionPasswordChangeFailed, &user, "", "Ancien mot de passe incorrect")
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Ancien mot de passe incorrect",
@@ -549,6 +630,13 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Valider le nouveau mot de passe (composition, historique, fuites)
+	if err := h.passwordPolicy.Validate(req.NewPassword, &user); err != nil {
+		recordAuthEvent(h.audit, c, models.AuditActionPasswordChangeFailed, &user, "", err.Error())
+		respondPasswordPolicyError(c, err)
+		return
+	}
+
 	// Hasher le nouveau mot de passe avec coût sécurisé (12 minimum - OWASP 2025)
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), h.bcryptCost)
 	if err != nil {
@@ -570,12 +658,36 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		})
 		return
 	}
+	if err := h.passwordPolicy.RecordPassword(user.ID, user.Password); err != nil {
+		log.Printf("Erreur lors de l'enregistrement de l'historique des mots de passe: %v", err)
+	}
+
+	recordAuthEvent(h.audit, c, models.AuditActionPasswordChange, &user, "", "")
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Mot de passe changé avec succès",
 	})
 }
 
+// @Summary Politique de mots de passe
+// @Description Récupère les règles de mot de passe en vigueur (affichées par les formulaires)
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.PasswordPolicyInfo
+// @Router /auth/password-policy [get]
+func (h *AuthHandler) GetPasswordPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.passwordPolicy.Policy())
+}
+
+// respondPasswordPolicyError répond 400 avec le motif de refus du mot de passe
+func respondPasswordPolicyError(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:   "Weak Password",
+		Message: fmt.Sprintf("Mot de passe refusé: %v", err),
+		Code:    http.StatusBadRequest,
+	})
+}
+
 // @Summary Statut de l'inscription
 // @Description Récupère le statut de l'inscription (activée/désactivée)
 // @Tags Auth
@@ -648,7 +760,8 @@ func (h *AuthHandler) SSOAutoLogin(c *gin.Context) {
 	}
 
 	// Générer les tokens JWT pour l'utilisateur SSO
-	token, err := h.authMiddleware.GenerateToken(ssoUser)
+	sessionID := middleware.NewSessionID()
+	token, err := h.authMiddleware.GenerateToken(ssoUser, sessionID)
 	if err != nil {
 		log.Printf("[SSO] Erreur lors de la génération du token: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -659,6 +772,8 @@ func (h *AuthHandler) SSOAutoLogin(c *gin.Context) {
 		return
 	}
 
+	recordAuthEvent(h.audit, c, models.AuditActionLogin, ssoUser, ssoUser.Username, "sso")
+
 	// Mettre à jour la date de dernière connexion
 	now := time.Now()
 	if err := h.db.Model(ssoUser).Update("last_login", now).Error; err != nil {
@@ -666,7 +781,7 @@ func (h *AuthHandler) SSOAutoLogin(c *gin.Context) {
 		// Ne pas bloquer la connexion pour cette erreur
 	}
 
-	refreshToken, err := h.authMiddleware.GenerateRefreshToken(ssoUser)
+	refreshToken, err := h.authMiddleware.GenerateRefreshToken(ssoUser, sessionID)
 	if err != nil {
 		log.Printf("[SSO] Erreur lors de la génération du refresh token: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"airboard/models"
+	"airboard/services"
 	"airboard/services/chat"
 
 	"github.com/gin-gonic/gin"
@@ -13,12 +14,13 @@ import (
 )
 
 type ChatHandler struct {
-	db  *gorm.DB
-	hub *chat.Hub
+	db        *gorm.DB
+	hub       *chat.Hub
+	directory *services.DirectoryService
 }
 
 func NewChatHandler(db *gorm.DB, hub *chat.Hub) *ChatHandler {
-	return &ChatHandler{db: db, hub: hub}
+	return &ChatHandler{db: db, hub: hub, directory: services.NewDirectoryService(db)}
 }
 
 // ServeWS handles WebSocket requests from the peer.
@@ -65,18 +67,16 @@ func (h *ChatHandler) ServeWS(c *gin.Context) {
 func (h *ChatHandler) GetContacts(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	var users []models.User
 	var groups []models.Group
 
-	// 1. Get all active users (excluding self)
-	// Optimization: Only users in same groups or all users depending on privacy policy
-	// For MVP: All active users
-	h.db.Where("id != ? AND is_active = ?", userID, true).
-		Select("id, username, first_name, last_name, avatar_url, role").
-		Preload("Groups", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id, name, color")
-		}).
-		Find(&users)
+	// 1. Contacts: people listed in the directory and members of the user's groups,
+	// with fields filtered by their privacy settings
+	users, err := h.directory.Contacts(directoryViewer(c))
+	if err != nil {
+		log.Printf("Error fetching contacts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contacts"})
+		return
+	}
 
 	// 2. Get user's groups
 	h.db.Joins("JOIN user_groups on user_groups.group_id = groups.id").
@@ -107,15 +107,8 @@ func (h *CommentHandler) GetComments(c *gin.Context) {
 		Order("created_at DESC")
 
 	// Si modération requise, ne montrer que les commentaires approuvés (sauf pour admin/editor/admin de groupe)
-	userRole, exists := c.Get("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-	if settings.RequireModeration && exists {
-		role := userRole.(string)
-		// Admin, editor, et utilisateurs qui administrent au moins un groupe peuvent voir tous les commentaires
-		if role != "admin" && role != "editor" && len(managedGroupIDs) == 0 {
-			query = query.Where("is_approved = ?", true)
-		}
-	} else if settings.RequireModeration {
+	// Les modérateurs (admin, editor, administrateurs de groupe) peuvent voir tous les commentaires
+	if settings.RequireModeration && !middleware.HasAnyPermission(c, models.PermCommentsModerate) {
 		query = query.Where("is_approved = ?", true)
 	}
 
@@ -259,8 +252,6 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	userRole, _ := c.Get("role")
-
 	// Récupérer le commentaire
 	var comment models.Comment
 	if err := h.DB.First(&comment, commentID).Error; err != nil {
@@ -280,8 +271,17 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	// Vérifier que l'utilisateur est l'auteur ou admin
-	if comment.UserID != userID.(uint) && userRole != "admin" && userRole != "editor" {
+	// Seules les modifications par un modérateur sont journalisées (route auditée)
+	if comment.UserID == userID.(uint) {
+		middleware.SkipAudit(c)
+	} else {
+		middleware.SetAuditAction(c, "comments.moderate_update")
+		middleware.SetAuditTarget(c, "comments", comment.ID, comment.EntityType)
+		middleware.AuditBefore(c, comment)
+	}
+
+	// Vérifier que l'utilisateur est l'auteur ou modérateur
+	if comment.UserID != userID.(uint) && !middleware.HasPermission(c, models.PermCommentsModerate) {
 		c.JSON(http.StatusForbidden, models.ErrorResponse{
 			Error:   "FORBIDDEN",
 			Message: "Vous n'avez pas la permission de modifier ce commentaire",
@@ -312,6 +312,10 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
+	if comment.UserID != userID.(uint) {
+		middleware.AuditAfter(c, comment)
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Commentaire mis à jour avec succès",
 		Data:    comment,
@@ -332,9 +336,6 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	userRole, _ := c.Get("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-
 	// Récupérer le commentaire
 	var comment models.Comment
 	if err := h.DB.First(&comment, commentID).Error; err != nil {
@@ -354,8 +355,17 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	// Vérifier que l'utilisateur est l'auteur ou admin/editor/admin de groupe
-	if comment.UserID != userID.(uint) && userRole != "admin" && userRole != "editor" && len(managedGroupIDs) == 0 {
+	// Seules les suppressions par un modérateur sont journalisées (route auditée)
+	if comment.UserID == userID.(uint) {
+		middleware.SkipAudit(c)
+	} else {
+		middleware.SetAuditAction(c, "comments.moderate_delete")
+		middleware.SetAuditTarget(c, "comments", comment.ID, comment.EntityType)
+		middleware.AuditBefore(c, comment)
+	}
+
+	// Vérifier que l'utilisateur est l'auteur ou modérateur (y compris administrateur de groupe)
+	if comment.UserID != userID.(uint) && !middleware.HasAnyPermission(c, models.PermCommentsModerate) {
 		c.JSON(http.StatusForbidden, models.ErrorResponse{
 			Error:   "FORBIDDEN",
 			Message: "Vous n'avez pas la permission de supprimer ce commentaire",
@@ -420,7 +430,11 @@ func (h *CommentHandler) ModerateComment(c *gin.Context) {
 		return
 	}
 
+	middleware.SetAuditTarget(c, "comments", comment.ID, comment.EntityType)
+	middleware.AuditBefore(c, comment)
+
 	// Mettre à jour le statut de modération
+	wasFlagged := comment.IsFlagged
 	comment.IsApproved = req.IsApproved
 	comment.IsFlagged = req.IsFlagged
 	moderatorID := userID.(uint)
@@ -447,6 +461,11 @@ func (h *CommentHandler) ModerateComment(c *gin.Context) {
 		return
 	}
 
+	// Webhooks sortants
+	if comment.IsFlagged && !wasFlagged {
+		go services.NewWebhookService(h.DB).CommentFlagged(&comment)
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "Commentaire modéré avec succès",
 		Data:    comment,
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DirectoryHandler gère l'annuaire des collaborateurs et les réglages de confidentialité des profils
+type DirectoryHandler struct {
+	db        *gorm.DB
+	directory *services.DirectoryService
+}
+
+// NewDirectoryHandler crée une nouvelle instance de DirectoryHandler
+func NewDirectoryHandler(db *gorm.DB, directory *services.DirectoryService) *DirectoryHandler {
+	return &DirectoryHandler{db: db, directory: directory}
+}
+
+// directoryViewer : les profils masqués et les champs privés ne sont visibles qu'avec la gestion des utilisateurs
+// (permission effective, pas le seul rôle admin)
+func directoryViewer(c *gin.Context) services.DirectoryViewer {
+	return services.DirectoryViewer{
+		UserID:  c.GetUint("user_id"),
+		IsAdmin: middleware.HasPermission(c, models.PermUsersManage),
+	}
+}
+
+// ============ ANNUAIRE ============
+
+// ListPeople recherche dans l'annuaire (q, department, location, group_id) avec compteurs par filtre
+func (h *DirectoryHandler) ListPeople(c *gin.Context) {
+	groupID, _ := strconv.ParseUint(c.Query("group_id"), 10, 64)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(services.DirectoryDefaultPageSize)))
+
+	response, err := h.directory.Search(services.DirectoryParams{
+		Query:      strings.TrimSpace(c.Query("q")),
+		Department: strings.TrimSpace(c.Query("department")),
+		Location:   strings.TrimSpace(c.Query("location")),
+		GroupID:    uint(groupID),
+		Page:       page,
+		PageSize:   pageSize,
+	}, directoryViewer(c))
+	if err != nil {
+		log.Printf("[Directory] Erreur de recherche dans l'annuaire: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la recherche dans l'annuaire",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPerson fiche publique d'une personne de l'annuaire
+func (h *DirectoryHandler) GetPerson(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "ID invalide",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	profile, err := h.directory.Profile(uint(id), directoryViewer(c))
+	if err != nil {
+		if errors.Is(err, services.ErrDirectoryPersonNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Not Found",
+				Message: err.Error(),
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération du profil",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ============ CONFIDENTIALITÉ ============
+
+// GetMyPrivacy retourne les réglages de confidentialité du profil de l'utilisateur connecté
+func (h *DirectoryHandler) GetMyPrivacy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.directory.Privacy(c.GetUint("user_id")))
+}
+
+// UpdateMyPrivacy modifie les réglages de confidentialité du profil de l'utilisateur connecté
+func (h *DirectoryHandler) UpdateMyPrivacy(c *gin.Context) {
+	var req models.UpdateProfilePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Données invalides",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	privacy, err := h.directory.UpdatePrivacy(c.GetUint("user_id"), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProfileVisibility) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de l'enregistrement des réglages de confidentialité",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, privacy)
+}
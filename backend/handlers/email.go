@@ -126,12 +126,19 @@ func (h *EmailHandler) GetEmailTemplates(c *gin.Context) {
 		return
 	}
 
-	// Si aucun template, créer les templates par défaut
-	if len(templates) == 0 {
-		defaultTemplates := models.GetDefaultEmailTemplates()
-		for _, t := range defaultTemplates {
+	// Créer les templates par défaut manquants (première utilisation ou nouveaux types)
+	existing := make(map[string]bool, len(templates))
+	for _, t := range templates {
+		existing[t.Type] = true
+	}
+	created := false
+	for _, t := range models.GetDefaultEmailTemplates() {
+		if !existing[t.Type] {
 			h.db.Create(&t)
+			created = true
 		}
+	}
+	if created {
 		h.db.Order("type").Find(&templates)
 	}
 
@@ -271,7 +278,10 @@ func (h *EmailHandler) GetTemplateVariables(c *gin.Context) {
 	variables := map[string][]map[string]string{
 		"news": {
 			{"name": "{{.Title}}", "description": "Titre de l'article"},
-			{"name": "{{.Summary}}", "description": "Résumé de l'article"},
+			{"name": "{{.Summary}}", "description": "Résumé de l'article (début du contenu si l'article n'en a pas)"},
+			{"name": "{{.Content}}", "description": "Contenu de l'article en HTML assaini"},
+			{"name": "{{.ContentText}}", "description": "Contenu de l'article en texte brut"},
+			{"name": "{{.ReadingTime}}", "description": "Temps de lecture estimé (minutes)"},
 			{"name": "{{.Author}}", "description": "Nom de l'auteur"},
 			{"name": "{{.Link}}", "description": "Lien vers l'article"},
 			{"name": "{{.AppName}}", "description": "Nom de l'application"},
@@ -286,7 +296,8 @@ func (h *EmailHandler) GetTemplateVariables(c *gin.Context) {
 		},
 		"event": {
 			{"name": "{{.Title}}", "description": "Titre de l'événement"},
-			{"name": "{{.Description}}", "description": "Description de l'événement"},
+			{"name": "{{.Description}}", "description": "Description de l'événement en texte brut"},
+			{"name": "{{.DescriptionHTML}}", "description": "Description de l'événement en HTML assaini"},
 			{"name": "{{.StartDate}}", "description": "Date de début"},
 			{"name": "{{.EndDate}}", "description": "Date de fin"},
 			{"name": "{{.Location}}", "description": "Lieu de l'événement"},
@@ -299,6 +310,14 @@ func (h *EmailHandler) GetTemplateVariables(c *gin.Context) {
 			{"name": "{{.Type}}", "description": "Type d'annonce (info, warning, success, error)"},
 			{"name": "{{.AppName}}", "description": "Nom de l'application"},
 		},
+		models.EmailTemplateAckReminder: {
+			{"name": "{{.Name}}", "description": "Nom du destinataire"},
+			{"name": "{{.Title}}", "description": "Titre du contenu à lire"},
+			{"name": "{{.DueAt}}", "description": "Échéance de lecture (vide si aucune)"},
+			{"name": "{{.Overdue}}", "description": "Vrai si l'échéance est dépassée"},
+			{"name": "{{.Link}}", "description": "Lien vers le contenu"},
+			{"name": "{{.AppName}}", "description": "Nom de l'application"},
+		},
 	}
 
 	c.JSON(http.StatusOK, variables)
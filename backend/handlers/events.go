@@ -18,10 +18,11 @@ import (
 type EventsHandler struct {
 	db                  *gorm.DB
 	gamificationService *services.GamificationService
+	revisionService     *services.RevisionService
 }
 
 func NewEventsHandler(db *gorm.DB, gs *services.GamificationService) *EventsHandler {
-	return &EventsHandler{db: db, gamificationService: gs}
+	return &EventsHandler{db: db, gamificationService: gs, revisionService: services.NewRevisionService(db)}
 }
 
 // GetEvents - Liste des événements (accessible à tous les utilisateurs connectés)
@@ -120,12 +121,11 @@ func (h *EventsHandler) GetEvents(c *gin.Context) {
 			"%"+sanitizedSearch+"%", "%"+sanitizedSearch+"%", "%"+sanitizedSearch+"%")
 	}
 
-	// Filtre published only et visibilité par groupes selon le rôle
-	userRole := c.GetString("role")
+	// Filtre published only et visibilité par groupes selon les permissions
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermEventsManage)
 
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermEventsManage) {
 		// Admin voit tout (publié + brouillons)
 	} else if len(managedGroupIDs) > 0 {
 		// Utilisateur qui administre au moins un groupe
@@ -158,7 +158,7 @@ func (h *EventsHandler) GetEvents(c *gin.Context) {
 				))
 			`, true, time.Now(), managedGroupIDs)
 		}
-	} else if userRole == "editor" {
+	} else if middleware.HasAnyPermission(c, models.PermEventsCreate) {
 		// Editor voit : événements publiques + ses propres brouillons
 		query = query.Where("(is_published = ? AND (published_at IS NULL OR published_at <= ?)) OR author_id = ?",
 			true, time.Now(), userID)
@@ -256,12 +256,11 @@ func (h *EventsHandler) GetCalendarView(c *gin.Context) {
 		"(start_date <= ? AND (end_date IS NULL OR end_date >= ?)) OR (end_date IS NOT NULL AND end_date >= ? AND start_date <= ?)",
 		endDate, startDate, endDate, startDate)
 
-	// Appliquer filtres de visibilité selon rôle
-	userRole := c.GetString("role")
+	// Appliquer filtres de visibilité selon les permissions
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermEventsManage)
 
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermEventsManage) {
 		// Admin voit tout
 	} else if len(managedGroupIDs) > 0 {
 		// Utilisateur qui administre au moins un groupe
@@ -374,12 +373,11 @@ func (h *EventsHandler) GetEventBySlug(c *gin.Context) {
 		return
 	}
 
-	// Vérifier la visibilité selon le rôle
-	userRole := c.GetString("role")
+	// Vérifier la visibilité selon les permissions
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermEventsManage)
 
-	if userRole != "admin" {
+	if !middleware.HasPermission(c, models.PermEventsManage) {
 		// Vérifier si l'événement est publié
 		if !event.IsPublished {
 			// Seul l'auteur peut voir un brouillon
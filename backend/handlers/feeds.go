@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"airboard/config"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Paramètres de filtrage acceptés par les flux
+var newsFeedFilters = []string{"category", "tag", "type", "limit"}
+
+// FeedHandler gère les flux RSS/Atom des news et les jetons d'abonnement
+type FeedHandler struct {
+	db    *gorm.DB
+	feeds *services.FeedService
+}
+
+// NewFeedHandler crée une nouvelle instance de FeedHandler
+func NewFeedHandler(db *gorm.DB, cfg *config.Config, permissions *services.PermissionService) *FeedHandler {
+	return &FeedHandler{
+		db:    db,
+		feeds: services.NewFeedService(db, cfg, permissions),
+	}
+}
+
+// ============ JETON D'ABONNEMENT ============
+
+// GetMyFeedToken retourne l'état du jeton de flux de l'utilisateur connecté
+func (h *FeedHandler) GetMyFeedToken(c *gin.Context) {
+	token, err := h.feeds.ActiveToken(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération du jeton de flux",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.FeedTokenInfo{
+		Active:   token != nil,
+		Token:    token,
+		Filters:  newsFeedFilters,
+		MaxItems: services.FeedMaxItems,
+	})
+}
+
+// RegenerateMyFeedToken crée un nouveau jeton de flux (l'ancien cesse de fonctionner) et retourne les URL des flux
+func (h *FeedHandler) RegenerateMyFeedToken(c *gin.Context) {
+	plain, token, err := h.feeds.Issue(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la création du jeton de flux",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.FeedTokenInfo{
+		Active:   true,
+		Token:    token,
+		Plain:    plain,
+		AtomURL:  h.feeds.FeedURL("atom", plain),
+		RSSURL:   h.feeds.FeedURL("rss", plain),
+		Filters:  newsFeedFilters,
+		MaxItems: services.FeedMaxItems,
+	})
+}
+
+// RevokeMyFeedToken révoque le jeton de flux de l'utilisateur connecté
+func (h *FeedHandler) RevokeMyFeedToken(c *gin.Context) {
+	if err := h.feeds.Revoke(c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la révocation du jeton de flux",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{Message: "Jeton de flux révoqué"})
+}
+
+// ============ FLUX ============
+
+// NewsAtom flux Atom des news visibles par le propriétaire du jeton
+func (h *FeedHandler) NewsAtom(c *gin.Context) {
+	h.serveNewsFeed(c, "atom")
+}
+
+// NewsRSS flux RSS 2.0 des news visibles par le propriétaire du jeton
+func (h *FeedHandler) NewsRSS(c *gin.Context) {
+	h.serveNewsFeed(c, "rss")
+}
+
+func (h *FeedHandler) serveNewsFeed(c *gin.Context, format string) {
+	// Les lecteurs de flux ne savent pas envoyer d'en-tête : le jeton est passé dans l'URL
+	user, err := h.feeds.Authenticate(c.Query("token"), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: err.Error(),
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	filter := models.NewsFeedFilter{
+		Category: strings.TrimSpace(c.Query("category")),
+		Type:     strings.TrimSpace(c.Query("type")),
+	}
+	for _, tag := range strings.Split(c.Query("tag"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, _ = strconv.Atoi(limit)
+	}
+
+	news, err := h.feeds.NewsFor(user, filter)
+	if err != nil {
+		log.Printf("[Feeds] Erreur de récupération des news pour l'utilisateur %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la génération du flux",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	etag, lastModified := h.feeds.Validators(user.ID, format, feedQueryWithoutToken(c), news)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, max-age=300")
+	if feedNotModified(c, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	selfURL := h.feeds.FeedURL(format, c.Query("token"))
+	if extra := feedQueryWithoutToken(c); extra != "" {
+		selfURL += "&" + extra
+	}
+
+	var body []byte
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == "rss" {
+		body, err = h.feeds.RenderRSS(news, selfURL, lastModified)
+		contentType = "application/rss+xml; charset=utf-8"
+	} else {
+		body, err = h.feeds.RenderAtom(news, selfURL, lastModified)
+	}
+	if err != nil {
+		log.Printf("[Feeds] Erreur de rendu du flux %s: %v", format, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "render_error",
+			Message: "Erreur lors de la génération du flux",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// feedNotModified applique les requêtes conditionnelles (If-None-Match prioritaire sur If-Modified-Since)
+func feedNotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if since := c.GetHeader("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil && !lastModified.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func feedQueryWithoutToken(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	query.Del("token")
+	return query.Encode()
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"airboard/middleware"
 	"airboard/models"
 	"airboard/services"
 
@@ -56,15 +57,11 @@ func (h *GamificationHandler) GetMyAchievements(c *gin.Context) {
 
 // GetAllAchievements récupère tous les badges configurés
 func (h *GamificationHandler) GetAllAchievements(c *gin.Context) {
-	role := c.GetString("role")
-	managedGroupIDs, _ := c.Get("managed_group_ids")
-
-	// Un utilisateur est considéré comme "contributeur" s'il est admin, editor,
-	// ou s'il administre au moins un groupe.
-	isContributor := role == "admin" || role == "editor"
-	if ids, ok := managedGroupIDs.([]uint); ok && len(ids) > 0 {
-		isContributor = true
-	}
+	// Un utilisateur est considéré comme "contributeur" s'il peut créer du contenu,
+	// quel que soit le périmètre (rôle éditeur, administrateur de groupe, rôle personnalisé).
+	isContributor := middleware.HasAnyPermission(c, models.PermNewsCreate) ||
+		middleware.HasAnyPermission(c, models.PermEventsCreate) ||
+		middleware.HasAnyPermission(c, models.PermPollsCreate)
 
 	var achievements []models.Achievement
 	query := h.db
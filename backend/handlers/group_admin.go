@@ -20,9 +20,7 @@ func NewGroupAdminHandler(db *gorm.DB) *GroupAdminHandler {
 
 // GetAppGroups retourne les AppGroups accessibles par les groupes administrés
 func (h *GroupAdminHandler) GetAppGroups(c *gin.Context) {
-	role := c.GetString("role")
-
-	if role == "admin" {
+	if middleware.HasPermission(c, models.PermApplicationsManage) {
 		// Admin global voit tous les AppGroups
 		var appGroups []models.AppGroup
 		h.db.Order("\"order\" ASC, name ASC").
@@ -34,7 +32,7 @@ func (h *GroupAdminHandler) GetAppGroups(c *gin.Context) {
 	}
 
 	// Group admin voit uniquement les AppGroups privés (is_private = true) liés à ses groupes administrés
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
 	if len(managedGroupIDs) == 0 {
 		c.JSON(http.StatusOK, []models.AppGroup{})
 		return
@@ -55,9 +53,7 @@ func (h *GroupAdminHandler) GetAppGroups(c *gin.Context) {
 
 // GetManagedGroups retourne les groupes administrés par l'utilisateur
 func (h *GroupAdminHandler) GetManagedGroups(c *gin.Context) {
-	role := c.GetString("role")
-
-	if role == "admin" {
+	if middleware.HasPermission(c, models.PermGroupsManage) {
 		// Admin global voit tous les groupes
 		var groups []models.Group
 		h.db.Where("is_active = ?", true).Preload("Users").Preload("AppGroups").Find(&groups)
@@ -83,9 +79,7 @@ func (h *GroupAdminHandler) GetManagedGroups(c *gin.Context) {
 
 // GetApplications retourne les applications des AppGroups privés gérés
 func (h *GroupAdminHandler) GetApplications(c *gin.Context) {
-	role := c.GetString("role")
-
-	if role == "admin" {
+	if middleware.HasPermission(c, models.PermApplicationsManage) {
 		// Admin global voit toutes les applications
 		var applications []models.Application
 		h.db.Order("\"order\" ASC, name ASC").
@@ -96,7 +90,7 @@ func (h *GroupAdminHandler) GetApplications(c *gin.Context) {
 	}
 
 	// Group admin voit uniquement les applications des AppGroups privés liés à ses groupes
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
 	if len(managedGroupIDs) == 0 {
 		c.JSON(http.StatusOK, []models.Application{})
 		return
@@ -147,10 +141,9 @@ func (h *GroupAdminHandler) CreateApplication(c *gin.Context) {
 		return
 	}
 
-	role := c.GetString("role")
-	if role != "admin" {
+	if !middleware.HasPermission(c, models.PermApplicationsManage) {
 		// Vérifier que l'AppGroup est privé et appartient aux groupes administrés
-		managedGroupIDs := middleware.GetManagedGroupIDs(c)
+		managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
 		if len(managedGroupIDs) == 0 {
 			c.JSON(http.StatusForbidden, models.ErrorResponse{
 				Error:   "Forbidden",
@@ -237,10 +230,9 @@ func (h *GroupAdminHandler) UpdateApplication(c *gin.Context) {
 		return
 	}
 
-	role := c.GetString("role")
-	if role != "admin" {
+	if !middleware.HasPermission(c, models.PermApplicationsManage) {
 		// Vérifier que l'application actuelle et le nouveau AppGroup sont accessibles
-		managedGroupIDs := middleware.GetManagedGroupIDs(c)
+		managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
 		if len(managedGroupIDs) == 0 {
 			c.JSON(http.StatusForbidden, models.ErrorResponse{
 				Error:   "Forbidden",
@@ -324,10 +316,9 @@ func (h *GroupAdminHandler) DeleteApplication(c *gin.Context) {
 		return
 	}
 
-	role := c.GetString("role")
-	if role != "admin" {
+	if !middleware.HasPermission(c, models.PermApplicationsManage) {
 		// Vérifier que l'application appartient à un AppGroup privé géré
-		managedGroupIDs := middleware.GetManagedGroupIDs(c)
+		managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermApplicationsManage)
 		if len(managedGroupIDs) == 0 {
 			c.JSON(http.StatusForbidden, models.ErrorResponse{
 				Error:   "Forbidden",
@@ -9,6 +9,7 @@ import (
 	"airboard/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreateEventGroupAdmin - Créer un événement (Group Admin)
@@ -20,26 +21,12 @@ func (h *EventsHandler) CreateEventGroupAdmin(c *gin.Context) {
 		return
 	}
 
-	// Récupérer les groupes gérés par ce group admin
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-
-	// Validation : si des groupes cibles sont spécifiés, ils doivent être dans les groupes gérés
-	if len(req.TargetGroupIDs) > 0 {
-		for _, targetGroupID := range req.TargetGroupIDs {
-			isManaged := false
-			for _, managedID := range managedGroupIDs {
-				if targetGroupID == managedID {
-					isManaged = true
-					break
-				}
-			}
-			if !isManaged {
-				c.JSON(http.StatusForbidden, gin.H{
-					"error": "Vous ne pouvez cibler que les groupes que vous administrez",
-				})
-				return
-			}
-		}
+	// Validation : si des groupes cibles sont spécifiés, ils doivent être dans le périmètre autorisé
+	if !middleware.CanTargetGroups(c, models.PermEventsCreate, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Vous ne pouvez cibler que les groupes que vous administrez",
+		})
+		return
 	}
 
 	// Récupérer l'utilisateur connecté
@@ -75,24 +62,40 @@ func (h *EventsHandler) CreateEventGroupAdmin(c *gin.Context) {
 		event.PublishedAt = &now
 	}
 
-	// Créer l'événement en BDD
-	if err := h.db.Create(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création de l'événement"})
-		return
-	}
+	// Créer l'événement, ses associations et sa première version dans la même transaction
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
 
-	// Associer les tags
-	if len(req.TagIDs) > 0 {
-		var tags []models.Tag
-		h.db.Where("id IN ?", req.TagIDs).Find(&tags)
-		h.db.Model(&event).Association("Tags").Append(tags)
-	}
+		// Associer les tags
+		if len(req.TagIDs) > 0 {
+			var tags []models.Tag
+			if err := tx.Where("id IN ?", req.TagIDs).Find(&tags).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("Tags").Append(tags); err != nil {
+				return err
+			}
+		}
 
-	// Associer les groupes cibles
-	if len(req.TargetGroupIDs) > 0 {
-		var groups []models.Group
-		h.db.Where("id IN ?", req.TargetGroupIDs).Find(&groups)
-		h.db.Model(&event).Association("TargetGroups").Append(groups)
+		// Associer les groupes cibles
+		if len(req.TargetGroupIDs) > 0 {
+			var groups []models.Group
+			if err := tx.Where("id IN ?", req.TargetGroupIDs).Find(&groups).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("TargetGroups").Append(groups); err != nil {
+				return err
+			}
+		}
+
+		// Historique des versions
+		return h.recordRevision(tx, &event, userID, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création de l'événement"})
+		return
 	}
 
 	// Recharger avec les relations
@@ -132,30 +135,9 @@ func (h *EventsHandler) UpdateEventGroupAdmin(c *gin.Context) {
 
 	// Vérification des permissions
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-
-	// Vérifier si le group admin peut gérer cet événement
-	canManage := false
 
-	// 1. Si c'est son propre événement
-	if event.AuthorID == userID {
-		canManage = true
-	} else {
-		// 2. Si l'événement cible un de ses groupes gérés
-		for _, targetGroup := range event.TargetGroups {
-			for _, managedID := range managedGroupIDs {
-				if targetGroup.ID == managedID {
-					canManage = true
-					break
-				}
-			}
-			if canManage {
-				break
-			}
-		}
-	}
-
-	if !canManage {
+	// Son propre événement, gestion globale des événements ou événement ciblant un de ses groupes gérés
+	if !h.canEditEvent(c, &event) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez modifier que les événements de vos groupes gérés"})
 		return
 	}
@@ -166,23 +148,12 @@ func (h *EventsHandler) UpdateEventGroupAdmin(c *gin.Context) {
 		return
 	}
 
-	// Validation : si des groupes cibles sont spécifiés, ils doivent être dans les groupes gérés
-	if len(req.TargetGroupIDs) > 0 {
-		for _, targetGroupID := range req.TargetGroupIDs {
-			isManaged := false
-			for _, managedID := range managedGroupIDs {
-				if targetGroupID == managedID {
-					isManaged = true
-					break
-				}
-			}
-			if !isManaged {
-				c.JSON(http.StatusForbidden, gin.H{
-					"error": "Vous ne pouvez cibler que les groupes que vous administrez",
-				})
-				return
-			}
-		}
+	// Validation : si des groupes cibles sont spécifiés, ils doivent être dans le périmètre autorisé
+	if !middleware.CanTargetGroups(c, models.PermEventsCreate, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Vous ne pouvez cibler que les groupes que vous administrez",
+		})
+		return
 	}
 
 	// Mettre à jour les champs
@@ -215,26 +186,46 @@ func (h *EventsHandler) UpdateEventGroupAdmin(c *gin.Context) {
 		event.PublishedAt = &now
 	}
 
-	// Sauvegarder
-	if err := h.db.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour"})
-		return
-	}
+	// Sauvegarder l'événement, ses associations et la nouvelle version dans la même transaction
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&event).Error; err != nil {
+			return err
+		}
 
-	// Mettre à jour les tags
-	h.db.Model(&event).Association("Tags").Clear()
-	if len(req.TagIDs) > 0 {
-		var tags []models.Tag
-		h.db.Where("id IN ?", req.TagIDs).Find(&tags)
-		h.db.Model(&event).Association("Tags").Append(tags)
-	}
+		// Mettre à jour les tags
+		if err := tx.Model(&event).Association("Tags").Clear(); err != nil {
+			return err
+		}
+		if len(req.TagIDs) > 0 {
+			var tags []models.Tag
+			if err := tx.Where("id IN ?", req.TagIDs).Find(&tags).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("Tags").Append(tags); err != nil {
+				return err
+			}
+		}
+
+		// Mettre à jour les groupes cibles
+		if err := tx.Model(&event).Association("TargetGroups").Clear(); err != nil {
+			return err
+		}
+		if len(req.TargetGroupIDs) > 0 {
+			var groups []models.Group
+			if err := tx.Where("id IN ?", req.TargetGroupIDs).Find(&groups).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&event).Association("TargetGroups").Append(groups); err != nil {
+				return err
+			}
+		}
 
-	// Mettre à jour les groupes cibles
-	h.db.Model(&event).Association("TargetGroups").Clear()
-	if len(req.TargetGroupIDs) > 0 {
-		var groups []models.Group
-		h.db.Where("id IN ?", req.TargetGroupIDs).Find(&groups)
-		h.db.Model(&event).Association("TargetGroups").Append(groups)
+		// Historique des versions
+		return h.recordRevision(tx, &event, userID, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour"})
+		return
 	}
 
 	// Recharger avec les relations
@@ -271,13 +262,13 @@ func (h *EventsHandler) DeleteEventGroupAdmin(c *gin.Context) {
 
 	// Vérification des permissions
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermEventsManage)
 
 	// Vérifier si le group admin peut gérer cet événement
 	canManage := false
 
-	// 1. Si c'est son propre événement
-	if event.AuthorID == userID {
+	// 1. Si c'est son propre événement (ou gestion globale des événements)
+	if event.AuthorID == userID || middleware.HasPermission(c, models.PermEventsManage) {
 		canManage = true
 	} else {
 		// 2. Si l'événement cible un de ses groupes gérés
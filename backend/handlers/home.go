@@ -7,18 +7,21 @@ import (
 	"sync"
 	"time"
 
+	"airboard/middleware"
 	"airboard/models"
+	"airboard/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type HomeHandler struct {
-	db *gorm.DB
+	db              *gorm.DB
+	recommendations *services.RecommendationService
 }
 
 func NewHomeHandler(db *gorm.DB) *HomeHandler {
-	return &HomeHandler{db: db}
+	return &HomeHandler{db: db, recommendations: services.NewRecommendationService(db)}
 }
 
 // Response structures
@@ -50,19 +53,19 @@ type GamificationSummary struct {
 }
 
 type HomeResponse struct {
-	FavoriteApps    []models.Application  `json:"favorite_apps"`
-	NewApps         []models.Application  `json:"new_apps"`
-	TodayEvents     []models.Event        `json:"today_events"`
-	UpcomingEvents  []models.Event        `json:"upcoming_events"`
-	RecentNews      []models.News         `json:"recent_news"`
-	Polls           []models.Poll         `json:"polls"`
-	Announcements   []models.Announcement `json:"announcements"`
-	Stats           *HomeStats            `json:"stats,omitempty"`
-	Gamification    *GamificationSummary  `json:"gamification,omitempty"`
-	UserRole        string                `json:"user_role"`
-	ManagedGroupIDs []uint                `json:"managed_group_ids,omitempty"`
-	AppSettings     *models.AppSettings   `json:"app_settings,omitempty"`
-	HeroMessages    []models.HeroMessage  `json:"hero_messages,omitempty"`
+	FavoriteApps    []models.Application        `json:"favorite_apps"`
+	NewApps         []models.Application        `json:"new_apps"`
+	TodayEvents     []models.Event              `json:"today_events"`
+	UpcomingEvents  []models.Event              `json:"upcoming_events"`
+	RecentNews      []models.NewsRecommendation `json:"recent_news"`
+	Polls           []models.Poll               `json:"polls"`
+	Announcements   []models.Announcement       `json:"announcements"`
+	Stats           *HomeStats                  `json:"stats,omitempty"`
+	Gamification    *GamificationSummary        `json:"gamification,omitempty"`
+	UserRole        string                      `json:"user_role"`
+	ManagedGroupIDs []uint                      `json:"managed_group_ids,omitempty"`
+	AppSettings     *models.AppSettings         `json:"app_settings,omitempty"`
+	HeroMessages    []models.HeroMessage        `json:"hero_messages,omitempty"`
 }
 
 // Main handler
@@ -149,13 +152,14 @@ func (h *HomeHandler) GetHomeData(c *gin.Context) {
 	}()
 
 	// 5. Load Recent News
+	allNews := middleware.HasPermission(c, models.PermNewsManage)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		news, err := h.getRecentNews(userID.(uint), role.(string))
+		news, err := h.getRecentNews(userID.(uint), allNews)
 		if err != nil {
 			log.Printf("[HOME] Failed to load recent news: %v", err)
-			news = []models.News{}
+			news = []models.NewsRecommendation{}
 		}
 		mu.Lock()
 		response.RecentNews = news
@@ -324,102 +328,29 @@ func (h *HomeHandler) getUpcomingEvents(userID uint, role string) ([]models.Even
 	return events, err
 }
 
-// Helper: Get recent news (last 5 published articles filtered by user groups)
-func (h *HomeHandler) getRecentNews(userID uint, role string) ([]models.News, error) {
-	var news []models.News
-	var err error
-
-	// Les admins voient tous les articles publiés
-	if role == "admin" {
-		err = h.db.Where("is_published = ?", true).
-			Preload("Author").
-			Preload("Category").
-			Preload("Tags").
-			Preload("TargetGroups").
-			Order("is_pinned DESC, published_at DESC").
-			Limit(5).
-			Find(&news).Error
-
-		if err != nil {
-			return news, err
-		}
-	} else {
-		// Récupérer les groupes administrés ET les groupes d'appartenance
+// Helper: Get recent news (5 articles publiés visibles, classés selon les centres d'intérêt de l'utilisateur)
+func (h *HomeHandler) getRecentNews(userID uint, allNews bool) ([]models.NewsRecommendation, error) {
+	// Les gestionnaires des news voient tous les articles publiés
+	scope := services.RecommendationScope{UserID: userID, AllNews: allNews}
+	if !scope.AllNews {
+		// Groupes administrés ET groupes d'appartenance
 		var managedGroupIDs []uint
 		h.db.Table("group_admins").Where("user_id = ?", userID).Pluck("group_id", &managedGroupIDs)
 
 		var userGroupIDs []uint
 		h.db.Table("user_groups").Where("user_id = ?", userID).Pluck("group_id", &userGroupIDs)
 
-		// Combiner les deux listes (appartenance + administration)
-		allGroupIDs := make(map[uint]bool)
-		for _, id := range userGroupIDs {
-			allGroupIDs[id] = true
-		}
-		for _, id := range managedGroupIDs {
-			allGroupIDs[id] = true
-		}
-
-		var combinedGroupIDs []uint
-		for id := range allGroupIDs {
-			combinedGroupIDs = append(combinedGroupIDs, id)
-		}
-
-		if len(combinedGroupIDs) > 0 {
-			// L'utilisateur appartient à des groupes ou en administre
-			// Afficher les articles globaux (sans target_groups) OU les articles ciblant ses groupes
-			err = h.db.Where("is_published = ?", true).
-				Where(`
-					(SELECT COUNT(*) FROM news_target_groups WHERE news_target_groups.news_id = news.id) = 0
-					OR EXISTS (
-						SELECT 1 FROM news_target_groups
-						WHERE news_target_groups.news_id = news.id
-						AND news_target_groups.group_id IN (?)
-					)
-				`, combinedGroupIDs).
-				Preload("Author").
-				Preload("Category").
-				Preload("Tags").
-				Preload("TargetGroups").
-				Order("is_pinned DESC, published_at DESC").
-				Limit(5).
-				Find(&news).Error
-
-			if err != nil {
-				return news, err
-			}
-		} else {
-			// L'utilisateur n'appartient à aucun groupe : seulement les articles globaux
-			err = h.db.Where("is_published = ?", true).
-				Where("(SELECT COUNT(*) FROM news_target_groups WHERE news_target_groups.news_id = news.id) = 0").
-				Preload("Author").
-				Preload("Category").
-				Preload("Tags").
-				Preload("TargetGroups").
-				Order("is_pinned DESC, published_at DESC").
-				Limit(5).
-				Find(&news).Error
-
-			if err != nil {
-				return news, err
-			}
-		}
+		scope.GroupIDs = uniqueGroupIDs(append(userGroupIDs, managedGroupIDs...))
 	}
 
-	// Ajouter les compteurs de commentaires et réactions pour chaque article
-	for i := range news {
-		// Compter les commentaires
-		var commentCount int64
-		h.db.Model(&models.Comment{}).Where("entity_type = ? AND entity_id = ? AND status = ?", "news", news[i].ID, "approved").Count(&commentCount)
-		news[i].CommentCount = int(commentCount)
-
-		// Compter les réactions
-		var reactionCount int64
-		h.db.Model(&models.Feedback{}).Where("entity_type = ? AND entity_id = ?", "news", news[i].ID).Count(&reactionCount)
-		news[i].ReactionCount = int(reactionCount)
+	// Les articles déjà lus restent affichés, après les articles non lus de pertinence comparable
+	news, err := h.recommendations.Recommend(scope, services.RecommendationParams{Limit: 5, IncludeRead: true})
+	if err != nil {
+		return news, err
 	}
 
-	return news, err
+	countNewsInteractions(h.db, news)
+	return news, nil
 }
 
 // Helper: Get recent polls (last 10 polls - active and closed)
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ImpersonationHandler sessions d'usurpation d'identité (support aux utilisateurs)
+type ImpersonationHandler struct {
+	db             *gorm.DB
+	authMiddleware *middleware.AuthMiddleware
+	audit          *services.AuditService
+}
+
+// NewImpersonationHandler crée une nouvelle instance de ImpersonationHandler
+func NewImpersonationHandler(db *gorm.DB, authMiddleware *middleware.AuthMiddleware) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		db:             db,
+		authMiddleware: authMiddleware,
+		audit:          services.NewAuditService(db),
+	}
+}
+
+// StartImpersonation ouvre une session d'usurpation et émet un jeton d'accès limité dans le temps
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	var req models.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Un motif (5 caractères minimum) est requis",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	impersonatorID := c.GetUint("user_id")
+	var impersonator models.User
+	if err := h.db.First(&impersonator, impersonatorID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Utilisateur non authentifié",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var target models.User
+	if err := h.db.First(&target, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Utilisateur non trouvé",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	middleware.SetAuditAction(c, models.AuditActionImpersonationStart)
+	middleware.SetAuditTarget(c, "users", target.ID, target.Username)
+
+	if target.ID == impersonator.ID || target.IsServiceAccount || !target.IsActive {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_target",
+			Message: "Impossible d'usurper son propre compte, un compte de service ou un compte désactivé",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Pas d'élévation de privilèges : le compte cible ne doit rien permettre de plus que le compte de l'administrateur
+	var targetGroupIDs []uint
+	h.db.Table("group_admins").Where("user_id = ?", target.ID).Pluck("group_id", &targetGroupIDs)
+	targetPermissions := h.authMiddleware.Permissions().Resolve(target.ID, target.Role, targetGroupIDs)
+	if !middleware.GetPermissions(c).Covers(targetPermissions) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Le compte cible dispose de permissions que vous n'avez pas",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	duration := req.DurationMinutes
+	if duration == 0 {
+		duration = models.ImpersonationDefaultMinutes
+	}
+	if duration > models.ImpersonationMaxMinutes {
+		duration = models.ImpersonationMaxMinutes
+	}
+
+	now := time.Now()
+	session := models.ImpersonationSession{
+		ImpersonatorID: impersonator.ID,
+		TargetUserID:   target.ID,
+		Reason:         req.Reason,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		StartedAt:      now,
+		ExpiresAt:      now.Add(time.Duration(duration) * time.Minute),
+	}
+	if err := h.db.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de l'ouverture de la session",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	token, err := h.authMiddleware.GenerateImpersonationToken(&target, &impersonator, &session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "token_error",
+			Message: "Erreur lors de la génération du jeton",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	middleware.SetAuditDetails(c, fmt.Sprintf("Session #%d (%d min) : %s", session.ID, duration, req.Reason))
+
+	target.Password = ""
+	target.Impersonation = &models.ImpersonationInfo{
+		SessionID:            session.ID,
+		ImpersonatorID:       impersonator.ID,
+		ImpersonatorUsername: impersonator.Username,
+		ExpiresAt:            session.ExpiresAt,
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"token":      token,
+		"expires_at": session.ExpiresAt,
+		"session":    session,
+		"user":       target,
+	})
+}
+
+// StopImpersonation met fin à la session d'usurpation en cours (appelée avec le jeton d'usurpation)
+func (h *ImpersonationHandler) StopImpersonation(c *gin.Context) {
+	if !middleware.IsImpersonating(c) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "not_impersonating",
+			Message: "Aucune session d'usurpation en cours",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	sessionID := c.GetUint(middleware.ImpersonationIDKey)
+	now := time.Now()
+	if err := h.db.Model(&models.ImpersonationSession{}).
+		Where("id = ? AND ended_at IS NULL", sessionID).
+		Update("ended_at", now).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la fermeture de la session",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	entry := middleware.NewAuditEntry(c, models.AuditActionImpersonationStop)
+	entry.Action = models.AuditActionImpersonationStop
+	entry.TargetType = "users"
+	entry.TargetID = strconv.FormatUint(uint64(c.GetUint("user_id")), 10)
+	entry.TargetLabel = c.GetString("username")
+	entry.Details = fmt.Sprintf("Session #%d arrêtée par %s", sessionID, c.GetString(middleware.ImpersonatorNameKey))
+	entry.StatusCode = http.StatusOK
+	entry.Success = true
+	h.audit.Record(entry)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Session d'usurpation terminée",
+	})
+}
+
+// ListSessions retourne l'historique des sessions d'usurpation (?active=true pour les sessions en cours)
+func (h *ImpersonationHandler) ListSessions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	query := h.db.Model(&models.ImpersonationSession{})
+	if c.Query("active") == "true" {
+		query = query.Where("ended_at IS NULL AND expires_at > ?", time.Now())
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("target_user_id = ? OR impersonator_id = ?", userID, userID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var sessions []models.ImpersonationSession
+	if err := query.
+		Preload("Impersonator", func(db *gorm.DB) *gorm.DB { return db.Select("id", "username", "email", "first_name", "last_name") }).
+		Preload("TargetUser", func(db *gorm.DB) *gorm.DB { return db.Select("id", "username", "email", "first_name", "last_name") }).
+		Order("started_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des sessions",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       sessions,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// EndSession met fin à une session d'usurpation depuis l'administration (le jeton devient inutilisable)
+func (h *ImpersonationHandler) EndSession(c *gin.Context) {
+	var session models.ImpersonationSession
+	if err := h.db.First(&session, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Session introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	middleware.SetAuditAction(c, models.AuditActionImpersonationStop)
+	middleware.SetAuditTarget(c, "impersonation_sessions", session.ID, fmt.Sprintf("Session #%d", session.ID))
+	if !session.IsActive() {
+		c.JSON(http.StatusOK, models.SuccessResponse{
+			Message: "Session déjà terminée",
+		})
+		return
+	}
+
+	now := time.Now()
+	endedBy := c.GetUint("user_id")
+	if err := h.db.Model(&session).Updates(map[string]interface{}{
+		"ended_at":    now,
+		"ended_by_id": endedBy,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la fermeture de la session",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Session d'usurpation terminée",
+	})
+}
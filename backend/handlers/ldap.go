@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"airboard/models"
+	"airboard/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LDAPHandler struct {
+	db          *gorm.DB
+	ldapService *services.LDAPService
+}
+
+func NewLDAPHandler(db *gorm.DB, ldapService *services.LDAPService) *LDAPHandler {
+	return &LDAPHandler{
+		db:          db,
+		ldapService: ldapService,
+	}
+}
+
+// GetConfig retourne la configuration LDAP (sans le mot de passe du compte de service)
+func (h *LDAPHandler) GetConfig(c *gin.Context) {
+	conf, err := h.ldapService.GetConfig()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Retourner une config vide avec les valeurs par défaut
+		c.JSON(http.StatusOK, gin.H{"config": models.LDAPConfig{
+			Port:                389,
+			UserFilter:          "(&(objectClass=person)(|(sAMAccountName={username})(uid={username})(mail={username})))",
+			SyncFilter:          "(&(objectClass=person)(mail=*))",
+			UsernameAttribute:   "sAMAccountName",
+			EmailAttribute:      "mail",
+			FirstNameAttribute:  "givenName",
+			LastNameAttribute:   "sn",
+			DepartmentAttribute: "department",
+			JobTitleAttribute:   "title",
+			PhoneAttribute:      "telephoneNumber",
+			LocationAttribute:   "l",
+			GroupAttribute:      "memberOf",
+			ManagerAttribute:    "manager",
+			UniqueIDAttribute:   "objectGUID",
+			DefaultRole:         "user",
+			SyncIntervalMinutes: 60,
+			DeactivateMissing:   true,
+		}, "bind_password_set": false})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération de la configuration LDAP",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"config":            conf,
+		"bind_password_set": conf.BindPassword != "",
+	})
+}
+
+// UpdateConfig crée ou met à jour la configuration LDAP et ses règles de mapping de groupes
+func (h *LDAPHandler) UpdateConfig(c *gin.Context) {
+	var req models.LDAPConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var conf models.LDAPConfig
+	h.db.First(&conf)
+
+	conf.IsEnabled = req.IsEnabled
+	conf.Host = req.Host
+	conf.Port = req.Port
+	conf.UseTLS = req.UseTLS
+	conf.UseStartTLS = req.UseStartTLS
+	conf.SkipTLSVerify = req.SkipTLSVerify
+	conf.BindDN = req.BindDN
+	conf.BaseDN = req.BaseDN
+	conf.UserFilter = defaultString(req.UserFilter, conf.UserFilter, "(&(objectClass=person)(|(sAMAccountName={username})(uid={username})(mail={username})))")
+	conf.SyncFilter = defaultString(req.SyncFilter, conf.SyncFilter, "(&(objectClass=person)(mail=*))")
+	conf.UsernameAttribute = defaultString(req.UsernameAttribute, conf.UsernameAttribute, "sAMAccountName")
+	conf.EmailAttribute = defaultString(req.EmailAttribute, conf.EmailAttribute, "mail")
+	conf.FirstNameAttribute = req.FirstNameAttribute
+	conf.LastNameAttribute = req.LastNameAttribute
+	conf.DepartmentAttribute = req.DepartmentAttribute
+	conf.JobTitleAttribute = req.JobTitleAttribute
+	conf.PhoneAttribute = req.PhoneAttribute
+	conf.LocationAttribute = req.LocationAttribute
+	conf.GroupAttribute = req.GroupAttribute
+	conf.ManagerAttribute = req.ManagerAttribute
+	conf.UniqueIDAttribute = req.UniqueIDAttribute
+	conf.AdminGroups = req.AdminGroups
+	conf.DefaultRole = defaultString(req.DefaultRole, "", "user")
+	conf.SyncEnabled = req.SyncEnabled
+	conf.SyncIntervalMinutes = req.SyncIntervalMinutes
+	if conf.SyncIntervalMinutes == 0 {
+		conf.SyncIntervalMinutes = 60
+	}
+	if req.DeactivateMissing != nil {
+		conf.DeactivateMissing = *req.DeactivateMissing
+	} else if conf.ID == 0 {
+		conf.DeactivateMissing = true
+	}
+	if conf.Port == 0 {
+		conf.Port = 389
+		if conf.UseTLS {
+			conf.Port = 636
+		}
+	}
+
+	if req.BindPassword != "" {
+		encrypted, err := h.ldapService.EncryptSecret(req.BindPassword)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "encryption_error",
+				Message: "Erreur lors du chiffrement du mot de passe",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		conf.BindPassword = encrypted
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("GroupMappings").Save(&conf).Error; err != nil {
+			return err
+		}
+		if req.GroupMappings == nil {
+			return nil
+		}
+		// Remplacer les règles de mapping
+		if err := tx.Where("ldap_config_id = ?", conf.ID).Delete(&models.LDAPGroupMapping{}).Error; err != nil {
+			return err
+		}
+		for _, m := range req.GroupMappings {
+			if err := tx.Create(&models.LDAPGroupMapping{
+				LDAPConfigID: conf.ID,
+				LDAPGroup:    m.LDAPGroup,
+				GroupID:      m.GroupID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de l'enregistrement de la configuration LDAP",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	updated, _ := h.ldapService.GetConfig()
+	c.JSON(http.StatusOK, gin.H{
+		"config":            updated,
+		"bind_password_set": updated != nil && updated.BindPassword != "",
+	})
+}
+
+// TestConnection teste la connexion à l'annuaire et, si fourni, l'authentification d'un utilisateur
+func (h *LDAPHandler) TestConnection(c *gin.Context) {
+	var req models.LDAPTestRequest
+	_ = c.ShouldBindJSON(&req)
+
+	conf, err := h.ldapService.GetConfig()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "not_configured",
+			Message: "Configuration LDAP non trouvée",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	count, err := h.ldapService.TestConnection(conf)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "ldap_error",
+			Message: err.Error(),
+			Code:    http.StatusBadGateway,
+		})
+		return
+	}
+
+	response := gin.H{
+		"success":    true,
+		"user_count": count,
+	}
+
+	if req.Username != "" {
+		user, err := h.ldapService.Authenticate(req.Username, req.Password)
+		if err != nil {
+			response["auth_success"] = false
+			response["auth_error"] = err.Error()
+		} else {
+			response["auth_success"] = true
+			response["user"] = user
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SyncNow lance immédiatement une synchronisation de l'annuaire
+func (h *LDAPHandler) SyncNow(c *gin.Context) {
+	result, err := h.ldapService.Sync()
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, services.ErrLDAPDisabled) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:   "ldap_sync_error",
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// defaultString retourne la première valeur non vide
+func defaultString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LoginSecurityHandler consultation et levée des verrouillages de connexion
+type LoginSecurityHandler struct {
+	db            *gorm.DB
+	loginSecurity *services.LoginSecurityService
+}
+
+// NewLoginSecurityHandler crée une nouvelle instance de LoginSecurityHandler
+func NewLoginSecurityHandler(db *gorm.DB, loginSecurity *services.LoginSecurityService) *LoginSecurityHandler {
+	return &LoginSecurityHandler{
+		db:            db,
+		loginSecurity: loginSecurity,
+	}
+}
+
+// ListLockouts retourne les compteurs d'échecs de connexion (?active=true pour les seuls verrouillages en cours)
+func (h *LoginSecurityHandler) ListLockouts(c *gin.Context) {
+	scope := c.Query("scope")
+	if scope != "" && scope != models.LockoutScopeIP && scope != models.LockoutScopeAccount {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Portée inconnue (ip ou account)",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	lockouts, total, err := h.loginSecurity.ListLockouts(scope, c.Query("active") == "true", (page-1)*pageSize, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des verrouillages",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       lockouts,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// GetLockoutStats retourne la synthèse des verrouillages en cours
+func (h *LoginSecurityHandler) GetLockoutStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.loginSecurity.Stats())
+}
+
+// ClearLockout lève un verrouillage (suppression du compteur)
+func (h *LoginSecurityHandler) ClearLockout(c *gin.Context) {
+	var lockout models.LoginLockout
+	if err := h.db.First(&lockout, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Verrouillage introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	middleware.SetAuditTarget(c, "login_lockouts", lockout.ID, lockout.Scope+":"+lockout.Key)
+	middleware.AuditBefore(c, lockout)
+	if err := h.loginSecurity.ClearLockout(lockout.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la levée du verrouillage",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Verrouillage levé",
+	})
+}
+
+// ClearUserLockouts lève les verrouillages d'un compte utilisateur
+func (h *LoginSecurityHandler) ClearUserLockouts(c *gin.Context) {
+	var user models.User
+	if err := h.db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Utilisateur non trouvé",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	middleware.SetAuditTarget(c, "users", user.ID, user.Username)
+	cleared, err := h.loginSecurity.ClearUserLockouts(&user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la levée des verrouillages",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Verrouillages du compte levés",
+		Data:    gin.H{"cleared": cleared},
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"airboard/middleware"
 	"airboard/models"
 	"airboard/services"
 	"airboard/utils"
@@ -302,7 +303,6 @@ func (h *MediaHandler) DeleteMedia(c *gin.Context) {
 	}
 
 	userID, _ := c.Get("user_id")
-	role, _ := c.Get("role")
 
 	var media models.Media
 	if err := h.db.First(&media, id).Error; err != nil {
@@ -322,8 +322,16 @@ func (h *MediaHandler) DeleteMedia(c *gin.Context) {
 		return
 	}
 
-	// Check permissions: only uploader or admin can delete
-	if media.UploadedBy != userID.(uint) && role.(string) != "admin" {
+	// Only deletions by someone other than the uploader are audited
+	if media.UploadedBy == userID.(uint) {
+		middleware.SkipAudit(c)
+	} else {
+		middleware.SetAuditTarget(c, "media", media.ID, media.Filename)
+		middleware.AuditBefore(c, media)
+	}
+
+	// Check permissions: only uploader or media.delete holders can delete
+	if media.UploadedBy != userID.(uint) && !middleware.HasPermission(c, models.PermMediaDelete) {
 		c.JSON(http.StatusForbidden, models.ErrorResponse{
 			Error:   "forbidden",
 			Message: "You don't have permission to delete this media",
@@ -21,10 +21,12 @@ type NewsHandler struct {
 	db                  *gorm.DB
 	config              *config.Config
 	gamificationService *services.GamificationService
+	revisionService     *services.RevisionService
+	workflow            *services.NewsWorkflowService
 }
 
-func NewNewsHandler(db *gorm.DB, cfg *config.Config, gs *services.GamificationService) *NewsHandler {
-	return &NewsHandler{db: db, config: cfg, gamificationService: gs}
+func NewNewsHandler(db *gorm.DB, cfg *config.Config, gs *services.GamificationService, workflow *services.NewsWorkflowService) *NewsHandler {
+	return &NewsHandler{db: db, config: cfg, gamificationService: gs, revisionService: services.NewRevisionService(db), workflow: workflow}
 }
 
 // GetNews - Liste des news (accessible à tous les utilisateurs connectés)
@@ -62,6 +64,10 @@ func (h *NewsHandler) GetNews(c *gin.Context) {
 		query = query.Where("type = ?", newsType)
 	}
 
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
 	// Filtre par tags (supporte plusieurs tags séparés par des virgules)
 	if tags := c.Query("tags"); tags != "" {
 		tagIDs := strings.Split(tags, ",")
@@ -106,12 +112,11 @@ func (h *NewsHandler) GetNews(c *gin.Context) {
 		query = query.Where("title ILIKE ? OR summary ILIKE ?", "%"+sanitizedSearch+"%", "%"+sanitizedSearch+"%")
 	}
 
-	// Filtre published only et visibilité par groupes selon le rôle
-	userRole := c.GetString("role")
+	// Filtre published only et visibilité par groupes selon les permissions
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermNewsManage)
 
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermNewsManage) {
 		// Admin voit tout (publié + brouillons)
 	} else if len(managedGroupIDs) > 0 {
 		// Group admin (utilisateur qui administre au moins un groupe) voit UNIQUEMENT dans l'interface d'administration :
@@ -179,7 +184,7 @@ func (h *NewsHandler) GetNews(c *gin.Context) {
 				`, userID, true, time.Now())
 			}
 		}
-	} else if userRole == "editor" {
+	} else if middleware.HasAnyPermission(c, models.PermNewsCreate) {
 		// Editor voit : news publiques + ses propres brouillons
 		query = query.Where("(is_published = ? AND (published_at IS NULL OR published_at <= ?)) OR author_id = ?",
 			true, time.Now(), userID)
@@ -265,12 +270,19 @@ func (h *NewsHandler) GetNewsBySlug(c *gin.Context) {
 		return
 	}
 
-	// Vérifier les permissions selon le rôle
-	userRole := c.GetString("role")
+	// Vérifier les permissions
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermNewsManage)
+
+	// Lecture obligatoire : date de l'accusé de lecture de l'utilisateur
+	if news.RequiresAck {
+		var ack models.ContentAcknowledgement
+		if err := h.db.Where("entity_type = ? AND entity_id = ? AND user_id = ?", models.AckEntityNews, news.ID, userID).First(&ack).Error; err == nil {
+			news.AcknowledgedAt = &ack.AcknowledgedAt
+		}
+	}
 
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermNewsManage) {
 		// Admin voit tout
 		c.JSON(http.StatusOK, news)
 		return
@@ -278,8 +290,14 @@ func (h *NewsHandler) GetNewsBySlug(c *gin.Context) {
 
 	// Vérifier si publié
 	if !news.IsPublished {
-		// Seul l'auteur ou un editor/admin de groupe peut voir un brouillon
-		if (userRole == "editor" || len(managedGroupIDs) > 0) && news.AuthorID == userID {
+		// Seul l'auteur (avec le droit de rédaction) peut voir un brouillon
+		if middleware.HasAnyPermission(c, models.PermNewsCreate) && news.AuthorID == userID {
+			c.JSON(http.StatusOK, news)
+			return
+		}
+		// Les relecteurs voient les articles soumis à relecture, approuvés ou planifiés
+		if news.Status != models.NewsStatusDraft && news.Status != models.NewsStatusArchived &&
+			middleware.HasPermission(c, models.PermNewsPublish) {
 			c.JSON(http.StatusOK, news)
 			return
 		}
@@ -343,6 +361,22 @@ func (h *NewsHandler) CreateNews(c *gin.Context) {
 	// Récupérer l'ID de l'utilisateur connecté
 	userID := c.GetUint("user_id")
 
+	// Vérifier le périmètre : groupes ciblés, catégorie et droit de publication
+	if !middleware.CanTargetGroups(c, models.PermNewsCreate, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Vous ne pouvez cibler que les groupes que vous administrez",
+		})
+		return
+	}
+	if !middleware.CanUseCategory(c, models.PermNewsCreate, req.CategoryID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez pas publier dans cette catégorie"})
+		return
+	}
+	if req.IsPublished && !middleware.CanTargetGroups(c, models.PermNewsPublish, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission news.publish requise pour publier"})
+		return
+	}
+
 	news := models.News{
 		Title:       req.Title,
 		Summary:     req.Summary,
@@ -350,63 +384,51 @@ func (h *NewsHandler) CreateNews(c *gin.Context) {
 		CoverImage:  req.CoverImage,
 		Type:        req.Type,
 		Priority:    req.Priority,
-		IsPinned:    req.IsPinned,
-		IsPublished: req.IsPublished,
+		IsPinned:    req.IsPinned && middleware.HasPermission(c, models.PermNewsManage),
+		Status:      models.NewsStatusDraft, // La publication passe par le workflow éditorial
 		PublishedAt: req.PublishedAt,
 		ExpiresAt:   req.ExpiresAt,
 		CategoryID:  req.CategoryID,
 		AuthorID:    userID,
+		RequiresAck: req.RequiresAck,
+		AckDueAt:    req.AckDueAt,
 	}
 
-	// Si publié sans date, mettre la date actuelle
-	if news.IsPublished && news.PublishedAt == nil {
-		now := time.Now()
-		news.PublishedAt = &now
-	}
-
-	// Créer la news
-	if err := h.db.Create(&news).Error; err != nil {
-		log.Printf("[ERROR CreateNews] Failed to create news: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create news", "details": err.Error()})
-		return
-	}
-
-	// Associer les tags
-	if len(req.TagIDs) > 0 {
-		var tags []models.Tag
-		h.db.Where("id IN ?", req.TagIDs).Find(&tags)
-		h.db.Model(&news).Association("Tags").Replace(tags)
-	}
+	// Créer la news, ses associations et sa première version dans la même transaction
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&news).Error; err != nil {
+			return err
+		}
 
-	// Associer les groupes cibles avec vérification pour group_admin
-	if len(req.TargetGroupIDs) > 0 {
-		userRole := c.GetString("role")
-		managedGroupIDs := middleware.GetManagedGroupIDs(c)
+		// Associer les tags
+		if len(req.TagIDs) > 0 {
+			var tags []models.Tag
+			if err := tx.Where("id IN ?", req.TagIDs).Find(&tags).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&news).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		}
 
-		// Si admin de groupe (non-admin global), vérifier qu'il ne cible que ses groupes
-		if userRole != "admin" && len(managedGroupIDs) > 0 {
-			for _, targetGroupID := range req.TargetGroupIDs {
-				canManage := false
-				for _, managedID := range managedGroupIDs {
-					if targetGroupID == managedID {
-						canManage = true
-						break
-					}
-				}
-				if !canManage {
-					c.JSON(http.StatusForbidden, gin.H{
-						"error": "Vous ne pouvez cibler que les groupes que vous administrez",
-					})
-					// Supprimer la news créée
-					h.db.Delete(&news)
-					return
-				}
+		// Associer les groupes cibles (périmètre vérifié plus haut)
+		if len(req.TargetGroupIDs) > 0 {
+			var groups []models.Group
+			if err := tx.Where("id IN ?", req.TargetGroupIDs).Find(&groups).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&news).Association("TargetGroups").Replace(groups); err != nil {
+				return err
 			}
 		}
 
-		var groups []models.Group
-		h.db.Where("id IN ?", req.TargetGroupIDs).Find(&groups)
-		h.db.Model(&news).Association("TargetGroups").Replace(groups)
+		// Historique des versions
+		return h.recordRevision(tx, &news, userID, nil)
+	})
+	if err != nil {
+		log.Printf("[ERROR CreateNews] Failed to create news: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create news", "details": err.Error()})
+		return
 	}
 
 	// Recharger avec les relations
@@ -416,56 +438,18 @@ func (h *NewsHandler) CreateNews(c *gin.Context) {
 		Preload("TargetGroups").
 		First(&news, news.ID)
 
-	// Envoyer une notification email si l'article est publié
-	if news.IsPublished {
-		go func() {
-			log.Printf("[Email] Tentative d'envoi de notification pour news ID=%d, titre='%s'", news.ID, news.Title)
-			// Utiliser la configuration stockée pour éviter de régénérer le secret JWT
-			emailService := services.NewEmailService(h.db, h.config)
-			var targetGroupIDs []uint
-			for _, g := range news.TargetGroups {
-				targetGroupIDs = append(targetGroupIDs, g.ID)
-			}
-			log.Printf("[Email] Groupes cibles pour news %d: %v", news.ID, targetGroupIDs)
-			if err := emailService.SendNotification("news", news.ID, targetGroupIDs); err != nil {
-				log.Printf("[Email] ❌ ÉCHEC notification news ID=%d: %v", news.ID, err)
-			} else {
-				log.Printf("[Email] ✅ Notification envoyée avec succès pour news ID=%d", news.ID)
-			}
-		}()
-
-		// Créer des notifications pour les utilisateurs
-		go func() {
-			notifService := services.NewNotificationService(h.db)
-
-			// Récupérer les utilisateurs à notifier
-			var userIDs []uint
-			if len(news.TargetGroups) > 0 {
-				// Notifier les membres des groupes cibles
-				var groupIDs []uint
-				for _, g := range news.TargetGroups {
-					groupIDs = append(groupIDs, g.ID)
-				}
-				h.db.Table("user_groups").
-					Where("group_id IN ?", groupIDs).
-					Distinct("user_id").
-					Pluck("user_id", &userIDs)
-			} else {
-				// Article global : notifier tous les utilisateurs actifs
-				h.db.Model(&models.User{}).
-					Where("is_active = ?", true).
-					Where("id != ?", userID). // Ne pas notifier l'auteur
-					Pluck("id", &userIDs)
-			}
-
-			// Envoyer la notification
-			if len(userIDs) > 0 {
-				authorName := news.Author.FirstName + " " + news.Author.LastName
-				if err := notifService.NotifyNewArticle(news.Title, news.Slug, authorName, userIDs); err != nil {
-					log.Printf("[Notification] Échec de l'envoi de la notification: %v", err)
-				}
-			}
-		}()
+	// Workflow éditorial : publication (immédiate ou planifiée) ou soumission en relecture
+	h.workflow.Record(news.ID, &userID, models.NewsActionCreate, "", models.NewsStatusDraft, "")
+	if req.IsPublished {
+		var err error
+		if h.needsApproval(c) {
+			err = h.workflow.Submit(&news, &news.Author, nil, "")
+		} else {
+			err = h.workflow.Publish(&news, userID, req.PublishedAt)
+		}
+		if err != nil {
+			log.Printf("[Workflow] Erreur publication de l'article %d: %v", news.ID, err)
+		}
 	}
 
 	// Award Contributor XP
@@ -490,42 +474,10 @@ func (h *NewsHandler) UpdateNews(c *gin.Context) {
 
 	// Vérifier les permissions
 	// - admin peut tout modifier
-	// - editor/group_admin peut modifier ses propres news
+	// - l'auteur peut modifier ses propres news
 	// - admin de groupe peut modifier les news ciblant les groupes qu'il administre
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-
-	canEdit := false
-	if userRole == "admin" {
-		canEdit = true
-	} else if news.AuthorID == userID {
-		// L'auteur peut modifier sa propre news
-		canEdit = true
-	} else if len(managedGroupIDs) > 0 {
-		// Admin de groupe peut modifier les news ciblant ses groupes administrés
-		// Charger les groupes cibles de la news
-		var newsWithGroups models.News
-		h.db.Preload("TargetGroups").First(&newsWithGroups, news.ID)
-
-		// Si la news n'a pas de groupes cibles, elle est publique → admin de groupe ne peut pas la modifier
-		if len(newsWithGroups.TargetGroups) > 0 {
-			// Vérifier si au moins un groupe cible est administré par l'admin de groupe
-			for _, targetGroup := range newsWithGroups.TargetGroups {
-				for _, managedID := range managedGroupIDs {
-					if targetGroup.ID == managedID {
-						canEdit = true
-						break
-					}
-				}
-				if canEdit {
-					break
-				}
-			}
-		}
-	}
-
-	if !canEdit {
+	if !h.canEditNews(c, &news) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to edit this news"})
 		return
 	}
@@ -536,6 +488,28 @@ func (h *NewsHandler) UpdateNews(c *gin.Context) {
 		return
 	}
 
+	// Vérifier le périmètre des groupes ciblés et de la catégorie
+	if req.TargetGroupIDs != nil && !middleware.CanTargetGroups(c, models.PermNewsCreate, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Vous ne pouvez cibler que les groupes que vous administrez",
+		})
+		return
+	}
+	if !middleware.CanUseCategory(c, models.PermNewsCreate, req.CategoryID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Vous ne pouvez pas publier dans cette catégorie"})
+		return
+	}
+	if req.IsPublished && !news.IsPublished {
+		publishGroupIDs := req.TargetGroupIDs
+		if publishGroupIDs == nil {
+			h.db.Table("news_target_groups").Where("news_id = ?", news.ID).Pluck("group_id", &publishGroupIDs)
+		}
+		if !middleware.CanTargetGroups(c, models.PermNewsPublish, publishGroupIDs) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission news.publish requise pour publier"})
+			return
+		}
+	}
+
 	// Mise à jour des champs
 	news.Title = req.Title
 	news.Summary = req.Summary
@@ -545,60 +519,54 @@ func (h *NewsHandler) UpdateNews(c *gin.Context) {
 	news.Priority = req.Priority
 	news.CategoryID = req.CategoryID
 	news.ExpiresAt = req.ExpiresAt
+	news.RequiresAck = req.RequiresAck
+	news.AckDueAt = req.AckDueAt
 
-	// Seul admin peut épingler
-	if userRole == "admin" {
+	// Seul un gestionnaire global des news peut épingler
+	if middleware.HasPermission(c, models.PermNewsManage) {
 		news.IsPinned = req.IsPinned
 	}
 
-	// Gestion de la publication
-	wasPublished := news.IsPublished
-	news.IsPublished = req.IsPublished
-	if news.IsPublished && !wasPublished && news.PublishedAt == nil {
-		now := time.Now()
-		news.PublishedAt = &now
-	}
+	// La date de publication est appliquée ici, l'état de publication par le workflow éditorial
 	if req.PublishedAt != nil {
 		news.PublishedAt = req.PublishedAt
 	}
 
-	// Sauvegarder
-	if err := h.db.Save(&news).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update news"})
-		return
-	}
+	// Sauvegarder la news, ses associations et la nouvelle version dans la même transaction
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&news).Error; err != nil {
+			return err
+		}
 
-	// Mettre à jour les tags
-	if req.TagIDs != nil {
-		var tags []models.Tag
-		h.db.Where("id IN ?", req.TagIDs).Find(&tags)
-		h.db.Model(&news).Association("Tags").Replace(tags)
-	}
+		// Mettre à jour les tags
+		if req.TagIDs != nil {
+			var tags []models.Tag
+			if err := tx.Where("id IN ?", req.TagIDs).Find(&tags).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&news).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		}
 
-	// Mettre à jour les groupes cibles avec vérification pour les admins de groupe
-	if req.TargetGroupIDs != nil {
-		// Si admin de groupe (non-admin global), vérifier qu'il ne cible que ses groupes
-		if userRole != "admin" && len(managedGroupIDs) > 0 && len(req.TargetGroupIDs) > 0 {
-			for _, targetGroupID := range req.TargetGroupIDs {
-				canManage := false
-				for _, managedID := range managedGroupIDs {
-					if targetGroupID == managedID {
-						canManage = true
-						break
-					}
-				}
-				if !canManage {
-					c.JSON(http.StatusForbidden, gin.H{
-						"error": "Vous ne pouvez cibler que les groupes que vous administrez",
-					})
-					return
-				}
+		// Mettre à jour les groupes cibles (périmètre vérifié plus haut)
+		if req.TargetGroupIDs != nil {
+			var groups []models.Group
+			if err := tx.Where("id IN ?", req.TargetGroupIDs).Find(&groups).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&news).Association("TargetGroups").Replace(groups); err != nil {
+				return err
 			}
 		}
 
-		var groups []models.Group
-		h.db.Where("id IN ?", req.TargetGroupIDs).Find(&groups)
-		h.db.Model(&news).Association("TargetGroups").Replace(groups)
+		// Historique des versions
+		return h.recordRevision(tx, &news, userID, nil)
+	})
+	if err != nil {
+		log.Printf("[ERROR UpdateNews] Failed to update news %d: %v", news.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update news"})
+		return
 	}
 
 	// Recharger avec les relations
@@ -608,6 +576,11 @@ func (h *NewsHandler) UpdateNews(c *gin.Context) {
 		Preload("TargetGroups").
 		First(&news, news.ID)
 
+	// Workflow éditorial
+	if err := h.applyPublication(c, &news, req.IsPublished, req.PublishedAt); err != nil {
+		log.Printf("[Workflow] Erreur publication de l'article %d: %v", news.ID, err)
+	}
+
 	c.JSON(http.StatusOK, news)
 }
 
@@ -630,11 +603,10 @@ func (h *NewsHandler) DeleteNews(c *gin.Context) {
 	// - editor/group_admin peut supprimer ses propres news
 	// - admin de groupe peut supprimer les news ciblant les groupes qu'il administre
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermNewsManage)
 
 	canDelete := false
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermNewsManage) {
 		canDelete = true
 	} else if news.AuthorID == userID {
 		// L'auteur peut supprimer sa propre news
@@ -663,12 +635,12 @@ func (h *NewsHandler) DeleteNews(c *gin.Context) {
 	}
 
 	if !canDelete {
-		log.Printf("[DEBUG DeleteNews] Permission denied for user %d (role=%s) to delete news %d", userID, userRole, news.ID)
+		log.Printf("[DEBUG DeleteNews] Permission denied for user %d (role=%s) to delete news %d", userID, c.GetString("role"), news.ID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to delete this news"})
 		return
 	}
 
-	log.Printf("[DEBUG DeleteNews] Deleting news ID=%d by user %d (role=%s)", news.ID, userID, userRole)
+	log.Printf("[DEBUG DeleteNews] Deleting news ID=%d by user %d (role=%s)", news.ID, userID, c.GetString("role"))
 	if err := h.db.Delete(&news).Error; err != nil {
 		log.Printf("[ERROR DeleteNews] Failed to delete news %d: %v", news.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete news"})
@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkflow retourne l'état éditorial d'une news : relecteurs, décisions et historique
+func (h *NewsHandler) GetWorkflow(c *gin.Context) {
+	news, ok := h.findNews(c)
+	if !ok {
+		return
+	}
+	if !h.canEditNews(c, news) && !h.canReviewNews(c, news) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Vous n'avez pas accès au workflow de cette news",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+	h.respondWorkflow(c, news, "")
+}
+
+// ListReviewers liste les utilisateurs pouvant être assignés comme relecteurs
+func (h *NewsHandler) ListReviewers(c *gin.Context) {
+	reviewers, err := h.workflow.Reviewers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des relecteurs",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	result := make([]gin.H, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if reviewer.ID == userID {
+			continue
+		}
+		result = append(result, gin.H{
+			"id":         reviewer.ID,
+			"username":   reviewer.Username,
+			"first_name": reviewer.FirstName,
+			"last_name":  reviewer.LastName,
+			"email":      reviewer.Email,
+		})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ReviewQueue liste les news en attente de relecture par l'utilisateur connecté
+// (assignées à lui ou sans relecteur assigné ; toutes pour un gestionnaire des news)
+func (h *NewsHandler) ReviewQueue(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	query := h.db.Preload("Author").
+		Preload("Category").
+		Preload("TargetGroups").
+		Preload("Reviewers.Reviewer").
+		Where("status = ? AND author_id != ?", models.NewsStatusInReview, userID)
+
+	if !middleware.HasPermission(c, models.PermNewsManage) {
+		query = query.Where(`
+			EXISTS (SELECT 1 FROM news_reviewers WHERE news_reviewers.news_id = news.id AND news_reviewers.reviewer_id = ?)
+			OR NOT EXISTS (SELECT 1 FROM news_reviewers WHERE news_reviewers.news_id = news.id)
+		`, userID)
+	}
+
+	var news []models.News
+	if err := query.Order("updated_at ASC").Find(&news).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des news à relire",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, news)
+}
+
+// SubmitNews soumet une news en relecture
+func (h *NewsHandler) SubmitNews(c *gin.Context) {
+	var req models.NewsSubmitRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	submitter, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workflow.Submit(news, submitter, req.ReviewerIDs, req.Comment); err != nil {
+		respondWorkflowError(c, err)
+		return
+	}
+	h.respondWorkflow(c, news, "News soumise en relecture")
+}
+
+// AssignReviewers remplace les relecteurs assignés à une news
+func (h *NewsHandler) AssignReviewers(c *gin.Context) {
+	var req models.NewsReviewersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWorkflowValidation(c)
+		return
+	}
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workflow.AssignReviewers(news, c.GetUint("user_id"), req.ReviewerIDs); err != nil {
+		respondWorkflowError(c, err)
+		return
+	}
+	h.respondWorkflow(c, news, "Relecteurs mis à jour")
+}
+
+// ApproveNews approuve une news en relecture
+func (h *NewsHandler) ApproveNews(c *gin.Context) {
+	h.reviewDecision(c, true)
+}
+
+// RequestChanges renvoie une news en brouillon avec une demande de modifications
+func (h *NewsHandler) RequestChanges(c *gin.Context) {
+	h.reviewDecision(c, false)
+}
+
+// PublishNews publie une news immédiatement ou à la date indiquée (publication planifiée)
+func (h *NewsHandler) PublishNews(c *gin.Context) {
+	var req models.NewsPublishRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	if !middleware.CanTargetGroups(c, models.PermNewsPublish, groupIDsOf(news.TargetGroups)) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Permission news.publish requise pour publier",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+	if h.needsApproval(c) && news.Status != models.NewsStatusApproved && news.Status != models.NewsStatusScheduled {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "approval_required",
+			Message: "Cette news doit être approuvée par un éditeur avant publication",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	if err := h.workflow.Publish(news, c.GetUint("user_id"), req.PublishAt); err != nil {
+		respondWorkflowError(c, err)
+		return
+	}
+	message := "News publiée"
+	if news.Status == models.NewsStatusScheduled {
+		message = "Publication planifiée"
+	}
+	h.respondWorkflow(c, news, message)
+}
+
+// UnscheduleNews annule la publication planifiée d'une news
+func (h *NewsHandler) UnscheduleNews(c *gin.Context) {
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	if err := h.workflow.Unschedule(news, c.GetUint("user_id")); err != nil {
+		respondWorkflowError(c, err)
+		return
+	}
+	h.respondWorkflow(c, news, "Publication planifiée annulée")
+}
+
+// ArchiveNews archive une news
+func (h *NewsHandler) ArchiveNews(c *gin.Context) {
+	var req models.NewsReviewDecisionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	userID := c.GetUint("user_id")
+	if err := h.workflow.Archive(news, &userID, req.Comment); err != nil {
+		respondWorkflowError(c, err)
+		return
+	}
+	h.respondWorkflow(c, news, "News archivée")
+}
+
+// UnarchiveNews remet une news archivée en brouillon
+func (h *NewsHandler) UnarchiveNews(c *gin.Context) {
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	if err := h.workflow.Unarchive(news, c.GetUint("user_id")); err != nil {
+		respondWorkflowError(c, err)
+		return
+	}
+	h.respondWorkflow(c, news, "News remise en brouillon")
+}
+
+// reviewDecision enregistre l'approbation ou la demande de modifications d'un relecteur
+func (h *NewsHandler) reviewDecision(c *gin.Context, approve bool) {
+	var req models.NewsReviewDecisionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	news, ok := h.findNews(c)
+	if !ok {
+		return
+	}
+	if !h.canReviewNews(c, news) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Vous n'êtes pas relecteur de cette news",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+	reviewer, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if approve {
+		if err := h.workflow.Approve(news, reviewer, strings.TrimSpace(req.Comment)); err != nil {
+			respondWorkflowError(c, err)
+			return
+		}
+		h.respondWorkflow(c, news, "News approuvée")
+		return
+	}
+
+	if strings.TrimSpace(req.Comment) == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Un commentaire est requis pour demander des modifications",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if err := h.workflow.RequestChanges(news, reviewer, strings.TrimSpace(req.Comment)); err != nil {
+		respondWorkflowError(c, err)
+		return
+	}
+	h.respondWorkflow(c, news, "Modifications demandées")
+}
+
+// applyPublication fait suivre à une news enregistrée le workflow éditorial selon la demande de publication du formulaire
+func (h *NewsHandler) applyPublication(c *gin.Context, news *models.News, publish bool, publishAt *time.Time) error {
+	userID := c.GetUint("user_id")
+	needsApproval := h.needsApproval(c)
+	if _, err := h.invalidateApproval(c, news); err != nil {
+		return err
+	}
+
+	switch {
+	case publish && (news.Status == models.NewsStatusPublished || news.Status == models.NewsStatusInReview):
+		return nil
+	case publish && news.Status == models.NewsStatusScheduled && publishAt != nil && publishAt.After(time.Now()):
+		// Date de publication planifiée déjà enregistrée
+		return nil
+	case publish && needsApproval && news.Status != models.NewsStatusApproved && news.Status != models.NewsStatusScheduled:
+		submitter, err := h.loadUser(userID)
+		if err != nil {
+			return err
+		}
+		return h.workflow.Submit(news, submitter, nil, "")
+	case publish:
+		return h.workflow.Publish(news, userID, publishAt)
+	case news.Status == models.NewsStatusPublished:
+		return h.workflow.Unpublish(news, userID)
+	}
+	return nil
+}
+
+// invalidateApproval : une news approuvée, planifiée ou publiée modifiée par un rédacteur soumis à approbation doit
+// être relue. Une news publiée est retirée de la publication (retourne true) : la version modifiée n'est jamais en
+// ligne sans relecture.
+func (h *NewsHandler) invalidateApproval(c *gin.Context, news *models.News) (bool, error) {
+	if !h.needsApproval(c) {
+		return false, nil
+	}
+	wasPublished := news.Status == models.NewsStatusPublished
+	if !wasPublished && news.Status != models.NewsStatusApproved && news.Status != models.NewsStatusScheduled {
+		return false, nil
+	}
+	if err := h.workflow.InvalidateApproval(news, c.GetUint("user_id")); err != nil {
+		return false, err
+	}
+	return wasPublished, nil
+}
+
+// needsApproval : la règle d'approbation s'applique aux rédacteurs sans droit de publication global (admins de groupe)
+func (h *NewsHandler) needsApproval(c *gin.Context) bool {
+	return !middleware.HasPermission(c, models.PermNewsPublish) && h.workflow.ApprovalRequired()
+}
+
+// canReviewNews : droit de publication global, pas l'auteur, et relecteur assigné s'il y en a (sauf gestionnaire des news)
+func (h *NewsHandler) canReviewNews(c *gin.Context, news *models.News) bool {
+	userID := c.GetUint("user_id")
+	if !middleware.HasPermission(c, models.PermNewsPublish) || news.AuthorID == userID {
+		return false
+	}
+	if middleware.HasPermission(c, models.PermNewsManage) {
+		return true
+	}
+	var reviewerIDs []uint
+	h.db.Model(&models.NewsReviewer{}).Where("news_id = ?", news.ID).Pluck("reviewer_id", &reviewerIDs)
+	return len(reviewerIDs) == 0 || containsAnyID(reviewerIDs, []uint{userID})
+}
+
+// respondWorkflow renvoie l'état éditorial de la news
+func (h *NewsHandler) respondWorkflow(c *gin.Context, news *models.News, message string) {
+	info, err := h.workflow.Info(news)
+	if err != nil {
+		log.Printf("[Workflow] Erreur lecture du workflow de l'article %d: %v", news.ID, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la lecture du workflow",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	info.ApprovalRequired = h.needsApproval(c)
+	info.CanReview = h.canReviewNews(c, news)
+
+	if message == "" {
+		c.JSON(http.StatusOK, info)
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{Message: message, Data: info})
+}
+
+func (h *NewsHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	user, err := h.loadUser(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "Utilisateur introuvable",
+			Code:    http.StatusUnauthorized,
+		})
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *NewsHandler) loadUser(userID uint) (*models.User, error) {
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// bindOptionalJSON lit le corps JSON s'il est fourni (champs facultatifs)
+func bindOptionalJSON(c *gin.Context, req interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		respondWorkflowValidation(c)
+		return false
+	}
+	return true
+}
+
+func respondWorkflowValidation(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:   "validation_error",
+		Message: "Données invalides",
+		Code:    http.StatusBadRequest,
+	})
+}
+
+func respondWorkflowError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidNewsTransition):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "invalid_transition",
+			Message: "Action impossible dans l'état actuel de la news",
+			Code:    http.StatusConflict,
+		})
+	case errors.Is(err, services.ErrInvalidReviewer):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_reviewer",
+			Message: "Les relecteurs doivent avoir le droit de publication et ne pas être l'auteur",
+			Code:    http.StatusBadRequest,
+		})
+	default:
+		log.Printf("[Workflow] Erreur: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la mise à jour du workflow",
+			Code:    http.StatusInternalServerError,
+		})
+	}
+}
+
+func groupIDsOf(groups []models.Group) []uint {
+	ids := make([]uint, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
+	}
+	return ids
+}
@@ -1,15 +1,19 @@
 package handlers
 
 import (
+	"airboard/config"
 	"airboard/middleware"
 	"airboard/models"
+	"airboard/services"
 	"airboard/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -19,15 +23,31 @@ import (
 
 type OAuthHandler struct {
 	db             *gorm.DB
+	config         *config.Config
 	authMiddleware *middleware.AuthMiddleware
 	stateManager   *utils.OAuthStateManager
+	oidcService    *services.OIDCService
+	ssoMapper      *services.SSOMapper
+	audit          *services.AuditService
 }
 
-func NewOAuthHandler(db *gorm.DB, authMiddleware *middleware.AuthMiddleware) *OAuthHandler {
+// oauthTokenResponse contient les tokens retournés par le token endpoint
+type oauthTokenResponse struct {
+	AccessToken string
+	IDToken     string
+}
+
+var providerNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,49}$`)
+
+func NewOAuthHandler(db *gorm.DB, authMiddleware *middleware.AuthMiddleware, cfg *config.Config) *OAuthHandler {
 	return &OAuthHandler{
 		db:             db,
+		config:         cfg,
 		authMiddleware: authMiddleware,
 		stateManager:   utils.NewOAuthStateManager(),
+		oidcService:    services.NewOIDCService(),
+		ssoMapper:      services.NewSSOMapper(db, cfg),
+		audit:          services.NewAuditService(db),
 	}
 }
 
@@ -115,6 +135,17 @@ func (h *OAuthHandler) UpdateProvider(c *gin.Context) {
 	provider.TokenURL = req.TokenURL
 	provider.UserInfoURL = req.UserInfoURL
 	provider.Scopes = req.Scopes
+	applyOIDCFields(&provider, &req)
+
+	if provider.IsOIDC() && provider.IssuerURL == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Issuer URL is required for OpenID Connect providers",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	h.oidcService.InvalidateIssuer(provider.IssuerURL)
 
 	if err := h.db.Save(&provider).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -131,6 +162,208 @@ func (h *OAuthHandler) UpdateProvider(c *gin.Context) {
 	})
 }
 
+// CreateProvider crée un fournisseur OpenID Connect générique (admin uniquement)
+func (h *OAuthHandler) CreateProvider(c *gin.Context) {
+	var req models.OAuthProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	req.ProviderName = strings.ToLower(strings.TrimSpace(req.ProviderName))
+	if !providerNameRegex.MatchString(req.ProviderName) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Provider name must contain only lowercase letters, digits and dashes",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if req.IssuerURL == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Issuer URL is required for OpenID Connect providers",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.OAuthProvider{}).Where("provider_name = ?", req.ProviderName).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "conflict",
+			Message: "A provider with this name already exists",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	// Vérifier que l'issuer est joignable et valide avant d'enregistrer
+	discovery, err := h.oidcService.Discover(req.IssuerURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "discovery_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	provider := models.OAuthProvider{
+		ProviderName: req.ProviderName,
+		DisplayName:  req.DisplayName,
+		Icon:         req.Icon,
+		IsEnabled:    req.IsEnabled,
+		ClientID:     req.ClientID,
+		ClientSecret: req.ClientSecret,
+		RedirectURI:  req.RedirectURI,
+		AuthURL:      discovery.AuthorizationEndpoint,
+		TokenURL:     discovery.TokenEndpoint,
+		UserInfoURL:  discovery.UserInfoEndpoint,
+		Scopes:       req.Scopes,
+		UsePKCE:      true,
+	}
+	req.ProviderType = "oidc"
+	applyOIDCFields(&provider, &req)
+
+	if provider.Icon == "" {
+		provider.Icon = "mdi:openid"
+	}
+	if provider.Scopes == "" {
+		provider.Scopes = "openid email profile"
+	}
+	if provider.RedirectURI == "" {
+		provider.RedirectURI = h.config.Server.PublicURL + "/auth/oauth/" + provider.ProviderName + "/callback"
+	}
+
+	if err := h.db.Create(&provider).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to create OAuth provider",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Message: "OpenID Connect provider created successfully",
+		Data:    provider,
+	})
+}
+
+// DeleteProvider supprime un fournisseur OpenID Connect générique (admin uniquement)
+func (h *OAuthHandler) DeleteProvider(c *gin.Context) {
+	var provider models.OAuthProvider
+	if err := h.db.First(&provider, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "OAuth provider not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// Les fournisseurs intégrés (Google, Microsoft) peuvent seulement être désactivés
+	if !provider.IsOIDC() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "bad_request",
+			Message: "Built-in providers cannot be deleted, disable them instead",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.db.Delete(&provider).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to delete OAuth provider",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	h.oidcService.InvalidateIssuer(provider.IssuerURL)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "OAuth provider deleted successfully",
+	})
+}
+
+// DiscoverProvider récupère le document de découverte d'un issuer OIDC (admin uniquement)
+// pour pré-remplir le formulaire de configuration
+func (h *OAuthHandler) DiscoverProvider(c *gin.Context) {
+	var req struct {
+		IssuerURL string `json:"issuer_url" binding:"required,url"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	h.oidcService.InvalidateIssuer(req.IssuerURL)
+	discovery, err := h.oidcService.Discover(req.IssuerURL)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "discovery_error",
+			Message: err.Error(),
+			Code:    http.StatusBadGateway,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"discovery": discovery,
+	})
+}
+
+// applyOIDCFields recopie les paramètres OpenID Connect de la requête vers le fournisseur
+func applyOIDCFields(provider *models.OAuthProvider, req *models.OAuthProviderRequest) {
+	if req.ProviderType != "" {
+		provider.ProviderType = req.ProviderType
+	}
+	if req.IssuerURL != "" {
+		provider.IssuerURL = strings.TrimSuffix(strings.TrimSpace(req.IssuerURL), "/")
+	}
+	if req.UsePKCE != nil {
+		provider.UsePKCE = *req.UsePKCE
+	}
+	if req.EmailClaim != "" {
+		provider.EmailClaim = req.EmailClaim
+	}
+	if req.NameClaim != "" {
+		provider.NameClaim = req.NameClaim
+	}
+	provider.GroupsClaim = strings.TrimSpace(req.GroupsClaim)
+	provider.ManagerClaim = strings.TrimSpace(req.ManagerClaim)
+	provider.AdminGroups = strings.TrimSpace(req.AdminGroups)
+	provider.TrustEmail = req.TrustEmail
+}
+
+// resolveEndpoints complète les endpoints d'un fournisseur OIDC depuis son document de découverte
+func (h *OAuthHandler) resolveEndpoints(provider *models.OAuthProvider) error {
+	if !provider.IsOIDC() {
+		return nil
+	}
+
+	discovery, err := h.oidcService.Discover(provider.IssuerURL)
+	if err != nil {
+		return err
+	}
+
+	provider.AuthURL = discovery.AuthorizationEndpoint
+	provider.TokenURL = discovery.TokenEndpoint
+	provider.UserInfoURL = discovery.UserInfoEndpoint
+	return nil
+}
+
 // InitiateOAuth démarre le flux OAuth pour un fournisseur avec protection CSRF renforcée
 func (h *OAuthHandler) InitiateOAuth(c *gin.Context) {
 	providerName := c.Param("provider")
@@ -145,8 +378,34 @@ func (h *OAuthHandler) InitiateOAuth(c *gin.Context) {
 		return
 	}
 
-	// Générer un state et nonce sécurisés
-	state, nonce, err := h.stateManager.GenerateState(providerName, provider.ClientID)
+	if err := h.resolveEndpoints(&provider); err != nil {
+		log.Printf("[OAuth] OIDC discovery failed for %s: %v", providerName, err)
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "discovery_error",
+			Message: "Failed to reach the identity provider",
+			Code:    http.StatusBadGateway,
+		})
+		return
+	}
+
+	params := map[string]string{
+		"client_id":     provider.ClientID,
+		"redirect_uri":  provider.RedirectURI,
+		"response_type": "code",
+		"scope":         provider.Scopes,
+	}
+
+	// Générer un state et nonce sécurisés (+ PKCE pour les fournisseurs OIDC)
+	var state, nonce string
+	var err error
+	if provider.IsOIDC() && provider.UsePKCE {
+		var challenge string
+		state, nonce, challenge, err = h.stateManager.GenerateStateWithPKCE(providerName, provider.ClientID)
+		params["code_challenge"] = challenge
+		params["code_challenge_method"] = "S256"
+	} else {
+		state, nonce, err = h.stateManager.GenerateState(providerName, provider.ClientID)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "state_generation_error",
@@ -157,12 +416,7 @@ func (h *OAuthHandler) InitiateOAuth(c *gin.Context) {
 	}
 
 	// Construire l'URL d'autorisation sécurisée
-	authURL, err := utils.SecureOAuthURL(provider.AuthURL, map[string]string{
-		"client_id":     provider.ClientID,
-		"redirect_uri":  provider.RedirectURI,
-		"response_type": "code",
-		"scope":         provider.Scopes,
-	}, state, nonce)
+	authURL, err := utils.SecureOAuthURL(provider.AuthURL, params, state, nonce)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -275,6 +529,15 @@ func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
 		}
 		
 		log.Printf("[OAuth] State and nonce validation successful for %s", providerName)
+	} else if provider.IsOIDC() {
+		// Les fournisseurs OIDC exigent toujours state + nonce (validation de l'ID token et PKCE)
+		log.Printf("[OAuth] Missing state/nonce for OIDC provider %s", providerName)
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "invalid_state",
+			Message: "Missing state or nonce parameter",
+			Code:    http.StatusForbidden,
+		})
+		return
 	} else {
 		// Pour certains providers (comme Microsoft), on peut continuer sans state/nonce
 		// ATTENTION: Ceci réduit la sécurité CSRF, mais permet l'authentification
@@ -306,9 +569,19 @@ func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
 		}
 	}
 
+	if err := h.resolveEndpoints(&provider); err != nil {
+		log.Printf("[OAuth] OIDC discovery failed for %s: %v", providerName, err)
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "discovery_error",
+			Message: "Failed to reach the identity provider",
+			Code:    http.StatusBadGateway,
+		})
+		return
+	}
+
 	// Échanger le code contre un token
 	log.Printf("[OAuth] Exchanging code for token with %s...", provider.ProviderName)
-	token, err := h.exchangeCodeForToken(provider, code)
+	token, err := h.exchangeCodeForToken(provider, code, h.stateManager.GetCodeVerifier(state))
 	if err != nil {
 		log.Printf("[OAuth] ❌ Error exchanging code for token: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -321,22 +594,46 @@ func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
 	log.Printf("[OAuth] ✅ Token exchange successful")
 
 	// Récupérer les informations utilisateur
-	log.Printf("[OAuth] Fetching user info from %s...", provider.ProviderName)
-	userInfo, err := h.getUserInfo(provider, token)
-	if err != nil {
-		log.Printf("[OAuth] ❌ Error getting user info: %v", err)
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "oauth_error",
-			Message: "Failed to get user information",
-			Code:    http.StatusInternalServerError,
-		})
-		return
+	var userInfo map[string]interface{}
+	if provider.IsOIDC() {
+		log.Printf("[OAuth] Validating ID token from %s...", provider.ProviderName)
+		userInfo, err = h.getOIDCClaims(provider, token, validatedNonce)
+		if err != nil {
+			log.Printf("[OAuth] ❌ ID token validation failed: %v", err)
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "invalid_id_token",
+				Message: "ID token validation failed",
+				Code:    http.StatusUnauthorized,
+			})
+			return
+		}
+	} else {
+		log.Printf("[OAuth] Fetching user info from %s...", provider.ProviderName)
+		userInfo, err = h.getUserInfo(provider, token.AccessToken)
+		if err != nil {
+			log.Printf("[OAuth] ❌ Error getting user info: %v", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "oauth_error",
+				Message: "Failed to get user information",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
 	}
 	log.Printf("[OAuth] ✅ User info retrieved: %v", userInfo["mail"])
 
 	// Créer ou récupérer l'utilisateur
 	log.Printf("[OAuth] Finding or creating user...")
-	user, err := h.findOrCreateOAuthUser(provider.ProviderName, userInfo)
+	user, err := h.findOrCreateOAuthUser(provider, userInfo)
+	if errors.Is(err, errOAuthEmailNotVerified) {
+		recordAuthEvent(h.audit, c, models.AuditActionLoginFailed, nil, "", "oauth:"+providerName+" - email non vérifié")
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "email_not_verified",
+			Message: "L'email n'est pas vérifié par le fournisseur d'identité",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
 	if err != nil {
 		log.Printf("[OAuth] ❌ Error finding or creating user: %v", err)
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -347,6 +644,7 @@ func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
 		return
 	}
 	log.Printf("[OAuth] ✅ User found/created: %s (%s)", user.Username, user.Email)
+	recordAuthEvent(h.audit, c, models.AuditActionLogin, &user, user.Email, "oauth:"+providerName)
 
 	// Mettre à jour la date de dernière connexion
 	now := time.Now()
@@ -356,7 +654,8 @@ func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
 	}
 
 	// Générer les tokens JWT
-	jwtToken, err := h.authMiddleware.GenerateToken(&user)
+	sessionID := middleware.NewSessionID()
+	jwtToken, err := h.authMiddleware.GenerateToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "token_error",
@@ -366,7 +665,7 @@ func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
 		return
 	}
 
-	refreshToken, err := h.authMiddleware.GenerateRefreshToken(&user)
+	refreshToken, err := h.authMiddleware.GenerateRefreshToken(&user, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "token_error",
@@ -404,18 +703,23 @@ func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
 	})
 }
 
-// exchangeCodeForToken échange le code d'autorisation contre un token d'accès
-func (h *OAuthHandler) exchangeCodeForToken(provider models.OAuthProvider, code string) (string, error) {
+// exchangeCodeForToken échange le code d'autorisation contre un token d'accès (et un ID token pour OIDC)
+func (h *OAuthHandler) exchangeCodeForToken(provider models.OAuthProvider, code, codeVerifier string) (*oauthTokenResponse, error) {
 	data := url.Values{}
 	data.Set("grant_type", "authorization_code")
 	data.Set("code", code)
 	data.Set("client_id", provider.ClientID)
-	data.Set("client_secret", provider.ClientSecret)
+	if provider.ClientSecret != "" {
+		data.Set("client_secret", provider.ClientSecret)
+	}
 	data.Set("redirect_uri", provider.RedirectURI)
+	if codeVerifier != "" {
+		data.Set("code_verifier", codeVerifier)
+	}
 
 	req, err := http.NewRequest("POST", provider.TokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -423,30 +727,59 @@ func (h *OAuthHandler) exchangeCodeForToken(provider models.OAuthProvider, code
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("token exchange failed: %s", string(body))
+		return nil, fmt.Errorf("token exchange failed: %s", string(body))
 	}
 
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	accessToken, ok := result["access_token"].(string)
 	if !ok {
-		return "", fmt.Errorf("no access_token in response")
+		return nil, fmt.Errorf("no access_token in response")
 	}
+	idToken, _ := result["id_token"].(string)
 
-	return accessToken, nil
+	return &oauthTokenResponse{AccessToken: accessToken, IDToken: idToken}, nil
+}
+
+// getOIDCClaims valide l'ID token et le complète avec les claims du userinfo endpoint
+func (h *OAuthHandler) getOIDCClaims(provider models.OAuthProvider, token *oauthTokenResponse, nonce string) (map[string]interface{}, error) {
+	claims, err := h.oidcService.VerifyIDToken(token.IDToken, services.IDTokenValidation{
+		IssuerURL: provider.IssuerURL,
+		ClientID:  provider.ClientID,
+		Nonce:     nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Les claims du userinfo ne sont retenus que si le subject correspond (OIDC Core §5.3.2)
+	if provider.UserInfoURL != "" {
+		userInfo, err := h.getUserInfo(provider, token.AccessToken)
+		if err != nil {
+			log.Printf("[OAuth] Userinfo request failed for %s, using ID token claims only: %v", provider.ProviderName, err)
+		} else if sub, _ := userInfo["sub"].(string); sub == claims["sub"] {
+			for key, value := range userInfo {
+				if _, exists := claims[key]; !exists {
+					claims[key] = value
+				}
+			}
+		}
+	}
+
+	return claims, nil
 }
 
 // getUserInfo récupère les informations utilisateur depuis le provider OAuth
@@ -482,18 +815,39 @@ func (h *OAuthHandler) getUserInfo(provider models.OAuthProvider, accessToken st
 	return userInfo, nil
 }
 
+// errOAuthEmailNotVerified indique qu'un compte existant ne peut pas être rattaché sur la foi d'un email non vérifié
+var errOAuthEmailNotVerified = errors.New("email non vérifié par le fournisseur")
+
 // findOrCreateOAuthUser trouve ou crée un utilisateur OAuth
-func (h *OAuthHandler) findOrCreateOAuthUser(providerName string, userInfo map[string]interface{}) (models.User, error) {
+func (h *OAuthHandler) findOrCreateOAuthUser(provider models.OAuthProvider, userInfo map[string]interface{}) (models.User, error) {
 	var email, firstName, lastName, ssoID string
+	providerName := provider.ProviderName
 
 	// Extraire les informations selon le provider
-	switch providerName {
-	case "google":
+	switch {
+	case provider.IsOIDC():
+		email = services.ClaimString(userInfo, provider.EmailClaim)
+		if email == "" {
+			email = services.ClaimString(userInfo, "email")
+		}
+		firstName = services.ClaimString(userInfo, "given_name")
+		lastName = services.ClaimString(userInfo, "family_name")
+		if firstName == "" && lastName == "" {
+			if fullName := services.ClaimString(userInfo, provider.NameClaim); fullName != "" {
+				parts := strings.SplitN(fullName, " ", 2)
+				firstName = parts[0]
+				if len(parts) > 1 {
+					lastName = parts[1]
+				}
+			}
+		}
+		ssoID, _ = userInfo["sub"].(string)
+	case providerName == "google":
 		email, _ = userInfo["email"].(string)
 		firstName, _ = userInfo["given_name"].(string)
 		lastName, _ = userInfo["family_name"].(string)
 		ssoID, _ = userInfo["sub"].(string)
-	case "microsoft":
+	case providerName == "microsoft":
 		email, _ = userInfo["mail"].(string)
 		if email == "" {
 			email, _ = userInfo["userPrincipalName"].(string)
@@ -507,13 +861,17 @@ func (h *OAuthHandler) findOrCreateOAuthUser(providerName string, userInfo map[s
 		return models.User{}, fmt.Errorf("missing required user information")
 	}
 
-	// Chercher l'utilisateur existant par email d'abord, puis par SSO
+	// Chercher l'identité déjà rattachée, puis un compte existant de même email. Le rattachement par
+	// email d'une connexion OIDC exige un email vérifié par le fournisseur : sinon, un email choisi
+	// librement chez l'IdP suffirait à prendre le contrôle d'un compte existant.
 	var user models.User
-	err := h.db.Where("email = ?", email).First(&user).Error
-
+	err := h.db.Where("sso_provider = ? AND sso_id = ?", providerName, ssoID).First(&user).Error
 	if err == gorm.ErrRecordNotFound {
-		// Chercher par SSO ID si pas trouvé par email
-		err = h.db.Where("sso_provider = ? AND sso_id = ?", providerName, ssoID).First(&user).Error
+		err = h.db.Where("email = ?", email).First(&user).Error
+		if err == nil && provider.IsOIDC() && !provider.TrustEmail && !services.ClaimEmailVerified(userInfo) {
+			log.Printf("[OAuth] Rattachement refusé: email %s non vérifié par %s", email, providerName)
+			return models.User{}, errOAuthEmailNotVerified
+		}
 	}
 
 	if err == gorm.ErrRecordNotFound {
@@ -546,6 +904,7 @@ func (h *OAuthHandler) findOrCreateOAuthUser(providerName string, userInfo map[s
 		if err := h.db.Create(&user).Error; err != nil {
 			return models.User{}, err
 		}
+		go services.NewWebhookService(h.db).UserCreated(&user, "oauth")
 
 		// Ajouter au groupe par défaut (depuis les paramètres de l'application)
 		var settings models.AppSettings
@@ -594,7 +953,50 @@ func (h *OAuthHandler) findOrCreateOAuthUser(providerName string, userInfo map[s
 		log.Printf("[OAuth] Existing user logged in: %s (%s) via %s", user.Email, user.Username, providerName)
 	}
 
+	// Synchroniser les groupes et le rôle depuis le claim de groupes (OIDC)
+	if provider.IsOIDC() && provider.GroupsClaim != "" {
+		if err := h.syncOIDCGroups(provider, &user, services.ClaimStrings(userInfo, provider.GroupsClaim)); err != nil {
+			log.Printf("[OAuth] Group sync failed for %s: %v", user.Email, err)
+		}
+	}
+
+	// Rattachement hiérarchique depuis le claim du responsable (OIDC)
+	if provider.IsOIDC() && provider.ManagerClaim != "" {
+		manager := services.ClaimString(userInfo, provider.ManagerClaim)
+		if err := services.NewOrgService(h.db).SyncManagerReference(user.ID, manager, models.ManagerSourceOIDC); err != nil {
+			log.Printf("[OAuth] Manager sync failed for %s: %v", user.Email, err)
+		}
+	}
+
 	return user, nil
 }
 
+// syncOIDCGroups synchronise les groupes Airboard et le rôle admin à partir du claim de groupes
+func (h *OAuthHandler) syncOIDCGroups(provider models.OAuthProvider, user *models.User, groups []string) error {
+	if err := h.ssoMapper.SyncGroups(user, groups); err != nil {
+		return err
+	}
+
+	adminGroups := strings.Split(provider.AdminGroups, ",")
+	isAdmin := false
+	for _, group := range groups {
+		for _, adminGroup := range adminGroups {
+			if adminGroup = strings.TrimSpace(adminGroup); adminGroup != "" && strings.EqualFold(group, adminGroup) {
+				isAdmin = true
+			}
+		}
+	}
+
+	// Le claim de groupes fait autorité sur le rôle admin, sans toucher aux éditeurs
+	if isAdmin && user.Role != "admin" {
+		user.Role = "admin"
+		return h.db.Model(user).Update("role", "admin").Error
+	}
+	if !isAdmin && user.Role == "admin" && provider.AdminGroups != "" {
+		user.Role = h.config.SSO.DefaultRole
+		return h.db.Model(user).Update("role", user.Role).Error
+	}
+	return nil
+}
+
 // generateRandomState function removed - replaced by OAuthStateManager
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Import CSV des responsables
+const (
+	orgImportMaxBytes = 5 << 20
+	orgImportMaxRows  = 20000
+	orgImportMaxErrs  = 200
+)
+
+// Colonnes acceptées dans l'en-tête du fichier d'import
+var (
+	orgImportUserColumns    = []string{"user", "email", "username", "user_email", "user_username"}
+	orgImportManagerColumns = []string{"manager", "manager_email", "manager_username"}
+)
+
+// OrgHandler gère l'organigramme et les rattachements hiérarchiques
+type OrgHandler struct {
+	db  *gorm.DB
+	org *services.OrgService
+}
+
+// NewOrgHandler crée une nouvelle instance de OrgHandler
+func NewOrgHandler(db *gorm.DB, org *services.OrgService) *OrgHandler {
+	return &OrgHandler{db: db, org: org}
+}
+
+// orgError traduit les erreurs du service d'organigramme en réponse HTTP
+func orgError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOrgPersonNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+			Code:    http.StatusNotFound,
+		})
+	case errors.Is(err, services.ErrManagerNotFound), errors.Is(err, services.ErrManagerSelf), errors.Is(err, services.ErrManagerCycle):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+	default:
+		log.Printf("[Org] %s: %v", fallback, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: fallback,
+			Code:    http.StatusInternalServerError,
+		})
+	}
+}
+
+func parseOrgPersonID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "ID invalide",
+			Code:    http.StatusBadRequest,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ============ ORGANIGRAMME ============
+
+// GetChart exporte l'organigramme : organisation entière, branche (root_id) ou département (department),
+// limité à depth niveaux ; format=flat renvoie une liste (id, manager_id) plutôt qu'une arborescence
+func (h *OrgHandler) GetChart(c *gin.Context) {
+	rootID, _ := strconv.ParseUint(c.Query("root_id"), 10, 64)
+	depth, _ := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if depth < 0 {
+		depth = 0
+	}
+
+	chart, err := h.org.Tree(services.OrgTreeParams{
+		RootID:     uint(rootID),
+		Department: strings.TrimSpace(c.Query("department")),
+		Depth:      depth,
+		Flat:       c.Query("format") == "flat",
+	}, directoryViewer(c))
+	if err != nil {
+		orgError(c, err, "Erreur lors du chargement de l'organigramme")
+		return
+	}
+
+	c.JSON(http.StatusOK, chart)
+}
+
+// GetChainOfCommand responsables successifs d'une personne, du responsable direct au sommet
+func (h *OrgHandler) GetChainOfCommand(c *gin.Context) {
+	id, ok := parseOrgPersonID(c)
+	if !ok {
+		return
+	}
+
+	chain, err := h.org.ChainOfCommand(id, directoryViewer(c))
+	if err != nil {
+		orgError(c, err, "Erreur lors du chargement de la chaîne hiérarchique")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"chain": chain})
+}
+
+// GetDirectReports collaborateurs directs d'une personne
+func (h *OrgHandler) GetDirectReports(c *gin.Context) {
+	id, ok := parseOrgPersonID(c)
+	if !ok {
+		return
+	}
+
+	reports, err := h.org.DirectReports(id, directoryViewer(c))
+	if err != nil {
+		orgError(c, err, "Erreur lors du chargement des collaborateurs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reports": reports})
+}
+
+// ============ ADMINISTRATION ============
+
+// SetUserManager rattache un utilisateur à son responsable (admin)
+func (h *OrgHandler) SetUserManager(c *gin.Context) {
+	id, ok := parseOrgPersonID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetManagerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Données invalides",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Utilisateur non trouvé",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	middleware.SetAuditTarget(c, "users", user.ID, user.Username)
+	middleware.AuditBefore(c, gin.H{"manager_id": user.ManagerID, "manager_source": user.ManagerSource})
+
+	if _, err := h.org.SetManager(user.ID, req.ManagerID, models.ManagerSourceAdmin); err != nil {
+		orgError(c, err, "Erreur lors du rattachement au responsable")
+		return
+	}
+
+	h.db.First(&user, user.ID)
+	middleware.AuditAfter(c, gin.H{"manager_id": user.ManagerID, "manager_source": user.ManagerSource})
+	user.Password = ""
+	c.JSON(http.StatusOK, user)
+}
+
+// ImportManagers importe les rattachements depuis un fichier CSV (colonnes user et manager, par email
+// ou identifiant ; manager vide = aucun responsable). dry_run=true valide le fichier sans rien enregistrer.
+func (h *OrgHandler) ImportManagers(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, orgImportMaxBytes)
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_file",
+			Message: "Fichier CSV manquant ou trop volumineux",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_file",
+			Message: "Fichier CSV vide ou illisible",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	userCol, managerCol := csvColumn(header, orgImportUserColumns), csvColumn(header, orgImportManagerColumns)
+	if userCol < 0 || managerCol < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_file",
+			Message: "Colonnes requises : user et manager (email ou identifiant)",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	result := models.OrgImportResult{DryRun: dryRun, Errors: []models.OrgImportError{}}
+	fail := func(line int, userRef, managerRef, message string) {
+		if len(result.Errors) < orgImportMaxErrs {
+			result.Errors = append(result.Errors, models.OrgImportError{Line: line, User: userRef, Manager: managerRef, Message: message})
+		}
+	}
+
+	// Les lignes sont appliquées dans l'ordre au sein d'une transaction : une boucle formée par
+	// plusieurs lignes est détectée, et un essai à blanc est simplement annulé
+	errDryRun := errors.New("dry run")
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		org := services.NewOrgService(tx)
+		for line := 2; ; line++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				if dryRun {
+					return errDryRun
+				}
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("ligne %d: %w", line, err)
+			}
+			if result.Rows >= orgImportMaxRows {
+				return fmt.Errorf("le fichier dépasse %d lignes", orgImportMaxRows)
+			}
+			userRef, managerRef := csvField(record, userCol), csvField(record, managerCol)
+			if userRef == "" && managerRef == "" {
+				continue
+			}
+			result.Rows++
+
+			userID, err := org.ResolveManager(userRef)
+			if err != nil {
+				fail(line, userRef, managerRef, "Utilisateur introuvable")
+				continue
+			}
+			var managerID *uint
+			if managerRef != "" {
+				id, err := org.ResolveManager(managerRef)
+				if err != nil {
+					fail(line, userRef, managerRef, err.Error())
+					continue
+				}
+				managerID = &id
+			}
+
+			changed, err := org.SetManager(userID, managerID, models.ManagerSourceCSV)
+			switch {
+			case errors.Is(err, services.ErrManagerSelf), errors.Is(err, services.ErrManagerCycle),
+				errors.Is(err, services.ErrManagerNotFound), errors.Is(err, services.ErrOrgPersonNotFound):
+				fail(line, userRef, managerRef, err.Error())
+			case err != nil:
+				return err
+			case changed:
+				result.Updated++
+			default:
+				result.Unchanged++
+			}
+		}
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		log.Printf("[Org] Erreur lors de l'import des responsables: %v", err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "import_failed",
+			Message: "Import annulé : " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	middleware.SetAuditDetails(c, fmt.Sprintf("%d ligne(s), %d modifiée(s), %d erreur(s), essai à blanc: %t",
+		result.Rows, result.Updated, len(result.Errors), dryRun))
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportManagers exporte les rattachements au format CSV (réimportable)
+func (h *OrgHandler) ExportManagers(c *gin.Context) {
+	type managerRow struct {
+		Email           string
+		Username        string
+		ManagerEmail    string
+		ManagerUsername string
+		ManagerSource   string
+	}
+	var rows []managerRow
+	if err := h.db.Table("users u").
+		Select("u.email, u.username, m.email AS manager_email, m.username AS manager_username, u.manager_source").
+		Joins("LEFT JOIN users m ON m.id = u.manager_id AND m.deleted_at IS NULL").
+		Where("u.deleted_at IS NULL AND u.is_service_account = ?", false).
+		Order("lower(u.last_name), lower(u.first_name), u.id").
+		Scan(&rows).Error; err != nil {
+		orgError(c, err, "Erreur lors de l'export des responsables")
+		return
+	}
+
+	filename := fmt.Sprintf("managers-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"user", "username", "manager", "manager_username", "source"})
+	for _, row := range rows {
+		writer.Write([]string{
+			csvSafe(row.Email),
+			csvSafe(row.Username),
+			csvSafe(row.ManagerEmail),
+			csvSafe(row.ManagerUsername),
+			row.ManagerSource,
+		})
+	}
+	writer.Flush()
+}
+
+// csvColumn index de la première colonne de l'en-tête portant l'un des noms donnés (-1 si absente)
+func csvColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func csvField(record []string, index int) string {
+	if index >= len(record) {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(record[index]), "'")
+}
@@ -126,13 +126,13 @@ func (h *PollsHandler) GetPolls(c *gin.Context) {
 	// le filtre de visibilité par groupes car on veut UNIQUEMENT les sondages liés à cette entité
 	userRole := c.GetString("role")
 	userID := c.GetUint("user_id")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermPollsManage)
 
 	// Si on filtre par news_id ou announcement_id, ne pas appliquer le filtre de visibilité
 	// car on veut seulement les sondages explicitement liés à cette entité
 	isEntityFilter := newsIDFilter != "" || c.Query("announcement_id") != ""
 
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermPollsManage) {
 		// Admin voit tout
 	} else if !isEntityFilter && len(managedGroupIDs) > 0 {
 		// Utilisateur qui administre au moins un groupe : distinguer interface admin vs interface publique
@@ -273,11 +273,10 @@ func (h *PollsHandler) GetPollByID(c *gin.Context) {
 		return
 	}
 
-	// Vérifier les permissions selon le rôle
-	userRole := c.GetString("role")
+	// Vérifier les permissions
 	userID := c.GetUint("user_id")
 
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermPollsManage) {
 		// Admin voit tout
 		c.JSON(http.StatusOK, poll)
 		return
@@ -287,7 +286,7 @@ func (h *PollsHandler) GetPollByID(c *gin.Context) {
 	hasAccess := false
 
 	// Récupérer les groupes administrés ET les groupes d'appartenance
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermPollsManage)
 	var userGroupIDs []uint
 	h.db.Table("user_groups").Where("user_id = ?", userID).Pluck("group_id", &userGroupIDs)
 
@@ -331,7 +330,6 @@ func (h *PollsHandler) CreatePoll(c *gin.Context) {
 
 	// Récupérer l'ID de l'utilisateur connecté
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
 
 	// Validation : au moins 2 options, max 10
 	if len(req.Options) < 2 || len(req.Options) > 10 {
@@ -339,24 +337,12 @@ func (h *PollsHandler) CreatePoll(c *gin.Context) {
 		return
 	}
 
-	// Vérifier les permissions pour les groupes cibles (admins de groupe non-global)
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
-	if userRole != "admin" && len(managedGroupIDs) > 0 && len(req.TargetGroupIDs) > 0 {
-		for _, targetGroupID := range req.TargetGroupIDs {
-			canManage := false
-			for _, managedID := range managedGroupIDs {
-				if targetGroupID == managedID {
-					canManage = true
-					break
-				}
-			}
-			if !canManage {
-				c.JSON(http.StatusForbidden, gin.H{
-					"error": "Vous ne pouvez cibler que les groupes que vous administrez",
-				})
-				return
-			}
-		}
+	// Vérifier le périmètre des groupes cibles
+	if !middleware.CanTargetGroups(c, models.PermPollsCreate, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Vous ne pouvez cibler que les groupes que vous administrez",
+		})
+		return
 	}
 
 	poll := models.Poll{
@@ -469,11 +455,10 @@ func (h *PollsHandler) UpdatePoll(c *gin.Context) {
 
 	// Vérifier les permissions
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermPollsManage)
 
 	canEdit := false
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermPollsManage) {
 		canEdit = true
 	} else if poll.AuthorID == userID {
 		// L'auteur peut modifier son propre sondage
@@ -506,23 +491,12 @@ func (h *PollsHandler) UpdatePoll(c *gin.Context) {
 		return
 	}
 
-	// Vérifier les permissions pour les nouveaux groupes cibles (admins de groupe non-global)
-	if userRole != "admin" && len(managedGroupIDs) > 0 && len(req.TargetGroupIDs) > 0 {
-		for _, targetGroupID := range req.TargetGroupIDs {
-			canManage := false
-			for _, managedID := range managedGroupIDs {
-				if targetGroupID == managedID {
-					canManage = true
-					break
-				}
-			}
-			if !canManage {
-				c.JSON(http.StatusForbidden, gin.H{
-					"error": "Vous ne pouvez cibler que les groupes que vous administrez",
-				})
-				return
-			}
-		}
+	// Vérifier le périmètre des nouveaux groupes cibles
+	if !middleware.CanTargetGroups(c, models.PermPollsCreate, req.TargetGroupIDs) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Vous ne pouvez cibler que les groupes que vous administrez",
+		})
+		return
 	}
 
 	// Mise à jour des champs du sondage
@@ -536,8 +510,8 @@ func (h *PollsHandler) UpdatePoll(c *gin.Context) {
 	poll.NewsID = req.NewsID
 	poll.AnnouncementID = req.AnnouncementID
 
-	// Seul admin peut activer/désactiver
-	if userRole == "admin" || poll.AuthorID == userID {
+	// Seul un gestionnaire des sondages ou l'auteur peut activer/désactiver
+	if middleware.HasPermission(c, models.PermPollsManage) || poll.AuthorID == userID {
 		poll.IsActive = req.IsActive
 	}
 
@@ -594,11 +568,10 @@ func (h *PollsHandler) DeletePoll(c *gin.Context) {
 
 	// Vérifier les permissions
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
-	managedGroupIDs := middleware.GetManagedGroupIDs(c)
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermPollsManage)
 
 	canDelete := false
-	if userRole == "admin" {
+	if middleware.HasPermission(c, models.PermPollsManage) {
 		canDelete = true
 	} else if poll.AuthorID == userID {
 		canDelete = true
@@ -648,19 +621,24 @@ func (h *PollsHandler) ClosePoll(c *gin.Context) {
 
 	// Vérifier les permissions (admin ou auteur)
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
 
-	if userRole != "admin" && poll.AuthorID != userID {
+	if !middleware.HasPermission(c, models.PermPollsManage) && poll.AuthorID != userID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to close this poll"})
 		return
 	}
 
+	wasActive := poll.IsActive
 	poll.IsActive = false
 	if err := h.db.Save(&poll).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close poll"})
 		return
 	}
 
+	// Webhooks sortants
+	if wasActive {
+		go services.NewWebhookService(h.db).PollClosed(&poll)
+	}
+
 	c.JSON(http.StatusOK, poll)
 }
 
@@ -801,7 +779,6 @@ func (h *PollsHandler) Vote(c *gin.Context) {
 func (h *PollsHandler) GetPollResults(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetUint("user_id")
-	userRole := c.GetString("role")
 
 	var poll models.Poll
 	if err := h.db.Preload("Author").
@@ -814,7 +791,7 @@ func (h *PollsHandler) GetPollResults(c *gin.Context) {
 
 	// Vérifier que l'utilisateur a accès au sondage
 	hasAccess := false
-	if userRole == "admin" || poll.AuthorID == userID {
+	if middleware.HasPermission(c, models.PermPollsManage) || poll.AuthorID == userID {
 		hasAccess = true
 	} else if len(poll.TargetGroups) == 0 {
 		hasAccess = true
@@ -863,7 +840,7 @@ func (h *PollsHandler) GetPollResults(c *gin.Context) {
 	}
 
 	// Admin et auteur peuvent toujours voir les résultats
-	if userRole == "admin" || poll.AuthorID == userID {
+	if middleware.HasPermission(c, models.PermPollsManage) || poll.AuthorID == userID {
 		canSeeResults = true
 	}
 
@@ -904,7 +881,7 @@ func (h *PollsHandler) GetPollResults(c *gin.Context) {
 
 	// Détails des votants (seulement si non anonyme ET admin/auteur)
 	var voterDetails []models.PollVoterDetail
-	if !poll.IsAnonymous && (userRole == "admin" || poll.AuthorID == userID) {
+	if !poll.IsAnonymous && (middleware.HasPermission(c, models.PermPollsManage) || poll.AuthorID == userID) {
 		var votes []models.PollVote
 		h.db.Preload("User").Where("poll_id = ?", poll.ID).Find(&votes)
 		for _, vote := range votes {
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// PrivacyHandler demandes RGPD : export des données personnelles et droit à l'effacement
+type PrivacyHandler struct {
+	db      *gorm.DB
+	privacy *services.PrivacyService
+	audit   *services.AuditService
+}
+
+// NewPrivacyHandler crée une nouvelle instance de PrivacyHandler
+func NewPrivacyHandler(db *gorm.DB, privacy *services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		db:      db,
+		privacy: privacy,
+		audit:   services.NewAuditService(db),
+	}
+}
+
+// GetPolicy retourne la politique d'effacement appliquée à chaque catégorie de données
+func (h *PrivacyHandler) GetPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"erasure": models.PrivacyErasurePolicy,
+	})
+}
+
+// ListMyRequests retourne les demandes RGPD de l'utilisateur connecté
+func (h *PrivacyHandler) ListMyRequests(c *gin.Context) {
+	var requests []models.PrivacyRequest
+	if err := h.db.Where("user_id = ?", c.GetUint("user_id")).Order("created_at DESC").Find(&requests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des demandes",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// RequestExport demande l'export de ses données (archive ZIP générée en arrière-plan)
+func (h *PrivacyHandler) RequestExport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	request, err := h.privacy.CreateRequest(userID, models.PrivacyRequestExport, userID, "", false)
+	if err != nil {
+		h.respondCreateError(c, err)
+		return
+	}
+
+	h.recordSelfService(c, models.AuditActionPrivacyExport, request)
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Message: "Export en cours de préparation, vous serez notifié lorsqu'il sera disponible",
+		Data:    request,
+	})
+}
+
+// RequestErasure demande la suppression de son compte (soumise à la validation d'un administrateur)
+func (h *PrivacyHandler) RequestErasure(c *gin.Context) {
+	var req models.PrivacyErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Données invalides",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Utilisateur non trouvé",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// Confirmation par mot de passe pour les comptes locaux
+	if user.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_password",
+			Message: "Mot de passe incorrect",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	request, err := h.privacy.CreateRequest(user.ID, models.PrivacyRequestErasure, user.ID, req.Reason, true)
+	if err != nil {
+		h.respondCreateError(c, err)
+		return
+	}
+
+	h.recordSelfService(c, models.AuditActionPrivacyErasure, request)
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Message: "Demande de suppression enregistrée, elle sera traitée après validation",
+		Data:    request,
+	})
+}
+
+// DownloadMyExport télécharge l'archive d'une de ses demandes d'export
+func (h *PrivacyHandler) DownloadMyExport(c *gin.Context) {
+	var request models.PrivacyRequest
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), c.GetUint("user_id")).First(&request).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Demande introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	h.sendExport(c, &request)
+}
+
+// ListRequests retourne les demandes RGPD (filtres: type, status, user_id)
+func (h *PrivacyHandler) ListRequests(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	query := h.db.Model(&models.PrivacyRequest{})
+	if requestType := c.Query("type"); requestType != "" {
+		query = query.Where("type = ?", requestType)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var requests []models.PrivacyRequest
+	if err := query.
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "username", "email", "first_name", "last_name")
+		}).
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&requests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des demandes",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       requests,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// ExportUser lance l'export des données d'un utilisateur depuis l'administration
+func (h *PrivacyHandler) ExportUser(c *gin.Context) {
+	user, ok := h.findUser(c)
+	if !ok {
+		return
+	}
+
+	request, err := h.privacy.CreateRequest(user.ID, models.PrivacyRequestExport, c.GetUint("user_id"), "", false)
+	if err != nil {
+		h.respondCreateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Message: "Export en cours de préparation",
+		Data:    request,
+	})
+}
+
+// EraseUser lance l'effacement des données d'un utilisateur depuis l'administration (sans validation supplémentaire)
+func (h *PrivacyHandler) EraseUser(c *gin.Context) {
+	var req models.PrivacyReviewRequest
+	if !h.bindOptional(c, &req) {
+		return
+	}
+
+	user, ok := h.findUser(c)
+	if !ok {
+		return
+	}
+	if !h.canErase(c, user) {
+		return
+	}
+
+	request, err := h.privacy.CreateRequest(user.ID, models.PrivacyRequestErasure, c.GetUint("user_id"), req.Comment, false)
+	if err != nil {
+		h.respondCreateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Message: "Effacement des données en cours",
+		Data:    request,
+	})
+}
+
+// ApproveRequest valide une demande d'effacement formulée par l'utilisateur
+func (h *PrivacyHandler) ApproveRequest(c *gin.Context) {
+	h.review(c, true)
+}
+
+// RejectRequest refuse une demande d'effacement formulée par l'utilisateur
+func (h *PrivacyHandler) RejectRequest(c *gin.Context) {
+	h.review(c, false)
+}
+
+// DownloadExport télécharge l'archive d'une demande d'export (administration)
+func (h *PrivacyHandler) DownloadExport(c *gin.Context) {
+	var request models.PrivacyRequest
+	if err := h.db.First(&request, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Demande introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	h.sendExport(c, &request)
+}
+
+// review traite la validation ou le refus d'une demande d'effacement
+func (h *PrivacyHandler) review(c *gin.Context, approve bool) {
+	var req models.PrivacyReviewRequest
+	if !h.bindOptional(c, &req) {
+		return
+	}
+
+	var request models.PrivacyRequest
+	if err := h.db.First(&request, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Demande introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	middleware.SetAuditAction(c, models.AuditActionPrivacyErasure)
+	middleware.SetAuditTarget(c, "privacy_requests", request.ID, fmt.Sprintf("%s #%d", request.Type, request.ID))
+
+	if approve {
+		var user models.User
+		if err := h.db.First(&user, request.UserID).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Utilisateur non trouvé",
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		if !h.canErase(c, &user) {
+			return
+		}
+	}
+
+	if err := h.privacy.Review(&request, c.GetUint("user_id"), approve, req.Comment); err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "invalid_status",
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	message := "Demande refusée"
+	if approve {
+		message = "Demande validée, effacement en cours"
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: message,
+	})
+}
+
+// bindOptional lit le corps JSON s'il est fourni (commentaire facultatif)
+func (h *PrivacyHandler) bindOptional(c *gin.Context, req *models.PrivacyReviewRequest) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Données invalides",
+			Code:    http.StatusBadRequest,
+		})
+		return false
+	}
+	return true
+}
+
+// findUser charge l'utilisateur ciblé par la route
+func (h *PrivacyHandler) findUser(c *gin.Context) (*models.User, bool) {
+	var user models.User
+	if err := h.db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Utilisateur non trouvé",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	middleware.SetAuditTarget(c, "users", user.ID, user.Username)
+	return &user, true
+}
+
+// canErase refuse l'effacement de son propre compte depuis l'administration et celui du dernier administrateur actif
+func (h *PrivacyHandler) canErase(c *gin.Context, user *models.User) bool {
+	if user.ID == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_target",
+			Message: "Impossible d'effacer son propre compte depuis l'administration",
+			Code:    http.StatusBadRequest,
+		})
+		return false
+	}
+	if user.Role == "admin" {
+		var admins int64
+		h.db.Model(&models.User{}).Where("role = ? AND is_active = ? AND id <> ?", "admin", true, user.ID).Count(&admins)
+		if admins == 0 {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "last_admin",
+				Message: "Impossible d'effacer le dernier administrateur actif",
+				Code:    http.StatusConflict,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// sendExport envoie l'archive d'une demande d'export si elle est disponible
+func (h *PrivacyHandler) sendExport(c *gin.Context, request *models.PrivacyRequest) {
+	path, ok := h.privacy.ExportPath(request)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "export_unavailable",
+			Message: "Archive non disponible (en préparation ou expirée)",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	c.FileAttachment(path, fmt.Sprintf("airboard-export-%d%s", request.UserID, filepath.Ext(path)))
+}
+
+// respondCreateError traduit l'erreur de création d'une demande
+func (h *PrivacyHandler) respondCreateError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrPrivacyRequestInProgress) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "request_in_progress",
+			Message: "Une demande de ce type est déjà en cours",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   "database_error",
+		Message: "Erreur lors de l'enregistrement de la demande",
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+// recordSelfService journalise une demande formulée par l'utilisateur lui-même
+func (h *PrivacyHandler) recordSelfService(c *gin.Context, action string, request *models.PrivacyRequest) {
+	entry := middleware.NewAuditEntry(c, action)
+	entry.Action = action
+	entry.TargetType = "privacy_requests"
+	entry.TargetID = strconv.FormatUint(uint64(request.ID), 10)
+	entry.TargetLabel = c.GetString("username")
+	entry.StatusCode = http.StatusAccepted
+	entry.Success = true
+	h.audit.Record(entry)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RateLimitHandler gère les politiques de limitation et leurs métriques
+type RateLimitHandler struct {
+	db        *gorm.DB
+	rateLimit *services.RateLimitService
+}
+
+// NewRateLimitHandler crée une nouvelle instance de RateLimitHandler
+func NewRateLimitHandler(db *gorm.DB, rateLimit *services.RateLimitService) *RateLimitHandler {
+	return &RateLimitHandler{
+		db:        db,
+		rateLimit: rateLimit,
+	}
+}
+
+// ListPolicies retourne les politiques et les groupes de routes disponibles
+func (h *RateLimitHandler) ListPolicies(c *gin.Context) {
+	var policies []models.RateLimitPolicy
+	if err := h.db.Order("route_group, role").Find(&policies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des politiques",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies":     policies,
+		"route_groups": models.RateLimitGroups,
+	})
+}
+
+// CreatePolicy crée une politique de limitation
+func (h *RateLimitHandler) CreatePolicy(c *gin.Context) {
+	var req models.RateLimitPolicyRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	policy := models.RateLimitPolicy{
+		Name:          req.Name,
+		RouteGroup:    req.RouteGroup,
+		Role:          req.Role,
+		MaxRequests:   req.MaxRequests,
+		PeriodSeconds: req.PeriodSeconds,
+		IsActive:      req.IsActive == nil || *req.IsActive,
+	}
+	if err := h.db.Create(&policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la création de la politique",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	// GORM ignore la valeur false du champ booléen avec valeur par défaut
+	if !policy.IsActive {
+		h.db.Model(&policy).Update("is_active", false)
+	}
+
+	h.rateLimit.InvalidatePolicies()
+	middleware.SetAuditTarget(c, "rate_limit_policies", policy.ID, policy.Name)
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdatePolicy met à jour une politique de limitation
+func (h *RateLimitHandler) UpdatePolicy(c *gin.Context) {
+	policy, ok := h.findPolicy(c)
+	if !ok {
+		return
+	}
+
+	var req models.RateLimitPolicyRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	middleware.AuditBefore(c, policy)
+	updates := map[string]interface{}{
+		"name":           req.Name,
+		"route_group":    req.RouteGroup,
+		"role":           req.Role,
+		"max_requests":   req.MaxRequests,
+		"period_seconds": req.PeriodSeconds,
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if err := h.db.Model(policy).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la mise à jour de la politique",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.rateLimit.InvalidatePolicies()
+	h.db.First(policy, policy.ID)
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeletePolicy supprime une politique de limitation
+func (h *RateLimitHandler) DeletePolicy(c *gin.Context) {
+	policy, ok := h.findPolicy(c)
+	if !ok {
+		return
+	}
+
+	middleware.AuditBefore(c, policy)
+	if err := h.db.Delete(policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la suppression de la politique",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.rateLimit.InvalidatePolicies()
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Politique supprimée",
+	})
+}
+
+// rateLimitMetricRow agrégat de requêtes rejetées
+type rateLimitMetricRow struct {
+	Bucket     *time.Time `json:"bucket,omitempty"`
+	PolicyID   uint       `json:"policy_id,omitempty"`
+	RouteGroup string     `json:"route_group,omitempty"`
+	Role       string     `json:"role,omitempty"`
+	Subject    string     `json:"subject,omitempty"`
+	Throttled  int64      `json:"throttled"`
+}
+
+// GetMetrics retourne les requêtes rejetées sur les dernières heures (toutes instances),
+// par heure et groupe de routes, les clients les plus limités et les compteurs de l'instance courante
+func (h *RateLimitHandler) GetMetrics(c *gin.Context) {
+	hours, _ := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if hours < 1 || hours > 24*30 {
+		hours = 24
+	}
+	since := time.Now().Add(-time.Duration(hours) * time.Hour).Truncate(time.Hour)
+
+	// Les rejets accumulés depuis la dernière minute sont inclus
+	h.rateLimit.FlushMetrics()
+
+	var timeline []rateLimitMetricRow
+	h.db.Model(&models.RateLimitMetric{}).
+		Select("bucket, route_group, SUM(throttled) AS throttled").
+		Where("bucket >= ?", since).
+		Group("bucket, route_group").
+		Order("bucket, route_group").
+		Scan(&timeline)
+
+	var byPolicy []rateLimitMetricRow
+	h.db.Model(&models.RateLimitMetric{}).
+		Select("policy_id, route_group, role, SUM(throttled) AS throttled").
+		Where("bucket >= ?", since).
+		Group("policy_id, route_group, role").
+		Order("throttled DESC").
+		Scan(&byPolicy)
+
+	var topSubjects []rateLimitMetricRow
+	h.db.Model(&models.RateLimitMetric{}).
+		Select("subject, SUM(throttled) AS throttled").
+		Where("bucket >= ?", since).
+		Group("subject").
+		Order("throttled DESC").
+		Limit(20).
+		Scan(&topSubjects)
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":        since,
+		"timeline":     timeline,
+		"by_policy":    byPolicy,
+		"top_subjects": topSubjects,
+		"instance":     h.rateLimit.InstanceStats(),
+	})
+}
+
+// bindRequest valide la requête : groupe de routes connu
+func (h *RateLimitHandler) bindRequest(c *gin.Context, req *models.RateLimitPolicyRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return false
+	}
+
+	req.RouteGroup = strings.TrimSpace(req.RouteGroup)
+	req.Role = strings.TrimSpace(req.Role)
+	for _, group := range models.RateLimitGroups {
+		if req.RouteGroup == group {
+			return true
+		}
+	}
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:   "validation_error",
+		Message: "Groupe de routes inconnu: " + req.RouteGroup,
+		Code:    http.StatusBadRequest,
+	})
+	return false
+}
+
+func (h *RateLimitHandler) findPolicy(c *gin.Context) (*models.RateLimitPolicy, bool) {
+	var policy models.RateLimitPolicy
+	if err := h.db.First(&policy, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Politique introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	middleware.SetAuditTarget(c, "rate_limit_policies", policy.ID, policy.Name)
+	return &policy, true
+}
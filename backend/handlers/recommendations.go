@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RecommendationHandler gère le fil de news personnalisé ("pour vous")
+type RecommendationHandler struct {
+	db              *gorm.DB
+	recommendations *services.RecommendationService
+}
+
+// NewRecommendationHandler crée une nouvelle instance de RecommendationHandler
+func NewRecommendationHandler(db *gorm.DB) *RecommendationHandler {
+	return &RecommendationHandler{
+		db:              db,
+		recommendations: services.NewRecommendationService(db),
+	}
+}
+
+// GetForYou retourne les news recommandées à l'utilisateur connecté avec les motifs de chaque
+// recommandation. Les articles déjà lus sont écartés, sauf avec include_read=true.
+func (h *RecommendationHandler) GetForYou(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.RecommendationDefaultLimit)))
+	categoryID, _ := strconv.ParseUint(c.Query("category_id"), 10, 64)
+
+	userID := c.GetUint("user_id")
+	var userGroupIDs []uint
+	h.db.Table("user_groups").Where("user_id = ?", userID).Pluck("group_id", &userGroupIDs)
+
+	news, err := h.recommendations.Recommend(services.RecommendationScope{
+		UserID:   userID,
+		AllNews:  middleware.HasPermission(c, models.PermNewsManage),
+		GroupIDs: uniqueGroupIDs(append(userGroupIDs, middleware.GetManagedGroupIDs(c)...)),
+	}, services.RecommendationParams{
+		Limit:       limit,
+		IncludeRead: c.Query("include_read") == "true",
+		CategoryID:  uint(categoryID),
+	})
+	if err != nil {
+		log.Printf("[Recommendations] Erreur lors du calcul des recommandations: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors du chargement des recommandations",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	countNewsInteractions(h.db, news)
+	c.JSON(http.StatusOK, gin.H{"news": news})
+}
+
+// countNewsInteractions renseigne le nombre de commentaires approuvés et d'avis de chaque article
+func countNewsInteractions(db *gorm.DB, news []models.NewsRecommendation) {
+	if len(news) == 0 {
+		return
+	}
+	ids := make([]uint, len(news))
+	for i := range news {
+		ids[i] = news[i].ID
+	}
+
+	type countRow struct {
+		EntityID uint
+		Count    int
+	}
+	var comments, feedbacks []countRow
+	db.Model(&models.Comment{}).Select("entity_id, COUNT(*) AS count").
+		Where("entity_type = ? AND entity_id IN ? AND status = ?", "news", ids, "approved").
+		Group("entity_id").Scan(&comments)
+	db.Model(&models.Feedback{}).Select("entity_id, COUNT(*) AS count").
+		Where("entity_type = ? AND entity_id IN ?", "news", ids).
+		Group("entity_id").Scan(&feedbacks)
+
+	commentCounts := make(map[uint]int)
+	for _, row := range comments {
+		commentCounts[row.EntityID] = row.Count
+	}
+	feedbackCounts := make(map[uint]int)
+	for _, row := range feedbacks {
+		feedbackCounts[row.EntityID] = row.Count
+	}
+	for i := range news {
+		news[i].CommentCount = commentCounts[news[i].ID]
+		news[i].ReactionCount = feedbackCounts[news[i].ID]
+	}
+}
+
+// uniqueGroupIDs dédoublonne une liste d'identifiants de groupes en conservant leur ordre
+func uniqueGroupIDs(ids []uint) []uint {
+	seen := make(map[uint]bool)
+	var unique []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,510 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ===== Historique des versions des news =====
+
+// ListRevisions liste les versions d'une news
+func (h *NewsHandler) ListRevisions(c *gin.Context) {
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	listRevisions(c, h.revisionService, models.RevisionEntityNews, news.ID)
+}
+
+// GetRevision retourne une version complète d'une news (instantané inclus)
+func (h *NewsHandler) GetRevision(c *gin.Context) {
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	getRevision(c, h.revisionService, models.RevisionEntityNews, news.ID)
+}
+
+// DiffRevisions compare deux versions d'une news (?from=&to=, par défaut la dernière et la précédente)
+func (h *NewsHandler) DiffRevisions(c *gin.Context) {
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	diffRevisions(c, h.revisionService, models.RevisionEntityNews, news.ID)
+}
+
+// RestoreRevision restaure le contenu d'une version de la news en créant une nouvelle version.
+// L'état de publication et l'épinglage ne sont pas modifiés.
+func (h *NewsHandler) RestoreRevision(c *gin.Context) {
+	news, ok := h.findEditableNews(c)
+	if !ok {
+		return
+	}
+	revision, ok := findRevision(c, h.revisionService, models.RevisionEntityNews, news.ID, c.Param("revisionId"))
+	if !ok {
+		return
+	}
+	snapshot, err := h.revisionService.NewsSnapshot(revision)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	// Le périmètre actuel de l'utilisateur s'applique aussi aux groupes et à la catégorie restaurés
+	groupIDs := services.RevisionRefIDs(snapshot.TargetGroups)
+	if !middleware.CanTargetGroups(c, models.PermNewsCreate, groupIDs) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Cette version cible des groupes hors de votre périmètre",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+	categoryID := existingCategoryID(h.db, &models.NewsCategory{}, snapshot.CategoryID)
+	if !middleware.CanUseCategory(c, models.PermNewsCreate, categoryID) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Vous ne pouvez pas publier dans la catégorie de cette version",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	middleware.AuditBefore(c, news)
+	news.Title = snapshot.Title
+	news.Summary = snapshot.Summary
+	news.Content = snapshot.Content
+	news.CoverImage = snapshot.CoverImage
+	news.Type = snapshot.Type
+	news.Priority = snapshot.Priority
+	news.ExpiresAt = snapshot.ExpiresAt
+	news.CategoryID = categoryID
+	news.Category = nil
+
+	var tags []models.Tag
+	var groups []models.Group
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags", "TargetGroups", "Author", "Category").Save(news).Error; err != nil {
+			return err
+		}
+		// Les tags et groupes supprimés depuis sont ignorés
+		if err := tx.Where("id IN ?", services.RevisionRefIDs(snapshot.Tags)).Find(&tags).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(news).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(news).Association("TargetGroups").Replace(groups); err != nil {
+			return err
+		}
+		return h.recordRevision(tx, news, c.GetUint("user_id"), &revision.ID)
+	})
+	if err != nil {
+		log.Printf("[Revisions] Erreur restauration news %d version %d: %v", news.ID, revision.Number, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la restauration de la version",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.db.Preload("Author").
+		Preload("Category").
+		Preload("Tags").
+		Preload("TargetGroups").
+		First(news, news.ID)
+	// Restaurer une version d'un article publié vaut modification : soumise à relecture comme dans UpdateNews
+	unpublished, err := h.invalidateApproval(c, news)
+	if err != nil {
+		log.Printf("[Workflow] Erreur invalidation de l'approbation de l'article %d: %v", news.ID, err)
+	} else if unpublished {
+		submitter, err := h.loadUser(c.GetUint("user_id"))
+		if err == nil {
+			err = h.workflow.Submit(news, submitter, nil, "")
+		}
+		if err != nil {
+			log.Printf("[Workflow] Erreur soumission de l'article %d: %v", news.ID, err)
+		}
+	}
+
+	middleware.AuditAfter(c, news)
+	middleware.SetAuditDetails(c, fmt.Sprintf("Restauration de la version %d", revision.Number))
+	c.JSON(http.StatusOK, news)
+}
+
+// findEditableNews charge la news (ID ou slug) et vérifie que l'utilisateur peut la modifier
+func (h *NewsHandler) findEditableNews(c *gin.Context) (*models.News, bool) {
+	news, ok := h.findNews(c)
+	if !ok {
+		return nil, false
+	}
+	if !h.canEditNews(c, news) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Vous n'avez pas le droit de modifier cette news",
+			Code:    http.StatusForbidden,
+		})
+		return nil, false
+	}
+	return news, true
+}
+
+// findNews charge la news désignée par le paramètre id (ID numérique ou slug)
+func (h *NewsHandler) findNews(c *gin.Context) (*models.News, bool) {
+	identifier := c.Param("id")
+	query := h.db.Preload("TargetGroups")
+	if id, err := strconv.Atoi(identifier); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("slug = ?", identifier)
+	}
+
+	var news models.News
+	if err := query.First(&news).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "News introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	middleware.SetAuditTarget(c, "news", news.ID, news.Title)
+	return &news, true
+}
+
+// canEditNews : gestionnaire global des news, auteur, ou admin d'un des groupes ciblés
+// (une news publique, sans groupe cible, n'est pas modifiable par un admin de groupe)
+func (h *NewsHandler) canEditNews(c *gin.Context, news *models.News) bool {
+	if middleware.HasPermission(c, models.PermNewsManage) || news.AuthorID == c.GetUint("user_id") {
+		return true
+	}
+
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermNewsManage)
+	if len(managedGroupIDs) == 0 {
+		return false
+	}
+	var targetGroupIDs []uint
+	h.db.Table("news_target_groups").Where("news_id = ?", news.ID).Pluck("group_id", &targetGroupIDs)
+	return containsAnyID(targetGroupIDs, managedGroupIDs)
+}
+
+// recordRevision enregistre une version de la news dans la transaction qui l'enregistre :
+// une modification sans version dans l'historique est annulée
+func (h *NewsHandler) recordRevision(tx *gorm.DB, news *models.News, authorID uint, restoredFromID *uint) error {
+	if err := tx.Model(news).Association("Tags").Find(&news.Tags); err != nil {
+		return err
+	}
+	if err := tx.Model(news).Association("TargetGroups").Find(&news.TargetGroups); err != nil {
+		return err
+	}
+	if _, err := h.revisionService.RecordNews(tx, news, authorID, restoredFromID); err != nil {
+		return fmt.Errorf("enregistrement de la version de la news %d: %w", news.ID, err)
+	}
+	return nil
+}
+
+// ===== Historique des versions des événements =====
+
+// ListRevisions liste les versions d'un événement
+func (h *EventsHandler) ListRevisions(c *gin.Context) {
+	event, ok := h.findEditableEvent(c)
+	if !ok {
+		return
+	}
+	listRevisions(c, h.revisionService, models.RevisionEntityEvent, event.ID)
+}
+
+// GetRevision retourne une version complète d'un événement (instantané inclus)
+func (h *EventsHandler) GetRevision(c *gin.Context) {
+	event, ok := h.findEditableEvent(c)
+	if !ok {
+		return
+	}
+	getRevision(c, h.revisionService, models.RevisionEntityEvent, event.ID)
+}
+
+// DiffRevisions compare deux versions d'un événement (?from=&to=, par défaut la dernière et la précédente)
+func (h *EventsHandler) DiffRevisions(c *gin.Context) {
+	event, ok := h.findEditableEvent(c)
+	if !ok {
+		return
+	}
+	diffRevisions(c, h.revisionService, models.RevisionEntityEvent, event.ID)
+}
+
+// RestoreRevision restaure une version de l'événement en créant une nouvelle version.
+// L'état de publication n'est pas modifié.
+func (h *EventsHandler) RestoreRevision(c *gin.Context) {
+	event, ok := h.findEditableEvent(c)
+	if !ok {
+		return
+	}
+	revision, ok := findRevision(c, h.revisionService, models.RevisionEntityEvent, event.ID, c.Param("revisionId"))
+	if !ok {
+		return
+	}
+	snapshot, err := h.revisionService.EventSnapshot(revision)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	groupIDs := services.RevisionRefIDs(snapshot.TargetGroups)
+	if !middleware.CanTargetGroups(c, models.PermEventsCreate, groupIDs) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Cette version cible des groupes hors de votre périmètre",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	middleware.AuditBefore(c, event)
+	event.Title = snapshot.Title
+	event.Description = snapshot.Description
+	event.StartDate = snapshot.StartDate
+	event.EndDate = snapshot.EndDate
+	event.IsAllDay = snapshot.IsAllDay
+	event.Timezone = snapshot.Timezone
+	event.IsRecurring = snapshot.IsRecurring
+	event.RecurrenceRule = snapshot.RecurrenceRule
+	event.RecurrenceEnd = snapshot.RecurrenceEnd
+	event.RecurrenceExceptions = snapshot.RecurrenceExceptions
+	event.Location = snapshot.Location
+	event.ExternalLinks = snapshot.ExternalLinks
+	event.Color = snapshot.Color
+	event.Priority = snapshot.Priority
+	event.Status = snapshot.Status
+	event.CoverImage = snapshot.CoverImage
+	event.CategoryID = existingCategoryID(h.db, &models.EventCategory{}, snapshot.CategoryID)
+	event.Category = nil
+
+	var tags []models.Tag
+	var groups []models.Group
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags", "TargetGroups", "Author", "Category").Save(event).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", services.RevisionRefIDs(snapshot.Tags)).Find(&tags).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(event).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(event).Association("TargetGroups").Replace(groups); err != nil {
+			return err
+		}
+		return h.recordRevision(tx, event, c.GetUint("user_id"), &revision.ID)
+	})
+	if err != nil {
+		log.Printf("[Revisions] Erreur restauration événement %d version %d: %v", event.ID, revision.Number, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la restauration de la version",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.db.Preload("Author").
+		Preload("Category").
+		Preload("Tags").
+		Preload("TargetGroups").
+		First(event, event.ID)
+
+	middleware.AuditAfter(c, event)
+	middleware.SetAuditDetails(c, fmt.Sprintf("Restauration de la version %d", revision.Number))
+	c.JSON(http.StatusOK, event)
+}
+
+// findEditableEvent charge l'événement (ID ou slug) et vérifie que l'utilisateur peut le modifier
+func (h *EventsHandler) findEditableEvent(c *gin.Context) (*models.Event, bool) {
+	identifier := c.Param("id")
+	query := h.db.Preload("TargetGroups")
+	if id, err := strconv.Atoi(identifier); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("slug = ?", identifier)
+	}
+
+	var event models.Event
+	if err := query.First(&event).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Événement non trouvé",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	if !h.canEditEvent(c, &event) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Vous ne pouvez modifier que vos événements ou ceux de vos groupes gérés",
+			Code:    http.StatusForbidden,
+		})
+		return nil, false
+	}
+	middleware.SetAuditTarget(c, "events", event.ID, event.Title)
+	return &event, true
+}
+
+// canEditEvent : gestionnaire global des événements, auteur, ou admin d'un des groupes ciblés
+func (h *EventsHandler) canEditEvent(c *gin.Context, event *models.Event) bool {
+	if middleware.HasPermission(c, models.PermEventsManage) || event.AuthorID == c.GetUint("user_id") {
+		return true
+	}
+
+	managedGroupIDs := middleware.PermissionGroupIDs(c, models.PermEventsManage)
+	if len(managedGroupIDs) == 0 {
+		return false
+	}
+	var targetGroupIDs []uint
+	h.db.Table("event_target_groups").Where("event_id = ?", event.ID).Pluck("group_id", &targetGroupIDs)
+	return containsAnyID(targetGroupIDs, managedGroupIDs)
+}
+
+// recordRevision enregistre une version de l'événement dans la transaction qui l'enregistre :
+// une modification sans version dans l'historique est annulée
+func (h *EventsHandler) recordRevision(tx *gorm.DB, event *models.Event, authorID uint, restoredFromID *uint) error {
+	if err := tx.Model(event).Association("Tags").Find(&event.Tags); err != nil {
+		return err
+	}
+	if err := tx.Model(event).Association("TargetGroups").Find(&event.TargetGroups); err != nil {
+		return err
+	}
+	if _, err := h.revisionService.RecordEvent(tx, event, authorID, restoredFromID); err != nil {
+		return fmt.Errorf("enregistrement de la version de l'événement %d: %w", event.ID, err)
+	}
+	return nil
+}
+
+// ===== Fonctions communes =====
+
+func listRevisions(c *gin.Context, revisionService *services.RevisionService, entityType string, entityID uint) {
+	revisions, err := revisionService.List(entityType, entityID)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+func getRevision(c *gin.Context, revisionService *services.RevisionService, entityType string, entityID uint) {
+	revision, ok := findRevision(c, revisionService, entityType, entityID, c.Param("revisionId"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+func diffRevisions(c *gin.Context, revisionService *services.RevisionService, entityType string, entityID uint) {
+	var to *models.ContentRevision
+	var err error
+	if toID := c.Query("to"); toID != "" {
+		var ok bool
+		if to, ok = findRevision(c, revisionService, entityType, entityID, toID); !ok {
+			return
+		}
+	} else if to, err = revisionService.Latest(entityType, entityID); err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	var from *models.ContentRevision
+	if fromID := c.Query("from"); fromID != "" {
+		var ok bool
+		if from, ok = findRevision(c, revisionService, entityType, entityID, fromID); !ok {
+			return
+		}
+	} else if from, err = revisionService.Previous(to); errors.Is(err, services.ErrRevisionNotFound) {
+		// Première version : comparaison avec un contenu vide
+		from = &models.ContentRevision{EntityType: to.EntityType, EntityID: to.EntityID, Snapshot: "{}"}
+	} else if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	diff, err := revisionService.Diff(from, to)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+func findRevision(c *gin.Context, revisionService *services.RevisionService, entityType string, entityID uint, rawID string) (*models.ContentRevision, bool) {
+	revisionID, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Identifiant de version invalide",
+			Code:    http.StatusBadRequest,
+		})
+		return nil, false
+	}
+	revision, err := revisionService.Get(entityType, entityID, uint(revisionID))
+	if err != nil {
+		respondRevisionError(c, err)
+		return nil, false
+	}
+	return revision, true
+}
+
+func respondRevisionError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Version introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	log.Printf("[Revisions] Erreur: %v", err)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   "database_error",
+		Message: "Erreur lors de la lecture de l'historique des versions",
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+// existingCategoryID retourne la catégorie d'un instantané si elle existe encore
+func existingCategoryID(db *gorm.DB, model interface{}, categoryID *uint) *uint {
+	if categoryID == nil {
+		return nil
+	}
+	var count int64
+	db.Model(model).Where("id = ?", *categoryID).Count(&count)
+	if count == 0 {
+		return nil
+	}
+	return categoryID
+}
+
+func containsAnyID(ids, candidates []uint) bool {
+	for _, id := range ids {
+		for _, candidate := range candidates {
+			if id == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
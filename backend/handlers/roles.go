@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RoleHandler gère les rôles personnalisés et leurs attributions
+type RoleHandler struct {
+	db          *gorm.DB
+	permissions *services.PermissionService
+}
+
+// NewRoleHandler crée une nouvelle instance de RoleHandler
+func NewRoleHandler(db *gorm.DB, permissions *services.PermissionService) *RoleHandler {
+	return &RoleHandler{
+		db:          db,
+		permissions: permissions,
+	}
+}
+
+// ListPermissions retourne le catalogue des permissions assignables
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": models.AvailablePermissions,
+	})
+}
+
+// ListRoles retourne tous les rôles avec le nombre d'attributions
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := h.db.Order("is_system DESC, name ASC").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des rôles",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	type roleWithCount struct {
+		models.Role
+		AssignmentCount int64 `json:"assignment_count"`
+	}
+	result := make([]roleWithCount, 0, len(roles))
+	for _, role := range roles {
+		var count int64
+		h.db.Model(&models.RoleAssignment{}).Where("role_id = ?", role.ID).Count(&count)
+		result = append(result, roleWithCount{Role: role, AssignmentCount: count})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateRole crée un rôle personnalisé
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	permissions, ok := h.validatePermissions(c, req.Permissions)
+	if !ok {
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	var count int64
+	h.db.Model(&models.Role{}).Where("name = ?", name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "conflict",
+			Message: "Un rôle avec ce nom existe déjà",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	role := models.Role{
+		Name:        name,
+		DisplayName: defaultString(req.DisplayName, "", name),
+		Description: req.Description,
+		Permissions: strings.Join(permissions, ","),
+	}
+	if err := h.db.Create(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la création du rôle",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.permissions.Invalidate()
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole met à jour un rôle. Les rôles système ne peuvent pas être renommés
+// et le rôle admin conserve toutes les permissions.
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var role models.Role
+	if err := h.db.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Rôle introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	middleware.SetAuditTarget(c, "roles", role.ID, role.Name)
+	middleware.AuditBefore(c, role)
+
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if role.Name == models.RoleAdmin {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Le rôle admin ne peut pas être modifié",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	permissions, ok := h.validatePermissions(c, req.Permissions)
+	if !ok {
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if role.IsSystem && name != role.Name {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Un rôle système ne peut pas être renommé",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if name != role.Name {
+		var count int64
+		h.db.Model(&models.Role{}).Where("name = ? AND id <> ?", name, role.ID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "conflict",
+				Message: "Un rôle avec ce nom existe déjà",
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+	}
+
+	role.Name = name
+	role.DisplayName = defaultString(req.DisplayName, "", name)
+	role.Description = req.Description
+	role.Permissions = strings.Join(permissions, ",")
+
+	if err := h.db.Save(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la mise à jour du rôle",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.permissions.Invalidate()
+	middleware.AuditAfter(c, role)
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole supprime un rôle personnalisé et ses attributions
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	var role models.Role
+	if err := h.db.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Rôle introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	middleware.SetAuditTarget(c, "roles", role.ID, role.Name)
+	middleware.AuditBefore(c, role)
+
+	if role.IsSystem {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Un rôle système ne peut pas être supprimé",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RoleAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la suppression du rôle",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.permissions.Invalidate()
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Rôle supprimé avec succès",
+	})
+}
+
+// ListAssignments retourne les attributions de rôles (filtrables par user_id, group_id ou role_id)
+func (h *RoleHandler) ListAssignments(c *gin.Context) {
+	query := h.db.Preload("Role").Order("created_at DESC")
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if groupID := c.Query("group_id"); groupID != "" {
+		query = query.Where("group_id = ?", groupID)
+	}
+	if roleID := c.Query("role_id"); roleID != "" {
+		query = query.Where("role_id = ?", roleID)
+	}
+
+	var assignments []models.RoleAssignment
+	if err := query.Find(&assignments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des attributions",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignments)
+}
+
+// CreateAssignment attribue un rôle à un utilisateur ou à un groupe, éventuellement sur un périmètre
+func (h *RoleHandler) CreateAssignment(c *gin.Context) {
+	var req models.RoleAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if (req.UserID == nil) == (req.GroupID == nil) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Indiquez soit un utilisateur, soit un groupe",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if (req.ScopeType == models.ScopeGlobal) != (req.ScopeID == nil) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Un périmètre nécessite un type et un identifiant",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var role models.Role
+	if err := h.db.First(&role, req.RoleID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Rôle introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// Seul un administrateur peut attribuer un rôle donnant toutes les permissions
+	for _, permission := range role.PermissionList() {
+		if permission == models.PermAll && !middleware.HasPermission(c, models.PermAll) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Droits administrateur requis pour attribuer ce rôle",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+	}
+
+	if req.UserID != nil {
+		var count int64
+		h.db.Model(&models.User{}).Where("id = ?", *req.UserID).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Utilisateur introuvable",
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+	}
+	if req.GroupID != nil {
+		var count int64
+		h.db.Model(&models.Group{}).Where("id = ?", *req.GroupID).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Groupe introuvable",
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+	}
+
+	assignment := models.RoleAssignment{
+		RoleID:      role.ID,
+		UserID:      req.UserID,
+		GroupID:     req.GroupID,
+		ScopeType:   req.ScopeType,
+		ScopeID:     req.ScopeID,
+		CreatedByID: c.GetUint("user_id"),
+	}
+	if err := h.db.Create(&assignment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de l'attribution du rôle",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	assignment.Role = role
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// DeleteAssignment retire une attribution de rôle
+func (h *RoleHandler) DeleteAssignment(c *gin.Context) {
+	var assignment models.RoleAssignment
+	if err := h.db.Preload("Role").First(&assignment, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Attribution introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	middleware.SetAuditTarget(c, "role_assignments", assignment.ID, assignment.Role.Name)
+	middleware.AuditBefore(c, assignment)
+
+	for _, permission := range assignment.Role.PermissionList() {
+		if permission == models.PermAll && !middleware.HasPermission(c, models.PermAll) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Droits administrateur requis pour retirer ce rôle",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+	}
+
+	if err := h.db.Delete(&assignment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors du retrait du rôle",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Attribution supprimée avec succès",
+	})
+}
+
+// GetMyPermissions retourne les permissions effectives de l'utilisateur connecté
+func (h *RoleHandler) GetMyPermissions(c *gin.Context) {
+	set := middleware.GetPermissions(c)
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": set.List(),
+		"grants":      set.Grants,
+	})
+}
+
+// validatePermissions normalise et vérifie la liste des permissions d'un rôle.
+// La permission "*" est réservée aux administrateurs.
+func (h *RoleHandler) validatePermissions(c *gin.Context, requested []string) ([]string, bool) {
+	permissions := make([]string, 0, len(requested))
+	seen := make(map[string]bool)
+	for _, permission := range requested {
+		permission = strings.TrimSpace(permission)
+		if permission == "" || seen[permission] {
+			continue
+		}
+		if !services.IsKnownPermission(permission) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "validation_error",
+				Message: "Permission inconnue: " + permission,
+				Code:    http.StatusBadRequest,
+			})
+			return nil, false
+		}
+		if permission == models.PermAll && !middleware.HasPermission(c, models.PermAll) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Droits administrateur requis pour accorder toutes les permissions",
+				Code:    http.StatusForbidden,
+			})
+			return nil, false
+		}
+		seen[permission] = true
+		permissions = append(permissions, permission)
+	}
+	return permissions, true
+}
@@ -0,0 +1,660 @@
+package handlers
+
+import (
+	"airboard/config"
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+	"encoding/xml"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SAMLHandler struct {
+	db             *gorm.DB
+	config         *config.Config
+	authMiddleware *middleware.AuthMiddleware
+	samlService    *services.SAMLService
+	ssoMapper      *services.SSOMapper
+	audit          *services.AuditService
+}
+
+func NewSAMLHandler(db *gorm.DB, authMiddleware *middleware.AuthMiddleware, cfg *config.Config) *SAMLHandler {
+	return &SAMLHandler{
+		db:             db,
+		config:         cfg,
+		authMiddleware: authMiddleware,
+		samlService:    services.NewSAMLService(db, cfg),
+		ssoMapper:      services.NewSSOMapper(db, cfg),
+		audit:          services.NewAuditService(db),
+	}
+}
+
+// findProvider charge un fournisseur SAML par son nom (activé uniquement si onlyEnabled)
+func (h *SAMLHandler) findProvider(c *gin.Context, onlyEnabled bool) (*models.SAMLProvider, bool) {
+	var provider models.SAMLProvider
+	query := h.db.Where("name = ?", c.Param("provider"))
+	if onlyEnabled {
+		query = query.Where("is_enabled = ?", true)
+	}
+	if err := query.First(&provider).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "SAML provider not found or disabled",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	return &provider, true
+}
+
+// redirectToFrontend redirige le navigateur vers la page de callback SAML du frontend
+func (h *SAMLHandler) redirectToFrontend(c *gin.Context, params url.Values) {
+	target := strings.TrimSuffix(h.config.Server.PublicURL, "/") + "/auth/saml/callback?" + params.Encode()
+	c.Redirect(http.StatusFound, target)
+}
+
+// GetEnabledProviders retourne les fournisseurs SAML activés (page de connexion)
+func (h *SAMLHandler) GetEnabledProviders(c *gin.Context) {
+	var providers []models.SAMLProvider
+	if err := h.db.Where("is_enabled = ?", true).Order("display_name").Find(&providers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch SAML providers",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	publicProviders := make([]models.SAMLProviderPublic, len(providers))
+	for i, p := range providers {
+		publicProviders[i] = models.SAMLProviderPublic{
+			ID:          p.ID,
+			Name:        p.Name,
+			DisplayName: p.DisplayName,
+			Icon:        p.Icon,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"providers": publicProviders,
+	})
+}
+
+// GetMetadata retourne les métadonnées SP (XML) à fournir à l'IdP
+func (h *SAMLHandler) GetMetadata(c *gin.Context) {
+	provider, ok := h.findProvider(c, false)
+	if !ok {
+		return
+	}
+
+	sp, err := h.samlService.ServiceProvider(provider)
+	if err != nil {
+		log.Printf("[SAML] Erreur lors de la génération des métadonnées pour %s: %v", provider.Name, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "saml_error",
+			Message: "Failed to build SAML metadata",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	metadata, err := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "saml_error",
+			Message: "Failed to encode SAML metadata",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// InitiateLogin retourne l'URL de l'IdP contenant l'AuthnRequest (signée si configuré)
+func (h *SAMLHandler) InitiateLogin(c *gin.Context) {
+	provider, ok := h.findProvider(c, true)
+	if !ok {
+		return
+	}
+
+	authURL, err := h.samlService.MakeAuthenticationRequest(provider)
+	if err != nil {
+		log.Printf("[SAML] Erreur lors de la création de l'AuthnRequest pour %s: %v", provider.Name, err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "saml_error",
+			Message: "Failed to create SAML authentication request",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"auth_url": authURL,
+	})
+}
+
+// AssertionConsumerService reçoit la réponse de l'IdP (binding HTTP-POST), valide l'assertion,
+// synchronise l'utilisateur puis redirige vers le frontend avec un code à usage unique
+func (h *SAMLHandler) AssertionConsumerService(c *gin.Context) {
+	provider, ok := h.findProvider(c, true)
+	if !ok {
+		return
+	}
+
+	attrs, err := h.samlService.ParseResponse(provider, c.Request)
+	if err != nil {
+		log.Printf("[SAML] Assertion rejetée pour %s: %v", provider.Name, err)
+		recordAuthEvent(h.audit, c, models.AuditActionLoginFailed, nil, "", "saml:"+provider.Name+" - assertion rejetée")
+		h.redirectToFrontend(c, url.Values{"error": {"invalid_assertion"}})
+		return
+	}
+
+	var adminGroups []string
+	for _, g := range strings.Split(provider.AdminGroups, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			adminGroups = append(adminGroups, g)
+		}
+	}
+
+	// Les comptes désactivés localement ne doivent pas être réactivés par une connexion SAML
+	var existing models.User
+	if err := h.db.Where("email = ?", attrs.Email).First(&existing).Error; err == nil && !existing.IsActive {
+		log.Printf("[SAML] Connexion refusée pour le compte désactivé %s", attrs.Email)
+		recordAuthEvent(h.audit, c, models.AuditActionLoginFailed, &existing, attrs.Email, "saml:"+provider.Name+" - compte désactivé")
+		h.redirectToFrontend(c, url.Values{"error": {"account_disabled"}})
+		return
+	}
+
+	user, err := h.ssoMapper.SyncUser(&services.SSOUserInfo{
+		Email:         attrs.Email,
+		Username:      attrs.Username,
+		FirstName:     attrs.FirstName,
+		LastName:      attrs.LastName,
+		Groups:        attrs.Groups,
+		SSOID:         attrs.NameID,
+		Provider:      "saml:" + provider.Name,
+		Manager:       attrs.Manager,
+		AdminGroups:   adminGroups,
+		DefaultRole:   provider.DefaultRole,
+		ManagerSource: models.ManagerSourceSAML,
+	})
+	if err != nil {
+		log.Printf("[SAML] Erreur lors de la synchronisation de l'utilisateur %s: %v", attrs.Email, err)
+		h.redirectToFrontend(c, url.Values{"error": {"provisioning_failed"}})
+		return
+	}
+
+	// Conserver NameID/SessionIndex de cette connexion pour le Single Logout (la session de connexion
+	// Airboard lui est rattachée lors de l'échange du code)
+	session := models.SAMLSession{
+		UserID:       user.ID,
+		ProviderID:   provider.ID,
+		NameID:       attrs.NameID,
+		SessionIndex: attrs.SessionIndex,
+	}
+	if err := h.db.Create(&session).Error; err != nil {
+		log.Printf("[SAML] Erreur lors de l'enregistrement de la session: %v", err)
+	}
+
+	code, err := h.samlService.IssueLoginCode(user.ID, session.ID)
+	if err != nil {
+		h.redirectToFrontend(c, url.Values{"error": {"server_error"}})
+		return
+	}
+
+	log.Printf("[SAML] Assertion validée pour %s via %s", user.Email, provider.Name)
+	recordAuthEvent(h.audit, c, models.AuditActionLogin, user, user.Email, "saml:"+provider.Name)
+	h.redirectToFrontend(c, url.Values{"code": {code}})
+}
+
+// ExchangeCode échange le code à usage unique émis par l'ACS contre des tokens JWT
+func (h *SAMLHandler) ExchangeCode(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	loginCode, err := h.samlService.ConsumeLoginCode(req.Code)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "invalid_code",
+			Message: "Code de connexion SAML invalide ou expiré",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.db.Preload("Groups").Preload("AdminOfGroups").First(&user, loginCode.UserID).Error; err != nil || !user.IsActive {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Compte désactivé",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// Rattacher la session de connexion à la session SAML pour qu'un Single Logout de l'IdP la ferme
+	sessionID := middleware.NewSessionID()
+	if err := h.db.Model(&models.SAMLSession{}).
+		Where("id = ? AND user_id = ?", loginCode.SAMLSessionID, user.ID).
+		Update("login_session_id", sessionID).Error; err != nil {
+		log.Printf("[SAML] Erreur lors du rattachement de la session de connexion: %v", err)
+	}
+
+	token, err := h.authMiddleware.GenerateToken(&user, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "token_error",
+			Message: "Failed to generate JWT token",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	refreshToken, err := h.authMiddleware.GenerateRefreshToken(&user, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "token_error",
+			Message: "Failed to generate refresh token",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if err := h.db.Model(&user).Update("last_login", time.Now()).Error; err != nil {
+		log.Printf("[SAML] Erreur lors de la mise à jour de la dernière connexion: %v", err)
+	}
+
+	// Charger les IDs des groupes administrés (nécessaire pour le frontend)
+	var managedGroupIDs []uint
+	h.db.Table("group_admins").
+		Where("user_id = ?", user.ID).
+		Pluck("group_id", &managedGroupIDs)
+	user.ManagedGroupIDs = managedGroupIDs
+
+	user.Password = ""
+
+	c.JSON(http.StatusOK, models.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         user,
+	})
+}
+
+// Logout retourne l'URL de Single Logout de l'IdP si l'utilisateur s'est connecté via SAML
+func (h *SAMLHandler) Logout(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	// Connexion SAML de la session courante, à défaut la plus récente de l'utilisateur
+	var session models.SAMLSession
+	err := gorm.ErrRecordNotFound
+	if sessionID := c.GetString(middleware.SessionIDKey); sessionID != "" {
+		err = h.db.Where("user_id = ? AND login_session_id = ?", userID, sessionID).First(&session).Error
+	}
+	if err != nil {
+		err = h.db.Where("user_id = ? AND ended_at IS NULL", userID).Order("updated_at DESC").First(&session).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"logout_url": ""})
+		return
+	}
+
+	var provider models.SAMLProvider
+	if err := h.db.First(&provider, session.ProviderID).Error; err != nil || !provider.SLOEnabled || !provider.IsEnabled {
+		c.JSON(http.StatusOK, gin.H{"logout_url": ""})
+		return
+	}
+
+	logoutURL, err := h.samlService.MakeLogoutRequest(&provider, session.NameID)
+	if err != nil {
+		log.Printf("[SAML] Erreur lors de la création de la LogoutRequest: %v", err)
+		logoutURL = ""
+	}
+
+	h.db.Delete(&session)
+
+	c.JSON(http.StatusOK, gin.H{"logout_url": logoutURL})
+}
+
+// SingleLogoutService traite les messages de Single Logout de l'IdP : une LogoutRequest (déconnexion
+// initiée par l'IdP) ferme la session correspondante et reçoit une LogoutResponse ; une LogoutResponse
+// (déconnexion initiée par Airboard) redirige vers la page de connexion
+func (h *SAMLHandler) SingleLogoutService(c *gin.Context) {
+	provider, ok := h.findProvider(c, false)
+	if !ok {
+		return
+	}
+
+	if c.Query("SAMLRequest") != "" || c.PostForm("SAMLRequest") != "" {
+		h.handleLogoutRequest(c, provider)
+		return
+	}
+
+	if err := h.samlService.ValidateLogoutResponse(provider, c.Request); err != nil {
+		log.Printf("[SAML] LogoutResponse invalide pour %s: %v", provider.Name, err)
+	}
+
+	c.Redirect(http.StatusFound, strings.TrimSuffix(h.config.Server.PublicURL, "/")+"/auth/login")
+}
+
+// handleLogoutRequest ferme les sessions désignées par une LogoutRequest de l'IdP et lui répond
+func (h *SAMLHandler) handleLogoutRequest(c *gin.Context, provider *models.SAMLProvider) {
+	if !provider.SLOEnabled {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Single Logout is not enabled for this provider",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	logoutRequest, err := h.samlService.ParseLogoutRequest(provider, c.Request)
+	if err == nil {
+		err = h.samlService.ClaimLogoutRequest(provider, logoutRequest)
+	}
+	if err != nil {
+		log.Printf("[SAML] LogoutRequest rejetée pour %s: %v", provider.Name, err)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_logout_request",
+			Message: "Invalid SAML LogoutRequest",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	query := h.db.Where("provider_id = ? AND name_id = ? AND ended_at IS NULL", provider.ID, logoutRequest.NameID.Value)
+	if logoutRequest.SessionIndex != nil && logoutRequest.SessionIndex.Value != "" {
+		query = query.Where("(session_index = ? OR session_index = '')", logoutRequest.SessionIndex.Value)
+	}
+	var sessions []models.SAMLSession
+	if err := query.Find(&sessions).Error; err != nil {
+		log.Printf("[SAML] Erreur lors de la recherche des sessions à fermer: %v", err)
+	}
+	now := time.Now()
+	for _, session := range sessions {
+		if err := h.db.Model(&session).Update("ended_at", now).Error; err != nil {
+			log.Printf("[SAML] Erreur lors de la fermeture de la session %d: %v", session.ID, err)
+			continue
+		}
+		var user models.User
+		if h.db.First(&user, session.UserID).Error == nil {
+			recordAuthEvent(h.audit, c, models.AuditActionLogout, &user, user.Email, "saml:"+provider.Name+" - Single Logout initié par l'IdP")
+		}
+	}
+
+	relayState := c.Query("RelayState")
+	if relayState == "" {
+		relayState = c.PostForm("RelayState")
+	}
+	redirectURL, form, err := h.samlService.MakeLogoutResponse(provider, logoutRequest.ID, relayState)
+	if err != nil {
+		log.Printf("[SAML] Erreur lors de la création de la LogoutResponse: %v", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "server_error",
+			Message: "Failed to build SAML LogoutResponse",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if redirectURL != "" {
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", form)
+}
+
+// GetAllProviders retourne tous les fournisseurs SAML ainsi que le certificat SP (admin uniquement)
+func (h *SAMLHandler) GetAllProviders(c *gin.Context) {
+	var providers []models.SAMLProvider
+	if err := h.db.Order("display_name").Find(&providers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch SAML providers",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	certificate, err := h.samlService.CertificatePEM()
+	if err != nil {
+		log.Printf("[SAML] Erreur lors du chargement du certificat SP: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"providers":      providers,
+		"sp_certificate": certificate,
+	})
+}
+
+// applySAMLRequest copie les champs de la requête dans le fournisseur
+func applySAMLRequest(provider *models.SAMLProvider, req *models.SAMLProviderRequest) {
+	provider.DisplayName = req.DisplayName
+	provider.Icon = req.Icon
+	provider.IsEnabled = req.IsEnabled
+	provider.IDPMetadataURL = strings.TrimSpace(req.IDPMetadataURL)
+	if req.IDPMetadataXML != "" {
+		provider.IDPMetadataXML = req.IDPMetadataXML
+	}
+	provider.NameIDFormat = req.NameIDFormat
+	if provider.NameIDFormat == "" {
+		provider.NameIDFormat = "emailAddress"
+	}
+	if req.SignRequests != nil {
+		provider.SignRequests = *req.SignRequests
+	}
+	provider.AllowIDPInitiated = req.AllowIDPInitiated
+	provider.SLOEnabled = req.SLOEnabled
+	provider.DefaultRole = req.DefaultRole
+	if provider.DefaultRole == "" {
+		provider.DefaultRole = "user"
+	}
+	provider.AdminGroups = req.AdminGroups
+	provider.EmailAttribute = req.EmailAttribute
+	provider.FirstNameAttribute = req.FirstNameAttribute
+	provider.LastNameAttribute = req.LastNameAttribute
+	provider.UsernameAttribute = req.UsernameAttribute
+	provider.GroupsAttribute = req.GroupsAttribute
+	provider.ManagerAttribute = strings.TrimSpace(req.ManagerAttribute)
+	if provider.Icon == "" {
+		provider.Icon = "mdi:shield-account"
+	}
+}
+
+// CreateProvider crée un fournisseur SAML et importe ses métadonnées (admin uniquement)
+func (h *SAMLHandler) CreateProvider(c *gin.Context) {
+	var req models.SAMLProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if !providerNameRegex.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Le nom doit contenir uniquement des minuscules, chiffres et tirets",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.SAMLProvider{}).Where("name = ?", req.Name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "conflict",
+			Message: "Un fournisseur SAML avec ce nom existe déjà",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	provider := models.SAMLProvider{Name: req.Name, SignRequests: true}
+	applySAMLRequest(&provider, &req)
+
+	if err := h.samlService.ImportMetadata(&provider); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "metadata_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.db.Create(&provider).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to create SAML provider",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, provider)
+}
+
+// UpdateProvider met à jour un fournisseur SAML (admin uniquement)
+func (h *SAMLHandler) UpdateProvider(c *gin.Context) {
+	var req models.SAMLProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var provider models.SAMLProvider
+	if err := h.db.First(&provider, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "SAML provider not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	previousURL := provider.IDPMetadataURL
+	applySAMLRequest(&provider, &req)
+
+	// Réimporter si la source des métadonnées a changé
+	if req.IDPMetadataXML != "" || provider.IDPMetadataURL != previousURL {
+		if err := h.samlService.ImportMetadata(&provider); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "metadata_error",
+				Message: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	if err := h.db.Save(&provider).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update SAML provider",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, provider)
+}
+
+// RefreshMetadata réimporte les métadonnées depuis l'URL de l'IdP (admin uniquement)
+func (h *SAMLHandler) RefreshMetadata(c *gin.Context) {
+	var provider models.SAMLProvider
+	if err := h.db.First(&provider, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "SAML provider not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	if provider.IDPMetadataURL == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "Aucune URL de métadonnées configurée",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := h.samlService.ImportMetadata(&provider); err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "metadata_error",
+			Message: err.Error(),
+			Code:    http.StatusBadGateway,
+		})
+		return
+	}
+
+	if err := h.db.Save(&provider).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to update SAML provider",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, provider)
+}
+
+// DeleteProvider supprime un fournisseur SAML et ses sessions (admin uniquement)
+func (h *SAMLHandler) DeleteProvider(c *gin.Context) {
+	var provider models.SAMLProvider
+	if err := h.db.First(&provider, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "SAML provider not found",
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to fetch SAML provider",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.db.Where("provider_id = ?", provider.ID).Delete(&models.SAMLSession{})
+	if err := h.db.Delete(&provider).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Failed to delete SAML provider",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "SAML provider deleted",
+	})
+}
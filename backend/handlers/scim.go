@@ -0,0 +1,1117 @@
+package handlers
+
+import (
+	"airboard/config"
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+	"airboard/utils"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const scimEnterprisePrefix = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:user:"
+
+// SCIMHandler implémente l'API de provisioning SCIM 2.0 (/scim/v2)
+type SCIMHandler struct {
+	db     *gorm.DB
+	config *config.Config
+	org    *services.OrgService
+}
+
+func NewSCIMHandler(db *gorm.DB, cfg *config.Config) *SCIMHandler {
+	return &SCIMHandler{
+		db:     db,
+		config: cfg,
+		org:    services.NewOrgService(db),
+	}
+}
+
+// scimUserUpdate utilisateur en cours de modification et responsable transmis par l'IdP,
+// résolu une fois l'utilisateur enregistré
+type scimUserUpdate struct {
+	*models.User
+	manager    string // id SCIM (ou externalId) du responsable
+	managerSet bool   // Attribut manager transmis (valeur vide = retrait)
+}
+
+var scimUserFilterColumns = map[string]utils.SCIMFilterColumn{
+	"id":              {Column: "CAST(users.id AS TEXT)"},
+	"username":        {Column: "users.username"},
+	"externalid":      {Column: "users.sso_id"},
+	"emails":          {Column: "users.email"},
+	"emails.value":    {Column: "users.email"},
+	"name.givenname":  {Column: "users.first_name"},
+	"name.familyname": {Column: "users.last_name"},
+	"title":           {Column: "users.job_title"},
+	"department":      {Column: "users.department"},
+	"manager.value":   {Column: "CAST(users.manager_id AS TEXT)"},
+	"active":          {Column: "users.is_active", IsBoolean: true},
+}
+
+var scimGroupFilterColumns = map[string]utils.SCIMFilterColumn{
+	"id":          {Column: "CAST(groups.id AS TEXT)"},
+	"displayname": {Column: "groups.name"},
+	"externalid":  {Column: "groups.external_id"},
+}
+
+// writeSCIM envoie une réponse avec le type de contenu SCIM
+func writeSCIM(c *gin.Context, status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, "application/scim+json; charset=utf-8", data)
+}
+
+func scimError(c *gin.Context, status int, scimType, detail string) {
+	writeSCIM(c, status, models.SCIMError{
+		Schemas:  []string{models.SCIMSchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func (h *SCIMHandler) location(resource string, id uint) string {
+	return fmt.Sprintf("%s/scim/v2/%s/%d", strings.TrimSuffix(h.config.Server.PublicURL, "/"), resource, id)
+}
+
+// pagination lit startIndex (base 1) et count
+func scimPagination(c *gin.Context) (int, int) {
+	startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "100"))
+	if err != nil || count < 0 {
+		count = 100
+	}
+	if count > 200 {
+		count = 200
+	}
+	return startIndex, count
+}
+
+// ---------------------------------------------------------------------------
+// Découverte
+// ---------------------------------------------------------------------------
+
+// ServiceProviderConfig décrit les fonctionnalités SCIM supportées
+func (h *SCIMHandler) ServiceProviderConfig(c *gin.Context) {
+	writeSCIM(c, http.StatusOK, gin.H{
+		"schemas":        []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": 200},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Jeton généré dans l'administration Airboard",
+			"primary":     true,
+		}},
+	})
+}
+
+// ResourceTypes liste les ressources exposées
+func (h *SCIMHandler) ResourceTypes(c *gin.Context) {
+	resources := []gin.H{
+		{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   models.SCIMSchemaUser,
+			"schemaExtensions": []gin.H{{
+				"schema":   models.SCIMSchemaEnterpriseUser,
+				"required": false,
+			}},
+		},
+		{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   models.SCIMSchemaGroup,
+		},
+	}
+	writeSCIM(c, http.StatusOK, models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: int64(len(resources)),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Users
+// ---------------------------------------------------------------------------
+
+func (h *SCIMHandler) toSCIMUser(user *models.User) models.SCIMUser {
+	resource := models.SCIMUser{
+		Schemas:  []string{models.SCIMSchemaUser, models.SCIMSchemaEnterpriseUser},
+		ID:       strconv.FormatUint(uint64(user.ID), 10),
+		UserName: user.Username,
+		Name: models.SCIMName{
+			Formatted:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+			GivenName:  user.FirstName,
+			FamilyName: user.LastName,
+		},
+		DisplayName: strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Title:       user.JobTitle,
+		Active:      user.IsActive,
+		Emails:      []models.SCIMMultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Meta: models.SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     h.location("Users", user.ID),
+		},
+	}
+	if user.SSOProvider == services.SCIMProvider {
+		resource.ExternalID = user.SSOID
+	}
+	if user.Phone != "" {
+		resource.Phones = []models.SCIMMultiValue{{Value: user.Phone, Type: "work", Primary: true}}
+	}
+	if user.Location != "" {
+		resource.Addresses = []models.SCIMAddress{{Type: "work", Locality: user.Location, Primary: true}}
+	}
+	if user.Department != "" || user.ManagerID != nil {
+		resource.Enterprise = &models.SCIMEnterpriseUser{Department: user.Department}
+	}
+	if user.ManagerID != nil {
+		resource.Enterprise.Manager = &models.SCIMManager{
+			Value: strconv.FormatUint(uint64(*user.ManagerID), 10),
+			Ref:   h.location("Users", *user.ManagerID),
+		}
+	}
+	for _, g := range user.Groups {
+		resource.Groups = append(resource.Groups, models.SCIMMultiValue{
+			Value:   strconv.FormatUint(uint64(g.ID), 10),
+			Display: g.Name,
+			Ref:     h.location("Groups", g.ID),
+		})
+	}
+	return resource
+}
+
+// scimUsers restreint la requête aux comptes provisionnés par SCIM : les comptes locaux, SSO et LDAP
+// (administrateurs compris) ne sont ni visibles ni modifiables avec un jeton SCIM
+func (h *SCIMHandler) scimUsers() *gorm.DB {
+	return h.db.Model(&models.User{}).
+		Where("users.sso_provider = ? AND users.is_service_account = ?", services.SCIMProvider, false)
+}
+
+// ListUsers liste les utilisateurs provisionnés par SCIM (filtre SCIM et pagination)
+func (h *SCIMHandler) ListUsers(c *gin.Context) {
+	query := h.scimUsers()
+	if filter := c.Query("filter"); filter != "" {
+		clause, args, err := utils.ParseSCIMFilter(filter, scimUserFilterColumns)
+		if err != nil {
+			scimError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+			return
+		}
+		query = query.Where(clause, args...)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors du comptage des utilisateurs")
+		return
+	}
+
+	startIndex, count := scimPagination(c)
+	var users []models.User
+	if count > 0 {
+		if err := query.Preload("Groups").Order("users.id").Offset(startIndex - 1).Limit(count).Find(&users).Error; err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Erreur lors de la récupération des utilisateurs")
+			return
+		}
+	}
+
+	resources := make([]models.SCIMUser, 0, len(users))
+	for i := range users {
+		resources = append(resources, h.toSCIMUser(&users[i]))
+	}
+
+	writeSCIM(c, http.StatusOK, models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (h *SCIMHandler) findUser(c *gin.Context) (*models.User, bool) {
+	var user models.User
+	if err := h.scimUsers().Preload("Groups").First(&user, "users.id = ?", c.Param("id")).Error; err != nil {
+		scimError(c, http.StatusNotFound, "", "Utilisateur introuvable")
+		return nil, false
+	}
+	return &user, true
+}
+
+// GetUser retourne un utilisateur
+func (h *SCIMHandler) GetUser(c *gin.Context) {
+	user, ok := h.findUser(c)
+	if !ok {
+		return
+	}
+	writeSCIM(c, http.StatusOK, h.toSCIMUser(user))
+}
+
+// readSCIMBody décode le corps JSON en map générique
+func readSCIMBody(c *gin.Context) (map[string]interface{}, bool) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", "JSON invalide")
+		return nil, false
+	}
+	return body, true
+}
+
+// CreateUser provisionne un nouvel utilisateur
+func (h *SCIMHandler) CreateUser(c *gin.Context) {
+	body, ok := readSCIMBody(c)
+	if !ok {
+		return
+	}
+
+	user := models.User{
+		Role:        h.config.SSO.DefaultRole,
+		IsActive:    true,
+		SSOProvider: services.SCIMProvider,
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	update := &scimUserUpdate{User: &user}
+	if err := applySCIMUserAttributes(update, body); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	if user.Email == "" && strings.Contains(user.Username, "@") {
+		user.Email = strings.ToLower(user.Username)
+	}
+	if user.Username == "" || user.Email == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "userName et email sont requis")
+		return
+	}
+
+	// Compte déjà créé par le SSO (JIT) : SCIM le reprend au lieu d'en créer un second
+	if h.config.SSO.SCIMAdoptUsers {
+		if existing := h.adoptableUser(user.Email, user.SSOID); existing != nil {
+			h.adoptUser(c, existing, body)
+			return
+		}
+	}
+
+	var count int64
+	h.db.Model(&models.User{}).Where("LOWER(username) = LOWER(?) OR LOWER(email) = LOWER(?)", user.Username, user.Email).Count(&count)
+	if count > 0 {
+		scimError(c, http.StatusConflict, "uniqueness", "Un utilisateur avec ce userName ou cet email existe déjà")
+		return
+	}
+
+	if err := h.db.Create(&user).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors de la création de l'utilisateur")
+		return
+	}
+
+	log.Printf("[SCIM] Utilisateur provisionné: %s (%s)", user.Username, user.Email)
+	if err := h.applySCIMManager(update); err != nil {
+		log.Printf("[SCIM] Responsable de %s non appliqué: %v", user.Email, err)
+	}
+	h.db.Preload("Groups").First(&user, user.ID)
+	go services.NewWebhookService(h.db).UserCreated(&user, "scim")
+	writeSCIM(c, http.StatusCreated, h.toSCIMUser(&user))
+}
+
+// adoptableUser compte créé par une source SSO (en-têtes, OAuth, SAML) que SCIM peut reprendre : même
+// email ou même identifiant externe. Les comptes locaux, LDAP, de service et administrateurs ne sont
+// jamais repris ; une correspondance ambiguë (plusieurs comptes) n'en reprend aucun.
+func (h *SCIMHandler) adoptableUser(email, externalID string) *models.User {
+	query := h.db.Model(&models.User{}).
+		Where("COALESCE(users.sso_provider, '') NOT IN ? AND users.is_service_account = ? AND users.role <> ?",
+			[]string{"", services.SCIMProvider, services.LDAPProvider}, false, "admin")
+	if externalID != "" {
+		query = query.Where("(LOWER(users.email) = LOWER(?) OR users.sso_id = ?)", email, externalID)
+	} else {
+		query = query.Where("LOWER(users.email) = LOWER(?)", email)
+	}
+
+	var users []models.User
+	if err := query.Preload("Groups").Limit(2).Find(&users).Error; err != nil || len(users) != 1 {
+		return nil
+	}
+	return &users[0]
+}
+
+// adoptUser rattache un compte SSO existant à SCIM et lui applique les attributs du POST
+func (h *SCIMHandler) adoptUser(c *gin.Context, user *models.User, body map[string]interface{}) {
+	previousProvider := user.SSOProvider
+	user.SSOProvider = services.SCIMProvider
+	user.SSOID = "" // L'identifiant de l'ancienne source n'est pas un externalId SCIM
+	user.IsActive = true
+
+	update := &scimUserUpdate{User: user}
+	if err := applySCIMUserAttributes(update, body); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	if user.Email == "" && strings.Contains(user.Username, "@") {
+		user.Email = strings.ToLower(user.Username)
+	}
+
+	log.Printf("[SCIM] Compte %s repris (source précédente: %s)", user.Email, previousProvider)
+	h.saveUser(c, update, http.StatusCreated)
+}
+
+// ReplaceUser remplace les attributs d'un utilisateur (PUT)
+func (h *SCIMHandler) ReplaceUser(c *gin.Context) {
+	user, ok := h.findUser(c)
+	if !ok {
+		return
+	}
+	body, ok := readSCIMBody(c)
+	if !ok {
+		return
+	}
+
+	// PUT remplace l'ensemble des attributs mappés (responsable absent = retrait)
+	user.FirstName, user.LastName, user.JobTitle = "", "", ""
+	user.Phone, user.Location, user.Department = "", "", ""
+	update := &scimUserUpdate{User: user, managerSet: true}
+	if err := applySCIMUserAttributes(update, body); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	h.saveUser(c, update, http.StatusOK)
+}
+
+// PatchUser applique des opérations PATCH sur un utilisateur
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+	user, ok := h.findUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	update := &scimUserUpdate{User: user}
+	for _, op := range req.Operations {
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path == "" {
+				values, ok := op.Value.(map[string]interface{})
+				if !ok {
+					err = errors.New("une valeur objet est requise sans path")
+					break
+				}
+				err = applySCIMUserAttributes(update, values)
+			} else {
+				err = setSCIMUserAttribute(update, op.Path, op.Value)
+			}
+		case "remove":
+			if op.Path == "" {
+				err = errors.New("path requis pour remove")
+				break
+			}
+			err = setSCIMUserAttribute(update, op.Path, nil)
+		default:
+			err = fmt.Errorf("opération non supportée: %s", op.Op)
+		}
+		if err != nil {
+			scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+
+	h.saveUser(c, update, http.StatusOK)
+}
+
+// saveUser enregistre l'utilisateur et répond avec status (200 pour PUT/PATCH, 201 pour une reprise)
+func (h *SCIMHandler) saveUser(c *gin.Context, update *scimUserUpdate, status int) {
+	user := update.User
+	if user.Username == "" || user.Email == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "userName et email sont requis")
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.User{}).
+		Where("(LOWER(username) = LOWER(?) OR LOWER(email) = LOWER(?)) AND id <> ?", user.Username, user.Email, user.ID).
+		Count(&count)
+	if count > 0 {
+		scimError(c, http.StatusConflict, "uniqueness", "Un utilisateur avec ce userName ou cet email existe déjà")
+		return
+	}
+
+	// Un rattachement formant une boucle est refusé avant toute modification
+	if update.managerSet && update.manager != "" {
+		if managerID, err := h.resolveSCIMManager(update.manager); err == nil {
+			if err := h.org.ValidateManager(user.ID, managerID); err != nil {
+				scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+				return
+			}
+		}
+	}
+
+	if err := h.db.Omit("Groups", "AdminOfGroups", "Favorites").Save(user).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors de la mise à jour de l'utilisateur")
+		return
+	}
+	if err := h.applySCIMManager(update); err != nil {
+		log.Printf("[SCIM] Responsable de %s non appliqué: %v", user.Email, err)
+	}
+	h.db.Preload("Groups").First(user, user.ID)
+	writeSCIM(c, status, h.toSCIMUser(user))
+}
+
+// DeleteUser déprovisionne un utilisateur : le compte est désactivé, jamais supprimé
+func (h *SCIMHandler) DeleteUser(c *gin.Context) {
+	user, ok := h.findUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Model(user).Update("is_active", false).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors de la désactivation de l'utilisateur")
+		return
+	}
+
+	log.Printf("[SCIM] Utilisateur déprovisionné (désactivé): %s", user.Email)
+	c.Status(http.StatusNoContent)
+}
+
+// resolveSCIMManager retrouve le responsable désigné par son id SCIM, à défaut par son externalId ou son email
+func (h *SCIMHandler) resolveSCIMManager(ref string) (uint, error) {
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		var count int64
+		h.db.Model(&models.User{}).Where("id = ? AND is_service_account = ?", id, false).Count(&count)
+		if count > 0 {
+			return uint(id), nil
+		}
+	}
+	return h.org.ResolveManager(ref)
+}
+
+// applySCIMManager rattache l'utilisateur enregistré au responsable transmis par l'IdP. Un responsable
+// pas encore provisionné est ignoré : l'IdP le renverra lors de la prochaine mise à jour.
+func (h *SCIMHandler) applySCIMManager(update *scimUserUpdate) error {
+	if !update.managerSet {
+		return nil
+	}
+	if update.manager == "" {
+		return h.org.ReleaseManager(update.ID, models.ManagerSourceSCIM)
+	}
+	managerID, err := h.resolveSCIMManager(update.manager)
+	if err != nil {
+		return err
+	}
+	_, err = h.org.SetManager(update.ID, &managerID, models.ManagerSourceSCIM)
+	return err
+}
+
+// applySCIMUserAttributes applique un objet SCIM (POST/PUT ou PATCH sans path)
+func applySCIMUserAttributes(user *scimUserUpdate, values map[string]interface{}) error {
+	for key, value := range values {
+		lower := strings.ToLower(key)
+		switch {
+		case lower == "schemas" || lower == "id" || lower == "meta" || lower == "password" || lower == "groups":
+			// Attributs gérés par le serveur ou ignorés
+		case lower == "name" || lower == strings.TrimSuffix(scimEnterprisePrefix, ":"):
+			nested, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			prefix := "name."
+			if lower != "name" {
+				prefix = scimEnterprisePrefix
+			}
+			for sub, v := range nested {
+				if err := setSCIMUserAttribute(user, prefix+sub, v); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := setSCIMUserAttribute(user, key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setSCIMUserAttribute affecte un attribut identifié par son path SCIM (nil = suppression)
+func setSCIMUserAttribute(user *scimUserUpdate, path string, value interface{}) error {
+	attr := normalizeSCIMPath(path)
+
+	switch attr {
+	case "username":
+		user.Username = scimString(value)
+	case "externalid":
+		user.SSOID = scimString(value)
+	case "active":
+		if value == nil {
+			return nil
+		}
+		active, err := scimBool(value)
+		if err != nil {
+			return err
+		}
+		user.IsActive = active
+	case "name.givenname":
+		user.FirstName = scimString(value)
+	case "name.familyname":
+		user.LastName = scimString(value)
+	case "name.formatted", "displayname", "nickname", "preferredlanguage", "locale", "timezone", "usertype":
+		// Non mappés
+	case "title":
+		user.JobTitle = scimString(value)
+	case "department":
+		user.Department = scimString(value)
+	case "manager", "manager.value":
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = nested["value"]
+		}
+		user.manager = scimString(value)
+		user.managerSet = true
+	case "emails", "emails.value":
+		if email := scimPrimaryValue(value, "value"); email != "" || value == nil {
+			user.Email = strings.ToLower(email)
+		}
+	case "phonenumbers", "phonenumbers.value":
+		user.Phone = scimPrimaryValue(value, "value")
+	case "addresses", "addresses.locality":
+		user.Location = scimPrimaryValue(value, "locality")
+	default:
+		// Les attributs inconnus sont ignorés (RFC 7644 autorise le serveur à ne pas les stocker)
+	}
+	return nil
+}
+
+// normalizeSCIMPath retire les filtres de valeur et le préfixe d'extension d'un path
+// (ex: `emails[type eq "work"].value` -> "emails.value")
+func normalizeSCIMPath(path string) string {
+	lower := strings.ToLower(strings.TrimSpace(path))
+	lower = strings.TrimPrefix(lower, scimEnterprisePrefix)
+	lower = strings.TrimPrefix(lower, strings.ToLower(models.SCIMSchemaUser)+":")
+	if start := strings.Index(lower, "["); start >= 0 {
+		if end := strings.Index(lower[start:], "]"); end >= 0 {
+			lower = lower[:start] + lower[start+end+1:]
+		}
+	}
+	return lower
+}
+
+func scimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// scimBool accepte true/false ainsi que "True"/"False" (envoyés par certains IdP)
+func scimBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.ToLower(v))
+	default:
+		return false, fmt.Errorf("valeur booléenne invalide: %v", value)
+	}
+}
+
+// scimPrimaryValue extrait la valeur principale d'un attribut multi-valué
+func scimPrimaryValue(value interface{}, field string) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]interface{}:
+		return scimString(v[field])
+	case []interface{}:
+		first := ""
+		for _, item := range v {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if primary, _ := scimBool(entry["primary"]); primary {
+				return scimString(entry[field])
+			}
+			if first == "" {
+				first = scimString(entry[field])
+			}
+		}
+		return first
+	}
+	return ""
+}
+
+// ---------------------------------------------------------------------------
+// Groups
+// ---------------------------------------------------------------------------
+
+func (h *SCIMHandler) toSCIMGroup(group *models.Group, includeMembers bool) models.SCIMGroup {
+	resource := models.SCIMGroup{
+		Schemas:     []string{models.SCIMSchemaGroup},
+		ID:          strconv.FormatUint(uint64(group.ID), 10),
+		ExternalID:  group.ExternalID,
+		DisplayName: group.Name,
+		Members:     []models.SCIMMultiValue{},
+		Meta: models.SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     h.location("Groups", group.ID),
+		},
+	}
+	if includeMembers {
+		for _, u := range group.Users {
+			resource.Members = append(resource.Members, models.SCIMMultiValue{
+				Value:   strconv.FormatUint(uint64(u.ID), 10),
+				Display: u.Username,
+				Ref:     h.location("Users", u.ID),
+			})
+		}
+	}
+	return resource
+}
+
+// scimGroups restreint une requête aux groupes actifs provisionnés par SCIM : les groupes créés
+// dans Airboard ne sont ni exposés ni modifiables par le fournisseur d'identité
+func (h *SCIMHandler) scimGroups() *gorm.DB {
+	return h.db.Model(&models.Group{}).
+		Where("groups.is_active = ?", true).
+		Where("groups.source = ? OR groups.external_id <> ''", services.SCIMProvider)
+}
+
+// ListGroups liste les groupes actifs provisionnés par SCIM (filtre SCIM et pagination)
+func (h *SCIMHandler) ListGroups(c *gin.Context) {
+	query := h.scimGroups()
+	if filter := c.Query("filter"); filter != "" {
+		clause, args, err := utils.ParseSCIMFilter(filter, scimGroupFilterColumns)
+		if err != nil {
+			scimError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+			return
+		}
+		query = query.Where(clause, args...)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors du comptage des groupes")
+		return
+	}
+
+	includeMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	startIndex, count := scimPagination(c)
+	var groups []models.Group
+	if count > 0 {
+		q := query.Order("groups.id").Offset(startIndex - 1).Limit(count)
+		if includeMembers {
+			q = q.Preload("Users")
+		}
+		if err := q.Find(&groups).Error; err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Erreur lors de la récupération des groupes")
+			return
+		}
+	}
+
+	resources := make([]models.SCIMGroup, 0, len(groups))
+	for i := range groups {
+		resources = append(resources, h.toSCIMGroup(&groups[i], includeMembers))
+	}
+
+	writeSCIM(c, http.StatusOK, models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (h *SCIMHandler) findGroup(c *gin.Context) (*models.Group, bool) {
+	var group models.Group
+	if err := h.scimGroups().Preload("Users").First(&group, "groups.id = ?", c.Param("id")).Error; err != nil {
+		scimError(c, http.StatusNotFound, "", "Groupe introuvable")
+		return nil, false
+	}
+	return &group, true
+}
+
+// GetGroup retourne un groupe et ses membres
+func (h *SCIMHandler) GetGroup(c *gin.Context) {
+	group, ok := h.findGroup(c)
+	if !ok {
+		return
+	}
+	includeMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	writeSCIM(c, http.StatusOK, h.toSCIMGroup(group, includeMembers))
+}
+
+// CreateGroup provisionne un groupe (un groupe SCIM désactivé du même nom est réactivé)
+func (h *SCIMHandler) CreateGroup(c *gin.Context) {
+	body, ok := readSCIMBody(c)
+	if !ok {
+		return
+	}
+
+	name := scimString(body["displayName"])
+	if name == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "displayName requis")
+		return
+	}
+
+	var group models.Group
+	err := h.db.Where("name = ?", name).First(&group).Error
+	switch {
+	case err == nil && (group.IsActive || group.Source != services.SCIMProvider):
+		scimError(c, http.StatusConflict, "uniqueness", "Un groupe avec ce nom existe déjà")
+		return
+	case err == nil:
+		group.IsActive = true
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		group = models.Group{Name: name, Description: "Provisionné via SCIM", IsActive: true, Source: services.SCIMProvider}
+	default:
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors de la recherche du groupe")
+		return
+	}
+	group.ExternalID = scimString(body["externalId"])
+
+	if err := h.db.Omit("Users", "AppGroups").Save(&group).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors de la création du groupe")
+		return
+	}
+
+	if members, ok := body["members"]; ok {
+		if err := h.setGroupMembers(&group, scimMemberIDs(members), "replace"); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+
+	log.Printf("[SCIM] Groupe provisionné: %s", group.Name)
+	h.db.Preload("Users").First(&group, group.ID)
+	writeSCIM(c, http.StatusCreated, h.toSCIMGroup(&group, true))
+}
+
+// ReplaceGroup remplace le nom et les membres d'un groupe (PUT)
+func (h *SCIMHandler) ReplaceGroup(c *gin.Context) {
+	group, ok := h.findGroup(c)
+	if !ok {
+		return
+	}
+	body, ok := readSCIMBody(c)
+	if !ok {
+		return
+	}
+
+	if name := scimString(body["displayName"]); name != "" {
+		group.Name = name
+	}
+	group.ExternalID = scimString(body["externalId"])
+	if err := h.db.Omit("Users", "AppGroups").Save(group).Error; err != nil {
+		scimError(c, http.StatusConflict, "uniqueness", "Impossible de renommer le groupe")
+		return
+	}
+
+	if err := h.setGroupMembers(group, scimMemberIDs(body["members"]), "replace"); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	h.db.Preload("Users").First(group, group.ID)
+	writeSCIM(c, http.StatusOK, h.toSCIMGroup(group, true))
+}
+
+// PatchGroup applique des opérations PATCH (displayName, ajout/retrait de membres)
+func (h *SCIMHandler) PatchGroup(c *gin.Context) {
+	group, ok := h.findGroup(c)
+	if !ok {
+		return
+	}
+
+	var req models.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	for _, op := range req.Operations {
+		action := strings.ToLower(op.Op)
+		path := strings.ToLower(strings.TrimSpace(op.Path))
+
+		var err error
+		switch {
+		case path == "" && (action == "add" || action == "replace"):
+			values, ok := op.Value.(map[string]interface{})
+			if !ok {
+				err = errors.New("une valeur objet est requise sans path")
+				break
+			}
+			for key, value := range values {
+				switch strings.ToLower(key) {
+				case "displayname":
+					group.Name = scimString(value)
+				case "externalid":
+					group.ExternalID = scimString(value)
+				case "members":
+					err = h.setGroupMembers(group, scimMemberIDs(value), action)
+				}
+			}
+		case path == "displayname":
+			group.Name = scimString(op.Value)
+		case path == "externalid":
+			group.ExternalID = scimString(op.Value)
+		case path == "members" && action == "remove" && op.Value == nil:
+			err = h.setGroupMembers(group, nil, "replace")
+		case path == "members":
+			err = h.setGroupMembers(group, scimMemberIDs(op.Value), action)
+		case strings.HasPrefix(path, "members[") && action == "remove":
+			// members[value eq "42"]
+			err = h.setGroupMembers(group, scimMemberIDs(scimFilterValue(op.Path)), "remove")
+		default:
+			err = fmt.Errorf("opération non supportée: %s %s", op.Op, op.Path)
+		}
+		if err != nil {
+			scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+
+	if group.Name == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "displayName requis")
+		return
+	}
+	if err := h.db.Omit("Users", "AppGroups").Save(group).Error; err != nil {
+		scimError(c, http.StatusConflict, "uniqueness", "Impossible de renommer le groupe")
+		return
+	}
+
+	h.db.Preload("Users").First(group, group.ID)
+	writeSCIM(c, http.StatusOK, h.toSCIMGroup(group, true))
+}
+
+// DeleteGroup déprovisionne un groupe : il est désactivé et vidé de ses membres provisionnés par SCIM
+// (les membres ajoutés dans Airboard sont conservés)
+func (h *SCIMHandler) DeleteGroup(c *gin.Context) {
+	group, ok := h.findGroup(c)
+	if !ok {
+		return
+	}
+
+	if err := h.setGroupMembers(group, nil, "replace"); err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors du retrait des membres du groupe")
+		return
+	}
+	if err := h.db.Model(group).Update("is_active", false).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Erreur lors de la désactivation du groupe")
+		return
+	}
+
+	log.Printf("[SCIM] Groupe déprovisionné (désactivé): %s", group.Name)
+	c.Status(http.StatusNoContent)
+}
+
+// setGroupMembers ajoute, retire ou remplace les membres provisionnés par SCIM d'un groupe (table user_groups)
+func (h *SCIMHandler) setGroupMembers(group *models.Group, memberIDs []uint, action string) error {
+	var users []models.User
+	if len(memberIDs) > 0 {
+		if err := h.scimUsers().Where("users.id IN ?", memberIDs).Find(&users).Error; err != nil {
+			return err
+		}
+		if len(users) != len(memberIDs) {
+			return errors.New("un ou plusieurs membres sont introuvables")
+		}
+	}
+
+	association := h.db.Model(group).Association("Users")
+	switch action {
+	case "add":
+		if len(users) == 0 {
+			return nil
+		}
+		return association.Append(users)
+	case "remove":
+		if len(users) == 0 {
+			return nil
+		}
+		return association.Delete(users)
+	default:
+		// Les membres hors SCIM (comptes locaux, SSO, LDAP) ne sont pas gérés par l'IdP : ils sont conservés
+		var kept []models.User
+		if err := h.db.Model(group).Where("users.sso_provider <> ? OR users.is_service_account = ?", services.SCIMProvider, true).
+			Association("Users").Find(&kept); err != nil {
+			return err
+		}
+		return association.Replace(append(users, kept...))
+	}
+}
+
+// scimMemberIDs extrait les IDs d'une liste de membres [{"value": "42"}, ...]
+func scimMemberIDs(value interface{}) []uint {
+	var ids []uint
+	add := func(v interface{}) {
+		if id, err := strconv.ParseUint(scimString(v), 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if entry, ok := item.(map[string]interface{}); ok {
+				add(entry["value"])
+			} else {
+				add(item)
+			}
+		}
+	case map[string]interface{}:
+		add(v["value"])
+	case string:
+		add(v)
+	}
+	return ids
+}
+
+// scimFilterValue extrait la valeur d'un filtre simple de path (ex: members[value eq "42"])
+func scimFilterValue(path string) string {
+	start := strings.Index(path, "\"")
+	end := strings.LastIndex(path, "\"")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return path[start+1 : end]
+}
+
+// ---------------------------------------------------------------------------
+// Administration des jetons SCIM
+// ---------------------------------------------------------------------------
+
+// ListTokens liste les jetons SCIM (sans leur valeur)
+func (h *SCIMHandler) ListTokens(c *gin.Context) {
+	var tokens []models.SCIMToken
+	if err := h.db.Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des jetons SCIM",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens":   tokens,
+		"base_url": strings.TrimSuffix(h.config.Server.PublicURL, "/") + "/scim/v2",
+	})
+}
+
+// CreateToken génère un jeton SCIM ; sa valeur n'est retournée qu'une seule fois
+func (h *SCIMHandler) CreateToken(c *gin.Context) {
+	var req models.SCIMTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "token_error",
+			Message: "Erreur lors de la génération du jeton",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	plain := "scim_" + base64.RawURLEncoding.EncodeToString(raw)
+
+	userID, _ := c.Get("user_id")
+	token := models.SCIMToken{
+		Name:        req.Name,
+		TokenHash:   middleware.HashSCIMToken(plain),
+		TokenPrefix: plain[:12],
+	}
+	if id, ok := userID.(uint); ok {
+		token.CreatedByID = id
+	}
+	if req.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expires
+	}
+
+	if err := h.db.Create(&token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de l'enregistrement du jeton",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":       token,
+		"plain_token": plain,
+	})
+}
+
+// RevokeToken révoque un jeton SCIM
+func (h *SCIMHandler) RevokeToken(c *gin.Context) {
+	var token models.SCIMToken
+	if err := h.db.First(&token, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Jeton SCIM introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	now := time.Now()
+	if err := h.db.Model(&token).Update("revoked_at", now).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la révocation du jeton",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Jeton SCIM révoqué",
+	})
+}
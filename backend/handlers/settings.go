@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"airboard/middleware"
 	"airboard/models"
 	"net/http"
 
@@ -95,6 +96,11 @@ func (h *SettingsHandler) UpdateAppSettings(c *gin.Context) {
 				SignupEnabled:   signupEnabled,
 				DefaultGroupID:  request.DefaultGroupID,
 			}
+			if request.AuditRetentionDays != nil {
+				settings.AuditRetentionDays = *request.AuditRetentionDays
+			}
+			applyPasswordPolicySettings(&settings, &request)
+			applyNewsWorkflowSettings(&settings, &request)
 
 			if err := h.DB.Create(&settings).Error; err != nil {
 				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -114,6 +120,7 @@ func (h *SettingsHandler) UpdateAppSettings(c *gin.Context) {
 		}
 	} else {
 		// Mettre à jour les paramètres existants
+		middleware.AuditBefore(c, settings)
 		settings.AppName = request.AppName
 		settings.AppIcon = request.AppIcon
 		settings.DashboardTitle = request.DashboardTitle
@@ -123,6 +130,11 @@ func (h *SettingsHandler) UpdateAppSettings(c *gin.Context) {
 			settings.SignupEnabled = *request.SignupEnabled
 		}
 		settings.DefaultGroupID = request.DefaultGroupID
+		if request.AuditRetentionDays != nil {
+			settings.AuditRetentionDays = *request.AuditRetentionDays
+		}
+		applyPasswordPolicySettings(&settings, &request)
+		applyNewsWorkflowSettings(&settings, &request)
 
 		if err := h.DB.Save(&settings).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -145,6 +157,41 @@ func (h *SettingsHandler) UpdateAppSettings(c *gin.Context) {
 	})
 }
 
+// applyPasswordPolicySettings applique les champs de politique de mots de passe fournis
+func applyPasswordPolicySettings(settings *models.AppSettings, request *models.AppSettingsRequest) {
+	if request.PasswordMinLength != nil {
+		settings.PasswordMinLength = *request.PasswordMinLength
+	}
+	if request.PasswordRequireUpper != nil {
+		settings.PasswordRequireUpper = *request.PasswordRequireUpper
+	}
+	if request.PasswordRequireLower != nil {
+		settings.PasswordRequireLower = *request.PasswordRequireLower
+	}
+	if request.PasswordRequireDigit != nil {
+		settings.PasswordRequireDigit = *request.PasswordRequireDigit
+	}
+	if request.PasswordRequireSpecial != nil {
+		settings.PasswordRequireSpecial = *request.PasswordRequireSpecial
+	}
+	if request.PasswordMaxAgeDays != nil {
+		settings.PasswordMaxAgeDays = *request.PasswordMaxAgeDays
+	}
+	if request.PasswordHistoryDepth != nil {
+		settings.PasswordHistoryDepth = *request.PasswordHistoryDepth
+	}
+	if request.PasswordCheckBreached != nil {
+		settings.PasswordCheckBreached = *request.PasswordCheckBreached
+	}
+}
+
+// applyNewsWorkflowSettings applique les paramètres du workflow éditorial fournis
+func applyNewsWorkflowSettings(settings *models.AppSettings, request *models.AppSettingsRequest) {
+	if request.NewsApprovalRequired != nil {
+		settings.NewsApprovalRequired = *request.NewsApprovalRequired
+	}
+}
+
 // ResetAppSettings remet les paramètres aux valeurs par défaut
 func (h *SettingsHandler) ResetAppSettings(c *gin.Context) {
 	var settings models.AppSettings
@@ -161,12 +208,23 @@ func (h *SettingsHandler) ResetAppSettings(c *gin.Context) {
 	}
 
 	// Remettre aux valeurs par défaut
+	middleware.AuditBefore(c, settings)
 	settings.AppName = "Airboard"
 	settings.AppIcon = "mdi:view-dashboard"
 	settings.DashboardTitle = "Dashboard"
 	settings.WelcomeMessage = "Welcome to your application portal"
 	settings.HomePageMessage = "Discover your personalized workspace"
 	settings.SignupEnabled = true
+	settings.AuditRetentionDays = 365
+	settings.PasswordMinLength = 8
+	settings.PasswordRequireUpper = true
+	settings.PasswordRequireLower = true
+	settings.PasswordRequireDigit = true
+	settings.PasswordRequireSpecial = true
+	settings.PasswordMaxAgeDays = 0
+	settings.PasswordHistoryDepth = 5
+	settings.PasswordCheckBreached = true
+	settings.NewsApprovalRequired = true
 
 	if result.Error == gorm.ErrRecordNotFound {
 		// Créer de nouveaux paramètres avec les valeurs par défaut
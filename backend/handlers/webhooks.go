@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"airboard/middleware"
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler gère la configuration des webhooks sortants et leur journal de livraisons
+type WebhookHandler struct {
+	db       *gorm.DB
+	webhooks *services.WebhookService
+}
+
+// NewWebhookHandler crée une nouvelle instance de WebhookHandler
+func NewWebhookHandler(db *gorm.DB, webhooks *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		db:       db,
+		webhooks: webhooks,
+	}
+}
+
+// ListWebhookEvents retourne les événements disponibles
+func (h *WebhookHandler) ListWebhookEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, models.WebhookEvents)
+}
+
+// ListWebhooks retourne les webhooks configurés
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	var webhooks []models.Webhook
+	if err := h.db.Order("name").Find(&webhooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des webhooks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook crée un webhook ; la clé de signature n'est retournée qu'à la création
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req models.WebhookRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	secret, err := services.GenerateWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Erreur lors de la génération de la clé de signature",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	webhook := models.Webhook{
+		Name:        req.Name,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      strings.Join(req.Events, ","),
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		CreatedByID: c.GetUint("user_id"),
+	}
+	if err := h.db.Create(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la création du webhook",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	// GORM ignore la valeur false du champ booléen avec valeur par défaut
+	if !webhook.IsActive {
+		h.db.Model(&webhook).Update("is_active", false)
+	}
+
+	middleware.SetAuditTarget(c, "webhooks", webhook.ID, webhook.Name)
+	c.JSON(http.StatusCreated, gin.H{
+		"secret":  secret,
+		"webhook": webhook,
+		"message": "Conservez cette clé : elle ne sera plus affichée",
+	})
+}
+
+// UpdateWebhook met à jour un webhook ; la réactivation remet le compteur d'échecs à zéro
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req models.WebhookRequest
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	middleware.AuditBefore(c, webhook)
+
+	updates := map[string]interface{}{
+		"name":   req.Name,
+		"url":    req.URL,
+		"events": strings.Join(req.Events, ","),
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+		if *req.IsActive && !webhook.IsActive {
+			updates["consecutive_failures"] = 0
+			updates["disabled_at"] = nil
+			updates["disabled_reason"] = ""
+		}
+	}
+
+	if err := h.db.Model(webhook).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la mise à jour du webhook",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	h.db.First(webhook, webhook.ID)
+	middleware.AuditAfter(c, webhook)
+	c.JSON(http.StatusOK, webhook)
+}
+
+// RotateWebhookSecret génère une nouvelle clé de signature
+func (h *WebhookHandler) RotateWebhookSecret(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	secret, err := services.GenerateWebhookSecret()
+	if err == nil {
+		err = h.db.Model(webhook).Update("secret", secret).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Erreur lors du renouvellement de la clé de signature",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":  secret,
+		"message": "Conservez cette clé : elle ne sera plus affichée",
+	})
+}
+
+// DeleteWebhook supprime un webhook et son journal de livraisons
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	middleware.AuditBefore(c, webhook)
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la suppression du webhook",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Webhook supprimé",
+	})
+}
+
+// TestWebhook envoie immédiatement un événement "ping"
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhooks.Ping(webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Erreur lors de l'envoi du test",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ListDeliveries retourne le journal des livraisons (filtrable par webhook_id, event et status)
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	query := h.db.Model(&models.WebhookDelivery{})
+	if webhookID := c.Query("webhook_id"); webhookID != "" {
+		query = query.Where("webhook_id = ?", webhookID)
+	}
+	if event := c.Query("event"); event != "" {
+		query = query.Where("event = ?", event)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	// Le contenu envoyé n'est retourné que par GetDelivery
+	var deliveries []models.WebhookDelivery
+	if err := query.Omit("payload").Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors de la récupération des livraisons",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       deliveries,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// GetDelivery retourne une livraison avec le contenu envoyé et la réponse reçue
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	delivery, ok := h.findDelivery(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ReplayDelivery remet en file une livraison (même contenu, nouvelle entrée du journal)
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	original, ok := h.findDelivery(c)
+	if !ok {
+		return
+	}
+
+	middleware.SetAuditTarget(c, "webhook_deliveries", original.ID, original.Event)
+	delivery, err := h.webhooks.Replay(original)
+	if err == services.ErrWebhookDisabled {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "webhook_disabled",
+			Message: "Le webhook est désactivé : réactivez-le avant de rejouer la livraison",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erreur lors du rejeu de la livraison",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// bindRequest valide la requête : URL http(s) et événements connus
+func (h *WebhookHandler) bindRequest(c *gin.Context, req *models.WebhookRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return false
+	}
+
+	if parsed, err := url.Parse(req.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "validation_error",
+			Message: "L'URL doit utiliser le schéma http ou https",
+			Code:    http.StatusBadRequest,
+		})
+		return false
+	}
+
+	for i, event := range req.Events {
+		event = strings.TrimSpace(event)
+		req.Events[i] = event
+		if event == "*" {
+			continue
+		}
+		known := false
+		for _, available := range models.WebhookEvents {
+			if event == available {
+				known = true
+				break
+			}
+		}
+		if !known {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "validation_error",
+				Message: "Événement inconnu: " + event,
+				Code:    http.StatusBadRequest,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+func (h *WebhookHandler) findWebhook(c *gin.Context) (*models.Webhook, bool) {
+	var webhook models.Webhook
+	if err := h.db.First(&webhook, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Webhook introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	middleware.SetAuditTarget(c, "webhooks", webhook.ID, webhook.Name)
+	return &webhook, true
+}
+
+func (h *WebhookHandler) findDelivery(c *gin.Context) (*models.WebhookDelivery, bool) {
+	var delivery models.WebhookDelivery
+	if err := h.db.Preload("Webhook").First(&delivery, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "Livraison introuvable",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	return &delivery, true
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"airboard/config"
@@ -12,6 +15,7 @@ import (
 	"airboard/models"
 	"airboard/services"
 	"airboard/services/chat" // Import chat service
+	"airboard/utils"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -40,6 +44,36 @@ func main() {
 		&models.Application{},
 		&models.AppSettings{},
 		&models.OAuthProvider{},
+		&models.SAMLProvider{}, // SAML 2.0
+		&models.SAMLCredential{},
+		&models.SAMLSession{},
+		&models.SAMLPendingRequest{},
+		&models.SAMLLoginCode{},
+		&models.SAMLLogoutRequestID{},
+		&models.LDAPConfig{}, // LDAP / Active Directory
+		&models.LDAPGroupMapping{},
+		&models.SCIMToken{}, // Provisioning SCIM 2.0
+		&models.Role{},      // Rôles et permissions
+		&models.RoleAssignment{},
+		&models.AuditLog{}, // Journal d'audit
+		&models.APIToken{}, // Jetons d'API (accès personnels et comptes de service)
+		&models.Webhook{},  // Webhooks sortants
+		&models.WebhookDelivery{},
+		&models.RateLimitPolicy{}, // Limitation des requêtes
+		&models.RateLimitCounter{},
+		&models.RateLimitMetric{},
+		&models.PasswordHistory{},      // Historique des mots de passe (politique de réutilisation)
+		&models.LoginLockout{},         // Verrouillages après échecs de connexion
+		&models.LoginDevice{},          // Appareils connus (alertes de nouvelle connexion)
+		&models.ImpersonationSession{}, // Sessions d'usurpation d'identité (support)
+		&models.PrivacyRequest{},       // Demandes RGPD (export et effacement)
+		&models.ContentRevision{},      // Historique des versions des news et événements
+		&models.NewsReviewer{},         // Relecteurs des news (workflow éditorial)
+		&models.NewsWorkflowEvent{},    // Historique du workflow éditorial
+		&models.FeedToken{},            // Jetons d'abonnement aux flux RSS/Atom
+		&models.ProfilePrivacy{},       // Confidentialité des profils dans l'annuaire
+		// Accusés de lecture des contenus à lecture obligatoire
+		&models.ContentAcknowledgement{},
 		&models.ApplicationClick{},
 		&models.Announcement{},
 		&models.News{},
@@ -81,6 +115,20 @@ func main() {
 		log.Printf("Avertissement: Impossible de créer l'index unique pour poll_votes: %v", err)
 	}
 
+	// Journal d'audit append-only : les entrées ne peuvent pas être modifiées (la suppression reste possible pour la rétention)
+	if err := db.Exec(`CREATE OR REPLACE FUNCTION audit_logs_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs est en ajout seul';
+END;
+$$ LANGUAGE plpgsql`).Error; err != nil {
+		log.Printf("Avertissement: Impossible de créer la fonction de protection du journal d'audit: %v", err)
+	} else {
+		db.Exec("DROP TRIGGER IF EXISTS trg_audit_logs_immutable ON audit_logs")
+		if err := db.Exec("CREATE TRIGGER trg_audit_logs_immutable BEFORE UPDATE ON audit_logs FOR EACH ROW EXECUTE FUNCTION audit_logs_immutable()").Error; err != nil {
+			log.Printf("Avertissement: Impossible de protéger le journal d'audit: %v", err)
+		}
+	}
+
 	// Fix: Corriger les contraintes d'unicité sur les slugs pour permettre la réutilisation après soft delete
 	// News slug
 	db.Exec("DROP INDEX IF EXISTS idx_news_slug")
@@ -138,6 +186,42 @@ func main() {
 		log.Println("✓ Index unique partiel créé/vérifié pour event_categories.slug")
 	}
 
+	// Sessions SAML : une ligne par connexion, l'ancien index unique utilisateur/fournisseur est supprimé
+	db.Exec("DROP INDEX IF EXISTS idx_saml_session_user_provider")
+
+	// Workflow éditorial : les news publiées avant son introduction passent à l'état published
+	if err := db.Exec("UPDATE news SET status = ? WHERE is_published = ? AND (status = ? OR status IS NULL)",
+		models.NewsStatusPublished, true, models.NewsStatusDraft).Error; err != nil {
+		log.Printf("Avertissement: Impossible d'initialiser l'état éditorial des news: %v", err)
+	}
+
+	// Les news déjà publiées ont été diffusées : une republication ne doit pas renotifier leurs lecteurs
+	if err := db.Exec(`UPDATE news SET announced_at = COALESCE(published_at, created_at)
+		WHERE announced_at IS NULL AND (is_published = ? OR EXISTS (
+			SELECT 1 FROM news_workflow_events e WHERE e.news_id = news.id AND e.to_status = ?))`,
+		true, models.NewsStatusPublished).Error; err != nil {
+		log.Printf("Avertissement: Impossible d'initialiser la date de diffusion des news: %v", err)
+	}
+
+	// Temps de lecture des news enregistrées avant son calcul côté serveur
+	var unmeasured []models.News
+	if err := db.Select("id", "content").Where("reading_time = 0 AND content <> ''").
+		FindInBatches(&unmeasured, 100, func(tx *gorm.DB, batch int) error {
+			for _, news := range unmeasured {
+				db.Model(&models.News{}).Where("id = ?", news.ID).
+					UpdateColumn("reading_time", utils.TiptapReadingTime(news.Content))
+			}
+			return nil
+		}).Error; err != nil {
+		log.Printf("Avertissement: Impossible de calculer le temps de lecture des news: %v", err)
+	}
+
+	// Recherche plein texte : vecteurs pondérés maintenus par trigger et index GIN
+	searchService := services.NewSearchService(db)
+	if err := searchService.EnsureSchema(); err != nil {
+		log.Printf("Avertissement: Impossible d'initialiser la recherche plein texte: %v", err)
+	}
+
 	// Créer les données initiales
 	if err := createInitialData(db, cfg); err != nil {
 		log.Fatalf("Erreur lors de la création des données initiales: %v", err)
@@ -154,8 +238,22 @@ func main() {
 
 	// Initialisation des middlewares
 	authMiddleware := middleware.NewAuthMiddleware(cfg, db)
+	if err := authMiddleware.Permissions().SeedSystemRoles(); err != nil {
+		log.Printf("Avertissement: Impossible de créer les rôles système: %v", err)
+	}
 	ssoMiddleware := middleware.NewSSOMiddleware(db, cfg)
-	csrfManager := middleware.NewCSRFManager()
+	auditService := services.NewAuditService(db)
+	auditService.StartRetentionScheduler()
+	auditMiddleware := middleware.NewAuditMiddleware(auditService)
+	csrfManager := middleware.NewCSRFManager(cfg.JWT.Secret)
+
+	// Limitation des requêtes (compteurs partagés entre instances via PostgreSQL par défaut)
+	rateLimitService := services.NewRateLimitService(db, services.NewRateLimitStore(db, cfg.RateLimit.Store))
+	if err := rateLimitService.SeedDefaultPolicies(); err != nil {
+		log.Printf("Avertissement: Impossible de créer les politiques de limitation par défaut: %v", err)
+	}
+	rateLimitService.StartScheduler()
+	rateLimiter := middleware.NewRateLimiter(rateLimitService, cfg.RateLimit.Enabled)
 
 	mediaHandler := handlers.NewMediaHandler(db, storageService)
 
@@ -163,18 +261,68 @@ func main() {
 	gamificationService := services.NewGamificationService(db)
 
 	// Initialisation des handlers
-	authHandler := handlers.NewAuthHandler(db, authMiddleware, cfg.Server.SignupEnabled, cfg, gamificationService)
+	// Annuaire LDAP / Active Directory (authentification en repli + synchronisation planifiée)
+	ldapService := services.NewLDAPService(db, cfg)
+	ldapService.StartScheduler()
+
+	// Webhooks sortants (file de livraisons persistée)
+	webhookService := services.NewWebhookService(db)
+	webhookService.StartWorker()
+
+	// Politique de mots de passe (liste de fuites chargée hors ligne si configurée)
+	var breachedPasswords *services.BreachedPasswordList
+	if cfg.Security.BreachedPasswordsPath != "" {
+		breachedPasswords, err = services.LoadBreachedPasswordList(cfg.Security.BreachedPasswordsPath)
+		if err != nil {
+			log.Printf("Avertissement: Liste de mots de passe compromis non chargée: %v", err)
+		} else {
+			log.Printf("Liste de mots de passe compromis chargée (%d entrées)", breachedPasswords.Size())
+		}
+	}
+	passwordPolicyService := services.NewPasswordPolicyService(db, breachedPasswords)
+
+	// Verrouillage des connexions et alertes de sécurité (état partagé en base)
+	loginSecurityService := services.NewLoginSecurityService(db, cfg)
+	loginSecurityService.StartScheduler()
+
+	// Demandes RGPD : exports et effacements traités en arrière-plan
+	privacyService := services.NewPrivacyService(db, cfg.Privacy.ExportDir, cfg.Privacy.ExportRetention)
+	privacyService.StartWorker()
+
+	// Workflow éditorial des news : relecture, approbation et publication planifiée
+	newsWorkflowService := services.NewNewsWorkflowService(db, cfg, authMiddleware.Permissions())
+	newsWorkflowService.StartScheduler()
+
+	// Lecture obligatoire : relance à échéance des utilisateurs n'ayant pas accusé lecture
+	acknowledgementService := services.NewAcknowledgementService(db, cfg)
+	acknowledgementService.StartScheduler()
+
+	authHandler := handlers.NewAuthHandler(db, authMiddleware, cfg.Server.SignupEnabled, cfg, gamificationService, ldapService, passwordPolicyService)
 	dashboardHandler := handlers.NewDashboardHandler(db)
-	adminHandler := handlers.NewAdminHandler(db, cfg, gamificationService)
+	adminHandler := handlers.NewAdminHandler(db, cfg, gamificationService, passwordPolicyService)
 	groupAdminHandler := handlers.NewGroupAdminHandler(db)
 	settingsHandler := handlers.NewSettingsHandler(db)
-	oauthHandler := handlers.NewOAuthHandler(db, authMiddleware)
+	oauthHandler := handlers.NewOAuthHandler(db, authMiddleware, cfg)
+	samlHandler := handlers.NewSAMLHandler(db, authMiddleware, cfg)
+	ldapHandler := handlers.NewLDAPHandler(db, ldapService)
+	scimHandler := handlers.NewSCIMHandler(db, cfg)
+	roleHandler := handlers.NewRoleHandler(db, authMiddleware.Permissions())
+	auditHandler := handlers.NewAuditHandler(db, auditService)
+	apiTokenHandler := handlers.NewAPITokenHandler(db)
+	feedHandler := handlers.NewFeedHandler(db, cfg, authMiddleware.Permissions())
+	webhookHandler := handlers.NewWebhookHandler(db, webhookService)
+	rateLimitHandler := handlers.NewRateLimitHandler(db, rateLimitService)
+	loginSecurityHandler := handlers.NewLoginSecurityHandler(db, loginSecurityService)
+	impersonationHandler := handlers.NewImpersonationHandler(db, authMiddleware)
+	privacyHandler := handlers.NewPrivacyHandler(db, privacyService)
 	favoritesHandler := handlers.NewFavoritesHandler(db)
 	analyticsHandler := handlers.NewAnalyticsHandler(db, gamificationService)
 	announcementHandler := handlers.NewAnnouncementHandler(db)
-	newsHandler := handlers.NewNewsHandler(db, cfg, gamificationService)
+	newsHandler := handlers.NewNewsHandler(db, cfg, gamificationService, newsWorkflowService)
 	eventsHandler := handlers.NewEventsHandler(db, gamificationService)
 	homeHandler := handlers.NewHomeHandler(db)
+	recommendationHandler := handlers.NewRecommendationHandler(db)
+	acknowledgementHandler := handlers.NewAcknowledgementHandler(db, acknowledgementService)
 	versionHandler := handlers.NewVersionHandler()
 	emailHandler := handlers.NewEmailHandler(db, cfg)
 	commentHandler := handlers.NewCommentHandler(db, gamificationService)
@@ -182,7 +330,9 @@ func main() {
 	notificationHandler := handlers.NewNotificationHandler(db)
 	pollsHandler := handlers.NewPollsHandler(db, gamificationService)
 	gamificationHandler := handlers.NewGamificationHandler(db, gamificationService)
-	searchHandler := handlers.NewSearchHandler(db)
+	searchHandler := handlers.NewSearchHandler(db, searchService)
+	directoryHandler := handlers.NewDirectoryHandler(db, services.NewDirectoryService(db))
+	orgHandler := handlers.NewOrgHandler(db, services.NewOrgService(db))
 
 	// Seeding gamification
 	if err := gamificationService.SeedAchievements(); err != nil {
@@ -200,30 +350,18 @@ func main() {
 	// Créer un routeur personnalisé avec configuration sécurisée
 	router := gin.New()
 
-	// Configuration des proxies de confiance (sécurisée)
-	trustedProxies := []string{
-		"127.0.0.1", // Localhost
-		"::1",       // IPv6 localhost
-	}
-
-	// En production, ajouter les réseaux privés si nécessaire
-	if cfg.Server.Mode == "release" {
-		trustedProxies = append(trustedProxies,
-			"172.16.0.0/12",  // Docker networks
-			"192.168.0.0/16", // Private networks
-			"10.0.0.0/8",     // Private networks
-		)
+	// Définir les proxies de confiance pour éviter l'IP spoofing (TRUSTED_PROXIES)
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("TRUSTED_PROXIES invalide:", err)
 	}
 
-	// Définir les proxies de confiance pour éviter l'IP spoofing
-	router.SetTrustedProxies(trustedProxies)
-
-	// Middleware de logging sécurisé avec vraie IP
+	// Middleware de logging sécurisé avec vraie IP (les jetons passés en query string sont masqués :
+	// jetons de flux RSS/Atom et jetons WebSocket)
 	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		return fmt.Sprintf("[%s] \"%s %s %s\" %d %v %s %s %s\n",
 			param.TimeStamp.Format("2006/01/02 15:04:05"),
 			param.Method,
-			param.Path,
+			utils.RedactQueryParams(param.Path, "token"),
 			param.Request.Proto,
 			param.StatusCode,
 			param.Latency,
@@ -252,12 +390,36 @@ func main() {
 	// Serve uploaded files statically
 	router.Static("/uploads", cfg.Storage.UploadDir)
 
+	// Provisioning SCIM 2.0 (authentification par jeton bearer dédié)
+	scim := router.Group("/scim/v2")
+	scim.Use(middleware.RequireSCIMToken(db))
+	scim.Use(rateLimiter.Limit(models.RateLimitGroupSCIM))
+	{
+		scim.GET("/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
+		scim.GET("/ResourceTypes", scimHandler.ResourceTypes)
+
+		scim.GET("/Users", scimHandler.ListUsers)
+		scim.POST("/Users", scimHandler.CreateUser)
+		scim.GET("/Users/:id", scimHandler.GetUser)
+		scim.PUT("/Users/:id", scimHandler.ReplaceUser)
+		scim.PATCH("/Users/:id", scimHandler.PatchUser)
+		scim.DELETE("/Users/:id", scimHandler.DeleteUser)
+
+		scim.GET("/Groups", scimHandler.ListGroups)
+		scim.POST("/Groups", scimHandler.CreateGroup)
+		scim.GET("/Groups/:id", scimHandler.GetGroup)
+		scim.PUT("/Groups/:id", scimHandler.ReplaceGroup)
+		scim.PATCH("/Groups/:id", scimHandler.PatchGroup)
+		scim.DELETE("/Groups/:id", scimHandler.DeleteGroup)
+	}
+
 	// Routes publiques
 	api := router.Group("/api/v1")
 	{
 		// Gamification
 		gamification := api.Group("/gamification")
 		gamification.Use(authMiddleware.RequireAuth())
+		gamification.Use(rateLimiter.Limit(models.RateLimitGroupAPI))
 		{
 			gamification.GET("/profile", gamificationHandler.GetMyProfile)
 			gamification.GET("/achievements", gamificationHandler.GetMyAchievements)
@@ -267,10 +429,13 @@ func main() {
 		}
 
 		auth := api.Group("/auth")
+		auth.Use(rateLimiter.Limit(models.RateLimitGroupPublic))
 		{
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/refresh", authHandler.RefreshToken)
+			loginLimit := rateLimiter.Limit(models.RateLimitGroupLogin)
+			auth.POST("/login", loginLimit, authHandler.Login)
+			auth.POST("/register", loginLimit, authHandler.Register)
+			auth.POST("/refresh", loginLimit, authHandler.RefreshToken)
+			auth.GET("/password-policy", authHandler.GetPasswordPolicy)
 
 			// Route pour vérifier si l'inscription est activée
 			signup := auth.Group("/signup")
@@ -293,28 +458,84 @@ func main() {
 				oauth.GET("/:provider/callback", oauthHandler.OAuthCallback)
 				oauth.POST("/:provider/callback", oauthHandler.OAuthCallback)
 			}
+
+			// Routes SAML 2.0 publiques (service provider)
+			samlRoutes := auth.Group("/saml")
+			{
+				samlRoutes.GET("/providers", samlHandler.GetEnabledProviders)
+				samlRoutes.POST("/exchange", loginLimit, samlHandler.ExchangeCode)
+				samlRoutes.GET("/:provider/metadata", samlHandler.GetMetadata)
+				samlRoutes.GET("/:provider/login", samlHandler.InitiateLogin)
+				samlRoutes.POST("/:provider/acs", samlHandler.AssertionConsumerService)
+				samlRoutes.GET("/:provider/slo", samlHandler.SingleLogoutService)
+				samlRoutes.POST("/:provider/slo", samlHandler.SingleLogoutService)
+			}
+		}
+
+		// Flux RSS/Atom des news (authentifiés par le jeton de flux passé dans l'URL)
+		feeds := api.Group("/feeds")
+		feeds.Use(rateLimiter.Limit(models.RateLimitGroupPublic))
+		{
+			feeds.GET("/news.atom", feedHandler.NewsAtom)
+			feeds.GET("/news.rss", feedHandler.NewsRSS)
 		}
 
 		// Routes version (publiques)
 		version := api.Group("/version")
+		version.Use(rateLimiter.Limit(models.RateLimitGroupPublic))
 		{
 			version.GET("", versionHandler.GetVersion)
 			version.GET("/check-updates", versionHandler.CheckForUpdates)
 		}
 	}
 
-	// Routes protégées - Ordre correct: Auth d'abord, puis CSRF
+	// Émission des tokens CSRF (authentifiée, non soumise au contrôle CSRF)
+	api.POST("/auth/csrf-token", authMiddleware.RequireAuth(), rateLimiter.Limit(models.RateLimitGroupAPI), middleware.CSRFTokenHandler(csrfManager))
+
+	// Routes protégées - Ordre correct: Auth d'abord, puis CSRF (obligatoire pour toutes les mutations,
+	// y compris /admin, /editor et /group-admin)
 	protected := api.Group("/")
 	protected.Use(authMiddleware.RequireAuth())
-	protected.Use(middleware.OptionalCSRFProtection(csrfManager))
+	protected.Use(rateLimiter.Limit(models.RateLimitGroupAPI))
+	protected.Use(middleware.CSRFProtection(csrfManager))
 	{
-		// Route pour générer un token CSRF
-		protected.POST("/auth/csrf-token", middleware.CSRFTokenHandler(csrfManager))
-
 		// Profil utilisateur
 		protected.GET("/auth/profile", authHandler.GetProfile)
 		protected.PUT("/auth/profile", authHandler.UpdateProfile)
-		protected.POST("/auth/change-password", authHandler.ChangePassword)
+		protected.GET("/auth/profile/privacy", directoryHandler.GetMyPrivacy)
+		protected.PUT("/auth/profile/privacy", authMiddleware.RequireNotImpersonating(), directoryHandler.UpdateMyPrivacy)
+		protected.POST("/auth/change-password", authMiddleware.RequireInteractiveSession(), authMiddleware.RequireNotImpersonating(), authHandler.ChangePassword)
+		protected.POST("/auth/impersonation/stop", impersonationHandler.StopImpersonation)
+		protected.GET("/auth/permissions", roleHandler.GetMyPermissions)
+
+		// Données personnelles (RGPD) : export et demande d'effacement
+		privacy := protected.Group("/privacy")
+		{
+			privacy.GET("/policy", privacyHandler.GetPolicy)
+			privacy.GET("/requests", privacyHandler.ListMyRequests)
+			privacy.GET("/requests/:id/download", authMiddleware.RequireNotImpersonating(), privacyHandler.DownloadMyExport)
+			privacy.POST("/export", authMiddleware.RequireInteractiveSession(), authMiddleware.RequireNotImpersonating(), privacyHandler.RequestExport)
+			privacy.POST("/erasure", authMiddleware.RequireInteractiveSession(), authMiddleware.RequireNotImpersonating(), privacyHandler.RequestErasure)
+		}
+
+		// Jetons d'accès personnels (gérés uniquement depuis une session interactive)
+		tokens := protected.Group("/auth/tokens")
+		tokens.Use(authMiddleware.RequireInteractiveSession(), authMiddleware.RequireNotImpersonating())
+		{
+			tokens.GET("", apiTokenHandler.ListMyTokens)
+			tokens.POST("", apiTokenHandler.CreateMyToken)
+			tokens.DELETE("/:id", apiTokenHandler.RevokeMyToken)
+		}
+
+		// Jeton d'abonnement aux flux RSS/Atom
+		feedToken := protected.Group("/auth/feed-token")
+		feedToken.Use(authMiddleware.RequireNotImpersonating())
+		{
+			feedToken.GET("", feedHandler.GetMyFeedToken)
+			feedToken.POST("", feedHandler.RegenerateMyFeedToken)
+			feedToken.DELETE("", feedHandler.RevokeMyFeedToken)
+		}
+		protected.POST("/auth/saml/logout", samlHandler.Logout)
 		protected.POST("/auth/avatar", authHandler.UploadAvatar)
 		protected.DELETE("/auth/avatar", authHandler.DeleteAvatar)
 
@@ -341,9 +562,24 @@ func main() {
 
 		// Recherche globale
 		protected.GET("/search", searchHandler.GlobalSearch)
+		protected.GET("/search/suggest", searchHandler.Suggest)
+
+		// Annuaire des collaborateurs et organigramme
+		directory := protected.Group("/directory")
+		{
+			directory.GET("/people", directoryHandler.ListPeople)
+			directory.GET("/people/:id", directoryHandler.GetPerson)
+			directory.GET("/people/:id/chain", orgHandler.GetChainOfCommand)
+			directory.GET("/people/:id/reports", orgHandler.GetDirectReports)
+			directory.GET("/org-chart", orgHandler.GetChart)
+		}
 
 		// Routes announcements (accessible à tous les utilisateurs connectés)
 		protected.GET("/announcements", announcementHandler.GetActiveAnnouncements)
+		protected.POST("/announcements/:id/acknowledge", acknowledgementHandler.AcknowledgeAnnouncement)
+
+		// Lecture obligatoire : contenus en attente d'accusé de lecture de l'utilisateur
+		protected.GET("/acknowledgements/pending", acknowledgementHandler.GetPending)
 
 		// Routes News Hub (accessible à tous les utilisateurs connectés)
 		news := protected.Group("/news")
@@ -352,6 +588,7 @@ func main() {
 
 			// Routes spécifiques d'abord (avant les routes avec paramètres)
 			news.GET("/unread/count", newsHandler.GetUnreadCount) // Nombre de news non lues
+			news.GET("/for-you", recommendationHandler.GetForYou) // Fil personnalisé et motifs des recommandations
 			news.GET("/categories", newsHandler.GetCategories)    // Catégories (lecture seule)
 			news.GET("/tags", newsHandler.GetTags)                // Tags (lecture seule)
 
@@ -361,6 +598,9 @@ func main() {
 			news.POST("/:id/react", newsHandler.AddReaction)      // Ajouter une réaction
 			news.DELETE("/:id/react", newsHandler.RemoveReaction) // Retirer une réaction
 
+			// Lecture obligatoire : accusé de lecture (« J'ai lu et compris »)
+			news.POST("/:id/acknowledge", acknowledgementHandler.AcknowledgeNews)
+
 			// Route slug en dernier (greedy wildcard)
 			news.GET("/article/:slug", newsHandler.GetNewsBySlug) // Récupérer une news par slug
 		}
@@ -368,9 +608,9 @@ func main() {
 		// Routes Media (accessible à tous les utilisateurs connectés - editors et admins peuvent uploader)
 		media := protected.Group("/media")
 		{
-			media.GET("", mediaHandler.GetMediaList)       // Liste des médias avec pagination et filtres
-			media.GET("/:id", mediaHandler.GetMedia)       // Récupérer un média par ID
-			media.DELETE("/:id", mediaHandler.DeleteMedia) // Supprimer un média (uploader ou admin)
+			media.GET("", mediaHandler.GetMediaList)                                // Liste des médias avec pagination et filtres
+			media.GET("/:id", mediaHandler.GetMedia)                                // Récupérer un média par ID
+			media.DELETE("/:id", auditMiddleware.Trail(), mediaHandler.DeleteMedia) // Supprimer un média (uploader ou admin, audité si ce n'est pas l'uploader)
 		}
 
 		// Routes Events (accessible à tous les utilisateurs connectés)
@@ -385,11 +625,11 @@ func main() {
 		// Routes Commentaires (accessible à tous les utilisateurs connectés)
 		comments := protected.Group("/comments")
 		{
-			comments.GET("", commentHandler.GetComments)                 // Récupérer les commentaires d'une entité
-			comments.POST("", commentHandler.CreateComment)              // Créer un commentaire
-			comments.PUT("/:id", commentHandler.UpdateComment)           // Modifier un commentaire
-			comments.DELETE("/:id", commentHandler.DeleteComment)        // Supprimer un commentaire
-			comments.GET("/settings", commentHandler.GetCommentSettings) // Récupérer les paramètres
+			comments.GET("", commentHandler.GetComments)                                   // Récupérer les commentaires d'une entité
+			comments.POST("", commentHandler.CreateComment)                                // Créer un commentaire
+			comments.PUT("/:id", auditMiddleware.Trail(), commentHandler.UpdateComment)    // Modifier un commentaire (audité si modérateur)
+			comments.DELETE("/:id", auditMiddleware.Trail(), commentHandler.DeleteComment) // Supprimer un commentaire (audité si modérateur)
+			comments.GET("/settings", commentHandler.GetCommentSettings)                   // Récupérer les paramètres
 		}
 
 		// Routes Feedback (accessible à tous les utilisateurs connectés)
@@ -447,226 +687,371 @@ func main() {
 		// For this implementation, let's keep it here.
 		protected.GET("/ws", chatHandler.ServeWS)
 
+		// Contrôle d'accès par permission (voir models/permission.go) :
+		// perm exige la permission sans restriction, scopedPerm l'accepte sur un périmètre (vérifié par le handler)
+		perm := authMiddleware.RequirePermission
+		scopedPerm := authMiddleware.RequireScopedPermission
+
 		// Routes admin
 		admin := protected.Group("/admin")
-		admin.Use(authMiddleware.RequireAdmin())
+		admin.Use(auditMiddleware.Trail())
 		{
+			// Journal d'audit
+			admin.GET("/audit-logs", perm(models.PermAuditView), auditHandler.ListAuditLogs)
+			admin.GET("/audit-logs/export", perm(models.PermAuditView), auditHandler.ExportAuditLogs)
+			admin.GET("/audit-logs/:id", perm(models.PermAuditView), auditHandler.GetAuditLog)
+
 			// Gestion des groupes d'applications
-			admin.GET("/app-groups", adminHandler.GetAppGroups)
-			admin.POST("/app-groups", adminHandler.CreateAppGroup)
-			admin.PUT("/app-groups/:id", adminHandler.UpdateAppGroup)
-			admin.DELETE("/app-groups/:id", adminHandler.DeleteAppGroup)
+			admin.GET("/app-groups", perm(models.PermApplicationsManage), adminHandler.GetAppGroups)
+			admin.POST("/app-groups", perm(models.PermApplicationsManage), adminHandler.CreateAppGroup)
+			admin.PUT("/app-groups/:id", perm(models.PermApplicationsManage), adminHandler.UpdateAppGroup)
+			admin.DELETE("/app-groups/:id", perm(models.PermApplicationsManage), adminHandler.DeleteAppGroup)
 
 			// Gestion des applications
-			admin.GET("/applications", adminHandler.GetApplications)
-			admin.POST("/applications", adminHandler.CreateApplication)
-			admin.PUT("/applications/:id", adminHandler.UpdateApplication)
-			admin.DELETE("/applications/:id", adminHandler.DeleteApplication)
+			admin.GET("/applications", perm(models.PermApplicationsManage), adminHandler.GetApplications)
+			admin.POST("/applications", perm(models.PermApplicationsManage), adminHandler.CreateApplication)
+			admin.PUT("/applications/:id", perm(models.PermApplicationsManage), adminHandler.UpdateApplication)
+			admin.DELETE("/applications/:id", perm(models.PermApplicationsManage), adminHandler.DeleteApplication)
 
 			// Gestion des utilisateurs
-			admin.GET("/users", adminHandler.GetUsers)
-			admin.POST("/users", adminHandler.CreateUser)
-			admin.PUT("/users/:id", adminHandler.UpdateUser)
-			admin.DELETE("/users/:id", adminHandler.DeleteUser)
-			admin.GET("/users/deleted", adminHandler.GetDeletedUsers)
-			admin.POST("/users/:id/restore", adminHandler.RestoreUser)
-			admin.DELETE("/users/:id/permanent", adminHandler.PermanentlyDeleteUser)
+			admin.GET("/users", perm(models.PermUsersManage), adminHandler.GetUsers)
+			admin.POST("/users", perm(models.PermUsersManage), adminHandler.CreateUser)
+			admin.PUT("/users/:id", perm(models.PermUsersManage), adminHandler.UpdateUser)
+			admin.DELETE("/users/:id", perm(models.PermUsersManage), adminHandler.DeleteUser)
+			admin.GET("/users/deleted", perm(models.PermUsersManage), adminHandler.GetDeletedUsers)
+			admin.POST("/users/:id/restore", perm(models.PermUsersManage), adminHandler.RestoreUser)
+			admin.DELETE("/users/:id/permanent", perm(models.PermUsersManage), adminHandler.PermanentlyDeleteUser)
+			admin.DELETE("/users/:id/lockouts", perm(models.PermUsersManage), loginSecurityHandler.ClearUserLockouts)
+			admin.PUT("/users/:id/manager", perm(models.PermUsersManage), orgHandler.SetUserManager)
+			admin.GET("/org/managers/export", perm(models.PermUsersManage), orgHandler.ExportManagers)
+			admin.POST("/org/managers/import", perm(models.PermUsersManage), orgHandler.ImportManagers)
+
+			// Verrouillages de connexion (par adresse IP et par compte)
+			admin.GET("/security/lockouts", perm(models.PermUsersManage), loginSecurityHandler.ListLockouts)
+			admin.GET("/security/lockouts/stats", perm(models.PermUsersManage), loginSecurityHandler.GetLockoutStats)
+			admin.DELETE("/security/lockouts/:id", perm(models.PermUsersManage), loginSecurityHandler.ClearLockout)
+
+			// Usurpation d'identité (support)
+			admin.POST("/users/:id/impersonate", perm(models.PermUsersImpersonate), authMiddleware.RequireInteractiveSession(), authMiddleware.RequireNotImpersonating(), impersonationHandler.StartImpersonation)
+			admin.GET("/impersonations", perm(models.PermUsersImpersonate), impersonationHandler.ListSessions)
+			admin.DELETE("/impersonations/:id", perm(models.PermUsersImpersonate), impersonationHandler.EndSession)
+
+			// Données personnelles (RGPD)
+			admin.GET("/privacy/requests", perm(models.PermUsersManage), privacyHandler.ListRequests)
+			admin.GET("/privacy/requests/:id/download", perm(models.PermUsersManage), privacyHandler.DownloadExport)
+			admin.POST("/privacy/requests/:id/approve", perm(models.PermUsersManage), authMiddleware.RequireNotImpersonating(), privacyHandler.ApproveRequest)
+			admin.POST("/privacy/requests/:id/reject", perm(models.PermUsersManage), privacyHandler.RejectRequest)
+			admin.POST("/users/:id/privacy/export", perm(models.PermUsersManage), privacyHandler.ExportUser)
+			admin.POST("/users/:id/privacy/erasure", perm(models.PermUsersManage), authMiddleware.RequireNotImpersonating(), privacyHandler.EraseUser)
+
+			// Rôles et permissions
+			admin.GET("/permissions", perm(models.PermRolesManage), roleHandler.ListPermissions)
+			admin.GET("/roles", perm(models.PermRolesManage), roleHandler.ListRoles)
+			admin.POST("/roles", perm(models.PermRolesManage), roleHandler.CreateRole)
+			admin.PUT("/roles/:id", perm(models.PermRolesManage), roleHandler.UpdateRole)
+			admin.DELETE("/roles/:id", perm(models.PermRolesManage), roleHandler.DeleteRole)
+			admin.GET("/role-assignments", perm(models.PermRolesManage), roleHandler.ListAssignments)
+			admin.POST("/role-assignments", perm(models.PermRolesManage), roleHandler.CreateAssignment)
+			admin.DELETE("/role-assignments/:id", perm(models.PermRolesManage), roleHandler.DeleteAssignment)
+
+			// Jetons d'API et comptes de service
+			admin.GET("/api-tokens", perm(models.PermUsersManage), apiTokenHandler.ListAllTokens)
+			admin.DELETE("/api-tokens/:id", perm(models.PermUsersManage), apiTokenHandler.RevokeToken)
+			admin.GET("/service-accounts", perm(models.PermServiceAccounts), apiTokenHandler.ListServiceAccounts)
+			admin.POST("/service-accounts", perm(models.PermServiceAccounts), apiTokenHandler.CreateServiceAccount)
+			admin.PUT("/service-accounts/:id", perm(models.PermServiceAccounts), apiTokenHandler.UpdateServiceAccount)
+			admin.DELETE("/service-accounts/:id", perm(models.PermServiceAccounts), apiTokenHandler.DeleteServiceAccount)
+			admin.GET("/service-accounts/:id/tokens", perm(models.PermServiceAccounts), apiTokenHandler.ListServiceAccountTokens)
+			admin.POST("/service-accounts/:id/tokens", perm(models.PermServiceAccounts), authMiddleware.RequireInteractiveSession(), authMiddleware.RequireNotImpersonating(), apiTokenHandler.CreateServiceAccountToken)
+			admin.DELETE("/service-accounts/:id/tokens/:tokenId", perm(models.PermServiceAccounts), apiTokenHandler.RevokeServiceAccountToken)
+
+			// Limitation des requêtes
+			admin.GET("/rate-limits/policies", perm(models.PermSettingsManage), rateLimitHandler.ListPolicies)
+			admin.POST("/rate-limits/policies", perm(models.PermSettingsManage), rateLimitHandler.CreatePolicy)
+			admin.PUT("/rate-limits/policies/:id", perm(models.PermSettingsManage), rateLimitHandler.UpdatePolicy)
+			admin.DELETE("/rate-limits/policies/:id", perm(models.PermSettingsManage), rateLimitHandler.DeletePolicy)
+			admin.GET("/rate-limits/metrics", perm(models.PermSettingsManage), rateLimitHandler.GetMetrics)
+
+			// Webhooks sortants
+			admin.GET("/webhooks", perm(models.PermWebhooksManage), webhookHandler.ListWebhooks)
+			admin.GET("/webhooks/events", perm(models.PermWebhooksManage), webhookHandler.ListWebhookEvents)
+			admin.POST("/webhooks", perm(models.PermWebhooksManage), webhookHandler.CreateWebhook)
+			admin.PUT("/webhooks/:id", perm(models.PermWebhooksManage), webhookHandler.UpdateWebhook)
+			admin.DELETE("/webhooks/:id", perm(models.PermWebhooksManage), webhookHandler.DeleteWebhook)
+			admin.POST("/webhooks/:id/test", perm(models.PermWebhooksManage), webhookHandler.TestWebhook)
+			admin.POST("/webhooks/:id/rotate-secret", perm(models.PermWebhooksManage), webhookHandler.RotateWebhookSecret)
+			admin.GET("/webhook-deliveries", perm(models.PermWebhooksManage), webhookHandler.ListDeliveries)
+			admin.GET("/webhook-deliveries/:id", perm(models.PermWebhooksManage), webhookHandler.GetDelivery)
+			admin.POST("/webhook-deliveries/:id/replay", perm(models.PermWebhooksManage), webhookHandler.ReplayDelivery)
 
 			// Gestion des groupes d'utilisateurs
-			admin.GET("/groups", adminHandler.GetGroups)
-			admin.POST("/groups", adminHandler.CreateGroup)
-			admin.PUT("/groups/:id", adminHandler.UpdateGroup)
-			admin.DELETE("/groups/:id", adminHandler.DeleteGroup)
+			admin.GET("/groups", perm(models.PermGroupsManage), adminHandler.GetGroups)
+			admin.POST("/groups", perm(models.PermGroupsManage), adminHandler.CreateGroup)
+			admin.PUT("/groups/:id", perm(models.PermGroupsManage), adminHandler.UpdateGroup)
+			admin.DELETE("/groups/:id", perm(models.PermGroupsManage), adminHandler.DeleteGroup)
 
 			// Gestion des group admins (admin uniquement)
-			admin.GET("/groups/:id/admins", adminHandler.GetGroupAdmins)
-			admin.PUT("/groups/:id/admins", adminHandler.AssignGroupAdmins)
+			admin.GET("/groups/:id/admins", perm(models.PermGroupsManage), adminHandler.GetGroupAdmins)
+			admin.PUT("/groups/:id/admins", perm(models.PermGroupsManage), adminHandler.AssignGroupAdmins)
 
 			// Gestion des paramètres de l'application
-			admin.GET("/settings", settingsHandler.GetAppSettings)
-			admin.PUT("/settings", settingsHandler.UpdateAppSettings)
-			admin.POST("/settings/reset", settingsHandler.ResetAppSettings)
+			admin.GET("/settings", perm(models.PermSettingsManage), settingsHandler.GetAppSettings)
+			admin.PUT("/settings", perm(models.PermSettingsManage), settingsHandler.UpdateAppSettings)
+			admin.POST("/settings/reset", perm(models.PermSettingsManage), settingsHandler.ResetAppSettings)
 
 			// Gestion des messages Hero
-			admin.GET("/settings/hero-messages", settingsHandler.GetHeroMessages)
-			admin.POST("/settings/hero-messages", settingsHandler.CreateHeroMessage)
-			admin.PUT("/settings/hero-messages/:id", settingsHandler.UpdateHeroMessage)
-			admin.DELETE("/settings/hero-messages/:id", settingsHandler.DeleteHeroMessage)
+			admin.GET("/settings/hero-messages", perm(models.PermSettingsManage), settingsHandler.GetHeroMessages)
+			admin.POST("/settings/hero-messages", perm(models.PermSettingsManage), settingsHandler.CreateHeroMessage)
+			admin.PUT("/settings/hero-messages/:id", perm(models.PermSettingsManage), settingsHandler.UpdateHeroMessage)
+			admin.DELETE("/settings/hero-messages/:id", perm(models.PermSettingsManage), settingsHandler.DeleteHeroMessage)
 
 			// Gestion des fournisseurs OAuth
-			admin.GET("/oauth/providers", oauthHandler.GetAllProviders)
-			admin.PUT("/oauth/providers/:id", oauthHandler.UpdateProvider)
+			admin.GET("/oauth/providers", perm(models.PermIdentityManage), oauthHandler.GetAllProviders)
+			admin.POST("/oauth/providers", perm(models.PermIdentityManage), oauthHandler.CreateProvider)
+			admin.POST("/oauth/providers/discover", perm(models.PermIdentityManage), oauthHandler.DiscoverProvider)
+			admin.PUT("/oauth/providers/:id", perm(models.PermIdentityManage), oauthHandler.UpdateProvider)
+			admin.DELETE("/oauth/providers/:id", perm(models.PermIdentityManage), oauthHandler.DeleteProvider)
+
+			// Gestion des fournisseurs SAML
+			admin.GET("/saml/providers", perm(models.PermIdentityManage), samlHandler.GetAllProviders)
+			admin.POST("/saml/providers", perm(models.PermIdentityManage), samlHandler.CreateProvider)
+			admin.PUT("/saml/providers/:id", perm(models.PermIdentityManage), samlHandler.UpdateProvider)
+			admin.POST("/saml/providers/:id/refresh-metadata", perm(models.PermIdentityManage), samlHandler.RefreshMetadata)
+			admin.DELETE("/saml/providers/:id", perm(models.PermIdentityManage), samlHandler.DeleteProvider)
+
+			// Annuaire LDAP / Active Directory
+			admin.GET("/ldap/config", perm(models.PermIdentityManage), ldapHandler.GetConfig)
+			admin.PUT("/ldap/config", perm(models.PermIdentityManage), ldapHandler.UpdateConfig)
+			admin.POST("/ldap/test", perm(models.PermIdentityManage), ldapHandler.TestConnection)
+			admin.POST("/ldap/sync", perm(models.PermIdentityManage), ldapHandler.SyncNow)
+
+			// Jetons de provisioning SCIM
+			admin.GET("/scim/tokens", perm(models.PermIdentityManage), scimHandler.ListTokens)
+			admin.POST("/scim/tokens", perm(models.PermIdentityManage), authMiddleware.RequireNotImpersonating(), scimHandler.CreateToken)
+			admin.DELETE("/scim/tokens/:id", perm(models.PermIdentityManage), scimHandler.RevokeToken)
+
+			// Diagnostic de l'évaluation des headers SSO (proxy d'authentification)
+			admin.GET("/sso/diagnostics", perm(models.PermIdentityManage), ssoMiddleware.Diagnostics)
 
 			// Analytics (réservé aux admins)
-			admin.GET("/analytics/dashboard", analyticsHandler.GetDashboard)
-			admin.GET("/analytics/applications/:id", analyticsHandler.GetApplicationStats)
-			admin.GET("/analytics/users/:id", analyticsHandler.GetUserStats)
+			admin.GET("/analytics/dashboard", perm(models.PermAnalyticsView), analyticsHandler.GetDashboard)
+			admin.GET("/analytics/applications/:id", perm(models.PermAnalyticsView), analyticsHandler.GetApplicationStats)
+			admin.GET("/analytics/users/:id", perm(models.PermAnalyticsView), analyticsHandler.GetUserStats)
 
 			// Gestion des annonces (réservé aux admins)
-			admin.GET("/announcements", announcementHandler.GetAllAnnouncements)
-			admin.GET("/announcements/:id", announcementHandler.GetAnnouncement)
-			admin.POST("/announcements", announcementHandler.CreateAnnouncement)
-			admin.PUT("/announcements/:id", announcementHandler.UpdateAnnouncement)
-			admin.DELETE("/announcements/:id", announcementHandler.DeleteAnnouncement)
+			admin.GET("/announcements", perm(models.PermAnnouncementsManage), announcementHandler.GetAllAnnouncements)
+			admin.GET("/announcements/:id", perm(models.PermAnnouncementsManage), announcementHandler.GetAnnouncement)
+			admin.POST("/announcements", perm(models.PermAnnouncementsManage), announcementHandler.CreateAnnouncement)
+			admin.PUT("/announcements/:id", perm(models.PermAnnouncementsManage), announcementHandler.UpdateAnnouncement)
+			admin.DELETE("/announcements/:id", perm(models.PermAnnouncementsManage), announcementHandler.DeleteAnnouncement)
+
+			// Lecture obligatoire des annonces : rapport de conformité, export CSV et relance
+			admin.GET("/announcements/:id/acknowledgements", perm(models.PermAnnouncementsManage), acknowledgementHandler.GetAnnouncementReport)
+			admin.GET("/announcements/:id/acknowledgements/export", perm(models.PermAnnouncementsManage), acknowledgementHandler.ExportAnnouncementReport)
+			admin.POST("/announcements/:id/acknowledgements/remind", perm(models.PermAnnouncementsManage), acknowledgementHandler.RemindAnnouncement)
 
 			// Gestion de la base de données
-			admin.POST("/database/reset", adminHandler.ResetDatabase)
+			admin.POST("/database/reset", perm(models.PermSystemReset), adminHandler.ResetDatabase)
 
 			// Gestion des catégories de news (admin uniquement)
-			admin.POST("/news/categories", newsHandler.CreateCategory)
-			admin.PUT("/news/categories/:id", newsHandler.UpdateCategory)
-			admin.DELETE("/news/categories/:id", newsHandler.DeleteCategory)
+			admin.POST("/news/categories", perm(models.PermNewsCategoriesManage), newsHandler.CreateCategory)
+			admin.PUT("/news/categories/:id", perm(models.PermNewsCategoriesManage), newsHandler.UpdateCategory)
+			admin.DELETE("/news/categories/:id", perm(models.PermNewsCategoriesManage), newsHandler.DeleteCategory)
 
 			// Épingler des news (admin uniquement)
-			admin.POST("/news/:id/pin", newsHandler.TogglePin)
+			admin.POST("/news/:id/pin", perm(models.PermNewsManage), newsHandler.TogglePin)
 
 			// Analytics News (admin uniquement)
-			admin.GET("/news/analytics", newsHandler.GetAnalytics)
+			admin.GET("/news/analytics", perm(models.PermNewsManage), newsHandler.GetAnalytics)
+
+			// Lecture obligatoire des news : rapport de conformité, export CSV et relance
+			admin.GET("/news/:id/acknowledgements", perm(models.PermNewsManage), acknowledgementHandler.GetNewsReport)
+			admin.GET("/news/:id/acknowledgements/export", perm(models.PermNewsManage), acknowledgementHandler.ExportNewsReport)
+			admin.POST("/news/:id/acknowledgements/remind", perm(models.PermNewsManage), acknowledgementHandler.RemindNews)
 
 			// Gestion des événements (admin uniquement)
-			admin.GET("/events", eventsHandler.ListEvents)
-			admin.POST("/events", eventsHandler.CreateEvent)
-			admin.PUT("/events/:id", eventsHandler.UpdateEvent)
-			admin.DELETE("/events/:id", eventsHandler.DeleteEvent)
+			admin.GET("/events", perm(models.PermEventsManage), eventsHandler.ListEvents)
+			admin.POST("/events", perm(models.PermEventsManage), eventsHandler.CreateEvent)
+			admin.PUT("/events/:id", perm(models.PermEventsManage), eventsHandler.UpdateEvent)
+			admin.DELETE("/events/:id", perm(models.PermEventsManage), eventsHandler.DeleteEvent)
 
 			// Gestion des catégories d'événements (admin uniquement)
-			admin.POST("/events/categories", eventsHandler.CreateCategory)
-			admin.PUT("/events/categories/:id", eventsHandler.UpdateCategory)
-			admin.DELETE("/events/categories/:id", eventsHandler.DeleteCategory)
+			admin.POST("/events/categories", perm(models.PermEventsManage), eventsHandler.CreateCategory)
+			admin.PUT("/events/categories/:id", perm(models.PermEventsManage), eventsHandler.UpdateCategory)
+			admin.DELETE("/events/categories/:id", perm(models.PermEventsManage), eventsHandler.DeleteCategory)
 
 			// Analytics Events (admin uniquement)
-			admin.GET("/events/analytics", eventsHandler.GetAnalytics)
+			admin.GET("/events/analytics", perm(models.PermEventsManage), eventsHandler.GetAnalytics)
 
 			// Gestion des jours fériés (admin uniquement)
-			admin.GET("/events/holidays/countries", eventsHandler.GetAvailableCountries)
-			admin.GET("/events/holidays/preview", eventsHandler.PreviewHolidays)
-			admin.POST("/events/holidays/import", eventsHandler.ImportHolidays)
-			admin.DELETE("/events/holidays", eventsHandler.DeleteHolidays)
+			admin.GET("/events/holidays/countries", perm(models.PermEventsManage), eventsHandler.GetAvailableCountries)
+			admin.GET("/events/holidays/preview", perm(models.PermEventsManage), eventsHandler.PreviewHolidays)
+			admin.POST("/events/holidays/import", perm(models.PermEventsManage), eventsHandler.ImportHolidays)
+			admin.DELETE("/events/holidays", perm(models.PermEventsManage), eventsHandler.DeleteHolidays)
 
 			// Gestion des emails et notifications
-			admin.GET("/email/smtp", emailHandler.GetSMTPConfig)
-			admin.PUT("/email/smtp", emailHandler.UpdateSMTPConfig)
-			admin.POST("/email/smtp/test", emailHandler.TestSMTPConfig)
-			admin.GET("/email/templates", emailHandler.GetEmailTemplates)
-			admin.GET("/email/templates/variables", emailHandler.GetTemplateVariables)
-			admin.GET("/email/templates/:type", emailHandler.GetEmailTemplate)
-			admin.PUT("/email/templates/:type", emailHandler.UpdateEmailTemplate)
-			admin.POST("/email/templates/:type/reset", emailHandler.ResetEmailTemplate)
-			admin.GET("/email/templates/:type/preview", emailHandler.PreviewTemplate)
-			admin.GET("/email/logs", emailHandler.GetEmailLogs)
+			admin.GET("/email/smtp", perm(models.PermEmailManage), emailHandler.GetSMTPConfig)
+			admin.PUT("/email/smtp", perm(models.PermEmailManage), emailHandler.UpdateSMTPConfig)
+			admin.POST("/email/smtp/test", perm(models.PermEmailManage), emailHandler.TestSMTPConfig)
+			admin.GET("/email/templates", perm(models.PermEmailManage), emailHandler.GetEmailTemplates)
+			admin.GET("/email/templates/variables", perm(models.PermEmailManage), emailHandler.GetTemplateVariables)
+			admin.GET("/email/templates/:type", perm(models.PermEmailManage), emailHandler.GetEmailTemplate)
+			admin.PUT("/email/templates/:type", perm(models.PermEmailManage), emailHandler.UpdateEmailTemplate)
+			admin.POST("/email/templates/:type/reset", perm(models.PermEmailManage), emailHandler.ResetEmailTemplate)
+			admin.GET("/email/templates/:type/preview", perm(models.PermEmailManage), emailHandler.PreviewTemplate)
+			admin.GET("/email/logs", perm(models.PermEmailManage), emailHandler.GetEmailLogs)
 
 			// OAuth 2.0 configuration for email (admin only)
-			admin.GET("/email/oauth", emailHandler.GetOAuthConfig)
-			admin.PUT("/email/oauth", emailHandler.UpdateOAuthConfig)
-			admin.POST("/email/oauth/test", emailHandler.TestOAuthConnection)
-			admin.POST("/email/oauth/refresh", emailHandler.RefreshOAuthToken)
-			admin.GET("/email/health", emailHandler.GetEmailHealthStatus)
+			admin.GET("/email/oauth", perm(models.PermEmailManage), emailHandler.GetOAuthConfig)
+			admin.PUT("/email/oauth", perm(models.PermEmailManage), emailHandler.UpdateOAuthConfig)
+			admin.POST("/email/oauth/test", perm(models.PermEmailManage), emailHandler.TestOAuthConnection)
+			admin.POST("/email/oauth/refresh", perm(models.PermEmailManage), emailHandler.RefreshOAuthToken)
+			admin.GET("/email/health", perm(models.PermEmailManage), emailHandler.GetEmailHealthStatus)
 
 			// Gestion des commentaires (modération - admin uniquement)
-			admin.GET("/comments/pending", commentHandler.GetPendingComments)     // Commentaires en attente
-			admin.POST("/comments/moderate", commentHandler.ModerateComment)      // Modérer un commentaire
-			admin.PUT("/comments/settings", commentHandler.UpdateCommentSettings) // Mettre à jour les paramètres
+			admin.GET("/comments/pending", perm(models.PermCommentsModerate), commentHandler.GetPendingComments)   // Commentaires en attente
+			admin.POST("/comments/moderate", perm(models.PermCommentsModerate), commentHandler.ModerateComment)    // Modérer un commentaire
+			admin.PUT("/comments/settings", perm(models.PermSettingsManage), commentHandler.UpdateCommentSettings) // Mettre à jour les paramètres
 
 			// Gestion des feedbacks (admin uniquement)
-			admin.GET("/feedback/all", feedbackHandler.GetAllFeedback) // Tous les feedbacks d'une entité
+			admin.GET("/feedback/all", perm(models.PermFeedbackView), feedbackHandler.GetAllFeedback) // Tous les feedbacks d'une entité
 
 			// Gestion des sondages (admin uniquement)
-			admin.POST("/polls", pollsHandler.CreatePoll)
-			admin.PUT("/polls/:id", pollsHandler.UpdatePoll)
-			admin.DELETE("/polls/:id", pollsHandler.DeletePoll)
-			admin.POST("/polls/:id/close", pollsHandler.ClosePoll)
-			admin.GET("/polls/analytics", pollsHandler.GetAnalytics)
+			admin.POST("/polls", perm(models.PermPollsManage), pollsHandler.CreatePoll)
+			admin.PUT("/polls/:id", perm(models.PermPollsManage), pollsHandler.UpdatePoll)
+			admin.DELETE("/polls/:id", perm(models.PermPollsManage), pollsHandler.DeletePoll)
+			admin.POST("/polls/:id/close", perm(models.PermPollsManage), pollsHandler.ClosePoll)
+			admin.GET("/polls/analytics", perm(models.PermPollsManage), pollsHandler.GetAnalytics)
 
 			// Gestion des médias (admin uniquement)
-			admin.GET("/media", mediaHandler.GetMediaList)        // Liste des médias avec pagination et filtres
-			admin.GET("/media/:id", mediaHandler.GetMedia)        // Récupérer un média par ID
-			admin.POST("/media/upload", mediaHandler.UploadMedia) // Uploader un média
-			admin.PUT("/media/:id", mediaHandler.UpdateMedia)     // Mettre à jour les métadonnées d'un média
-			admin.DELETE("/media/:id", mediaHandler.DeleteMedia)  // Supprimer un média
+			admin.GET("/media", perm(models.PermMediaUpload), mediaHandler.GetMediaList)        // Liste des médias avec pagination et filtres
+			admin.GET("/media/:id", perm(models.PermMediaUpload), mediaHandler.GetMedia)        // Récupérer un média par ID
+			admin.POST("/media/upload", perm(models.PermMediaUpload), mediaHandler.UploadMedia) // Uploader un média
+			admin.PUT("/media/:id", perm(models.PermMediaUpload), mediaHandler.UpdateMedia)     // Mettre à jour les métadonnées d'un média
+			admin.DELETE("/media/:id", perm(models.PermMediaDelete), mediaHandler.DeleteMedia)  // Supprimer un média
 		}
 
 		// Routes editor (admin et editor peuvent créer/modifier des news et événements)
 		editor := protected.Group("/editor")
-		editor.Use(authMiddleware.RequireEditor())
+		editor.Use(auditMiddleware.Trail())
 		{
 			// Gestion des news
-			editor.POST("/news", newsHandler.CreateNews)
-			editor.PUT("/news/:id", newsHandler.UpdateNews)
-			editor.DELETE("/news/:id", newsHandler.DeleteNews)
+			editor.POST("/news", scopedPerm(models.PermNewsCreate), newsHandler.CreateNews)
+			editor.PUT("/news/:id", scopedPerm(models.PermNewsCreate), newsHandler.UpdateNews)
+			editor.DELETE("/news/:id", scopedPerm(models.PermNewsCreate), newsHandler.DeleteNews)
+
+			// Historique des versions des news (diff et restauration)
+			editor.GET("/news/:id/revisions", scopedPerm(models.PermNewsCreate), newsHandler.ListRevisions)
+			editor.GET("/news/:id/revisions/diff", scopedPerm(models.PermNewsCreate), newsHandler.DiffRevisions)
+			editor.GET("/news/:id/revisions/:revisionId", scopedPerm(models.PermNewsCreate), newsHandler.GetRevision)
+			editor.POST("/news/:id/revisions/:revisionId/restore", scopedPerm(models.PermNewsCreate), newsHandler.RestoreRevision)
+
+			// Workflow éditorial (brouillon → relecture → approuvé → planifié → publié → archivé)
+			editor.GET("/news/reviewers", scopedPerm(models.PermNewsCreate), newsHandler.ListReviewers)
+			editor.GET("/news/review-queue", perm(models.PermNewsPublish), newsHandler.ReviewQueue)
+			editor.GET("/news/:id/workflow", scopedPerm(models.PermNewsCreate), newsHandler.GetWorkflow)
+			editor.POST("/news/:id/submit", scopedPerm(models.PermNewsCreate), newsHandler.SubmitNews)
+			editor.PUT("/news/:id/reviewers", scopedPerm(models.PermNewsCreate), newsHandler.AssignReviewers)
+			editor.POST("/news/:id/approve", perm(models.PermNewsPublish), newsHandler.ApproveNews)
+			editor.POST("/news/:id/request-changes", perm(models.PermNewsPublish), newsHandler.RequestChanges)
+			editor.POST("/news/:id/publish", scopedPerm(models.PermNewsPublish), newsHandler.PublishNews)
+			editor.POST("/news/:id/unschedule", scopedPerm(models.PermNewsPublish), newsHandler.UnscheduleNews)
+			editor.POST("/news/:id/archive", scopedPerm(models.PermNewsCreate), newsHandler.ArchiveNews)
+			editor.POST("/news/:id/unarchive", scopedPerm(models.PermNewsCreate), newsHandler.UnarchiveNews)
 
 			// Gestion des tags (editors peuvent créer des tags)
-			editor.POST("/news/tags", newsHandler.CreateTag)
-			editor.PUT("/news/tags/:id", newsHandler.UpdateTag)
-			editor.DELETE("/news/tags/:id", newsHandler.DeleteTag)
+			editor.POST("/news/tags", scopedPerm(models.PermNewsTagsManage), newsHandler.CreateTag)
+			editor.PUT("/news/tags/:id", scopedPerm(models.PermNewsTagsManage), newsHandler.UpdateTag)
+			editor.DELETE("/news/tags/:id", scopedPerm(models.PermNewsTagsManage), newsHandler.DeleteTag)
 
 			// Upload de médias (editors, group_admins et admins peuvent uploader)
-			editor.POST("/media/upload", mediaHandler.UploadMedia)
+			editor.POST("/media/upload", scopedPerm(models.PermMediaUpload), mediaHandler.UploadMedia)
 
 			// Gestion des événements
-			editor.POST("/events", eventsHandler.CreateEvent)
-			editor.PUT("/events/:id", eventsHandler.UpdateEvent)
-			editor.DELETE("/events/:id", eventsHandler.DeleteEvent)
+			editor.POST("/events", scopedPerm(models.PermEventsCreate), eventsHandler.CreateEvent)
+			editor.PUT("/events/:id", scopedPerm(models.PermEventsCreate), eventsHandler.UpdateEvent)
+			editor.DELETE("/events/:id", scopedPerm(models.PermEventsCreate), eventsHandler.DeleteEvent)
+			editor.GET("/events/:id/revisions", scopedPerm(models.PermEventsCreate), eventsHandler.ListRevisions)
+			editor.GET("/events/:id/revisions/diff", scopedPerm(models.PermEventsCreate), eventsHandler.DiffRevisions)
+			editor.GET("/events/:id/revisions/:revisionId", scopedPerm(models.PermEventsCreate), eventsHandler.GetRevision)
+			editor.POST("/events/:id/revisions/:revisionId/restore", scopedPerm(models.PermEventsCreate), eventsHandler.RestoreRevision)
 
 			// Modération des commentaires (editors peuvent aussi modérer)
-			editor.GET("/comments/pending", commentHandler.GetPendingComments)
-			editor.POST("/comments/moderate", commentHandler.ModerateComment)
+			editor.GET("/comments/pending", perm(models.PermCommentsModerate), commentHandler.GetPendingComments)
+			editor.POST("/comments/moderate", perm(models.PermCommentsModerate), commentHandler.ModerateComment)
 
 			// Gestion des sondages (editors peuvent créer/modifier/supprimer des sondages)
-			editor.POST("/polls", pollsHandler.CreatePoll)
-			editor.PUT("/polls/:id", pollsHandler.UpdatePoll)
-			editor.DELETE("/polls/:id", pollsHandler.DeletePoll)
+			editor.POST("/polls", scopedPerm(models.PermPollsCreate), pollsHandler.CreatePoll)
+			editor.PUT("/polls/:id", scopedPerm(models.PermPollsCreate), pollsHandler.UpdatePoll)
+			editor.DELETE("/polls/:id", scopedPerm(models.PermPollsCreate), pollsHandler.DeletePoll)
 		}
 
 		// Routes group-admin (gestion limitée au périmètre)
 		groupAdmin := protected.Group("/group-admin")
-		groupAdmin.Use(authMiddleware.RequireGroupAdmin())
+		groupAdmin.Use(auditMiddleware.Trail())
 		{
 			// AppGroups (scoped)
-			groupAdmin.GET("/app-groups", groupAdminHandler.GetAppGroups)
-			groupAdmin.POST("/app-groups", adminHandler.CreateAppGroup)
-			groupAdmin.PUT("/app-groups/:id", adminHandler.UpdateAppGroup)
-			groupAdmin.DELETE("/app-groups/:id", adminHandler.DeleteAppGroup)
+			groupAdmin.GET("/app-groups", scopedPerm(models.PermApplicationsManage), groupAdminHandler.GetAppGroups)
+			groupAdmin.POST("/app-groups", scopedPerm(models.PermApplicationsManage), adminHandler.CreateAppGroup)
+			groupAdmin.PUT("/app-groups/:id", scopedPerm(models.PermApplicationsManage), adminHandler.UpdateAppGroup)
+			groupAdmin.DELETE("/app-groups/:id", scopedPerm(models.PermApplicationsManage), adminHandler.DeleteAppGroup)
 
 			// Applications (scoped)
-			groupAdmin.GET("/applications", groupAdminHandler.GetApplications)
-			groupAdmin.POST("/applications", groupAdminHandler.CreateApplication)
-			groupAdmin.PUT("/applications/:id", groupAdminHandler.UpdateApplication)
-			groupAdmin.DELETE("/applications/:id", groupAdminHandler.DeleteApplication)
+			groupAdmin.GET("/applications", scopedPerm(models.PermApplicationsManage), groupAdminHandler.GetApplications)
+			groupAdmin.POST("/applications", scopedPerm(models.PermApplicationsManage), groupAdminHandler.CreateApplication)
+			groupAdmin.PUT("/applications/:id", scopedPerm(models.PermApplicationsManage), groupAdminHandler.UpdateApplication)
+			groupAdmin.DELETE("/applications/:id", scopedPerm(models.PermApplicationsManage), groupAdminHandler.DeleteApplication)
 
 			// News (scoped)
-			groupAdmin.GET("/news", newsHandler.GetNews) // Liste des news avec filtrage automatique par rôle
-			groupAdmin.POST("/news", newsHandler.CreateNews)
-			groupAdmin.PUT("/news/:id", newsHandler.UpdateNews)
-			groupAdmin.DELETE("/news/:id", newsHandler.DeleteNews)
+			groupAdmin.GET("/news", scopedPerm(models.PermNewsCreate), newsHandler.GetNews) // Liste des news avec filtrage automatique par rôle
+			groupAdmin.POST("/news", scopedPerm(models.PermNewsCreate), newsHandler.CreateNews)
+			groupAdmin.PUT("/news/:id", scopedPerm(models.PermNewsCreate), newsHandler.UpdateNews)
+			groupAdmin.DELETE("/news/:id", scopedPerm(models.PermNewsCreate), newsHandler.DeleteNews)
+			groupAdmin.GET("/news/:id/revisions", scopedPerm(models.PermNewsCreate), newsHandler.ListRevisions)
+			groupAdmin.GET("/news/:id/revisions/diff", scopedPerm(models.PermNewsCreate), newsHandler.DiffRevisions)
+			groupAdmin.GET("/news/:id/revisions/:revisionId", scopedPerm(models.PermNewsCreate), newsHandler.GetRevision)
+			groupAdmin.POST("/news/:id/revisions/:revisionId/restore", scopedPerm(models.PermNewsCreate), newsHandler.RestoreRevision)
+			groupAdmin.GET("/news/reviewers", scopedPerm(models.PermNewsCreate), newsHandler.ListReviewers)
+			groupAdmin.GET("/news/:id/workflow", scopedPerm(models.PermNewsCreate), newsHandler.GetWorkflow)
+			groupAdmin.POST("/news/:id/submit", scopedPerm(models.PermNewsCreate), newsHandler.SubmitNews)
+			groupAdmin.PUT("/news/:id/reviewers", scopedPerm(models.PermNewsCreate), newsHandler.AssignReviewers)
+			groupAdmin.POST("/news/:id/publish", scopedPerm(models.PermNewsPublish), newsHandler.PublishNews)
+			groupAdmin.POST("/news/:id/unschedule", scopedPerm(models.PermNewsPublish), newsHandler.UnscheduleNews)
+			groupAdmin.POST("/news/:id/archive", scopedPerm(models.PermNewsCreate), newsHandler.ArchiveNews)
+			groupAdmin.POST("/news/:id/unarchive", scopedPerm(models.PermNewsCreate), newsHandler.UnarchiveNews)
 
 			// Upload de médias
-			groupAdmin.POST("/media/upload", mediaHandler.UploadMedia)
+			groupAdmin.POST("/media/upload", scopedPerm(models.PermMediaUpload), mediaHandler.UploadMedia)
 
 			// Tags (group admin peut créer/modifier des tags)
-			groupAdmin.POST("/news/tags", newsHandler.CreateTag)
-			groupAdmin.PUT("/news/tags/:id", newsHandler.UpdateTag)
-			groupAdmin.DELETE("/news/tags/:id", newsHandler.DeleteTag)
+			groupAdmin.POST("/news/tags", scopedPerm(models.PermNewsTagsManage), newsHandler.CreateTag)
+			groupAdmin.PUT("/news/tags/:id", scopedPerm(models.PermNewsTagsManage), newsHandler.UpdateTag)
+			groupAdmin.DELETE("/news/tags/:id", scopedPerm(models.PermNewsTagsManage), newsHandler.DeleteTag)
 
 			// Categories (group admin peut créer/modifier des catégories)
-			groupAdmin.POST("/news/categories", newsHandler.CreateCategory)
-			groupAdmin.PUT("/news/categories/:id", newsHandler.UpdateCategory)
-			groupAdmin.DELETE("/news/categories/:id", newsHandler.DeleteCategory)
+			groupAdmin.POST("/news/categories", scopedPerm(models.PermNewsCategoriesManage), newsHandler.CreateCategory)
+			groupAdmin.PUT("/news/categories/:id", scopedPerm(models.PermNewsCategoriesManage), newsHandler.UpdateCategory)
+			groupAdmin.DELETE("/news/categories/:id", scopedPerm(models.PermNewsCategoriesManage), newsHandler.DeleteCategory)
 
 			// Events (scoped)
-			groupAdmin.GET("/events", eventsHandler.GetEvents) // Liste des événements avec filtrage automatique par rôle
-			groupAdmin.POST("/events", eventsHandler.CreateEventGroupAdmin)
-			groupAdmin.PUT("/events/:id", eventsHandler.UpdateEventGroupAdmin)
-			groupAdmin.DELETE("/events/:id", eventsHandler.DeleteEventGroupAdmin)
+			groupAdmin.GET("/events", scopedPerm(models.PermEventsCreate), eventsHandler.GetEvents) // Liste des événements avec filtrage automatique par rôle
+			groupAdmin.POST("/events", scopedPerm(models.PermEventsCreate), eventsHandler.CreateEventGroupAdmin)
+			groupAdmin.PUT("/events/:id", scopedPerm(models.PermEventsCreate), eventsHandler.UpdateEventGroupAdmin)
+			groupAdmin.DELETE("/events/:id", scopedPerm(models.PermEventsCreate), eventsHandler.DeleteEventGroupAdmin)
+			groupAdmin.GET("/events/:id/revisions", scopedPerm(models.PermEventsCreate), eventsHandler.ListRevisions)
+			groupAdmin.GET("/events/:id/revisions/diff", scopedPerm(models.PermEventsCreate), eventsHandler.DiffRevisions)
+			groupAdmin.GET("/events/:id/revisions/:revisionId", scopedPerm(models.PermEventsCreate), eventsHandler.GetRevision)
+			groupAdmin.POST("/events/:id/revisions/:revisionId/restore", scopedPerm(models.PermEventsCreate), eventsHandler.RestoreRevision)
 
 			// Polls (scoped - group admin peut gérer les sondages de ses groupes)
-			groupAdmin.GET("/polls", pollsHandler.GetPolls) // Liste des sondages avec filtrage automatique par rôle
-			groupAdmin.POST("/polls", pollsHandler.CreatePoll)
-			groupAdmin.PUT("/polls/:id", pollsHandler.UpdatePoll)
-			groupAdmin.DELETE("/polls/:id", pollsHandler.DeletePoll)
-			groupAdmin.POST("/polls/:id/close", pollsHandler.ClosePoll)
+			groupAdmin.GET("/polls", scopedPerm(models.PermPollsCreate), pollsHandler.GetPolls) // Liste des sondages avec filtrage automatique par rôle
+			groupAdmin.POST("/polls", scopedPerm(models.PermPollsCreate), pollsHandler.CreatePoll)
+			groupAdmin.PUT("/polls/:id", scopedPerm(models.PermPollsCreate), pollsHandler.UpdatePoll)
+			groupAdmin.DELETE("/polls/:id", scopedPerm(models.PermPollsCreate), pollsHandler.DeletePoll)
+			groupAdmin.POST("/polls/:id/close", scopedPerm(models.PermPollsManage), pollsHandler.ClosePoll)
 
 			// Info sur les groupes administrés
-			groupAdmin.GET("/managed-groups", groupAdminHandler.GetManagedGroups)
+			groupAdmin.GET("/managed-groups", scopedPerm(models.PermApplicationsManage, models.PermNewsCreate, models.PermEventsCreate, models.PermPollsCreate), groupAdminHandler.GetManagedGroups)
 		}
 	}
 
@@ -685,7 +1070,30 @@ func main() {
 	log.Printf("📊 Dashboard: http://localhost:%s/health", cfg.Server.Port)
 	log.Printf("📚 Mode: %s", cfg.Server.Mode)
 
-	// Démarrer le serveur
+	// Démarrer le serveur (HTTPS direct si un certificat est configuré, requis pour le mTLS)
+	if cfg.Server.TLSCertFile != "" && cfg.Server.TLSKeyFile != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.SSO.ClientCAFile != "" {
+			caPEM, err := os.ReadFile(cfg.SSO.ClientCAFile)
+			if err != nil {
+				log.Fatal("Impossible de lire SSO_CLIENT_CA_FILE:", err)
+			}
+			clientCAs := x509.NewCertPool()
+			if !clientCAs.AppendCertsFromPEM(caPEM) {
+				log.Fatal("SSO_CLIENT_CA_FILE ne contient aucun certificat valide")
+			}
+			// Certificat client facultatif : seules les requêtes portant des headers SSO l'exigent
+			tlsConfig.ClientCAs = clientCAs
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+
+		server := &http.Server{
+			Addr:      ":" + cfg.Server.Port,
+			Handler:   router,
+			TLSConfig: tlsConfig,
+		}
+		log.Fatal(server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile))
+	}
 	router.Run(":" + cfg.Server.Port)
 }
 
@@ -1301,25 +1709,31 @@ func createDefaultOAuthProviders(db *gorm.DB, cfg *config.Config, adminUser mode
 }
 
 func createDefaultEmailTemplates(db *gorm.DB) error {
-	// Vérifier si des templates existent déjà
-	var count int64
-	if err := db.Model(&models.EmailTemplate{}).Count(&count).Error; err != nil {
-		return fmt.Errorf("failed to count email templates: %w", err)
+	// Types de templates déjà créés (les templates personnalisés ne sont pas modifiés)
+	var existing []string
+	if err := db.Model(&models.EmailTemplate{}).Pluck("type", &existing).Error; err != nil {
+		return fmt.Errorf("failed to list email templates: %w", err)
 	}
-
-	if count > 0 {
-		return nil // Templates déjà créés
+	exists := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		exists[t] = true
 	}
 
-	// Créer les templates par défaut
-	templates := models.GetDefaultEmailTemplates()
-	for _, t := range templates {
+	// Créer les templates par défaut manquants
+	created := 0
+	for _, t := range models.GetDefaultEmailTemplates() {
+		if exists[t.Type] {
+			continue
+		}
 		if err := db.Create(&t).Error; err != nil {
 			return fmt.Errorf("failed to create email template %s: %w", t.Type, err)
 		}
+		created++
 	}
 
-	log.Println("✅ Templates d'email par défaut créés (4 templates)")
+	if created > 0 {
+		log.Printf("✅ Templates d'email par défaut créés (%d templates)", created)
+	}
 	return nil
 }
 
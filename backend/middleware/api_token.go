@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"airboard/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Méthodes d'authentification exposées dans le contexte ("auth_method")
+const (
+	AuthMethodJWT      = "jwt"
+	AuthMethodAPIToken = "api_token"
+)
+
+// authenticateAPIToken authentifie la requête avec un jeton d'API.
+// Les permissions sont celles du propriétaire, restreintes aux scopes du jeton.
+func (am *AuthMiddleware) authenticateAPIToken(c *gin.Context, raw string) {
+	token, user, err := am.apiTokens.Authenticate(raw, c.ClientIP())
+	if err != nil {
+		log.Printf("[API TOKEN] Authentification refusée depuis %s: %v", c.ClientIP(), err)
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Jeton d'API invalide, révoqué ou expiré",
+			Code:    http.StatusUnauthorized,
+		})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("username", user.Username)
+	c.Set("role", user.Role)
+	c.Set("email", user.Email)
+	c.Set("auth_method", AuthMethodAPIToken)
+	c.Set("api_token_id", token.ID)
+
+	var managedGroupIDs []uint
+	am.db.Table("group_admins").
+		Where("user_id = ?", user.ID).
+		Pluck("group_id", &managedGroupIDs)
+	c.Set("managed_group_ids", managedGroupIDs)
+
+	permissions := am.permissions.Resolve(user.ID, user.Role, managedGroupIDs)
+	c.Set("permissions", permissions.Restrict(token.ScopeList()))
+
+	c.Next()
+}
+
+// IsAPITokenRequest indique si la requête est authentifiée par un jeton d'API
+func IsAPITokenRequest(c *gin.Context) bool {
+	return c.GetString("auth_method") == AuthMethodAPIToken
+}
+
+// RequireInteractiveSession refuse les requêtes authentifiées par jeton d'API
+// (création de jetons, changement de mot de passe)
+func (am *AuthMiddleware) RequireInteractiveSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsAPITokenRequest(c) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Action impossible avec un jeton d'API",
+				Code:    http.StatusForbidden,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"airboard/models"
+	"airboard/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Taille maximale du corps de requête conservé comme état "après"
+const auditMaxBodySize = 64 << 10
+
+// Clés de contexte utilisées par les handlers pour enrichir l'entrée d'audit
+const (
+	auditActionKey      = "audit_action"
+	auditTargetTypeKey  = "audit_target_type"
+	auditTargetIDKey    = "audit_target_id"
+	auditTargetLabelKey = "audit_target_label"
+	auditBeforeKey      = "audit_before"
+	auditAfterKey       = "audit_after"
+	auditDetailsKey     = "audit_details"
+	auditSkipKey        = "audit_skip"
+)
+
+// AuditMiddleware journalise les mutations effectuées via l'API
+type AuditMiddleware struct {
+	service *services.AuditService
+}
+
+// NewAuditMiddleware crée le middleware d'audit
+func NewAuditMiddleware(service *services.AuditService) *AuditMiddleware {
+	return &AuditMiddleware{service: service}
+}
+
+// Trail enregistre chaque requête modifiante (POST, PUT, PATCH, DELETE) une fois traitée.
+// L'action est déduite de la route (ex: PUT /admin/users/:id -> admin.users.update) sauf si le handler la précise.
+func (am *AuditMiddleware) Trail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		body := readAuditBody(c)
+		c.Next()
+
+		if c.GetBool(auditSkipKey) {
+			return
+		}
+
+		entry := NewAuditEntry(c, routeAuditAction(c))
+		entry.StatusCode = c.Writer.Status()
+		entry.Success = entry.StatusCode < http.StatusBadRequest
+		if entry.After == "" && body != nil {
+			entry.After = services.AuditSnapshot(body)
+		}
+		if entry.TargetType == "" {
+			entry.TargetType = routeAuditTargetType(c)
+		}
+		if entry.TargetID == "" {
+			entry.TargetID = c.Param("id")
+		}
+		if len(c.Errors) > 0 && entry.Details == "" {
+			entry.Details = c.Errors.String()
+		}
+
+		am.service.Record(entry)
+	}
+}
+
+// NewAuditEntry prépare une entrée d'audit avec l'acteur, l'origine de la requête et les informations
+// fournies par le handler (cible, snapshots)
+func NewAuditEntry(c *gin.Context, action string) *models.AuditLog {
+	if custom := c.GetString(auditActionKey); custom != "" {
+		action = custom
+	}
+
+	entry := &models.AuditLog{
+		Action:        action,
+		ActorUsername: c.GetString("username"),
+		ActorRole:     c.GetString("role"),
+		TargetType:    c.GetString(auditTargetTypeKey),
+		TargetID:      c.GetString(auditTargetIDKey),
+		TargetLabel:   c.GetString(auditTargetLabelKey),
+		Before:        c.GetString(auditBeforeKey),
+		After:         c.GetString(auditAfterKey),
+		Details:       c.GetString(auditDetailsKey),
+		Method:        c.Request.Method,
+		Path:          c.Request.URL.Path,
+		IPAddress:     c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(uint); ok {
+			entry.ActorID = &id
+		}
+	}
+	if impersonatorID := c.GetUint(ImpersonatorIDKey); impersonatorID != 0 {
+		entry.ImpersonatorID = &impersonatorID
+	}
+	if tokenID, ok := c.Get("api_token_id"); ok && entry.Details == "" {
+		entry.Details = fmt.Sprintf("Jeton d'API #%v", tokenID)
+	}
+	return entry
+}
+
+// SetAuditAction remplace l'action déduite de la route
+func SetAuditAction(c *gin.Context, action string) {
+	c.Set(auditActionKey, action)
+}
+
+// SetAuditTarget précise l'entité concernée par la requête
+func SetAuditTarget(c *gin.Context, targetType string, targetID interface{}, label string) {
+	c.Set(auditTargetTypeKey, targetType)
+	c.Set(auditTargetIDKey, fmt.Sprint(targetID))
+	c.Set(auditTargetLabelKey, label)
+}
+
+// AuditBefore enregistre l'état de l'entité avant modification (sérialisé immédiatement)
+func AuditBefore(c *gin.Context, value interface{}) {
+	c.Set(auditBeforeKey, services.AuditSnapshot(value))
+}
+
+// AuditAfter enregistre l'état de l'entité après modification ; à défaut, les données soumises sont utilisées
+func AuditAfter(c *gin.Context, value interface{}) {
+	c.Set(auditAfterKey, services.AuditSnapshot(value))
+}
+
+// SetAuditDetails ajoute un commentaire libre à l'entrée d'audit
+func SetAuditDetails(c *gin.Context, details string) {
+	c.Set(auditDetailsKey, details)
+}
+
+// SkipAudit désactive la journalisation automatique (le handler enregistre lui-même l'événement)
+func SkipAudit(c *gin.Context) {
+	c.Set(auditSkipKey, true)
+}
+
+// readAuditBody lit le corps JSON de la requête sans le consommer
+func readAuditBody(c *gin.Context) []byte {
+	if c.Request.Body == nil || !strings.Contains(c.ContentType(), "json") {
+		return nil
+	}
+	if c.Request.ContentLength > auditMaxBodySize {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, auditMaxBodySize+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil || len(body) == 0 || len(body) > auditMaxBodySize {
+		return nil
+	}
+	return body
+}
+
+// routeSegments retourne les segments de la route après le préfixe /api/v1
+func routeSegments(c *gin.Context) []string {
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	path = strings.TrimPrefix(path, "/api/v1")
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// routeAuditAction déduit l'action de la méthode HTTP et de la route
+func routeAuditAction(c *gin.Context) string {
+	var parts []string
+	var verb string
+	segments := routeSegments(c)
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			continue
+		}
+		// Action explicite après un paramètre (ex: /users/:id/restore)
+		if i > 0 && i == len(segments)-1 && strings.HasPrefix(segments[i-1], ":") {
+			verb = segment
+			continue
+		}
+		parts = append(parts, strings.ReplaceAll(segment, "-", "_"))
+	}
+
+	if verb == "" {
+		switch c.Request.Method {
+		case http.MethodPost:
+			verb = "create"
+		case http.MethodPut, http.MethodPatch:
+			verb = "update"
+		case http.MethodDelete:
+			verb = "delete"
+		default:
+			verb = strings.ToLower(c.Request.Method)
+		}
+	}
+	return strings.Join(append(parts, strings.ReplaceAll(verb, "-", "_")), ".")
+}
+
+// routeAuditTargetType déduit le type d'entité ciblée (premier segment après l'espace admin/editor/group-admin)
+func routeAuditTargetType(c *gin.Context) string {
+	segments := routeSegments(c)
+	if len(segments) > 1 {
+		return segments[1]
+	}
+	if len(segments) == 1 {
+		return segments[0]
+	}
+	return ""
+}
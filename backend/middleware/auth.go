@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,19 +10,37 @@ import (
 
 	"airboard/config"
 	"airboard/models"
+	"airboard/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 )
 
+// SessionIDKey clé de contexte de l'identifiant de session du jeton d'accès (claim sid)
+const SessionIDKey = "session_id"
+
 type AuthMiddleware struct {
-	config *config.Config
-	db     *gorm.DB
+	config      *config.Config
+	db          *gorm.DB
+	permissions *services.PermissionService
+	apiTokens   *services.APITokenService
+	audit       *services.AuditService
 }
 
 func NewAuthMiddleware(cfg *config.Config, db *gorm.DB) *AuthMiddleware {
-	return &AuthMiddleware{config: cfg, db: db}
+	return &AuthMiddleware{
+		config:      cfg,
+		db:          db,
+		permissions: services.NewPermissionService(db),
+		apiTokens:   services.NewAPITokenService(db),
+		audit:       services.NewAuditService(db),
+	}
+}
+
+// Permissions retourne le service de résolution des permissions
+func (am *AuthMiddleware) Permissions() *services.PermissionService {
+	return am.permissions
 }
 
 // RequireAuth middleware pour vérifier l'authentification
@@ -55,6 +75,21 @@ func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			}
 		}
 
+		// Jetons d'API (accès personnels et comptes de service)
+		if strings.HasPrefix(tokenString, models.APITokenPrefix) {
+			if authHeader == "" {
+				c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Error:   "Unauthorized",
+					Message: "Les jetons d'API doivent être transmis dans l'en-tête Authorization",
+					Code:    http.StatusUnauthorized,
+				})
+				c.Abort()
+				return
+			}
+			am.authenticateAPIToken(c, tokenString)
+			return
+		}
+
 		// Vérifier le token
 		claims, err := am.verifyToken(tokenString)
 		if err != nil {
@@ -67,11 +102,29 @@ func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
+		// Jeton d'usurpation : la session doit être encore active
+		if claims.ImpersonationID != 0 && !am.checkImpersonation(c, claims) {
+			return
+		}
+
+		// Session de connexion fermée par un Single Logout de l'IdP SAML
+		if am.SessionEnded(claims.SessionID) {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "session_ended",
+				Message: "Session fermée par le fournisseur d'identité",
+				Code:    http.StatusUnauthorized,
+			})
+			c.Abort()
+			return
+		}
+
 		// Stocker les informations de l'utilisateur dans le contexte
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
 		c.Set("email", claims.Email)
+		c.Set("auth_method", AuthMethodJWT)
+		c.Set(SessionIDKey, claims.SessionID)
 
 		// Charger dynamiquement les groupes administrés depuis la BDD
 		// (au lieu d'utiliser ceux du JWT qui peuvent être obsolètes)
@@ -81,29 +134,40 @@ func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			Pluck("group_id", &managedGroupIDs)
 		c.Set("managed_group_ids", managedGroupIDs)
 
+		// Permissions effectives (rôle de base, groupes administrés et rôles attribués)
+		c.Set("permissions", am.permissions.Resolve(claims.UserID, claims.Role, managedGroupIDs))
+
 		c.Next()
+
+		// Toute requête effectuée sous une identité usurpée est journalisée
+		if claims.ImpersonationID != 0 {
+			am.recordImpersonatedRequest(c, claims)
+		}
 	}
 }
 
-// RequireAdmin middleware pour vérifier les droits administrateur
-func (am *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, models.ErrorResponse{
-				Error:   "Forbidden",
-				Message: "Droits administrateur requis",
-				Code:    http.StatusForbidden,
-			})
-			c.Abort()
-			return
-		}
-		c.Next()
+// SessionEnded indique si la session de connexion a été fermée par un Single Logout initié par l'IdP
+func (am *AuthMiddleware) SessionEnded(sessionID string) bool {
+	if sessionID == "" {
+		return false
 	}
+	var count int64
+	am.db.Model(&models.SAMLSession{}).
+		Where("login_session_id = ? AND ended_at IS NOT NULL", sessionID).
+		Count(&count)
+	return count > 0
 }
 
-// GenerateToken génère un token JWT
-func (am *AuthMiddleware) GenerateToken(user *models.User) (string, error) {
+// NewSessionID génère l'identifiant d'une nouvelle session de connexion, porté par le token d'accès et
+// le refresh token (claim sid). Les tokens CSRF y sont liés : une nouvelle connexion les invalide.
+func NewSessionID() string {
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// GenerateToken génère un token JWT pour la session de connexion sessionID
+func (am *AuthMiddleware) GenerateToken(user *models.User, sessionID string) (string, error) {
 	// Charger les groupes administrés pour tous les utilisateurs
 	var managedGroupIDs []uint
 	am.db.Table("group_admins").
@@ -116,6 +180,7 @@ func (am *AuthMiddleware) GenerateToken(user *models.User) (string, error) {
 		"role":              user.Role,
 		"email":             user.Email,
 		"managed_group_ids": managedGroupIDs,
+		"sid":               sessionID,
 		"exp":               time.Now().Add(time.Hour * time.Duration(am.config.JWT.TokenExpirationHours)).Unix(),
 		"iat":               time.Now().Unix(),
 	}
@@ -124,8 +189,8 @@ func (am *AuthMiddleware) GenerateToken(user *models.User) (string, error) {
 	return token.SignedString([]byte(am.config.JWT.Secret))
 }
 
-// GenerateRefreshToken génère un refresh token
-func (am *AuthMiddleware) GenerateRefreshToken(user *models.User) (string, error) {
+// GenerateRefreshToken génère un refresh token pour la session de connexion sessionID
+func (am *AuthMiddleware) GenerateRefreshToken(user *models.User, sessionID string) (string, error) {
 	// Charger les groupes administrés pour tous les utilisateurs
 	var managedGroupIDs []uint
 	am.db.Table("group_admins").
@@ -138,6 +203,7 @@ func (am *AuthMiddleware) GenerateRefreshToken(user *models.User) (string, error
 		"role":              user.Role,
 		"email":             user.Email,
 		"managed_group_ids": managedGroupIDs,
+		"sid":               sessionID,
 		"exp":               time.Now().Add(time.Hour * 24 * time.Duration(am.config.JWT.RefreshExpirationDays)).Unix(),
 		"iat":               time.Now().Unix(),
 		"type":              "refresh",
@@ -194,6 +260,10 @@ func (am *AuthMiddleware) verifyToken(tokenString string) (*models.Claims, error
 		Email:           claims["email"].(string),
 		ManagedGroupIDs: managedGroupIDs,
 	}
+	if sid, ok := claims["sid"].(string); ok {
+		userClaims.SessionID = sid
+	}
+	impersonationClaims(claims, userClaims)
 
 	return userClaims, nil
 }
@@ -255,6 +325,9 @@ func (am *AuthMiddleware) VerifyRefreshToken(tokenString string) (*models.Claims
 		Email:           claims["email"].(string),
 		ManagedGroupIDs: managedGroupIDs,
 	}
+	if sid, ok := claims["sid"].(string); ok {
+		userClaims.SessionID = sid
+	}
 
 	return userClaims, nil
 }
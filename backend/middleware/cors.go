@@ -76,8 +76,13 @@ func createSecureCORSConfig(cfg *config.Config) *SecureCORSConfig {
 			"X-Requested-With", // AJAX requests
 		},
 		ExposedHeaders: []string{
-			"Content-Length", // Informations de taille
-			"X-Total-Count",  // Pagination info
+			"Content-Length",  // Informations de taille
+			"X-Total-Count",   // Pagination info
+			"RateLimit-Limit", // Limitation des requêtes
+			"RateLimit-Remaining",
+			"RateLimit-Reset",
+			"RateLimit-Policy",
+			"Retry-After",
 		},
 		AllowCredentials: shouldAllowCredentials(allowedOrigins),
 		MaxAge:           time.Duration(3600) * time.Second, // 1 heure max (réduit pour sécurité)
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
-	"encoding/hex"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,142 +16,120 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// CSRFToken représente un token CSRF avec son expiration
-type CSRFToken struct {
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-}
+// Durée de validité d'un token CSRF
+const CSRFTokenTTL = 12 * time.Hour
 
-// CSRFManager gère les tokens CSRF
+// CSRFManager émet et vérifie des tokens CSRF signés (HMAC) sans stockage côté serveur.
+// Un token est lié à l'utilisateur authentifié et à sa session de connexion (claim sid du token d'accès,
+// conservé lors du rafraîchissement) : une nouvelle connexion invalide les tokens émis auparavant. Il est
+// vérifiable par toutes les instances partageant JWT_SECRET, survit aux redémarrages et plusieurs tokens
+// peuvent être valides simultanément (un par onglet).
+//
+// Format : <nonce>.<expiration unix>.<HMAC-SHA256(user_id, session, nonce, expiration)>, en base64url.
 type CSRFManager struct {
-	tokens map[string]CSRFToken // userID -> CSRFToken
+	key []byte
 }
 
-// NewCSRFManager crée un nouveau gestionnaire CSRF
-func NewCSRFManager() *CSRFManager {
-	return &CSRFManager{
-		tokens: make(map[string]CSRFToken),
-	}
+// NewCSRFManager crée un gestionnaire CSRF dont la clé est dérivée du secret JWT
+func NewCSRFManager(secret string) *CSRFManager {
+	key := sha256.Sum256([]byte("airboard-csrf:" + secret))
+	return &CSRFManager{key: key[:]}
 }
 
-// generateToken génère un token CSRF aléatoire
-func (csm *CSRFManager) generateToken() string {
-	bytes := make([]byte, 32)
-	if _, err := rand.Read(bytes); err != nil {
-		// Fallback: utiliser le temps actuel si rand.Read échoue
-		hash := sha256.Sum256([]byte(fmt.Sprintf("%d", time.Now().UnixNano())))
-		return hex.EncodeToString(hash[:])
+// GenerateToken génère un token CSRF pour une session de connexion d'un utilisateur
+func (csm *CSRFManager) GenerateToken(userID uint, sessionID string) (string, time.Time, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", time.Time{}, err
 	}
-	return hex.EncodeToString(bytes)
-}
-
-// generateCSRFForUser génère un token CSRF pour un utilisateur
-func (csm *CSRFManager) generateCSRFForUser(userID uint) string {
-	// Nettoyer les anciens tokens expirés
-	csm.cleanupExpiredTokens()
-
-	// Générer un nouveau token
-	token := csm.generateToken()
 
-	// Stocker avec expiration (1 heure)
-	csm.tokens[fmt.Sprintf("%d", userID)] = CSRFToken{
-		Token:     token,
-		ExpiresAt: time.Now().Add(time.Hour),
-	}
-
-	return token
+	expiresAt := time.Now().Add(CSRFTokenTTL)
+	encodedNonce := base64.RawURLEncoding.EncodeToString(nonce)
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return encodedNonce + "." + expiry + "." + csm.sign(userID, sessionID, encodedNonce, expiry), expiresAt, nil
 }
 
-// validateCSRFToken valide un token CSRF
-func (csm *CSRFManager) validateCSRFToken(userID uint, token string) bool {
-	if token == "" {
+// ValidateToken vérifie la signature et l'expiration d'un token pour une session de connexion d'un utilisateur
+func (csm *CSRFManager) ValidateToken(userID uint, sessionID, token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
 		return false
 	}
 
-	userToken, exists := csm.tokens[fmt.Sprintf("%d", userID)]
-	if !exists {
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiry {
 		return false
 	}
 
-	// Vérifier l'expiration
-	if time.Now().After(userToken.ExpiresAt) {
-		delete(csm.tokens, fmt.Sprintf("%d", userID))
-		return false
-	}
+	expected := csm.sign(userID, sessionID, parts[0], parts[1])
+	return hmac.Equal([]byte(parts[2]), []byte(expected))
+}
 
-	// Vérifier la correspondance
-	return userToken.Token == token
+func (csm *CSRFManager) sign(userID uint, sessionID, nonce, expiry string) string {
+	mac := hmac.New(sha256.New, csm.key)
+	fmt.Fprintf(mac, "%d|%s|%s|%s", userID, sessionID, nonce, expiry)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
 
-// cleanupExpiredTokens supprime les tokens expirés
-func (csm *CSRFManager) cleanupExpiredTokens() {
-	now := time.Now()
-	for userID, tokenData := range csm.tokens {
-		if now.After(tokenData.ExpiresAt) {
-			delete(csm.tokens, userID)
-		}
+// isStateChanging indique si la méthode HTTP modifie l'état du serveur
+func isStateChanging(method string) bool {
+	return method == http.MethodPost ||
+		method == http.MethodPut ||
+		method == http.MethodDelete ||
+		method == http.MethodPatch
+}
+
+// csrfTokenFromRequest récupère le token depuis l'en-tête X-CSRF-Token ou un champ de formulaire
+// (jamais depuis l'URL, qui peut fuiter dans les journaux)
+func csrfTokenFromRequest(c *gin.Context) string {
+	if token := c.GetHeader("X-CSRF-Token"); token != "" {
+		return token
+	}
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") || c.ContentType() == "application/x-www-form-urlencoded" {
+		return c.PostForm("csrf_token")
 	}
+	return ""
 }
 
-// CSRFProtection middleware pour protéger les requêtes de mutation
+// CSRFProtection exige un token CSRF valide sur toutes les requêtes modifiantes (POST, PUT, PATCH, DELETE).
+// À placer après RequireAuth. Les requêtes authentifiées par jeton d'API (clients non navigateur) en sont exemptées.
 func CSRFProtection(csrfManager *CSRFManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Méthodes qui nécessitent une protection CSRF
-		if c.Request.Method == http.MethodPost ||
-			c.Request.Method == http.MethodPut ||
-			c.Request.Method == http.MethodDelete ||
-			c.Request.Method == http.MethodPatch {
-
-			// Ignorer certains endpoints (webhooks, API externes)
-			if strings.Contains(c.Request.URL.Path, "/webhook") ||
-				strings.Contains(c.Request.URL.Path, "/api/v1/oauth/callback") {
-				c.Next()
-				return
-			}
-
-			// Vérifier la présence du token CSRF
-			userID, exists := c.Get("user_id")
-			if !exists {
-				c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-					Error:   "Unauthorized",
-					Message: "Token CSRF requis - utilisateur non authentifié",
-					Code:    http.StatusUnauthorized,
-				})
-				c.Abort()
-				return
-			}
-
-			// Récupérer le token CSRF depuis l'en-tête ou le formulaire
-			csrfToken := c.GetHeader("X-CSRF-Token")
-			if csrfToken == "" {
-				csrfToken = c.PostForm("csrf_token")
-			}
-			if csrfToken == "" {
-				csrfToken = c.Query("csrf_token")
-			}
-
-			// Valider le token CSRF
-			if !csrfManager.validateCSRFToken(userID.(uint), csrfToken) {
-				c.JSON(http.StatusForbidden, models.ErrorResponse{
-					Error:   "Forbidden",
-					Message: "Token CSRF invalide ou expiré",
-					Code:    http.StatusForbidden,
-				})
-				c.Abort()
-				return
-			}
+		if !isStateChanging(c.Request.Method) || IsAPITokenRequest(c) {
+			c.Next()
+			return
+		}
+
+		userID := c.GetUint("user_id")
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Token CSRF requis - utilisateur non authentifié",
+				Code:    http.StatusUnauthorized,
+			})
+			c.Abort()
+			return
+		}
+
+		if !csrfManager.ValidateToken(userID, c.GetString(SessionIDKey), csrfTokenFromRequest(c)) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "csrf_invalid",
+				Message: "Token CSRF manquant, invalide ou expiré",
+				Code:    http.StatusForbidden,
+			})
+			c.Abort()
+			return
 		}
 
 		c.Next()
 	}
 }
 
-// CSRFTokenHandler génère un nouveau token CSRF
+// CSRFTokenHandler génère un nouveau token CSRF (les tokens émis précédemment restent valides)
 func CSRFTokenHandler(csrfManager *CSRFManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Vérifier l'authentification
-		userID, exists := c.Get("user_id")
-		if !exists {
+		userID := c.GetUint("user_id")
+		if userID == 0 {
 			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:   "Unauthorized",
 				Message: "Authentification requise pour générer un token CSRF",
@@ -158,52 +138,20 @@ func CSRFTokenHandler(csrfManager *CSRFManager) gin.HandlerFunc {
 			return
 		}
 
-		// Générer un nouveau token CSRF
-		token := csrfManager.generateCSRFForUser(userID.(uint))
+		token, expiresAt, err := csrfManager.GenerateToken(userID, c.GetString(SessionIDKey))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Erreur lors de la génération du token CSRF",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
 
+		c.Header("Cache-Control", "no-store")
 		c.JSON(http.StatusOK, gin.H{
 			"csrf_token": token,
-			"expires_at": time.Now().Add(time.Hour),
+			"expires_at": expiresAt,
 		})
 	}
 }
-
-// OptionalCSRFProtection middleware optionnel pour CSRF (ne bloque pas si absent)
-func OptionalCSRFProtection(csrfManager *CSRFManager) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Seulement pour les méthodes de mutation
-		if c.Request.Method == http.MethodPost ||
-			c.Request.Method == http.MethodPut ||
-			c.Request.Method == http.MethodDelete ||
-			c.Request.Method == http.MethodPatch {
-
-			userID, exists := c.Get("user_id")
-			if !exists {
-				c.Next()
-				return
-			}
-
-			// Récupérer le token CSRF
-			csrfToken := c.GetHeader("X-CSRF-Token")
-			if csrfToken == "" {
-				csrfToken = c.PostForm("csrf_token")
-			}
-			if csrfToken == "" {
-				csrfToken = c.Query("csrf_token")
-			}
-
-			// Si token présent, le valider
-			if csrfToken != "" && !csrfManager.validateCSRFToken(userID.(uint), csrfToken) {
-				c.JSON(http.StatusForbidden, models.ErrorResponse{
-					Error:   "Forbidden",
-					Message: "Token CSRF invalide",
-					Code:    http.StatusForbidden,
-				})
-				c.Abort()
-				return
-			}
-		}
-
-		c.Next()
-	}
-}
@@ -1,85 +1,15 @@
 package middleware
 
 import (
-	"net/http"
-
 	"airboard/models"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// RequireGroupAdmin vérifie que l'utilisateur est admin global OU administre au moins un groupe (via table group_admins)
-func (am *AuthMiddleware) RequireGroupAdmin() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-				Error:   "Unauthorized",
-				Message: "Authentication requise",
-				Code:    http.StatusUnauthorized,
-			})
-			c.Abort()
-			return
-		}
-
-		roleStr, ok := role.(string)
-		if !ok {
-			c.JSON(http.StatusForbidden, models.ErrorResponse{
-				Error:   "Forbidden",
-				Message: "Rôle invalide",
-				Code:    http.StatusForbidden,
-			})
-			c.Abort()
-			return
-		}
-
-		// Vérifier les rôles non autorisés
-		if roleStr == "" || roleStr == "banned" {
-			c.JSON(http.StatusForbidden, models.ErrorResponse{
-				Error:   "Forbidden",
-				Message: "Accès non autorisé",
-				Code:    http.StatusForbidden,
-			})
-			c.Abort()
-			return
-		}
-
-		// Admin global peut tout faire
-		if roleStr == "admin" {
-			c.Next()
-			return
-		}
-
-		// Vérifier si l'utilisateur administre au moins un groupe (via table group_admins)
-		managedGroupIDsInterface, exists := c.Get("managed_group_ids")
-		var managedGroupIDs []uint
-		if exists {
-			if mgids, ok := managedGroupIDsInterface.([]uint); ok {
-				managedGroupIDs = mgids
-			}
-		}
-
-		if len(managedGroupIDs) > 0 {
-			// L'utilisateur administre au moins un groupe, donc il peut accéder
-			c.Next()
-			return
-		}
-
-		// Sinon, accès refusé
-		c.JSON(http.StatusForbidden, models.ErrorResponse{
-			Error:   "Forbidden",
-			Message: "Rôle Admin ou Admin de groupe requis",
-			Code:    http.StatusForbidden,
-		})
-		c.Abort()
-	}
-}
-
 // CanManageGroup vérifie si l'utilisateur peut gérer un groupe spécifique
 func CanManageGroup(c *gin.Context, groupID uint) bool {
-	role := c.GetString("role")
-	if role == "admin" {
+	if HasPermission(c, models.PermGroupsManage) {
 		return true // Admin global peut tout gérer
 	}
 
@@ -123,12 +53,11 @@ func GetManagedGroupIDs(c *gin.Context) []uint {
 // - ET l'AppGroup est lié à l'un des groupes qu'il administre (via group_app_groups)
 // Les AppGroups publics (IsPrivate = false) ne peuvent être administrés que par l'admin global
 func CanManageAppGroupWithDB(c *gin.Context, appGroupID uint, db interface{}) bool {
-	role := c.GetString("role")
-	if role == "admin" {
+	if HasPermission(c, models.PermApplicationsManage) {
 		return true // Admin global peut tout gérer
 	}
 
-	managedGroupIDs := GetManagedGroupIDs(c)
+	managedGroupIDs := PermissionGroupIDs(c, models.PermApplicationsManage)
 	if len(managedGroupIDs) == 0 {
 		return false
 	}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"airboard/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Clés de contexte renseignées par RequireAuth pendant une usurpation d'identité
+const (
+	ImpersonationIDKey   = "impersonation_id"
+	ImpersonatorIDKey    = "impersonator_id"
+	ImpersonatorNameKey  = "impersonator_username"
+	impersonationExpires = "impersonation_expires_at"
+)
+
+// GenerateImpersonationToken génère un jeton d'accès au nom de l'utilisateur cible, marqué de l'ID de
+// l'administrateur et de la session. Il expire avec la session et n'est accompagné d'aucun refresh token.
+func (am *AuthMiddleware) GenerateImpersonationToken(target *models.User, impersonator *models.User, session *models.ImpersonationSession) (string, error) {
+	var managedGroupIDs []uint
+	am.db.Table("group_admins").
+		Where("user_id = ?", target.ID).
+		Pluck("group_id", &managedGroupIDs)
+
+	claims := jwt.MapClaims{
+		"user_id":               target.ID,
+		"username":              target.Username,
+		"role":                  target.Role,
+		"email":                 target.Email,
+		"managed_group_ids":     managedGroupIDs,
+		"impersonation_id":      session.ID,
+		"impersonator_id":       impersonator.ID,
+		"impersonator_username": impersonator.Username,
+		"sid":                   NewSessionID(),
+		"exp":                   session.ExpiresAt.Unix(),
+		"iat":                   time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(am.config.JWT.Secret))
+}
+
+// impersonationClaims extrait les informations d'usurpation d'un jeton (absentes pour un jeton ordinaire)
+func impersonationClaims(claims jwt.MapClaims, userClaims *models.Claims) {
+	if id, ok := claims["impersonation_id"].(float64); ok {
+		userClaims.ImpersonationID = uint(id)
+	}
+	if id, ok := claims["impersonator_id"].(float64); ok {
+		userClaims.ImpersonatorID = uint(id)
+	}
+	if name, ok := claims["impersonator_username"].(string); ok {
+		userClaims.ImpersonatorUsername = name
+	}
+}
+
+// checkImpersonation vérifie que la session d'usurpation du jeton est toujours active
+// (elle peut être arrêtée avant son expiration) et renseigne le contexte
+func (am *AuthMiddleware) checkImpersonation(c *gin.Context, claims *models.Claims) bool {
+	var session models.ImpersonationSession
+	err := am.db.First(&session, claims.ImpersonationID).Error
+	if err != nil || !session.IsActive() || session.TargetUserID != claims.UserID || session.ImpersonatorID != claims.ImpersonatorID {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "impersonation_ended",
+			Message: "Session d'usurpation terminée ou expirée",
+			Code:    http.StatusUnauthorized,
+		})
+		c.Abort()
+		return false
+	}
+
+	c.Set(ImpersonationIDKey, session.ID)
+	c.Set(ImpersonatorIDKey, session.ImpersonatorID)
+	c.Set(ImpersonatorNameKey, claims.ImpersonatorUsername)
+	c.Set(impersonationExpires, session.ExpiresAt)
+	return true
+}
+
+// recordImpersonatedRequest journalise une requête effectuée sous une identité usurpée
+func (am *AuthMiddleware) recordImpersonatedRequest(c *gin.Context, claims *models.Claims) {
+	entry := NewAuditEntry(c, models.AuditActionImpersonatedRequest)
+	entry.Action = models.AuditActionImpersonatedRequest
+	entry.TargetType = "users"
+	entry.TargetID = strconv.FormatUint(uint64(claims.UserID), 10)
+	entry.TargetLabel = claims.Username
+	entry.StatusCode = c.Writer.Status()
+	entry.Success = entry.StatusCode < http.StatusBadRequest
+	entry.Details = fmt.Sprintf("%s en tant que %s (session #%d)", claims.ImpersonatorUsername, claims.Username, claims.ImpersonationID)
+	am.audit.Record(entry)
+
+	now := time.Now()
+	err := am.db.Model(&models.ImpersonationSession{}).Where("id = ?", claims.ImpersonationID).
+		Updates(map[string]interface{}{
+			"request_count":   gorm.Expr("request_count + 1"),
+			"last_request_at": now,
+		}).Error
+	if err != nil {
+		log.Printf("[Impersonation] Erreur mise à jour de la session %d: %v", claims.ImpersonationID, err)
+	}
+}
+
+// IsImpersonating indique si la requête est effectuée sous une identité usurpée
+func IsImpersonating(c *gin.Context) bool {
+	return c.GetUint(ImpersonationIDKey) != 0
+}
+
+// GetImpersonation retourne l'indicateur d'usurpation de la requête (nil hors usurpation)
+func GetImpersonation(c *gin.Context) *models.ImpersonationInfo {
+	if !IsImpersonating(c) {
+		return nil
+	}
+	expiresAt, _ := c.Get(impersonationExpires)
+	info := &models.ImpersonationInfo{
+		SessionID:            c.GetUint(ImpersonationIDKey),
+		ImpersonatorID:       c.GetUint(ImpersonatorIDKey),
+		ImpersonatorUsername: c.GetString(ImpersonatorNameKey),
+	}
+	if t, ok := expiresAt.(time.Time); ok {
+		info.ExpiresAt = t
+	}
+	return info
+}
+
+// RequireNotImpersonating refuse les actions sensibles pendant une usurpation d'identité
+// (changement de mot de passe, gestion de l'authentification forte, création de jetons, nouvelle usurpation)
+func (am *AuthMiddleware) RequireNotImpersonating() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsImpersonating(c) {
+			entry := NewAuditEntry(c, models.AuditActionImpersonationBlocked)
+			entry.Action = models.AuditActionImpersonationBlocked
+			entry.StatusCode = http.StatusForbidden
+			am.audit.Record(entry)
+
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "impersonation_forbidden",
+				Message: "Action interdite pendant une usurpation d'identité",
+				Code:    http.StatusForbidden,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"airboard/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission vérifie que l'utilisateur dispose d'au moins une des permissions sans restriction de périmètre
+func (am *AuthMiddleware) RequirePermission(permissions ...string) gin.HandlerFunc {
+	return am.requirePermission(permissions, false)
+}
+
+// RequireScopedPermission vérifie que l'utilisateur dispose d'au moins une des permissions, quel que soit le périmètre.
+// Réservé aux routes dont les handlers vérifient eux-mêmes le périmètre (CanTargetGroups, CanUseCategory).
+func (am *AuthMiddleware) RequireScopedPermission(permissions ...string) gin.HandlerFunc {
+	return am.requirePermission(permissions, true)
+}
+
+func (am *AuthMiddleware) requirePermission(permissions []string, scoped bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set := GetPermissions(c)
+		for _, permission := range permissions {
+			if set.Has(permission) || (scoped && set.HasAny(permission)) {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Permission requise: " + strings.Join(permissions, " ou "),
+			Code:    http.StatusForbidden,
+		})
+		c.Abort()
+	}
+}
+
+// GetPermissions retourne les permissions effectives de l'utilisateur connecté
+func GetPermissions(c *gin.Context) *models.PermissionSet {
+	if value, exists := c.Get("permissions"); exists {
+		if set, ok := value.(*models.PermissionSet); ok {
+			return set
+		}
+	}
+	return models.NewPermissionSet()
+}
+
+// HasPermission indique si l'utilisateur dispose de la permission sans restriction de périmètre
+func HasPermission(c *gin.Context, permission string) bool {
+	return GetPermissions(c).Has(permission)
+}
+
+// HasAnyPermission indique si l'utilisateur dispose de la permission sur au moins un périmètre
+func HasAnyPermission(c *gin.Context, permission string) bool {
+	return GetPermissions(c).HasAny(permission)
+}
+
+// PermissionGroupIDs retourne les groupes sur lesquels l'utilisateur dispose de la permission
+func PermissionGroupIDs(c *gin.Context, permission string) []uint {
+	return GetPermissions(c).GroupIDs(permission)
+}
+
+// CanTargetGroups vérifie que l'utilisateur peut cibler les groupes donnés avec la permission.
+// Sans groupe ciblé, ou si la permission n'est restreinte qu'à des catégories, il suffit de disposer
+// de la permission sur un périmètre quelconque.
+func CanTargetGroups(c *gin.Context, permission string, groupIDs []uint) bool {
+	set := GetPermissions(c)
+	if set.Has(permission) {
+		return true
+	}
+	if len(groupIDs) == 0 || len(set.GroupIDs(permission)) == 0 {
+		return set.HasAny(permission)
+	}
+	return set.InGroups(permission, groupIDs)
+}
+
+// CanUseCategory vérifie le périmètre catégorie d'une permission.
+// Une permission sans restriction de catégorie autorise toutes les catégories.
+func CanUseCategory(c *gin.Context, permission string, categoryID *uint) bool {
+	set := GetPermissions(c)
+	if set.Has(permission) || len(set.GroupIDs(permission)) > 0 {
+		return set.HasAny(permission)
+	}
+	if len(set.CategoryIDs(permission)) == 0 {
+		return set.HasAny(permission)
+	}
+	return categoryID != nil && set.InCategory(permission, *categoryID)
+}
@@ -1,51 +1,67 @@
 package middleware
 
 import (
+	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"time"
+	"strconv"
 
 	"airboard/models"
+	"airboard/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/ulule/limiter/v3"
-	"github.com/ulule/limiter/v3/drivers/store/memory"
 )
 
-// RateLimitMiddleware crée un middleware de rate limiting
-func RateLimitMiddleware(rate limiter.Rate) gin.HandlerFunc {
-	store := memory.NewStore()
-	instance := limiter.New(store, rate)
+// RateLimiter applique les politiques de limitation configurées par groupe de routes et par rôle
+type RateLimiter struct {
+	service *services.RateLimitService
+	enabled bool
+}
+
+// NewRateLimiter crée le middleware de limitation (sans effet si enabled est faux)
+func NewRateLimiter(service *services.RateLimitService, enabled bool) *RateLimiter {
+	return &RateLimiter{service: service, enabled: enabled}
+}
 
+// Limit limite les requêtes du groupe de routes donné.
+// Le client est identifié par son ID utilisateur s'il est authentifié (middleware placé après RequireAuth), sinon par son IP.
+// Les en-têtes RateLimit-* suivent le brouillon IETF "RateLimit header fields for HTTP".
+func (rl *RateLimiter) Limit(routeGroup string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Utiliser l'IP du client comme identifiant
-		key := c.ClientIP()
+		if !rl.enabled {
+			c.Next()
+			return
+		}
 
-		// Si l'utilisateur est authentifié, utiliser son ID pour un meilleur contrôle
-		if userID, exists := c.Get("user_id"); exists {
-			key = userID.(string)
+		subject := "ip:" + c.ClientIP()
+		role := models.RateLimitRoleAnonymous
+		if userID := c.GetUint("user_id"); userID != 0 {
+			subject = fmt.Sprintf("user:%d", userID)
+			role = c.GetString("role")
 		}
 
-		context, err := instance.Get(c.Request.Context(), key)
+		decision, err := rl.service.Check(routeGroup, role, subject)
 		if err != nil {
+			// En cas d'indisponibilité du stockage, la requête est laissée passer
 			log.Printf("[RateLimit] Erreur lors de la vérification: %v", err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error:   "Internal Server Error",
-				Message: "Erreur lors de la vérification du rate limit",
-				Code:    http.StatusInternalServerError,
-			})
-			c.Abort()
+			c.Next()
+			return
+		}
+		if decision == nil {
+			c.Next()
 			return
 		}
 
-		// Ajouter les headers de rate limit
-		c.Header("X-RateLimit-Limit", string(rune(context.Limit)))
-		c.Header("X-RateLimit-Remaining", string(rune(context.Remaining)))
-		c.Header("X-RateLimit-Reset", string(rune(context.Reset)))
+		reset := int(math.Ceil(decision.Reset.Seconds()))
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Policy.MaxRequests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Policy.MaxRequests, decision.Policy.PeriodSeconds))
 
-		// Si la limite est dépassée, retourner une erreur 429
-		if context.Reached {
-			log.Printf("[RateLimit] Limite atteinte pour %s - IP: %s", key, c.ClientIP())
+		if !decision.Allowed {
+			log.Printf("[RateLimit] Limite atteinte pour %s sur %s (politique %d)", subject, routeGroup, decision.Policy.ID)
+			c.Header("Retry-After", strconv.Itoa(reset))
 			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
 				Error:   "Too Many Requests",
 				Message: "Trop de requêtes. Veuillez réessayer plus tard.",
@@ -58,43 +74,3 @@ func RateLimitMiddleware(rate limiter.Rate) gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-// GlobalRateLimit limite globale pour toutes les requêtes
-// 100 requêtes par minute par IP
-func GlobalRateLimit() gin.HandlerFunc {
-	rate := limiter.Rate{
-		Period: 1 * time.Minute,
-		Limit:  100,
-	}
-	return RateLimitMiddleware(rate)
-}
-
-// AuthRateLimit limite stricte pour les endpoints d'authentification
-// 5 tentatives par minute par IP pour éviter le brute-force
-func AuthRateLimit() gin.HandlerFunc {
-	rate := limiter.Rate{
-		Period: 1 * time.Minute,
-		Limit:  5,
-	}
-	return RateLimitMiddleware(rate)
-}
-
-// APIRateLimit limite pour les endpoints API généraux
-// 60 requêtes par minute par utilisateur
-func APIRateLimit() gin.HandlerFunc {
-	rate := limiter.Rate{
-		Period: 1 * time.Minute,
-		Limit:  60,
-	}
-	return RateLimitMiddleware(rate)
-}
-
-// StrictAPIRateLimit limite stricte pour les opérations sensibles
-// 10 requêtes par minute (création, modification, suppression)
-func StrictAPIRateLimit() gin.HandlerFunc {
-	rate := limiter.Rate{
-		Period: 1 * time.Minute,
-		Limit:  10,
-	}
-	return RateLimitMiddleware(rate)
-}
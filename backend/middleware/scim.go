@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"airboard/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HashSCIMToken retourne l'empreinte SHA-256 stockée en base pour un jeton SCIM
+func HashSCIMToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// RequireSCIMToken authentifie les requêtes de provisioning SCIM par jeton bearer
+func RequireSCIMToken(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+		if authHeader == "" || token == authHeader || token == "" {
+			abortSCIM(c, http.StatusUnauthorized, "Jeton bearer requis")
+			return
+		}
+
+		var scimToken models.SCIMToken
+		if err := db.Where("token_hash = ?", HashSCIMToken(token)).First(&scimToken).Error; err != nil {
+			log.Printf("[SCIM] Jeton invalide depuis %s", c.ClientIP())
+			abortSCIM(c, http.StatusUnauthorized, "Jeton invalide")
+			return
+		}
+
+		now := time.Now()
+		if scimToken.RevokedAt != nil || (scimToken.ExpiresAt != nil && now.After(*scimToken.ExpiresAt)) {
+			abortSCIM(c, http.StatusUnauthorized, "Jeton révoqué ou expiré")
+			return
+		}
+
+		// Mettre à jour la date de dernière utilisation (au plus une fois par minute)
+		if scimToken.LastUsedAt == nil || now.Sub(*scimToken.LastUsedAt) > time.Minute {
+			db.Model(&scimToken).Update("last_used_at", now)
+		}
+
+		c.Set("scim_token_id", scimToken.ID)
+		c.Next()
+	}
+}
+
+func abortSCIM(c *gin.Context, status int, detail string) {
+	c.Header("Content-Type", "application/scim+json")
+	c.AbortWithStatusJSON(status, models.SCIMError{
+		Schemas: []string{models.SCIMSchemaError},
+		Status:  strconv.Itoa(status),
+		Detail:  detail,
+	})
+}
@@ -2,31 +2,187 @@ package middleware
 
 import (
 	"airboard/config"
+	"airboard/models"
 	"airboard/services"
+	"crypto/subtle"
+	"crypto/x509"
 	"log"
+	"net"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// SSOMiddleware détecte et traite les headers Authentik pour l'authentification SSO
+// Décisions possibles lors de l'évaluation des headers SSO
+const (
+	SSODecisionDisabled  = "disabled"   // SSO désactivé
+	SSODecisionNoHeaders = "no_headers" // Pas de headers d'identité : authentification classique
+	SSODecisionRejected  = "rejected"   // Headers présents mais source non vérifiée
+	SSODecisionAccepted  = "accepted"   // Headers acceptés
+)
+
+// SSOMiddleware détecte et traite les headers d'identité du proxy d'authentification (Authentik, oauth2-proxy, Pomerium, Traefik forward-auth)
 type SSOMiddleware struct {
-	db        *gorm.DB
-	config    *config.Config
-	ssoMapper *services.SSOMapper
+	db              *gorm.DB
+	config          *config.Config
+	ssoMapper       *services.SSOMapper
+	trustedSources  []*net.IPNet
+	clientCertNames map[string]bool
+}
+
+// SSOEvaluation décrit comment une requête a été évaluée par le middleware SSO
+type SSOEvaluation struct {
+	SSOEnabled   bool   `json:"sso_enabled"`
+	HeaderPreset string `json:"header_preset"`
+	RemoteIP     string `json:"remote_ip"` // Pair TCP direct (le proxy d'authentification)
+	ClientIP     string `json:"client_ip"` // IP client après résolution de X-Forwarded-For
+	ForwardedFor string `json:"forwarded_for"`
+	Scheme       string `json:"scheme"`
+
+	SourceTrusted bool   `json:"source_trusted"`
+	MatchedSource string `json:"matched_source,omitempty"`
+
+	SharedSecretRequired bool `json:"shared_secret_required"`
+	SharedSecretPresent  bool `json:"shared_secret_present"`
+	SharedSecretValid    bool `json:"shared_secret_valid"`
+
+	ClientCertRequired bool   `json:"client_cert_required"`
+	ClientCertPresent  bool   `json:"client_cert_present"`
+	ClientCertValid    bool   `json:"client_cert_valid"`
+	ClientCertSubject  string `json:"client_cert_subject,omitempty"`
+
+	Headers  map[string]string `json:"headers"` // Nom du header configuré => valeur reçue
+	Email    string            `json:"email,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Groups   []string          `json:"groups,omitempty"`
+
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
 }
 
 // NewSSOMiddleware crée une nouvelle instance de SSOMiddleware
 func NewSSOMiddleware(db *gorm.DB, cfg *config.Config) *SSOMiddleware {
-	return &SSOMiddleware{
-		db:        db,
-		config:    cfg,
-		ssoMapper: services.NewSSOMapper(db, cfg),
+	m := &SSOMiddleware{
+		db:              db,
+		config:          cfg,
+		ssoMapper:       services.NewSSOMapper(db, cfg),
+		clientCertNames: make(map[string]bool),
+	}
+
+	for _, source := range cfg.SSO.TrustedSources {
+		if !strings.Contains(source, "/") {
+			if ip := net.ParseIP(source); ip != nil && ip.To4() != nil {
+				source += "/32"
+			} else {
+				source += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(source)
+		if err != nil {
+			log.Printf("⚠️ SSO_TRUSTED_SOURCES: CIDR invalide ignoré: %s", source)
+			continue
+		}
+		m.trustedSources = append(m.trustedSources, network)
+	}
+
+	for _, name := range cfg.SSO.ClientCertNames {
+		m.clientCertNames[strings.ToLower(name)] = true
+	}
+
+	if cfg.SSO.Enabled && cfg.SSO.ClientCAFile != "" && (cfg.Server.TLSCertFile == "" || cfg.Server.TLSKeyFile == "") {
+		log.Printf("⚠️ SSO_CLIENT_CA_FILE est défini mais le serveur n'utilise pas TLS (SERVER_TLS_CERT_FILE/SERVER_TLS_KEY_FILE) : les headers SSO seront refusés")
 	}
+
+	return m
 }
 
-// DetectSSO détecte si la requête contient des headers Authentik
+// Evaluate analyse la requête : source, secret partagé, certificat client et headers d'identité
+func (m *SSOMiddleware) Evaluate(c *gin.Context) *SSOEvaluation {
+	headers := m.config.SSO.Headers
+	eval := &SSOEvaluation{
+		SSOEnabled:   m.config.SSO.Enabled,
+		HeaderPreset: headers.Preset,
+		RemoteIP:     c.RemoteIP(),
+		ClientIP:     c.ClientIP(),
+		ForwardedFor: c.GetHeader("X-Forwarded-For"),
+		Scheme:       "http",
+		Headers:      make(map[string]string),
+	}
+	if c.Request.TLS != nil {
+		eval.Scheme = "https"
+	}
+
+	// Headers d'identité
+	for _, name := range []string{headers.Email, headers.Username, headers.Name, headers.Groups, headers.UID} {
+		if name != "" {
+			eval.Headers[name] = c.GetHeader(name)
+		}
+	}
+	eval.Email = strings.TrimSpace(headerValue(c, headers.Email))
+	eval.Username = strings.TrimSpace(headerValue(c, headers.Username))
+	if eval.Username == "" && eval.Email != "" {
+		// oauth2-proxy et Traefik ne transmettent pas toujours d'identifiant : utiliser la partie locale de l'email
+		eval.Username = strings.SplitN(eval.Email, "@", 2)[0]
+	}
+	eval.Groups = parseGroups(headerValue(c, headers.Groups))
+
+	// Source : adresse du pair TCP (le proxy), indépendamment de X-Forwarded-For
+	if ip := net.ParseIP(eval.RemoteIP); ip != nil {
+		for _, network := range m.trustedSources {
+			if network.Contains(ip) {
+				eval.SourceTrusted = true
+				eval.MatchedSource = network.String()
+				break
+			}
+		}
+	}
+
+	// Secret partagé (optionnel)
+	if secret := m.config.SSO.SharedSecret; secret != "" {
+		eval.SharedSecretRequired = true
+		received := c.GetHeader(m.config.SSO.SharedSecretHeader)
+		eval.SharedSecretPresent = received != ""
+		eval.SharedSecretValid = subtle.ConstantTimeCompare([]byte(received), []byte(secret)) == 1
+	}
+
+	// Certificat client mTLS (optionnel, vérifié par le serveur TLS contre SSO_CLIENT_CA_FILE)
+	if m.config.SSO.ClientCAFile != "" {
+		eval.ClientCertRequired = true
+		if c.Request.TLS != nil && len(c.Request.TLS.PeerCertificates) > 0 {
+			eval.ClientCertPresent = true
+			leaf := c.Request.TLS.PeerCertificates[0]
+			eval.ClientCertSubject = leaf.Subject.String()
+			eval.ClientCertValid = len(c.Request.TLS.VerifiedChains) > 0 && m.clientCertAllowed(leaf)
+		}
+	}
+
+	switch {
+	case !eval.SSOEnabled:
+		eval.Decision = SSODecisionDisabled
+		eval.Reason = "SSO désactivé (SSO_ENABLED=false)"
+	case eval.Email == "" || eval.Username == "":
+		eval.Decision = SSODecisionNoHeaders
+		eval.Reason = "Aucun header d'identité reçu (" + headers.Email + ")"
+	case !eval.SourceTrusted:
+		eval.Decision = SSODecisionRejected
+		eval.Reason = "Adresse " + eval.RemoteIP + " absente de SSO_TRUSTED_SOURCES"
+	case eval.SharedSecretRequired && !eval.SharedSecretValid:
+		eval.Decision = SSODecisionRejected
+		eval.Reason = "Secret partagé absent ou invalide (" + m.config.SSO.SharedSecretHeader + ")"
+	case eval.ClientCertRequired && !eval.ClientCertValid:
+		eval.Decision = SSODecisionRejected
+		eval.Reason = "Certificat client absent, non vérifié ou non autorisé"
+	default:
+		eval.Decision = SSODecisionAccepted
+		eval.Reason = "Headers d'identité acceptés"
+	}
+
+	return eval
+}
+
+// DetectSSO détecte si la requête contient des headers d'identité du proxy d'authentification
 func (m *SSOMiddleware) DetectSSO() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Si SSO n'est pas activé, passer
@@ -35,56 +191,37 @@ func (m *SSOMiddleware) DetectSSO() gin.HandlerFunc {
 			return
 		}
 
-		// Vérifier la présence des headers Authentik
-		email := c.GetHeader("X-authentik-email")
-		username := c.GetHeader("X-authentik-username")
+		eval := m.Evaluate(c)
 
 		// Si pas de headers SSO, continuer normalement (mode classique)
-		if email == "" || username == "" {
+		if eval.Decision == SSODecisionNoHeaders {
 			c.Next()
 			return
 		}
 
 		// SECURITY: Valider que les headers SSO proviennent d'une source de confiance
-		clientIP := c.ClientIP()
-		trustedSources := []string{
-			"127.0.0.1",
-			"::1",
-			"localhost",
-			"172.", // Docker networks (commence par 172.)
-			"192.168.", // Private networks
-			"10.", // Private networks
-		}
-
-		isTrusted := false
-		for _, trusted := range trustedSources {
-			if clientIP == trusted || strings.HasPrefix(clientIP, trusted) {
-				isTrusted = true
-				break
-			}
-		}
-
-		if !isTrusted {
-			log.Printf("[SECURITY] Tentative de SSO spoofing détectée depuis IP non autorisée: %s (email: %s, username: %s)",
-				clientIP, email, username)
-			c.JSON(403, gin.H{
-				"error": "Forbidden",
+		if eval.Decision == SSODecisionRejected {
+			log.Printf("[SECURITY] Tentative de SSO spoofing détectée depuis %s (email: %s, username: %s): %s",
+				eval.RemoteIP, eval.Email, eval.Username, eval.Reason)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
 				"message": "Headers SSO non autorisés depuis cette source",
 			})
 			c.Abort()
 			return
 		}
 
-		log.Printf("[SSO] Headers Authentik détectés pour: %s (%s) depuis IP autorisée: %s", username, email, clientIP)
+		email := eval.Email
+		log.Printf("[SSO] Headers d'identité détectés pour: %s (%s) depuis %s", eval.Username, email, eval.RemoteIP)
 
 		// Extraire les informations SSO
 		ssoInfo := &services.SSOUserInfo{
 			Email:     email,
-			Username:  username,
-			FirstName: c.GetHeader("X-authentik-name"),
+			Username:  eval.Username,
+			FirstName: headerValue(c, m.config.SSO.Headers.Name),
 			LastName:  "",
-			Groups:    parseGroups(c.GetHeader("X-authentik-groups")),
-			SSOID:     c.GetHeader("X-authentik-uid"),
+			Groups:    eval.Groups,
+			SSOID:     headerValue(c, m.config.SSO.Headers.UID),
 		}
 
 		// Séparer FirstName et LastName si nécessaire
@@ -96,6 +233,17 @@ func (m *SSOMiddleware) DetectSSO() gin.HandlerFunc {
 			}
 		}
 
+		// Les comptes provisionnés via SCIM sont gérés par l'IdP : pas de synchronisation à la volée
+		var scimUser models.User
+		if err := m.db.Preload("Groups").Preload("AdminOfGroups").
+			Where("email = ? AND sso_provider = ?", email, services.SCIMProvider).
+			First(&scimUser).Error; err == nil {
+			c.Set("sso_user", &scimUser)
+			c.Set("sso_active", true)
+			c.Next()
+			return
+		}
+
 		// Synchroniser l'utilisateur
 		user, err := m.ssoMapper.SyncUser(ssoInfo)
 		if err != nil {
@@ -114,7 +262,47 @@ func (m *SSOMiddleware) DetectSSO() gin.HandlerFunc {
 	}
 }
 
-// parseGroups parse le header X-authentik-groups qui peut être sous différents formats
+// Diagnostics retourne l'évaluation SSO de la requête courante (admin)
+func (m *SSOMiddleware) Diagnostics(c *gin.Context) {
+	eval := m.Evaluate(c)
+
+	c.JSON(http.StatusOK, gin.H{
+		"evaluation": eval,
+		"config": gin.H{
+			"trusted_proxies":      m.config.Server.TrustedProxies,
+			"trusted_sources":      m.config.SSO.TrustedSources,
+			"shared_secret_header": m.config.SSO.SharedSecretHeader,
+			"client_cert_names":    m.config.SSO.ClientCertNames,
+			"tls_enabled":          m.config.Server.TLSCertFile != "" && m.config.Server.TLSKeyFile != "",
+			"headers":              m.config.SSO.Headers,
+		},
+	})
+}
+
+// clientCertAllowed vérifie le CN ou les SAN DNS du certificat client
+func (m *SSOMiddleware) clientCertAllowed(cert *x509.Certificate) bool {
+	if len(m.clientCertNames) == 0 {
+		return true
+	}
+	if m.clientCertNames[strings.ToLower(cert.Subject.CommonName)] {
+		return true
+	}
+	for _, name := range cert.DNSNames {
+		if m.clientCertNames[strings.ToLower(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+func headerValue(c *gin.Context, name string) string {
+	if name == "" {
+		return ""
+	}
+	return c.GetHeader(name)
+}
+
+// parseGroups parse le header des groupes qui peut être sous différents formats
 func parseGroups(groupsHeader string) []string {
 	if groupsHeader == "" {
 		return []string{}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Contenus pouvant exiger un accusé de lecture (ContentAcknowledgement.EntityType)
+const (
+	AckEntityNews         = "news"
+	AckEntityAnnouncement = "announcement"
+)
+
+// ContentAcknowledgement accusé de lecture explicite (« J'ai lu et compris ») d'un contenu par un utilisateur
+type ContentAcknowledgement struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	EntityType     string    `json:"entity_type" gorm:"size:20;not null;uniqueIndex:idx_content_ack_user,priority:1"`
+	EntityID       uint      `json:"entity_id" gorm:"not null;uniqueIndex:idx_content_ack_user,priority:2"`
+	UserID         uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_content_ack_user,priority:3;index"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+	IPAddress      string    `json:"ip_address,omitempty" gorm:"size:45"` // Preuve de l'action pour le rapport de conformité
+}
+
+// PendingAcknowledgement contenu en attente d'accusé de lecture pour l'utilisateur connecté
+type PendingAcknowledgement struct {
+	EntityType string     `json:"entity_type"`
+	EntityID   uint       `json:"entity_id"`
+	Title      string     `json:"title"`
+	Summary    string     `json:"summary,omitempty"`
+	Slug       string     `json:"slug,omitempty"` // News uniquement
+	DueAt      *time.Time `json:"due_at"`
+	Overdue    bool       `json:"overdue"`
+}
+
+// AcknowledgementGroupStats avancement des accusés de lecture parmi les membres d'un groupe (GroupID 0 = sans groupe)
+type AcknowledgementGroupStats struct {
+	GroupID      uint    `json:"group_id"`
+	Name         string  `json:"name"`
+	Audience     int     `json:"audience"`
+	Acknowledged int     `json:"acknowledged"`
+	Pending      int     `json:"pending"`
+	Rate         float64 `json:"rate"` // Pourcentage d'accusés de lecture
+}
+
+// AcknowledgementReportUser utilisateur ciblé et date de son accusé de lecture (nil = en attente)
+type AcknowledgementReportUser struct {
+	ID             uint       `json:"id"`
+	Username       string     `json:"username"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	Email          string     `json:"email"`
+	Department     string     `json:"department,omitempty"`
+	Groups         []string   `json:"groups" gorm:"-"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	IPAddress      string     `json:"ip_address,omitempty"`
+}
+
+// AcknowledgementReport rapport de conformité d'un contenu à lecture obligatoire
+type AcknowledgementReport struct {
+	EntityType   string                      `json:"entity_type"`
+	EntityID     uint                        `json:"entity_id"`
+	Title        string                      `json:"title"`
+	DueAt        *time.Time                  `json:"due_at"`
+	Overdue      bool                        `json:"overdue"`
+	RemindedAt   *time.Time                  `json:"reminded_at"`
+	Audience     int                         `json:"audience"`
+	Acknowledged int                         `json:"acknowledged"`
+	Pending      int                         `json:"pending"`
+	Rate         float64                     `json:"rate"`
+	Groups       []AcknowledgementGroupStats `json:"groups"`
+	Users        []AcknowledgementReportUser `json:"users"`
+	Total        int                         `json:"total"` // Utilisateurs correspondant aux filtres (avant pagination)
+	Page         int                         `json:"page"`
+	PageSize     int                         `json:"page_size"`
+}
+
+// AcknowledgementReminderResult résultat d'une relance des utilisateurs n'ayant pas accusé lecture
+type AcknowledgementReminderResult struct {
+	Notified   int       `json:"notified"`
+	RemindedAt time.Time `json:"reminded_at"`
+}
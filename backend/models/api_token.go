@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Préfixe des jetons d'API (permet de les distinguer des JWT dans l'en-tête Authorization)
+const APITokenPrefix = "abt_"
+
+// APIToken jeton d'accès personnel ou de compte de service.
+// Les permissions effectives sont l'intersection des scopes du jeton et des permissions de son propriétaire.
+type APIToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"not null"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex;not null"` // SHA-256 du jeton
+	TokenPrefix string     `json:"token_prefix"`                  // Premiers caractères pour identification
+	UserID      uint       `json:"user_id" gorm:"not null;index"` // Propriétaire (utilisateur ou compte de service)
+	User        *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Scopes      string     `json:"scopes" gorm:"type:text"` // Permissions séparées par des virgules ("news.*" accepté)
+	CreatedByID uint       `json:"created_by_id"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ScopeList retourne les scopes du jeton
+func (t *APIToken) ScopeList() []string {
+	var scopes []string
+	for _, scope := range strings.Split(t.Scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// IsValid indique si le jeton n'est ni révoqué ni expiré
+func (t *APIToken) IsValid(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// APITokenRequest pour la création d'un jeton d'API
+type APITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=730"` // 0 = sans expiration (comptes de service uniquement)
+}
+
+// ServiceAccountRequest pour la création ou la mise à jour d'un compte de service
+type ServiceAccountRequest struct {
+	Username    string `json:"username" binding:"required,min=3,max=50"`
+	DisplayName string `json:"display_name" binding:"max=100"`
+	Role        string `json:"role" binding:"omitempty,oneof=admin editor user"`
+	GroupIDs    []uint `json:"group_ids"`
+	IsActive    *bool  `json:"is_active"`
+}
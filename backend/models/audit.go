@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Actions d'authentification enregistrées dans le journal d'audit
+const (
+	AuditActionLogin                = "auth.login"
+	AuditActionLoginFailed          = "auth.login_failed"
+	AuditActionLogout               = "auth.logout"
+	AuditActionTokenRefresh         = "auth.token_refresh"
+	AuditActionTokenRefreshFailed   = "auth.token_refresh_failed"
+	AuditActionPasswordChange       = "auth.password_change"
+	AuditActionPasswordChangeFailed = "auth.password_change_failed"
+	AuditActionAccountLocked        = "auth.account_locked"
+)
+
+// AuditLog entrée du journal d'audit (append-only : aucune mise à jour, suppression uniquement par la rétention)
+type AuditLog struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+	ActorID       *uint     `json:"actor_id" gorm:"index"` // Pas de clé étrangère : l'entrée survit à la suppression de l'utilisateur
+	ActorUsername string    `json:"actor_username"`
+	ActorRole     string    `json:"actor_role"`
+	Action        string    `json:"action" gorm:"not null;index"` // ex: admin.users.update, auth.login_failed
+	TargetType    string    `json:"target_type" gorm:"index"`     // ex: users, news, settings
+	TargetID      string    `json:"target_id" gorm:"index"`
+	TargetLabel   string    `json:"target_label"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	StatusCode    int       `json:"status_code"`
+	Success       bool      `json:"success" gorm:"index"`
+	Before        string    `json:"before,omitempty" gorm:"type:text"`  // État avant modification (champs sensibles masqués)
+	After         string    `json:"after,omitempty" gorm:"type:text"`   // État après modification ou données soumises
+	Changes       string    `json:"changes,omitempty" gorm:"type:text"` // Différence champ par champ {"champ": {"old": .., "new": ..}}
+	Details       string    `json:"details"`
+	IPAddress     string    `json:"ip_address" gorm:"index"`
+	UserAgent     string    `json:"user_agent"`
+
+	// Administrateur agissant en tant que l'acteur (usurpation d'identité)
+	ImpersonatorID *uint `json:"impersonator_id,omitempty" gorm:"index"`
+}
+
+// AuditLogFilter critères de recherche dans le journal d'audit
+type AuditLogFilter struct {
+	ActorID    *uint
+	Action     string // Préfixe accepté (ex: "admin.users")
+	TargetType string
+	TargetID   string
+	Success    *bool
+	IPAddress  string
+	Search     string // Recherche dans l'acteur, la cible et les détails
+	From       *time.Time
+	To         *time.Time
+}
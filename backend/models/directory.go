@@ -0,0 +1,146 @@
+package models
+
+import "time"
+
+// Visibilité d'un champ du profil dans l'annuaire
+const (
+	ProfileVisibilityPublic  = "public"  // Tous les utilisateurs connectés
+	ProfileVisibilityGroups  = "groups"  // Membres d'au moins un groupe commun
+	ProfileVisibilityPrivate = "private" // L'utilisateur lui-même et les administrateurs
+)
+
+// Champs du profil soumis aux réglages de confidentialité
+const (
+	ProfileFieldEmail      = "email"
+	ProfileFieldDepartment = "department"
+	ProfileFieldJobTitle   = "job_title"
+	ProfileFieldLocation   = "location"
+	ProfileFieldPhone      = "phone"
+	ProfileFieldAvatar     = "avatar"
+)
+
+// ProfileFields liste les champs réglables et leur visibilité par défaut
+var ProfileFields = map[string]string{
+	ProfileFieldEmail:      ProfileVisibilityPublic,
+	ProfileFieldDepartment: ProfileVisibilityPublic,
+	ProfileFieldJobTitle:   ProfileVisibilityPublic,
+	ProfileFieldLocation:   ProfileVisibilityPublic,
+	ProfileFieldPhone:      ProfileVisibilityGroups,
+	ProfileFieldAvatar:     ProfileVisibilityPublic,
+}
+
+// ProfilePrivacy réglages de confidentialité du profil d'un utilisateur dans l'annuaire.
+// Sans ligne enregistrée, les valeurs par défaut de ProfileFields s'appliquent et le profil est listé.
+type ProfilePrivacy struct {
+	ID                   uint      `json:"-" gorm:"primaryKey"`
+	UserID               uint      `json:"-" gorm:"uniqueIndex;not null"`
+	ListedInDirectory    bool      `json:"listed_in_directory" gorm:"not null"` // Apparaît dans l'annuaire et la recherche
+	EmailVisibility      string    `json:"email_visibility" gorm:"not null;default:'public'"`
+	DepartmentVisibility string    `json:"department_visibility" gorm:"not null;default:'public'"`
+	JobTitleVisibility   string    `json:"job_title_visibility" gorm:"not null;default:'public'"`
+	LocationVisibility   string    `json:"location_visibility" gorm:"not null;default:'public'"`
+	PhoneVisibility      string    `json:"phone_visibility" gorm:"not null;default:'groups'"`
+	AvatarVisibility     string    `json:"avatar_visibility" gorm:"not null;default:'public'"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// DefaultProfilePrivacy réglages appliqués à un utilisateur qui n'a rien configuré
+func DefaultProfilePrivacy(userID uint) ProfilePrivacy {
+	return ProfilePrivacy{
+		UserID:               userID,
+		ListedInDirectory:    true,
+		EmailVisibility:      ProfileFields[ProfileFieldEmail],
+		DepartmentVisibility: ProfileFields[ProfileFieldDepartment],
+		JobTitleVisibility:   ProfileFields[ProfileFieldJobTitle],
+		LocationVisibility:   ProfileFields[ProfileFieldLocation],
+		PhoneVisibility:      ProfileFields[ProfileFieldPhone],
+		AvatarVisibility:     ProfileFields[ProfileFieldAvatar],
+	}
+}
+
+// Visibility retourne la visibilité d'un champ du profil
+func (p ProfilePrivacy) Visibility(field string) string {
+	switch field {
+	case ProfileFieldEmail:
+		return p.EmailVisibility
+	case ProfileFieldDepartment:
+		return p.DepartmentVisibility
+	case ProfileFieldJobTitle:
+		return p.JobTitleVisibility
+	case ProfileFieldLocation:
+		return p.LocationVisibility
+	case ProfileFieldPhone:
+		return p.PhoneVisibility
+	case ProfileFieldAvatar:
+		return p.AvatarVisibility
+	}
+	return ProfileVisibilityPrivate
+}
+
+// IsValidProfileVisibility vérifie une valeur de visibilité
+func IsValidProfileVisibility(visibility string) bool {
+	return visibility == ProfileVisibilityPublic || visibility == ProfileVisibilityGroups || visibility == ProfileVisibilityPrivate
+}
+
+// UpdateProfilePrivacyRequest modification des réglages de confidentialité (champs omis inchangés)
+type UpdateProfilePrivacyRequest struct {
+	ListedInDirectory    *bool  `json:"listed_in_directory"`
+	EmailVisibility      string `json:"email_visibility"`
+	DepartmentVisibility string `json:"department_visibility"`
+	JobTitleVisibility   string `json:"job_title_visibility"`
+	LocationVisibility   string `json:"location_visibility"`
+	PhoneVisibility      string `json:"phone_visibility"`
+	AvatarVisibility     string `json:"avatar_visibility"`
+}
+
+// DirectoryGroup groupe affiché sur une fiche de l'annuaire
+type DirectoryGroup struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// DirectoryPerson fiche de l'annuaire : les champs masqués par les réglages de confidentialité sont vides
+type DirectoryPerson struct {
+	ID         uint             `json:"id"`
+	Username   string           `json:"username"`
+	FirstName  string           `json:"first_name"`
+	LastName   string           `json:"last_name"`
+	Email      string           `json:"email,omitempty"`
+	Department string           `json:"department,omitempty"`
+	JobTitle   string           `json:"job_title,omitempty"`
+	Location   string           `json:"location,omitempty"`
+	Phone      string           `json:"phone,omitempty"`
+	AvatarURL  string           `json:"avatar_url,omitempty"`
+	Groups     []DirectoryGroup `json:"groups"`
+}
+
+// DirectoryFacet nombre de personnes par valeur d'un filtre
+type DirectoryFacet struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// DirectoryFacets compteurs des filtres, calculés avec les autres filtres appliqués
+type DirectoryFacets struct {
+	Departments []DirectoryFacet `json:"departments"`
+	Locations   []DirectoryFacet `json:"locations"`
+	Groups      []DirectoryFacet `json:"groups"`
+}
+
+// DirectoryResponse page de l'annuaire
+type DirectoryResponse struct {
+	People     []DirectoryPerson `json:"people"`
+	Facets     DirectoryFacets   `json:"facets"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	TotalPages int               `json:"total_pages"`
+}
+
+// DirectoryProfile fiche publique d'une personne ; Privacy n'est renseigné que pour l'utilisateur lui-même
+type DirectoryProfile struct {
+	DirectoryPerson
+	Privacy *ProfilePrivacy `json:"privacy,omitempty"`
+}
@@ -128,6 +128,9 @@ type EmailOAuthTestRequest struct {
 	ToEmail string `json:"to_email" binding:"required,email"`
 }
 
+// EmailTemplateAckReminder type du template de relance des lectures obligatoires
+const EmailTemplateAckReminder = "acknowledgement_reminder"
+
 // GetDefaultEmailTemplates retourne les templates par défaut
 func GetDefaultEmailTemplates() []EmailTemplate {
 	return []EmailTemplate{
@@ -149,6 +152,10 @@ body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helv
 .content { padding: 30px; }
 .content h2 { color: #1f2937; margin-top: 0; font-size: 22px; }
 .summary { background: #f8fafc; border-left: 4px solid #3B82F6; padding: 15px; margin: 20px 0; border-radius: 0 8px 8px 0; }
+.article { color: #374151; margin: 20px 0; }
+.article img { max-width: 100%; height: auto; }
+.article pre { background: #f3f4f6; padding: 12px; border-radius: 8px; overflow-x: auto; }
+.article blockquote { border-left: 4px solid #e5e7eb; margin: 0; padding-left: 15px; color: #6b7280; }
 .meta { color: #6b7280; font-size: 14px; margin: 15px 0; }
 .meta span { margin-right: 20px; }
 .button { display: inline-block; padding: 12px 24px; background: #3B82F6; color: white; text-decoration: none; border-radius: 8px; font-weight: 500; margin-top: 20px; }
@@ -167,7 +174,9 @@ body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helv
 <div class="meta">
 <span>Par <strong>{{.Author}}</strong></span>
 <span>Publié le {{.PublishedAt}}</span>
+{{if .ReadingTime}}<span>{{.ReadingTime}} min de lecture</span>{{end}}
 </div>
+{{if .Content}}<div class="article">{{.Content}}</div>{{end}}
 <a href="{{.Link}}" class="button">Lire l'article</a>
 </div>
 <div class="footer">
@@ -249,6 +258,7 @@ body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helv
 .event-details .label { color: #92400e; font-weight: 500; }
 .event-details .value { color: #78350f; margin-left: 5px; }
 .description { color: #4b5563; margin: 20px 0; }
+.description img { max-width: 100%; height: auto; }
 .button { display: inline-block; padding: 12px 24px; background: #F59E0B; color: white; text-decoration: none; border-radius: 8px; font-weight: 500; margin-top: 20px; }
 .button:hover { background: #D97706; }
 .footer { background: #f8fafc; padding: 20px; text-align: center; color: #6b7280; font-size: 12px; }
@@ -279,7 +289,7 @@ body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helv
 </div>
 {{end}}
 </div>
-<p class="description">{{.Description}}</p>
+{{if .DescriptionHTML}}<div class="description">{{.DescriptionHTML}}</div>{{end}}
 <a href="{{.Link}}" class="button">Voir les détails</a>
 </div>
 <div class="footer">
@@ -340,6 +350,51 @@ body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helv
 </div>
 </div>
 </body>
+</html>`,
+		},
+		{
+			Type:      EmailTemplateAckReminder,
+			Name:      "Relance Lecture Obligatoire",
+			Subject:   "{{.AppName}} - Lecture obligatoire : {{.Title}}",
+			IsEnabled: true,
+			HTMLBody: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; margin: 0; padding: 0; background-color: #f5f5f5; }
+.container { max-width: 600px; margin: 0 auto; background: white; }
+.header { background: linear-gradient(135deg, #F59E0B 0%, #D97706 100%); color: white; padding: 30px; text-align: center; }
+.header h1 { margin: 0; font-size: 24px; font-weight: 600; }
+.content { padding: 30px; }
+.content h2 { color: #1f2937; margin-top: 0; font-size: 22px; }
+.due { background: #fffbeb; border-left: 4px solid #F59E0B; padding: 15px; margin: 20px 0; border-radius: 0 8px 8px 0; }
+.button { display: inline-block; padding: 12px 24px; background: #F59E0B; color: white; text-decoration: none; border-radius: 8px; font-weight: 500; margin-top: 20px; }
+.button:hover { background: #D97706; }
+.footer { background: #f8fafc; padding: 20px; text-align: center; color: #6b7280; font-size: 12px; }
+</style>
+</head>
+<body>
+<div class="container">
+<div class="header">
+<h1>{{.AppName}}</h1>
+</div>
+<div class="content">
+<p>Bonjour {{.Name}},</p>
+<h2>{{.Title}}</h2>
+<div class="due">
+La lecture de ce contenu est obligatoire{{if .DueAt}}{{if .Overdue}} et était attendue avant le {{.DueAt}}{{else}} et est attendue avant le {{.DueAt}}{{end}}{{end}}.
+</div>
+<p>Merci de le consulter et de confirmer que vous l'avez lu et compris.</p>
+<a href="{{.Link}}" class="button">Lire et confirmer la lecture</a>
+</div>
+<div class="footer">
+<p>Vous recevez cet email car vous n'avez pas encore confirmé la lecture de ce contenu.</p>
+<p>© {{.AppName}}</p>
+</div>
+</div>
+</body>
 </html>`,
 		},
 	}
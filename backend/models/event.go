@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"time"
 
+	"airboard/utils"
+
 	"gorm.io/gorm"
 )
 
@@ -14,6 +16,7 @@ type Event struct {
 	Slug        string `json:"slug" gorm:"size:255;not null;uniqueIndex:idx_event_slug,where:deleted_at IS NULL"`
 	Title       string `json:"title" gorm:"not null;size:255"`
 	Description string `json:"description" gorm:"type:text"` // Contenu riche (JSON Tiptap)
+	SearchText  string `json:"-" gorm:"type:text"`           // Description en texte brut indexée par la recherche plein texte
 
 	// Dates & Times
 	StartDate time.Time  `json:"start_date" gorm:"not null;index:idx_events_date_range"`
@@ -164,8 +167,10 @@ type EventStatsResponse struct {
 	EventsByPriority map[string]int64 `json:"events_by_priority"`
 }
 
-// BeforeSave hook pour générer le slug automatiquement
+// BeforeSave hook pour générer le slug et extraire le texte indexé
 func (e *Event) BeforeSave(tx *gorm.DB) error {
+	e.SearchText = utils.TiptapToText(e.Description)
+
 	if e.Slug == "" {
 		baseSlug := generateSlug(e.Title)
 		e.Slug = e.generateUniqueSlug(tx, baseSlug)
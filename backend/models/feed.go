@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Préfixe des jetons de flux RSS/Atom (transmis dans l'URL du flux)
+const FeedTokenPrefix = "abf_"
+
+// FeedToken jeton personnel d'abonnement aux flux RSS/Atom.
+// Il n'ouvre que la lecture des flux, avec la visibilité de son propriétaire ; un seul jeton actif par utilisateur.
+type FeedToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex;not null"` // SHA-256 du jeton
+	TokenPrefix string     `json:"token_prefix"`                  // Premiers caractères pour identification
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// FeedTokenInfo état du jeton de flux de l'utilisateur ; le jeton en clair et les URL ne sont renseignés qu'à la création
+type FeedTokenInfo struct {
+	Active   bool       `json:"active"`
+	Token    *FeedToken `json:"token,omitempty"`
+	Plain    string     `json:"plain_token,omitempty"`
+	AtomURL  string     `json:"atom_url,omitempty"`
+	RSSURL   string     `json:"rss_url,omitempty"`
+	Filters  []string   `json:"filters"` // Paramètres acceptés par les flux
+	MaxItems int        `json:"max_items"`
+}
+
+// NewsFeedFilter filtres d'un flux de news
+type NewsFeedFilter struct {
+	Category string // Identifiant ou slug de catégorie
+	Tags     []string
+	Type     string
+	Limit    int
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Durées des sessions d'usurpation d'identité
+const (
+	ImpersonationDefaultMinutes = 30
+	ImpersonationMaxMinutes     = 120
+)
+
+// Action d'audit enregistrée pour chaque requête effectuée sous une identité usurpée
+const (
+	AuditActionImpersonationStart   = "auth.impersonation_start"
+	AuditActionImpersonationStop    = "auth.impersonation_stop"
+	AuditActionImpersonatedRequest  = "auth.impersonated_request"
+	AuditActionImpersonationBlocked = "auth.impersonation_blocked"
+)
+
+// ImpersonationSession session d'usurpation d'identité ouverte par un administrateur (support).
+// Le jeton émis porte l'ID de la session et celui de l'administrateur ; il n'est valable que tant que
+// la session n'est ni terminée ni expirée.
+type ImpersonationSession struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	ImpersonatorID uint       `json:"impersonator_id" gorm:"not null;index"`
+	Impersonator   *User      `json:"impersonator,omitempty" gorm:"foreignKey:ImpersonatorID"`
+	TargetUserID   uint       `json:"target_user_id" gorm:"not null;index"`
+	TargetUser     *User      `json:"target_user,omitempty" gorm:"foreignKey:TargetUserID"`
+	Reason         string     `json:"reason" gorm:"type:text;not null"`
+	IPAddress      string     `json:"ip_address"`
+	UserAgent      string     `json:"user_agent"`
+	StartedAt      time.Time  `json:"started_at"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"index"`
+	EndedAt        *time.Time `json:"ended_at"`
+	EndedByID      *uint      `json:"ended_by_id"` // Administrateur ayant mis fin à la session (nil si arrêtée par l'usurpateur ou expirée)
+	RequestCount   int        `json:"request_count" gorm:"not null;default:0"`
+	LastRequestAt  *time.Time `json:"last_request_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// IsActive indique si la session permet encore d'effectuer des requêtes
+func (s *ImpersonationSession) IsActive() bool {
+	return s.EndedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// ImpersonationRequest pour démarrer une session d'usurpation
+type ImpersonationRequest struct {
+	Reason          string `json:"reason" binding:"required,min=5,max=500"`
+	DurationMinutes int    `json:"duration_minutes" binding:"omitempty,min=5,max=120"`
+}
+
+// ImpersonationInfo indicateur exposé dans le profil pendant une usurpation
+type ImpersonationInfo struct {
+	SessionID            uint      `json:"session_id"`
+	ImpersonatorID       uint      `json:"impersonator_id"`
+	ImpersonatorUsername string    `json:"impersonator_username"`
+	ExpiresAt            time.Time `json:"expires_at"`
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+)
+
+// LDAPConfig stocke la configuration de l'annuaire LDAP / Active Directory (une seule ligne)
+type LDAPConfig struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	IsEnabled     bool   `json:"is_enabled" gorm:"default:false"`
+	Host          string `json:"host"`
+	Port          int    `json:"port" gorm:"default:389"`
+	UseTLS        bool   `json:"use_tls" gorm:"default:false"`         // ldaps://
+	UseStartTLS   bool   `json:"use_starttls" gorm:"default:false"`    // StartTLS sur ldap://
+	SkipTLSVerify bool   `json:"skip_tls_verify" gorm:"default:false"` // Certificats auto-signés (déconseillé)
+	BindDN        string `json:"bind_dn"`                              // Compte de service pour la recherche et la synchronisation
+	BindPassword  string `json:"-" gorm:"type:text"`                   // Chiffré (AES-256)
+	BaseDN        string `json:"base_dn"`
+
+	// Filtres - {username} est remplacé par l'identifiant saisi (échappé)
+	UserFilter string `json:"user_filter" gorm:"default:'(&(objectClass=person)(|(sAMAccountName={username})(uid={username})(mail={username})))'"`
+	SyncFilter string `json:"sync_filter" gorm:"default:'(&(objectClass=person)(mail=*))'"`
+
+	// Mapping des attributs vers models.User
+	UsernameAttribute   string `json:"username_attribute" gorm:"default:'sAMAccountName'"`
+	EmailAttribute      string `json:"email_attribute" gorm:"default:'mail'"`
+	FirstNameAttribute  string `json:"first_name_attribute" gorm:"default:'givenName'"`
+	LastNameAttribute   string `json:"last_name_attribute" gorm:"default:'sn'"`
+	DepartmentAttribute string `json:"department_attribute" gorm:"default:'department'"`
+	JobTitleAttribute   string `json:"job_title_attribute" gorm:"default:'title'"`
+	PhoneAttribute      string `json:"phone_attribute" gorm:"default:'telephoneNumber'"`
+	LocationAttribute   string `json:"location_attribute" gorm:"default:'l'"`
+	GroupAttribute      string `json:"group_attribute" gorm:"default:'memberOf'"`  // Attribut listant les DN des groupes
+	ManagerAttribute    string `json:"manager_attribute" gorm:"default:'manager'"` // DN du responsable hiérarchique (organigramme)
+	UniqueIDAttribute   string `json:"unique_id_attribute" gorm:"default:'objectGUID'"`
+
+	// Rôles
+	AdminGroups string `json:"admin_groups"`                       // CN ou DN des groupes admin (séparés par des virgules)
+	DefaultRole string `json:"default_role" gorm:"default:'user'"` // Rôle par défaut
+
+	// Synchronisation planifiée
+	SyncEnabled         bool       `json:"sync_enabled" gorm:"default:false"`
+	SyncIntervalMinutes int        `json:"sync_interval_minutes" gorm:"default:60"`
+	DeactivateMissing   bool       `json:"deactivate_missing" gorm:"default:true"` // Désactiver les utilisateurs retirés de l'annuaire
+	LastSyncAt          *time.Time `json:"last_sync_at"`
+	LastSyncSuccess     bool       `json:"last_sync_success"`
+	LastSyncMessage     string     `json:"last_sync_message" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	GroupMappings []LDAPGroupMapping `json:"group_mappings,omitempty" gorm:"foreignKey:LDAPConfigID"`
+}
+
+// LDAPGroupMapping associe un groupe de l'annuaire à un groupe Airboard
+type LDAPGroupMapping struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	LDAPConfigID uint      `json:"ldap_config_id" gorm:"not null;index"`
+	LDAPGroup    string    `json:"ldap_group" gorm:"not null"` // DN ou CN, caractères génériques * acceptés (ex: "GG-Airboard-*")
+	GroupID      uint      `json:"group_id" gorm:"not null"`
+	Group        Group     `json:"group,omitempty" gorm:"foreignKey:GroupID"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// LDAPConfigRequest pour la mise à jour de la configuration LDAP
+type LDAPConfigRequest struct {
+	IsEnabled           bool   `json:"is_enabled"`
+	Host                string `json:"host" binding:"required_if=IsEnabled true"`
+	Port                int    `json:"port" binding:"omitempty,min=1,max=65535"`
+	UseTLS              bool   `json:"use_tls"`
+	UseStartTLS         bool   `json:"use_starttls"`
+	SkipTLSVerify       bool   `json:"skip_tls_verify"`
+	BindDN              string `json:"bind_dn"`
+	BindPassword        string `json:"bind_password"` // Vide = conserver le mot de passe actuel
+	BaseDN              string `json:"base_dn" binding:"required_if=IsEnabled true"`
+	UserFilter          string `json:"user_filter"`
+	SyncFilter          string `json:"sync_filter"`
+	UsernameAttribute   string `json:"username_attribute"`
+	EmailAttribute      string `json:"email_attribute"`
+	FirstNameAttribute  string `json:"first_name_attribute"`
+	LastNameAttribute   string `json:"last_name_attribute"`
+	DepartmentAttribute string `json:"department_attribute"`
+	JobTitleAttribute   string `json:"job_title_attribute"`
+	PhoneAttribute      string `json:"phone_attribute"`
+	LocationAttribute   string `json:"location_attribute"`
+	GroupAttribute      string `json:"group_attribute"`
+	ManagerAttribute    string `json:"manager_attribute"`
+	UniqueIDAttribute   string `json:"unique_id_attribute"`
+	AdminGroups         string `json:"admin_groups"`
+	DefaultRole         string `json:"default_role" binding:"omitempty,oneof=user editor"`
+	SyncEnabled         bool   `json:"sync_enabled"`
+	SyncIntervalMinutes int    `json:"sync_interval_minutes" binding:"omitempty,min=5"`
+	DeactivateMissing   *bool  `json:"deactivate_missing"`
+
+	GroupMappings []LDAPGroupMappingRequest `json:"group_mappings"`
+}
+
+// LDAPGroupMappingRequest pour une règle de mapping de groupe
+type LDAPGroupMappingRequest struct {
+	LDAPGroup string `json:"ldap_group" binding:"required"`
+	GroupID   uint   `json:"group_id" binding:"required"`
+}
+
+// LDAPTestRequest pour tester la connexion (et optionnellement l'authentification d'un utilisateur)
+type LDAPTestRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LDAPSyncResult résume une synchronisation de l'annuaire
+type LDAPSyncResult struct {
+	Created     int      `json:"created"`
+	Updated     int      `json:"updated"`
+	Deactivated int      `json:"deactivated"`
+	Skipped     int      `json:"skipped"`
+	Managers    int      `json:"managers"` // Rattachements hiérarchiques modifiés
+	Errors      []string `json:"errors,omitempty"`
+	DurationMs  int64    `json:"duration_ms"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Portées de verrouillage des connexions
+const (
+	LockoutScopeIP      = "ip"      // Adresse IP source (toutes identités confondues)
+	LockoutScopeAccount = "account" // Compte visé (toutes adresses confondues)
+)
+
+// LoginLockout compteur de tentatives de connexion échouées et verrouillage, persisté pour survivre
+// aux redémarrages et s'appliquer à toutes les instances
+type LoginLockout struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Scope         string     `json:"scope" gorm:"not null;uniqueIndex:idx_login_lockout_key"` // ip ou account
+	Key           string     `json:"key" gorm:"not null;uniqueIndex:idx_login_lockout_key"`   // Adresse IP, user:<id> ou name:<identifiant saisi>
+	UserID        *uint      `json:"user_id" gorm:"index"`                                    // Compte visé s'il existe
+	User          *User      `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Identifier    string     `json:"identifier"` // Dernier identifiant saisi
+	LastIP        string     `json:"last_ip"`
+	FailedCount   int        `json:"failed_count" gorm:"not null;default:0"` // Échecs dans la fenêtre courante
+	FirstFailedAt time.Time  `json:"first_failed_at"`
+	LastFailedAt  time.Time  `json:"last_failed_at" gorm:"index"`
+	LockedUntil   *time.Time `json:"locked_until" gorm:"index"`
+	LockCount     int        `json:"lock_count" gorm:"not null;default:0"` // Nombre total de verrouillages
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Champ calculé (non stocké en base)
+	IsLocked bool `json:"is_locked" gorm:"-"`
+}
+
+// LoginDevice appareil (navigateur) et adresse IP depuis lesquels un utilisateur s'est connecté
+type LoginDevice struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_login_device"`
+	User        *User     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Fingerprint string    `json:"fingerprint" gorm:"not null;uniqueIndex:idx_login_device"` // SHA-256 du User-Agent
+	IPAddress   string    `json:"ip_address" gorm:"not null;uniqueIndex:idx_login_device"`
+	UserAgent   string    `json:"user_agent"`
+	LoginCount  int       `json:"login_count" gorm:"not null;default:1"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// LoginLockoutStats synthèse des verrouillages
+type LoginLockoutStats struct {
+	Tracked        int64 `json:"tracked"`         // Compteurs en cours
+	LockedAccounts int64 `json:"locked_accounts"` // Comptes actuellement verrouillés
+	LockedIPs      int64 `json:"locked_ips"`      // Adresses IP actuellement verrouillées
+	FailedAttempts int64 `json:"failed_attempts"` // Échecs dans les fenêtres courantes
+}
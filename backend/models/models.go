@@ -9,25 +9,29 @@ import (
 
 // User représente un utilisateur du système
 type User struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Username    string         `json:"username" gorm:"unique;not null"`
-	Email       string         `json:"email" gorm:"unique;not null"`
-	Password    string         `json:"-"` // Nullable pour les users SSO
-	FirstName   string         `json:"first_name"`
-	LastName    string         `json:"last_name"`
-	Role        string         `json:"role" gorm:"default:'user'"` // admin, editor, user
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	SSOProvider string         `json:"sso_provider,omitempty"` // authentik, azure, etc.
-	SSOID       string         `json:"sso_id,omitempty"`       // ID utilisateur externe
-	LastLogin   *time.Time     `json:"last_login"`             // Dernière connexion
-	AvatarURL   string         `json:"avatar_url,omitempty"`   // URL de l'avatar (stocké localement ou externe)
-	Phone       string         `json:"phone,omitempty"`        // Numéro de téléphone
-	Department  string         `json:"department,omitempty"`   // Département
-	JobTitle    string         `json:"job_title,omitempty"`    // Titre du poste
-	Location    string         `json:"location,omitempty"`     // Localisation
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Username          string         `json:"username" gorm:"unique;not null"`
+	Email             string         `json:"email" gorm:"unique;not null"`
+	Password          string         `json:"-"` // Nullable pour les users SSO
+	FirstName         string         `json:"first_name"`
+	LastName          string         `json:"last_name"`
+	Role              string         `json:"role" gorm:"default:'user'"` // admin, editor, user
+	IsActive          bool           `json:"is_active" gorm:"default:true"`
+	SSOProvider       string         `json:"sso_provider,omitempty"`                        // authentik, azure, etc.
+	SSOID             string         `json:"sso_id,omitempty"`                              // ID utilisateur externe
+	LastLogin         *time.Time     `json:"last_login"`                                    // Dernière connexion
+	PasswordChangedAt *time.Time     `json:"password_changed_at,omitempty"`                 // Dernier changement de mot de passe (expiration)
+	AvatarURL         string         `json:"avatar_url,omitempty"`                          // URL de l'avatar (stocké localement ou externe)
+	Phone             string         `json:"phone,omitempty"`                               // Numéro de téléphone
+	Department        string         `json:"department,omitempty"`                          // Département
+	JobTitle          string         `json:"job_title,omitempty"`                           // Titre du poste
+	Location          string         `json:"location,omitempty"`                            // Localisation
+	IsServiceAccount  bool           `json:"is_service_account" gorm:"default:false;index"` // Compte technique (authentification par jeton d'API uniquement)
+	ManagerID         *uint          `json:"manager_id" gorm:"index"`                       // Responsable hiérarchique direct (organigramme)
+	ManagerSource     string         `json:"manager_source,omitempty"`                      // Origine du rattachement : admin, csv, ldap, scim, saml, oidc
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations
 	Groups        []Group       `json:"groups,omitempty" gorm:"many2many:user_groups;"`
@@ -35,7 +39,9 @@ type User struct {
 	AdminOfGroups []Group       `json:"admin_of_groups,omitempty" gorm:"many2many:group_admins;"` // Groupes administrés (utilisateurs qui peuvent gérer ces groupes)
 
 	// Champ calculé (non stocké en base)
-	ManagedGroupIDs []uint `json:"managed_group_ids,omitempty" gorm:"-"` // IDs des groupes administrés (chargés depuis group_admins)
+	ManagedGroupIDs []uint             `json:"managed_group_ids,omitempty" gorm:"-"` // IDs des groupes administrés (chargés depuis group_admins)
+	PasswordExpired bool               `json:"password_expired,omitempty" gorm:"-"`  // Mot de passe à renouveler (durée de validité dépassée)
+	Impersonation   *ImpersonationInfo `json:"impersonation,omitempty" gorm:"-"`     // Session d'usurpation en cours (profil uniquement)
 }
 
 // Group représente un groupe d'utilisateurs
@@ -45,6 +51,8 @@ type Group struct {
 	Description string         `json:"description"`
 	Color       string         `json:"color" gorm:"default:'#3B82F6'"` // Couleur pour l'affichage
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	ExternalID  string         `json:"external_id,omitempty" gorm:"index"`    // Identifiant du fournisseur d'identité (SCIM)
+	Source      string         `json:"source,omitempty" gorm:"size:20;index"` // Origine : vide (créé dans Airboard) ou "scim"
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -102,6 +110,12 @@ type Claims struct {
 	Role            string `json:"role"`
 	Email           string `json:"email"`
 	ManagedGroupIDs []uint `json:"managed_group_ids,omitempty"` // IDs des groupes administrés (chargés depuis group_admins)
+	SessionID       string `json:"sid,omitempty"`               // Session de connexion (conservée lors du rafraîchissement des tokens)
+
+	// Usurpation d'identité (jeton émis pour un administrateur agissant en tant que l'utilisateur)
+	ImpersonationID      uint   `json:"impersonation_id,omitempty"`
+	ImpersonatorID       uint   `json:"impersonator_id,omitempty"`
+	ImpersonatorUsername string `json:"impersonator_username,omitempty"`
 }
 
 // Request/Response structures
@@ -205,33 +219,60 @@ func (m *Media) MediaType() string {
 
 // AppSettings représente les paramètres de configuration de l'application
 type AppSettings struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`
-	AppName         string    `json:"app_name" gorm:"default:'Airboard'"`
-	AppIcon         string    `json:"app_icon" gorm:"default:'mdi:view-dashboard'"`
-	DashboardTitle  string    `json:"dashboard_title" gorm:"default:'Dashboard'"`
-	WelcomeMessage  string    `json:"welcome_message" gorm:"default:'Welcome to your application portal'"`     // Message pour la page Dashboard
-	HomePageMessage string    `json:"home_page_message" gorm:"default:'Discover your personalized workspace'"` // Message pour la page d'accueil
-	SignupEnabled   bool      `json:"signup_enabled" gorm:"default:true"`                                      // Activer/désactiver l'inscription
-	DefaultGroupID  *uint     `json:"default_group_id" gorm:"default:null"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	AppName            string    `json:"app_name" gorm:"default:'Airboard'"`
+	AppIcon            string    `json:"app_icon" gorm:"default:'mdi:view-dashboard'"`
+	DashboardTitle     string    `json:"dashboard_title" gorm:"default:'Dashboard'"`
+	WelcomeMessage     string    `json:"welcome_message" gorm:"default:'Welcome to your application portal'"`     // Message pour la page Dashboard
+	HomePageMessage    string    `json:"home_page_message" gorm:"default:'Discover your personalized workspace'"` // Message pour la page d'accueil
+	SignupEnabled      bool      `json:"signup_enabled" gorm:"default:true"`                                      // Activer/désactiver l'inscription
+	AuditRetentionDays int       `json:"audit_retention_days" gorm:"default:365"`                                 // Conservation du journal d'audit en jours (0 = illimitée)
+	DefaultGroupID     *uint     `json:"default_group_id" gorm:"default:null"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+
+	// Politique de mots de passe (comptes locaux)
+	PasswordMinLength      int  `json:"password_min_length" gorm:"default:8"`
+	PasswordRequireUpper   bool `json:"password_require_upper" gorm:"default:true"`
+	PasswordRequireLower   bool `json:"password_require_lower" gorm:"default:true"`
+	PasswordRequireDigit   bool `json:"password_require_digit" gorm:"default:true"`
+	PasswordRequireSpecial bool `json:"password_require_special" gorm:"default:true"`
+	PasswordMaxAgeDays     int  `json:"password_max_age_days" gorm:"default:0"`      // Durée de validité en jours (0 = illimitée)
+	PasswordHistoryDepth   int  `json:"password_history_depth" gorm:"default:5"`     // Nombre d'anciens mots de passe interdits (0 = aucun)
+	PasswordCheckBreached  bool `json:"password_check_breached" gorm:"default:true"` // Refuser les mots de passe présents dans la liste de fuites
+
+	// Workflow éditorial : les articles des rédacteurs sans droit de publication global (admins de groupe)
+	// doivent être approuvés par un éditeur avant publication
+	NewsApprovalRequired bool `json:"news_approval_required" gorm:"default:true"`
 }
 
 // AppSettingsRequest pour les requêtes de mise à jour
 type AppSettingsRequest struct {
-	AppName         string `json:"app_name" binding:"required,min=1"`
-	AppIcon         string `json:"app_icon" binding:"required"`
-	DashboardTitle  string `json:"dashboard_title" binding:"required,min=1"`
-	WelcomeMessage  string `json:"welcome_message" binding:"required,min=1"` // Message pour Dashboard
-	HomePageMessage string `json:"home_page_message"`                        // Message pour page d'accueil (optionnel)
-	SignupEnabled   *bool  `json:"signup_enabled"`                           // Activer/désactiver l'inscription
-	DefaultGroupID  *uint  `json:"default_group_id"`
+	AppName            string `json:"app_name" binding:"required,min=1"`
+	AppIcon            string `json:"app_icon" binding:"required"`
+	DashboardTitle     string `json:"dashboard_title" binding:"required,min=1"`
+	WelcomeMessage     string `json:"welcome_message" binding:"required,min=1"` // Message pour Dashboard
+	HomePageMessage    string `json:"home_page_message"`                        // Message pour page d'accueil (optionnel)
+	SignupEnabled      *bool  `json:"signup_enabled"`                           // Activer/désactiver l'inscription
+	AuditRetentionDays *int   `json:"audit_retention_days" binding:"omitempty,min=0,max=3650"`
+	DefaultGroupID     *uint  `json:"default_group_id"`
+
+	PasswordMinLength      *int  `json:"password_min_length" binding:"omitempty,min=8,max=128"`
+	PasswordRequireUpper   *bool `json:"password_require_upper"`
+	PasswordRequireLower   *bool `json:"password_require_lower"`
+	PasswordRequireDigit   *bool `json:"password_require_digit"`
+	PasswordRequireSpecial *bool `json:"password_require_special"`
+	PasswordMaxAgeDays     *int  `json:"password_max_age_days" binding:"omitempty,min=0,max=3650"`
+	PasswordHistoryDepth   *int  `json:"password_history_depth" binding:"omitempty,min=0,max=24"`
+	PasswordCheckBreached  *bool `json:"password_check_breached"`
+
+	NewsApprovalRequired *bool `json:"news_approval_required"`
 }
 
 // ChangePasswordRequest pour les changements de mot de passe
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=128"`
 }
 
 // UpdateProfileRequest pour les mises à jour du profil utilisateur
@@ -246,20 +287,37 @@ type UpdateProfileRequest struct {
 
 // OAuthProvider représente un fournisseur OAuth (Google, Microsoft, etc.)
 type OAuthProvider struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	ProviderName string    `json:"provider_name" gorm:"unique;not null"` // google, microsoft
-	DisplayName  string    `json:"display_name" gorm:"not null"`         // "Google", "Microsoft"
-	Icon         string    `json:"icon" gorm:"default:'mdi:login'"`      // Icône Iconify
-	IsEnabled    bool      `json:"is_enabled" gorm:"default:false"`
-	ClientID     string    `json:"client_id"`
-	ClientSecret string    `json:"-"` // Ne jamais exposer dans le JSON
-	RedirectURI  string    `json:"redirect_uri"`
-	AuthURL      string    `json:"auth_url"`      // URL d'autorisation OAuth
-	TokenURL     string    `json:"token_url"`     // URL d'échange de token
-	UserInfoURL  string    `json:"user_info_url"` // URL pour récupérer les infos utilisateur
-	Scopes       string    `json:"scopes"`        // Scopes OAuth séparés par des espaces
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	ProviderName string `json:"provider_name" gorm:"unique;not null"` // google, microsoft
+	DisplayName  string `json:"display_name" gorm:"not null"`         // "Google", "Microsoft"
+	Icon         string `json:"icon" gorm:"default:'mdi:login'"`      // Icône Iconify
+	IsEnabled    bool   `json:"is_enabled" gorm:"default:false"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"-"` // Ne jamais exposer dans le JSON
+	RedirectURI  string `json:"redirect_uri"`
+	AuthURL      string `json:"auth_url"`      // URL d'autorisation OAuth
+	TokenURL     string `json:"token_url"`     // URL d'échange de token
+	UserInfoURL  string `json:"user_info_url"` // URL pour récupérer les infos utilisateur
+	Scopes       string `json:"scopes"`        // Scopes OAuth séparés par des espaces
+
+	// OpenID Connect (ProviderType = "oidc")
+	ProviderType string `json:"provider_type" gorm:"default:'oauth2'"` // oauth2, oidc
+	IssuerURL    string `json:"issuer_url"`                            // Issuer OIDC (découverte via /.well-known/openid-configuration)
+	UsePKCE      bool   `json:"use_pkce" gorm:"default:true"`          // PKCE (S256) lors de l'autorisation
+	EmailClaim   string `json:"email_claim" gorm:"default:'email'"`    // Claim contenant l'email
+	NameClaim    string `json:"name_claim" gorm:"default:'name'"`      // Claim contenant le nom complet
+	GroupsClaim  string `json:"groups_claim"`                          // Claim contenant les groupes (vide = pas de synchronisation)
+	ManagerClaim string `json:"manager_claim"`                         // Claim contenant le responsable (email, identifiant ou sub ; vide = pas de synchronisation)
+	AdminGroups  string `json:"admin_groups"`                          // Groupes donnant le rôle admin (séparés par des virgules)
+	TrustEmail   bool   `json:"trust_email" gorm:"default:false"`      // Emails fiables sans claim email_verified (rattachement aux comptes existants)
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsOIDC indique si le fournisseur est un fournisseur OpenID Connect générique
+func (p *OAuthProvider) IsOIDC() bool {
+	return p.ProviderType == "oidc"
 }
 
 // OAuthProviderRequest pour les requêtes de mise à jour
@@ -275,6 +333,15 @@ type OAuthProviderRequest struct {
 	TokenURL     string `json:"token_url"`
 	UserInfoURL  string `json:"user_info_url"`
 	Scopes       string `json:"scopes"`
+	ProviderType string `json:"provider_type" binding:"omitempty,oneof=oauth2 oidc"`
+	IssuerURL    string `json:"issuer_url"`
+	UsePKCE      *bool  `json:"use_pkce"`
+	EmailClaim   string `json:"email_claim"`
+	NameClaim    string `json:"name_claim"`
+	GroupsClaim  string `json:"groups_claim"`
+	ManagerClaim string `json:"manager_claim"`
+	AdminGroups  string `json:"admin_groups"`
+	TrustEmail   bool   `json:"trust_email"`
 }
 
 // OAuthProviderPublic pour l'affichage public (sans secrets)
@@ -357,6 +424,14 @@ type Announcement struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Lecture obligatoire : accusé de lecture explicite demandé à tous les utilisateurs actifs
+	RequiresAck   bool       `json:"requires_ack" gorm:"default:false;index"`
+	AckDueAt      *time.Time `json:"ack_due_at"`      // Échéance au-delà de laquelle les retardataires sont relancés
+	AckRemindedAt *time.Time `json:"ack_reminded_at"` // Dernière relance
+
+	// Accusé de lecture de l'utilisateur connecté (non persisté)
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" gorm:"-"`
 }
 
 // AnnouncementRequest pour les requêtes de création/mise à jour
@@ -368,6 +443,9 @@ type AnnouncementRequest struct {
 	IsActive  bool       `json:"is_active"`
 	StartDate *time.Time `json:"start_date"`
 	EndDate   *time.Time `json:"end_date"`
+
+	RequiresAck bool       `json:"requires_ack"`
+	AckDueAt    *time.Time `json:"ack_due_at"`
 }
 
 // Notification représente une notification in-app pour un utilisateur
@@ -7,6 +7,8 @@ import (
 	"time"
 	"unicode"
 
+	"airboard/utils"
+
 	"gorm.io/gorm"
 )
 
@@ -21,11 +23,19 @@ type News struct {
 	Type        string     `json:"type" gorm:"default:'article'"`    // article, tutorial, announcement, faq
 	Priority    string     `json:"priority" gorm:"default:'normal'"` // urgent, important, normal
 	IsPinned    bool       `json:"is_pinned" gorm:"default:false"`
-	IsPublished bool       `json:"is_published" gorm:"default:false"`
+	Status      string     `json:"status" gorm:"size:20;default:'draft';index"` // draft, in_review, approved, scheduled, published, archived
+	IsPublished bool       `json:"is_published" gorm:"default:false"`           // Vrai uniquement à l'état published
 	PublishedAt *time.Time `json:"published_at"`
+	AnnouncedAt *time.Time `json:"-"`          // Diffusion (emails, notifications, webhooks) de la première publication
 	ExpiresAt   *time.Time `json:"expires_at"` // Auto-archivage après cette date
 	ViewCount   int        `json:"view_count" gorm:"default:0"`
-	ReadingTime int        `json:"reading_time"` // Temps de lecture estimé (minutes)
+	ReadingTime int        `json:"reading_time"`       // Temps de lecture estimé (minutes)
+	SearchText  string     `json:"-" gorm:"type:text"` // Contenu en texte brut indexé par la recherche plein texte
+
+	// Lecture obligatoire : accusé de lecture explicite demandé aux lecteurs ciblés
+	RequiresAck   bool       `json:"requires_ack" gorm:"default:false;index"`
+	AckDueAt      *time.Time `json:"ack_due_at"`      // Échéance au-delà de laquelle les retardataires sont relancés
+	AckRemindedAt *time.Time `json:"ack_reminded_at"` // Dernière relance
 
 	// Relations
 	AuthorID   uint          `json:"author_id"`
@@ -41,10 +51,16 @@ type News struct {
 	// Reactions
 	Reactions []NewsReaction `json:"reactions,omitempty" gorm:"foreignKey:NewsID"`
 
+	// Relecture éditoriale
+	Reviewers []NewsReviewer `json:"reviewers,omitempty" gorm:"foreignKey:NewsID"`
+
 	// Compteurs calculés (non persistés)
 	CommentCount  int `json:"comment_count" gorm:"-"`
 	ReactionCount int `json:"reaction_count" gorm:"-"`
 
+	// Accusé de lecture de l'utilisateur connecté (non persisté)
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" gorm:"-"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -119,6 +135,8 @@ type NewsRequest struct {
 	CategoryID     *uint      `json:"category_id"`
 	TagIDs         []uint     `json:"tag_ids"`          // IDs des tags
 	TargetGroupIDs []uint     `json:"target_group_ids"` // IDs des groupes cibles
+	RequiresAck    bool       `json:"requires_ack"`
+	AckDueAt       *time.Time `json:"ack_due_at"`
 }
 
 // CategoryRequest pour la création/modification de catégories
@@ -178,8 +196,11 @@ func (NewsRead) TableName() string {
 	return "news_reads"
 }
 
-// BeforeSave hook pour générer le slug automatiquement
+// BeforeSave hook pour générer le slug, estimer le temps de lecture et extraire le texte indexé
 func (n *News) BeforeSave(tx *gorm.DB) error {
+	n.ReadingTime = utils.TiptapReadingTime(n.Content)
+	n.SearchText = utils.TiptapToText(n.Content)
+
 	if n.Slug == "" {
 		baseSlug := generateSlug(n.Title)
 		slug := baseSlug
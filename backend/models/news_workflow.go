@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// États éditoriaux d'un article
+const (
+	NewsStatusDraft     = "draft"
+	NewsStatusInReview  = "in_review"
+	NewsStatusApproved  = "approved"
+	NewsStatusScheduled = "scheduled" // Publication différée (PublishedAt dans le futur)
+	NewsStatusPublished = "published"
+	NewsStatusArchived  = "archived"
+)
+
+// Décisions d'un relecteur
+const (
+	ReviewDecisionPending          = "pending"
+	ReviewDecisionApproved         = "approved"
+	ReviewDecisionChangesRequested = "changes_requested"
+)
+
+// Actions du workflow éditorial (historique)
+const (
+	NewsActionCreate           = "create"
+	NewsActionSubmit           = "submit"
+	NewsActionAssignReviewers  = "assign_reviewers"
+	NewsActionApprove          = "approve"
+	NewsActionRequestChanges   = "request_changes"
+	NewsActionSchedule         = "schedule"
+	NewsActionUnschedule       = "unschedule"
+	NewsActionPublish          = "publish"
+	NewsActionUnpublish        = "unpublish"
+	NewsActionArchive          = "archive"
+	NewsActionUnarchive        = "unarchive"
+	NewsActionEditAfterApprove = "edit_after_approval" // Modification d'un article approuvé : nouvelle relecture nécessaire
+	NewsActionEditAfterPublish = "edit_after_publish"  // Modification d'un article publié : retiré de la publication jusqu'à sa relecture
+)
+
+// NewsReviewer relecteur assigné à un article et sa décision
+type NewsReviewer struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	NewsID       uint       `json:"news_id" gorm:"not null;uniqueIndex:idx_news_reviewer"`
+	ReviewerID   uint       `json:"reviewer_id" gorm:"not null;uniqueIndex:idx_news_reviewer;index"`
+	Reviewer     *User      `json:"reviewer,omitempty" gorm:"foreignKey:ReviewerID"`
+	AssignedByID uint       `json:"assigned_by_id"`
+	Decision     string     `json:"decision" gorm:"size:20;default:'pending'"`
+	Comment      string     `json:"comment" gorm:"type:text"`
+	DecidedAt    *time.Time `json:"decided_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// NewsWorkflowEvent transition du workflow éditorial (historique immuable, commentaires inclus)
+type NewsWorkflowEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	NewsID     uint      `json:"news_id" gorm:"not null;index"`
+	ActorID    *uint     `json:"actor_id"` // nil pour les transitions automatiques (publication planifiée, expiration)
+	Actor      *User     `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	Action     string    `json:"action" gorm:"size:30;not null"`
+	FromStatus string    `json:"from_status" gorm:"size:20"`
+	ToStatus   string    `json:"to_status" gorm:"size:20"`
+	Comment    string    `json:"comment" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// NewsWorkflowInfo état éditorial d'un article
+type NewsWorkflowInfo struct {
+	Status           string              `json:"status"`
+	ApprovalRequired bool                `json:"approval_required"` // L'utilisateur courant doit obtenir une approbation avant de publier
+	CanReview        bool                `json:"can_review"`        // L'utilisateur courant peut approuver ou demander des modifications
+	PublishedAt      *time.Time          `json:"published_at"`
+	Reviewers        []NewsReviewer      `json:"reviewers"`
+	History          []NewsWorkflowEvent `json:"history"`
+}
+
+// NewsSubmitRequest soumission d'un article en relecture
+type NewsSubmitRequest struct {
+	ReviewerIDs []uint `json:"reviewer_ids"` // Vide : tous les relecteurs éligibles sont notifiés
+	Comment     string `json:"comment" binding:"max=2000"`
+}
+
+// NewsReviewersRequest assignation des relecteurs
+type NewsReviewersRequest struct {
+	ReviewerIDs []uint `json:"reviewer_ids" binding:"required"`
+}
+
+// NewsReviewDecisionRequest approbation ou demande de modifications
+type NewsReviewDecisionRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// NewsPublishRequest publication immédiate ou planifiée
+type NewsPublishRequest struct {
+	PublishAt *time.Time `json:"publish_at"` // Dans le futur : publication planifiée
+}
@@ -0,0 +1,53 @@
+package models
+
+// Origine du rattachement hiérarchique d'un utilisateur (User.ManagerSource)
+const (
+	ManagerSourceAdmin = "admin"
+	ManagerSourceCSV   = "csv"
+	ManagerSourceLDAP  = "ldap"
+	ManagerSourceSCIM  = "scim"
+	ManagerSourceSAML  = "saml"
+	ManagerSourceOIDC  = "oidc"
+)
+
+// OrgChartNode personne de l'organigramme. Les personnes absentes de l'annuaire pour l'utilisateur
+// qui consulte sont omises : leurs collaborateurs sont rattachés au premier responsable visible.
+type OrgChartNode struct {
+	DirectoryPerson
+	ManagerID   *uint           `json:"manager_id"`        // Premier responsable visible (nil = racine)
+	ReportCount int             `json:"report_count"`      // Collaborateurs directs, y compris ceux non développés
+	TeamSize    int             `json:"team_size"`         // Effectif total de la branche (hors la personne)
+	Reports     []*OrgChartNode `json:"reports,omitempty"` // Collaborateurs directs développés (export arborescent)
+}
+
+// OrgChart export de l'organigramme, arborescent (Roots) ou à plat (Nodes)
+type OrgChart struct {
+	Roots      []*OrgChartNode `json:"roots,omitempty"`
+	Nodes      []*OrgChartNode `json:"nodes,omitempty"`
+	Total      int             `json:"total"` // Personnes dans le périmètre exporté
+	RootID     *uint           `json:"root_id,omitempty"`
+	Department string          `json:"department,omitempty"`
+	Depth      int             `json:"depth,omitempty"` // Niveaux développés (0 = tous)
+}
+
+// SetManagerRequest rattachement d'un utilisateur à son responsable (nil = aucun)
+type SetManagerRequest struct {
+	ManagerID *uint `json:"manager_id"`
+}
+
+// OrgImportError ligne rejetée lors d'un import CSV des responsables
+type OrgImportError struct {
+	Line    int    `json:"line"`
+	User    string `json:"user"`
+	Manager string `json:"manager,omitempty"`
+	Message string `json:"message"`
+}
+
+// OrgImportResult résume un import CSV des responsables
+type OrgImportResult struct {
+	DryRun    bool             `json:"dry_run"`
+	Rows      int              `json:"rows"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Errors    []OrgImportError `json:"errors"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PasswordHistory ancien mot de passe (hash bcrypt) d'un utilisateur, conservé pour empêcher sa réutilisation
+type PasswordHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	User         *User     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PasswordPolicyInfo règles de mot de passe exposées aux formulaires (inscription, changement de mot de passe)
+type PasswordPolicyInfo struct {
+	MinLength      int  `json:"min_length"`
+	MaxLength      int  `json:"max_length"`
+	RequireUpper   bool `json:"require_upper"`
+	RequireLower   bool `json:"require_lower"`
+	RequireDigit   bool `json:"require_digit"`
+	RequireSpecial bool `json:"require_special"`
+	MaxAgeDays     int  `json:"max_age_days"`
+	HistoryDepth   int  `json:"history_depth"`
+	CheckBreached  bool `json:"check_breached"` // Vrai seulement si une liste de fuites est chargée
+}
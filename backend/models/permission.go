@@ -0,0 +1,357 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Permissions nommées (format "<domaine>.<action>")
+const (
+	PermAll = "*" // Toutes les permissions
+
+	PermUsersManage         = "users.manage"
+	PermGroupsManage        = "groups.manage"
+	PermRolesManage         = "roles.manage"
+	PermServiceAccounts     = "service_accounts.manage"
+	PermApplicationsManage  = "applications.manage"
+	PermSettingsManage      = "settings.manage"
+	PermIdentityManage      = "identity.manage" // OAuth, SAML, LDAP, SCIM, SSO
+	PermEmailManage         = "email.manage"
+	PermSystemReset         = "system.reset"
+	PermAnalyticsView       = "analytics.view"
+	PermAnnouncementsManage = "announcements.manage"
+	PermAuditView           = "audit.view"
+	PermWebhooksManage      = "webhooks.manage"
+	PermUsersImpersonate    = "users.impersonate" // Se connecter en tant qu'un autre utilisateur (support)
+
+	PermNewsCreate           = "news.create"
+	PermNewsPublish          = "news.publish"
+	PermNewsManage           = "news.manage" // Modifier/supprimer les articles des autres, épingler, statistiques
+	PermNewsCategoriesManage = "news.categories.manage"
+	PermNewsTagsManage       = "news.tags.manage"
+
+	PermEventsCreate = "events.create"
+	PermEventsManage = "events.manage" // Événements des autres, catégories, jours fériés, statistiques
+
+	PermPollsCreate = "polls.create"
+	PermPollsManage = "polls.manage" // Sondages des autres, clôture, statistiques
+
+	PermCommentsModerate = "comments.moderate"
+	PermFeedbackView     = "feedback.view"
+
+	PermMediaUpload = "media.upload"
+	PermMediaDelete = "media.delete" // Supprimer les médias des autres
+)
+
+// Types de périmètre d'une attribution de rôle
+const (
+	ScopeGlobal   = ""
+	ScopeGroup    = "group"    // Limité aux contenus ciblant un groupe
+	ScopeCategory = "category" // Limité à une catégorie de news
+)
+
+// Rôles système issus de l'ancien champ User.Role (et de la table group_admins)
+const (
+	RoleAdmin      = "admin"
+	RoleEditor     = "editor"
+	RoleUser       = "user"
+	RoleGroupAdmin = "group_admin" // Appliqué automatiquement aux groupes administrés
+)
+
+// PermissionInfo décrit une permission disponible
+type PermissionInfo struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Scopable    bool   `json:"scopable"` // Peut être limitée à un groupe ou une catégorie
+}
+
+// AvailablePermissions liste les permissions assignables aux rôles
+var AvailablePermissions = []PermissionInfo{
+	{PermUsersManage, "Gérer les utilisateurs", false},
+	{PermGroupsManage, "Gérer les groupes et leurs administrateurs", false},
+	{PermRolesManage, "Gérer les rôles et leurs attributions", false},
+	{PermServiceAccounts, "Gérer les comptes de service et leurs jetons d'API", false},
+	{PermApplicationsManage, "Gérer les applications et groupes d'applications", true},
+	{PermSettingsManage, "Gérer les paramètres de l'application", false},
+	{PermIdentityManage, "Gérer les fournisseurs d'identité (OAuth, SAML, LDAP, SCIM)", false},
+	{PermEmailManage, "Gérer la configuration email et les modèles", false},
+	{PermSystemReset, "Réinitialiser la base de données", false},
+	{PermAnalyticsView, "Consulter les statistiques", false},
+	{PermAnnouncementsManage, "Gérer les annonces", false},
+	{PermAuditView, "Consulter et exporter le journal d'audit", false},
+	{PermWebhooksManage, "Gérer les webhooks sortants et leurs livraisons", false},
+	{PermUsersImpersonate, "Se connecter en tant qu'un autre utilisateur (support)", false},
+	{PermNewsCreate, "Rédiger des articles", true},
+	{PermNewsPublish, "Publier des articles", true},
+	{PermNewsManage, "Gérer tous les articles", true},
+	{PermNewsCategoriesManage, "Gérer les catégories d'articles", false},
+	{PermNewsTagsManage, "Gérer les tags", false},
+	{PermEventsCreate, "Créer des événements", true},
+	{PermEventsManage, "Gérer tous les événements", true},
+	{PermPollsCreate, "Créer des sondages", true},
+	{PermPollsManage, "Gérer tous les sondages", true},
+	{PermCommentsModerate, "Modérer les commentaires", false},
+	{PermFeedbackView, "Consulter les retours", false},
+	{PermMediaUpload, "Téléverser des médias", false},
+	{PermMediaDelete, "Supprimer les médias des autres", false},
+}
+
+// SystemRolePermissions définit les rôles système équivalents aux anciens rôles
+var SystemRolePermissions = map[string][]string{
+	RoleAdmin: {PermAll},
+	RoleEditor: {
+		PermNewsCreate, PermNewsPublish, PermNewsTagsManage,
+		PermEventsCreate, PermPollsCreate,
+		PermCommentsModerate, PermMediaUpload,
+	},
+	RoleUser: {},
+	RoleGroupAdmin: {
+		PermApplicationsManage,
+		PermNewsCreate, PermNewsPublish, PermNewsManage, PermNewsCategoriesManage, PermNewsTagsManage,
+		PermEventsCreate, PermEventsManage, PermPollsCreate, PermPollsManage,
+		PermCommentsModerate, PermMediaUpload,
+	},
+}
+
+// Role est un ensemble nommé de permissions
+type Role struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"` // Identifiant (ex: "communication")
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description"`
+	Permissions string    `json:"permissions" gorm:"type:text"` // Permissions séparées par des virgules ("news.*" accepté)
+	IsSystem    bool      `json:"is_system" gorm:"default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PermissionList retourne les permissions du rôle
+func (r *Role) PermissionList() []string {
+	var permissions []string
+	for _, p := range strings.Split(r.Permissions, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
+
+// RoleAssignment attribue un rôle à un utilisateur ou aux membres d'un groupe, avec un périmètre optionnel
+type RoleAssignment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	RoleID      uint      `json:"role_id" gorm:"not null;index"`
+	Role        Role      `json:"role,omitempty" gorm:"foreignKey:RoleID"`
+	UserID      *uint     `json:"user_id" gorm:"index"`  // Attribution à un utilisateur
+	GroupID     *uint     `json:"group_id" gorm:"index"` // Attribution aux membres d'un groupe
+	ScopeType   string    `json:"scope_type"`            // "", "group" ou "category"
+	ScopeID     *uint     `json:"scope_id"`
+	CreatedByID uint      `json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RoleRequest pour la création ou la mise à jour d'un rôle
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	DisplayName string   `json:"display_name" binding:"max=100"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleAssignmentRequest pour attribuer un rôle
+type RoleAssignmentRequest struct {
+	RoleID    uint   `json:"role_id" binding:"required"`
+	UserID    *uint  `json:"user_id"`
+	GroupID   *uint  `json:"group_id"`
+	ScopeType string `json:"scope_type" binding:"omitempty,oneof=group category"`
+	ScopeID   *uint  `json:"scope_id"`
+}
+
+// PermissionScope périmètre d'une permission accordée
+type PermissionScope struct {
+	GroupIDs    []uint `json:"group_ids,omitempty"`
+	CategoryIDs []uint `json:"category_ids,omitempty"`
+	Global      bool   `json:"global"`
+}
+
+// PermissionSet permissions effectives d'un utilisateur
+type PermissionSet struct {
+	Grants map[string]*PermissionScope `json:"grants"`
+}
+
+// NewPermissionSet crée un ensemble de permissions vide
+func NewPermissionSet() *PermissionSet {
+	return &PermissionSet{Grants: make(map[string]*PermissionScope)}
+}
+
+// Grant ajoute une permission (éventuellement limitée à un périmètre)
+func (s *PermissionSet) Grant(permission, scopeType string, scopeID uint) {
+	scope, ok := s.Grants[permission]
+	if !ok {
+		scope = &PermissionScope{}
+		s.Grants[permission] = scope
+	}
+	switch scopeType {
+	case ScopeGroup:
+		scope.GroupIDs = appendUnique(scope.GroupIDs, scopeID)
+	case ScopeCategory:
+		scope.CategoryIDs = appendUnique(scope.CategoryIDs, scopeID)
+	default:
+		scope.Global = true
+	}
+}
+
+// scopesFor retourne les périmètres correspondant à une permission (wildcards "*" et "domaine.*" inclus)
+func (s *PermissionSet) scopesFor(permission string) []*PermissionScope {
+	var scopes []*PermissionScope
+	for key, scope := range s.Grants {
+		if permissionCovers(key, permission) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// Has indique si la permission est accordée sans restriction de périmètre
+func (s *PermissionSet) Has(permission string) bool {
+	for _, scope := range s.scopesFor(permission) {
+		if scope.Global {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAny indique si la permission est accordée, quel que soit le périmètre
+func (s *PermissionSet) HasAny(permission string) bool {
+	return len(s.scopesFor(permission)) > 0
+}
+
+// GroupIDs retourne les groupes sur lesquels la permission est accordée
+func (s *PermissionSet) GroupIDs(permission string) []uint {
+	var ids []uint
+	for _, scope := range s.scopesFor(permission) {
+		for _, id := range scope.GroupIDs {
+			ids = appendUnique(ids, id)
+		}
+	}
+	return ids
+}
+
+// CategoryIDs retourne les catégories sur lesquelles la permission est accordée
+func (s *PermissionSet) CategoryIDs(permission string) []uint {
+	var ids []uint
+	for _, scope := range s.scopesFor(permission) {
+		for _, id := range scope.CategoryIDs {
+			ids = appendUnique(ids, id)
+		}
+	}
+	return ids
+}
+
+// InGroups indique si la permission couvre tous les groupes donnés
+func (s *PermissionSet) InGroups(permission string, groupIDs []uint) bool {
+	if s.Has(permission) {
+		return true
+	}
+	if len(groupIDs) == 0 {
+		return false
+	}
+	allowed := s.GroupIDs(permission)
+	for _, id := range groupIDs {
+		if !containsUint(allowed, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// InCategory indique si la permission couvre la catégorie donnée
+func (s *PermissionSet) InCategory(permission string, categoryID uint) bool {
+	return s.Has(permission) || containsUint(s.CategoryIDs(permission), categoryID)
+}
+
+// Covers indique si l'ensemble inclut toutes les permissions (et tous les périmètres) de other
+func (s *PermissionSet) Covers(other *PermissionSet) bool {
+	for permission, scope := range other.Grants {
+		if s.Has(permission) {
+			continue
+		}
+		if scope.Global {
+			return false
+		}
+		if len(scope.GroupIDs) > 0 && !s.InGroups(permission, scope.GroupIDs) {
+			return false
+		}
+		for _, categoryID := range scope.CategoryIDs {
+			if !s.InCategory(permission, categoryID) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// List retourne les permissions accordées, triées
+func (s *PermissionSet) List() []string {
+	list := make([]string, 0, len(s.Grants))
+	for key := range s.Grants {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Restrict retourne l'intersection de l'ensemble avec une liste de permissions (scopes d'un jeton d'API).
+// Les périmètres accordés sont conservés.
+func (s *PermissionSet) Restrict(allowed []string) *PermissionSet {
+	restricted := NewPermissionSet()
+	for key, scope := range s.Grants {
+		for _, permission := range allowed {
+			var granted string
+			switch {
+			case permissionCovers(key, permission):
+				granted = permission
+			case permissionCovers(permission, key):
+				granted = key
+			default:
+				continue
+			}
+			target, ok := restricted.Grants[granted]
+			if !ok {
+				target = &PermissionScope{}
+				restricted.Grants[granted] = target
+			}
+			target.Global = target.Global || scope.Global
+			for _, id := range scope.GroupIDs {
+				target.GroupIDs = appendUnique(target.GroupIDs, id)
+			}
+			for _, id := range scope.CategoryIDs {
+				target.CategoryIDs = appendUnique(target.CategoryIDs, id)
+			}
+		}
+	}
+	return restricted
+}
+
+// permissionCovers indique si le motif (permission, "*" ou "domaine.*") couvre la permission
+func permissionCovers(pattern, permission string) bool {
+	return pattern == permission || pattern == PermAll ||
+		(strings.HasSuffix(pattern, ".*") && strings.HasPrefix(permission, strings.TrimSuffix(pattern, "*")))
+}
+
+func appendUnique(ids []uint, id uint) []uint {
+	if containsUint(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
+
+func containsUint(ids []uint, id uint) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+// Types de demandes relatives aux données personnelles (RGPD)
+const (
+	PrivacyRequestExport  = "export"  // Droit d'accès et à la portabilité (archive ZIP)
+	PrivacyRequestErasure = "erasure" // Droit à l'effacement
+)
+
+// Statuts des demandes RGPD
+const (
+	PrivacyStatusPendingApproval = "pending_approval" // Effacement demandé par l'utilisateur, en attente de validation
+	PrivacyStatusQueued          = "queued"           // En file d'attente de traitement
+	PrivacyStatusProcessing      = "processing"
+	PrivacyStatusCompleted       = "completed"
+	PrivacyStatusFailed          = "failed"
+	PrivacyStatusRejected        = "rejected"
+	PrivacyStatusExpired         = "expired" // Archive d'export purgée
+)
+
+// Traitements appliqués à une catégorie de données lors d'un effacement
+const (
+	PrivacyActionDelete    = "delete"    // Suppression des enregistrements
+	PrivacyActionAnonymize = "anonymize" // Conservation sans donnée identifiante
+	PrivacyActionRetain    = "retain"    // Conservation (obligation légale ou contenu éditorial)
+)
+
+// Actions d'audit des demandes RGPD
+const (
+	AuditActionPrivacyExport  = "privacy.export"
+	AuditActionPrivacyErasure = "privacy.erasure"
+)
+
+// PrivacyRequest demande d'export ou d'effacement des données d'un utilisateur et suivi de son traitement.
+// La demande est conservée après effacement (preuve du traitement) ; l'utilisateur n'est alors plus identifiable.
+type PrivacyRequest struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	User          *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Type          string     `json:"type" gorm:"not null;index"`   // export, erasure
+	Status        string     `json:"status" gorm:"not null;index"` // pending_approval, queued, processing, completed, failed, rejected, expired
+	RequestedByID uint       `json:"requested_by_id"`              // Utilisateur lui-même ou administrateur
+	Reason        string     `json:"reason" gorm:"type:text"`
+	ReviewedByID  *uint      `json:"reviewed_by_id"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewComment string     `json:"review_comment" gorm:"type:text"`
+	FilePath      string     `json:"-"`         // Archive d'export
+	FileSize      int64      `json:"file_size"` // Taille de l'archive (octets)
+	ExpiresAt     *time.Time `json:"expires_at"`
+	Summary       string     `json:"summary" gorm:"type:jsonb"` // Résultat par catégorie ([]PrivacyCategoryResult)
+	Error         string     `json:"error" gorm:"type:text"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	StartedAt     *time.Time `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// PrivacyPolicyRule traitement d'une catégorie de données lors d'un effacement
+type PrivacyPolicyRule struct {
+	Category    string `json:"category"`
+	Action      string `json:"action"` // delete, anonymize, retain
+	Description string `json:"description"`
+}
+
+// PrivacyCategoryResult résultat de l'export ou de l'effacement d'une catégorie
+type PrivacyCategoryResult struct {
+	Category string `json:"category"`
+	Action   string `json:"action"`
+	Count    int64  `json:"count"`
+}
+
+// PrivacyErasureRequest demande d'effacement formulée par l'utilisateur (confirmation par mot de passe)
+type PrivacyErasureRequest struct {
+	Password string `json:"password"` // Requis pour les comptes locaux
+	Reason   string `json:"reason" binding:"max=1000"`
+}
+
+// PrivacyReviewRequest validation ou refus d'une demande d'effacement
+type PrivacyReviewRequest struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// PrivacyErasurePolicy politique d'effacement appliquée catégorie par catégorie (documentée et exposée par l'API).
+// Le compte est conservé sous forme anonyme pour préserver l'intégrité des contenus partagés.
+var PrivacyErasurePolicy = []PrivacyPolicyRule{
+	{Category: "profile", Action: PrivacyActionAnonymize, Description: "Identité, coordonnées, avatar, rattachements SSO et mot de passe supprimés ; le compte devient « Utilisateur supprimé » et est désactivé"},
+	{Category: "groups", Action: PrivacyActionDelete, Description: "Appartenances et administration de groupes retirées"},
+	{Category: "favorites", Action: PrivacyActionDelete, Description: "Applications favorites supprimées"},
+	{Category: "comments", Action: PrivacyActionAnonymize, Description: "Texte remplacé par une mention de suppression ; le fil de discussion est conservé"},
+	{Category: "reactions", Action: PrivacyActionDelete, Description: "Réactions et avis (feedbacks) supprimés"},
+	{Category: "poll_votes", Action: PrivacyActionAnonymize, Description: "Votes conservés pour ne pas fausser les résultats, rattachés au compte anonymisé"},
+	{Category: "chat_messages", Action: PrivacyActionAnonymize, Description: "Messages envoyés vidés de leur contenu ; les messages reçus restent visibles de leurs auteurs"},
+	{Category: "notifications", Action: PrivacyActionDelete, Description: "Notifications supprimées"},
+	{Category: "gamification", Action: PrivacyActionDelete, Description: "Profil de progression, badges et historique des points supprimés"},
+	{Category: "activity", Action: PrivacyActionDelete, Description: "Lectures d'articles et clics sur les applications supprimés"},
+	{Category: "security", Action: PrivacyActionDelete, Description: "Historique des mots de passe, appareils connus, verrouillages, jetons d'API et sessions SAML supprimés"},
+	{Category: "acknowledgements", Action: PrivacyActionRetain, Description: "Accusés de lecture des contenus obligatoires conservés comme preuve de conformité, rattachés au compte anonymisé"},
+	{Category: "authored_content", Action: PrivacyActionRetain, Description: "Articles, événements, sondages et médias publiés conservés, attribués au compte anonymisé"},
+	{Category: "audit_logs", Action: PrivacyActionRetain, Description: "Journal d'audit conservé au titre des obligations légales de traçabilité"},
+	{Category: "privacy_requests", Action: PrivacyActionRetain, Description: "Demandes RGPD conservées comme preuve de traitement"},
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Groupes de routes auxquels s'appliquent les politiques de limitation
+const (
+	RateLimitGroupAll    = "*"      // Toutes les routes limitées
+	RateLimitGroupLogin  = "login"  // Connexion, inscription, rafraîchissement de jeton
+	RateLimitGroupPublic = "public" // Autres routes publiques (fournisseurs SSO, version...)
+	RateLimitGroupAPI    = "api"    // Routes authentifiées
+	RateLimitGroupSCIM   = "scim"   // Provisioning SCIM
+)
+
+// RateLimitGroups liste les groupes de routes configurables
+var RateLimitGroups = []string{RateLimitGroupAll, RateLimitGroupLogin, RateLimitGroupPublic, RateLimitGroupAPI, RateLimitGroupSCIM}
+
+// Rôle particulier désignant les requêtes non authentifiées
+const RateLimitRoleAnonymous = "anonymous"
+
+// RateLimitPolicy politique de limitation configurable par groupe de routes et par rôle.
+// Pour une requête, la politique la plus spécifique s'applique (groupe exact puis rôle exact).
+type RateLimitPolicy struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Name          string    `json:"name" gorm:"not null"`
+	RouteGroup    string    `json:"route_group" gorm:"not null;index"` // login, public, api, scim ou "*"
+	Role          string    `json:"role" gorm:"index"`                 // Vide = tous les rôles, "anonymous" = non authentifié
+	MaxRequests   int       `json:"max_requests" gorm:"not null"`
+	PeriodSeconds int       `json:"period_seconds" gorm:"not null"`
+	IsActive      bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// RateLimitCounter compteur partagé entre les instances (fenêtre fixe)
+type RateLimitCounter struct {
+	Key       string    `gorm:"primaryKey"`
+	Count     int64     `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// RateLimitMetric nombre de requêtes rejetées, agrégé par heure, politique et client
+type RateLimitMetric struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Bucket     time.Time `json:"bucket" gorm:"not null;uniqueIndex:idx_rate_limit_metric"` // Début de l'heure
+	PolicyID   uint      `json:"policy_id" gorm:"not null;uniqueIndex:idx_rate_limit_metric"`
+	RouteGroup string    `json:"route_group" gorm:"not null;uniqueIndex:idx_rate_limit_metric"`
+	Subject    string    `json:"subject" gorm:"not null;uniqueIndex:idx_rate_limit_metric"` // user:<id> ou ip:<adresse>
+	Role       string    `json:"role"`
+	Throttled  int64     `json:"throttled" gorm:"not null;default:0"`
+}
+
+// RateLimitPolicyRequest pour la création ou la mise à jour d'une politique
+type RateLimitPolicyRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	RouteGroup    string `json:"route_group" binding:"required"`
+	Role          string `json:"role" binding:"max=50"`
+	MaxRequests   int    `json:"max_requests" binding:"required,min=1,max=1000000"`
+	PeriodSeconds int    `json:"period_seconds" binding:"required,min=1,max=86400"`
+	IsActive      *bool  `json:"is_active"`
+}
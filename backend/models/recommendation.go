@@ -0,0 +1,29 @@
+package models
+
+// Motifs expliquant la place d'un article dans le fil personnalisé (NewsRecommendationReason.Code)
+const (
+	RecommendationReasonRecent         = "recent"          // Publié récemment
+	RecommendationReasonUrgent         = "urgent"          // Priorité urgente
+	RecommendationReasonImportant      = "important"       // Priorité importante
+	RecommendationReasonPinned         = "pinned"          // Épinglé
+	RecommendationReasonCategory       = "category"        // Catégorie souvent lue (Label = catégorie)
+	RecommendationReasonTag            = "tag"             // Tag souvent lu (Label = tag)
+	RecommendationReasonGroupPopular   = "group_popular"   // Lu par les membres d'un groupe (Label = groupe)
+	RecommendationReasonPopular        = "popular"         // Lu par de nombreux utilisateurs
+	RecommendationReasonSimilarReaders = "similar_readers" // Lu par des utilisateurs des mêmes applications
+)
+
+// NewsRecommendationReason motif d'une recommandation et sa contribution au score
+type NewsRecommendationReason struct {
+	Code   string  `json:"code"`
+	Label  string  `json:"label,omitempty"`
+	Weight float64 `json:"weight"`
+}
+
+// NewsRecommendation article du fil personnalisé, avec son score et les motifs de son classement
+type NewsRecommendation struct {
+	News
+	Score   float64                    `json:"score"`
+	IsRead  bool                       `json:"is_read"`
+	Reasons []NewsRecommendationReason `json:"reasons"`
+}
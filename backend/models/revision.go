@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Types de contenus versionnés
+const (
+	RevisionEntityNews  = "news"
+	RevisionEntityEvent = "event"
+)
+
+// ContentRevision version immuable d'un article ou d'un événement, créée à chaque enregistrement.
+// Snapshot contient l'état complet (NewsSnapshot ou EventSnapshot), y compris tags et groupes cibles.
+type ContentRevision struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	EntityType     string    `json:"entity_type" gorm:"not null;uniqueIndex:idx_content_revision_number;index:idx_content_revision_entity"`
+	EntityID       uint      `json:"entity_id" gorm:"not null;uniqueIndex:idx_content_revision_number;index:idx_content_revision_entity"`
+	Number         int       `json:"number" gorm:"not null;uniqueIndex:idx_content_revision_number"` // Numéro de version (1, 2, ...)
+	AuthorID       uint      `json:"author_id" gorm:"index"`
+	Author         *User     `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+	Title          string    `json:"title"`
+	Snapshot       string    `json:"snapshot,omitempty" gorm:"type:jsonb;not null"`
+	RestoredFromID *uint     `json:"restored_from_id"` // Version restaurée (nil pour un enregistrement normal)
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+}
+
+// RevisionRef référence à un tag ou un groupe dans un instantané
+type RevisionRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewsSnapshot état complet d'un article au moment d'une révision
+type NewsSnapshot struct {
+	Title        string        `json:"title"`
+	Summary      string        `json:"summary"`
+	Content      string        `json:"content"` // JSON Tiptap
+	CoverImage   string        `json:"cover_image"`
+	Type         string        `json:"type"`
+	Priority     string        `json:"priority"`
+	IsPinned     bool          `json:"is_pinned"`
+	IsPublished  bool          `json:"is_published"`
+	PublishedAt  *time.Time    `json:"published_at"`
+	ExpiresAt    *time.Time    `json:"expires_at"`
+	CategoryID   *uint         `json:"category_id"`
+	Tags         []RevisionRef `json:"tags"`
+	TargetGroups []RevisionRef `json:"target_groups"`
+}
+
+// EventSnapshot état complet d'un événement au moment d'une révision
+type EventSnapshot struct {
+	Title                string        `json:"title"`
+	Description          string        `json:"description"` // JSON Tiptap
+	StartDate            time.Time     `json:"start_date"`
+	EndDate              *time.Time    `json:"end_date"`
+	IsAllDay             bool          `json:"is_all_day"`
+	Timezone             string        `json:"timezone"`
+	IsRecurring          bool          `json:"is_recurring"`
+	RecurrenceRule       string        `json:"recurrence_rule"`
+	RecurrenceEnd        *time.Time    `json:"recurrence_end"`
+	RecurrenceExceptions string        `json:"recurrence_exceptions"`
+	Location             string        `json:"location"`
+	ExternalLinks        string        `json:"external_links"`
+	Color                string        `json:"color"`
+	Priority             string        `json:"priority"`
+	Status               string        `json:"status"`
+	CoverImage           string        `json:"cover_image"`
+	IsPublished          bool          `json:"is_published"`
+	PublishedAt          *time.Time    `json:"published_at"`
+	CategoryID           *uint         `json:"category_id"`
+	Tags                 []RevisionRef `json:"tags"`
+	TargetGroups         []RevisionRef `json:"target_groups"`
+}
+
+// RevisionFieldChange modification d'un champ entre deux révisions
+type RevisionFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SAMLProvider représente un fournisseur d'identité SAML 2.0 (IdP) configuré par un admin
+type SAMLProvider struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"not null;uniqueIndex:idx_saml_provider_name,where:deleted_at IS NULL"` // Identifiant utilisé dans les URLs (ex: "partenaire-x")
+	DisplayName string `json:"display_name" gorm:"not null"`
+	Icon        string `json:"icon" gorm:"default:'mdi:shield-account'"`
+	IsEnabled   bool   `json:"is_enabled" gorm:"default:false"`
+
+	// Métadonnées de l'IdP (URL à rafraîchir ou XML importé)
+	IDPMetadataURL     string     `json:"idp_metadata_url"`
+	IDPMetadataXML     string     `json:"idp_metadata_xml,omitempty" gorm:"type:text"`
+	IDPEntityID        string     `json:"idp_entity_id"`                                   // Extrait des métadonnées
+	MetadataImportedAt *time.Time `json:"metadata_imported_at"`                            // Dernier import réussi
+	NameIDFormat       string     `json:"name_id_format" gorm:"default:'emailAddress'"`    // emailAddress, persistent, transient, unspecified
+	SignRequests       bool       `json:"sign_requests" gorm:"default:true"`               // Signer les AuthnRequest
+	AllowIDPInitiated  bool       `json:"allow_idp_initiated" gorm:"default:false"`        // Accepter les réponses non sollicitées
+	SLOEnabled         bool       `json:"slo_enabled" gorm:"default:false"`                // Single Logout
+	DefaultRole        string     `json:"default_role" gorm:"default:'user'"`              // Rôle si l'utilisateur n'est pas dans AdminGroups
+	AdminGroups        string     `json:"admin_groups"`                                    // Groupes donnant le rôle admin (séparés par des virgules)
+	EmailAttribute     string     `json:"email_attribute" gorm:"default:'email'"`          // Attribut contenant l'email (NameID si vide)
+	FirstNameAttribute string     `json:"first_name_attribute" gorm:"default:'givenName'"` // Attribut prénom
+	LastNameAttribute  string     `json:"last_name_attribute" gorm:"default:'sn'"`         // Attribut nom
+	UsernameAttribute  string     `json:"username_attribute" gorm:"default:'uid'"`         // Attribut identifiant (partie locale de l'email si absent)
+	GroupsAttribute    string     `json:"groups_attribute" gorm:"default:'groups'"`        // Attribut contenant les groupes (vide = pas de synchronisation)
+	ManagerAttribute   string     `json:"manager_attribute"`                               // Attribut contenant le responsable (email ou identifiant ; vide = pas de synchronisation)
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SAMLProviderRequest pour la création/modification d'un fournisseur SAML
+type SAMLProviderRequest struct {
+	Name               string `json:"name" binding:"required"`
+	DisplayName        string `json:"display_name" binding:"required"`
+	Icon               string `json:"icon"`
+	IsEnabled          bool   `json:"is_enabled"`
+	IDPMetadataURL     string `json:"idp_metadata_url"`
+	IDPMetadataXML     string `json:"idp_metadata_xml"`
+	NameIDFormat       string `json:"name_id_format" binding:"omitempty,oneof=emailAddress persistent transient unspecified"`
+	SignRequests       *bool  `json:"sign_requests"`
+	AllowIDPInitiated  bool   `json:"allow_idp_initiated"`
+	SLOEnabled         bool   `json:"slo_enabled"`
+	DefaultRole        string `json:"default_role" binding:"omitempty,oneof=user editor"`
+	AdminGroups        string `json:"admin_groups"`
+	EmailAttribute     string `json:"email_attribute"`
+	FirstNameAttribute string `json:"first_name_attribute"`
+	LastNameAttribute  string `json:"last_name_attribute"`
+	UsernameAttribute  string `json:"username_attribute"`
+	GroupsAttribute    string `json:"groups_attribute"`
+	ManagerAttribute   string `json:"manager_attribute"`
+}
+
+// SAMLProviderPublic pour l'affichage sur la page de connexion
+type SAMLProviderPublic struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Icon        string `json:"icon"`
+}
+
+// SAMLCredential stocke la paire clé/certificat du service provider (générée au premier démarrage
+// si SAML_SP_CERT_FILE / SAML_SP_KEY_FILE ne sont pas fournis)
+type SAMLCredential struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CertificatePEM string    `json:"certificate_pem" gorm:"type:text;not null"`
+	PrivateKeyPEM  string    `json:"-" gorm:"type:text;not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SAMLSession conserve le NameID et la SessionIndex d'une connexion SAML, nécessaires au Single Logout.
+// Chaque connexion a sa propre ligne : les sessions ouvertes en parallèle (plusieurs navigateurs)
+// sont toutes fermées par une LogoutRequest de l'IdP.
+type SAMLSession struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;index:idx_saml_session_user"`
+	ProviderID     uint       `json:"provider_id" gorm:"not null;index:idx_saml_session_user;index:idx_saml_session_name_id"`
+	NameID         string     `json:"name_id" gorm:"index:idx_saml_session_name_id"`
+	SessionIndex   string     `json:"session_index"`
+	LoginSessionID string     `json:"-" gorm:"size:64;index"` // Session de connexion Airboard (claim sid) ouverte par cette connexion SAML
+	EndedAt        *time.Time `json:"ended_at"`               // Fermée par une LogoutRequest de l'IdP : les tokens de la session sont refusés
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// SAMLPendingRequest AuthnRequest émise en attente de réponse de l'IdP, retrouvée par le RelayState
+// (partagée entre instances, usage unique)
+type SAMLPendingRequest struct {
+	ID             uint      `gorm:"primaryKey"`
+	RelayStateHash string    `gorm:"size:64;not null;uniqueIndex"` // Empreinte SHA-256 du RelayState
+	RequestID      string    `gorm:"not null"`                     // ID de l'AuthnRequest (validation InResponseTo)
+	Provider       string    `gorm:"not null"`
+	ExpiresAt      time.Time `gorm:"not null;index"`
+	CreatedAt      time.Time
+}
+
+// SAMLLogoutRequestID LogoutRequest de l'IdP déjà traitée, conservée jusqu'à la fin de sa fenêtre de
+// validité pour refuser son rejeu (partagée entre instances)
+type SAMLLogoutRequestID struct {
+	ID        uint      `gorm:"primaryKey"`
+	Provider  string    `gorm:"not null;uniqueIndex:idx_saml_logout_request"`
+	RequestID string    `gorm:"not null;uniqueIndex:idx_saml_logout_request"` // ID de la LogoutRequest
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// SAMLLoginCode code à usage unique échangé par le frontend contre des tokens JWT après l'ACS
+type SAMLLoginCode struct {
+	ID            uint      `gorm:"primaryKey"`
+	CodeHash      string    `gorm:"size:64;not null;uniqueIndex"` // Empreinte SHA-256 du code
+	UserID        uint      `gorm:"not null;index"`
+	SAMLSessionID uint      // Connexion SAML à laquelle rattacher la session de connexion Airboard
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time
+}
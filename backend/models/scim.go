@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+)
+
+// Schémas SCIM 2.0 (RFC 7643 / RFC 7644)
+const (
+	SCIMSchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SCIMSchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// SCIMToken est un jeton bearer autorisant un fournisseur d'identité à provisionner via /scim/v2
+type SCIMToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"not null"`          // Ex: "Entra ID production"
+	TokenHash   string     `json:"-" gorm:"uniqueIndex;not null"` // SHA-256 du jeton
+	TokenPrefix string     `json:"token_prefix"`                  // Premiers caractères pour identification
+	CreatedByID uint       `json:"created_by_id"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// SCIMTokenRequest pour la création d'un jeton SCIM
+type SCIMTokenRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=730"` // 0 = sans expiration
+}
+
+// SCIMMeta métadonnées d'une ressource SCIM
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// SCIMName nom structuré d'un utilisateur
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMMultiValue attribut multi-valué (emails, phoneNumbers, groups, members)
+type SCIMMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMAddress adresse d'un utilisateur (seule la localité est mappée)
+type SCIMAddress struct {
+	Type     string `json:"type,omitempty"`
+	Locality string `json:"locality,omitempty"`
+	Primary  bool   `json:"primary,omitempty"`
+}
+
+// SCIMManager responsable hiérarchique (extension entreprise)
+type SCIMManager struct {
+	Value string `json:"value"`
+	Ref   string `json:"$ref,omitempty"`
+}
+
+// SCIMEnterpriseUser extension entreprise
+type SCIMEnterpriseUser struct {
+	Department string       `json:"department,omitempty"`
+	Manager    *SCIMManager `json:"manager,omitempty"`
+}
+
+// SCIMUser représentation SCIM d'un models.User
+type SCIMUser struct {
+	Schemas     []string            `json:"schemas"`
+	ID          string              `json:"id"`
+	ExternalID  string              `json:"externalId,omitempty"`
+	UserName    string              `json:"userName"`
+	Name        SCIMName            `json:"name"`
+	DisplayName string              `json:"displayName,omitempty"`
+	Title       string              `json:"title,omitempty"`
+	Active      bool                `json:"active"`
+	Emails      []SCIMMultiValue    `json:"emails,omitempty"`
+	Phones      []SCIMMultiValue    `json:"phoneNumbers,omitempty"`
+	Addresses   []SCIMAddress       `json:"addresses,omitempty"`
+	Groups      []SCIMMultiValue    `json:"groups,omitempty"`
+	Enterprise  *SCIMEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        SCIMMeta            `json:"meta"`
+}
+
+// SCIMGroup représentation SCIM d'un models.Group
+type SCIMGroup struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []SCIMMultiValue `json:"members"`
+	Meta        SCIMMeta         `json:"meta"`
+}
+
+// SCIMListResponse réponse paginée
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMPatchRequest requête PATCH (RFC 7644 §3.5.2)
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations" binding:"required,min=1"`
+}
+
+// SCIMPatchOperation opération PATCH unitaire
+type SCIMPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// SCIMError réponse d'erreur SCIM
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
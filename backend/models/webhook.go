@@ -0,0 +1,101 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Événements pouvant être envoyés aux webhooks sortants
+const (
+	WebhookEventNewsPublished       = "news.published"
+	WebhookEventEventCreated        = "event.created"
+	WebhookEventPollClosed          = "poll.closed"
+	WebhookEventAnnouncementCreated = "announcement.created"
+	WebhookEventUserCreated         = "user.created"
+	WebhookEventCommentFlagged      = "comment.flagged"
+	WebhookEventPing                = "ping" // Envoi de test depuis l'administration
+)
+
+// WebhookEvents liste les événements auxquels un webhook peut s'abonner
+var WebhookEvents = []string{
+	WebhookEventNewsPublished,
+	WebhookEventEventCreated,
+	WebhookEventPollClosed,
+	WebhookEventAnnouncementCreated,
+	WebhookEventUserCreated,
+	WebhookEventCommentFlagged,
+}
+
+// États d'une livraison de webhook
+const (
+	WebhookDeliveryPending = "pending" // En attente (premier envoi ou nouvelle tentative planifiée)
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed" // Abandonnée après le nombre maximal de tentatives
+)
+
+// Webhook destination HTTP configurée par un administrateur (Teams, Mattermost, systèmes internes...)
+type Webhook struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`
+	Name                string     `json:"name" gorm:"not null"`
+	URL                 string     `json:"url" gorm:"not null"`
+	Secret              string     `json:"-" gorm:"not null"`       // Clé HMAC de signature, jamais exposée après création
+	Events              string     `json:"events" gorm:"type:text"` // Événements séparés par des virgules ("*" = tous)
+	IsActive            bool       `json:"is_active" gorm:"default:true"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"default:0"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	DisabledReason      string     `json:"disabled_reason"`
+	LastDeliveryAt      *time.Time `json:"last_delivery_at"`
+	CreatedByID         uint       `json:"created_by_id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// EventList retourne les événements auxquels le webhook est abonné
+func (w *Webhook) EventList() []string {
+	var events []string
+	for _, event := range strings.Split(w.Events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Subscribes indique si le webhook doit recevoir l'événement donné
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.EventList() {
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery livraison persistée d'un événement vers un webhook (file de tentatives et journal)
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"not null;index"`
+	Webhook        *Webhook   `json:"webhook,omitempty" gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE"`
+	EventID        string     `json:"event_id" gorm:"index"` // Identifiant unique envoyé dans X-Airboard-Delivery
+	Event          string     `json:"event" gorm:"not null;index"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"not null;default:'pending';index"`
+	Attempts       int        `json:"attempts" gorm:"default:0"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at" gorm:"index"`
+	ResponseStatus int        `json:"response_status"`
+	ResponseBody   string     `json:"response_body" gorm:"type:text"` // Tronqué
+	Error          string     `json:"error" gorm:"type:text"`
+	DurationMs     int64      `json:"duration_ms"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	ReplayOfID     *uint      `json:"replay_of_id"` // Livraison d'origine en cas de rejeu
+	CreatedAt      time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookRequest pour la création ou la mise à jour d'un webhook
+type WebhookRequest struct {
+	Name     string   `json:"name" binding:"required,max=100"`
+	URL      string   `json:"url" binding:"required,url,max=2000"`
+	Events   []string `json:"events" binding:"required,min=1"`
+	IsActive *bool    `json:"is_active"`
+}
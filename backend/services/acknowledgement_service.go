@@ -0,0 +1,577 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"airboard/config"
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAckContentNotFound = errors.New("Contenu introuvable")
+	ErrAckNotRequired     = errors.New("Ce contenu ne demande pas d'accusé de lecture")
+	ErrAckNotInAudience   = errors.New("Ce contenu ne vous est pas destiné")
+	ErrAckReminderTooSoon = errors.New("Une relance a déjà été envoyée il y a moins d'une heure")
+)
+
+// Pagination de la liste des utilisateurs du rapport de conformité
+const (
+	AckReportDefaultPageSize = 50
+	AckReportMaxPageSize     = 500
+)
+
+const (
+	ackReminderCooldown = time.Hour        // Délai minimal entre deux relances manuelles
+	ackSchedulerPeriod  = 15 * time.Minute // Vérification des échéances dépassées
+)
+
+// Statuts filtrables dans le rapport de conformité
+const (
+	AckStatusAcknowledged = "acknowledged"
+	AckStatusPending      = "pending"
+)
+
+// AckReportFilter filtres de la liste des utilisateurs du rapport de conformité
+type AckReportFilter struct {
+	Status   string // acknowledged, pending ou vide (tous)
+	GroupID  *uint  // 0 = utilisateurs sans groupe
+	Page     int
+	PageSize int // 0 = tous (export)
+}
+
+// AcknowledgementService gère les contenus à lecture obligatoire : accusés de lecture, relances et rapports de conformité
+type AcknowledgementService struct {
+	db            *gorm.DB
+	cfg           *config.Config
+	notifications *NotificationService
+}
+
+// NewAcknowledgementService crée une nouvelle instance de AcknowledgementService
+func NewAcknowledgementService(db *gorm.DB, cfg *config.Config) *AcknowledgementService {
+	return &AcknowledgementService{
+		db:            db,
+		cfg:           cfg,
+		notifications: NewNotificationService(db),
+	}
+}
+
+// ackItem contenu à lecture obligatoire, commun aux news et aux annonces
+type ackItem struct {
+	Type        string
+	ID          uint
+	Title       string
+	Summary     string
+	Slug        string
+	RequiresAck bool
+	Visible     bool // News publiée, ou annonce active en cours de diffusion
+	DueAt       *time.Time
+	RemindedAt  *time.Time
+	GroupIDs    []uint // Groupes ciblés (news) ; vide = tous les utilisateurs actifs
+}
+
+// table table du contenu
+func (item *ackItem) table() string {
+	if item.Type == models.AckEntityNews {
+		return "news"
+	}
+	return "announcements"
+}
+
+// path lien (relatif) vers le contenu : l'article, ou l'accueil où sont affichées les annonces
+func (item *ackItem) path() string {
+	if item.Type == models.AckEntityNews {
+		return "/news/" + item.Slug
+	}
+	return "/home"
+}
+
+// item charge un contenu à lecture obligatoire
+func (s *AcknowledgementService) item(entityType string, id uint) (*ackItem, error) {
+	now := time.Now()
+	switch entityType {
+	case models.AckEntityNews:
+		var news models.News
+		if err := s.db.Preload("TargetGroups").First(&news, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrAckContentNotFound
+			}
+			return nil, err
+		}
+		return &ackItem{
+			Type:        entityType,
+			ID:          news.ID,
+			Title:       news.Title,
+			Summary:     news.Summary,
+			Slug:        news.Slug,
+			RequiresAck: news.RequiresAck,
+			Visible: news.IsPublished && (news.PublishedAt == nil || !news.PublishedAt.After(now)) &&
+				(news.ExpiresAt == nil || news.ExpiresAt.After(now)),
+			DueAt:      news.AckDueAt,
+			RemindedAt: news.AckRemindedAt,
+			GroupIDs:   groupIDs(news.TargetGroups),
+		}, nil
+
+	case models.AckEntityAnnouncement:
+		var announcement models.Announcement
+		if err := s.db.First(&announcement, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrAckContentNotFound
+			}
+			return nil, err
+		}
+		return &ackItem{
+			Type:        entityType,
+			ID:          announcement.ID,
+			Title:       announcement.Title,
+			RequiresAck: announcement.RequiresAck,
+			Visible: announcement.IsActive &&
+				(announcement.StartDate == nil || !announcement.StartDate.After(now)) &&
+				(announcement.EndDate == nil || !announcement.EndDate.Before(now)),
+			DueAt:      announcement.AckDueAt,
+			RemindedAt: announcement.AckRemindedAt,
+		}, nil
+	}
+	return nil, ErrAckContentNotFound
+}
+
+// audience utilisateurs ciblés : comptes actifs hors comptes techniques, membres d'un groupe ciblé le cas échéant
+func (s *AcknowledgementService) audience(item *ackItem) *gorm.DB {
+	query := s.db.Table("users u").
+		Where("u.deleted_at IS NULL AND u.is_active = ? AND u.is_service_account = ?", true, false)
+	if len(item.GroupIDs) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM user_groups ug WHERE ug.user_id = u.id AND ug.group_id IN ?)", item.GroupIDs)
+	}
+	return query
+}
+
+// ============ UTILISATEUR ============
+
+// Acknowledge enregistre l'accusé de lecture de l'utilisateur ; le premier accusé de lecture fait foi
+func (s *AcknowledgementService) Acknowledge(entityType string, id, userID uint, ip string) (*models.ContentAcknowledgement, error) {
+	item, err := s.item(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	if !item.RequiresAck || !item.Visible {
+		return nil, ErrAckNotRequired
+	}
+	var inAudience int64
+	if err := s.audience(item).Where("u.id = ?", userID).Count(&inAudience).Error; err != nil {
+		return nil, err
+	}
+	if inAudience == 0 {
+		return nil, ErrAckNotInAudience
+	}
+
+	ack := models.ContentAcknowledgement{}
+	err = s.db.Where(models.ContentAcknowledgement{EntityType: entityType, EntityID: id, UserID: userID}).
+		Attrs(models.ContentAcknowledgement{AcknowledgedAt: time.Now(), IPAddress: ip}).
+		FirstOrCreate(&ack).Error
+	return &ack, err
+}
+
+// Pending contenus à lecture obligatoire visibles par l'utilisateur dont il n'a pas encore accusé lecture,
+// les plus urgents d'abord
+func (s *AcknowledgementService) Pending(userID uint) ([]models.PendingAcknowledgement, error) {
+	now := time.Now()
+	notAcknowledged := "NOT EXISTS (SELECT 1 FROM content_acknowledgements ca WHERE ca.entity_type = ? AND ca.entity_id = %s.id AND ca.user_id = ?)"
+
+	var memberGroupIDs []uint
+	s.db.Table("user_groups").Where("user_id = ?", userID).Pluck("group_id", &memberGroupIDs)
+
+	newsWhere := "news.requires_ack = ? AND news.status = ? AND news.is_published = ?" +
+		" AND (news.published_at IS NULL OR news.published_at <= ?) AND (news.expires_at IS NULL OR news.expires_at > ?)" +
+		" AND " + fmt.Sprintf(notAcknowledged, "news")
+	newsArgs := []interface{}{true, models.NewsStatusPublished, true, now, now, models.AckEntityNews, userID}
+	newsWhere, newsArgs = targetGroupsSQL(newsWhere, newsArgs, "news_target_groups", "news_id", "news.id", memberGroupIDs)
+
+	var news []models.News
+	if err := s.db.Model(&models.News{}).Where(newsWhere, newsArgs...).Find(&news).Error; err != nil {
+		return nil, err
+	}
+
+	var announcements []models.Announcement
+	if err := s.db.Model(&models.Announcement{}).
+		Where("announcements.requires_ack = ? AND announcements.is_active = ?", true, true).
+		Where("(announcements.start_date IS NULL OR announcements.start_date <= ?) AND (announcements.end_date IS NULL OR announcements.end_date >= ?)", now, now).
+		Where(fmt.Sprintf(notAcknowledged, "announcements"), models.AckEntityAnnouncement, userID).
+		Find(&announcements).Error; err != nil {
+		return nil, err
+	}
+
+	pending := make([]models.PendingAcknowledgement, 0, len(news)+len(announcements))
+	for _, n := range news {
+		pending = append(pending, models.PendingAcknowledgement{
+			EntityType: models.AckEntityNews,
+			EntityID:   n.ID,
+			Title:      n.Title,
+			Summary:    n.Summary,
+			Slug:       n.Slug,
+			DueAt:      n.AckDueAt,
+			Overdue:    n.AckDueAt != nil && n.AckDueAt.Before(now),
+		})
+	}
+	for _, a := range announcements {
+		pending = append(pending, models.PendingAcknowledgement{
+			EntityType: models.AckEntityAnnouncement,
+			EntityID:   a.ID,
+			Title:      a.Title,
+			Summary:    a.Content,
+			DueAt:      a.AckDueAt,
+			Overdue:    a.AckDueAt != nil && a.AckDueAt.Before(now),
+		})
+	}
+
+	// Échéance la plus proche d'abord, contenus sans échéance à la fin
+	sort.SliceStable(pending, func(i, j int) bool {
+		a, b := pending[i].DueAt, pending[j].DueAt
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Before(*b)
+	})
+	return pending, nil
+}
+
+// ============ CONFORMITÉ ============
+
+// Report rapport de conformité d'un contenu : utilisateurs ciblés ayant accusé lecture ou non, par groupe
+func (s *AcknowledgementService) Report(entityType string, id uint, filter AckReportFilter) (*models.AcknowledgementReport, error) {
+	item, err := s.item(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	if !item.RequiresAck {
+		return nil, ErrAckNotRequired
+	}
+
+	var users []models.AcknowledgementReportUser
+	if err := s.audience(item).
+		Select("u.id, u.username, u.first_name, u.last_name, u.email, u.department, ca.acknowledged_at, COALESCE(ca.ip_address, '') AS ip_address").
+		Joins("LEFT JOIN content_acknowledgements ca ON ca.user_id = u.id AND ca.entity_type = ? AND ca.entity_id = ?", item.Type, item.ID).
+		Order("lower(u.last_name), lower(u.first_name), u.id").
+		Scan(&users).Error; err != nil {
+		return nil, err
+	}
+
+	// Appartenances aux groupes (limitées aux groupes ciblés pour une news ciblée)
+	var memberships []struct {
+		UserID  uint
+		GroupID uint
+		Name    string
+	}
+	membershipQuery := s.db.Table("user_groups ug").
+		Select("ug.user_id, g.id AS group_id, g.name").
+		Joins("JOIN groups g ON g.id = ug.group_id AND g.deleted_at IS NULL").
+		Where("ug.user_id IN (?)", s.audience(item).Select("u.id"))
+	if len(item.GroupIDs) > 0 {
+		membershipQuery = membershipQuery.Where("ug.group_id IN ?", item.GroupIDs)
+	}
+	if err := membershipQuery.Order("g.name, g.id").Scan(&memberships).Error; err != nil {
+		return nil, err
+	}
+	userGroups := make(map[uint][]uint)
+	groupNames := make(map[uint]string)
+	for _, m := range memberships {
+		userGroups[m.UserID] = append(userGroups[m.UserID], m.GroupID)
+		groupNames[m.GroupID] = m.Name
+	}
+
+	now := time.Now()
+	report := &models.AcknowledgementReport{
+		EntityType: item.Type,
+		EntityID:   item.ID,
+		Title:      item.Title,
+		DueAt:      item.DueAt,
+		RemindedAt: item.RemindedAt,
+		Audience:   len(users),
+	}
+
+	stats := make(map[uint]*models.AcknowledgementGroupStats)
+	count := func(groupID uint, acknowledged bool) {
+		stat, ok := stats[groupID]
+		if !ok {
+			name := groupNames[groupID]
+			if groupID == 0 {
+				name = "Sans groupe"
+			}
+			stat = &models.AcknowledgementGroupStats{GroupID: groupID, Name: name}
+			stats[groupID] = stat
+		}
+		stat.Audience++
+		if acknowledged {
+			stat.Acknowledged++
+		} else {
+			stat.Pending++
+		}
+	}
+
+	filtered := make([]models.AcknowledgementReportUser, 0, len(users))
+	for _, user := range users {
+		acknowledged := user.AcknowledgedAt != nil
+		if acknowledged {
+			report.Acknowledged++
+		}
+
+		user.Groups = []string{}
+		inGroup := false
+		for _, groupID := range userGroups[user.ID] {
+			user.Groups = append(user.Groups, groupNames[groupID])
+			count(groupID, acknowledged)
+			inGroup = inGroup || (filter.GroupID != nil && *filter.GroupID == groupID)
+		}
+		if len(userGroups[user.ID]) == 0 {
+			count(0, acknowledged)
+			inGroup = filter.GroupID != nil && *filter.GroupID == 0
+		}
+
+		if filter.GroupID != nil && !inGroup {
+			continue
+		}
+		if (filter.Status == AckStatusAcknowledged && !acknowledged) || (filter.Status == AckStatusPending && acknowledged) {
+			continue
+		}
+		filtered = append(filtered, user)
+	}
+
+	report.Pending = report.Audience - report.Acknowledged
+	report.Rate = ackRate(report.Acknowledged, report.Audience)
+	report.Overdue = item.DueAt != nil && item.DueAt.Before(now) && report.Pending > 0
+
+	report.Groups = make([]models.AcknowledgementGroupStats, 0, len(stats))
+	for _, stat := range stats {
+		stat.Rate = ackRate(stat.Acknowledged, stat.Audience)
+		report.Groups = append(report.Groups, *stat)
+	}
+	// Groupes par ordre alphabétique, utilisateurs sans groupe en dernier
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if (a.GroupID == 0) != (b.GroupID == 0) {
+			return b.GroupID == 0
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+
+	report.Total = len(filtered)
+	report.Users = filtered
+	if filter.PageSize > 0 {
+		page := filter.Page
+		if page < 1 {
+			page = 1
+		}
+		start := (page - 1) * filter.PageSize
+		if start > len(filtered) {
+			start = len(filtered)
+		}
+		end := start + filter.PageSize
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		report.Users = filtered[start:end]
+		report.Page = page
+		report.PageSize = filter.PageSize
+	}
+	return report, nil
+}
+
+// ackRate pourcentage d'accusés de lecture, arrondi au dixième
+func ackRate(acknowledged, audience int) float64 {
+	if audience == 0 {
+		return 0
+	}
+	return math.Round(float64(acknowledged)*1000/float64(audience)) / 10
+}
+
+// ============ RELANCES ============
+
+// Remind relance immédiatement les utilisateurs ciblés n'ayant pas accusé lecture (notification et email)
+func (s *AcknowledgementService) Remind(entityType string, id uint) (*models.AcknowledgementReminderResult, error) {
+	item, err := s.item(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	if !item.RequiresAck || !item.Visible {
+		return nil, ErrAckNotRequired
+	}
+	if item.RemindedAt != nil && time.Since(*item.RemindedAt) < ackReminderCooldown {
+		return nil, ErrAckReminderTooSoon
+	}
+
+	now := time.Now()
+	if err := s.db.Table(item.table()).Where("id = ?", item.ID).Update("ack_reminded_at", now).Error; err != nil {
+		return nil, err
+	}
+	notified, err := s.remind(item)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AcknowledgementReminderResult{Notified: notified, RemindedAt: now}, nil
+}
+
+// RemindDue relance, à l'échéance, les utilisateurs n'ayant pas accusé lecture. Une relance manuelle
+// antérieure à l'échéance n'en dispense pas ; repousser l'échéance programme une nouvelle relance.
+func (s *AcknowledgementService) RemindDue() int {
+	now := time.Now()
+	reminded := 0
+
+	for _, entityType := range []string{models.AckEntityNews, models.AckEntityAnnouncement} {
+		table := (&ackItem{Type: entityType}).table()
+		due := "deleted_at IS NULL AND requires_ack = ? AND ack_due_at <= ? AND (ack_reminded_at IS NULL OR ack_reminded_at < ack_due_at)"
+
+		var ids []uint
+		s.db.Table(table).Where(due, true, now).Pluck("id", &ids)
+		for _, id := range ids {
+			// Contenu non visible (non publié, annonce inactive ou hors période) : relancé une fois diffusé
+			item, err := s.item(entityType, id)
+			if err != nil || !item.Visible {
+				continue
+			}
+			// Réservation optimiste : une seule instance relance
+			result := s.db.Table(table).Where("id = ? AND "+due, id, true, now).Update("ack_reminded_at", now)
+			if result.Error != nil || result.RowsAffected == 0 {
+				continue
+			}
+			if _, err := s.remind(item); err != nil {
+				log.Printf("[Acknowledgement] Erreur relance %s %d: %v", entityType, id, err)
+				continue
+			}
+			reminded++
+		}
+	}
+	return reminded
+}
+
+// StartScheduler lance les relances à échéance (vérification toutes les 15 minutes)
+func (s *AcknowledgementService) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(ackSchedulerPeriod)
+		defer ticker.Stop()
+		for range ticker.C {
+			if count := s.RemindDue(); count > 0 {
+				log.Printf("[Acknowledgement] %d contenu(s) à lecture obligatoire relancé(s)", count)
+			}
+		}
+	}()
+}
+
+// remind notifie les utilisateurs ciblés n'ayant pas accusé lecture et leur envoie un email (en arrière-plan)
+func (s *AcknowledgementService) remind(item *ackItem) (int, error) {
+	var users []models.User
+	if err := s.audience(item).
+		Select("u.id, u.username, u.first_name, u.last_name, u.email").
+		Where("NOT EXISTS (SELECT 1 FROM content_acknowledgements ca WHERE ca.entity_type = ? AND ca.entity_id = ? AND ca.user_id = u.id)", item.Type, item.ID).
+		Scan(&users).Error; err != nil {
+		return 0, err
+	}
+	if len(users) == 0 {
+		return 0, nil
+	}
+
+	userIDs := make([]uint, len(users))
+	for i := range users {
+		userIDs[i] = users[i].ID
+	}
+	if err := s.notifications.NotifyAcknowledgementReminder(item.Type, item.Title, item.path(), item.DueAt, userIDs); err != nil {
+		return 0, err
+	}
+
+	go s.emailReminders(item, users)
+	return len(users), nil
+}
+
+// emailReminders envoie l'email de relance à chaque utilisateur et journalise l'envoi
+func (s *AcknowledgementService) emailReminders(item *ackItem, users []models.User) {
+	var smtpConfig models.SMTPConfig
+	if err := s.db.First(&smtpConfig).Error; err != nil || !smtpConfig.IsEnabled {
+		log.Println("[Acknowledgement] Email non configuré ou désactivé, relance par email ignorée")
+		return
+	}
+
+	var emailTemplate models.EmailTemplate
+	if err := s.db.Where("type = ? AND is_enabled = ?", models.EmailTemplateAckReminder, true).First(&emailTemplate).Error; err != nil {
+		log.Printf("[Acknowledgement] Template '%s' non trouvé ou désactivé, relance par email ignorée", models.EmailTemplateAckReminder)
+		return
+	}
+
+	appName := "Airboard"
+	var settings models.AppSettings
+	if err := s.db.First(&settings).Error; err == nil && settings.AppName != "" {
+		appName = settings.AppName
+	}
+
+	now := time.Now()
+	data := AcknowledgementReminderEmailData{
+		Title:   item.Title,
+		Link:    s.cfg.Server.PublicURL + item.path(),
+		AppName: appName,
+	}
+	if item.DueAt != nil {
+		data.DueAt = item.DueAt.Format("02/01/2006 à 15:04")
+		data.Overdue = item.DueAt.Before(now)
+	}
+
+	notifLog := models.EmailNotificationLog{
+		TemplateType: item.Type + "_acknowledgement_reminder",
+		ContentID:    item.ID,
+		ContentTitle: item.Title,
+		Status:       "sending",
+		SentAt:       &now,
+	}
+	s.db.Create(&notifLog)
+
+	emailService := NewEmailService(s.db, s.cfg)
+	var lastError string
+	for _, user := range users {
+		if user.Email == "" {
+			continue
+		}
+		notifLog.RecipientCount++
+
+		name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+		if name == "" {
+			name = user.Username
+		}
+		data.Name = name
+		subject, err := emailService.ExecuteTemplate(emailTemplate.Subject, data)
+		if err != nil {
+			notifLog.Status = "failed"
+			notifLog.ErrorMessage = "Erreur template sujet: " + err.Error()
+			s.db.Save(&notifLog)
+			return
+		}
+		body, err := emailService.ExecuteTemplate(emailTemplate.HTMLBody, data)
+		if err != nil {
+			notifLog.Status = "failed"
+			notifLog.ErrorMessage = "Erreur template corps: " + err.Error()
+			s.db.Save(&notifLog)
+			return
+		}
+
+		if err := emailService.SendDirect(user.Email, subject, body); err != nil {
+			log.Printf("[Acknowledgement] Relance non envoyée à %s: %v", user.Email, err)
+			notifLog.FailureCount++
+			lastError = err.Error()
+		} else {
+			notifLog.SuccessCount++
+		}
+	}
+
+	completedAt := time.Now()
+	notifLog.CompletedAt = &completedAt
+	notifLog.Status = "completed"
+	if notifLog.FailureCount > 0 && notifLog.SuccessCount == 0 {
+		notifLog.Status = "failed"
+		notifLog.ErrorMessage = lastError
+	} else if notifLog.FailureCount > 0 {
+		notifLog.ErrorMessage = fmt.Sprintf("%d échecs sur %d", notifLog.FailureCount, notifLog.RecipientCount)
+	}
+	s.db.Save(&notifLog)
+}
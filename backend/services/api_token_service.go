@@ -0,0 +1,104 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPITokenInvalid  = errors.New("jeton d'API invalide")
+	ErrAPITokenExpired  = errors.New("jeton d'API révoqué ou expiré")
+	ErrAPITokenDisabled = errors.New("compte du jeton d'API désactivé")
+)
+
+// APITokenService émet et vérifie les jetons d'accès personnels et de comptes de service
+type APITokenService struct {
+	db *gorm.DB
+}
+
+// NewAPITokenService crée une nouvelle instance de APITokenService
+func NewAPITokenService(db *gorm.DB) *APITokenService {
+	return &APITokenService{db: db}
+}
+
+// HashAPIToken retourne l'empreinte SHA-256 stockée en base pour un jeton d'API
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Issue crée un jeton pour le propriétaire donné ; la valeur en clair n'est retournée qu'une seule fois
+func (s *APITokenService) Issue(ownerID uint, name string, scopes []string, expiresInDays int, createdByID uint) (string, *models.APIToken, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	plain := models.APITokenPrefix + secret
+
+	token := &models.APIToken{
+		Name:        name,
+		TokenHash:   HashAPIToken(plain),
+		TokenPrefix: plain[:12],
+		UserID:      ownerID,
+		Scopes:      strings.Join(scopes, ","),
+		CreatedByID: createdByID,
+	}
+	if expiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, expiresInDays)
+		token.ExpiresAt = &expires
+	}
+
+	if err := s.db.Create(token).Error; err != nil {
+		return "", nil, err
+	}
+	return plain, token, nil
+}
+
+// Authenticate vérifie un jeton et retourne son propriétaire.
+// La date et l'adresse de dernière utilisation sont mises à jour au plus une fois par minute.
+func (s *APITokenService) Authenticate(raw, clientIP string) (*models.APIToken, *models.User, error) {
+	var token models.APIToken
+	if err := s.db.Where("token_hash = ?", HashAPIToken(raw)).First(&token).Error; err != nil {
+		return nil, nil, ErrAPITokenInvalid
+	}
+
+	now := time.Now()
+	if !token.IsValid(now) {
+		return nil, nil, ErrAPITokenExpired
+	}
+
+	var user models.User
+	if err := s.db.First(&user, token.UserID).Error; err != nil || !user.IsActive {
+		return nil, nil, ErrAPITokenDisabled
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > time.Minute || token.LastUsedIP != clientIP {
+		s.db.Model(&token).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": clientIP})
+	}
+
+	return &token, &user, nil
+}
+
+// Revoke révoque un jeton
+func (s *APITokenService) Revoke(token *models.APIToken) error {
+	if token.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	return s.db.Model(token).Update("revoked_at", now).Error
+}
+
+// RevokeAllForUser révoque tous les jetons actifs d'un utilisateur ou compte de service
+func (s *APITokenService) RevokeAllForUser(userID uint) error {
+	return s.db.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
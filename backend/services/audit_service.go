@@ -0,0 +1,247 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+// Champs masqués dans les snapshots du journal d'audit
+var auditSensitiveKeys = []string{"password", "secret", "token", "private_key", "api_key", "certificate_key"}
+
+// Champs ignorés lors du calcul des différences
+var auditIgnoredKeys = map[string]bool{"updated_at": true, "created_at": true}
+
+// AuditChange différence d'un champ entre l'état avant et après modification
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditService enregistre et consulte le journal d'audit
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService crée une nouvelle instance de AuditService
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record ajoute une entrée au journal ; les différences sont calculées à partir des snapshots before/after
+func (s *AuditService) Record(entry *models.AuditLog) {
+	if entry.Before != "" && entry.After != "" && entry.Changes == "" {
+		if changes := ComputeAuditChanges(entry.Before, entry.After); len(changes) > 0 {
+			if data, err := json.Marshal(changes); err == nil {
+				entry.Changes = string(data)
+			}
+		}
+	}
+	if len(entry.UserAgent) > 500 {
+		entry.UserAgent = entry.UserAgent[:500]
+	}
+
+	if err := s.db.Create(entry).Error; err != nil {
+		log.Printf("[AUDIT] Erreur enregistrement %s: %v", entry.Action, err)
+	}
+}
+
+// AuditSnapshot sérialise une valeur en JSON en masquant les champs sensibles
+func AuditSnapshot(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		raw = data
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return ""
+	}
+	data, err := json.Marshal(redactAuditValue(decoded))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// redactAuditValue masque récursivement les valeurs des champs sensibles
+func redactAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isSensitiveAuditKey(key) {
+				if child != nil && child != "" {
+					v[key] = "[REDACTED]"
+				}
+				continue
+			}
+			v[key] = redactAuditValue(child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactAuditValue(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitiveAuditKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range auditSensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputeAuditChanges compare deux snapshots JSON champ par champ.
+// Seuls les champs présents dans "after" sont comparés, ce qui permet d'utiliser
+// les données soumises comme état après modification.
+func ComputeAuditChanges(before, after string) map[string]AuditChange {
+	var beforeMap, afterMap map[string]interface{}
+	if json.Unmarshal([]byte(before), &beforeMap) != nil || json.Unmarshal([]byte(after), &afterMap) != nil {
+		return nil
+	}
+
+	changes := make(map[string]AuditChange)
+	for key, newValue := range afterMap {
+		if auditIgnoredKeys[key] {
+			continue
+		}
+		oldValue, exists := beforeMap[key]
+		if exists && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes[key] = AuditChange{Old: oldValue, New: newValue}
+	}
+	return changes
+}
+
+// Query retourne les entrées correspondant au filtre, paginées, ainsi que le total
+func (s *AuditService) Query(filter models.AuditLogFilter, offset, limit int) ([]models.AuditLog, int64, error) {
+	query := s.filtered(filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AuditLog
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&logs).Error
+	return logs, total, err
+}
+
+// Each parcourt les entrées correspondant au filtre par lots de 500, dans la limite donnée (export)
+func (s *AuditService) Each(filter models.AuditLogFilter, limit int, fn func(models.AuditLog) error) error {
+	const batchSize = 500
+	for offset := 0; offset < limit; offset += batchSize {
+		size := batchSize
+		if limit-offset < size {
+			size = limit - offset
+		}
+
+		var batch []models.AuditLog
+		if err := s.filtered(filter).Order("created_at DESC, id DESC").Offset(offset).Limit(size).Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, entry := range batch {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(batch) < size {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *AuditService) filtered(filter models.AuditLogFilter) *gorm.DB {
+	query := s.db.Model(&models.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ? OR action LIKE ?", filter.Action, filter.Action+".%")
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("actor_username ILIKE ? OR target_label ILIKE ? OR details ILIKE ? OR path ILIKE ?",
+			pattern, pattern, pattern, pattern)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	return query
+}
+
+// PurgeExpired supprime les entrées plus anciennes que la durée de rétention configurée
+func (s *AuditService) PurgeExpired() (int64, error) {
+	var settings models.AppSettings
+	retentionDays := 365
+	if err := s.db.First(&settings).Error; err == nil {
+		retentionDays = settings.AuditRetentionDays
+	}
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	result := s.db.Where("created_at < ?", cutoff).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// StartRetentionScheduler purge le journal une fois par jour
+func (s *AuditService) StartRetentionScheduler() {
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			if purged, err := s.PurgeExpired(); err != nil {
+				log.Printf("[AUDIT] Erreur purge du journal: %v", err)
+			} else if purged > 0 {
+				log.Printf("[AUDIT] %d entrées expirées supprimées", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
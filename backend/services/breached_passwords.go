@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedPasswordList liste hors ligne de mots de passe compromis, au format k-anonymat "Pwned Passwords"
+// (empreintes SHA-1 en hexadécimal majuscule, découpées en un préfixe de 5 caractères et un suffixe de 35).
+// Aucune requête n'est envoyée à un service externe. Le chemin configuré peut désigner :
+//   - un répertoire de fichiers de plage nommés par préfixe (ABCDE ou ABCDE.txt) contenant des lignes
+//     "SUFFIXE:occurrences" : seul le fichier du préfixe concerné est lu à chaque vérification ;
+//   - un fichier contenant une empreinte complète par ligne ("EMPREINTE" ou "EMPREINTE:occurrences"),
+//     chargé en mémoire et indexé par préfixe (adapté aux listes réduites, ex. le million le plus courant).
+type BreachedPasswordList struct {
+	dir      string
+	prefixes map[string]map[string]struct{}
+	size     int
+}
+
+// LoadBreachedPasswordList charge la liste depuis un répertoire de plages ou un fichier d'empreintes
+func LoadBreachedPasswordList(path string) (*BreachedPasswordList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		list := &BreachedPasswordList{dir: path}
+		for _, entry := range entries {
+			if !entry.IsDir() && isHashPrefix(strings.TrimSuffix(entry.Name(), ".txt")) {
+				list.size++
+			}
+		}
+		if list.size == 0 {
+			return nil, fmt.Errorf("aucun fichier de plage (préfixe SHA-1 de 5 caractères) dans %s", path)
+		}
+		return list, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &BreachedPasswordList{prefixes: make(map[string]map[string]struct{})}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != 40 || !isHashPrefix(hash[:5]) {
+			continue
+		}
+		suffixes, ok := list.prefixes[hash[:5]]
+		if !ok {
+			suffixes = make(map[string]struct{})
+			list.prefixes[hash[:5]] = suffixes
+		}
+		suffixes[hash[5:]] = struct{}{}
+		list.size++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if list.size == 0 {
+		return nil, fmt.Errorf("aucune empreinte SHA-1 valide dans %s", path)
+	}
+	return list, nil
+}
+
+// Size retourne le nombre d'empreintes chargées (ou de fichiers de plage pour un répertoire)
+func (l *BreachedPasswordList) Size() int {
+	return l.size
+}
+
+// Contains indique si le mot de passe figure dans la liste
+func (l *BreachedPasswordList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	if l.prefixes != nil {
+		_, found := l.prefixes[prefix][suffix]
+		return found, nil
+	}
+
+	file, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if os.IsNotExist(err) {
+		file, err = os.Open(filepath.Join(l.dir, prefix))
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		candidate, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(candidate, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// isHashPrefix vérifie qu'une chaîne est un préfixe hexadécimal de 5 caractères
+func isHashPrefix(s string) bool {
+	if len(s) != 5 {
+		return false
+	}
+	_, err := hex.DecodeString(s + "0")
+	return err == nil
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+// Pagination et facettes de l'annuaire
+const (
+	DirectoryDefaultPageSize = 24
+	DirectoryMaxPageSize     = 100
+	directoryFacetLimit      = 50
+)
+
+// Facettes de l'annuaire (paramètre exclu du calcul de son propre compteur)
+const (
+	directoryFacetDepartment = "department"
+	directoryFacetLocation   = "location"
+	directoryFacetGroup      = "group"
+)
+
+var (
+	ErrDirectoryPersonNotFound  = errors.New("Personne introuvable dans l'annuaire")
+	ErrInvalidProfileVisibility = errors.New("Visibilité invalide (public, groups ou private)")
+)
+
+// Un groupe commun entre la personne affichée (u) et l'utilisateur qui consulte
+const directorySharesGroupSQL = `EXISTS (SELECT 1 FROM user_groups ug_person JOIN user_groups ug_viewer ON ug_viewer.group_id = ug_person.group_id
+	WHERE ug_person.user_id = u.id AND ug_viewer.user_id = ?)`
+
+// DirectoryViewer utilisateur qui consulte l'annuaire
+type DirectoryViewer struct {
+	UserID  uint
+	IsAdmin bool // Voit tous les profils et tous les champs
+}
+
+// DirectoryParams recherche et filtres de l'annuaire
+type DirectoryParams struct {
+	Query      string
+	Department string
+	Location   string
+	GroupID    uint
+	Page       int
+	PageSize   int
+}
+
+// DirectoryService annuaire des collaborateurs : recherche, facettes et fiches filtrées par les réglages de confidentialité
+type DirectoryService struct {
+	db *gorm.DB
+}
+
+// NewDirectoryService crée une nouvelle instance de DirectoryService
+func NewDirectoryService(db *gorm.DB) *DirectoryService {
+	return &DirectoryService{db: db}
+}
+
+// ============ VISIBILITÉ ============
+
+// directoryListedSQL personnes présentes dans l'annuaire pour l'utilisateur (alias u, réglages pp) :
+// comptes actifs non techniques, ayant accepté d'y figurer
+func directoryListedSQL(viewer DirectoryViewer) (string, []interface{}) {
+	sql := "u.deleted_at IS NULL AND u.is_active = true AND u.is_service_account = false"
+	if viewer.IsAdmin {
+		return sql, nil
+	}
+	return sql + " AND (u.id = ? OR COALESCE(pp.listed_in_directory, true))", []interface{}{viewer.UserID}
+}
+
+// profileFieldVisibleSQL condition de visibilité d'un champ du profil (alias u, réglages pp)
+func profileFieldVisibleSQL(field string, viewer DirectoryViewer) (string, []interface{}) {
+	if viewer.IsAdmin {
+		return "TRUE", nil
+	}
+	column := fmt.Sprintf("COALESCE(pp.%s_visibility, '%s')", field, models.ProfileFields[field])
+	sql := fmt.Sprintf("(u.id = ? OR %s = '%s' OR (%s = '%s' AND %s))",
+		column, models.ProfileVisibilityPublic, column, models.ProfileVisibilityGroups, directorySharesGroupSQL)
+	return sql, []interface{}{viewer.UserID, viewer.UserID}
+}
+
+// fieldVisible applique en Go la même règle que profileFieldVisibleSQL
+func fieldVisible(privacy models.ProfilePrivacy, field string, viewer DirectoryViewer, personID uint, sharesGroup bool) bool {
+	if viewer.IsAdmin || viewer.UserID == personID {
+		return true
+	}
+	switch privacy.Visibility(field) {
+	case models.ProfileVisibilityPublic:
+		return true
+	case models.ProfileVisibilityGroups:
+		return sharesGroup
+	}
+	return false
+}
+
+// ============ ANNUAIRE ============
+
+// filtered requête des personnes visibles correspondant aux filtres, sauf celui de la facette exclude
+func (s *DirectoryService) filtered(params DirectoryParams, viewer DirectoryViewer, exclude string) *gorm.DB {
+	listed, listedArgs := directoryListedSQL(viewer)
+	query := s.db.Table("users u").
+		Joins("LEFT JOIN profile_privacies pp ON pp.user_id = u.id").
+		Where(listed, listedArgs...)
+
+	if params.Query != "" {
+		emailVisible, emailArgs := profileFieldVisibleSQL(models.ProfileFieldEmail, viewer)
+		jobVisible, jobArgs := profileFieldVisibleSQL(models.ProfileFieldJobTitle, viewer)
+		departmentVisible, departmentArgs := profileFieldVisibleSQL(models.ProfileFieldDepartment, viewer)
+		for _, term := range strings.Fields(strings.ToLower(params.Query)) {
+			pattern := "%" + escapeLike(term) + "%"
+			args := []interface{}{pattern, pattern, pattern}
+			args = append(append(args, emailArgs...), pattern)
+			args = append(append(args, jobArgs...), pattern)
+			args = append(append(args, departmentArgs...), pattern)
+			query = query.Where("(lower(coalesce(u.first_name, '') || ' ' || coalesce(u.last_name, '')) LIKE ? OR lower(coalesce(u.last_name, '') || ' ' || coalesce(u.first_name, '')) LIKE ? OR lower(u.username) LIKE ?"+
+				" OR ("+emailVisible+" AND lower(u.email) LIKE ?)"+
+				" OR ("+jobVisible+" AND lower(coalesce(u.job_title, '')) LIKE ?)"+
+				" OR ("+departmentVisible+" AND lower(coalesce(u.department, '')) LIKE ?))", args...)
+		}
+	}
+	// Un filtre sur un champ masqué ne doit pas révéler sa valeur
+	if params.Department != "" && exclude != directoryFacetDepartment {
+		visible, args := profileFieldVisibleSQL(models.ProfileFieldDepartment, viewer)
+		query = query.Where("u.department = ? AND "+visible, append([]interface{}{params.Department}, args...)...)
+	}
+	if params.Location != "" && exclude != directoryFacetLocation {
+		visible, args := profileFieldVisibleSQL(models.ProfileFieldLocation, viewer)
+		query = query.Where("u.location = ? AND "+visible, append([]interface{}{params.Location}, args...)...)
+	}
+	if params.GroupID != 0 && exclude != directoryFacetGroup {
+		query = query.Where("EXISTS (SELECT 1 FROM user_groups ug WHERE ug.user_id = u.id AND ug.group_id = ?)", params.GroupID)
+	}
+	return query
+}
+
+// Search retourne une page de l'annuaire et les compteurs de chaque filtre
+func (s *DirectoryService) Search(params DirectoryParams, viewer DirectoryViewer) (*models.DirectoryResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 {
+		params.PageSize = DirectoryDefaultPageSize
+	}
+	if params.PageSize > DirectoryMaxPageSize {
+		params.PageSize = DirectoryMaxPageSize
+	}
+
+	response := &models.DirectoryResponse{
+		People:   []models.DirectoryPerson{},
+		Page:     params.Page,
+		PageSize: params.PageSize,
+	}
+	if err := s.filtered(params, viewer, "").Count(&response.Total).Error; err != nil {
+		return nil, err
+	}
+	response.TotalPages = int((response.Total + int64(params.PageSize) - 1) / int64(params.PageSize))
+
+	var ids []uint
+	if err := s.filtered(params, viewer, "").
+		Order("lower(u.last_name), lower(u.first_name), u.id").
+		Limit(params.PageSize).Offset((params.Page-1)*params.PageSize).
+		Pluck("u.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	people, err := s.People(ids, viewer)
+	if err != nil {
+		return nil, err
+	}
+	response.People = people
+
+	if response.Facets, err = s.facets(params, viewer); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// facets compteurs par département, site et groupe
+func (s *DirectoryService) facets(params DirectoryParams, viewer DirectoryViewer) (models.DirectoryFacets, error) {
+	facets := models.DirectoryFacets{}
+	var err error
+	if facets.Departments, err = s.valueFacet(params, viewer, directoryFacetDepartment, models.ProfileFieldDepartment, "u.department"); err != nil {
+		return facets, err
+	}
+	if facets.Locations, err = s.valueFacet(params, viewer, directoryFacetLocation, models.ProfileFieldLocation, "u.location"); err != nil {
+		return facets, err
+	}
+
+	facets.Groups = []models.DirectoryFacet{}
+	err = s.filtered(params, viewer, directoryFacetGroup).
+		Joins("JOIN user_groups ugf ON ugf.user_id = u.id").
+		Joins("JOIN groups g ON g.id = ugf.group_id AND g.deleted_at IS NULL AND g.is_active = true").
+		Select("CAST(g.id AS text) AS value, g.name AS label, count(*) AS count").
+		Group("g.id, g.name").Order("count DESC, label").Limit(directoryFacetLimit).
+		Scan(&facets.Groups).Error
+	return facets, err
+}
+
+// valueFacet compteur d'un champ texte du profil, limité aux valeurs visibles
+func (s *DirectoryService) valueFacet(params DirectoryParams, viewer DirectoryViewer, facet, field, column string) ([]models.DirectoryFacet, error) {
+	visible, args := profileFieldVisibleSQL(field, viewer)
+	values := []models.DirectoryFacet{}
+	err := s.filtered(params, viewer, facet).
+		Where(visible, args...).
+		Where("COALESCE(" + column + ", '') <> ''").
+		Select(column + " AS value, count(*) AS count").
+		Group(column).Order("count DESC, value").Limit(directoryFacetLimit).
+		Scan(&values).Error
+	return values, err
+}
+
+// People construit les fiches des personnes données (dans l'ordre des identifiants), champs masqués vidés
+func (s *DirectoryService) People(ids []uint, viewer DirectoryViewer) ([]models.DirectoryPerson, error) {
+	people := []models.DirectoryPerson{}
+	if len(ids) == 0 {
+		return people, nil
+	}
+
+	var users []models.User
+	if err := s.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	var privacies []models.ProfilePrivacy
+	s.db.Where("user_id IN ?", ids).Find(&privacies)
+	privacyByUser := make(map[uint]models.ProfilePrivacy, len(privacies))
+	for _, privacy := range privacies {
+		privacyByUser[privacy.UserID] = privacy
+	}
+
+	// Groupes communs avec l'utilisateur qui consulte (visibilité « groups »)
+	var viewerGroupIDs []uint
+	s.db.Table("user_groups").Where("user_id = ?", viewer.UserID).Pluck("group_id", &viewerGroupIDs)
+	var shared []uint
+	if len(viewerGroupIDs) > 0 {
+		s.db.Table("user_groups").Where("user_id IN ? AND group_id IN ?", ids, viewerGroupIDs).Distinct().Pluck("user_id", &shared)
+	}
+	sharesGroup := make(map[uint]bool, len(shared))
+	for _, id := range shared {
+		sharesGroup[id] = true
+	}
+
+	type groupRow struct {
+		UserID uint
+		models.DirectoryGroup
+	}
+	var groupRows []groupRow
+	s.db.Table("user_groups ug").
+		Select("ug.user_id, g.id, g.name, g.color").
+		Joins("JOIN groups g ON g.id = ug.group_id AND g.deleted_at IS NULL AND g.is_active = true").
+		Where("ug.user_id IN ?", ids).Order("g.name").
+		Scan(&groupRows)
+	groupsByUser := make(map[uint][]models.DirectoryGroup)
+	for _, row := range groupRows {
+		groupsByUser[row.UserID] = append(groupsByUser[row.UserID], row.DirectoryGroup)
+	}
+
+	for _, id := range ids {
+		user, ok := byID[id]
+		if !ok {
+			continue
+		}
+		privacy, ok := privacyByUser[id]
+		if !ok {
+			privacy = models.DefaultProfilePrivacy(id)
+		}
+		visible := func(field string) bool {
+			return fieldVisible(privacy, field, viewer, id, sharesGroup[id])
+		}
+
+		person := models.DirectoryPerson{
+			ID:        user.ID,
+			Username:  user.Username,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Groups:    groupsByUser[id],
+		}
+		if person.Groups == nil {
+			person.Groups = []models.DirectoryGroup{}
+		}
+		if visible(models.ProfileFieldEmail) {
+			person.Email = user.Email
+		}
+		if visible(models.ProfileFieldDepartment) {
+			person.Department = user.Department
+		}
+		if visible(models.ProfileFieldJobTitle) {
+			person.JobTitle = user.JobTitle
+		}
+		if visible(models.ProfileFieldLocation) {
+			person.Location = user.Location
+		}
+		if visible(models.ProfileFieldPhone) {
+			person.Phone = user.Phone
+		}
+		if visible(models.ProfileFieldAvatar) {
+			person.AvatarURL = user.AvatarURL
+		}
+		people = append(people, person)
+	}
+	return people, nil
+}
+
+// Profile fiche publique d'une personne visible dans l'annuaire
+func (s *DirectoryService) Profile(id uint, viewer DirectoryViewer) (*models.DirectoryProfile, error) {
+	var count int64
+	if err := s.filtered(DirectoryParams{}, viewer, "").Where("u.id = ?", id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrDirectoryPersonNotFound
+	}
+	people, err := s.People([]uint{id}, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if len(people) == 0 {
+		return nil, ErrDirectoryPersonNotFound
+	}
+
+	profile := &models.DirectoryProfile{DirectoryPerson: people[0]}
+	if viewer.UserID == id {
+		privacy := s.Privacy(id)
+		profile.Privacy = &privacy
+	}
+	return profile, nil
+}
+
+// Contacts personnes proposées dans la messagerie : fiches de l'annuaire et membres des groupes de l'utilisateur
+func (s *DirectoryService) Contacts(viewer DirectoryViewer) ([]models.DirectoryPerson, error) {
+	listed, args := directoryListedSQL(viewer)
+	var ids []uint
+	if err := s.db.Table("users u").
+		Joins("LEFT JOIN profile_privacies pp ON pp.user_id = u.id").
+		Where("u.id <> ?", viewer.UserID).
+		Where("("+listed+") OR (u.deleted_at IS NULL AND u.is_active = true AND u.is_service_account = false AND "+directorySharesGroupSQL+")",
+			append(args, viewer.UserID)...).
+		Order("lower(u.last_name), lower(u.first_name), u.id").
+		Pluck("u.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return s.People(ids, viewer)
+}
+
+// ============ CONFIDENTIALITÉ ============
+
+// Privacy réglages de confidentialité d'un utilisateur (valeurs par défaut s'il n'a rien configuré)
+func (s *DirectoryService) Privacy(userID uint) models.ProfilePrivacy {
+	var privacy models.ProfilePrivacy
+	if err := s.db.Where("user_id = ?", userID).First(&privacy).Error; err != nil {
+		return models.DefaultProfilePrivacy(userID)
+	}
+	return privacy
+}
+
+// UpdatePrivacy modifie les réglages de confidentialité d'un utilisateur
+func (s *DirectoryService) UpdatePrivacy(userID uint, req models.UpdateProfilePrivacyRequest) (models.ProfilePrivacy, error) {
+	privacy := s.Privacy(userID)
+	if req.ListedInDirectory != nil {
+		privacy.ListedInDirectory = *req.ListedInDirectory
+	}
+	for _, update := range []struct {
+		value  string
+		target *string
+	}{
+		{req.EmailVisibility, &privacy.EmailVisibility},
+		{req.DepartmentVisibility, &privacy.DepartmentVisibility},
+		{req.JobTitleVisibility, &privacy.JobTitleVisibility},
+		{req.LocationVisibility, &privacy.LocationVisibility},
+		{req.PhoneVisibility, &privacy.PhoneVisibility},
+		{req.AvatarVisibility, &privacy.AvatarVisibility},
+	} {
+		if update.value == "" {
+			continue
+		}
+		if !models.IsValidProfileVisibility(update.value) {
+			return privacy, ErrInvalidProfileVisibility
+		}
+		*update.target = update.value
+	}
+
+	if err := s.db.Save(&privacy).Error; err != nil {
+		return privacy, err
+	}
+	return privacy, nil
+}
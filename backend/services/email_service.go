@@ -17,6 +17,7 @@ import (
 
 	"airboard/config"
 	"airboard/models"
+	"airboard/utils"
 
 	"gorm.io/gorm"
 )
@@ -38,7 +39,10 @@ func NewEmailService(db *gorm.DB, cfg *config.Config) *EmailService {
 // NewsEmailData contient les données pour le template news
 type NewsEmailData struct {
 	Title       string
-	Summary     string
+	Summary     string        // Résumé, ou début du contenu si l'article n'en a pas
+	Content     template.HTML // Contenu rendu en HTML assaini
+	ContentText string        // Contenu en texte brut
+	ReadingTime int           // Temps de lecture estimé (minutes)
 	Author      string
 	Link        string
 	AppName     string
@@ -56,13 +60,14 @@ type ApplicationEmailData struct {
 
 // EventEmailData contient les données pour le template event
 type EventEmailData struct {
-	Title       string
-	Description string
-	StartDate   string
-	EndDate     string
-	Location    string
-	Link        string
-	AppName     string
+	Title           string
+	Description     string        // Description en texte brut
+	DescriptionHTML template.HTML // Description rendue en HTML assaini
+	StartDate       string
+	EndDate         string
+	Location        string
+	Link            string
+	AppName         string
 }
 
 // AnnouncementEmailData contient les données pour le template announcement
@@ -73,6 +78,16 @@ type AnnouncementEmailData struct {
 	AppName string
 }
 
+// AcknowledgementReminderEmailData contient les données pour le template acknowledgement_reminder
+type AcknowledgementReminderEmailData struct {
+	Name    string // Nom du destinataire
+	Title   string
+	DueAt   string // Échéance de lecture, vide si aucune
+	Overdue bool   // Échéance dépassée à l'envoi de la relance
+	Link    string
+	AppName string
+}
+
 // SendNotification envoie des notifications email aux groupes cibles
 func (s *EmailService) SendNotification(templateType string, contentID uint, targetGroupIDs []uint) error {
 	// Récupérer la config SMTP avec la config OAuth si disponible
@@ -223,9 +238,16 @@ func (s *EmailService) prepareEmailData(templateType string, contentID uint) (in
 		} else {
 			publishedAt = time.Now().Format("02/01/2006 à 15:04")
 		}
+		summary := news.Summary
+		if strings.TrimSpace(summary) == "" {
+			summary = utils.TiptapExcerpt(news.Content, 300)
+		}
 		return NewsEmailData{
 			Title:       news.Title,
-			Summary:     news.Summary,
+			Summary:     summary,
+			Content:     template.HTML(utils.TiptapToHTML(news.Content, utils.TiptapHTMLOptions{BaseURL: s.config.Server.PublicURL})),
+			ContentText: utils.TiptapToText(news.Content),
+			ReadingTime: news.ReadingTime,
 			Author:      authorName,
 			Link:        fmt.Sprintf("%s/news/%s", s.config.Server.PublicURL, news.Slug),
 			AppName:     appName,
@@ -259,13 +281,14 @@ func (s *EmailService) prepareEmailData(templateType string, contentID uint) (in
 			endDate = event.EndDate.Format("02/01/2006 à 15:04")
 		}
 		return EventEmailData{
-			Title:       event.Title,
-			Description: event.Description,
-			StartDate:   event.StartDate.Format("02/01/2006 à 15:04"),
-			EndDate:     endDate,
-			Location:    event.Location,
-			Link:        fmt.Sprintf("%s/events/%s", s.config.Server.PublicURL, event.Slug),
-			AppName:     appName,
+			Title:           event.Title,
+			Description:     utils.TiptapToText(event.Description),
+			DescriptionHTML: template.HTML(utils.TiptapToHTML(event.Description, utils.TiptapHTMLOptions{BaseURL: s.config.Server.PublicURL})),
+			StartDate:       event.StartDate.Format("02/01/2006 à 15:04"),
+			EndDate:         endDate,
+			Location:        event.Location,
+			Link:            fmt.Sprintf("%s/events/%s", s.config.Server.PublicURL, event.Slug),
+			AppName:         appName,
 		}, event.Title, nil
 
 	case "announcement":
@@ -540,6 +563,9 @@ func (s *EmailService) GetSampleData(templateType string) interface{} {
 		return NewsEmailData{
 			Title:       "Exemple d'article",
 			Summary:     "Ceci est un résumé exemple pour prévisualiser le template d'email.",
+			Content:     template.HTML("<p>Ceci est le <strong>contenu</strong> exemple de l'article.</p>"),
+			ContentText: "Ceci est le contenu exemple de l'article.",
+			ReadingTime: 1,
 			Author:      "Jean Dupont",
 			Link:        fmt.Sprintf("%s/news/exemple-article", s.config.Server.PublicURL),
 			AppName:     appName,
@@ -555,13 +581,14 @@ func (s *EmailService) GetSampleData(templateType string) interface{} {
 		}
 	case "event":
 		return EventEmailData{
-			Title:       "Événement Exemple",
-			Description: "Ceci est une description exemple pour prévisualiser le template d'événement.",
-			StartDate:   time.Now().Format("02/01/2006 à 15:04"),
-			EndDate:     time.Now().Add(2 * time.Hour).Format("02/01/2006 à 15:04"),
-			Location:    "Salle de conférence A",
-			Link:        fmt.Sprintf("%s/events/exemple-evenement", s.config.Server.PublicURL),
-			AppName:     appName,
+			Title:           "Événement Exemple",
+			Description:     "Ceci est une description exemple pour prévisualiser le template d'événement.",
+			DescriptionHTML: template.HTML("<p>Ceci est une description exemple pour prévisualiser le template d'événement.</p>"),
+			StartDate:       time.Now().Format("02/01/2006 à 15:04"),
+			EndDate:         time.Now().Add(2 * time.Hour).Format("02/01/2006 à 15:04"),
+			Location:        "Salle de conférence A",
+			Link:            fmt.Sprintf("%s/events/exemple-evenement", s.config.Server.PublicURL),
+			AppName:         appName,
 		}
 	case "announcement":
 		return AnnouncementEmailData{
@@ -570,6 +597,27 @@ func (s *EmailService) GetSampleData(templateType string) interface{} {
 			Type:    "info",
 			AppName: appName,
 		}
+	case models.EmailTemplateAckReminder:
+		return AcknowledgementReminderEmailData{
+			Name:    "Jean Dupont",
+			Title:   "Charte informatique",
+			DueAt:   time.Now().Add(72 * time.Hour).Format("02/01/2006 à 15:04"),
+			Link:    fmt.Sprintf("%s/news/charte-informatique", s.config.Server.PublicURL),
+			AppName: appName,
+		}
 	}
 	return nil
 }
+
+// SendDirect envoie un email à un destinataire unique (alertes de sécurité, hors modèles de notification)
+func (s *EmailService) SendDirect(to, subject, htmlBody string) error {
+	var smtpConfig models.SMTPConfig
+	if err := s.db.Preload("EmailOAuthConfig").First(&smtpConfig).Error; err != nil {
+		return fmt.Errorf("SMTP non configuré: %w", err)
+	}
+	if !smtpConfig.IsEnabled {
+		log.Println("[Email] SMTP désactivé, email ignoré")
+		return nil
+	}
+	return s.sendEmail(&smtpConfig, to, subject, htmlBody)
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"airboard/config"
+	"airboard/models"
+	"airboard/utils"
+
+	"gorm.io/gorm"
+)
+
+// Taille des flux
+const (
+	FeedDefaultItems = 20
+	FeedMaxItems     = 50
+)
+
+var ErrFeedTokenInvalid = errors.New("jeton de flux invalide ou révoqué")
+
+// FeedService émet les jetons de flux et génère les flux RSS 2.0 et Atom des news
+type FeedService struct {
+	db          *gorm.DB
+	config      *config.Config
+	permissions *PermissionService
+}
+
+// NewFeedService crée une nouvelle instance de FeedService
+func NewFeedService(db *gorm.DB, cfg *config.Config, permissions *PermissionService) *FeedService {
+	return &FeedService{db: db, config: cfg, permissions: permissions}
+}
+
+// ============ JETONS ============
+
+// ActiveToken retourne le jeton de flux actif de l'utilisateur (nil s'il n'en a pas)
+func (s *FeedService) ActiveToken(userID uint) (*models.FeedToken, error) {
+	var token models.FeedToken
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL", userID).Order("created_at DESC").First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Issue crée un nouveau jeton de flux et révoque le précédent ; la valeur en clair n'est retournée qu'une seule fois
+func (s *FeedService) Issue(userID uint) (string, *models.FeedToken, error) {
+	secret, err := randomToken(24)
+	if err != nil {
+		return "", nil, err
+	}
+	plain := models.FeedTokenPrefix + secret
+
+	token := &models.FeedToken{
+		UserID:      userID,
+		TokenHash:   HashAPIToken(plain),
+		TokenPrefix: plain[:10],
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.FeedToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return plain, token, nil
+}
+
+// Revoke révoque le jeton de flux actif de l'utilisateur
+func (s *FeedService) Revoke(userID uint) error {
+	return s.db.Model(&models.FeedToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Authenticate vérifie un jeton de flux et retourne son propriétaire (compte actif uniquement).
+// La date et l'adresse de dernière utilisation sont mises à jour au plus une fois par heure.
+func (s *FeedService) Authenticate(raw, clientIP string) (*models.User, error) {
+	if !strings.HasPrefix(raw, models.FeedTokenPrefix) {
+		return nil, ErrFeedTokenInvalid
+	}
+
+	var token models.FeedToken
+	if err := s.db.Where("token_hash = ? AND revoked_at IS NULL", HashAPIToken(raw)).First(&token).Error; err != nil {
+		return nil, ErrFeedTokenInvalid
+	}
+
+	var user models.User
+	if err := s.db.First(&user, token.UserID).Error; err != nil || !user.IsActive {
+		return nil, ErrFeedTokenInvalid
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > time.Hour || token.LastUsedIP != clientIP {
+		s.db.Model(&token).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": clientIP})
+	}
+	return &user, nil
+}
+
+// FeedURL retourne l'URL publique d'un flux ("atom" ou "rss") pour un jeton
+func (s *FeedService) FeedURL(format, token string) string {
+	return fmt.Sprintf("%s/api/v1/feeds/news.%s?token=%s", strings.TrimRight(s.config.Server.PublicURL, "/"), format, token)
+}
+
+// ============ CONTENU ============
+
+// NewsFor retourne les news publiées visibles par l'utilisateur : globales ou ciblant ses groupes
+// (appartenance ou administration), toutes pour les détenteurs de news.manage
+func (s *FeedService) NewsFor(user *models.User, filter models.NewsFeedFilter) ([]models.News, error) {
+	now := time.Now()
+	query := s.db.Model(&models.News{}).
+		Preload("Author").
+		Preload("Category").
+		Preload("Tags").
+		Where("news.status = ? AND news.is_published = ?", models.NewsStatusPublished, true).
+		Where("(news.published_at IS NULL OR news.published_at <= ?)", now).
+		Where("(news.expires_at IS NULL OR news.expires_at > ?)", now)
+
+	var managedGroupIDs []uint
+	s.db.Table("group_admins").Where("user_id = ?", user.ID).Pluck("group_id", &managedGroupIDs)
+	if !s.permissions.Resolve(user.ID, user.Role, managedGroupIDs).Has(models.PermNewsManage) {
+		var groupIDs []uint
+		s.db.Table("user_groups").Where("user_id = ?", user.ID).Pluck("group_id", &groupIDs)
+		groupIDs = append(groupIDs, managedGroupIDs...)
+		if len(groupIDs) > 0 {
+			query = query.Where(`(NOT EXISTS (SELECT 1 FROM news_target_groups WHERE news_target_groups.news_id = news.id)
+				OR EXISTS (SELECT 1 FROM news_target_groups WHERE news_target_groups.news_id = news.id AND news_target_groups.group_id IN ?))`, groupIDs)
+		} else {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM news_target_groups WHERE news_target_groups.news_id = news.id)")
+		}
+	}
+
+	if filter.Category != "" {
+		if id, err := strconv.ParseUint(filter.Category, 10, 64); err == nil {
+			query = query.Where("news.category_id = ?", id)
+		} else {
+			query = query.Where("news.category_id IN (SELECT id FROM news_categories WHERE slug = ?)", filter.Category)
+		}
+	}
+	if filter.Type != "" {
+		query = query.Where("news.type = ?", filter.Type)
+	}
+	// Plusieurs tags : l'article doit les porter tous
+	for _, tag := range filter.Tags {
+		if id, err := strconv.ParseUint(tag, 10, 64); err == nil {
+			query = query.Where("EXISTS (SELECT 1 FROM news_tags WHERE news_tags.news_id = news.id AND news_tags.tag_id = ?)", id)
+		} else {
+			query = query.Where(`EXISTS (SELECT 1 FROM news_tags JOIN tags ON tags.id = news_tags.tag_id
+				WHERE news_tags.news_id = news.id AND tags.slug = ?)`, tag)
+		}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = FeedDefaultItems
+	}
+	if limit > FeedMaxItems {
+		limit = FeedMaxItems
+	}
+
+	var news []models.News
+	err := query.Order("news.published_at DESC NULLS LAST, news.id DESC").Limit(limit).Find(&news).Error
+	return news, err
+}
+
+// Validators retourne l'ETag et la date de dernière modification d'un flux : ils changent dès qu'un
+// article est publié, modifié ou retiré, ou que les filtres ou la visibilité du lecteur changent
+func (s *FeedService) Validators(userID uint, format, filterKey string, news []models.News) (string, time.Time) {
+	var lastModified time.Time
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%d|%s", format, userID, filterKey)
+	for _, n := range news {
+		fmt.Fprintf(h, "|%d:%d", n.ID, n.UpdatedAt.UnixNano())
+		if n.UpdatedAt.After(lastModified) {
+			lastModified = n.UpdatedAt
+		}
+	}
+	if lastModified.IsZero() {
+		lastModified = time.Unix(0, 0)
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`, lastModified.UTC().Truncate(time.Second)
+}
+
+// ============ RENDU ============
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"`
+	Self          rssSelf   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description"`
+	Content     string        `xml:"content:encoded,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+// feedEnclosure image de couverture : type et taille lus dans la médiathèque, à défaut déduits de l'extension
+type feedEnclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// RenderAtom génère un flux Atom 1.0
+func (s *FeedService) RenderAtom(news []models.News, selfURL string, updated time.Time) ([]byte, error) {
+	appName, siteURL := s.site()
+	feed := atomFeed{
+		Title:    appName + " - News",
+		Subtitle: "Actualités internes",
+		ID:       siteURL + "/news",
+		Updated:  updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Href: selfURL, Type: "application/atom+xml"},
+			{Rel: "alternate", Href: siteURL + "/news", Type: "text/html"},
+		},
+	}
+
+	for i := range news {
+		n := &news[i]
+		link := s.newsURL(n)
+		entry := atomEntry{
+			ID:      link,
+			Title:   n.Title,
+			Updated: n.UpdatedAt.UTC().Format(time.RFC3339),
+			Links:   []atomLink{{Rel: "alternate", Href: link, Type: "text/html"}},
+			Content: &atomText{Type: "html", Body: s.contentHTML(n)},
+		}
+		if n.PublishedAt != nil {
+			entry.Published = n.PublishedAt.UTC().Format(time.RFC3339)
+		}
+		if name := feedAuthorName(&n.Author); name != "" {
+			entry.Author = &atomPerson{Name: name}
+		}
+		if summary := s.summary(n); summary != "" {
+			entry.Summary = &atomText{Type: "text", Body: summary}
+		}
+		for _, category := range feedCategories(n) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if enclosure := s.enclosure(n); enclosure != nil {
+			entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Href: enclosure.URL, Type: enclosure.Type, Length: enclosure.Length})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshalFeed(feed)
+}
+
+// RenderRSS génère un flux RSS 2.0 (contenu HTML dans content:encoded)
+func (s *FeedService) RenderRSS(news []models.News, selfURL string, updated time.Time) ([]byte, error) {
+	appName, siteURL := s.site()
+	feed := rssFeed{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         appName + " - News",
+			Link:          siteURL + "/news",
+			Description:   "Actualités internes",
+			LastBuildDate: updated.Format(time.RFC1123Z),
+			TTL:           30,
+			Self:          rssSelf{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+
+	for i := range news {
+		n := &news[i]
+		link := s.newsURL(n)
+		item := rssItem{
+			Title:       n.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			Creator:     feedAuthorName(&n.Author),
+			Categories:  feedCategories(n),
+			Description: s.summary(n),
+			Content:     s.contentHTML(n),
+		}
+		if n.PublishedAt != nil {
+			item.PubDate = n.PublishedAt.Format(time.RFC1123Z)
+		}
+		if enclosure := s.enclosure(n); enclosure != nil {
+			item.Enclosure = &rssEnclosure{URL: enclosure.URL, Length: enclosure.Length, Type: enclosure.Type}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	return marshalFeed(feed)
+}
+
+func marshalFeed(feed interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func (s *FeedService) site() (string, string) {
+	var settings models.AppSettings
+	s.db.First(&settings)
+	appName := settings.AppName
+	if appName == "" {
+		appName = "Airboard"
+	}
+	return appName, strings.TrimRight(s.config.Server.PublicURL, "/")
+}
+
+func (s *FeedService) newsURL(n *models.News) string {
+	return fmt.Sprintf("%s/news/%s", strings.TrimRight(s.config.Server.PublicURL, "/"), n.Slug)
+}
+
+func (s *FeedService) contentHTML(n *models.News) string {
+	return utils.TiptapToHTML(n.Content, utils.TiptapHTMLOptions{BaseURL: s.config.Server.PublicURL})
+}
+
+func (s *FeedService) summary(n *models.News) string {
+	if strings.TrimSpace(n.Summary) != "" {
+		return n.Summary
+	}
+	return utils.TiptapExcerpt(n.Content, 300)
+}
+
+func (s *FeedService) enclosure(n *models.News) *feedEnclosure {
+	if n.CoverImage == "" {
+		return nil
+	}
+	enclosure := &feedEnclosure{URL: n.CoverImage}
+
+	var media models.Media
+	if err := s.db.Where("url = ?", n.CoverImage).First(&media).Error; err == nil {
+		enclosure.Type = media.MimeType
+		enclosure.Length = media.FileSize
+	} else {
+		enclosure.Type = mime.TypeByExtension(strings.ToLower(path.Ext(strings.SplitN(n.CoverImage, "?", 2)[0])))
+	}
+	if enclosure.Type == "" {
+		enclosure.Type = "application/octet-stream"
+	}
+	if strings.HasPrefix(enclosure.URL, "/") && !strings.HasPrefix(enclosure.URL, "//") {
+		enclosure.URL = strings.TrimRight(s.config.Server.PublicURL, "/") + enclosure.URL
+	}
+	return enclosure
+}
+
+func feedCategories(n *models.News) []string {
+	var categories []string
+	if n.Category != nil {
+		categories = append(categories, n.Category.Name)
+	}
+	for _, tag := range n.Tags {
+		categories = append(categories, tag.Name)
+	}
+	return categories
+}
+
+func feedAuthorName(user *models.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Username
+}
@@ -0,0 +1,724 @@
+package services
+
+import (
+	"airboard/config"
+	"airboard/models"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+	"gorm.io/gorm"
+)
+
+// LDAPProvider est la valeur de User.SSOProvider pour les comptes issus de l'annuaire
+const LDAPProvider = "ldap"
+
+var (
+	// ErrLDAPDisabled indique que l'authentification LDAP n'est pas configurée
+	ErrLDAPDisabled = errors.New("LDAP désactivé")
+	// ErrLDAPInvalidCredentials indique un échec de bind utilisateur
+	ErrLDAPInvalidCredentials = errors.New("identifiants LDAP invalides")
+	// ErrLDAPAccountConflict indique qu'un compte existant de même email ne peut pas être rattaché à l'annuaire
+	ErrLDAPAccountConflict = errors.New("compte existant non rattachable à l'annuaire")
+)
+
+// LDAPService gère l'authentification par bind LDAP/AD et la synchronisation de l'annuaire
+type LDAPService struct {
+	db     *gorm.DB
+	config *config.Config
+
+	syncMu sync.Mutex // Une seule synchronisation à la fois
+}
+
+// NewLDAPService crée une nouvelle instance du service LDAP
+func NewLDAPService(db *gorm.DB, cfg *config.Config) *LDAPService {
+	return &LDAPService{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// GetConfig retourne la configuration LDAP avec ses règles de mapping
+func (s *LDAPService) GetConfig() (*models.LDAPConfig, error) {
+	var conf models.LDAPConfig
+	if err := s.db.Preload("GroupMappings").First(&conf).Error; err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// enabledConfig retourne la configuration si LDAP est activé
+func (s *LDAPService) enabledConfig() (*models.LDAPConfig, error) {
+	conf, err := s.GetConfig()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLDAPDisabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !conf.IsEnabled || conf.Host == "" {
+		return nil, ErrLDAPDisabled
+	}
+	return conf, nil
+}
+
+// connect ouvre une connexion à l'annuaire et effectue le bind du compte de service
+func (s *LDAPService) connect(conf *models.LDAPConfig) (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         conf.Host,
+		InsecureSkipVerify: conf.SkipTLSVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	port := conf.Port
+	scheme := "ldap"
+	if conf.UseTLS {
+		scheme = "ldaps"
+		if port == 0 {
+			port = 636
+		}
+	}
+	if port == 0 {
+		port = 389
+	}
+
+	conn, err := ldap.DialURL(fmt.Sprintf("%s://%s:%d", scheme, conf.Host, port),
+		ldap.DialWithTLSConfig(tlsConfig),
+		ldap.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}))
+	if err != nil {
+		return nil, fmt.Errorf("connexion LDAP impossible: %w", err)
+	}
+	conn.SetTimeout(15 * time.Second)
+
+	if conf.UseStartTLS && !conf.UseTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("StartTLS a échoué: %w", err)
+		}
+	}
+
+	if conf.BindDN != "" {
+		password, err := s.DecryptSecret(conf.BindPassword)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := conn.Bind(conf.BindDN, password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("bind du compte de service refusé: %w", err)
+		}
+	} else if err := conn.UnauthenticatedBind(""); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("bind anonyme refusé: %w", err)
+	}
+
+	return conn, nil
+}
+
+// attributes retourne la liste des attributs à récupérer pour un utilisateur
+func ldapAttributes(conf *models.LDAPConfig) []string {
+	var attrs []string
+	for _, a := range []string{
+		conf.UsernameAttribute, conf.EmailAttribute, conf.FirstNameAttribute, conf.LastNameAttribute,
+		conf.DepartmentAttribute, conf.JobTitleAttribute, conf.PhoneAttribute, conf.LocationAttribute,
+		conf.GroupAttribute, conf.UniqueIDAttribute, conf.ManagerAttribute,
+	} {
+		if a != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// Authenticate vérifie les identifiants par bind sur l'annuaire puis crée ou met à jour
+// l'utilisateur local. Retourne ErrLDAPDisabled si LDAP n'est pas configuré.
+func (s *LDAPService) Authenticate(username, password string) (*models.User, error) {
+	conf, err := s.enabledConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Un mot de passe vide provoquerait un bind anonyme qui réussit toujours
+	if username == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	conn, err := s.connect(conf)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := ldapBindUser(conn, conf, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := s.upsertUser(conf, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Groups").Preload("AdminOfGroups").First(user, user.ID).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ldapBindUser recherche l'entrée de l'utilisateur avec le filtre configuré puis vérifie son mot
+// de passe par bind. La connexion reste liée à l'utilisateur après un bind réussi.
+func ldapBindUser(conn *ldap.Conn, conf *models.LDAPConfig, username, password string) (*ldap.Entry, error) {
+	filter := strings.ReplaceAll(conf.UserFilter, "{username}", ldap.EscapeFilter(username))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		conf.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 15, false,
+		filter, ldapAttributes(conf), nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("recherche LDAP: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrLDAPInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		return nil, ErrLDAPInvalidCredentials
+	}
+	return entry, nil
+}
+
+// TestConnection vérifie la connexion et le bind du compte de service, et retourne
+// le nombre d'utilisateurs correspondant au filtre de synchronisation
+func (s *LDAPService) TestConnection(conf *models.LDAPConfig) (int, error) {
+	conn, err := s.connect(conf)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	result, err := conn.SearchWithPaging(ldap.NewSearchRequest(
+		conf.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 30, false,
+		conf.SyncFilter, []string{"dn"}, nil,
+	), 500)
+	if err != nil {
+		return 0, fmt.Errorf("recherche LDAP: %w", err)
+	}
+	return len(result.Entries), nil
+}
+
+// Sync importe les utilisateurs de l'annuaire, applique le mapping des groupes et
+// désactive les comptes LDAP qui ne figurent plus dans l'annuaire
+func (s *LDAPService) Sync() (*models.LDAPSyncResult, error) {
+	if !s.syncMu.TryLock() {
+		return nil, errors.New("une synchronisation LDAP est déjà en cours")
+	}
+	defer s.syncMu.Unlock()
+
+	conf, err := s.enabledConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := s.runSync(conf)
+	if result == nil {
+		result = &models.LDAPSyncResult{}
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	// Enregistrer le statut de la synchronisation
+	message := fmt.Sprintf("%d créé(s), %d mis à jour, %d désactivé(s), %d ignoré(s), %d responsable(s) mis à jour",
+		result.Created, result.Updated, result.Deactivated, result.Skipped, result.Managers)
+	if err != nil {
+		message = err.Error()
+	}
+	s.db.Model(&models.LDAPConfig{}).Where("id = ?", conf.ID).Updates(map[string]interface{}{
+		"last_sync_at":      start,
+		"last_sync_success": err == nil,
+		"last_sync_message": message,
+	})
+
+	if err != nil {
+		log.Printf("[LDAP] Échec de la synchronisation: %v", err)
+		return result, err
+	}
+	log.Printf("[LDAP] Synchronisation terminée: %s", message)
+	return result, nil
+}
+
+func (s *LDAPService) runSync(conf *models.LDAPConfig) (*models.LDAPSyncResult, error) {
+	conn, err := s.connect(conf)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := ldapSearchDirectory(conn, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.LDAPSyncResult{}
+	seen := make([]uint, 0, len(entries))
+	var synced []ldapSyncedUser
+	for _, entry := range entries {
+		user, created, err := s.upsertUser(conf, entry)
+		if err != nil {
+			result.Skipped++
+			if len(result.Errors) < 50 {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.DN, err))
+			}
+			continue
+		}
+		seen = append(seen, user.ID)
+		synced = append(synced, ldapSyncedUser{user: user, dn: entry.DN, managerDN: ldapValue(entry, conf.ManagerAttribute, "")})
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	if conf.ManagerAttribute != "" {
+		s.syncManagers(synced, result)
+	}
+
+	if conf.DeactivateMissing {
+		if reason := ldapDeactivationBlocked(len(entries), result.Skipped); reason != "" {
+			result.Errors = append(result.Errors, reason)
+			return result, nil
+		}
+		query := s.db.Model(&models.User{}).
+			Where("sso_provider = ? AND is_active = ?", LDAPProvider, true)
+		if len(seen) > 0 {
+			query = query.Where("id NOT IN ?", seen)
+		}
+		res := query.Update("is_active", false)
+		if res.Error != nil {
+			return result, res.Error
+		}
+		result.Deactivated = int(res.RowsAffected)
+	}
+
+	return result, nil
+}
+
+// ldapSearchDirectory retourne les entrées de l'annuaire correspondant au filtre de synchronisation
+func ldapSearchDirectory(conn *ldap.Conn, conf *models.LDAPConfig) ([]*ldap.Entry, error) {
+	search, err := conn.SearchWithPaging(ldap.NewSearchRequest(
+		conf.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		conf.SyncFilter, ldapAttributes(conf), nil,
+	), 500)
+	if err != nil {
+		return nil, fmt.Errorf("recherche LDAP: %w", err)
+	}
+	return search.Entries, nil
+}
+
+// ldapDeactivationBlocked retourne la raison pour laquelle la désactivation des comptes absents
+// de l'annuaire ne doit pas être appliquée (chaîne vide si elle peut l'être)
+func ldapDeactivationBlocked(found, skipped int) string {
+	// Une recherche vide (filtre erroné, OU déplacée) ne doit pas désactiver tout le monde
+	if found == 0 {
+		return "désactivation ignorée : aucune entrée trouvée dans l'annuaire"
+	}
+	// Une entrée en erreur n'est pas un compte absent : son utilisateur ne figure pas dans les comptes vus
+	if skipped > 0 {
+		return fmt.Sprintf("désactivation ignorée : %d entrée(s) en erreur", skipped)
+	}
+	return ""
+}
+
+// ldapProfile attributs d'un utilisateur lus dans une entrée de l'annuaire
+type ldapProfile struct {
+	entry *ldap.Entry
+	conf  *models.LDAPConfig
+
+	Email      string
+	Username   string
+	ExternalID string
+	Groups     []string // DN des groupes de l'annuaire
+	IsAdmin    bool
+}
+
+// readLDAPEntry lit les attributs mappés d'une entrée de l'annuaire
+func readLDAPEntry(conf *models.LDAPConfig, entry *ldap.Entry) (*ldapProfile, error) {
+	profile := &ldapProfile{entry: entry, conf: conf}
+
+	profile.Email = strings.ToLower(strings.TrimSpace(entry.GetAttributeValue(conf.EmailAttribute)))
+	if profile.Email == "" {
+		return nil, errors.New("attribut email absent")
+	}
+	profile.Username = strings.TrimSpace(entry.GetAttributeValue(conf.UsernameAttribute))
+	if profile.Username == "" {
+		profile.Username = strings.Split(profile.Email, "@")[0]
+	}
+
+	profile.ExternalID = entry.DN
+	if conf.UniqueIDAttribute != "" {
+		if raw := entry.GetRawAttributeValue(conf.UniqueIDAttribute); len(raw) > 0 {
+			// objectGUID (AD) est binaire, entryUUID (OpenLDAP) est textuel
+			if utf8.Valid(raw) {
+				profile.ExternalID = string(raw)
+			} else {
+				profile.ExternalID = hex.EncodeToString(raw)
+			}
+		}
+	}
+
+	if conf.GroupAttribute != "" {
+		profile.Groups = entry.GetAttributeValues(conf.GroupAttribute)
+	}
+	profile.IsAdmin = ldapGroupsMatch(profile.Groups, splitList(conf.AdminGroups))
+	return profile, nil
+}
+
+// apply recopie les attributs de l'annuaire sur l'utilisateur local (les attributs non mappés
+// conservent leur valeur actuelle)
+func (p *ldapProfile) apply(user *models.User) {
+	user.Email = p.Email
+	user.SSOProvider = LDAPProvider
+	user.SSOID = p.ExternalID
+	user.FirstName = ldapValue(p.entry, p.conf.FirstNameAttribute, user.FirstName)
+	user.LastName = ldapValue(p.entry, p.conf.LastNameAttribute, user.LastName)
+	user.Department = ldapValue(p.entry, p.conf.DepartmentAttribute, user.Department)
+	user.JobTitle = ldapValue(p.entry, p.conf.JobTitleAttribute, user.JobTitle)
+	user.Phone = ldapValue(p.entry, p.conf.PhoneAttribute, user.Phone)
+	user.Location = ldapValue(p.entry, p.conf.LocationAttribute, user.Location)
+}
+
+// upsertUser crée ou met à jour l'utilisateur local correspondant à une entrée de l'annuaire
+func (s *LDAPService) upsertUser(conf *models.LDAPConfig, entry *ldap.Entry) (*models.User, bool, error) {
+	profile, err := readLDAPEntry(conf, entry)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Rechercher par identifiant externe, puis par email
+	var user models.User
+	created := false
+	err = s.db.Where("sso_provider = ? AND sso_id = ?", LDAPProvider, profile.ExternalID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = s.db.Where("email = ?", profile.Email).First(&user).Error
+		if err == nil {
+			if conflict := ldapLinkConflict(&user); conflict != nil {
+				return nil, false, conflict
+			}
+		}
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		role := conf.DefaultRole
+		if role == "" {
+			role = "user"
+		}
+		if profile.IsAdmin {
+			role = "admin"
+		}
+		user = models.User{
+			Username: profile.Username,
+			Role:     role,
+			IsActive: true,
+		}
+		created = true
+	case err != nil:
+		return nil, false, err
+	default:
+		if profile.IsAdmin {
+			user.Role = "admin"
+		} else if user.Role == "admin" && user.SSOProvider == LDAPProvider && conf.AdminGroups != "" {
+			// Retrait du groupe admin dans l'annuaire
+			user.Role = conf.DefaultRole
+		}
+	}
+
+	// Les comptes locaux rattachés à l'annuaire perdent leur mot de passe local :
+	// c'est désormais le bind LDAP qui fait foi. Les comptes désactivés le restent : la
+	// réactivation est une décision d'administrateur.
+	profile.apply(&user)
+	user.Password = ""
+
+	if created {
+		if err := s.db.Create(&user).Error; err != nil {
+			return nil, false, err
+		}
+		go NewWebhookService(s.db).UserCreated(&user, LDAPProvider)
+	} else if err := s.db.Omit("Groups", "AdminOfGroups", "Favorites").Save(&user).Error; err != nil {
+		return nil, false, err
+	}
+
+	if conf.GroupAttribute != "" {
+		if err := s.syncGroups(conf, &user, profile.Groups); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return &user, created, nil
+}
+
+// ldapLinkConflict vérifie qu'un compte trouvé par email peut être rattaché à l'annuaire : seuls les
+// comptes locaux non administrateurs le peuvent. Les comptes d'un autre fournisseur (SCIM, OAuth, SAML,
+// en-têtes) et les administrateurs locaux (comptes de secours) ne sont jamais repris par l'annuaire.
+func ldapLinkConflict(user *models.User) error {
+	switch {
+	case user.SSOProvider != "" && user.SSOProvider != LDAPProvider:
+		return fmt.Errorf("%w : %s appartient au fournisseur %s", ErrLDAPAccountConflict, user.Email, user.SSOProvider)
+	case user.SSOProvider == "" && user.Role == "admin":
+		return fmt.Errorf("%w : %s est un administrateur local", ErrLDAPAccountConflict, user.Email)
+	}
+	return nil
+}
+
+// ldapSyncedUser utilisateur synchronisé et DN de son responsable dans l'annuaire
+type ldapSyncedUser struct {
+	user      *models.User
+	dn        string
+	managerDN string
+}
+
+// syncManagers rattache chaque utilisateur synchronisé à son responsable, une fois tous les comptes
+// créés (le responsable peut apparaître après ses collaborateurs dans les résultats de recherche)
+func (s *LDAPService) syncManagers(synced []ldapSyncedUser, result *models.LDAPSyncResult) {
+	byDN := make(map[string]uint, len(synced))
+	for _, entry := range synced {
+		byDN[ldapNormalizeDN(entry.dn)] = entry.user.ID
+	}
+
+	org := NewOrgService(s.db)
+	for _, entry := range synced {
+		if entry.managerDN == "" {
+			if err := org.ReleaseManager(entry.user.ID, models.ManagerSourceLDAP); err != nil {
+				log.Printf("[LDAP] Erreur lors du retrait du responsable de %s: %v", entry.dn, err)
+			}
+			continue
+		}
+		managerID, ok := byDN[ldapNormalizeDN(entry.managerDN)]
+		if !ok {
+			if len(result.Errors) < 50 {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: responsable hors du périmètre de synchronisation (%s)", entry.dn, entry.managerDN))
+			}
+			continue
+		}
+		changed, err := org.SetManager(entry.user.ID, &managerID, models.ManagerSourceLDAP)
+		if err != nil {
+			if len(result.Errors) < 50 {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.dn, err))
+			}
+			continue
+		}
+		if changed {
+			result.Managers++
+		}
+	}
+}
+
+// ldapNormalizeDN forme canonique d'un DN pour la comparaison (casse et espaces ignorés)
+func ldapNormalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(dn))
+	}
+	rdns := make([]string, 0, len(parsed.RDNs))
+	for _, rdn := range parsed.RDNs {
+		attrs := make([]string, 0, len(rdn.Attributes))
+		for _, attr := range rdn.Attributes {
+			attrs = append(attrs, strings.ToLower(attr.Type)+"="+strings.ToLower(attr.Value))
+		}
+		rdns = append(rdns, strings.Join(attrs, "+"))
+	}
+	return strings.Join(rdns, ",")
+}
+
+// syncGroups applique les règles de mapping aux groupes de l'annuaire. Sans règle configurée,
+// les CN des groupes sont synchronisés avec les mêmes règles que le SSO.
+func (s *LDAPService) syncGroups(conf *models.LDAPConfig, user *models.User, directoryGroups []string) error {
+	if len(conf.GroupMappings) == 0 {
+		names := make([]string, 0, len(directoryGroups))
+		for _, dn := range directoryGroups {
+			names = append(names, ldapCN(dn))
+		}
+		return NewSSOMapper(s.db, s.config).SyncGroups(user, names)
+	}
+
+	groupIDs := ldapMappedGroupIDs(conf.GroupMappings, directoryGroups)
+	groups := make([]models.Group, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		groups = append(groups, models.Group{ID: id})
+	}
+	return s.db.Model(user).Association("Groups").Replace(groups)
+}
+
+// ldapMappedGroupIDs retourne les groupes locaux (triés, sans doublon) des règles de mapping
+// correspondant aux groupes de l'annuaire
+func ldapMappedGroupIDs(mappings []models.LDAPGroupMapping, directoryGroups []string) []uint {
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, mapping := range mappings {
+		if !seen[mapping.GroupID] && ldapGroupsMatch(directoryGroups, []string{mapping.LDAPGroup}) {
+			seen[mapping.GroupID] = true
+			ids = append(ids, mapping.GroupID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// ldapGroupsMatch indique si un des groupes de l'annuaire (DN) correspond à un des motifs
+// (DN ou CN, insensible à la casse, caractères génériques acceptés)
+func ldapGroupsMatch(directoryGroups, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		for _, dn := range directoryGroups {
+			for _, candidate := range []string{strings.ToLower(dn), strings.ToLower(ldapCN(dn))} {
+				if candidate == pattern {
+					return true
+				}
+				if ok, _ := path.Match(pattern, candidate); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ldapCN extrait le CN d'un DN (ou retourne la valeur telle quelle)
+func ldapCN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return dn
+	}
+	for _, attr := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return dn
+}
+
+// ldapValue retourne la valeur d'un attribut, ou la valeur actuelle si l'attribut n'est pas mappé
+func ldapValue(entry *ldap.Entry, attribute, current string) string {
+	if attribute == "" {
+		return current
+	}
+	return strings.TrimSpace(entry.GetAttributeValue(attribute))
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// StartScheduler lance la synchronisation périodique selon l'intervalle configuré
+func (s *LDAPService) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			conf, err := s.enabledConfig()
+			if err != nil || !conf.SyncEnabled {
+				continue
+			}
+
+			interval := time.Duration(conf.SyncIntervalMinutes) * time.Minute
+			if interval < 5*time.Minute {
+				interval = 5 * time.Minute
+			}
+			if conf.LastSyncAt != nil && time.Since(*conf.LastSyncAt) < interval {
+				continue
+			}
+
+			if _, err := s.Sync(); err != nil {
+				log.Printf("[LDAP] Synchronisation planifiée: %v", err)
+			}
+		}
+	}()
+}
+
+// EncryptSecret chiffre le mot de passe du compte de service avec AES-256
+// (même méthode que les mots de passe SMTP)
+func (s *LDAPService) EncryptSecret(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	block, err := aes.NewCipher(s.encryptionKey())
+	if err != nil {
+		return "", fmt.Errorf("erreur création cipher: %w", err)
+	}
+
+	plaintext := []byte(value)
+	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
+	iv := ciphertext[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", fmt.Errorf("erreur génération IV: %w", err)
+	}
+
+	stream := cipher.NewCFBEncrypter(block, iv)
+	stream.XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptSecret déchiffre le mot de passe du compte de service
+func (s *LDAPService) DecryptSecret(encrypted string) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("erreur décodage base64: %w", err)
+	}
+
+	block, err := aes.NewCipher(s.encryptionKey())
+	if err != nil {
+		return "", fmt.Errorf("erreur création cipher: %w", err)
+	}
+
+	if len(ciphertext) < aes.BlockSize {
+		return "", fmt.Errorf("ciphertext trop court")
+	}
+
+	iv := ciphertext[:aes.BlockSize]
+	ciphertext = ciphertext[aes.BlockSize:]
+
+	stream := cipher.NewCFBDecrypter(block, iv)
+	stream.XORKeyStream(ciphertext, ciphertext)
+
+	return string(ciphertext), nil
+}
+
+// encryptionKey utilise les 32 premiers octets du secret JWT comme clé
+func (s *LDAPService) encryptionKey() []byte {
+	secret := s.config.JWT.Secret
+	if len(secret) < 32 {
+		secret = secret + strings.Repeat("0", 32-len(secret))
+	}
+	return []byte(secret[:32])
+}
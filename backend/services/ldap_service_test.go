@@ -0,0 +1,520 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"airboard/config"
+	"airboard/models"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	ldapTestBaseDN     = "dc=example,dc=com"
+	ldapTestServiceDN  = "cn=svc-airboard,ou=services,dc=example,dc=com"
+	ldapTestServicePwd = "svc-secret"
+)
+
+// ldapTestEntry entrée de l'annuaire servi par ldapStub
+type ldapTestEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// ldapStub annuaire LDAP minimal (bind simple, recherche, unbind) servi en mémoire
+type ldapStub struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	entries []ldapTestEntry
+}
+
+func newLDAPStub(t *testing.T, entries ...ldapTestEntry) *ldapStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("écoute du serveur LDAP: %v", err)
+	}
+	stub := &ldapStub{listener: listener, entries: entries}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return stub
+}
+
+// config configuration LDAP pointant sur le stub (attributs OpenLDAP, compte de service chiffré)
+func (stub *ldapStub) config(t *testing.T, service *LDAPService) *models.LDAPConfig {
+	t.Helper()
+	password, err := service.EncryptSecret(ldapTestServicePwd)
+	if err != nil {
+		t.Fatalf("chiffrement du mot de passe de service: %v", err)
+	}
+	addr := stub.listener.Addr().(*net.TCPAddr)
+	return &models.LDAPConfig{
+		IsEnabled:           true,
+		Host:                addr.IP.String(),
+		Port:                addr.Port,
+		BindDN:              ldapTestServiceDN,
+		BindPassword:        password,
+		BaseDN:              ldapTestBaseDN,
+		UserFilter:          "(&(objectClass=person)(|(uid={username})(mail={username})))",
+		SyncFilter:          "(&(objectClass=person)(mail=*))",
+		UsernameAttribute:   "uid",
+		EmailAttribute:      "mail",
+		FirstNameAttribute:  "givenName",
+		LastNameAttribute:   "sn",
+		DepartmentAttribute: "departmentNumber",
+		JobTitleAttribute:   "title",
+		GroupAttribute:      "memberOf",
+		UniqueIDAttribute:   "entryUUID",
+		AdminGroups:         "airboard-admins, gg-airboard-admins",
+		DefaultRole:         "user",
+		DeactivateMissing:   true,
+	}
+}
+
+func (stub *ldapStub) setEntries(entries ...ldapTestEntry) {
+	stub.mu.Lock()
+	stub.entries = entries
+	stub.mu.Unlock()
+}
+
+func (stub *ldapStub) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		messageID, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := stub.bind(op.Children[1].Data.String(), op.Children[2].Data.String())
+			conn.Write(ldapTestMessage(messageID, ldapTestResult(ldap.ApplicationBindResponse, code)).Bytes())
+		case ldap.ApplicationSearchRequest:
+			for _, entry := range stub.search(op) {
+				conn.Write(ldapTestMessage(messageID, entry).Bytes())
+			}
+			conn.Write(ldapTestMessage(messageID, ldapTestResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)).Bytes())
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			return
+		}
+	}
+}
+
+// bind accepte le compte de service, les entrées avec leur mot de passe et le bind anonyme
+func (stub *ldapStub) bind(dn, password string) int64 {
+	if dn == "" && password == "" {
+		return ldap.LDAPResultSuccess
+	}
+	if strings.EqualFold(dn, ldapTestServiceDN) && password == ldapTestServicePwd {
+		return ldap.LDAPResultSuccess
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	for _, entry := range stub.entries {
+		if strings.EqualFold(entry.dn, dn) && entry.password != "" && entry.password == password {
+			return ldap.LDAPResultSuccess
+		}
+	}
+	return ldap.LDAPResultInvalidCredentials
+}
+
+func (stub *ldapStub) search(op *ber.Packet) []*ber.Packet {
+	baseDN := strings.ToLower(op.Children[0].Data.String())
+	filter := op.Children[6]
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	var results []*ber.Packet
+	for _, entry := range stub.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.dn), baseDN) || !ldapTestMatch(filter, entry) {
+			continue
+		}
+		result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for name, values := range entry.attrs {
+			attribute := ber.NewSequence("Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attribute.AppendChild(set)
+			attributes.AppendChild(attribute)
+		}
+		result.AppendChild(attributes)
+		results = append(results, result)
+	}
+	return results
+}
+
+// ldapTestMatch évalue les filtres utilisés par le service (and, or, not, égalité, présence, sous-chaînes)
+func ldapTestMatch(filter *ber.Packet, entry ldapTestEntry) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !ldapTestMatch(child, entry) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if ldapTestMatch(child, entry) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !ldapTestMatch(filter.Children[0], entry)
+	case ldap.FilterEqualityMatch:
+		assertion := filter.Children[1].Data.String()
+		for _, value := range ldapTestValues(entry, filter.Children[0].Data.String()) {
+			if strings.EqualFold(value, assertion) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(ldapTestValues(entry, filter.Data.String())) > 0
+	case ldap.FilterSubstrings:
+		for _, value := range ldapTestValues(entry, filter.Children[0].Data.String()) {
+			value = strings.ToLower(value)
+			matched := true
+			for _, part := range filter.Children[1].Children {
+				sub := strings.ToLower(part.Data.String())
+				switch part.Tag {
+				case ldap.FilterSubstringsInitial:
+					matched = matched && strings.HasPrefix(value, sub)
+				case ldap.FilterSubstringsAny:
+					matched = matched && strings.Contains(value, sub)
+				case ldap.FilterSubstringsFinal:
+					matched = matched && strings.HasSuffix(value, sub)
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func ldapTestValues(entry ldapTestEntry, attribute string) []string {
+	for name, values := range entry.attrs {
+		if strings.EqualFold(name, attribute) {
+			return values
+		}
+	}
+	return nil
+}
+
+func ldapTestMessage(messageID int64, op *ber.Packet) *ber.Packet {
+	packet := ber.NewSequence("LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(op)
+	return packet
+}
+
+func ldapTestResult(tag ber.Tag, code int64) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return result
+}
+
+func ldapTestPerson(uid, mail, password string, attrs map[string][]string) ldapTestEntry {
+	entry := ldapTestEntry{
+		dn:       "uid=" + uid + ",ou=people," + ldapTestBaseDN,
+		password: password,
+		attrs: map[string][]string{
+			"objectClass": {"top", "person", "inetOrgPerson"},
+			"uid":         {uid},
+		},
+	}
+	if mail != "" {
+		entry.attrs["mail"] = []string{mail}
+	}
+	for name, values := range attrs {
+		entry.attrs[name] = values
+	}
+	return entry
+}
+
+func newTestLDAPService() *LDAPService {
+	return NewLDAPService(nil, &config.Config{JWT: config.JWTConfig{Secret: "ldap-test-secret-0123456789abcdef"}})
+}
+
+func TestLDAPBindUser(t *testing.T) {
+	stub := newLDAPStub(t,
+		ldapTestPerson("alice", "Alice@Example.com", "alice-pwd", nil),
+		ldapTestPerson("bob", "bob@example.com", "bob-pwd", nil),
+	)
+	service := newTestLDAPService()
+	conf := stub.config(t, service)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantDN   string
+		wantErr  error
+	}{
+		{name: "identifiant et mot de passe valides", username: "alice", password: "alice-pwd", wantDN: "uid=alice,ou=people," + ldapTestBaseDN},
+		{name: "connexion par email", username: "bob@example.com", password: "bob-pwd", wantDN: "uid=bob,ou=people," + ldapTestBaseDN},
+		{name: "mauvais mot de passe", username: "alice", password: "bob-pwd", wantErr: ErrLDAPInvalidCredentials},
+		{name: "utilisateur inconnu", username: "carol", password: "alice-pwd", wantErr: ErrLDAPInvalidCredentials},
+		{name: "caractère générique échappé", username: "*", password: "alice-pwd", wantErr: ErrLDAPInvalidCredentials},
+		{name: "injection de filtre échappée", username: "alice)(uid=*", password: "alice-pwd", wantErr: ErrLDAPInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := service.connect(conf)
+			if err != nil {
+				t.Fatalf("connexion au stub: %v", err)
+			}
+			defer conn.Close()
+
+			entry, err := ldapBindUser(conn, conf, tt.username, tt.password)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("erreur = %v, attendu %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erreur inattendue: %v", err)
+			}
+			if entry.DN != tt.wantDN {
+				t.Fatalf("DN = %q, attendu %q", entry.DN, tt.wantDN)
+			}
+		})
+	}
+}
+
+func TestLDAPConnectRejectsServiceAccount(t *testing.T) {
+	stub := newLDAPStub(t)
+	service := newTestLDAPService()
+	conf := stub.config(t, service)
+
+	wrong, err := service.EncryptSecret("wrong-secret")
+	if err != nil {
+		t.Fatalf("chiffrement: %v", err)
+	}
+	conf.BindPassword = wrong
+	if _, err := service.connect(conf); err == nil {
+		t.Fatal("bind du compte de service accepté avec un mauvais mot de passe")
+	}
+}
+
+func TestReadLDAPEntry(t *testing.T) {
+	guid := string([]byte{0x8f, 0x01, 0xfe, 0x42, 0x00, 0xc3, 0x28, 0x9a})
+	stub := newLDAPStub(t,
+		ldapTestPerson("alice", " Alice@Example.COM ", "", map[string][]string{
+			"givenName":        {"Alice"},
+			"sn":               {"Martin"},
+			"departmentNumber": {"Finance"},
+			"title":            {"Comptable"},
+			"entryUUID":        {"6f1c2d1e-3b7a-4a4e-9d1f-1b2c3d4e5f60"},
+			"memberOf": {
+				"cn=airboard-admins,ou=groups,dc=example,dc=com",
+				"cn=GG-Finance,ou=groups,dc=example,dc=com",
+			},
+		}),
+		ldapTestPerson("", "bob@example.com", "", map[string][]string{
+			"entryUUID": {guid},
+			"memberOf":  {"cn=GG-Support,ou=groups,dc=example,dc=com"},
+		}),
+		ldapTestPerson("nomail", "", "", nil),
+	)
+	service := newTestLDAPService()
+	conf := stub.config(t, service)
+
+	conn, err := service.connect(conf)
+	if err != nil {
+		t.Fatalf("connexion au stub: %v", err)
+	}
+	defer conn.Close()
+
+	// Le filtre de synchronisation exclut les entrées sans email : les lire toutes pour le cas d'erreur
+	conf.SyncFilter = "(objectClass=person)"
+	entries, err := ldapSearchDirectory(conn, conf)
+	if err != nil {
+		t.Fatalf("recherche: %v", err)
+	}
+	byDN := make(map[string]*ldap.Entry, len(entries))
+	for _, entry := range entries {
+		byDN[entry.DN] = entry
+	}
+	if len(byDN) != 3 {
+		t.Fatalf("%d entrées trouvées, attendu 3", len(byDN))
+	}
+
+	t.Run("mapping des attributs et rôle admin", func(t *testing.T) {
+		profile, err := readLDAPEntry(conf, byDN["uid=alice,ou=people,"+ldapTestBaseDN])
+		if err != nil {
+			t.Fatalf("erreur inattendue: %v", err)
+		}
+		if profile.Email != "alice@example.com" || profile.Username != "alice" {
+			t.Fatalf("email/username = %q/%q", profile.Email, profile.Username)
+		}
+		if profile.ExternalID != "6f1c2d1e-3b7a-4a4e-9d1f-1b2c3d4e5f60" {
+			t.Fatalf("identifiant externe = %q", profile.ExternalID)
+		}
+		if !profile.IsAdmin {
+			t.Fatal("membre du groupe admin non reconnu")
+		}
+
+		// Les attributs non mappés (téléphone, site) conservent leur valeur locale
+		user := models.User{Phone: "0102030405", Location: "Lyon", FirstName: "Ancien"}
+		profile.apply(&user)
+		want := models.User{
+			Email: "alice@example.com", SSOProvider: LDAPProvider, SSOID: profile.ExternalID,
+			FirstName: "Alice", LastName: "Martin", Department: "Finance", JobTitle: "Comptable",
+			Phone: "0102030405", Location: "Lyon",
+		}
+		if !reflect.DeepEqual(user, want) {
+			t.Fatalf("utilisateur = %+v, attendu %+v", user, want)
+		}
+	})
+
+	t.Run("identifiant binaire et username déduit de l'email", func(t *testing.T) {
+		profile, err := readLDAPEntry(conf, byDN["uid=,ou=people,"+ldapTestBaseDN])
+		if err != nil {
+			t.Fatalf("erreur inattendue: %v", err)
+		}
+		if profile.Username != "bob" {
+			t.Fatalf("username = %q, attendu bob", profile.Username)
+		}
+		if profile.ExternalID != "8f01fe4200c3289a" {
+			t.Fatalf("identifiant externe = %q, attendu l'encodage hexadécimal", profile.ExternalID)
+		}
+		if profile.IsAdmin {
+			t.Fatal("rôle admin attribué hors du groupe admin")
+		}
+	})
+
+	t.Run("email absent", func(t *testing.T) {
+		if _, err := readLDAPEntry(conf, byDN["uid=nomail,ou=people,"+ldapTestBaseDN]); err == nil {
+			t.Fatal("entrée sans email acceptée")
+		}
+	})
+}
+
+func TestLDAPMappedGroupIDs(t *testing.T) {
+	mappings := []models.LDAPGroupMapping{
+		{LDAPGroup: "GG-Airboard-*", GroupID: 3},
+		{LDAPGroup: "cn=Finance,ou=groups,dc=example,dc=com", GroupID: 1},
+		{LDAPGroup: "support", GroupID: 2},
+		{LDAPGroup: "GG-Airboard-Lyon", GroupID: 3},
+		{LDAPGroup: "direction", GroupID: 4},
+	}
+	directoryGroups := []string{
+		"CN=finance,OU=Groups,DC=example,DC=com",
+		"cn=GG-Airboard-Lyon,ou=groups,dc=example,dc=com",
+		"cn=Support,ou=groups,dc=example,dc=com",
+	}
+
+	got := ldapMappedGroupIDs(mappings, directoryGroups)
+	if want := []uint{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("groupes = %v, attendu %v", got, want)
+	}
+	if got := ldapMappedGroupIDs(mappings, nil); len(got) != 0 {
+		t.Fatalf("groupes = %v sans groupe d'annuaire", got)
+	}
+}
+
+func TestLDAPLinkConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     models.User
+		conflict bool
+	}{
+		{name: "compte local", user: models.User{Email: "alice@example.com", Role: "user"}},
+		{name: "compte déjà rattaché à l'annuaire", user: models.User{Email: "alice@example.com", Role: "admin", SSOProvider: LDAPProvider}},
+		{name: "administrateur local", user: models.User{Email: "admin@example.com", Role: "admin"}, conflict: true},
+		{name: "compte SCIM", user: models.User{Email: "alice@example.com", Role: "user", SSOProvider: SCIMProvider}, conflict: true},
+		{name: "compte OAuth", user: models.User{Email: "alice@example.com", Role: "user", SSOProvider: "google"}, conflict: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ldapLinkConflict(&tt.user)
+			if tt.conflict != (err != nil) {
+				t.Fatalf("conflit = %v, attendu %v", err, tt.conflict)
+			}
+			if err != nil && !errors.Is(err, ErrLDAPAccountConflict) {
+				t.Fatalf("erreur = %v, attendu ErrLDAPAccountConflict", err)
+			}
+		})
+	}
+}
+
+func TestLDAPDeactivationGuard(t *testing.T) {
+	alice := ldapTestPerson("alice", "alice@example.com", "", nil)
+	stub := newLDAPStub(t, alice, ldapTestPerson("bob", "bob@example.com", "", nil))
+	service := newTestLDAPService()
+	conf := stub.config(t, service)
+
+	// Reproduit la boucle de synchronisation sans base : entrées lues et entrées en erreur
+	sync := func(t *testing.T) string {
+		t.Helper()
+		conn, err := service.connect(conf)
+		if err != nil {
+			t.Fatalf("connexion au stub: %v", err)
+		}
+		defer conn.Close()
+		entries, err := ldapSearchDirectory(conn, conf)
+		if err != nil {
+			t.Fatalf("recherche: %v", err)
+		}
+		skipped := 0
+		for _, entry := range entries {
+			if _, err := readLDAPEntry(conf, entry); err != nil {
+				skipped++
+			}
+		}
+		return ldapDeactivationBlocked(len(entries), skipped)
+	}
+
+	if reason := sync(t); reason != "" {
+		t.Fatalf("désactivation bloquée sans erreur: %s", reason)
+	}
+
+	// L'email de bob devient vide : l'entrée est en erreur et bob ne doit pas être désactivé
+	stub.setEntries(alice, ldapTestPerson("bob", "", "", map[string][]string{"mail": {" "}}))
+	if reason := sync(t); !strings.Contains(reason, "1 entrée(s) en erreur") {
+		t.Fatalf("désactivation non bloquée malgré une entrée en erreur: %q", reason)
+	}
+
+	// Filtre ne retournant plus rien (OU déplacée)
+	conf.BaseDN = "ou=archive," + ldapTestBaseDN
+	if reason := sync(t); reason == "" {
+		t.Fatal("désactivation appliquée après une recherche vide")
+	}
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+	"time"
+
+	"airboard/config"
+	"airboard/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	loginLockoutRetention = 24 * time.Hour       // Conservation des compteurs sans échec récent
+	loginDeviceRetention  = 365 * 24 * time.Hour // Conservation des appareils inutilisés
+)
+
+// LoginSecurityService verrouille les connexions après des échecs répétés (par adresse IP et par compte)
+// et alerte l'utilisateur en cas de verrouillage ou de connexion depuis un nouvel appareil.
+// L'état est stocké en base : il survit aux redémarrages et s'applique à toutes les instances.
+type LoginSecurityService struct {
+	db            *gorm.DB
+	cfg           *config.Config
+	notifications *NotificationService
+}
+
+// NewLoginSecurityService crée une nouvelle instance de LoginSecurityService
+func NewLoginSecurityService(db *gorm.DB, cfg *config.Config) *LoginSecurityService {
+	return &LoginSecurityService{
+		db:            db,
+		cfg:           cfg,
+		notifications: NewNotificationService(db),
+	}
+}
+
+// accountLockoutKey clé du compteur par compte : l'ID si le compte existe, sinon l'identifiant saisi
+func accountLockoutKey(user *models.User, identifier string) string {
+	if user != nil && user.ID != 0 {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// CheckLocked indique si l'adresse IP ou le compte visé est verrouillé, et pour combien de temps
+func (s *LoginSecurityService) CheckLocked(ip, identifier string, user *models.User) (bool, time.Duration) {
+	now := time.Now()
+	var lockout models.LoginLockout
+	err := s.db.Where("locked_until > ?", now).
+		Where("(scope = ? AND key = ?) OR (scope = ? AND key = ?)",
+			models.LockoutScopeIP, ip, models.LockoutScopeAccount, accountLockoutKey(user, identifier)).
+		Order("locked_until DESC").
+		First(&lockout).Error
+	if err != nil {
+		return false, 0
+	}
+	return true, lockout.LockedUntil.Sub(now)
+}
+
+// RecordFailure comptabilise un échec de connexion pour l'adresse IP et pour le compte visé.
+// Retourne vrai si l'un des deux est désormais verrouillé.
+func (s *LoginSecurityService) RecordFailure(ip, identifier string, user *models.User) (bool, time.Duration) {
+	var userID *uint
+	if user != nil && user.ID != 0 {
+		userID = &user.ID
+	}
+
+	ipLocked, _, err := s.increment(models.LockoutScopeIP, ip, nil, identifier, ip, s.cfg.Security.LoginMaxAttemptsIP)
+	if err != nil {
+		log.Printf("[LoginSecurity] Erreur compteur IP %s: %v", ip, err)
+	}
+	accountLocked, newlyLocked, err := s.increment(models.LockoutScopeAccount, accountLockoutKey(user, identifier), userID, identifier, ip, s.cfg.Security.LoginMaxAttemptsAccount)
+	if err != nil {
+		log.Printf("[LoginSecurity] Erreur compteur compte %s: %v", identifier, err)
+	}
+
+	if newlyLocked && userID != nil {
+		lockedUser := *user
+		go s.notifyLockout(&lockedUser, ip, time.Now().Add(s.cfg.Security.LoginLockoutDuration))
+	}
+
+	if ipLocked || accountLocked {
+		return true, s.cfg.Security.LoginLockoutDuration
+	}
+	return false, 0
+}
+
+// increment incrémente le compteur sous verrou de ligne (accès concurrents de plusieurs instances) et
+// verrouille au-delà du seuil. newlyLocked est vrai pour la requête qui a déclenché le verrouillage.
+func (s *LoginSecurityService) increment(scope, key string, userID *uint, identifier, ip string, maxAttempts int) (locked, newlyLocked bool, err error) {
+	now := time.Now()
+
+	var lockout models.LoginLockout
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Créer le compteur s'il n'existe pas encore, puis le verrouiller jusqu'à la fin de la transaction
+		if err := tx.Exec(`INSERT INTO login_lockouts (scope, key, failed_count, first_failed_at, last_failed_at, lock_count, created_at, updated_at)
+			VALUES (?, ?, 0, ?, ?, 0, ?, ?) ON CONFLICT (scope, key) DO NOTHING`,
+			scope, key, now, now, now, now).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND key = ?", scope, key).
+			First(&lockout).Error; err != nil {
+			return err
+		}
+
+		lockout.Identifier = identifier
+		lockout.LastIP = ip
+		if userID != nil {
+			lockout.UserID = userID
+		}
+		locked, newlyLocked = registerLoginFailure(&lockout, now, s.cfg.Security.LoginAttemptWindow, s.cfg.Security.LoginLockoutDuration, maxAttempts)
+		return tx.Save(&lockout).Error
+	})
+	if err != nil {
+		return false, false, err
+	}
+
+	if newlyLocked {
+		log.Printf("[LoginSecurity] Verrouillage %s %s après %d échecs", scope, key, lockout.FailedCount)
+	}
+	return locked, newlyLocked, nil
+}
+
+// registerLoginFailure comptabilise un échec sur le compteur. Une nouvelle fenêtre démarre (compteur
+// remis à 1, verrouillage expiré effacé) si le dernier échec est hors fenêtre ou si le verrouillage
+// précédent a expiré : un compteur déjà verrouillé une fois peut ainsi l'être de nouveau. Un verrouillage
+// en cours n'est jamais levé par un nouvel échec.
+func registerLoginFailure(lockout *models.LoginLockout, now time.Time, window, lockDuration time.Duration, maxAttempts int) (locked, newlyLocked bool) {
+	alreadyLocked := lockout.LockedUntil != nil && lockout.LockedUntil.After(now)
+	lockExpired := lockout.LockedUntil != nil && !alreadyLocked
+
+	if !alreadyLocked && (lockout.FailedCount == 0 || lockout.LastFailedAt.Before(now.Add(-window)) || lockExpired) {
+		lockout.FailedCount = 1
+		lockout.FirstFailedAt = now
+		lockout.LockedUntil = nil
+	} else {
+		lockout.FailedCount++
+	}
+	lockout.LastFailedAt = now
+
+	if alreadyLocked {
+		return true, false
+	}
+	if lockout.FailedCount < maxAttempts {
+		return false, false
+	}
+
+	lockedUntil := now.Add(lockDuration)
+	lockout.LockedUntil = &lockedUntil
+	lockout.LockCount++
+	return true, true
+}
+
+// RecordSuccess remet à zéro le compteur du compte (pas celui de l'adresse IP, qu'un compte valide
+// ne doit pas pouvoir réinitialiser) et alerte l'utilisateur si l'appareil ou l'adresse IP est nouveau
+func (s *LoginSecurityService) RecordSuccess(ip, identifier, userAgent string, user *models.User) {
+	s.db.Where("scope = ? AND key IN ?", models.LockoutScopeAccount, []string{
+		accountLockoutKey(user, ""),
+		accountLockoutKey(nil, identifier),
+		accountLockoutKey(nil, user.Username),
+		accountLockoutKey(nil, user.Email),
+	}).Delete(&models.LoginLockout{})
+
+	if err := s.trackDevice(user, ip, userAgent); err != nil {
+		log.Printf("[LoginSecurity] Erreur suivi des appareils pour %s: %v", user.Username, err)
+	}
+}
+
+// trackDevice enregistre le couple appareil / adresse IP et alerte l'utilisateur lors d'une première utilisation
+// (sauf pour la toute première connexion du compte)
+func (s *LoginSecurityService) trackDevice(user *models.User, ip, userAgent string) error {
+	sum := sha256.Sum256([]byte(userAgent))
+	fingerprint := hex.EncodeToString(sum[:])
+
+	var known, sameDevice, sameIP int64
+	s.db.Model(&models.LoginDevice{}).Where("user_id = ?", user.ID).Count(&known)
+	if known > 0 {
+		s.db.Model(&models.LoginDevice{}).Where("user_id = ? AND fingerprint = ?", user.ID, fingerprint).Count(&sameDevice)
+		s.db.Model(&models.LoginDevice{}).Where("user_id = ? AND ip_address = ?", user.ID, ip).Count(&sameIP)
+	}
+
+	now := time.Now()
+	err := s.db.Exec(`INSERT INTO login_devices (user_id, fingerprint, ip_address, user_agent, login_count, first_seen_at, last_seen_at)
+		VALUES (?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT (user_id, fingerprint, ip_address) DO UPDATE SET login_count = login_devices.login_count + 1, last_seen_at = EXCLUDED.last_seen_at`,
+		user.ID, fingerprint, ip, userAgent, now, now).Error
+	if err != nil {
+		return err
+	}
+
+	if known > 0 && (sameDevice == 0 || sameIP == 0) {
+		loggedUser := *user
+		go s.notifyNewDevice(&loggedUser, ip, userAgent, sameDevice == 0)
+	}
+	return nil
+}
+
+// ListLockouts retourne les compteurs (verrouillés uniquement si activeOnly), paginés
+func (s *LoginSecurityService) ListLockouts(scope string, activeOnly bool, offset, limit int) ([]models.LoginLockout, int64, error) {
+	now := time.Now()
+	query := s.db.Model(&models.LoginLockout{})
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	if activeOnly {
+		query = query.Where("locked_until > ?", now)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var lockouts []models.LoginLockout
+	err := query.Preload("User").
+		Order("locked_until DESC NULLS LAST, last_failed_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&lockouts).Error
+	for i := range lockouts {
+		lockouts[i].IsLocked = lockouts[i].LockedUntil != nil && lockouts[i].LockedUntil.After(now)
+		if lockouts[i].User != nil {
+			lockouts[i].User.Password = ""
+		}
+	}
+	return lockouts, total, err
+}
+
+// Stats retourne la synthèse des compteurs et verrouillages en cours
+func (s *LoginSecurityService) Stats() models.LoginLockoutStats {
+	now := time.Now()
+	windowStart := now.Add(-s.cfg.Security.LoginAttemptWindow)
+
+	var stats models.LoginLockoutStats
+	s.db.Model(&models.LoginLockout{}).Count(&stats.Tracked)
+	s.db.Model(&models.LoginLockout{}).Where("scope = ? AND locked_until > ?", models.LockoutScopeAccount, now).Count(&stats.LockedAccounts)
+	s.db.Model(&models.LoginLockout{}).Where("scope = ? AND locked_until > ?", models.LockoutScopeIP, now).Count(&stats.LockedIPs)
+	s.db.Model(&models.LoginLockout{}).Where("last_failed_at >= ?", windowStart).
+		Select("COALESCE(SUM(failed_count), 0)").Scan(&stats.FailedAttempts)
+	return stats
+}
+
+// ClearLockout supprime un compteur (déverrouillage manuel)
+func (s *LoginSecurityService) ClearLockout(id uint) error {
+	return s.db.Delete(&models.LoginLockout{}, id).Error
+}
+
+// ClearUserLockouts supprime les compteurs d'un compte
+func (s *LoginSecurityService) ClearUserLockouts(user *models.User) (int64, error) {
+	result := s.db.Where("scope = ? AND (user_id = ? OR key IN ?)", models.LockoutScopeAccount, user.ID, []string{
+		accountLockoutKey(user, ""),
+		accountLockoutKey(nil, user.Username),
+		accountLockoutKey(nil, user.Email),
+	}).Delete(&models.LoginLockout{})
+	return result.RowsAffected, result.Error
+}
+
+// StartScheduler purge chaque heure les compteurs inactifs et les appareils inutilisés
+func (s *LoginSecurityService) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			now := time.Now()
+			s.db.Where("(locked_until IS NULL OR locked_until < ?) AND last_failed_at < ?", now, now.Add(-loginLockoutRetention)).
+				Delete(&models.LoginLockout{})
+			s.db.Where("last_seen_at < ?", now.Add(-loginDeviceRetention)).Delete(&models.LoginDevice{})
+		}
+	}()
+}
+
+// securityEmailTemplate gabarit des alertes de sécurité envoyées par email
+var securityEmailTemplate = template.Must(template.New("security").Parse(`<p>Bonjour {{.Name}},</p>
+<p>{{.Intro}}</p>
+<ul>
+<li>Date : {{.Date}}</li>
+<li>Adresse IP : {{.IP}}</li>
+{{if .UserAgent}}<li>Navigateur : {{.UserAgent}}</li>{{end}}
+</ul>
+<p>{{.Advice}}</p>
+<p><a href="{{.Link}}">{{.AppName}}</a></p>`))
+
+// sendSecurityEmail envoie une alerte de sécurité à l'utilisateur (si l'email est configuré)
+func (s *LoginSecurityService) sendSecurityEmail(user *models.User, subject, intro, advice, ip, userAgent string) {
+	if user.Email == "" {
+		return
+	}
+
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		name = user.Username
+	}
+	appName := "Airboard"
+	var settings models.AppSettings
+	if err := s.db.First(&settings).Error; err == nil && settings.AppName != "" {
+		appName = settings.AppName
+	}
+
+	var body bytes.Buffer
+	err := securityEmailTemplate.Execute(&body, map[string]string{
+		"Name":      name,
+		"Intro":     intro,
+		"Date":      time.Now().Format("02/01/2006 à 15:04"),
+		"IP":        ip,
+		"UserAgent": userAgent,
+		"Advice":    advice,
+		"Link":      s.cfg.Server.PublicURL + "/profile",
+		"AppName":   appName,
+	})
+	if err != nil {
+		log.Printf("[LoginSecurity] Erreur gabarit email: %v", err)
+		return
+	}
+
+	if err := NewEmailService(s.db, s.cfg).SendDirect(user.Email, fmt.Sprintf("[%s] %s", appName, subject), body.String()); err != nil {
+		log.Printf("[LoginSecurity] Alerte email non envoyée à %s: %v", user.Email, err)
+	}
+}
+
+// notifyLockout alerte l'utilisateur du verrouillage de son compte
+func (s *LoginSecurityService) notifyLockout(user *models.User, ip string, until time.Time) {
+	if err := s.notifications.NotifyAccountLocked(user.ID, ip, until); err != nil {
+		log.Printf("[LoginSecurity] Erreur notification de verrouillage: %v", err)
+	}
+	s.sendSecurityEmail(user, "Compte temporairement verrouillé",
+		fmt.Sprintf("Votre compte a été verrouillé jusqu'à %s après plusieurs tentatives de connexion échouées.", until.Format("15:04")),
+		"Si vous n'êtes pas à l'origine de ces tentatives, changez votre mot de passe dès le déverrouillage et prévenez votre administrateur.",
+		ip, "")
+}
+
+// notifyNewDevice alerte l'utilisateur d'une connexion depuis un nouvel appareil ou une nouvelle adresse IP
+func (s *LoginSecurityService) notifyNewDevice(user *models.User, ip, userAgent string, newDevice bool) {
+	if err := s.notifications.NotifyNewDeviceLogin(user.ID, ip, newDevice); err != nil {
+		log.Printf("[LoginSecurity] Erreur notification de nouvel appareil: %v", err)
+	}
+	intro := "Une connexion à votre compte a eu lieu depuis une nouvelle adresse IP."
+	if newDevice {
+		intro = "Une connexion à votre compte a eu lieu depuis un nouvel appareil."
+	}
+	s.sendSecurityEmail(user, "Nouvelle connexion à votre compte", intro,
+		"Si vous n'êtes pas à l'origine de cette connexion, changez immédiatement votre mot de passe et prévenez votre administrateur.",
+		ip, userAgent)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"airboard/models"
+)
+
+func TestRegisterLoginFailureRelocksAfterExpiry(t *testing.T) {
+	const (
+		window       = 15 * time.Minute
+		lockDuration = 30 * time.Minute
+		maxAttempts  = 5
+	)
+	now := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	lockout := &models.LoginLockout{}
+
+	// fail enregistre maxAttempts échecs espacés d'une seconde et retourne le résultat du dernier
+	fail := func() (locked, newlyLocked bool) {
+		for i := 0; i < maxAttempts; i++ {
+			now = now.Add(time.Second)
+			locked, newlyLocked = registerLoginFailure(lockout, now, window, lockDuration, maxAttempts)
+			if i < maxAttempts-1 && locked {
+				t.Fatalf("verrouillé après %d échecs, seuil %d", i+1, maxAttempts)
+			}
+		}
+		return locked, newlyLocked
+	}
+
+	if locked, newlyLocked := fail(); !locked || !newlyLocked {
+		t.Fatalf("premier verrouillage attendu, locked=%v newlyLocked=%v", locked, newlyLocked)
+	}
+
+	// Pendant le verrouillage, un échec ne le lève pas et ne le redéclenche pas
+	now = now.Add(lockDuration / 2)
+	if locked, newlyLocked := registerLoginFailure(lockout, now, window, lockDuration, maxAttempts); !locked || newlyLocked {
+		t.Fatalf("verrouillage en cours attendu, locked=%v newlyLocked=%v", locked, newlyLocked)
+	}
+
+	// Après expiration du verrouillage, maxAttempts nouveaux échecs verrouillent de nouveau
+	now = lockout.LockedUntil.Add(time.Minute)
+	if locked, newlyLocked := fail(); !locked || !newlyLocked {
+		t.Fatalf("nouveau verrouillage attendu, locked=%v newlyLocked=%v (failed_count=%d)", locked, newlyLocked, lockout.FailedCount)
+	}
+	if lockout.LockCount != 2 {
+		t.Fatalf("lock_count = %d, attendu 2", lockout.LockCount)
+	}
+}
+
+func TestRegisterLoginFailureWindow(t *testing.T) {
+	const window = 15 * time.Minute
+	now := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	lockout := &models.LoginLockout{}
+
+	registerLoginFailure(lockout, now, window, time.Hour, 3)
+	registerLoginFailure(lockout, now.Add(time.Minute), window, time.Hour, 3)
+	if lockout.FailedCount != 2 {
+		t.Fatalf("failed_count = %d, attendu 2", lockout.FailedCount)
+	}
+
+	// Un échec hors fenêtre redémarre le compteur
+	if locked, _ := registerLoginFailure(lockout, now.Add(window+2*time.Minute), window, time.Hour, 3); locked {
+		t.Fatal("verrouillage inattendu après une fenêtre expirée")
+	}
+	if lockout.FailedCount != 1 {
+		t.Fatalf("failed_count = %d, attendu 1", lockout.FailedCount)
+	}
+}
@@ -0,0 +1,512 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"airboard/config"
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidNewsTransition l'action n'est pas possible depuis l'état actuel de l'article
+	ErrInvalidNewsTransition = errors.New("invalid news workflow transition")
+	// ErrInvalidReviewer un relecteur n'a pas le droit de publication global
+	ErrInvalidReviewer = errors.New("invalid reviewer")
+)
+
+// NewsWorkflowService gère le workflow éditorial des articles : relecture, approbation, publication planifiée, archivage
+type NewsWorkflowService struct {
+	db            *gorm.DB
+	cfg           *config.Config
+	permissions   *PermissionService
+	notifications *NotificationService
+}
+
+// NewNewsWorkflowService crée une nouvelle instance de NewsWorkflowService
+func NewNewsWorkflowService(db *gorm.DB, cfg *config.Config, permissions *PermissionService) *NewsWorkflowService {
+	return &NewsWorkflowService{
+		db:            db,
+		cfg:           cfg,
+		permissions:   permissions,
+		notifications: NewNotificationService(db),
+	}
+}
+
+// ApprovalRequired indique si la règle d'approbation des articles est active
+func (s *NewsWorkflowService) ApprovalRequired() bool {
+	settings := models.AppSettings{NewsApprovalRequired: true}
+	s.db.First(&settings)
+	return settings.NewsApprovalRequired
+}
+
+// Record journalise une transition déjà enregistrée sur l'article (création, modification)
+func (s *NewsWorkflowService) Record(newsID uint, actorID *uint, action, fromStatus, toStatus, comment string) {
+	event := models.NewsWorkflowEvent{
+		NewsID:     newsID,
+		ActorID:    actorID,
+		Action:     action,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+		Comment:    comment,
+	}
+	if err := s.db.Create(&event).Error; err != nil {
+		log.Printf("[Workflow] Erreur journalisation %s de l'article %d: %v", action, newsID, err)
+	}
+}
+
+// Info retourne les relecteurs et l'historique éditorial d'un article
+func (s *NewsWorkflowService) Info(news *models.News) (*models.NewsWorkflowInfo, error) {
+	info := &models.NewsWorkflowInfo{Status: news.Status, PublishedAt: news.PublishedAt}
+	if err := s.db.Preload("Reviewer").Where("news_id = ?", news.ID).Order("created_at").Find(&info.Reviewers).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Actor").Where("news_id = ?", news.ID).Order("created_at DESC, id DESC").Find(&info.History).Error; err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Reviewers retourne les utilisateurs actifs pouvant relire et approuver les articles (droit news.publish global)
+func (s *NewsWorkflowService) Reviewers() ([]models.User, error) {
+	var candidates []models.User
+	err := s.db.Where("is_active = ?", true).
+		Where("role IN ? OR id IN (?) OR id IN (?)",
+			[]string{models.RoleAdmin, models.RoleEditor},
+			s.db.Table("role_assignments").Select("user_id").Where("user_id IS NOT NULL"),
+			s.db.Table("user_groups").Select("user_groups.user_id").
+				Joins("JOIN role_assignments ON role_assignments.group_id = user_groups.group_id")).
+		Order("first_name, last_name").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers := make([]models.User, 0, len(candidates))
+	for _, user := range candidates {
+		if s.permissions.Resolve(user.ID, user.Role, nil).Has(models.PermNewsPublish) {
+			reviewers = append(reviewers, user)
+		}
+	}
+	return reviewers, nil
+}
+
+// CanReview indique si l'utilisateur peut relire et approuver les articles
+func (s *NewsWorkflowService) CanReview(userID uint) bool {
+	var user models.User
+	if err := s.db.Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		return false
+	}
+	return s.permissions.Resolve(user.ID, user.Role, nil).Has(models.PermNewsPublish)
+}
+
+// Submit soumet un brouillon en relecture et notifie les relecteurs (assignés, sinon tous les relecteurs éligibles)
+func (s *NewsWorkflowService) Submit(news *models.News, submitter *models.User, reviewerIDs []uint, comment string) error {
+	if news.Status != models.NewsStatusDraft && news.Status != models.NewsStatusApproved {
+		return ErrInvalidNewsTransition
+	}
+	if len(reviewerIDs) > 0 {
+		if err := s.AssignReviewers(news, submitter.ID, reviewerIDs); err != nil {
+			return err
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Nouvelle relecture : les décisions précédentes ne s'appliquent plus
+		if err := tx.Model(&models.NewsReviewer{}).Where("news_id = ?", news.ID).
+			Updates(map[string]interface{}{"decision": models.ReviewDecisionPending, "decided_at": nil}).Error; err != nil {
+			return err
+		}
+		return s.transition(tx, news, &submitter.ID, models.NewsActionSubmit, models.NewsStatusInReview, comment)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyReviewers(news, submitter)
+	return nil
+}
+
+// AssignReviewers remplace les relecteurs assignés ; les nouveaux relecteurs sont notifiés si l'article est en relecture
+func (s *NewsWorkflowService) AssignReviewers(news *models.News, assignedByID uint, reviewerIDs []uint) error {
+	for _, reviewerID := range reviewerIDs {
+		if reviewerID == news.AuthorID || !s.CanReview(reviewerID) {
+			return ErrInvalidReviewer
+		}
+	}
+
+	var added []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("news_id = ?", news.ID)
+		if len(reviewerIDs) > 0 {
+			query = query.Where("reviewer_id NOT IN ?", reviewerIDs)
+		}
+		if err := query.Delete(&models.NewsReviewer{}).Error; err != nil {
+			return err
+		}
+
+		var existing []uint
+		if err := tx.Model(&models.NewsReviewer{}).Where("news_id = ?", news.ID).Pluck("reviewer_id", &existing).Error; err != nil {
+			return err
+		}
+		known := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+		for _, reviewerID := range reviewerIDs {
+			if known[reviewerID] {
+				continue
+			}
+			known[reviewerID] = true
+			reviewer := models.NewsReviewer{
+				NewsID:       news.ID,
+				ReviewerID:   reviewerID,
+				AssignedByID: assignedByID,
+				Decision:     models.ReviewDecisionPending,
+			}
+			if err := tx.Create(&reviewer).Error; err != nil {
+				return err
+			}
+			added = append(added, reviewerID)
+		}
+
+		event := models.NewsWorkflowEvent{
+			NewsID:     news.ID,
+			ActorID:    &assignedByID,
+			Action:     models.NewsActionAssignReviewers,
+			FromStatus: news.Status,
+			ToStatus:   news.Status,
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if news.Status == models.NewsStatusInReview && len(added) > 0 {
+		var assigner models.User
+		s.db.First(&assigner, assignedByID)
+		if err := s.notifications.NotifyNewsReviewRequested(news.Title, news.Slug, assigner.FirstName+" "+assigner.LastName, added); err != nil {
+			log.Printf("[Workflow] Erreur notification des relecteurs de l'article %d: %v", news.ID, err)
+		}
+	}
+	return nil
+}
+
+// Approve approuve un article en relecture et notifie l'auteur
+func (s *NewsWorkflowService) Approve(news *models.News, reviewer *models.User, comment string) error {
+	if news.Status != models.NewsStatusInReview {
+		return ErrInvalidNewsTransition
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.recordDecision(tx, news, reviewer.ID, models.ReviewDecisionApproved, comment); err != nil {
+			return err
+		}
+		return s.transition(tx, news, &reviewer.ID, models.NewsActionApprove, models.NewsStatusApproved, comment)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.notifications.NotifyNewsApproved(news.AuthorID, news.Title, news.Slug, reviewer.FirstName+" "+reviewer.LastName, comment); err != nil {
+		log.Printf("[Workflow] Erreur notification d'approbation de l'article %d: %v", news.ID, err)
+	}
+	return nil
+}
+
+// RequestChanges renvoie un article en brouillon avec un commentaire et notifie l'auteur
+func (s *NewsWorkflowService) RequestChanges(news *models.News, reviewer *models.User, comment string) error {
+	if news.Status != models.NewsStatusInReview && news.Status != models.NewsStatusApproved {
+		return ErrInvalidNewsTransition
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.recordDecision(tx, news, reviewer.ID, models.ReviewDecisionChangesRequested, comment); err != nil {
+			return err
+		}
+		return s.transition(tx, news, &reviewer.ID, models.NewsActionRequestChanges, models.NewsStatusDraft, comment)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.notifications.NotifyNewsChangesRequested(news.AuthorID, news.Title, news.Slug, reviewer.FirstName+" "+reviewer.LastName, comment); err != nil {
+		log.Printf("[Workflow] Erreur notification de demande de modifications de l'article %d: %v", news.ID, err)
+	}
+	return nil
+}
+
+// Publish publie l'article immédiatement, ou le planifie si publishAt est dans le futur.
+// La règle d'approbation est vérifiée par l'appelant.
+func (s *NewsWorkflowService) Publish(news *models.News, actorID uint, publishAt *time.Time) error {
+	switch news.Status {
+	case models.NewsStatusPublished, models.NewsStatusArchived:
+		return ErrInvalidNewsTransition
+	}
+
+	if publishAt != nil && publishAt.After(time.Now()) {
+		news.PublishedAt = publishAt
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			return s.transition(tx, news, &actorID, models.NewsActionSchedule, models.NewsStatusScheduled, "")
+		})
+	}
+
+	if publishAt != nil {
+		news.PublishedAt = publishAt
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.transition(tx, news, &actorID, models.NewsActionPublish, models.NewsStatusPublished, "")
+	})
+	if err != nil {
+		return err
+	}
+	go s.Announce(news.ID, actorID)
+	return nil
+}
+
+// Unschedule annule une publication planifiée ; l'article revient à l'état approuvé s'il l'avait été, sinon en brouillon
+func (s *NewsWorkflowService) Unschedule(news *models.News, actorID uint) error {
+	if news.Status != models.NewsStatusScheduled {
+		return ErrInvalidNewsTransition
+	}
+	to := models.NewsStatusDraft
+	if s.isApproved(news.ID) {
+		to = models.NewsStatusApproved
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.transition(tx, news, &actorID, models.NewsActionUnschedule, to, "")
+	})
+}
+
+// Unpublish retire un article publié (retour en brouillon)
+func (s *NewsWorkflowService) Unpublish(news *models.News, actorID uint) error {
+	if news.Status != models.NewsStatusPublished {
+		return ErrInvalidNewsTransition
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.transition(tx, news, &actorID, models.NewsActionUnpublish, models.NewsStatusDraft, "")
+	})
+}
+
+// InvalidateApproval renvoie en brouillon un article approuvé, planifié ou publié modifié par un rédacteur
+// soumis à approbation (un article publié est retiré de la publication)
+func (s *NewsWorkflowService) InvalidateApproval(news *models.News, actorID uint) error {
+	action := models.NewsActionEditAfterApprove
+	switch news.Status {
+	case models.NewsStatusApproved, models.NewsStatusScheduled:
+	case models.NewsStatusPublished:
+		action = models.NewsActionEditAfterPublish
+	default:
+		return ErrInvalidNewsTransition
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.NewsReviewer{}).Where("news_id = ?", news.ID).
+			Updates(map[string]interface{}{"decision": models.ReviewDecisionPending, "decided_at": nil}).Error; err != nil {
+			return err
+		}
+		return s.transition(tx, news, &actorID, action, models.NewsStatusDraft, "")
+	})
+}
+
+// Archive archive un article (retiré de la lecture)
+func (s *NewsWorkflowService) Archive(news *models.News, actorID *uint, comment string) error {
+	if news.Status == models.NewsStatusArchived {
+		return ErrInvalidNewsTransition
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.transition(tx, news, actorID, models.NewsActionArchive, models.NewsStatusArchived, comment)
+	})
+}
+
+// Unarchive remet un article archivé en brouillon
+func (s *NewsWorkflowService) Unarchive(news *models.News, actorID uint) error {
+	if news.Status != models.NewsStatusArchived {
+		return ErrInvalidNewsTransition
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.transition(tx, news, &actorID, models.NewsActionUnarchive, models.NewsStatusDraft, "")
+	})
+}
+
+// PublishDue publie les articles planifiés arrivés à échéance et archive les articles expirés
+func (s *NewsWorkflowService) PublishDue() int {
+	now := time.Now()
+	published := 0
+
+	var due []models.News
+	s.db.Where("status = ? AND published_at <= ?", models.NewsStatusScheduled, now).Find(&due)
+	for i := range due {
+		news := &due[i]
+		// Réservation optimiste : une seule instance publie l'article
+		result := s.db.Model(&models.News{}).
+			Where("id = ? AND status = ?", news.ID, models.NewsStatusScheduled).
+			Updates(map[string]interface{}{"status": models.NewsStatusPublished, "is_published": true})
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		s.Record(news.ID, nil, models.NewsActionPublish, models.NewsStatusScheduled, models.NewsStatusPublished, "")
+		published++
+
+		if err := s.notifications.NotifyScheduledNewsPublished(news.AuthorID, news.Title, news.Slug); err != nil {
+			log.Printf("[Workflow] Erreur notification de publication de l'article %d: %v", news.ID, err)
+		}
+		s.Announce(news.ID, news.AuthorID)
+	}
+
+	var expired []models.News
+	s.db.Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", models.NewsStatusPublished, now).Find(&expired)
+	for i := range expired {
+		result := s.db.Model(&models.News{}).
+			Where("id = ? AND status = ?", expired[i].ID, models.NewsStatusPublished).
+			Updates(map[string]interface{}{"status": models.NewsStatusArchived, "is_published": false})
+		if result.Error == nil && result.RowsAffected == 1 {
+			s.Record(expired[i].ID, nil, models.NewsActionArchive, models.NewsStatusPublished, models.NewsStatusArchived, "Date d'expiration atteinte")
+		}
+	}
+
+	return published
+}
+
+// StartScheduler lance la publication planifiée (vérification chaque minute)
+func (s *NewsWorkflowService) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if count := s.PublishDue(); count > 0 {
+				log.Printf("[Workflow] %d article(s) planifié(s) publié(s)", count)
+			}
+		}
+	}()
+}
+
+// Announce diffuse la publication d'un article : email, webhooks sortants et notifications des lecteurs ciblés
+func (s *NewsWorkflowService) Announce(newsID uint, publisherID uint) {
+	// Seule la première publication est diffusée : un article republié après modification ou retrait
+	// ne renotifie pas ses lecteurs (réservation conditionnelle, une seule instance diffuse)
+	claimed := s.db.Model(&models.News{}).Where("id = ? AND announced_at IS NULL", newsID).
+		UpdateColumn("announced_at", time.Now())
+	if claimed.Error != nil {
+		log.Printf("[Workflow] Erreur lors de la réservation de la diffusion de l'article %d: %v", newsID, claimed.Error)
+		return
+	}
+	if claimed.RowsAffected == 0 {
+		return
+	}
+
+	var news models.News
+	if err := s.db.Preload("Author").Preload("TargetGroups").First(&news, newsID).Error; err != nil {
+		log.Printf("[Workflow] Article %d introuvable pour la diffusion: %v", newsID, err)
+		return
+	}
+
+	targetGroupIDs := groupIDs(news.TargetGroups)
+
+	log.Printf("[Email] Tentative d'envoi de notification pour news ID=%d, titre='%s'", news.ID, news.Title)
+	log.Printf("[Email] Groupes cibles pour news %d: %v", news.ID, targetGroupIDs)
+	if err := NewEmailService(s.db, s.cfg).SendNotification("news", news.ID, targetGroupIDs); err != nil {
+		log.Printf("[Email] ❌ ÉCHEC notification news ID=%d: %v", news.ID, err)
+	} else {
+		log.Printf("[Email] ✅ Notification envoyée avec succès pour news ID=%d", news.ID)
+	}
+
+	// Webhooks sortants
+	NewWebhookService(s.db).NewsPublished(&news)
+
+	// Utilisateurs à notifier : membres des groupes cibles, sinon tous les utilisateurs actifs sauf l'auteur
+	var userIDs []uint
+	if len(targetGroupIDs) > 0 {
+		s.db.Table("user_groups").
+			Where("group_id IN ?", targetGroupIDs).
+			Distinct("user_id").
+			Pluck("user_id", &userIDs)
+	} else {
+		s.db.Model(&models.User{}).
+			Where("is_active = ?", true).
+			Where("id != ?", publisherID).
+			Pluck("id", &userIDs)
+	}
+	if len(userIDs) > 0 {
+		authorName := news.Author.FirstName + " " + news.Author.LastName
+		if err := s.notifications.NotifyNewArticle(news.Title, news.Slug, authorName, userIDs); err != nil {
+			log.Printf("[Notification] Échec de l'envoi de la notification: %v", err)
+		}
+	}
+}
+
+// transition change l'état de l'article et journalise la transition
+func (s *NewsWorkflowService) transition(tx *gorm.DB, news *models.News, actorID *uint, action, to, comment string) error {
+	from := news.Status
+	news.Status = to
+	news.IsPublished = to == models.NewsStatusPublished
+	if news.IsPublished && news.PublishedAt == nil {
+		now := time.Now()
+		news.PublishedAt = &now
+	}
+
+	if err := tx.Model(&models.News{}).Where("id = ?", news.ID).Updates(map[string]interface{}{
+		"status":       news.Status,
+		"is_published": news.IsPublished,
+		"published_at": news.PublishedAt,
+	}).Error; err != nil {
+		return err
+	}
+
+	event := models.NewsWorkflowEvent{
+		NewsID:     news.ID,
+		ActorID:    actorID,
+		Action:     action,
+		FromStatus: from,
+		ToStatus:   to,
+		Comment:    comment,
+	}
+	return tx.Create(&event).Error
+}
+
+// recordDecision enregistre la décision d'un relecteur (assigné automatiquement s'il ne l'était pas)
+func (s *NewsWorkflowService) recordDecision(tx *gorm.DB, news *models.News, reviewerID uint, decision, comment string) error {
+	now := time.Now()
+	reviewer := models.NewsReviewer{NewsID: news.ID, ReviewerID: reviewerID, AssignedByID: reviewerID}
+	if err := tx.Where("news_id = ? AND reviewer_id = ?", news.ID, reviewerID).FirstOrCreate(&reviewer).Error; err != nil {
+		return err
+	}
+	return tx.Model(&reviewer).Updates(map[string]interface{}{
+		"decision":   decision,
+		"comment":    comment,
+		"decided_at": &now,
+	}).Error
+}
+
+// notifyReviewers notifie les relecteurs assignés, ou à défaut tous les relecteurs éligibles
+func (s *NewsWorkflowService) notifyReviewers(news *models.News, submitter *models.User) {
+	var reviewerIDs []uint
+	s.db.Model(&models.NewsReviewer{}).Where("news_id = ?", news.ID).Pluck("reviewer_id", &reviewerIDs)
+	if len(reviewerIDs) == 0 {
+		reviewers, err := s.Reviewers()
+		if err != nil {
+			log.Printf("[Workflow] Erreur recherche des relecteurs: %v", err)
+			return
+		}
+		for _, reviewer := range reviewers {
+			if reviewer.ID != news.AuthorID && reviewer.ID != submitter.ID {
+				reviewerIDs = append(reviewerIDs, reviewer.ID)
+			}
+		}
+	}
+	if len(reviewerIDs) == 0 {
+		log.Printf("[Workflow] Aucun relecteur à notifier pour l'article %d", news.ID)
+		return
+	}
+	if err := s.notifications.NotifyNewsReviewRequested(news.Title, news.Slug, submitter.FirstName+" "+submitter.LastName, reviewerIDs); err != nil {
+		log.Printf("[Workflow] Erreur notification des relecteurs de l'article %d: %v", news.ID, err)
+	}
+}
+
+// isApproved indique si un relecteur a approuvé la version actuelle de l'article
+func (s *NewsWorkflowService) isApproved(newsID uint) bool {
+	var count int64
+	s.db.Model(&models.NewsReviewer{}).Where("news_id = ? AND decision = ?", newsID, models.ReviewDecisionApproved).Count(&count)
+	return count > 0
+}
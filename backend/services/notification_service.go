@@ -3,6 +3,8 @@ package services
 import (
 	"airboard/models"
 	"fmt"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -40,6 +42,49 @@ func (s *NotificationService) NotifyRoleChange(userID uint, oldRole, newRole str
 	return s.createNotification(userID, "system", "role_change", title, message, icon, "#F59E0B", "", 1)
 }
 
+// NotifyAccountLocked crée une notification de verrouillage du compte après des échecs de connexion
+func (s *NotificationService) NotifyAccountLocked(userID uint, ip string, until time.Time) error {
+	title := "Compte temporairement verrouillé"
+	message := fmt.Sprintf("Trop de tentatives de connexion échouées (dernière depuis %s). Connexion bloquée jusqu'à %s.", ip, until.Format("15:04"))
+	icon := "mdi:lock-alert"
+
+	return s.createNotification(userID, "system", "security", title, message, icon, "#EF4444", "/profile", 2)
+}
+
+// NotifyNewDeviceLogin crée une notification de connexion depuis un nouvel appareil ou une nouvelle adresse IP
+func (s *NotificationService) NotifyNewDeviceLogin(userID uint, ip string, newDevice bool) error {
+	title := "Connexion depuis une nouvelle adresse IP"
+	if newDevice {
+		title = "Connexion depuis un nouvel appareil"
+	}
+	message := fmt.Sprintf("Nouvelle connexion à votre compte depuis %s. Si ce n'était pas vous, changez votre mot de passe.", ip)
+	icon := "mdi:shield-alert"
+
+	return s.createNotification(userID, "system", "security", title, message, icon, "#F59E0B", "/profile", 1)
+}
+
+// NotifyPrivacyExportReady crée une notification de mise à disposition de l'export des données personnelles
+func (s *NotificationService) NotifyPrivacyExportReady(userID uint, requestID uint) error {
+	title := "Export de vos données disponible"
+	message := "L'archive de vos données personnelles est prête à être téléchargée depuis votre profil."
+	icon := "mdi:folder-zip"
+	actionURL := fmt.Sprintf("/profile?privacy_request=%d", requestID)
+
+	return s.createNotification(userID, "system", "privacy", title, message, icon, "#10B981", actionURL, 1)
+}
+
+// NotifyPrivacyRequestRejected crée une notification de refus d'une demande d'effacement
+func (s *NotificationService) NotifyPrivacyRequestRejected(userID uint, comment string) error {
+	title := "Demande de suppression refusée"
+	message := "Votre demande de suppression de compte a été refusée."
+	if comment != "" {
+		message += " Motif : " + comment
+	}
+	icon := "mdi:account-cancel"
+
+	return s.createNotification(userID, "system", "privacy", title, message, icon, "#F59E0B", "/profile", 1)
+}
+
 // NotifyAccessGranted crée une notification d'accès accordé à une application
 func (s *NotificationService) NotifyAccessGranted(userID uint, appName string, appID uint) error {
 	title := "Nouvel accès"
@@ -80,6 +125,64 @@ func (s *NotificationService) NotifyPinnedArticle(title, slug string, userIDs []
 	return s.createNotificationForUsers(userIDs, "news", "pinned_article", notifTitle, message, icon, "#EF4444", actionURL, 2)
 }
 
+// NotifyNewsReviewRequested crée une notification de demande de relecture d'un article
+func (s *NotificationService) NotifyNewsReviewRequested(title, slug, submitterName string, reviewerIDs []uint) error {
+	notifTitle := "Relecture demandée"
+	message := fmt.Sprintf("%s vous demande de relire '%s'", submitterName, title)
+	icon := "mdi:file-eye"
+	actionURL := fmt.Sprintf("/admin/news/%s/edit", slug)
+
+	return s.createNotificationForUsers(reviewerIDs, "news", "review_requested", notifTitle, message, icon, "#8B5CF6", actionURL, 1)
+}
+
+// NotifyNewsApproved crée une notification d'approbation d'un article pour son auteur
+func (s *NotificationService) NotifyNewsApproved(authorID uint, title, slug, reviewerName, comment string) error {
+	notifTitle := "Article approuvé"
+	message := fmt.Sprintf("'%s' a été approuvé par %s", title, reviewerName)
+	if comment != "" {
+		message += " : " + comment
+	}
+	icon := "mdi:file-check"
+	actionURL := fmt.Sprintf("/news/%s", slug)
+
+	return s.createNotification(authorID, "news", "review_approved", notifTitle, message, icon, "#10B981", actionURL, 1)
+}
+
+// NotifyNewsChangesRequested crée une notification de demande de modifications pour l'auteur d'un article
+func (s *NotificationService) NotifyNewsChangesRequested(authorID uint, title, slug, reviewerName, comment string) error {
+	notifTitle := "Modifications demandées"
+	message := fmt.Sprintf("%s demande des modifications sur '%s'", reviewerName, title)
+	if comment != "" {
+		message += " : " + comment
+	}
+	icon := "mdi:file-edit"
+	actionURL := fmt.Sprintf("/news/%s", slug)
+
+	return s.createNotification(authorID, "news", "review_changes_requested", notifTitle, message, icon, "#F59E0B", actionURL, 1)
+}
+
+// NotifyScheduledNewsPublished crée une notification de publication planifiée effectuée pour l'auteur
+func (s *NotificationService) NotifyScheduledNewsPublished(authorID uint, title, slug string) error {
+	notifTitle := "Article publié"
+	message := fmt.Sprintf("La publication planifiée de '%s' a été effectuée", title)
+	icon := "mdi:calendar-check"
+	actionURL := fmt.Sprintf("/news/%s", slug)
+
+	return s.createNotification(authorID, "news", "scheduled_published", notifTitle, message, icon, "#3B82F6", actionURL, 0)
+}
+
+// NotifyAcknowledgementReminder relance les utilisateurs n'ayant pas accusé lecture d'un contenu à lecture obligatoire
+func (s *NotificationService) NotifyAcknowledgementReminder(entityType, title, actionURL string, dueAt *time.Time, userIDs []uint) error {
+	notifTitle := "Lecture obligatoire"
+	message := fmt.Sprintf("Merci de lire et de confirmer la lecture de '%s'", title)
+	if dueAt != nil {
+		message += fmt.Sprintf(" (échéance : %s)", dueAt.Format("02/01/2006 à 15:04"))
+	}
+	icon := "mdi:file-sign"
+
+	return s.createNotificationForUsers(userIDs, entityType, "acknowledgement_reminder", notifTitle, message, icon, "#EF4444", actionURL, 2)
+}
+
 // NotifyNewAnnouncement crée une notification pour une nouvelle annonce
 func (s *NotificationService) NotifyNewAnnouncement(title string, announcementType string, userIDs []uint) error {
 	notifTitle := "Nouvelle annonce"
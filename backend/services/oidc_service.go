@@ -0,0 +1,409 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCDiscovery représente le document de découverte OpenID Connect
+// (/.well-known/openid-configuration)
+type OIDCDiscovery struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	UserInfoEndpoint              string   `json:"userinfo_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	EndSessionEndpoint            string   `json:"end_session_endpoint,omitempty"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	IDTokenSigningAlgValues       []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// jsonWebKey représente une clé publique au format JWK (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type cachedDiscovery struct {
+	doc       *OIDCDiscovery
+	fetchedAt time.Time
+}
+
+type cachedJWKS struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// OIDCService gère la découverte des fournisseurs OpenID Connect et la validation des ID tokens
+type OIDCService struct {
+	httpClient *http.Client
+	cacheTTL   time.Duration
+
+	mu          sync.RWMutex
+	discoveries map[string]cachedDiscovery // issuer -> discovery
+	jwks        map[string]cachedJWKS      // jwks_uri -> clés
+}
+
+// NewOIDCService crée une nouvelle instance du service OIDC
+func NewOIDCService() *OIDCService {
+	return &OIDCService{
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		cacheTTL:    time.Hour,
+		discoveries: make(map[string]cachedDiscovery),
+		jwks:        make(map[string]cachedJWKS),
+	}
+}
+
+// Discover récupère (ou renvoie depuis le cache) le document de découverte d'un émetteur
+func (s *OIDCService) Discover(issuerURL string) (*OIDCDiscovery, error) {
+	issuer := strings.TrimSuffix(strings.TrimSpace(issuerURL), "/")
+	if issuer == "" {
+		return nil, fmt.Errorf("issuer URL manquante")
+	}
+
+	s.mu.RLock()
+	cached, ok := s.discoveries[issuer]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.fetchedAt) < s.cacheTTL {
+		return cached.doc, nil
+	}
+
+	var doc OIDCDiscovery
+	if err := s.getJSON(issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("échec de la découverte OIDC: %w", err)
+	}
+
+	// OpenID Connect Discovery 1.0 §4.3: l'issuer doit correspondre exactement
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("issuer du document de découverte (%s) différent de l'issuer configuré (%s)", doc.Issuer, issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("document de découverte incomplet")
+	}
+
+	s.mu.Lock()
+	s.discoveries[issuer] = cachedDiscovery{doc: &doc, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	return &doc, nil
+}
+
+// InvalidateIssuer supprime du cache la découverte et les clés d'un émetteur
+func (s *OIDCService) InvalidateIssuer(issuerURL string) {
+	issuer := strings.TrimSuffix(strings.TrimSpace(issuerURL), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.discoveries[issuer]; ok {
+		delete(s.jwks, cached.doc.JWKSURI)
+	}
+	delete(s.discoveries, issuer)
+}
+
+// IDTokenValidation regroupe les paramètres attendus lors de la validation d'un ID token
+type IDTokenValidation struct {
+	IssuerURL string
+	ClientID  string
+	Nonce     string
+}
+
+// VerifyIDToken valide la signature (via JWKS), les claims standards et le nonce d'un ID token
+// et retourne ses claims
+func (s *OIDCService) VerifyIDToken(rawIDToken string, params IDTokenValidation) (jwt.MapClaims, error) {
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("ID token manquant")
+	}
+
+	discovery, err := s.Discover(params.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	validMethods := discovery.IDTokenSigningAlgValues
+	if len(validMethods) == 0 {
+		validMethods = []string{"RS256"}
+	}
+	// Ne jamais accepter "none" ni les algorithmes symétriques pour un ID token signé par JWKS
+	allowed := make([]string, 0, len(validMethods))
+	for _, alg := range validMethods {
+		if alg != "none" && !strings.HasPrefix(alg, "HS") {
+			allowed = append(allowed, alg)
+		}
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.lookupKey(discovery.JWKSURI, kid)
+	},
+		jwt.WithValidMethods(allowed),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(params.ClientID),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ID token invalide: %w", err)
+	}
+
+	// L'expiration est obligatoire pour un ID token (OIDC Core §2)
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("ID token sans expiration")
+	}
+
+	// Si plusieurs audiences, azp doit désigner notre client (OIDC Core §3.1.3.7)
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != params.ClientID {
+			return nil, fmt.Errorf("azp invalide pour un ID token multi-audience")
+		}
+	}
+
+	// Le flux authorization code émet toujours un nonce : son absence désactiverait la protection
+	// contre le rejeu (OIDC Core §3.1.3.7)
+	if params.Nonce == "" {
+		return nil, fmt.Errorf("nonce attendu manquant")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != params.Nonce {
+		return nil, fmt.Errorf("nonce de l'ID token invalide")
+	}
+
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, fmt.Errorf("ID token sans subject")
+	}
+
+	return claims, nil
+}
+
+// lookupKey retourne la clé publique correspondant au kid, en rechargeant le JWKS
+// si la clé est inconnue (rotation des clés côté fournisseur)
+func (s *OIDCService) lookupKey(jwksURI, kid string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	cached, ok := s.jwks[jwksURI]
+	s.mu.RUnlock()
+
+	if ok && time.Since(cached.fetchedAt) < s.cacheTTL {
+		if key := selectKey(cached.keys, kid); key != nil {
+			return key, nil
+		}
+		// Éviter de marteler le fournisseur avec des kid inconnus
+		if time.Since(cached.fetchedAt) < 30*time.Second {
+			return nil, fmt.Errorf("clé de signature inconnue: %s", kid)
+		}
+	}
+
+	keys, err := s.fetchJWKS(jwksURI)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.jwks[jwksURI] = cachedJWKS{keys: keys, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	if key := selectKey(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("clé de signature inconnue: %s", kid)
+}
+
+// selectKey choisit la clé par kid, ou l'unique clé disponible si le token n'a pas de kid
+func selectKey(keys map[string]crypto.PublicKey, kid string) crypto.PublicKey {
+	if kid != "" {
+		return keys[kid]
+	}
+	if len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// fetchJWKS télécharge et décode un JWKS
+func (s *OIDCService) fetchJWKS(jwksURI string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("échec du téléchargement du JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		kid := jwk.Kid
+		if kid == "" {
+			kid = fmt.Sprintf("#%d", i)
+		}
+		keys[kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("aucune clé de signature exploitable dans le JWKS")
+	}
+	return keys, nil
+}
+
+// publicKey convertit une JWK en clé publique Go
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("courbe non supportée: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("type de clé non supporté: %s", k.Kty)
+	}
+}
+
+// getJSON effectue une requête GET et décode la réponse JSON
+func (s *OIDCService) getJSON(url string, out interface{}) error {
+	resp, err := s.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d depuis %s", resp.StatusCode, url)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// ClaimString lit un claim texte, en supportant les chemins imbriqués ("realm_access.roles")
+func ClaimString(claims map[string]interface{}, path string) string {
+	value := lookupClaim(claims, path)
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		if len(v) > 0 {
+			if s, ok := v[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// ClaimEmailVerified indique si le fournisseur atteste que l'email a été vérifié (claim email_verified,
+// booléen ou chaîne "true" selon les fournisseurs)
+func ClaimEmailVerified(claims map[string]interface{}) bool {
+	switch v := lookupClaim(claims, "email_verified").(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// ClaimStrings lit un claim liste (ex: groups), accepte aussi une chaîne séparée par des virgules
+func ClaimStrings(claims map[string]interface{}, path string) []string {
+	value := lookupClaim(claims, path)
+	var result []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+	case []string:
+		result = append(result, v...)
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if trimmed := strings.TrimSpace(item); trimmed != "" {
+				result = append(result, trimmed)
+			}
+		}
+	}
+	return result
+}
+
+func lookupClaim(claims map[string]interface{}, path string) interface{} {
+	if path == "" {
+		return nil
+	}
+	if value, ok := claims[path]; ok {
+		return value
+	}
+
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil
+		}
+	}
+	return current
+}
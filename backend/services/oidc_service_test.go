@@ -0,0 +1,328 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const oidcTestClientID = "airboard-client"
+
+// oidcStub fournisseur OpenID Connect minimal (découverte et JWKS) servi en mémoire
+type oidcStub struct {
+	server *httptest.Server
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PrivateKey // kid -> clé publiée dans le JWKS
+}
+
+func newOIDCStub(t *testing.T) *oidcStub {
+	t.Helper()
+	stub := &oidcStub{keys: make(map[string]*rsa.PrivateKey)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OIDCDiscovery{
+			Issuer:                  stub.server.URL,
+			AuthorizationEndpoint:   stub.server.URL + "/authorize",
+			TokenEndpoint:           stub.server.URL + "/token",
+			JWKSURI:                 stub.server.URL + "/jwks",
+			IDTokenSigningAlgValues: []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		set := struct {
+			Keys []jsonWebKey `json:"keys"`
+		}{}
+		for kid, key := range stub.keys {
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+// rotate publie une nouvelle clé (les clés précédentes restent publiées)
+func (stub *oidcStub) rotate(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("génération de la clé RSA: %v", err)
+	}
+	stub.mu.Lock()
+	stub.keys[kid] = key
+	stub.mu.Unlock()
+	return key
+}
+
+// claims claims valides d'un ID token émis par le stub
+func (stub *oidcStub) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   stub.server.URL,
+		"sub":   "user-42",
+		"aud":   oidcTestClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+}
+
+func signIDToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signature de l'ID token: %v", err)
+	}
+	return raw
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	stub := newOIDCStub(t)
+	key := stub.rotate(t, "key-1")
+	attacker, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("génération de la clé RSA: %v", err)
+	}
+	params := IDTokenValidation{IssuerURL: stub.server.URL, ClientID: oidcTestClientID, Nonce: "nonce-1"}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr string
+	}{
+		{
+			name:  "token valide",
+			token: func() string { return signIDToken(t, key, "key-1", stub.claims("nonce-1")) },
+		},
+		{
+			name:    "signature invalide",
+			token:   func() string { return signIDToken(t, attacker, "key-1", stub.claims("nonce-1")) },
+			wantErr: "signature",
+		},
+		{
+			name: "alg none",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, stub.claims("nonce-1"))
+				token.Header["kid"] = "key-1"
+				raw, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatalf("token alg=none: %v", err)
+				}
+				return raw
+			},
+			wantErr: "signing method",
+		},
+		{
+			name: "alg HS256 signé avec la clé publique",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, stub.claims("nonce-1"))
+				token.Header["kid"] = "key-1"
+				raw, err := token.SignedString(key.PublicKey.N.Bytes())
+				if err != nil {
+					t.Fatalf("token HS256: %v", err)
+				}
+				return raw
+			},
+			wantErr: "signing method",
+		},
+		{
+			name: "mauvaise audience",
+			token: func() string {
+				claims := stub.claims("nonce-1")
+				claims["aud"] = "other-client"
+				return signIDToken(t, key, "key-1", claims)
+			},
+			wantErr: "aud",
+		},
+		{
+			name: "multi-audience sans azp",
+			token: func() string {
+				claims := stub.claims("nonce-1")
+				claims["aud"] = []string{oidcTestClientID, "other-client"}
+				return signIDToken(t, key, "key-1", claims)
+			},
+			wantErr: "azp",
+		},
+		{
+			name: "mauvais émetteur",
+			token: func() string {
+				claims := stub.claims("nonce-1")
+				claims["iss"] = "https://evil.example.com"
+				return signIDToken(t, key, "key-1", claims)
+			},
+			wantErr: "iss",
+		},
+		{
+			name: "token expiré",
+			token: func() string {
+				claims := stub.claims("nonce-1")
+				claims["iat"] = time.Now().Add(-time.Hour).Unix()
+				claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+				return signIDToken(t, key, "key-1", claims)
+			},
+			wantErr: "expired",
+		},
+		{
+			name: "pas encore valide (nbf)",
+			token: func() string {
+				claims := stub.claims("nonce-1")
+				claims["nbf"] = time.Now().Add(10 * time.Minute).Unix()
+				return signIDToken(t, key, "key-1", claims)
+			},
+			wantErr: "not valid yet",
+		},
+		{
+			name: "sans expiration",
+			token: func() string {
+				claims := stub.claims("nonce-1")
+				delete(claims, "exp")
+				return signIDToken(t, key, "key-1", claims)
+			},
+			wantErr: "expiration",
+		},
+		{
+			name:    "nonce différent",
+			token:   func() string { return signIDToken(t, key, "key-1", stub.claims("nonce-2")) },
+			wantErr: "nonce",
+		},
+		{
+			name: "nonce absent du token",
+			token: func() string {
+				claims := stub.claims("nonce-1")
+				delete(claims, "nonce")
+				return signIDToken(t, key, "key-1", claims)
+			},
+			wantErr: "nonce",
+		},
+		{
+			name:    "kid inconnu",
+			token:   func() string { return signIDToken(t, key, "unknown", stub.claims("nonce-1")) },
+			wantErr: "clé de signature inconnue",
+		},
+	}
+
+	service := NewOIDCService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := service.VerifyIDToken(tt.token(), params)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("erreur inattendue: %v", err)
+				}
+				if sub, _ := claims["sub"].(string); sub != "user-42" {
+					t.Fatalf("sub = %q, attendu user-42", sub)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("erreur attendue contenant %q, token accepté", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("erreur = %q, attendu %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRequiresExpectedNonce(t *testing.T) {
+	stub := newOIDCStub(t)
+	key := stub.rotate(t, "key-1")
+	params := IDTokenValidation{IssuerURL: stub.server.URL, ClientID: oidcTestClientID}
+
+	_, err := NewOIDCService().VerifyIDToken(signIDToken(t, key, "key-1", stub.claims("")), params)
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("erreur = %v, attendu un refus faute de nonce attendu", err)
+	}
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	stub := newOIDCStub(t)
+	oldKey := stub.rotate(t, "key-1")
+	params := IDTokenValidation{IssuerURL: stub.server.URL, ClientID: oidcTestClientID, Nonce: "nonce-1"}
+
+	service := NewOIDCService()
+	if _, err := service.VerifyIDToken(signIDToken(t, oldKey, "key-1", stub.claims("nonce-1")), params); err != nil {
+		t.Fatalf("token signé avec la clé initiale: %v", err)
+	}
+
+	// Le fournisseur publie une nouvelle clé : le JWKS en cache ne la contient pas encore
+	newKey := stub.rotate(t, "key-2")
+	rotated := signIDToken(t, newKey, "key-2", stub.claims("nonce-1"))
+
+	// Rechargement limité à une fois toutes les 30 secondes pour un kid inconnu
+	if _, err := service.VerifyIDToken(rotated, params); err == nil {
+		t.Fatal("kid inconnu accepté sans rechargement du JWKS")
+	}
+
+	// Passé ce délai, le JWKS est rechargé et la nouvelle clé acceptée
+	service.mu.Lock()
+	for uri, cached := range service.jwks {
+		cached.fetchedAt = time.Now().Add(-time.Minute)
+		service.jwks[uri] = cached
+	}
+	service.mu.Unlock()
+	if _, err := service.VerifyIDToken(rotated, params); err != nil {
+		t.Fatalf("token signé avec la nouvelle clé après rotation: %v", err)
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OIDCDiscovery{
+			Issuer:                "https://evil.example.com",
+			AuthorizationEndpoint: "https://evil.example.com/authorize",
+			TokenEndpoint:         "https://evil.example.com/token",
+			JWKSURI:               "https://evil.example.com/jwks",
+		})
+	}))
+	defer server.Close()
+
+	if _, err := NewOIDCService().Discover(server.URL); err == nil {
+		t.Fatal("document de découverte d'un autre émetteur accepté")
+	}
+}
+
+func TestClaimEmailVerified(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		want   bool
+	}{
+		{name: "booléen vrai", claims: map[string]interface{}{"email_verified": true}, want: true},
+		{name: "chaîne vraie", claims: map[string]interface{}{"email_verified": "true"}, want: true},
+		{name: "booléen faux", claims: map[string]interface{}{"email_verified": false}},
+		{name: "chaîne fausse", claims: map[string]interface{}{"email_verified": "false"}},
+		{name: "claim absent", claims: map[string]interface{}{"email": "alice@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClaimEmailVerified(tt.claims); got != tt.want {
+				t.Fatalf("ClaimEmailVerified = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+// Organigramme
+const (
+	OrgMaxDepth       = 100        // Niveaux hiérarchiques parcourus au maximum (garde-fou)
+	orgPeopleChunk    = 1000       // Fiches chargées par requête lors de l'export
+	orgManagerLockKey = 0x6f726763 // Verrou consultatif sérialisant les changements de responsable
+)
+
+var (
+	ErrOrgPersonNotFound = errors.New("Personne introuvable dans l'organigramme")
+	ErrManagerNotFound   = errors.New("Responsable introuvable")
+	ErrManagerSelf       = errors.New("Un utilisateur ne peut pas être son propre responsable")
+	ErrManagerCycle      = errors.New("Ce rattachement créerait une boucle dans l'organigramme")
+)
+
+// OrgTreeParams périmètre de l'export de l'organigramme
+type OrgTreeParams struct {
+	RootID     uint   // Branche d'une personne (0 = organisation entière)
+	Department string // Sous-arbre d'un département : ses membres rattachés au premier responsable du département
+	Depth      int    // Niveaux développés sous les racines (0 = tous)
+	Flat       bool   // Liste à plat (id, manager_id) plutôt qu'arborescence
+}
+
+// OrgService rattachements hiérarchiques et organigramme
+type OrgService struct {
+	db        *gorm.DB
+	directory *DirectoryService
+}
+
+// NewOrgService crée une nouvelle instance de OrgService
+func NewOrgService(db *gorm.DB) *OrgService {
+	return &OrgService{db: db, directory: NewDirectoryService(db)}
+}
+
+// ============ RATTACHEMENTS ============
+
+// ValidateManager vérifie qu'un utilisateur peut être rattaché à ce responsable
+func (s *OrgService) ValidateManager(userID, managerID uint) error {
+	return validateManager(s.db, userID, managerID)
+}
+
+func validateManager(db *gorm.DB, userID, managerID uint) error {
+	if userID == managerID {
+		return ErrManagerSelf
+	}
+	var manager models.User
+	if err := db.Select("id").Where("id = ? AND is_service_account = ?", managerID, false).First(&manager).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrManagerNotFound
+		}
+		return err
+	}
+
+	// La chaîne hiérarchique du futur responsable ne doit pas passer par l'utilisateur
+	var cycle bool
+	if err := db.Raw(`WITH RECURSIVE chain(id, manager_id, depth) AS (
+			SELECT id, manager_id, 0 FROM users WHERE id = ?
+			UNION ALL
+			SELECT u.id, u.manager_id, c.depth + 1 FROM users u JOIN chain c ON u.id = c.manager_id WHERE c.depth < ?
+		)
+		SELECT EXISTS (SELECT 1 FROM chain WHERE id = ?)`, managerID, OrgMaxDepth, userID).
+		Scan(&cycle).Error; err != nil {
+		return err
+	}
+	if cycle {
+		return ErrManagerCycle
+	}
+	return nil
+}
+
+// SetManager rattache un utilisateur à son responsable (nil = aucun). changed est faux si le
+// rattachement était déjà en place.
+func (s *OrgService) SetManager(userID uint, managerID *uint, source string) (changed bool, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Deux rattachements croisés simultanés ne doivent pas former une boucle
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", orgManagerLockKey).Error; err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Select("id", "manager_id").First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrgPersonNotFound
+			}
+			return err
+		}
+		if sameManager(user.ManagerID, managerID) {
+			return nil
+		}
+		if managerID != nil {
+			if err := validateManager(tx, userID, *managerID); err != nil {
+				return err
+			}
+		} else {
+			source = ""
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"manager_id":     managerID,
+			"manager_source": source,
+		}).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+// ReleaseManager retire le responsable d'un utilisateur s'il provient de cette source
+// (une source d'identité ne retire pas un rattachement saisi par un administrateur)
+func (s *OrgService) ReleaseManager(userID uint, source string) error {
+	return s.db.Model(&models.User{}).
+		Where("id = ? AND manager_id IS NOT NULL AND manager_source = ?", userID, source).
+		Updates(map[string]interface{}{"manager_id": nil, "manager_source": ""}).Error
+}
+
+// ResolveManager retrouve un responsable par email, identifiant, identifiant SSO ou ID
+func (s *OrgService) ResolveManager(ref string) (uint, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return 0, ErrManagerNotFound
+	}
+	type lookup struct {
+		query string
+		value interface{}
+	}
+	lower := strings.ToLower(ref)
+	lookups := []lookup{
+		{"LOWER(email) = ?", lower},
+		{"LOWER(username) = ?", lower},
+		{"sso_id = ?", ref},
+	}
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		lookups = append(lookups, lookup{"id = ?", id})
+	}
+
+	for _, lookup := range lookups {
+		var ids []uint
+		if err := s.db.Model(&models.User{}).
+			Where("is_service_account = ?", false).
+			Where(lookup.query, lookup.value).
+			Limit(2).Pluck("id", &ids).Error; err != nil {
+			return 0, err
+		}
+		if len(ids) == 1 {
+			return ids[0], nil
+		}
+	}
+	return 0, ErrManagerNotFound
+}
+
+// SyncManagerReference applique le responsable transmis par une source d'identité (SCIM, SAML, OIDC).
+// Une référence vide retire le rattachement précédemment posé par cette même source.
+func (s *OrgService) SyncManagerReference(userID uint, ref, source string) error {
+	if strings.TrimSpace(ref) == "" {
+		return s.ReleaseManager(userID, source)
+	}
+	managerID, err := s.ResolveManager(ref)
+	if err != nil {
+		return err
+	}
+	_, err = s.SetManager(userID, &managerID, source)
+	return err
+}
+
+func sameManager(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// ============ ORGANIGRAMME ============
+
+// orgEntry rattachement d'un utilisateur chargé en mémoire
+type orgEntry struct {
+	ID        uint
+	ManagerID *uint
+	Listed    bool // Présent dans l'annuaire pour l'utilisateur qui consulte
+	InScope   bool // Membre du département demandé
+}
+
+// orgGraph rattachements entre personnes visibles : les personnes masquées ou hors périmètre
+// sont court-circuitées et leurs collaborateurs rattachés au premier responsable visible
+type orgGraph struct {
+	roots    []uint
+	parent   map[uint]uint // 0 = racine
+	children map[uint][]uint
+	teamSize map[uint]int
+}
+
+func (g *orgGraph) contains(id uint) bool {
+	_, ok := g.parent[id]
+	return ok
+}
+
+// graph charge les rattachements de toute l'organisation, dans l'ordre alphabétique
+func (s *OrgService) graph(viewer DirectoryViewer, department string) (*orgGraph, error) {
+	listed, args := directoryListedSQL(viewer)
+	selectSQL := "u.id, u.manager_id, COALESCE(" + listed + ", false) AS listed"
+	if department != "" {
+		visible, visibleArgs := profileFieldVisibleSQL(models.ProfileFieldDepartment, viewer)
+		selectSQL += ", COALESCE(u.department = ? AND " + visible + ", false) AS in_scope"
+		args = append(append(args, department), visibleArgs...)
+	} else {
+		selectSQL += ", TRUE AS in_scope"
+	}
+
+	var entries []orgEntry
+	if err := s.db.Table("users u").
+		Select(selectSQL, args...).
+		Joins("LEFT JOIN profile_privacies pp ON pp.user_id = u.id").
+		Where("u.deleted_at IS NULL").
+		Order("lower(u.last_name), lower(u.first_name), u.id").
+		Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	managers := make(map[uint]*uint, len(entries))
+	kept := make(map[uint]bool, len(entries))
+	var order []uint
+	for _, entry := range entries {
+		managers[entry.ID] = entry.ManagerID
+		if entry.Listed && entry.InScope {
+			kept[entry.ID] = true
+			order = append(order, entry.ID)
+		}
+	}
+
+	g := &orgGraph{
+		parent:   make(map[uint]uint, len(order)),
+		children: make(map[uint][]uint),
+		teamSize: make(map[uint]int, len(order)),
+	}
+	for _, id := range order {
+		parent := uint(0)
+		current := managers[id]
+		for depth := 0; current != nil && depth < OrgMaxDepth; depth++ {
+			if kept[*current] {
+				parent = *current
+				break
+			}
+			current = managers[*current]
+		}
+		g.parent[id] = parent
+		if parent == 0 {
+			g.roots = append(g.roots, id)
+		} else {
+			g.children[parent] = append(g.children[parent], id)
+		}
+	}
+	for _, root := range g.roots {
+		g.countTeam(root)
+	}
+	return g, nil
+}
+
+func (g *orgGraph) countTeam(id uint) int {
+	size := 0
+	for _, child := range g.children[id] {
+		size += 1 + g.countTeam(child)
+	}
+	g.teamSize[id] = size
+	return size
+}
+
+// nodes construit les fiches (champs masqués vidés) des personnes données, dans l'ordre
+func (s *OrgService) nodes(g *orgGraph, ids []uint, viewer DirectoryViewer) ([]*models.OrgChartNode, error) {
+	nodes := make([]*models.OrgChartNode, 0, len(ids))
+	for start := 0; start < len(ids); start += orgPeopleChunk {
+		end := start + orgPeopleChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		people, err := s.directory.People(ids[start:end], viewer)
+		if err != nil {
+			return nil, err
+		}
+		for _, person := range people {
+			node := &models.OrgChartNode{
+				DirectoryPerson: person,
+				ReportCount:     len(g.children[person.ID]),
+				TeamSize:        g.teamSize[person.ID],
+			}
+			if parent := g.parent[person.ID]; parent != 0 {
+				node.ManagerID = &parent
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// ChainOfCommand responsables successifs d'une personne, du responsable direct au sommet
+func (s *OrgService) ChainOfCommand(userID uint, viewer DirectoryViewer) ([]*models.OrgChartNode, error) {
+	g, err := s.graph(viewer, "")
+	if err != nil {
+		return nil, err
+	}
+	if !g.contains(userID) {
+		return nil, ErrOrgPersonNotFound
+	}
+
+	var chain []uint
+	current := g.parent[userID]
+	for depth := 0; current != 0 && depth < OrgMaxDepth; depth++ {
+		chain = append(chain, current)
+		current = g.parent[current]
+	}
+	return s.nodes(g, chain, viewer)
+}
+
+// DirectReports collaborateurs directs d'une personne
+func (s *OrgService) DirectReports(userID uint, viewer DirectoryViewer) ([]*models.OrgChartNode, error) {
+	g, err := s.graph(viewer, "")
+	if err != nil {
+		return nil, err
+	}
+	if !g.contains(userID) {
+		return nil, ErrOrgPersonNotFound
+	}
+	return s.nodes(g, g.children[userID], viewer)
+}
+
+// Tree exporte l'organigramme (organisation entière, branche d'une personne ou département)
+func (s *OrgService) Tree(params OrgTreeParams, viewer DirectoryViewer) (*models.OrgChart, error) {
+	g, err := s.graph(viewer, params.Department)
+	if err != nil {
+		return nil, err
+	}
+
+	chart := &models.OrgChart{Department: params.Department, Depth: params.Depth}
+	roots := g.roots
+	if params.RootID != 0 {
+		if !g.contains(params.RootID) {
+			return nil, ErrOrgPersonNotFound
+		}
+		roots = []uint{params.RootID}
+		chart.RootID = &params.RootID
+	}
+
+	// Parcours en largeur, limité à Depth niveaux
+	included := append([]uint{}, roots...)
+	level := roots
+	for depth := 1; len(level) > 0 && (params.Depth <= 0 || depth < params.Depth) && depth < OrgMaxDepth; depth++ {
+		var next []uint
+		for _, id := range level {
+			next = append(next, g.children[id]...)
+		}
+		included = append(included, next...)
+		level = next
+	}
+
+	nodes, err := s.nodes(g, included, viewer)
+	if err != nil {
+		return nil, err
+	}
+	chart.Total = len(nodes)
+	if params.Flat {
+		chart.Nodes = nodes
+		return chart, nil
+	}
+
+	byID := make(map[uint]*models.OrgChartNode, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+	isRoot := make(map[uint]bool, len(roots))
+	for _, id := range roots {
+		isRoot[id] = true
+		if node, ok := byID[id]; ok {
+			chart.Roots = append(chart.Roots, node)
+		}
+	}
+	for _, node := range nodes {
+		if isRoot[node.ID] || node.ManagerID == nil {
+			continue
+		}
+		if parent, ok := byID[*node.ManagerID]; ok {
+			parent.Reports = append(parent.Reports, node)
+		}
+	}
+	if chart.Roots == nil {
+		chart.Roots = []*models.OrgChartNode{}
+	}
+	return chart, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"airboard/models"
+	"airboard/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Erreurs de politique de mots de passe (en plus des règles de composition)
+var (
+	ErrPasswordReused   = errors.New("mot de passe déjà utilisé récemment")
+	ErrPasswordBreached = errors.New("mot de passe présent dans une fuite de données connue, choisissez-en un autre")
+)
+
+// PasswordPolicyService applique la politique de mots de passe configurée dans les paramètres de l'application :
+// composition, historique des anciens mots de passe, liste de fuites hors ligne et durée de validité
+type PasswordPolicyService struct {
+	db       *gorm.DB
+	security *utils.AuthSecurityManager
+	breached *BreachedPasswordList // nil si aucune liste n'est configurée
+}
+
+// NewPasswordPolicyService crée une nouvelle instance de PasswordPolicyService
+func NewPasswordPolicyService(db *gorm.DB, breached *BreachedPasswordList) *PasswordPolicyService {
+	return &PasswordPolicyService{
+		db:       db,
+		security: utils.NewAuthSecurityManager(),
+		breached: breached,
+	}
+}
+
+// settings retourne les paramètres de l'application (valeurs par défaut si absents)
+func (s *PasswordPolicyService) settings() models.AppSettings {
+	settings := models.AppSettings{
+		PasswordMinLength:      8,
+		PasswordRequireUpper:   true,
+		PasswordRequireLower:   true,
+		PasswordRequireDigit:   true,
+		PasswordRequireSpecial: true,
+		PasswordHistoryDepth:   5,
+		PasswordCheckBreached:  true,
+	}
+	s.db.First(&settings)
+	return settings
+}
+
+// Policy retourne la politique effective
+func (s *PasswordPolicyService) Policy() models.PasswordPolicyInfo {
+	settings := s.settings()
+	defaults := utils.DefaultPasswordPolicy()
+
+	minLength := settings.PasswordMinLength
+	if minLength < defaults.MinLength {
+		minLength = defaults.MinLength
+	}
+	return models.PasswordPolicyInfo{
+		MinLength:      minLength,
+		MaxLength:      defaults.MaxLength,
+		RequireUpper:   settings.PasswordRequireUpper,
+		RequireLower:   settings.PasswordRequireLower,
+		RequireDigit:   settings.PasswordRequireDigit,
+		RequireSpecial: settings.PasswordRequireSpecial,
+		MaxAgeDays:     settings.PasswordMaxAgeDays,
+		HistoryDepth:   settings.PasswordHistoryDepth,
+		CheckBreached:  settings.PasswordCheckBreached && s.breached != nil,
+	}
+}
+
+// Validate vérifie un nouveau mot de passe. user est nil lors d'une inscription ;
+// sinon le mot de passe actuel et les anciens mots de passe de l'historique sont refusés.
+func (s *PasswordPolicyService) Validate(password string, user *models.User) error {
+	policy := s.Policy()
+
+	rules := utils.DefaultPasswordPolicy()
+	rules.MinLength = policy.MinLength
+	rules.RequireUpper = policy.RequireUpper
+	rules.RequireLower = policy.RequireLower
+	rules.RequireDigit = policy.RequireDigit
+	rules.RequireSpecial = policy.RequireSpecial
+	if err := s.security.ValidatePasswordWithPolicy(password, rules); err != nil {
+		return err
+	}
+
+	if policy.CheckBreached {
+		found, err := s.breached.Contains(password)
+		if err != nil {
+			// Une liste illisible ne doit pas empêcher les changements de mot de passe
+			log.Printf("[PasswordPolicy] Erreur lecture de la liste de fuites: %v", err)
+		} else if found {
+			return ErrPasswordBreached
+		}
+	}
+
+	if user != nil && user.ID != 0 && policy.HistoryDepth > 0 {
+		if user.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil {
+			return ErrPasswordReused
+		}
+
+		var history []models.PasswordHistory
+		s.db.Where("user_id = ?", user.ID).Order("created_at DESC, id DESC").Limit(policy.HistoryDepth).Find(&history)
+		for _, entry := range history {
+			if entry.PasswordHash != user.Password && bcrypt.CompareHashAndPassword([]byte(entry.PasswordHash), []byte(password)) == nil {
+				return fmt.Errorf("%w (%d derniers mots de passe interdits)", ErrPasswordReused, policy.HistoryDepth)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RecordPassword enregistre le nouveau hash dans l'historique (limité à la profondeur configurée)
+// et met à jour la date de changement du mot de passe
+func (s *PasswordPolicyService) RecordPassword(userID uint, hash string) error {
+	now := time.Now()
+	if err := s.db.Model(&models.User{}).Where("id = ?", userID).Update("password_changed_at", now).Error; err != nil {
+		return err
+	}
+
+	depth := s.settings().PasswordHistoryDepth
+	if depth <= 0 {
+		return s.db.Where("user_id = ?", userID).Delete(&models.PasswordHistory{}).Error
+	}
+
+	if err := s.db.Create(&models.PasswordHistory{UserID: userID, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+
+	var keep []uint
+	s.db.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").Limit(depth).Pluck("id", &keep)
+	return s.db.Where("user_id = ? AND id NOT IN ?", userID, keep).Delete(&models.PasswordHistory{}).Error
+}
+
+// IsExpired indique si le mot de passe local de l'utilisateur a dépassé la durée de validité configurée
+func (s *PasswordPolicyService) IsExpired(user *models.User) bool {
+	if user.Password == "" || user.SSOProvider != "" || user.IsServiceAccount {
+		return false
+	}
+
+	maxAge := s.settings().PasswordMaxAgeDays
+	if maxAge <= 0 {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > time.Duration(maxAge)*24*time.Hour
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"airboard/models"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Durée de validité du cache des rôles (les autres instances voient les modifications après ce délai)
+const roleCacheTTL = time.Minute
+
+// PermissionService résout les permissions effectives des utilisateurs
+type PermissionService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	roles    map[uint]models.Role
+	byName   map[string]models.Role
+	loadedAt time.Time
+}
+
+// NewPermissionService crée une nouvelle instance de PermissionService
+func NewPermissionService(db *gorm.DB) *PermissionService {
+	return &PermissionService{db: db}
+}
+
+// SeedSystemRoles crée les rôles système équivalents aux anciens rôles admin, editor, user et group admin.
+// Les utilisateurs existants conservent leur champ Role, qui désigne désormais le rôle système de même nom.
+func (s *PermissionService) SeedSystemRoles() error {
+	displayNames := map[string]string{
+		models.RoleAdmin:      "Administrateur",
+		models.RoleEditor:     "Éditeur",
+		models.RoleUser:       "Utilisateur",
+		models.RoleGroupAdmin: "Administrateur de groupe",
+	}
+
+	for name, permissions := range models.SystemRolePermissions {
+		var role models.Role
+		err := s.db.Where("name = ?", name).First(&role).Error
+		if err == gorm.ErrRecordNotFound {
+			role = models.Role{
+				Name:        name,
+				DisplayName: displayNames[name],
+				Permissions: strings.Join(permissions, ","),
+				IsSystem:    true,
+			}
+			if err := s.db.Create(&role).Error; err != nil {
+				return err
+			}
+			log.Printf("✅ Rôle système créé: %s", name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Le rôle admin conserve toujours toutes les permissions
+		if name == models.RoleAdmin && role.Permissions != models.PermAll {
+			s.db.Model(&role).Updates(map[string]interface{}{"permissions": models.PermAll, "is_system": true})
+		}
+	}
+
+	s.Invalidate()
+	return nil
+}
+
+// Invalidate vide le cache des rôles
+func (s *PermissionService) Invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// loadRoles recharge le cache des rôles si nécessaire
+func (s *PermissionService) loadRoles() (map[uint]models.Role, map[string]models.Role) {
+	s.mu.RLock()
+	if time.Since(s.loadedAt) < roleCacheTTL {
+		roles, byName := s.roles, s.byName
+		s.mu.RUnlock()
+		return roles, byName
+	}
+	s.mu.RUnlock()
+
+	var list []models.Role
+	if err := s.db.Find(&list).Error; err != nil {
+		log.Printf("[PERMISSIONS] Erreur chargement des rôles: %v", err)
+	}
+
+	roles := make(map[uint]models.Role, len(list))
+	byName := make(map[string]models.Role, len(list))
+	for _, role := range list {
+		roles[role.ID] = role
+		byName[role.Name] = role
+	}
+
+	s.mu.Lock()
+	s.roles, s.byName, s.loadedAt = roles, byName, time.Now()
+	s.mu.Unlock()
+
+	return roles, byName
+}
+
+// Resolve calcule les permissions effectives d'un utilisateur :
+// rôle de base (User.Role), rôle group_admin sur les groupes administrés, puis rôles attribués
+// directement ou via un groupe d'appartenance.
+func (s *PermissionService) Resolve(userID uint, baseRole string, managedGroupIDs []uint) *models.PermissionSet {
+	roles, byName := s.loadRoles()
+	set := models.NewPermissionSet()
+
+	if role, ok := byName[baseRole]; ok {
+		for _, permission := range role.PermissionList() {
+			set.Grant(permission, models.ScopeGlobal, 0)
+		}
+	}
+
+	if len(managedGroupIDs) > 0 {
+		if role, ok := byName[models.RoleGroupAdmin]; ok {
+			for _, permission := range role.PermissionList() {
+				for _, groupID := range managedGroupIDs {
+					set.Grant(permission, models.ScopeGroup, groupID)
+				}
+			}
+		}
+	}
+
+	var assignments []models.RoleAssignment
+	s.db.Where("user_id = ? OR group_id IN (?)", userID,
+		s.db.Table("user_groups").Select("group_id").Where("user_id = ?", userID)).
+		Find(&assignments)
+
+	for _, assignment := range assignments {
+		role, ok := roles[assignment.RoleID]
+		if !ok {
+			continue
+		}
+		var scopeID uint
+		if assignment.ScopeID != nil {
+			scopeID = *assignment.ScopeID
+		}
+		for _, permission := range role.PermissionList() {
+			set.Grant(permission, assignment.ScopeType, scopeID)
+		}
+	}
+
+	return set
+}
+
+// IsKnownPermission indique si une permission (ou un wildcard) est valide
+func IsKnownPermission(permission string) bool {
+	if permission == models.PermAll {
+		return true
+	}
+	for _, info := range models.AvailablePermissions {
+		if info.Key == permission ||
+			(strings.HasSuffix(permission, ".*") && strings.HasPrefix(info.Key, strings.TrimSuffix(permission, "*"))) {
+			return true
+		}
+	}
+	return false
+}
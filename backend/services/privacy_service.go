@@ -0,0 +1,592 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"airboard/models"
+	"airboard/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	privacyClaimLease   = 30 * time.Minute // Délai après lequel une demande restée « processing » est reprise
+	privacyMaxAttempts  = 3
+	privacyErasedNotice = "[Contenu supprimé à la demande de son auteur]"
+)
+
+// ErrPrivacyRequestInProgress une demande du même type est déjà en cours pour l'utilisateur
+var ErrPrivacyRequestInProgress = errors.New("privacy request already in progress")
+
+// PrivacyService export des données personnelles (archive ZIP) et effacement selon models.PrivacyErasurePolicy
+type PrivacyService struct {
+	db              *gorm.DB
+	exportDir       string
+	exportRetention time.Duration
+	notifications   *NotificationService
+}
+
+// NewPrivacyService crée le service RGPD
+func NewPrivacyService(db *gorm.DB, exportDir string, exportRetention time.Duration) *PrivacyService {
+	return &PrivacyService{
+		db:              db,
+		exportDir:       exportDir,
+		exportRetention: exportRetention,
+		notifications:   NewNotificationService(db),
+	}
+}
+
+// CreateRequest enregistre une demande ; les effacements demandés par l'utilisateur attendent une validation
+func (s *PrivacyService) CreateRequest(userID uint, requestType string, requestedBy uint, reason string, needsApproval bool) (*models.PrivacyRequest, error) {
+	var pending int64
+	s.db.Model(&models.PrivacyRequest{}).
+		Where("user_id = ? AND type = ? AND status IN ?", userID, requestType,
+			[]string{models.PrivacyStatusPendingApproval, models.PrivacyStatusQueued, models.PrivacyStatusProcessing}).
+		Count(&pending)
+	if pending > 0 {
+		return nil, ErrPrivacyRequestInProgress
+	}
+
+	status := models.PrivacyStatusQueued
+	if needsApproval {
+		status = models.PrivacyStatusPendingApproval
+	}
+	request := &models.PrivacyRequest{
+		UserID:        userID,
+		Type:          requestType,
+		Status:        status,
+		RequestedByID: requestedBy,
+		Reason:        reason,
+		Summary:       "[]",
+	}
+	if err := s.db.Create(request).Error; err != nil {
+		return nil, err
+	}
+
+	if status == models.PrivacyStatusQueued {
+		go s.ProcessQueued()
+	}
+	return request, nil
+}
+
+// Review valide (mise en file) ou refuse une demande d'effacement en attente
+func (s *PrivacyService) Review(request *models.PrivacyRequest, reviewerID uint, approve bool, comment string) error {
+	if request.Status != models.PrivacyStatusPendingApproval {
+		return fmt.Errorf("demande non soumise à validation (statut %s)", request.Status)
+	}
+
+	now := time.Now()
+	status := models.PrivacyStatusRejected
+	if approve {
+		status = models.PrivacyStatusQueued
+	}
+	result := s.db.Model(request).
+		Where("status = ?", models.PrivacyStatusPendingApproval).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"reviewed_by_id": reviewerID,
+			"reviewed_at":    now,
+			"review_comment": comment,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("demande déjà traitée")
+	}
+
+	if approve {
+		go s.ProcessQueued()
+	} else {
+		s.notifications.NotifyPrivacyRequestRejected(request.UserID, comment)
+	}
+	return nil
+}
+
+// ProcessQueued traite les demandes en file (et reprend celles dont le traitement a été interrompu)
+func (s *PrivacyService) ProcessQueued() int {
+	var due []models.PrivacyRequest
+	s.db.Where("(status = ? OR (status = ? AND started_at < ?)) AND attempts < ?",
+		models.PrivacyStatusQueued, models.PrivacyStatusProcessing, time.Now().Add(-privacyClaimLease), privacyMaxAttempts).
+		Order("created_at").
+		Limit(20).
+		Find(&due)
+
+	processed := 0
+	for i := range due {
+		request := &due[i]
+
+		// Réservation optimiste : une seule instance traite la demande
+		now := time.Now()
+		result := s.db.Model(&models.PrivacyRequest{}).
+			Where("id = ? AND status = ? AND attempts = ?", request.ID, request.Status, request.Attempts).
+			Updates(map[string]interface{}{
+				"status":     models.PrivacyStatusProcessing,
+				"attempts":   request.Attempts + 1,
+				"started_at": now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		request.Attempts++
+		request.StartedAt = &now
+
+		var summary []models.PrivacyCategoryResult
+		var err error
+		switch request.Type {
+		case models.PrivacyRequestExport:
+			summary, err = s.export(request)
+		case models.PrivacyRequestErasure:
+			summary, err = s.erase(request.UserID)
+		default:
+			err = fmt.Errorf("type de demande inconnu: %s", request.Type)
+		}
+		s.finish(request, summary, err)
+		processed++
+	}
+	return processed
+}
+
+// finish enregistre le résultat du traitement
+func (s *PrivacyService) finish(request *models.PrivacyRequest, summary []models.PrivacyCategoryResult, err error) {
+	now := time.Now()
+	updates := map[string]interface{}{}
+	if err != nil {
+		log.Printf("[Privacy] Échec du traitement de la demande %d (%s, tentative %d): %v", request.ID, request.Type, request.Attempts, err)
+		updates["error"] = err.Error()
+		updates["status"] = models.PrivacyStatusQueued
+		if request.Attempts >= privacyMaxAttempts {
+			updates["status"] = models.PrivacyStatusFailed
+			updates["completed_at"] = now
+		}
+	} else {
+		encoded, _ := json.Marshal(summary)
+		updates["summary"] = string(encoded)
+		updates["status"] = models.PrivacyStatusCompleted
+		updates["error"] = ""
+		updates["completed_at"] = now
+		if request.Type == models.PrivacyRequestExport {
+			expiresAt := now.Add(s.exportRetention)
+			updates["file_path"] = request.FilePath
+			updates["file_size"] = request.FileSize
+			updates["expires_at"] = expiresAt
+		}
+	}
+	if err := s.db.Model(&models.PrivacyRequest{}).Where("id = ?", request.ID).Updates(updates).Error; err != nil {
+		log.Printf("[Privacy] Erreur mise à jour de la demande %d: %v", request.ID, err)
+		return
+	}
+
+	if err == nil && request.Type == models.PrivacyRequestExport {
+		s.notifications.NotifyPrivacyExportReady(request.UserID, request.ID)
+	}
+}
+
+// StartWorker lance le traitement périodique de la file et la purge des archives expirées
+func (s *PrivacyService) StartWorker() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.ProcessQueued()
+			s.purgeExpiredExports()
+		}
+	}()
+}
+
+// purgeExpiredExports supprime les archives dont la durée de mise à disposition est dépassée
+func (s *PrivacyService) purgeExpiredExports() {
+	var expired []models.PrivacyRequest
+	s.db.Where("type = ? AND status = ? AND expires_at < ?", models.PrivacyRequestExport, models.PrivacyStatusCompleted, time.Now()).
+		Find(&expired)
+	for _, request := range expired {
+		s.removeExport(&request)
+	}
+}
+
+// removeExport supprime l'archive d'une demande d'export
+func (s *PrivacyService) removeExport(request *models.PrivacyRequest) {
+	if request.FilePath != "" {
+		if err := utils.RemoveFile(request.FilePath); err != nil {
+			log.Printf("[Privacy] Erreur suppression de l'archive %s: %v", request.FilePath, err)
+			return
+		}
+	}
+	s.db.Model(&models.PrivacyRequest{}).Where("id = ?", request.ID).
+		Updates(map[string]interface{}{"status": models.PrivacyStatusExpired, "file_path": ""})
+}
+
+// ExportPath retourne le chemin de l'archive d'une demande d'export terminée et non expirée
+func (s *PrivacyService) ExportPath(request *models.PrivacyRequest) (string, bool) {
+	if request.Type != models.PrivacyRequestExport || request.Status != models.PrivacyStatusCompleted || request.FilePath == "" {
+		return "", false
+	}
+	if request.ExpiresAt != nil && time.Now().After(*request.ExpiresAt) {
+		return "", false
+	}
+	if _, err := os.Stat(request.FilePath); err != nil {
+		return "", false
+	}
+	return request.FilePath, true
+}
+
+// privacyExportFile fichier JSON de l'archive d'export
+type privacyExportFile struct {
+	name     string
+	category string
+	data     interface{}
+	count    int64
+}
+
+// export génère l'archive ZIP (un fichier JSON par catégorie) des données de l'utilisateur
+func (s *PrivacyService) export(request *models.PrivacyRequest) ([]models.PrivacyCategoryResult, error) {
+	var user models.User
+	if err := s.db.Preload("Groups").Preload("AdminOfGroups").First(&user, request.UserID).Error; err != nil {
+		return nil, fmt.Errorf("utilisateur introuvable: %w", err)
+	}
+	user.Password = ""
+
+	files, err := s.collect(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.exportDir, 0o750); err != nil {
+		return nil, err
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.exportDir, fmt.Sprintf("export-%d-%d-%s.zip", user.ID, request.ID, hex.EncodeToString(suffix)))
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	archive := zip.NewWriter(out)
+
+	summary := make([]models.PrivacyCategoryResult, 0, len(files))
+	manifest := map[string]interface{}{
+		"generated_at": time.Now(),
+		"user_id":      user.ID,
+		"username":     user.Username,
+		"request_id":   request.ID,
+	}
+	for _, file := range files {
+		summary = append(summary, models.PrivacyCategoryResult{Category: file.category, Action: "export", Count: file.count})
+		if err = writeZipJSON(archive, file.name, file.data); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		manifest["categories"] = summary
+		err = writeZipJSON(archive, "manifest.json", manifest)
+	}
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	request.FilePath = path
+	request.FileSize = info.Size()
+	return summary, nil
+}
+
+// writeZipJSON ajoute un fichier JSON indenté à l'archive
+func writeZipJSON(archive *zip.Writer, name string, data interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// collect rassemble les données exportées, catégorie par catégorie
+func (s *PrivacyService) collect(user *models.User) ([]privacyExportFile, error) {
+	var favorites []struct {
+		ID   uint   `json:"id"`
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := s.db.Table("applications").
+		Select("applications.id, applications.name, applications.url").
+		Joins("JOIN user_favorites ON user_favorites.application_id = applications.id").
+		Where("user_favorites.user_id = ?", user.ID).
+		Scan(&favorites).Error; err != nil {
+		return nil, err
+	}
+
+	var comments []struct {
+		ID         uint      `json:"id"`
+		EntityType string    `json:"entity_type"`
+		EntityID   uint      `json:"entity_id"`
+		ParentID   *uint     `json:"parent_id"`
+		Content    string    `json:"content"`
+		IsApproved bool      `json:"is_approved"`
+		CreatedAt  time.Time `json:"created_at"`
+		UpdatedAt  time.Time `json:"updated_at"`
+	}
+	if err := s.db.Model(&models.Comment{}).Where("user_id = ?", user.ID).Order("created_at").Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	var newsReactions []struct {
+		NewsID       uint      `json:"news_id"`
+		NewsTitle    string    `json:"news_title"`
+		ReactionType string    `json:"reaction_type"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+	if err := s.db.Table("news_reactions").
+		Select("news_reactions.news_id, news.title AS news_title, news_reactions.reaction_type, news_reactions.created_at").
+		Joins("LEFT JOIN news ON news.id = news_reactions.news_id").
+		Where("news_reactions.user_id = ?", user.ID).
+		Order("news_reactions.created_at").
+		Scan(&newsReactions).Error; err != nil {
+		return nil, err
+	}
+	var feedbacks []struct {
+		EntityType   string    `json:"entity_type"`
+		EntityID     uint      `json:"entity_id"`
+		FeedbackType string    `json:"feedback_type"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+	if err := s.db.Model(&models.Feedback{}).Where("user_id = ?", user.ID).Order("created_at").Scan(&feedbacks).Error; err != nil {
+		return nil, err
+	}
+
+	var pollVotes []struct {
+		PollID     uint      `json:"poll_id"`
+		PollTitle  string    `json:"poll_title"`
+		OptionID   uint      `json:"option_id"`
+		OptionText string    `json:"option_text"`
+		VotedAt    time.Time `json:"voted_at"`
+	}
+	if err := s.db.Table("poll_votes").
+		Select("poll_votes.poll_id, polls.title AS poll_title, poll_votes.poll_option_id AS option_id, poll_options.text AS option_text, poll_votes.voted_at").
+		Joins("LEFT JOIN polls ON polls.id = poll_votes.poll_id").
+		Joins("LEFT JOIN poll_options ON poll_options.id = poll_votes.poll_option_id").
+		Where("poll_votes.user_id = ?", user.ID).
+		Order("poll_votes.voted_at").
+		Scan(&pollVotes).Error; err != nil {
+		return nil, err
+	}
+
+	var acknowledgements []models.ContentAcknowledgement
+	if err := s.db.Where("user_id = ?", user.ID).Order("acknowledged_at").Find(&acknowledgements).Error; err != nil {
+		return nil, err
+	}
+
+	// Messages envoyés et messages directs reçus
+	var chatMessages []struct {
+		ID          uint      `json:"id"`
+		Direction   string    `json:"direction"`
+		SenderID    uint      `json:"sender_id"`
+		RecipientID *uint     `json:"recipient_id,omitempty"`
+		GroupID     *uint     `json:"group_id,omitempty"`
+		Type        string    `json:"type"`
+		Content     string    `json:"content"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+	if err := s.db.Model(&models.ChatMessage{}).
+		Select("id, CASE WHEN sender_id = ? THEN 'sent' ELSE 'received' END AS direction, sender_id, recipient_id, group_id, type, content, created_at", user.ID).
+		Where("sender_id = ? OR recipient_id = ?", user.ID, user.ID).
+		Order("created_at").
+		Scan(&chatMessages).Error; err != nil {
+		return nil, err
+	}
+
+	var notifications []models.Notification
+	if err := s.db.Where("user_id = ?", user.ID).Order("created_at").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+
+	var profile models.GamificationProfile
+	hasProfile := s.db.Where("user_id = ?", user.ID).First(&profile).Error == nil
+	var achievements []struct {
+		AchievementID uint      `json:"achievement_id"`
+		Name          string    `json:"name"`
+		UnlockedAt    time.Time `json:"unlocked_at"`
+	}
+	if err := s.db.Table("user_achievements").
+		Select("user_achievements.achievement_id, achievements.name, user_achievements.unlocked_at").
+		Joins("LEFT JOIN achievements ON achievements.id = user_achievements.achievement_id").
+		Where("user_achievements.user_id = ?", user.ID).
+		Order("user_achievements.unlocked_at").
+		Scan(&achievements).Error; err != nil {
+		return nil, err
+	}
+	var transactions []models.XPTransaction
+	if err := s.db.Where("user_id = ?", user.ID).Order("created_at").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	gamification := map[string]interface{}{
+		"achievements":    achievements,
+		"xp_transactions": transactions,
+	}
+	if hasProfile {
+		profile.User = models.User{}
+		gamification["profile"] = profile
+	}
+
+	return []privacyExportFile{
+		{name: "profile.json", category: "profile", data: user, count: 1},
+		{name: "favorites.json", category: "favorites", data: favorites, count: int64(len(favorites))},
+		{name: "comments.json", category: "comments", data: comments, count: int64(len(comments))},
+		{name: "reactions.json", category: "reactions", data: map[string]interface{}{"news_reactions": newsReactions, "feedbacks": feedbacks}, count: int64(len(newsReactions) + len(feedbacks))},
+		{name: "poll_votes.json", category: "poll_votes", data: pollVotes, count: int64(len(pollVotes))},
+		{name: "acknowledgements.json", category: "acknowledgements", data: acknowledgements, count: int64(len(acknowledgements))},
+		{name: "chat_messages.json", category: "chat_messages", data: chatMessages, count: int64(len(chatMessages))},
+		{name: "notifications.json", category: "notifications", data: notifications, count: int64(len(notifications))},
+		{name: "gamification.json", category: "gamification", data: gamification, count: int64(len(achievements) + len(transactions))},
+	}, nil
+}
+
+// erase applique models.PrivacyErasurePolicy aux données de l'utilisateur, dans une transaction
+func (s *PrivacyService) erase(userID uint) ([]models.PrivacyCategoryResult, error) {
+	var user models.User
+	if err := s.db.Unscoped().First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("utilisateur introuvable: %w", err)
+	}
+	avatarURL := user.AvatarURL
+
+	counts := map[string]int64{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		steps := []struct {
+			category string
+			run      func() *gorm.DB
+		}{
+			{"groups", func() *gorm.DB { return tx.Exec("DELETE FROM user_groups WHERE user_id = ?", userID) }},
+			{"groups", func() *gorm.DB { return tx.Exec("DELETE FROM group_admins WHERE user_id = ?", userID) }},
+			{"favorites", func() *gorm.DB { return tx.Exec("DELETE FROM user_favorites WHERE user_id = ?", userID) }},
+			{"comments", func() *gorm.DB {
+				return tx.Unscoped().Model(&models.Comment{}).Where("user_id = ?", userID).Update("content", privacyErasedNotice)
+			}},
+			{"reactions", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.NewsReaction{}) }},
+			{"reactions", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.Feedback{}) }},
+			{"chat_messages", func() *gorm.DB {
+				return tx.Unscoped().Model(&models.ChatMessage{}).Where("sender_id = ?", userID).
+					Updates(map[string]interface{}{"content": privacyErasedNotice, "type": "text"})
+			}},
+			{"notifications", func() *gorm.DB { return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Notification{}) }},
+			{"gamification", func() *gorm.DB {
+				return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.GamificationProfile{})
+			}},
+			{"gamification", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.UserAchievement{}) }},
+			{"gamification", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.XPTransaction{}) }},
+			{"activity", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.NewsRead{}) }},
+			{"activity", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.ApplicationClick{}) }},
+			{"security", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.PasswordHistory{}) }},
+			{"security", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.LoginDevice{}) }},
+			{"security", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.LoginLockout{}) }},
+			{"security", func() *gorm.DB { return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.APIToken{}) }},
+			{"security", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.FeedToken{}) }},
+			{"security", func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&models.SAMLSession{}) }},
+		}
+		for _, step := range steps {
+			result := step.run()
+			if result.Error != nil {
+				return fmt.Errorf("%s: %w", step.category, result.Error)
+			}
+			counts[step.category] += result.RowsAffected
+		}
+
+		// Données conservées (anonymisées par le biais du compte ou retenues)
+		var votes, acknowledgements, news, events, polls, media, auditLogs, requests int64
+		tx.Model(&models.PollVote{}).Where("user_id = ?", userID).Count(&votes)
+		tx.Model(&models.ContentAcknowledgement{}).Where("user_id = ?", userID).Count(&acknowledgements)
+		tx.Model(&models.News{}).Where("author_id = ?", userID).Count(&news)
+		tx.Model(&models.Event{}).Where("author_id = ?", userID).Count(&events)
+		tx.Model(&models.Poll{}).Where("author_id = ?", userID).Count(&polls)
+		tx.Model(&models.Media{}).Where("uploaded_by = ?", userID).Count(&media)
+		tx.Model(&models.AuditLog{}).Where("actor_id = ?", userID).Count(&auditLogs)
+		tx.Model(&models.PrivacyRequest{}).Where("user_id = ?", userID).Count(&requests)
+		counts["poll_votes"] = votes
+		counts["acknowledgements"] = acknowledgements
+		counts["authored_content"] = news + events + polls + media
+		counts["audit_logs"] = auditLogs
+		counts["privacy_requests"] = requests
+
+		// Compte conservé sous forme anonyme (intégrité des contenus partagés), puis désactivé et supprimé
+		tombstone := fmt.Sprintf("deleted-user-%d", userID)
+		if err := tx.Unscoped().Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":            tombstone,
+			"email":               tombstone + "@deleted.invalid",
+			"password":            "",
+			"first_name":          "Utilisateur",
+			"last_name":           "supprimé",
+			"sso_provider":        "",
+			"sso_id":              "",
+			"avatar_url":          "",
+			"phone":               "",
+			"department":          "",
+			"job_title":           "",
+			"location":            "",
+			"is_active":           false,
+			"last_login":          nil,
+			"password_changed_at": nil,
+			"manager_id":          nil,
+			"manager_source":      "",
+		}).Error; err != nil {
+			return fmt.Errorf("profile: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.ProfilePrivacy{}).Error; err != nil {
+			return fmt.Errorf("profile: %w", err)
+		}
+		if err := tx.Unscoped().Model(&models.User{}).Where("manager_id = ?", userID).
+			Updates(map[string]interface{}{"manager_id": nil, "manager_source": ""}).Error; err != nil {
+			return fmt.Errorf("profile: %w", err)
+		}
+		counts["profile"] = 1
+		if !user.DeletedAt.Valid {
+			if err := tx.Delete(&models.User{}, userID).Error; err != nil {
+				return fmt.Errorf("profile: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Fichiers : avatar local et archives d'export contenant les données de l'utilisateur
+	if strings.HasPrefix(avatarURL, "/uploads/avatars/") {
+		if err := utils.RemoveFile("." + avatarURL); err != nil {
+			log.Printf("[Privacy] Erreur suppression de l'avatar de l'utilisateur %d: %v", userID, err)
+		}
+	}
+	var exports []models.PrivacyRequest
+	s.db.Where("user_id = ? AND type = ? AND status = ?", userID, models.PrivacyRequestExport, models.PrivacyStatusCompleted).Find(&exports)
+	for _, request := range exports {
+		s.removeExport(&request)
+	}
+
+	summary := make([]models.PrivacyCategoryResult, 0, len(models.PrivacyErasurePolicy))
+	for _, rule := range models.PrivacyErasurePolicy {
+		summary = append(summary, models.PrivacyCategoryResult{Category: rule.Category, Action: rule.Action, Count: counts[rule.Category]})
+	}
+	return summary, nil
+}
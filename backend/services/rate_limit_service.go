@@ -0,0 +1,330 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	rateLimitPolicyTTL       = 30 * time.Second // Délai de prise en compte des politiques modifiées sur les autres instances
+	rateLimitMetricRetention = 30 * 24 * time.Hour
+)
+
+// RateLimitStore stocke les compteurs de requêtes par fenêtre
+type RateLimitStore interface {
+	// Increment incrémente le compteur et retourne sa nouvelle valeur ; la clé expire à expiresAt
+	Increment(key string, expiresAt time.Time) (int64, error)
+	// PurgeExpired supprime les compteurs des fenêtres terminées
+	PurgeExpired() error
+}
+
+// NewRateLimitStore retourne le stockage demandé : "postgres" (partagé entre instances) ou "memory"
+func NewRateLimitStore(db *gorm.DB, kind string) RateLimitStore {
+	if kind == "memory" {
+		return &memoryRateLimitStore{counters: make(map[string]*memoryRateLimitCounter)}
+	}
+	return &postgresRateLimitStore{db: db}
+}
+
+// postgresRateLimitStore compteurs partagés via une table PostgreSQL (upsert atomique)
+type postgresRateLimitStore struct {
+	db *gorm.DB
+}
+
+func (s *postgresRateLimitStore) Increment(key string, expiresAt time.Time) (int64, error) {
+	var count int64
+	err := s.db.Raw(`INSERT INTO rate_limit_counters (key, count, expires_at) VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET count = rate_limit_counters.count + 1
+		RETURNING count`, key, expiresAt).Scan(&count).Error
+	return count, err
+}
+
+func (s *postgresRateLimitStore) PurgeExpired() error {
+	return s.db.Where("expires_at < ?", time.Now()).Delete(&models.RateLimitCounter{}).Error
+}
+
+// memoryRateLimitStore compteurs locaux au processus (instance unique ou développement)
+type memoryRateLimitStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryRateLimitCounter
+}
+
+type memoryRateLimitCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func (s *memoryRateLimitStore) Increment(key string, expiresAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &memoryRateLimitCounter{expiresAt: expiresAt}
+		s.counters[key] = counter
+	}
+	counter.count++
+	return counter.count, nil
+}
+
+func (s *memoryRateLimitStore) PurgeExpired() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, counter := range s.counters {
+		if counter.expiresAt.Before(now) {
+			delete(s.counters, key)
+		}
+	}
+	return nil
+}
+
+// RateLimitDecision résultat de la vérification d'une requête
+type RateLimitDecision struct {
+	Policy    *models.RateLimitPolicy
+	Remaining int
+	Reset     time.Duration // Temps restant avant la fin de la fenêtre
+	Allowed   bool
+}
+
+// RateLimitStats compteurs de l'instance depuis son démarrage
+type RateLimitStats struct {
+	RouteGroup string `json:"route_group"`
+	Allowed    int64  `json:"allowed"`
+	Throttled  int64  `json:"throttled"`
+}
+
+type rateLimitMetricKey struct {
+	policyID   uint
+	routeGroup string
+	subject    string
+	role       string
+}
+
+// RateLimitService applique les politiques de limitation et collecte les métriques de rejet
+type RateLimitService struct {
+	db    *gorm.DB
+	store RateLimitStore
+
+	mu       sync.RWMutex
+	policies []models.RateLimitPolicy
+	loadedAt time.Time
+
+	statsMu sync.Mutex
+	stats   map[string]*RateLimitStats
+	pending map[rateLimitMetricKey]int64 // Rejets en attente d'écriture en base
+}
+
+// NewRateLimitService crée une nouvelle instance de RateLimitService
+func NewRateLimitService(db *gorm.DB, store RateLimitStore) *RateLimitService {
+	return &RateLimitService{
+		db:      db,
+		store:   store,
+		stats:   make(map[string]*RateLimitStats),
+		pending: make(map[rateLimitMetricKey]int64),
+	}
+}
+
+// SeedDefaultPolicies crée les politiques par défaut si aucune n'existe
+func (s *RateLimitService) SeedDefaultPolicies() error {
+	var count int64
+	if err := s.db.Model(&models.RateLimitPolicy{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	defaults := []models.RateLimitPolicy{
+		{Name: "Connexion et inscription", RouteGroup: models.RateLimitGroupLogin, MaxRequests: 20, PeriodSeconds: 60, IsActive: true},
+		{Name: "Routes publiques", RouteGroup: models.RateLimitGroupPublic, MaxRequests: 300, PeriodSeconds: 60, IsActive: true},
+		{Name: "API authentifiée", RouteGroup: models.RateLimitGroupAPI, MaxRequests: 600, PeriodSeconds: 60, IsActive: true},
+		{Name: "API administrateurs", RouteGroup: models.RateLimitGroupAPI, Role: models.RoleAdmin, MaxRequests: 1200, PeriodSeconds: 60, IsActive: true},
+		{Name: "Provisioning SCIM", RouteGroup: models.RateLimitGroupSCIM, MaxRequests: 1200, PeriodSeconds: 60, IsActive: true},
+	}
+	return s.db.Create(&defaults).Error
+}
+
+// InvalidatePolicies force le rechargement des politiques à la prochaine requête
+func (s *RateLimitService) InvalidatePolicies() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// activePolicies retourne les politiques actives (mises en cache quelques secondes)
+func (s *RateLimitService) activePolicies() []models.RateLimitPolicy {
+	s.mu.RLock()
+	if time.Since(s.loadedAt) < rateLimitPolicyTTL {
+		policies := s.policies
+		s.mu.RUnlock()
+		return policies
+	}
+	s.mu.RUnlock()
+
+	var policies []models.RateLimitPolicy
+	if err := s.db.Where("is_active = ?", true).Order("id").Find(&policies).Error; err != nil {
+		log.Printf("[RateLimit] Erreur chargement des politiques: %v", err)
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.policies
+	}
+
+	s.mu.Lock()
+	s.policies = policies
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return policies
+}
+
+// MatchPolicy retourne la politique la plus spécifique pour un groupe de routes et un rôle
+func (s *RateLimitService) MatchPolicy(routeGroup, role string) *models.RateLimitPolicy {
+	var best *models.RateLimitPolicy
+	bestScore := -1
+
+	policies := s.activePolicies()
+	for i := range policies {
+		policy := &policies[i]
+		score := 0
+		switch policy.RouteGroup {
+		case routeGroup:
+			score += 2
+		case models.RateLimitGroupAll:
+		default:
+			continue
+		}
+		switch policy.Role {
+		case role:
+			score++
+		case "":
+		default:
+			continue
+		}
+		if score > bestScore {
+			best, bestScore = policy, score
+		}
+	}
+	return best
+}
+
+// Check comptabilise une requête du client (subject) et indique si elle est autorisée.
+// Retourne nil si aucune politique ne s'applique.
+func (s *RateLimitService) Check(routeGroup, role, subject string) (*RateLimitDecision, error) {
+	policy := s.MatchPolicy(routeGroup, role)
+	if policy == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	period := time.Duration(policy.PeriodSeconds) * time.Second
+	windowStart := now.Truncate(period)
+	windowEnd := windowStart.Add(period)
+
+	key := fmt.Sprintf("%d:%s:%d", policy.ID, subject, windowStart.Unix())
+	count, err := s.store.Increment(key, windowEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &RateLimitDecision{
+		Policy:  policy,
+		Reset:   windowEnd.Sub(now),
+		Allowed: count <= int64(policy.MaxRequests),
+	}
+	if remaining := int64(policy.MaxRequests) - count; remaining > 0 {
+		decision.Remaining = int(remaining)
+	}
+
+	s.record(policy, routeGroup, role, subject, decision.Allowed)
+	return decision, nil
+}
+
+// record met à jour les compteurs de l'instance et mémorise les rejets à enregistrer
+func (s *RateLimitService) record(policy *models.RateLimitPolicy, routeGroup, role, subject string, allowed bool) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats, ok := s.stats[routeGroup]
+	if !ok {
+		stats = &RateLimitStats{RouteGroup: routeGroup}
+		s.stats[routeGroup] = stats
+	}
+	if allowed {
+		stats.Allowed++
+		return
+	}
+	stats.Throttled++
+	s.pending[rateLimitMetricKey{policy.ID, routeGroup, subject, role}]++
+}
+
+// InstanceStats retourne les compteurs de l'instance courante
+func (s *RateLimitService) InstanceStats() []RateLimitStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats := make([]RateLimitStats, 0, len(s.stats))
+	for _, st := range s.stats {
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].RouteGroup < stats[j].RouteGroup })
+	return stats
+}
+
+// FlushMetrics enregistre en base les rejets accumulés (agrégés par heure). Les entrées non enregistrées
+// sont remises en attente pour le prochain passage.
+func (s *RateLimitService) FlushMetrics() error {
+	s.statsMu.Lock()
+	pending := s.pending
+	s.pending = make(map[rateLimitMetricKey]int64)
+	s.statsMu.Unlock()
+
+	bucket := time.Now().Truncate(time.Hour)
+	failed := make(map[rateLimitMetricKey]int64)
+	var errs []error
+	for key, throttled := range pending {
+		err := s.db.Exec(`INSERT INTO rate_limit_metrics (bucket, policy_id, route_group, subject, role, throttled)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (bucket, policy_id, route_group, subject) DO UPDATE SET throttled = rate_limit_metrics.throttled + EXCLUDED.throttled`,
+			bucket, key.policyID, key.routeGroup, key.subject, key.role, throttled).Error
+		if err != nil {
+			failed[key] = throttled
+			errs = append(errs, err)
+		}
+	}
+
+	if len(failed) > 0 {
+		s.statsMu.Lock()
+		for key, throttled := range failed {
+			s.pending[key] += throttled
+		}
+		s.statsMu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// StartScheduler enregistre les métriques et purge les compteurs expirés chaque minute
+func (s *RateLimitService) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.FlushMetrics(); err != nil {
+				log.Printf("[RateLimit] Erreur enregistrement des métriques: %v", err)
+			}
+			if err := s.store.PurgeExpired(); err != nil {
+				log.Printf("[RateLimit] Erreur purge des compteurs: %v", err)
+			}
+			s.db.Where("bucket < ?", time.Now().Add(-rateLimitMetricRetention)).Delete(&models.RateLimitMetric{})
+		}
+	}()
+}
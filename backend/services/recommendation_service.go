@@ -0,0 +1,449 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+// Taille du fil personnalisé
+const (
+	RecommendationDefaultLimit = 10
+	RecommendationMaxLimit     = 50
+)
+
+// Pondération du score : chaque composante est ramenée entre 0 et 1 avant d'être pondérée
+const (
+	recoWeightRecency  = 0.25
+	recoWeightPriority = 0.15
+	recoWeightPinned   = 0.10
+	recoWeightCategory = 0.15
+	recoWeightTag      = 0.10
+	recoWeightPopular  = 0.15
+	recoWeightPeers    = 0.10
+)
+
+const (
+	recoHalfLife        = 72 * time.Hour       // Demi-vie de la fraîcheur d'un article
+	recoCandidateWindow = 90 * 24 * time.Hour  // Articles candidats (hors épinglés), complétés au-delà si trop peu nombreux
+	recoAffinityWindow  = 180 * 24 * time.Hour // Historique pris en compte pour les affinités
+	recoActivityWindow  = 30 * 24 * time.Hour  // Activité récente pour la popularité et les lecteurs similaires
+	recoMaxCandidates   = 300
+	recoMaxPeers        = 50
+	recoMinPeerApps     = 2    // Applications communes pour qu'un utilisateur soit jugé similaire
+	recoMinReaders      = 2    // Lecteurs distincts en deçà desquels la popularité n'est pas révélée
+	recoReadPenalty     = 0.35 // Facteur appliqué au score des articles déjà lus
+	recoMinReasonWeight = 0.03 // Contribution en deçà de laquelle un motif n'est pas affiché
+	recoMaxReasons      = 3
+)
+
+// Poids des interactions de l'utilisateur dans le calcul de ses affinités
+const (
+	recoAffinityRead     = 1.0
+	recoAffinityReaction = 2.0
+	recoAffinityPositive = 3.0
+	recoAffinityNegative = -3.0
+)
+
+// RecommendationScope utilisateur pour lequel le fil est calculé et articles qui lui sont visibles
+type RecommendationScope struct {
+	UserID   uint
+	AllNews  bool   // Voit toutes les news publiées, quels que soient les groupes ciblés
+	GroupIDs []uint // Groupes d'appartenance et groupes administrés
+}
+
+// RecommendationParams options du fil personnalisé
+type RecommendationParams struct {
+	Limit       int
+	IncludeRead bool // Conserve les articles déjà lus (pénalisés) plutôt que de les écarter
+	CategoryID  uint
+}
+
+// RecommendationService classe les news publiées selon les centres d'intérêt de chaque utilisateur
+type RecommendationService struct {
+	db *gorm.DB
+}
+
+// NewRecommendationService crée une nouvelle instance de RecommendationService
+func NewRecommendationService(db *gorm.DB) *RecommendationService {
+	return &RecommendationService{db: db}
+}
+
+// recoSignals signaux collectés pour les articles candidats
+type recoSignals struct {
+	categories map[uint]float64 // Affinité par catégorie (0 à 1)
+	tags       map[uint]float64 // Affinité par tag (0 à 1)
+	read       map[uint]bool
+	group      map[uint]recoGroupPopularity
+	popular    map[uint]float64 // Popularité globale (0 à 1)
+	peers      map[uint]float64 // Part des utilisateurs similaires ayant lu l'article (0 à 1)
+}
+
+// recoGroupPopularity groupe de l'utilisateur où l'article est le plus lu
+type recoGroupPopularity struct {
+	Name  string
+	Share float64
+}
+
+// Recommend retourne les news publiées visibles par l'utilisateur, de la plus à la moins pertinente :
+// fraîcheur, priorité, affinité avec ses catégories et tags, popularité dans ses groupes et auprès
+// des utilisateurs des mêmes applications. Les articles jugés négativement sont écartés.
+func (s *RecommendationService) Recommend(scope RecommendationScope, params RecommendationParams) ([]models.NewsRecommendation, error) {
+	if params.Limit <= 0 {
+		params.Limit = RecommendationDefaultLimit
+	}
+	if params.Limit > RecommendationMaxLimit {
+		params.Limit = RecommendationMaxLimit
+	}
+
+	now := time.Now()
+	candidates, err := s.candidates(scope, params, now)
+	if err != nil || len(candidates) == 0 {
+		return []models.NewsRecommendation{}, err
+	}
+
+	ids := make([]uint, len(candidates))
+	for i := range candidates {
+		ids[i] = candidates[i].ID
+	}
+	signals, err := s.signals(scope, ids, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]models.NewsRecommendation, 0, len(candidates))
+	for _, news := range candidates {
+		ranked = append(ranked, scoreNews(news, signals, now))
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	if len(ranked) > params.Limit {
+		ranked = ranked[:params.Limit]
+	}
+	return ranked, nil
+}
+
+// candidates news publiées, non expirées et visibles : récentes ou épinglées. Sur un portail peu actif,
+// la sélection est complétée par les articles plus anciens les plus récents pour ne pas laisser le fil vide.
+func (s *RecommendationService) candidates(scope RecommendationScope, params RecommendationParams, now time.Time) ([]models.News, error) {
+	since := now.Add(-recoCandidateWindow)
+
+	var news []models.News
+	err := s.visibleNews(scope, params, now).
+		Where("news.is_pinned = ? OR COALESCE(news.published_at, news.created_at) >= ?", true, since).
+		Order("COALESCE(news.published_at, news.created_at) DESC, news.id DESC").
+		Limit(recoMaxCandidates).
+		Find(&news).Error
+	if err != nil || len(news) >= params.Limit {
+		return news, err
+	}
+
+	var older []models.News
+	err = s.visibleNews(scope, params, now).
+		Where("news.is_pinned = ? AND COALESCE(news.published_at, news.created_at) < ?", false, since).
+		Order("COALESCE(news.published_at, news.created_at) DESC, news.id DESC").
+		Limit(recoMaxCandidates - len(news)).
+		Find(&older).Error
+	return append(news, older...), err
+}
+
+// visibleNews requête des news publiées, non expirées et visibles par l'utilisateur, hors articles écartés
+func (s *RecommendationService) visibleNews(scope RecommendationScope, params RecommendationParams, now time.Time) *gorm.DB {
+	where := "news.status = ? AND news.is_published = ? AND (news.published_at IS NULL OR news.published_at <= ?)" +
+		" AND (news.expires_at IS NULL OR news.expires_at > ?)"
+	args := []interface{}{models.NewsStatusPublished, true, now, now}
+	if !scope.AllNews {
+		where, args = targetGroupsSQL(where, args, "news_target_groups", "news_id", "news.id", scope.GroupIDs)
+	}
+
+	// Les articles jugés négativement, et les articles déjà lus sauf IncludeRead, sont écartés dès la
+	// requête : le complément par les articles plus anciens tient ainsi compte des seuls articles retenus
+	where += " AND NOT EXISTS (SELECT 1 FROM feedbacks f WHERE f.entity_type = 'news' AND f.entity_id = news.id" +
+		" AND f.user_id = ? AND f.feedback_type = 'negative')"
+	args = append(args, scope.UserID)
+	if !params.IncludeRead {
+		where += " AND NOT EXISTS (SELECT 1 FROM news_reads nr WHERE nr.news_id = news.id AND nr.user_id = ?)"
+		args = append(args, scope.UserID)
+	}
+
+	query := s.db.Model(&models.News{}).
+		Preload("Author").
+		Preload("Category").
+		Preload("Tags").
+		Preload("TargetGroups").
+		Where(where, args...)
+	if params.CategoryID != 0 {
+		query = query.Where("news.category_id = ?", params.CategoryID)
+	}
+	return query
+}
+
+// signals collecte les affinités de l'utilisateur et la popularité des articles candidats
+func (s *RecommendationService) signals(scope RecommendationScope, ids []uint, now time.Time) (*recoSignals, error) {
+	signals := &recoSignals{
+		read: make(map[uint]bool),
+	}
+
+	var err error
+	if signals.categories, signals.tags, err = s.affinities(scope.UserID, now); err != nil {
+		return nil, err
+	}
+
+	var readIDs []uint
+	if err := s.db.Table("news_reads").Where("user_id = ? AND news_id IN ?", scope.UserID, ids).
+		Pluck("news_id", &readIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range readIDs {
+		signals.read[id] = true
+	}
+
+	since := now.Add(-recoActivityWindow)
+	if signals.group, err = s.groupPopularity(scope, ids, since); err != nil {
+		return nil, err
+	}
+	if signals.popular, err = s.popularity(scope.UserID, ids, since); err != nil {
+		return nil, err
+	}
+	if signals.peers, err = s.peerReadership(scope.UserID, ids, since); err != nil {
+		return nil, err
+	}
+	return signals, nil
+}
+
+// recoInteractionsSQL interactions pondérées de l'utilisateur avec les news (lectures, réactions, avis)
+const recoInteractionsSQL = `SELECT news_id, ?::float AS weight FROM news_reads WHERE user_id = ? AND read_at >= ?
+	UNION ALL SELECT news_id, ?::float FROM news_reactions WHERE user_id = ? AND created_at >= ?
+	UNION ALL SELECT entity_id, CASE WHEN feedback_type = 'positive' THEN ?::float ELSE ?::float END
+		FROM feedbacks WHERE user_id = ? AND entity_type = 'news' AND created_at >= ?`
+
+// affinities affinité de l'utilisateur pour chaque catégorie et chaque tag, relative à sa préférée
+func (s *RecommendationService) affinities(userID uint, now time.Time) (map[uint]float64, map[uint]float64, error) {
+	since := now.Add(-recoAffinityWindow)
+	args := []interface{}{
+		recoAffinityRead, userID, since,
+		recoAffinityReaction, userID, since,
+		recoAffinityPositive, recoAffinityNegative, userID, since,
+	}
+
+	type affinityRow struct {
+		ID     uint
+		Weight float64
+	}
+	var categoryRows, tagRows []affinityRow
+	if err := s.db.Raw(`SELECT n.category_id AS id, SUM(i.weight) AS weight FROM (`+recoInteractionsSQL+`) i
+		JOIN news n ON n.id = i.news_id WHERE n.category_id IS NOT NULL GROUP BY n.category_id`, args...).
+		Scan(&categoryRows).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := s.db.Raw(`SELECT nt.tag_id AS id, SUM(i.weight) AS weight FROM (`+recoInteractionsSQL+`) i
+		JOIN news_tags nt ON nt.news_id = i.news_id GROUP BY nt.tag_id`, args...).
+		Scan(&tagRows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	normalize := func(rows []affinityRow) map[uint]float64 {
+		affinity := make(map[uint]float64)
+		var highest float64
+		for _, row := range rows {
+			highest = math.Max(highest, row.Weight)
+		}
+		if highest <= 0 {
+			return affinity
+		}
+		for _, row := range rows {
+			if row.Weight > 0 {
+				affinity[row.ID] = row.Weight / highest
+			}
+		}
+		return affinity
+	}
+	return normalize(categoryRows), normalize(tagRows), nil
+}
+
+// recoEngagementSQL utilisateurs ayant lu ou réagi à l'un des articles candidats depuis une date
+const recoEngagementSQL = `SELECT news_id, user_id FROM news_reads WHERE news_id IN ? AND read_at >= ?
+	UNION SELECT news_id, user_id FROM news_reactions WHERE news_id IN ? AND created_at >= ?`
+
+// groupPopularity pour chaque article, le groupe de l'utilisateur dont la plus grande part des membres l'a lu
+func (s *RecommendationService) groupPopularity(scope RecommendationScope, ids []uint, since time.Time) (map[uint]recoGroupPopularity, error) {
+	popularity := make(map[uint]recoGroupPopularity)
+	if len(scope.GroupIDs) == 0 {
+		return popularity, nil
+	}
+
+	var rows []struct {
+		NewsID  uint
+		Name    string
+		Readers int
+		Members int
+	}
+	err := s.db.Raw(`SELECT e.news_id, g.name, COUNT(DISTINCT e.user_id) AS readers, gs.members
+		FROM (`+recoEngagementSQL+`) e
+		JOIN user_groups ug ON ug.user_id = e.user_id
+		JOIN groups g ON g.id = ug.group_id AND g.deleted_at IS NULL AND g.is_active = true
+		JOIN (SELECT group_id, COUNT(*) AS members FROM user_groups WHERE group_id IN ? GROUP BY group_id) gs ON gs.group_id = ug.group_id
+		WHERE ug.group_id IN ? AND e.user_id <> ?
+		GROUP BY e.news_id, g.id, g.name, gs.members
+		HAVING COUNT(DISTINCT e.user_id) >= ?`,
+		ids, since, ids, since, scope.GroupIDs, scope.GroupIDs, scope.UserID, recoMinReaders).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		// Les membres autres que l'utilisateur lui-même
+		others := row.Members - 1
+		if others < 1 {
+			continue
+		}
+		share := math.Min(float64(row.Readers)/float64(others), 1)
+		if share > popularity[row.NewsID].Share {
+			popularity[row.NewsID] = recoGroupPopularity{Name: row.Name, Share: share}
+		}
+	}
+	return popularity, nil
+}
+
+// popularity lecteurs distincts de chaque article, relatifs à l'article le plus lu
+func (s *RecommendationService) popularity(userID uint, ids []uint, since time.Time) (map[uint]float64, error) {
+	var rows []struct {
+		NewsID  uint
+		Readers int
+	}
+	err := s.db.Raw(`SELECT e.news_id, COUNT(DISTINCT e.user_id) AS readers FROM (`+recoEngagementSQL+`) e
+		WHERE e.user_id <> ? GROUP BY e.news_id HAVING COUNT(DISTINCT e.user_id) >= ?`,
+		ids, since, ids, since, userID, recoMinReaders).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	popularity := make(map[uint]float64)
+	highest := 0
+	for _, row := range rows {
+		if row.Readers > highest {
+			highest = row.Readers
+		}
+	}
+	for _, row := range rows {
+		popularity[row.NewsID] = float64(row.Readers) / float64(highest)
+	}
+	return popularity, nil
+}
+
+// peerReadership part des utilisateurs similaires (qui ouvrent les mêmes applications) ayant lu chaque article
+func (s *RecommendationService) peerReadership(userID uint, ids []uint, since time.Time) (map[uint]float64, error) {
+	var peerIDs []uint
+	err := s.db.Raw(`SELECT ac.user_id FROM application_clicks ac
+		WHERE ac.clicked_at >= ? AND ac.user_id <> ?
+		AND ac.application_id IN (SELECT DISTINCT application_id FROM application_clicks WHERE user_id = ? AND clicked_at >= ?)
+		GROUP BY ac.user_id HAVING COUNT(DISTINCT ac.application_id) >= ?
+		ORDER BY COUNT(DISTINCT ac.application_id) DESC, ac.user_id
+		LIMIT ?`, since, userID, userID, since, recoMinPeerApps, recoMaxPeers).
+		Scan(&peerIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	readership := make(map[uint]float64)
+	if len(peerIDs) < recoMinReaders {
+		return readership, nil
+	}
+
+	var rows []struct {
+		NewsID  uint
+		Readers int
+	}
+	err = s.db.Raw(`SELECT e.news_id, COUNT(DISTINCT e.user_id) AS readers FROM (`+recoEngagementSQL+`) e
+		WHERE e.user_id IN ? GROUP BY e.news_id HAVING COUNT(DISTINCT e.user_id) >= ?`,
+		ids, since, ids, since, peerIDs, recoMinReaders).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		readership[row.NewsID] = float64(row.Readers) / float64(len(peerIDs))
+	}
+	return readership, nil
+}
+
+// scoreNews calcule le score d'un article et les motifs qui y contribuent le plus
+func scoreNews(news models.News, signals *recoSignals, now time.Time) models.NewsRecommendation {
+	var reasons []models.NewsRecommendationReason
+	score := 0.0
+	add := func(code, label string, weight float64) {
+		score += weight
+		if weight >= recoMinReasonWeight {
+			reasons = append(reasons, models.NewsRecommendationReason{Code: code, Label: label, Weight: math.Round(weight*1000) / 1000})
+		}
+	}
+
+	published := news.CreatedAt
+	if news.PublishedAt != nil {
+		published = *news.PublishedAt
+	}
+	age := math.Max(now.Sub(published).Hours(), 0)
+	add(models.RecommendationReasonRecent, "", recoWeightRecency*math.Pow(0.5, age/recoHalfLife.Hours()))
+
+	switch news.Priority {
+	case "urgent":
+		add(models.RecommendationReasonUrgent, "", recoWeightPriority)
+	case "important":
+		add(models.RecommendationReasonImportant, "", recoWeightPriority*0.5)
+	}
+	if news.IsPinned {
+		add(models.RecommendationReasonPinned, "", recoWeightPinned)
+	}
+
+	if news.CategoryID != nil && news.Category != nil {
+		add(models.RecommendationReasonCategory, news.Category.Name, recoWeightCategory*signals.categories[*news.CategoryID])
+	}
+	// Le tag préféré de l'utilisateur parmi ceux de l'article
+	var bestTag *models.Tag
+	for i := range news.Tags {
+		if bestTag == nil || signals.tags[news.Tags[i].ID] > signals.tags[bestTag.ID] {
+			bestTag = &news.Tags[i]
+		}
+	}
+	if bestTag != nil {
+		add(models.RecommendationReasonTag, bestTag.Name, recoWeightTag*signals.tags[bestTag.ID])
+	}
+
+	// Popularité dans ses groupes, à défaut auprès de l'ensemble des utilisateurs (de moindre poids)
+	if group, global := signals.group[news.ID], signals.popular[news.ID]*0.5; group.Share >= global {
+		add(models.RecommendationReasonGroupPopular, group.Name, recoWeightPopular*group.Share)
+	} else {
+		add(models.RecommendationReasonPopular, "", recoWeightPopular*global)
+	}
+	add(models.RecommendationReasonSimilarReaders, "", recoWeightPeers*signals.peers[news.ID])
+
+	read := signals.read[news.ID]
+	if read {
+		score *= recoReadPenalty
+	}
+
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return reasons[i].Weight > reasons[j].Weight
+	})
+	if len(reasons) > recoMaxReasons {
+		reasons = reasons[:recoMaxReasons]
+	}
+	if reasons == nil {
+		reasons = []models.NewsRecommendationReason{}
+	}
+
+	return models.NewsRecommendation{
+		News:    news,
+		Score:   math.Round(score*10000) / 10000,
+		IsRead:  read,
+		Reasons: reasons,
+	}
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"airboard/models"
+	"airboard/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrRevisionNotFound la révision n'existe pas pour ce contenu
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Champ contenant le document Tiptap de chaque type de contenu (comparé séparément des autres champs)
+var revisionContentFields = map[string]string{
+	models.RevisionEntityNews:  "content",
+	models.RevisionEntityEvent: "description",
+}
+
+// RevisionDiffStats nombre de blocs ajoutés, supprimés et modifiés dans le document
+type RevisionDiffStats struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// RevisionDiff différence entre deux révisions d'un contenu
+type RevisionDiff struct {
+	From    models.ContentRevision       `json:"from"`
+	To      models.ContentRevision       `json:"to"`
+	Fields  []models.RevisionFieldChange `json:"fields"`
+	Content []utils.TiptapDiffOp         `json:"content"`
+	Stats   RevisionDiffStats            `json:"stats"`
+}
+
+// RevisionService gère l'historique des versions des articles et des événements
+type RevisionService struct {
+	db *gorm.DB
+}
+
+// NewRevisionService crée une nouvelle instance de RevisionService
+func NewRevisionService(db *gorm.DB) *RevisionService {
+	return &RevisionService{db: db}
+}
+
+// RecordNews enregistre une révision de l'article dans la transaction tx, celle qui enregistre
+// l'article (tags et groupes cibles doivent être préchargés)
+func (s *RevisionService) RecordNews(tx *gorm.DB, news *models.News, authorID uint, restoredFromID *uint) (*models.ContentRevision, error) {
+	snapshot := models.NewsSnapshot{
+		Title:        news.Title,
+		Summary:      news.Summary,
+		Content:      news.Content,
+		CoverImage:   news.CoverImage,
+		Type:         news.Type,
+		Priority:     news.Priority,
+		IsPinned:     news.IsPinned,
+		IsPublished:  news.IsPublished,
+		PublishedAt:  news.PublishedAt,
+		ExpiresAt:    news.ExpiresAt,
+		CategoryID:   news.CategoryID,
+		Tags:         tagRefs(news.Tags),
+		TargetGroups: groupRefs(news.TargetGroups),
+	}
+	return s.record(tx, models.RevisionEntityNews, news.ID, news.Title, snapshot, authorID, restoredFromID)
+}
+
+// RecordEvent enregistre une révision de l'événement dans la transaction tx, celle qui enregistre
+// l'événement (tags et groupes cibles doivent être préchargés)
+func (s *RevisionService) RecordEvent(tx *gorm.DB, event *models.Event, authorID uint, restoredFromID *uint) (*models.ContentRevision, error) {
+	snapshot := models.EventSnapshot{
+		Title:                event.Title,
+		Description:          event.Description,
+		StartDate:            event.StartDate,
+		EndDate:              event.EndDate,
+		IsAllDay:             event.IsAllDay,
+		Timezone:             event.Timezone,
+		IsRecurring:          event.IsRecurring,
+		RecurrenceRule:       event.RecurrenceRule,
+		RecurrenceEnd:        event.RecurrenceEnd,
+		RecurrenceExceptions: event.RecurrenceExceptions,
+		Location:             event.Location,
+		ExternalLinks:        event.ExternalLinks,
+		Color:                event.Color,
+		Priority:             event.Priority,
+		Status:               event.Status,
+		CoverImage:           event.CoverImage,
+		IsPublished:          event.IsPublished,
+		PublishedAt:          event.PublishedAt,
+		CategoryID:           event.CategoryID,
+		Tags:                 tagRefs(event.Tags),
+		TargetGroups:         groupRefs(event.TargetGroups),
+	}
+	return s.record(tx, models.RevisionEntityEvent, event.ID, event.Title, snapshot, authorID, restoredFromID)
+}
+
+func (s *RevisionService) record(tx *gorm.DB, entityType string, entityID uint, title string, snapshot interface{}, authorID uint, restoredFromID *uint) (*models.ContentRevision, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &models.ContentRevision{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		AuthorID:       authorID,
+		Title:          title,
+		Snapshot:       string(data),
+		RestoredFromID: restoredFromID,
+	}
+
+	// Deux enregistrements simultanés peuvent calculer le même numéro : l'index unique rejette le second, qui
+	// réessaie (chaque tentative dans un point de sauvegarde pour ne pas invalider la transaction englobante)
+	for attempt := 0; attempt < 3; attempt++ {
+		var last int
+		if err = tx.Model(&models.ContentRevision{}).
+			Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+			Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+			return nil, err
+		}
+		revision.ID = 0
+		revision.Number = last + 1
+		if err = tx.Transaction(func(sp *gorm.DB) error {
+			return sp.Create(revision).Error
+		}); err == nil {
+			return revision, nil
+		}
+	}
+	return nil, err
+}
+
+// List retourne les révisions d'un contenu, de la plus récente à la plus ancienne (sans les instantanés)
+func (s *RevisionService) List(entityType string, entityID uint) ([]models.ContentRevision, error) {
+	var revisions []models.ContentRevision
+	err := s.db.Preload("Author").
+		Omit("snapshot").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("number DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// Get retourne une révision complète d'un contenu
+func (s *RevisionService) Get(entityType string, entityID, revisionID uint) (*models.ContentRevision, error) {
+	var revision models.ContentRevision
+	err := s.db.Preload("Author").
+		Where("id = ? AND entity_type = ? AND entity_id = ?", revisionID, entityType, entityID).
+		First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Latest retourne la révision la plus récente d'un contenu
+func (s *RevisionService) Latest(entityType string, entityID uint) (*models.ContentRevision, error) {
+	var revision models.ContentRevision
+	err := s.db.Preload("Author").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("number DESC").
+		First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Previous retourne la révision précédant celle indiquée (ErrRevisionNotFound pour la première)
+func (s *RevisionService) Previous(revision *models.ContentRevision) (*models.ContentRevision, error) {
+	var previous models.ContentRevision
+	err := s.db.Preload("Author").
+		Where("entity_type = ? AND entity_id = ? AND number < ?", revision.EntityType, revision.EntityID, revision.Number).
+		Order("number DESC").
+		First(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// Diff compare deux révisions : champs modifiés et différence structurelle du document Tiptap
+func (s *RevisionService) Diff(from, to *models.ContentRevision) (*RevisionDiff, error) {
+	if from.EntityType != to.EntityType || from.EntityID != to.EntityID {
+		return nil, fmt.Errorf("les révisions %d et %d n'appartiennent pas au même contenu", from.ID, to.ID)
+	}
+
+	var oldFields, newFields map[string]interface{}
+	if err := json.Unmarshal([]byte(from.Snapshot), &oldFields); err != nil {
+		return nil, fmt.Errorf("instantané %d invalide: %w", from.ID, err)
+	}
+	if err := json.Unmarshal([]byte(to.Snapshot), &newFields); err != nil {
+		return nil, fmt.Errorf("instantané %d invalide: %w", to.ID, err)
+	}
+
+	contentField := revisionContentFields[to.EntityType]
+	oldContent, _ := oldFields[contentField].(string)
+	newContent, _ := newFields[contentField].(string)
+
+	keys := make([]string, 0, len(newFields))
+	for key := range newFields {
+		keys = append(keys, key)
+	}
+	for key := range oldFields {
+		if _, ok := newFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	diff := &RevisionDiff{From: *from, To: *to, Fields: []models.RevisionFieldChange{}}
+	for _, key := range keys {
+		if key == contentField || reflect.DeepEqual(oldFields[key], newFields[key]) {
+			continue
+		}
+		diff.Fields = append(diff.Fields, models.RevisionFieldChange{Field: key, Old: oldFields[key], New: newFields[key]})
+	}
+
+	diff.Content = utils.DiffTiptap(oldContent, newContent)
+	diff.Stats.Added, diff.Stats.Removed, diff.Stats.Changed = utils.CountDiffOps(diff.Content)
+
+	// Les instantanés complets sont inutiles dans la réponse
+	diff.From.Snapshot = ""
+	diff.To.Snapshot = ""
+	return diff, nil
+}
+
+// NewsSnapshot décode l'instantané d'une révision d'article
+func (s *RevisionService) NewsSnapshot(revision *models.ContentRevision) (*models.NewsSnapshot, error) {
+	if revision.EntityType != models.RevisionEntityNews {
+		return nil, ErrRevisionNotFound
+	}
+	var snapshot models.NewsSnapshot
+	if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// EventSnapshot décode l'instantané d'une révision d'événement
+func (s *RevisionService) EventSnapshot(revision *models.ContentRevision) (*models.EventSnapshot, error) {
+	if revision.EntityType != models.RevisionEntityEvent {
+		return nil, ErrRevisionNotFound
+	}
+	var snapshot models.EventSnapshot
+	if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// RevisionRefIDs extrait les identifiants de références d'un instantané
+func RevisionRefIDs(refs []models.RevisionRef) []uint {
+	ids := make([]uint, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	return ids
+}
+
+func tagRefs(tags []models.Tag) []models.RevisionRef {
+	refs := make([]models.RevisionRef, 0, len(tags))
+	for _, tag := range tags {
+		refs = append(refs, models.RevisionRef{ID: tag.ID, Name: tag.Name})
+	}
+	return refs
+}
+
+func groupRefs(groups []models.Group) []models.RevisionRef {
+	refs := make([]models.RevisionRef, 0, len(groups))
+	for _, group := range groups {
+		refs = append(refs, models.RevisionRef{ID: group.ID, Name: group.Name})
+	}
+	return refs
+}
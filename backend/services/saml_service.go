@@ -0,0 +1,766 @@
+package services
+
+import (
+	"airboard/config"
+	"airboard/models"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	dsig "github.com/russellhaering/goxmldsig"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SAMLUserAttributes contient les informations extraites d'une assertion SAML validée
+type SAMLUserAttributes struct {
+	NameID       string
+	SessionIndex string
+	Email        string
+	Username     string
+	FirstName    string
+	LastName     string
+	Groups       []string
+	Manager      *string // nil si aucun attribut responsable n'est configuré
+}
+
+// Durées de validité des requêtes en attente et des codes de connexion (stockés en base pour
+// fonctionner derrière un répartiteur de charge et survivre à un redémarrage)
+const (
+	samlPendingRequestTTL = 10 * time.Minute
+	samlLoginCodeTTL      = time.Minute
+)
+
+// SAMLService gère le rôle de service provider SAML 2.0 : métadonnées, AuthnRequest signées,
+// validation des assertions et Single Logout
+type SAMLService struct {
+	db         *gorm.DB
+	config     *config.Config
+	httpClient *http.Client
+
+	keyMu sync.Mutex
+	key   *rsa.PrivateKey
+	cert  *x509.Certificate
+}
+
+// NewSAMLService crée une nouvelle instance du service SAML
+func NewSAMLService(db *gorm.DB, cfg *config.Config) *SAMLService {
+	return &SAMLService{
+		db:         db,
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// baseURL retourne l'URL publique des endpoints SAML d'un fournisseur
+func (s *SAMLService) baseURL(provider *models.SAMLProvider) string {
+	return strings.TrimSuffix(s.config.Server.PublicURL, "/") + "/api/v1/auth/saml/" + url.PathEscape(provider.Name)
+}
+
+// ServiceProvider construit le service provider crewjam/saml correspondant à un fournisseur
+func (s *SAMLService) ServiceProvider(provider *models.SAMLProvider) (*saml.ServiceProvider, error) {
+	key, cert, err := s.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	base := s.baseURL(provider)
+	metadataURL, _ := url.Parse(base + "/metadata")
+	acsURL, _ := url.Parse(base + "/acs")
+	sloURL, _ := url.Parse(base + "/slo")
+
+	sp := &saml.ServiceProvider{
+		EntityID:              metadataURL.String(),
+		Key:                   key,
+		Certificate:           cert,
+		MetadataURL:           *metadataURL,
+		AcsURL:                *acsURL,
+		SloURL:                *sloURL,
+		AuthnNameIDFormat:     nameIDFormat(provider.NameIDFormat),
+		AllowIDPInitiated:     provider.AllowIDPInitiated,
+		MetadataValidDuration: 7 * 24 * time.Hour,
+	}
+	if provider.SignRequests {
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	}
+
+	if provider.IDPMetadataXML != "" {
+		idp, err := ParseIDPMetadata([]byte(provider.IDPMetadataXML))
+		if err != nil {
+			return nil, err
+		}
+		sp.IDPMetadata = idp
+	}
+
+	return sp, nil
+}
+
+// nameIDFormat convertit le format court stocké en base vers l'URN SAML
+func nameIDFormat(format string) saml.NameIDFormat {
+	switch format {
+	case "persistent":
+		return saml.PersistentNameIDFormat
+	case "transient":
+		return saml.TransientNameIDFormat
+	case "unspecified":
+		return saml.UnspecifiedNameIDFormat
+	default:
+		return saml.EmailAddressNameIDFormat
+	}
+}
+
+// FetchIDPMetadata télécharge les métadonnées d'un IdP et retourne le XML brut
+func (s *SAMLService) FetchIDPMetadata(metadataURL string) ([]byte, error) {
+	resp, err := s.httpClient.Get(metadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("impossible de récupérer les métadonnées: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("les métadonnées ont répondu %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseIDPMetadata(body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// ParseIDPMetadata analyse un document de métadonnées (EntityDescriptor ou EntitiesDescriptor)
+// et retourne la première entité exposant un IDPSSODescriptor
+func ParseIDPMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	var entity saml.EntityDescriptor
+	if err := xml.Unmarshal(data, &entity); err == nil && len(entity.IDPSSODescriptors) > 0 {
+		return &entity, nil
+	}
+
+	var entities saml.EntitiesDescriptor
+	if err := xml.Unmarshal(data, &entities); err != nil {
+		return nil, fmt.Errorf("métadonnées SAML invalides: %w", err)
+	}
+	for i := range entities.EntityDescriptors {
+		if len(entities.EntityDescriptors[i].IDPSSODescriptors) > 0 {
+			return &entities.EntityDescriptors[i], nil
+		}
+	}
+	return nil, errors.New("aucun IDPSSODescriptor trouvé dans les métadonnées")
+}
+
+// ImportMetadata met à jour les métadonnées stockées d'un fournisseur, depuis son URL si configurée
+func (s *SAMLService) ImportMetadata(provider *models.SAMLProvider) error {
+	data := []byte(provider.IDPMetadataXML)
+	if provider.IDPMetadataURL != "" {
+		fetched, err := s.FetchIDPMetadata(provider.IDPMetadataURL)
+		if err != nil {
+			return err
+		}
+		data = fetched
+	}
+	if len(data) == 0 {
+		return errors.New("URL ou XML de métadonnées requis")
+	}
+
+	entity, err := ParseIDPMetadata(data)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	provider.IDPMetadataXML = string(data)
+	provider.IDPEntityID = entity.EntityID
+	provider.MetadataImportedAt = &now
+	return nil
+}
+
+// MakeAuthenticationRequest génère l'URL de redirection vers l'IdP (binding HTTP-Redirect)
+// et mémorise l'ID de la requête pour la validation InResponseTo
+func (s *SAMLService) MakeAuthenticationRequest(provider *models.SAMLProvider) (string, error) {
+	sp, err := s.ServiceProvider(provider)
+	if err != nil {
+		return "", err
+	}
+	if sp.IDPMetadata == nil {
+		return "", errors.New("métadonnées de l'IdP non importées")
+	}
+
+	req, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return "", err
+	}
+
+	relayState, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	redirectURL, err := req.Redirect(relayState, sp)
+	if err != nil {
+		return "", err
+	}
+
+	s.cleanupExpired()
+	if err := s.db.Create(&models.SAMLPendingRequest{
+		RelayStateHash: HashAPIToken(relayState),
+		RequestID:      req.ID,
+		Provider:       provider.Name,
+		ExpiresAt:      time.Now().Add(samlPendingRequestTTL),
+	}).Error; err != nil {
+		return "", err
+	}
+
+	return redirectURL.String(), nil
+}
+
+// ParseResponse valide la réponse reçue sur l'ACS (signature, audience, conditions temporelles,
+// InResponseTo) et extrait les attributs de l'utilisateur
+func (s *SAMLService) ParseResponse(provider *models.SAMLProvider, r *http.Request) (*SAMLUserAttributes, error) {
+	sp, err := s.ServiceProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if sp.IDPMetadata == nil {
+		return nil, errors.New("métadonnées de l'IdP non importées")
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	// Retrouver la requête initiale via le RelayState (usage unique)
+	var possibleRequestIDs []string
+	if relayState := r.PostForm.Get("RelayState"); relayState != "" {
+		var pending models.SAMLPendingRequest
+		if err := s.db.Where("relay_state_hash = ?", HashAPIToken(relayState)).First(&pending).Error; err == nil {
+			// Suppression conditionnelle : une seule instance peut consommer la requête
+			consumed := s.db.Where("id = ?", pending.ID).Delete(&models.SAMLPendingRequest{})
+			if consumed.Error == nil && consumed.RowsAffected == 1 &&
+				pending.Provider == provider.Name && time.Now().Before(pending.ExpiresAt) {
+				possibleRequestIDs = append(possibleRequestIDs, pending.RequestID)
+			}
+		}
+	}
+
+	if len(possibleRequestIDs) == 0 && !provider.AllowIDPInitiated {
+		return nil, errors.New("réponse SAML non sollicitée ou expirée")
+	}
+
+	assertion, err := sp.ParseResponse(r, possibleRequestIDs)
+	if err != nil {
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) {
+			return nil, fmt.Errorf("assertion SAML invalide: %w", invalid.PrivateErr)
+		}
+		return nil, err
+	}
+
+	return extractSAMLAttributes(provider, assertion)
+}
+
+// extractSAMLAttributes applique le mapping d'attributs configuré pour le fournisseur
+func extractSAMLAttributes(provider *models.SAMLProvider, assertion *saml.Assertion) (*SAMLUserAttributes, error) {
+	values := make(map[string][]string)
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			for _, v := range attr.Values {
+				if v.Value == "" {
+					continue
+				}
+				// Indexer par Name et FriendlyName pour simplifier la configuration
+				values[strings.ToLower(attr.Name)] = append(values[strings.ToLower(attr.Name)], v.Value)
+				if attr.FriendlyName != "" {
+					values[strings.ToLower(attr.FriendlyName)] = append(values[strings.ToLower(attr.FriendlyName)], v.Value)
+				}
+			}
+		}
+	}
+	first := func(name string) string {
+		if name == "" {
+			return ""
+		}
+		if v := values[strings.ToLower(name)]; len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+
+	attrs := &SAMLUserAttributes{
+		Email:     first(provider.EmailAttribute),
+		Username:  first(provider.UsernameAttribute),
+		FirstName: first(provider.FirstNameAttribute),
+		LastName:  first(provider.LastNameAttribute),
+	}
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		attrs.NameID = assertion.Subject.NameID.Value
+	}
+	if len(assertion.AuthnStatements) > 0 {
+		attrs.SessionIndex = assertion.AuthnStatements[0].SessionIndex
+	}
+	if provider.GroupsAttribute != "" {
+		attrs.Groups = values[strings.ToLower(provider.GroupsAttribute)]
+	}
+	if provider.ManagerAttribute != "" {
+		manager := first(provider.ManagerAttribute)
+		attrs.Manager = &manager
+	}
+
+	if attrs.Email == "" && strings.Contains(attrs.NameID, "@") {
+		attrs.Email = attrs.NameID
+	}
+	if attrs.Email == "" {
+		return nil, errors.New("aucun email dans l'assertion SAML")
+	}
+	attrs.Email = strings.ToLower(attrs.Email)
+	if attrs.Username == "" {
+		attrs.Username = strings.Split(attrs.Email, "@")[0]
+	}
+
+	return attrs, nil
+}
+
+// MakeLogoutRequest génère l'URL de Single Logout vers l'IdP (vide si l'IdP ne supporte pas le SLO)
+func (s *SAMLService) MakeLogoutRequest(provider *models.SAMLProvider, nameID string) (string, error) {
+	sp, err := s.ServiceProvider(provider)
+	if err != nil {
+		return "", err
+	}
+	if sp.IDPMetadata == nil || sp.GetSLOBindingLocation(saml.HTTPRedirectBinding) == "" {
+		return "", nil
+	}
+
+	relayState, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	logoutURL, err := sp.MakeRedirectLogoutRequest(nameID, relayState)
+	if err != nil {
+		return "", err
+	}
+	return logoutURL.String(), nil
+}
+
+// ValidateLogoutResponse vérifie la LogoutResponse renvoyée par l'IdP
+func (s *SAMLService) ValidateLogoutResponse(provider *models.SAMLProvider, r *http.Request) error {
+	sp, err := s.ServiceProvider(provider)
+	if err != nil {
+		return err
+	}
+	return sp.ValidateLogoutResponseRequest(r)
+}
+
+// samlMaxLogoutRequestSize borne la taille d'une LogoutRequest décompressée (binding HTTP-Redirect)
+const samlMaxLogoutRequestSize = 1 << 20
+
+// ParseLogoutRequest valide une LogoutRequest émise par l'IdP (Single Logout initié par l'IdP) :
+// signature (XML ou paramètres Signature/SigAlg du binding HTTP-Redirect), émetteur, destination
+// et fenêtre de validité
+func (s *SAMLService) ParseLogoutRequest(provider *models.SAMLProvider, r *http.Request) (*saml.LogoutRequest, error) {
+	sp, err := s.ServiceProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if sp.IDPMetadata == nil {
+		return nil, errors.New("métadonnées de l'IdP non importées")
+	}
+	certs, err := idpSigningCertificates(sp.IDPMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []byte
+	redirectBinding := r.URL.Query().Get("SAMLRequest") != ""
+	if redirectBinding {
+		compressed, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("SAMLRequest"))
+		if err != nil {
+			return nil, fmt.Errorf("LogoutRequest mal encodée: %w", err)
+		}
+		raw, err = io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), samlMaxLogoutRequestSize))
+		if err != nil {
+			return nil, fmt.Errorf("LogoutRequest mal compressée: %w", err)
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		raw, err = base64.StdEncoding.DecodeString(r.PostForm.Get("SAMLRequest"))
+		if err != nil || len(raw) == 0 {
+			return nil, errors.New("LogoutRequest absente ou mal encodée")
+		}
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(raw); err != nil {
+		return nil, fmt.Errorf("LogoutRequest invalide: %w", err)
+	}
+	root := doc.Root()
+	if root == nil || root.Tag != "LogoutRequest" {
+		return nil, errors.New("message SAML inattendu (LogoutRequest attendue)")
+	}
+
+	// Binding HTTP-Redirect : la signature est portée par la query string (SAML Bindings §3.4.4.1) et
+	// couvre le message entier ; sinon elle est incluse dans le document et seul l'élément dont la
+	// signature a été vérifiée est lu (protection contre l'encapsulation de signature)
+	if redirectBinding && r.URL.Query().Get("Signature") != "" {
+		if err := verifyRedirectSignature(r.URL.RawQuery, certs); err != nil {
+			return nil, err
+		}
+	} else {
+		validated, err := verifyEnvelopedSignature(root, certs)
+		if err != nil {
+			return nil, err
+		}
+		signedDoc := etree.NewDocument()
+		signedDoc.SetRoot(validated)
+		if raw, err = signedDoc.WriteToBytes(); err != nil {
+			return nil, fmt.Errorf("LogoutRequest invalide: %w", err)
+		}
+	}
+
+	var req saml.LogoutRequest
+	if err := xml.Unmarshal(raw, &req); err != nil {
+		return nil, fmt.Errorf("LogoutRequest invalide: %w", err)
+	}
+
+	now := time.Now()
+	if req.Issuer == nil || req.Issuer.Value != sp.IDPMetadata.EntityID {
+		return nil, errors.New("émetteur de la LogoutRequest différent de l'IdP configuré")
+	}
+	if req.Destination != "" && req.Destination != sp.SloURL.String() {
+		return nil, errors.New("destination de la LogoutRequest invalide")
+	}
+	if req.IssueInstant.Add(saml.MaxIssueDelay).Before(now) {
+		return nil, errors.New("LogoutRequest expirée")
+	}
+	if req.NotOnOrAfter != nil && !now.Before(*req.NotOnOrAfter) {
+		return nil, errors.New("LogoutRequest expirée")
+	}
+	if req.NameID == nil || req.NameID.Value == "" {
+		return nil, errors.New("LogoutRequest sans NameID")
+	}
+	if req.ID == "" {
+		return nil, errors.New("LogoutRequest sans ID")
+	}
+
+	return &req, nil
+}
+
+// ClaimLogoutRequest mémorise l'ID d'une LogoutRequest validée jusqu'à la fin de sa fenêtre de validité
+// et refuse un ID déjà traité : une requête signée interceptée ne peut pas être rejouée
+func (s *SAMLService) ClaimLogoutRequest(provider *models.SAMLProvider, req *saml.LogoutRequest) error {
+	expiresAt := req.IssueInstant.Add(saml.MaxIssueDelay)
+	if req.NotOnOrAfter != nil && req.NotOnOrAfter.After(expiresAt) {
+		expiresAt = *req.NotOnOrAfter
+	}
+
+	s.cleanupExpired()
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.SAMLLogoutRequestID{
+		Provider:  provider.Name,
+		RequestID: req.ID,
+		ExpiresAt: expiresAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("LogoutRequest déjà traitée")
+	}
+	return nil
+}
+
+// MakeLogoutResponse répond à une LogoutRequest de l'IdP : URL de redirection (binding HTTP-Redirect)
+// ou formulaire auto-soumis (binding HTTP-POST)
+func (s *SAMLService) MakeLogoutResponse(provider *models.SAMLProvider, logoutRequestID, relayState string) (redirectURL string, form []byte, err error) {
+	sp, err := s.ServiceProvider(provider)
+	if err != nil {
+		return "", nil, err
+	}
+	if sp.IDPMetadata == nil {
+		return "", nil, errors.New("métadonnées de l'IdP non importées")
+	}
+
+	if sp.GetSLOBindingLocation(saml.HTTPRedirectBinding) != "" {
+		u, err := sp.MakeRedirectLogoutResponse(logoutRequestID, relayState)
+		if err != nil {
+			return "", nil, err
+		}
+		return u.String(), nil, nil
+	}
+	if sp.GetSLOBindingLocation(saml.HTTPPostBinding) != "" {
+		form, err := sp.MakePostLogoutResponse(logoutRequestID, relayState)
+		return "", form, err
+	}
+	return "", nil, errors.New("l'IdP ne publie aucun endpoint de Single Logout")
+}
+
+// idpSigningCertificates retourne les certificats de signature publiés dans les métadonnées de l'IdP
+func idpSigningCertificates(idp *saml.EntityDescriptor) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, descriptor := range idp.IDPSSODescriptors {
+		for _, keyDescriptor := range descriptor.KeyDescriptors {
+			if keyDescriptor.Use != "" && keyDescriptor.Use != "signing" {
+				continue
+			}
+			for _, c := range keyDescriptor.KeyInfo.X509Data.X509Certificates {
+				der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(c.Data), ""))
+				if err != nil {
+					return nil, fmt.Errorf("certificat de l'IdP invalide: %w", err)
+				}
+				cert, err := x509.ParseCertificate(der)
+				if err != nil {
+					return nil, fmt.Errorf("certificat de l'IdP invalide: %w", err)
+				}
+				certs = append(certs, cert)
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("aucun certificat de signature dans les métadonnées de l'IdP")
+	}
+	return certs, nil
+}
+
+// verifyEnvelopedSignature vérifie la signature XML incluse dans un message SAML et retourne l'élément
+// effectivement couvert par la signature
+func verifyEnvelopedSignature(el *etree.Element, certs []*x509.Certificate) (*etree.Element, error) {
+	if el.FindElement("./Signature") == nil {
+		return nil, errors.New("LogoutRequest non signée")
+	}
+	ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: certs})
+	ctx.IdAttribute = "ID"
+	validated, err := ctx.Validate(el)
+	if err != nil {
+		return nil, fmt.Errorf("signature de la LogoutRequest invalide: %w", err)
+	}
+	return validated, nil
+}
+
+// verifyRedirectSignature vérifie la signature d'un message transmis par le binding HTTP-Redirect,
+// calculée sur les paramètres SAMLRequest, RelayState et SigAlg tels qu'encodés dans l'URL
+func verifyRedirectSignature(rawQuery string, certs []*x509.Certificate) error {
+	encoded := make(map[string]string)
+	for _, part := range strings.Split(rawQuery, "&") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			encoded[key] = value
+		}
+	}
+
+	signed := "SAMLRequest=" + encoded["SAMLRequest"]
+	if relayState, ok := encoded["RelayState"]; ok {
+		signed += "&RelayState=" + relayState
+	}
+	signed += "&SigAlg=" + encoded["SigAlg"]
+
+	sigAlg, err := url.QueryUnescape(encoded["SigAlg"])
+	if err != nil {
+		return errors.New("SigAlg invalide")
+	}
+	var algorithm x509.SignatureAlgorithm
+	switch sigAlg {
+	case dsig.RSASHA256SignatureMethod:
+		algorithm = x509.SHA256WithRSA
+	case dsig.RSASHA512SignatureMethod:
+		algorithm = x509.SHA512WithRSA
+	case dsig.ECDSASHA256SignatureMethod:
+		algorithm = x509.ECDSAWithSHA256
+	default:
+		return fmt.Errorf("algorithme de signature non supporté: %s", sigAlg)
+	}
+
+	escaped, err := url.QueryUnescape(encoded["Signature"])
+	if err != nil {
+		return errors.New("signature mal encodée")
+	}
+	signature, err := base64.StdEncoding.DecodeString(escaped)
+	if err != nil {
+		return errors.New("signature mal encodée")
+	}
+
+	for _, cert := range certs {
+		if cert.CheckSignature(algorithm, []byte(signed), signature) == nil {
+			return nil
+		}
+	}
+	return errors.New("signature de la LogoutRequest invalide")
+}
+
+// IssueLoginCode crée un code à usage unique (1 minute) permettant au frontend
+// d'échanger la session SAML contre des tokens JWT
+func (s *SAMLService) IssueLoginCode(userID, samlSessionID uint) (string, error) {
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	s.cleanupExpired()
+	if err := s.db.Create(&models.SAMLLoginCode{
+		CodeHash:      HashAPIToken(code),
+		UserID:        userID,
+		SAMLSessionID: samlSessionID,
+		ExpiresAt:     time.Now().Add(samlLoginCodeTTL),
+	}).Error; err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// ConsumeLoginCode valide et consomme un code de connexion
+func (s *SAMLService) ConsumeLoginCode(code string) (*models.SAMLLoginCode, error) {
+	var entry models.SAMLLoginCode
+	if code == "" || s.db.Where("code_hash = ?", HashAPIToken(code)).First(&entry).Error != nil {
+		return nil, errors.New("code invalide")
+	}
+	// Suppression conditionnelle : le code ne peut être consommé qu'une fois, même en parallèle
+	consumed := s.db.Where("id = ?", entry.ID).Delete(&models.SAMLLoginCode{})
+	if consumed.Error != nil || consumed.RowsAffected != 1 {
+		return nil, errors.New("code invalide")
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		return nil, errors.New("code expiré")
+	}
+	return &entry, nil
+}
+
+// cleanupExpired supprime les requêtes en attente, codes de connexion et IDs de LogoutRequest expirés,
+// les connexions SAML dont le code n'a jamais été échangé et les sessions fermées dont les tokens ont expiré
+func (s *SAMLService) cleanupExpired() {
+	now := time.Now()
+	s.db.Where("expires_at < ?", now).Delete(&models.SAMLPendingRequest{})
+	s.db.Where("expires_at < ?", now).Delete(&models.SAMLLoginCode{})
+	s.db.Where("expires_at < ?", now).Delete(&models.SAMLLogoutRequestID{})
+	s.db.Where("login_session_id = '' AND created_at < ?", now.Add(-samlLoginCodeTTL)).Delete(&models.SAMLSession{})
+	s.db.Where("ended_at < ?", now.AddDate(0, 0, -s.config.JWT.RefreshExpirationDays)).Delete(&models.SAMLSession{})
+}
+
+// CertificatePEM retourne le certificat du service provider au format PEM
+func (s *SAMLService) CertificatePEM() (string, error) {
+	_, cert, err := s.credentials()
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})), nil
+}
+
+// credentials charge la paire clé/certificat du SP : fichiers configurés, sinon paire
+// stockée en base, sinon génération d'un certificat auto-signé persisté. Seul un chargement
+// réussi est mis en cache : une erreur transitoire sera retentée à l'appel suivant
+func (s *SAMLService) credentials() (*rsa.PrivateKey, *x509.Certificate, error) {
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+
+	if s.key != nil && s.cert != nil {
+		return s.key, s.cert, nil
+	}
+	key, cert, err := s.loadCredentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	s.key, s.cert = key, cert
+	return key, cert, nil
+}
+
+func (s *SAMLService) loadCredentials() (*rsa.PrivateKey, *x509.Certificate, error) {
+	if s.config.SAML.CertFile != "" && s.config.SAML.KeyFile != "" {
+		certPEM, err := os.ReadFile(s.config.SAML.CertFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("lecture du certificat SAML: %w", err)
+		}
+		keyPEM, err := os.ReadFile(s.config.SAML.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("lecture de la clé SAML: %w", err)
+		}
+		return parseSAMLKeyPair(certPEM, keyPEM)
+	}
+
+	var credential models.SAMLCredential
+	if err := s.db.Order("id DESC").First(&credential).Error; err == nil {
+		return parseSAMLKeyPair([]byte(credential.CertificatePEM), []byte(credential.PrivateKeyPEM))
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	log.Println("🔐 Génération d'un certificat SAML auto-signé pour le service provider")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	host := s.config.Server.PublicURL
+	if u, err := url.Parse(host); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: host, Organization: []string{"Airboard"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := s.db.Create(&models.SAMLCredential{
+		CertificatePEM: string(certPEM),
+		PrivateKeyPEM:  string(keyPEM),
+	}).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return parseSAMLKeyPair(certPEM, keyPEM)
+}
+
+func parseSAMLKeyPair(certPEM, keyPEM []byte) (*rsa.PrivateKey, *x509.Certificate, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("paire clé/certificat SAML invalide: %w", err)
+	}
+	key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("la clé SAML doit être une clé RSA")
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, cert, nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"airboard/config"
+	"airboard/models"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const samlTestIDPEntityID = "https://idp.example.com/metadata"
+
+// newSAMLTestCertificate génère une paire clé/certificat auto-signée
+func newSAMLTestCertificate(t *testing.T, cn string) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("génération de la clé RSA: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("création du certificat: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("lecture du certificat: %v", err)
+	}
+	return key, cert
+}
+
+// newSAMLLogoutTest prépare un service SAML (clés SP en mémoire) et un fournisseur dont l'IdP signe avec idpCert
+func newSAMLLogoutTest(t *testing.T, idpCert *x509.Certificate) (*SAMLService, *models.SAMLProvider) {
+	t.Helper()
+	spKey, spCert := newSAMLTestCertificate(t, "airboard.example.com")
+	service := NewSAMLService(nil, &config.Config{Server: config.ServerConfig{PublicURL: "https://airboard.example.com"}})
+	service.key, service.cert = spKey, spCert
+
+	metadata := fmt.Sprintf(`<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="%s">
+  <IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <KeyDescriptor use="signing">
+      <KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>%s</X509Certificate></X509Data></KeyInfo>
+    </KeyDescriptor>
+    <SingleLogoutService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/slo"/>
+    <SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso"/>
+  </IDPSSODescriptor>
+</EntityDescriptor>`, samlTestIDPEntityID, base64.StdEncoding.EncodeToString(idpCert.Raw))
+
+	return service, &models.SAMLProvider{Name: "corp", IDPMetadataXML: metadata, SLOEnabled: true}
+}
+
+// redirectLogoutRequest encode une LogoutRequest pour le binding HTTP-Redirect, signée par idpKey
+func redirectLogoutRequest(t *testing.T, idpKey *rsa.PrivateKey, issuer, nameID string) string {
+	t.Helper()
+	xml := logoutRequestXML(issuer, nameID)
+
+	var compressed bytes.Buffer
+	w, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	w.Write([]byte(xml))
+	w.Close()
+
+	query := "SAMLRequest=" + url.QueryEscape(base64.StdEncoding.EncodeToString(compressed.Bytes())) +
+		"&RelayState=" + url.QueryEscape("relay-1") +
+		"&SigAlg=" + url.QueryEscape(dsig.RSASHA256SignatureMethod)
+	digest := sha256.Sum256([]byte(query))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idpKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("signature de la LogoutRequest: %v", err)
+	}
+	return query + "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+}
+
+func TestParseLogoutRequest(t *testing.T) {
+	idpKey, idpCert := newSAMLTestCertificate(t, "idp.example.com")
+	attackerKey, _ := newSAMLTestCertificate(t, "attacker.example.com")
+	service, provider := newSAMLLogoutTest(t, idpCert)
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{
+			name:  "requête signée par l'IdP",
+			query: redirectLogoutRequest(t, idpKey, samlTestIDPEntityID, "alice@example.com"),
+		},
+		{
+			name:    "signature d'une autre clé",
+			query:   redirectLogoutRequest(t, attackerKey, samlTestIDPEntityID, "alice@example.com"),
+			wantErr: "signature",
+		},
+		{
+			name:    "RelayState modifié après signature",
+			query:   strings.Replace(redirectLogoutRequest(t, idpKey, samlTestIDPEntityID, "alice@example.com"), "RelayState=relay-1", "RelayState=relay-2", 1),
+			wantErr: "signature",
+		},
+		{
+			name:    "émetteur inconnu",
+			query:   redirectLogoutRequest(t, idpKey, "https://evil.example.com", "alice@example.com"),
+			wantErr: "émetteur",
+		},
+		{
+			name: "requête non signée",
+			query: func() string {
+				query := redirectLogoutRequest(t, idpKey, samlTestIDPEntityID, "alice@example.com")
+				return query[:strings.Index(query, "&Signature=")]
+			}(),
+			wantErr: "non signée",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/auth/saml/corp/slo?"+tt.query, nil)
+			logoutRequest, err := service.ParseLogoutRequest(provider, req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("erreur inattendue: %v", err)
+				}
+				if logoutRequest.NameID.Value != "alice@example.com" || logoutRequest.ID != "id-logout-1" {
+					t.Fatalf("LogoutRequest inattendue: %+v", logoutRequest)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("erreur attendue contenant %q, requête acceptée", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("erreur = %q, attendu %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// logoutRequestXML LogoutRequest non signée émise par issuer pour nameID
+func logoutRequestXML(issuer, nameID string) string {
+	return fmt.Sprintf(`<samlp:LogoutRequest xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="id-logout-1" Version="2.0" IssueInstant="%s" Destination="https://airboard.example.com/api/v1/auth/saml/corp/slo"><saml:Issuer>%s</saml:Issuer><saml:NameID>%s</saml:NameID><samlp:SessionIndex>session-1</samlp:SessionIndex></samlp:LogoutRequest>`,
+		time.Now().UTC().Format(time.RFC3339), issuer, nameID)
+}
+
+// signedLogoutRequestXML LogoutRequest dont la signature XML (binding HTTP-POST) est produite par idpKey
+func signedLogoutRequestXML(t *testing.T, idpKey *rsa.PrivateKey, idpCert *x509.Certificate, nameID string) *etree.Document {
+	t.Helper()
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(logoutRequestXML(samlTestIDPEntityID, nameID)); err != nil {
+		t.Fatalf("lecture de la LogoutRequest: %v", err)
+	}
+	ctx := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{Certificate: [][]byte{idpCert.Raw}, PrivateKey: idpKey}))
+	signed, err := ctx.SignEnveloped(doc.Root())
+	if err != nil {
+		t.Fatalf("signature de la LogoutRequest: %v", err)
+	}
+	doc.SetRoot(signed)
+	return doc
+}
+
+// postLogoutRequest requête HTTP-POST portant la LogoutRequest doc
+func postLogoutRequest(t *testing.T, doc *etree.Document) *http.Request {
+	t.Helper()
+	raw, err := doc.WriteToBytes()
+	if err != nil {
+		t.Fatalf("sérialisation de la LogoutRequest: %v", err)
+	}
+	form := url.Values{"SAMLRequest": {base64.StdEncoding.EncodeToString(raw)}}
+	req := httptest.NewRequest("POST", "/api/v1/auth/saml/corp/slo", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestParseLogoutRequestPOST(t *testing.T) {
+	idpKey, idpCert := newSAMLTestCertificate(t, "idp.example.com")
+	service, provider := newSAMLLogoutTest(t, idpCert)
+
+	t.Run("requête signée par l'IdP", func(t *testing.T) {
+		logoutRequest, err := service.ParseLogoutRequest(provider, postLogoutRequest(t, signedLogoutRequestXML(t, idpKey, idpCert, "alice@example.com")))
+		if err != nil {
+			t.Fatalf("erreur inattendue: %v", err)
+		}
+		if logoutRequest.NameID.Value != "alice@example.com" || logoutRequest.ID != "id-logout-1" {
+			t.Fatalf("LogoutRequest inattendue: %+v", logoutRequest)
+		}
+	})
+
+	t.Run("NameID modifié après signature", func(t *testing.T) {
+		doc := signedLogoutRequestXML(t, idpKey, idpCert, "alice@example.com")
+		doc.Root().FindElement("./NameID").SetText("admin@example.com")
+		if _, err := service.ParseLogoutRequest(provider, postLogoutRequest(t, doc)); err == nil || !strings.Contains(err.Error(), "signature") {
+			t.Fatalf("erreur de signature attendue, obtenu %v", err)
+		}
+	})
+
+	t.Run("NameID ajouté hors de l'élément signé", func(t *testing.T) {
+		// Le premier NameID lu par un décodage du document brut serait celui de l'attaquant
+		doc := signedLogoutRequestXML(t, idpKey, idpCert, "alice@example.com")
+		injected := etree.NewElement("saml:NameID")
+		injected.SetText("admin@example.com")
+		doc.Root().InsertChildAt(0, injected)
+		if _, err := service.ParseLogoutRequest(provider, postLogoutRequest(t, doc)); err == nil {
+			t.Fatal("LogoutRequest modifiée acceptée")
+		}
+	})
+}
+
+func TestMakeLogoutResponse(t *testing.T) {
+	_, idpCert := newSAMLTestCertificate(t, "idp.example.com")
+	service, provider := newSAMLLogoutTest(t, idpCert)
+
+	redirectURL, _, err := service.MakeLogoutResponse(provider, "id-logout-1", "relay-1")
+	if err != nil {
+		t.Fatalf("création de la LogoutResponse: %v", err)
+	}
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		t.Fatalf("URL de la LogoutResponse invalide: %v", err)
+	}
+	if u.Host != "idp.example.com" || u.Path != "/slo" || u.Query().Get("SAMLResponse") == "" || u.Query().Get("RelayState") != "relay-1" {
+		t.Fatalf("URL de la LogoutResponse inattendue: %s", redirectURL)
+	}
+
+	compressed, _ := base64.StdEncoding.DecodeString(u.Query().Get("SAMLResponse"))
+	var body bytes.Buffer
+	body.ReadFrom(flate.NewReader(bytes.NewReader(compressed)))
+	if !strings.Contains(body.String(), `InResponseTo="id-logout-1"`) || !strings.Contains(body.String(), saml.StatusSuccess) {
+		t.Fatalf("LogoutResponse inattendue: %s", body.String())
+	}
+}
@@ -0,0 +1,700 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"airboard/models"
+	"airboard/utils"
+
+	"gorm.io/gorm"
+)
+
+// Types de résultats de la recherche globale
+const (
+	SearchTypeApp          = "app"
+	SearchTypeNews         = "news"
+	SearchTypeEvent        = "event"
+	SearchTypePoll         = "poll"
+	SearchTypeAnnouncement = "announcement"
+	SearchTypePerson       = "person"
+)
+
+// Pagination de la recherche globale
+const (
+	SearchDefaultPageSize = 20
+	SearchMaxPageSize     = 50
+	searchMaxTerms        = 8
+)
+
+// Configurations linguistiques prises en charge, la première disponible sert de langue par défaut
+var searchLanguages = []string{"french", "english", "arabic", "spanish"}
+
+// Mots vides servant à détecter la langue d'un contenu latin
+var searchStopWords = map[string][]string{
+	"french":  {"le", "la", "les", "des", "du", "un", "une", "est", "et", "pour", "dans", "sur", "avec", "nous", "vous", "pas", "au", "aux", "ce", "cette", "qui"},
+	"english": {"the", "and", "is", "are", "of", "to", "in", "for", "with", "on", "this", "that", "be", "we", "you", "it", "at", "from", "will", "has", "have"},
+	"spanish": {"el", "los", "las", "del", "es", "y", "una", "para", "con", "por", "se", "su", "como", "más", "está", "pero", "al", "lo", "nuestro", "este", "esta"},
+}
+
+// searchTable table indexée : colonnes pondérées A (titre), B (résumé) et C (corps), texte des extraits
+type searchTable struct {
+	Type     string
+	Table    string
+	Alias    string
+	Fields   []string
+	Snippet  string
+	SortDate string // Départage des résultats de même pertinence
+}
+
+var searchTables = []searchTable{
+	{SearchTypeApp, "applications", "a", []string{"name", "description"}, "a.description", "a.updated_at"},
+	{SearchTypeNews, "news", "n", []string{"title", "summary", "search_text"}, "concat_ws(' ', n.summary, left(n.search_text, 5000))", "COALESCE(n.published_at, n.created_at)"},
+	{SearchTypeEvent, "events", "e", []string{"title", "location", "search_text"}, "concat_ws(' ', e.location, left(e.search_text, 5000))", "e.start_date"},
+	{SearchTypePoll, "polls", "p", []string{"title", "description"}, "p.description", "p.created_at"},
+	{SearchTypeAnnouncement, "announcements", "an", []string{"title", "content"}, "an.content", "an.created_at"},
+}
+
+// Délimiteurs des termes trouvés dans les extraits, remplacés par <mark> après échappement du texte
+const (
+	searchHighlightStart = "[[hl]]"
+	searchHighlightStop  = "[[/hl]]"
+)
+
+// SearchScope visibilité de l'utilisateur qui recherche
+type SearchScope struct {
+	UserID   uint
+	GroupIDs []uint // Groupes d'appartenance et groupes administrés
+
+	// Contenus visibles sans filtre de groupe ni de publication (permissions de gestion par type)
+	AllApps   bool
+	AllNews   bool
+	AllEvents bool
+	AllPolls  bool
+
+	ViewPrivateProfiles bool // Profils masqués et champs privés de l'annuaire visibles (gestion des utilisateurs)
+}
+
+// SearchParams paramètres d'une recherche globale
+type SearchParams struct {
+	Query    string
+	Type     string // Vide : tous les types
+	Page     int
+	PageSize int
+}
+
+// Structures de réponse pour la recherche
+type SearchResultApp struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Icon         string `json:"icon"`
+	URL          string `json:"url"`
+	Color        string `json:"color"`
+	AppGroupName string `json:"app_group_name"`
+	Snippet      string `json:"snippet"`
+}
+
+type SearchResultNews struct {
+	ID           uint      `json:"id"`
+	Title        string    `json:"title"`
+	Slug         string    `json:"slug"`
+	Summary      string    `json:"summary"`
+	CategoryName string    `json:"category_name"`
+	AuthorName   string    `json:"author_name"`
+	CreatedAt    time.Time `json:"created_at"`
+	Snippet      string    `json:"snippet"`
+}
+
+type SearchResultEvent struct {
+	ID        uint       `json:"id"`
+	Title     string     `json:"title"`
+	Slug      string     `json:"slug"`
+	Location  string     `json:"location"`
+	StartDate time.Time  `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+	Snippet   string     `json:"snippet"`
+}
+
+type SearchResultPoll struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	IsActive    bool       `json:"is_active"`
+	EndDate     *time.Time `json:"end_date"`
+	Snippet     string     `json:"snippet"`
+}
+
+type SearchResultAnnouncement struct {
+	ID        uint       `json:"id"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Type      string     `json:"type"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+	Snippet   string     `json:"snippet"`
+}
+
+// SearchHit résultat classé, tous types confondus (Item contient le résultat détaillé du type)
+type SearchHit struct {
+	Type    string      `json:"type"`
+	ID      uint        `json:"id"`
+	Rank    float64     `json:"rank"`
+	Snippet string      `json:"snippet"`
+	Item    interface{} `json:"item"`
+}
+
+// SearchResponse page de résultats : liste classée et, pour compatibilité, résultats de la page regroupés par type
+type SearchResponse struct {
+	Query         string                     `json:"query"`
+	Results       []SearchHit                `json:"results"`
+	Applications  []SearchResultApp          `json:"applications"`
+	News          []SearchResultNews         `json:"news"`
+	Events        []SearchResultEvent        `json:"events"`
+	Polls         []SearchResultPoll         `json:"polls"`
+	Announcements []SearchResultAnnouncement `json:"announcements"`
+	People        []models.DirectoryPerson   `json:"people"`
+	Counts        map[string]int             `json:"counts"` // Nombre total de résultats par type
+	TotalCount    int                        `json:"total_count"`
+	Page          int                        `json:"page"`
+	PageSize      int                        `json:"page_size"`
+	TotalPages    int                        `json:"total_pages"`
+	DidYouMean    string                     `json:"did_you_mean,omitempty"` // Requête corrigée proposée si aucun résultat
+	Suggestions   []SearchSuggestion         `json:"suggestions,omitempty"`  // Contenus proches de la requête si aucun résultat
+}
+
+// SearchService recherche plein texte PostgreSQL : vecteurs pondérés maintenus par trigger, classement et extraits
+type SearchService struct {
+	db        *gorm.DB
+	languages []string // Configurations disponibles sur le serveur PostgreSQL
+	trigram   bool     // Extension pg_trgm installée : suggestions tolérantes aux fautes
+	directory *DirectoryService
+}
+
+// NewSearchService crée une nouvelle instance de SearchService
+func NewSearchService(db *gorm.DB) *SearchService {
+	s := &SearchService{db: db, directory: NewDirectoryService(db)}
+	s.languages = s.availableLanguages()
+	s.trigram = s.trigramInstalled()
+	return s
+}
+
+// ============ INDEXATION ============
+
+// EnsureSchema crée les colonnes, fonctions, triggers et index GIN de la recherche plein texte,
+// puis indexe les lignes existantes. Idempotent : exécuté à chaque démarrage.
+func (s *SearchService) EnsureSchema() error {
+	statements := []string{s.languageFunctionSQL(), searchTriggerFunctionSQL}
+	for _, table := range searchTables {
+		statements = append(statements,
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS search_config regconfig", table.Table),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS search_vector tsvector", table.Table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_search_vector ON %s USING GIN (search_vector)", table.Table, table.Table),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS trg_%s_search_vector ON %s", table.Table, table.Table),
+			fmt.Sprintf("CREATE TRIGGER trg_%s_search_vector BEFORE INSERT OR UPDATE OF %s ON %s FOR EACH ROW EXECUTE FUNCTION airboard_search_vector(%s)",
+				table.Table, strings.Join(table.Fields, ", "), table.Table, "'"+strings.Join(table.Fields, "', '")+"'"),
+		)
+	}
+	for _, statement := range statements {
+		if err := s.db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	s.ensureTrigram()
+
+	s.backfillText(&models.News{}, "content")
+	s.backfillText(&models.Event{}, "description")
+
+	// Lignes créées avant l'installation des triggers : la mise à jour du titre déclenche l'indexation
+	for _, table := range searchTables {
+		result := s.db.Exec(fmt.Sprintf("UPDATE %s SET %s = %s WHERE search_vector IS NULL", table.Table, table.Fields[0], table.Fields[0]))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("[Search] %d lignes indexées dans %s", result.RowsAffected, table.Table)
+		}
+	}
+	return nil
+}
+
+// backfillText extrait le texte brut des contenus Tiptap enregistrés avant l'ajout de search_text
+func (s *SearchService) backfillText(model interface{}, contentColumn string) {
+	type row struct {
+		ID      uint
+		Content string
+	}
+	var rows []row
+	s.db.Model(model).Select("id, " + contentColumn + " AS content").
+		Where("(search_text IS NULL OR search_text = '') AND " + contentColumn + " <> ''").
+		Scan(&rows)
+	for _, r := range rows {
+		// UpdateColumn n'exécute pas les hooks et ne modifie pas updated_at
+		s.db.Model(model).Where("id = ?", r.ID).UpdateColumn("search_text", utils.TiptapToText(r.Content))
+	}
+}
+
+// availableLanguages retourne les configurations de searchLanguages installées sur le serveur
+func (s *SearchService) availableLanguages() []string {
+	var installed []string
+	s.db.Raw("SELECT cfgname FROM pg_ts_config").Scan(&installed)
+	set := make(map[string]bool, len(installed))
+	for _, name := range installed {
+		set[name] = true
+	}
+	var languages []string
+	for _, language := range searchLanguages {
+		if set[language] {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// languageFunctionSQL fonction de détection de la langue d'un document : écriture arabe, sinon langue dont
+// les mots vides sont les plus fréquents ; la première langue disponible en cas d'égalité
+func (s *SearchService) languageFunctionSQL() string {
+	fallback := "simple"
+	if len(s.languages) > 0 {
+		fallback = s.languages[0]
+	}
+
+	var latin []string
+	for _, language := range s.languages {
+		if language != "arabic" {
+			latin = append(latin, language)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("CREATE OR REPLACE FUNCTION airboard_search_config(doc text) RETURNS regconfig AS $$\nDECLARE\n")
+	b.WriteString("\tbest regconfig := '" + fallback + "';\n\tbest_count int := 0;\n")
+	for _, language := range latin {
+		b.WriteString("\tn_" + language + " int;\n")
+	}
+	b.WriteString("BEGIN\n\tIF doc IS NULL OR doc = '' THEN RETURN best; END IF;\n")
+	for _, language := range s.languages {
+		if language == "arabic" {
+			b.WriteString("\tIF doc ~ '[\\u0600-\\u06FF]' THEN RETURN 'arabic'; END IF;\n")
+		}
+	}
+	if len(latin) > 0 {
+		counts := make([]string, len(latin))
+		into := make([]string, len(latin))
+		for i, language := range latin {
+			counts[i] = fmt.Sprintf("count(*) FILTER (WHERE w IN ('%s'))", strings.Join(searchStopWords[language], "', '"))
+			into[i] = "n_" + language
+		}
+		b.WriteString("\tSELECT " + strings.Join(counts, ", ") + " INTO " + strings.Join(into, ", ") +
+			" FROM unnest(regexp_split_to_array(lower(left(doc, 4000)), '[^[:alpha:]]+')) AS w;\n")
+		for _, language := range latin {
+			b.WriteString(fmt.Sprintf("\tIF n_%s > best_count THEN best := '%s'; best_count := n_%s; END IF;\n", language, language, language))
+		}
+	}
+	b.WriteString("\tRETURN best;\nEND;\n$$ LANGUAGE plpgsql IMMUTABLE")
+	return b.String()
+}
+
+// Trigger générique : les arguments sont les colonnes pondérées A, B et C ; le titre est aussi indexé
+// sans racinisation pour retrouver noms propres et acronymes
+const searchTriggerFunctionSQL = `CREATE OR REPLACE FUNCTION airboard_search_vector() RETURNS trigger AS $$
+DECLARE
+	doc jsonb := to_jsonb(NEW);
+	a text := coalesce(doc->>TG_ARGV[0], '');
+	b text := '';
+	c text := '';
+	cfg regconfig;
+BEGIN
+	IF TG_NARGS > 1 THEN b := coalesce(doc->>TG_ARGV[1], ''); END IF;
+	IF TG_NARGS > 2 THEN c := coalesce(doc->>TG_ARGV[2], ''); END IF;
+	cfg := airboard_search_config(concat_ws(' ', a, b, left(c, 4000)));
+	NEW.search_config := cfg;
+	NEW.search_vector :=
+		setweight(to_tsvector(cfg, a), 'A') ||
+		setweight(to_tsvector('simple', a), 'A') ||
+		setweight(to_tsvector(cfg, b), 'B') ||
+		setweight(to_tsvector(cfg, c), 'C');
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`
+
+// ============ RECHERCHE ============
+
+// SearchTerms découpe une requête en termes (lettres et chiffres uniquement) : la ponctuation, les apostrophes
+// ("l'équipe") et les opérateurs tsquery sont des séparateurs. Les termes d'une lettre sont ignorés s'il en reste d'autres.
+func SearchTerms(q string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	})
+	var terms, short []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		if len([]rune(field)) == 1 {
+			short = append(short, field)
+			continue
+		}
+		terms = append(terms, field)
+	}
+	if len(terms) == 0 {
+		terms = short
+	}
+	if len(terms) > searchMaxTerms {
+		terms = terms[:searchMaxTerms]
+	}
+	return terms
+}
+
+// searchQuery expression SQL de la requête : union des tsquery de chaque langue, chaque terme en préfixe
+func (s *SearchService) searchQuery(terms []string) (string, []interface{}) {
+	prefixed := make([]string, len(terms))
+	for i, term := range terms {
+		prefixed[i] = term + ":*"
+	}
+	tsquery := strings.Join(prefixed, " & ")
+
+	configs := append(append([]string{}, s.languages...), "simple")
+	parts := make([]string, len(configs))
+	args := make([]interface{}, len(configs))
+	for i, config := range configs {
+		parts[i] = fmt.Sprintf("to_tsquery('%s', ?)", config)
+		args[i] = tsquery
+	}
+	return "WITH q AS (SELECT (" + strings.Join(parts, " || ") + ") AS query) ", args
+}
+
+// Search exécute une recherche classée sur tous les types visibles, avec pagination commune
+func (s *SearchService) Search(params SearchParams, scope SearchScope) (*SearchResponse, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 {
+		params.PageSize = SearchDefaultPageSize
+	}
+	if params.PageSize > SearchMaxPageSize {
+		params.PageSize = SearchMaxPageSize
+	}
+
+	response := &SearchResponse{
+		Query:         params.Query,
+		Results:       []SearchHit{},
+		Applications:  []SearchResultApp{},
+		News:          []SearchResultNews{},
+		Events:        []SearchResultEvent{},
+		Polls:         []SearchResultPoll{},
+		Announcements: []SearchResultAnnouncement{},
+		People:        []models.DirectoryPerson{},
+		Counts:        map[string]int{},
+		Page:          params.Page,
+		PageSize:      params.PageSize,
+	}
+
+	terms := SearchTerms(params.Query)
+	if len(terms) == 0 {
+		return response, nil
+	}
+	cte, cteArgs := s.searchQuery(terms)
+	union, unionArgs := s.matchesSQL(params.Type, scope)
+	if union == "" {
+		return response, nil
+	}
+
+	// Nombre de résultats par type
+	type countRow struct {
+		Type  string
+		Total int
+	}
+	var counts []countRow
+	if err := s.db.Raw(cte+"SELECT type, count(*) AS total FROM ("+union+") r GROUP BY type",
+		append(append([]interface{}{}, cteArgs...), unionArgs...)...).Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		response.Counts[count.Type] = count.Total
+		response.TotalCount += count.Total
+	}
+	response.TotalPages = (response.TotalCount + params.PageSize - 1) / params.PageSize
+	if response.TotalCount == 0 {
+		if params.Page == 1 {
+			response.DidYouMean, response.Suggestions = s.didYouMean(params.Query, terms, scope)
+		}
+		return response, nil
+	}
+
+	// Page de résultats classés (titre > résumé > corps), puis les plus récents
+	type hitRow struct {
+		Type string
+		ID   uint
+		Rank float64
+	}
+	var hits []hitRow
+	args := append(append([]interface{}{}, cteArgs...), unionArgs...)
+	args = append(args, params.PageSize, (params.Page-1)*params.PageSize)
+	if err := s.db.Raw(cte+"SELECT type, id, rank FROM ("+union+") r ORDER BY rank DESC, sort_date DESC NULLS LAST, type, id LIMIT ? OFFSET ?",
+		args...).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+
+	idsByType := make(map[string][]uint)
+	for _, hit := range hits {
+		idsByType[hit.Type] = append(idsByType[hit.Type], hit.ID)
+	}
+	items, snippets, err := s.hydrate(idsByType, cte, cteArgs, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hit := range hits {
+		key := fmt.Sprintf("%s:%d", hit.Type, hit.ID)
+		item, ok := items[key]
+		if !ok {
+			continue
+		}
+		response.Results = append(response.Results, SearchHit{Type: hit.Type, ID: hit.ID, Rank: hit.Rank, Snippet: snippets[key], Item: item})
+		switch v := item.(type) {
+		case SearchResultApp:
+			response.Applications = append(response.Applications, v)
+		case SearchResultNews:
+			response.News = append(response.News, v)
+		case SearchResultEvent:
+			response.Events = append(response.Events, v)
+		case SearchResultPoll:
+			response.Polls = append(response.Polls, v)
+		case SearchResultAnnouncement:
+			response.Announcements = append(response.Announcements, v)
+		case models.DirectoryPerson:
+			response.People = append(response.People, v)
+		}
+	}
+	return response, nil
+}
+
+// matchesSQL union des correspondances visibles (type, id, rank, sort_date) pour les types demandés
+func (s *SearchService) matchesSQL(typeFilter string, scope SearchScope) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	now := time.Now()
+
+	for _, table := range searchTables {
+		if typeFilter != "" && typeFilter != table.Type {
+			continue
+		}
+		visible, visibleArgs, ok := visibilitySQL(table.Type, scope, now)
+		if !ok {
+			continue
+		}
+		a := table.Alias
+		parts = append(parts, fmt.Sprintf("SELECT '%s'::text AS type, %s.id, ts_rank(%s.search_vector, q.query, 1) AS rank, %s AS sort_date FROM %s %s CROSS JOIN q WHERE %s.search_vector @@ q.query%s",
+			table.Type, a, a, table.SortDate, table.Table, a, a, visible))
+		args = append(args, visibleArgs...)
+	}
+	if typeFilter == "" || typeFilter == SearchTypePerson {
+		sql, peopleArgs := peopleMatchSQL(scope)
+		parts = append(parts, sql)
+		args = append(args, peopleArgs...)
+	}
+	return strings.Join(parts, " UNION ALL "), args
+}
+
+// peopleMatchSQL correspondances de l'annuaire : nom et identifiant, puis poste et département s'ils sont visibles.
+// Le vecteur est calculé à la volée, en respectant les réglages de confidentialité de chaque personne.
+func peopleMatchSQL(scope SearchScope) (string, []interface{}) {
+	viewer := scope.directoryViewer()
+	listed, listedArgs := directoryListedSQL(viewer)
+	jobVisible, jobArgs := profileFieldVisibleSQL(models.ProfileFieldJobTitle, viewer)
+	departmentVisible, departmentArgs := profileFieldVisibleSQL(models.ProfileFieldDepartment, viewer)
+
+	vector := "setweight(to_tsvector('simple', concat_ws(' ', u.first_name, u.last_name, u.username)), 'A') || " +
+		"setweight(to_tsvector('simple', concat_ws(' ', CASE WHEN " + jobVisible + " THEN u.job_title END, CASE WHEN " + departmentVisible + " THEN u.department END)), 'B')"
+	sql := fmt.Sprintf("SELECT '%s'::text AS type, u.id, ts_rank(pv.vector, q.query, 1) AS rank, u.updated_at AS sort_date FROM users u "+
+		"LEFT JOIN profile_privacies pp ON pp.user_id = u.id CROSS JOIN q CROSS JOIN LATERAL (SELECT %s AS vector) pv WHERE pv.vector @@ q.query AND %s",
+		SearchTypePerson, vector, listed)
+
+	args := append(append(jobArgs, departmentArgs...), listedArgs...)
+	return sql, args
+}
+
+// directoryViewer utilisateur de l'annuaire correspondant à la portée de recherche
+func (scope SearchScope) directoryViewer() DirectoryViewer {
+	return DirectoryViewer{UserID: scope.UserID, IsAdmin: scope.ViewPrivateProfiles}
+}
+
+// visibilitySQL conditions de visibilité d'un type pour l'utilisateur (alias de searchTables) ;
+// ok vaut false si aucun contenu de ce type ne peut lui être visible
+func visibilitySQL(searchType string, scope SearchScope, now time.Time) (sql string, args []interface{}, ok bool) {
+	switch searchType {
+	case SearchTypeApp:
+		sql = " AND a.deleted_at IS NULL AND a.is_active = true"
+		if !scope.AllApps {
+			// Sans groupe, aucune application n'est visible
+			if len(scope.GroupIDs) == 0 {
+				return "", nil, false
+			}
+			sql += ` AND EXISTS (SELECT 1 FROM app_groups ag JOIN group_app_groups gag ON gag.app_group_id = ag.id
+				WHERE ag.id = a.app_group_id AND ag.is_active = true AND gag.group_id IN ?)`
+			args = append(args, scope.GroupIDs)
+		}
+	case SearchTypeNews:
+		sql = " AND n.deleted_at IS NULL"
+		if !scope.AllNews {
+			sql += " AND n.is_published = true AND (n.published_at IS NULL OR n.published_at <= ?)"
+			args = append(args, now)
+			sql, args = targetGroupsSQL(sql, args, "news_target_groups", "news_id", "n.id", scope.GroupIDs)
+		}
+	case SearchTypeEvent:
+		sql = " AND e.deleted_at IS NULL"
+		if !scope.AllEvents {
+			sql += " AND e.is_published = true AND (e.published_at IS NULL OR e.published_at <= ?)"
+			args = append(args, now)
+			sql, args = targetGroupsSQL(sql, args, "event_target_groups", "event_id", "e.id", scope.GroupIDs)
+		}
+	case SearchTypePoll:
+		sql = " AND p.deleted_at IS NULL"
+		if !scope.AllPolls {
+			sql, args = targetGroupsSQL(sql, args, "poll_target_groups", "poll_id", "p.id", scope.GroupIDs)
+		}
+	case SearchTypeAnnouncement:
+		// Annonces actives uniquement, sans filtre par groupe
+		sql = " AND an.deleted_at IS NULL AND an.is_active = true AND (an.start_date IS NULL OR an.start_date <= ?) AND (an.end_date IS NULL OR an.end_date >= ?)"
+		args = append(args, now, now)
+	default:
+		return "", nil, false
+	}
+	return sql, args, true
+}
+
+// targetGroupsSQL restreint aux contenus globaux ou ciblant l'un des groupes de l'utilisateur
+func targetGroupsSQL(sql string, args []interface{}, joinTable, fkColumn, idExpr string, groupIDs []uint) (string, []interface{}) {
+	global := fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s)", joinTable, joinTable, fkColumn, idExpr)
+	if len(groupIDs) == 0 {
+		return sql + " AND " + global, args
+	}
+	sql += fmt.Sprintf(" AND (%s OR EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s AND %s.group_id IN ?))",
+		global, joinTable, joinTable, fkColumn, idExpr, joinTable)
+	return sql, append(args, groupIDs)
+}
+
+// hydrate charge le détail et l'extrait surligné des résultats de la page
+func (s *SearchService) hydrate(idsByType map[string][]uint, cte string, cteArgs []interface{}, scope SearchScope) (map[string]interface{}, map[string]string, error) {
+	items := make(map[string]interface{})
+	snippets := make(map[string]string)
+	headline := func(alias, text string) string {
+		return fmt.Sprintf("ts_headline(COALESCE(%s.search_config, 'simple'), COALESCE(%s, ''), q.query, "+
+			"'StartSel=\"%s\", StopSel=\"%s\", MaxWords=35, MinWords=12, ShortWord=2, MaxFragments=2, FragmentDelimiter=\" … \"') AS snippet",
+			alias, text, searchHighlightStart, searchHighlightStop)
+	}
+	raw := func(query string, ids []uint, dest interface{}) error {
+		args := append(append([]interface{}{}, cteArgs...), ids)
+		return s.db.Raw(cte+query, args...).Scan(dest).Error
+	}
+
+	for _, table := range searchTables {
+		ids := idsByType[table.Type]
+		if len(ids) == 0 {
+			continue
+		}
+		snippetSQL := headline(table.Alias, table.Snippet)
+
+		switch table.Type {
+		case SearchTypeApp:
+			var rows []SearchResultApp
+			if err := raw(`SELECT a.id, a.name, a.description, a.icon, a.url, a.color, ag.name AS app_group_name, `+snippetSQL+`
+				FROM applications a LEFT JOIN app_groups ag ON ag.id = a.app_group_id CROSS JOIN q WHERE a.id IN ?`, ids, &rows); err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				row.Snippet = formatSnippet(row.Snippet)
+				key := fmt.Sprintf("%s:%d", table.Type, row.ID)
+				items[key], snippets[key] = row, row.Snippet
+			}
+		case SearchTypeNews:
+			type newsRow struct {
+				SearchResultNews
+				FirstName string
+				LastName  string
+			}
+			var rows []newsRow
+			if err := raw(`SELECT n.id, n.title, n.slug, n.summary, COALESCE(nc.name, '') AS category_name, u.first_name, u.last_name, n.created_at, `+snippetSQL+`
+				FROM news n LEFT JOIN news_categories nc ON nc.id = n.category_id LEFT JOIN users u ON u.id = n.author_id CROSS JOIN q WHERE n.id IN ?`, ids, &rows); err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				result := row.SearchResultNews
+				result.AuthorName = strings.TrimSpace(row.FirstName + " " + row.LastName)
+				result.Snippet = formatSnippet(result.Snippet)
+				key := fmt.Sprintf("%s:%d", table.Type, result.ID)
+				items[key], snippets[key] = result, result.Snippet
+			}
+		case SearchTypeEvent:
+			var rows []SearchResultEvent
+			if err := raw(`SELECT e.id, e.title, e.slug, e.location, e.start_date, e.end_date, `+snippetSQL+`
+				FROM events e CROSS JOIN q WHERE e.id IN ?`, ids, &rows); err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				row.Snippet = formatSnippet(row.Snippet)
+				key := fmt.Sprintf("%s:%d", table.Type, row.ID)
+				items[key], snippets[key] = row, row.Snippet
+			}
+		case SearchTypePoll:
+			var rows []SearchResultPoll
+			if err := raw(`SELECT p.id, p.title, p.description, p.is_active, p.end_date, `+snippetSQL+`
+				FROM polls p CROSS JOIN q WHERE p.id IN ?`, ids, &rows); err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				row.Snippet = formatSnippet(row.Snippet)
+				key := fmt.Sprintf("%s:%d", table.Type, row.ID)
+				items[key], snippets[key] = row, row.Snippet
+			}
+		case SearchTypeAnnouncement:
+			var rows []SearchResultAnnouncement
+			if err := raw(`SELECT an.id, an.title, an.content, an.type, an.start_date, an.end_date, `+snippetSQL+`
+				FROM announcements an CROSS JOIN q WHERE an.id IN ?`, ids, &rows); err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				row.Snippet = formatSnippet(row.Snippet)
+				key := fmt.Sprintf("%s:%d", table.Type, row.ID)
+				items[key], snippets[key] = row, row.Snippet
+			}
+		}
+	}
+	if ids := idsByType[SearchTypePerson]; len(ids) > 0 {
+		people, err := s.directory.People(ids, scope.directoryViewer())
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, person := range people {
+			key := fmt.Sprintf("%s:%d", SearchTypePerson, person.ID)
+			items[key], snippets[key] = person, personSnippet(person)
+		}
+	}
+	return items, snippets, nil
+}
+
+// personSnippet extrait d'une fiche de l'annuaire : poste et département visibles
+func personSnippet(person models.DirectoryPerson) string {
+	var parts []string
+	for _, part := range []string{person.JobTitle, person.Department} {
+		if part != "" {
+			parts = append(parts, html.EscapeString(part))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatSnippet échappe l'extrait et remplace les délimiteurs par des balises <mark>
+func formatSnippet(snippet string) string {
+	snippet = html.EscapeString(strings.Join(strings.Fields(snippet), " "))
+	snippet = strings.ReplaceAll(snippet, html.EscapeString(searchHighlightStart), "<mark>")
+	return strings.ReplaceAll(snippet, html.EscapeString(searchHighlightStop), "</mark>")
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"airboard/models"
+)
+
+// Type supplémentaire proposé par l'autocomplétion
+const SearchTypeTag = "tag"
+
+// Autocomplétion
+const (
+	SuggestDefaultLimit = 8
+	SuggestMaxLimit     = 20
+	// Budget de latence : au-delà, la requête est annulée et aucune suggestion n'est retournée
+	SuggestTimeout = 250 * time.Millisecond
+	suggestPerType = 5
+	// Similarité minimale entre un terme et sa correction proposée
+	didYouMeanMinSimilarity = 0.3
+)
+
+// SearchSuggestion suggestion de saisie (application, news, événement, tag ou personne)
+type SearchSuggestion struct {
+	Type      string  `json:"type"`
+	ID        uint    `json:"id"`
+	Label     string  `json:"label"`
+	Sublabel  string  `json:"sublabel,omitempty"` // Groupe d'applications, catégorie, lieu ou poste
+	Slug      string  `json:"slug,omitempty"`
+	URL       string  `json:"url,omitempty"`
+	Icon      string  `json:"icon,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+// SuggestResponse suggestions classées ; TimedOut indique que le budget de latence a été dépassé
+type SuggestResponse struct {
+	Query       string             `json:"query"`
+	Suggestions []SearchSuggestion `json:"suggestions"`
+	TimedOut    bool               `json:"timed_out"`
+}
+
+// suggestSource colonne proposée en autocomplétion, indexée en trigrammes sur son expression en minuscules
+type suggestSource struct {
+	Type      string
+	Table     string
+	IndexExpr string // Expression indexée, sans alias
+	Column    string // Même expression avec l'alias de la requête
+	Select    string // id, label, sublabel, slug, url, icon, avatar_url (personnes : personSuggestSelect)
+	From      string
+}
+
+var suggestSources = []suggestSource{
+	{SearchTypeApp, "applications", "lower(name)", "lower(a.name)",
+		"a.id, a.name AS label, COALESCE(grp.name, '') AS sublabel, '' AS slug, a.url, a.icon, '' AS avatar_url",
+		"applications a LEFT JOIN app_groups grp ON grp.id = a.app_group_id"},
+	{SearchTypeNews, "news", "lower(title)", "lower(n.title)",
+		"n.id, n.title AS label, COALESCE(nc.name, '') AS sublabel, n.slug, '' AS url, '' AS icon, '' AS avatar_url",
+		"news n LEFT JOIN news_categories nc ON nc.id = n.category_id"},
+	{SearchTypeEvent, "events", "lower(title)", "lower(e.title)",
+		"e.id, e.title AS label, COALESCE(e.location, '') AS sublabel, e.slug, '' AS url, '' AS icon, '' AS avatar_url",
+		"events e"},
+	{SearchTypeTag, "tags", "lower(name)", "lower(t.name)",
+		"t.id, t.name AS label, '' AS sublabel, t.slug, '' AS url, '' AS icon, '' AS avatar_url",
+		"tags t"},
+	{SearchTypePerson, "users", "lower(coalesce(first_name, '') || ' ' || coalesce(last_name, ''))",
+		"lower(coalesce(u.first_name, '') || ' ' || coalesce(u.last_name, ''))", "",
+		"users u LEFT JOIN profile_privacies pp ON pp.user_id = u.id"},
+}
+
+// SuggestTypes types acceptés par l'autocomplétion
+func SuggestTypes() []string {
+	types := make([]string, len(suggestSources))
+	for i, source := range suggestSources {
+		types[i] = source.Type
+	}
+	return types
+}
+
+// ensureTrigram installe pg_trgm et les index trigrammes de l'autocomplétion. Sans l'extension
+// (droits insuffisants), l'autocomplétion se limite aux correspondances exactes de sous-chaînes.
+func (s *SearchService) ensureTrigram() {
+	if err := s.db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("Avertissement: Extension pg_trgm indisponible, suggestions sans tolérance aux fautes: %v", err)
+		s.trigram = false
+		return
+	}
+	s.trigram = true
+	for _, source := range suggestSources {
+		statement := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_suggest_trgm ON %s USING GIN ((%s) gin_trgm_ops)",
+			source.Table, source.Table, source.IndexExpr)
+		if err := s.db.Exec(statement).Error; err != nil {
+			log.Printf("Avertissement: Impossible de créer l'index trigrammes de %s: %v", source.Table, err)
+		}
+	}
+}
+
+// trigramInstalled indique si l'extension pg_trgm est déjà installée
+func (s *SearchService) trigramInstalled() bool {
+	var installed bool
+	s.db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')").Scan(&installed)
+	return installed
+}
+
+// Suggest retourne les meilleures suggestions de saisie visibles par l'utilisateur, dans le budget de latence.
+// types vide : tous les types.
+func (s *SearchService) Suggest(ctx context.Context, q string, types []string, limit int, scope SearchScope) (*SuggestResponse, error) {
+	if limit < 1 {
+		limit = SuggestDefaultLimit
+	}
+	if limit > SuggestMaxLimit {
+		limit = SuggestMaxLimit
+	}
+	response := &SuggestResponse{Query: q, Suggestions: []SearchSuggestion{}}
+
+	suggestions, err := s.suggestions(ctx, normalizeSuggestQuery(q), types, limit, false, scope)
+	if err != nil {
+		// Budget dépassé ou saisie abandonnée par le client
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			response.TimedOut = true
+			return response, nil
+		}
+		return nil, err
+	}
+	response.Suggestions = suggestions
+	return response, nil
+}
+
+// suggestions exécute la recherche trigrammes sur les sources demandées.
+// fuzzyOnly écarte les simples sous-chaînes pour ne garder que les correspondances approchées.
+func (s *SearchService) suggestions(ctx context.Context, q string, types []string, limit int, fuzzyOnly bool, scope SearchScope) ([]SearchSuggestion, error) {
+	if q == "" {
+		return []SearchSuggestion{}, nil
+	}
+	wanted := make(map[string]bool, len(types))
+	for _, t := range types {
+		wanted[t] = true
+	}
+
+	var parts []string
+	var args []interface{}
+	now := time.Now()
+	prefix := escapeLike(q) + "%"
+	contains := "%" + escapeLike(q) + "%"
+
+	for _, source := range suggestSources {
+		if len(wanted) > 0 && !wanted[source.Type] {
+			continue
+		}
+		visible, visibleArgs, ok := suggestVisibilitySQL(source.Type, scope, now)
+		if !ok {
+			continue
+		}
+
+		selectSQL, selectArgs := source.Select, []interface{}(nil)
+		if source.Type == SearchTypePerson {
+			selectSQL, selectArgs = personSuggestSelect(scope.directoryViewer())
+		}
+
+		// Préfixe du libellé, puis similarité du mot le plus proche (pg_trgm)
+		score := fmt.Sprintf("(CASE WHEN %s LIKE ? THEN 1 ELSE 0 END)", source.Column)
+		scoreArgs := []interface{}{prefix}
+		var match string
+		var matchArgs []interface{}
+		switch {
+		case s.trigram && fuzzyOnly:
+			score += fmt.Sprintf(" + word_similarity(?, %s)", source.Column)
+			scoreArgs = append(scoreArgs, q)
+			match = fmt.Sprintf("? <%% %s", source.Column)
+			matchArgs = []interface{}{q}
+		case s.trigram:
+			score += fmt.Sprintf(" + word_similarity(?, %s)", source.Column)
+			scoreArgs = append(scoreArgs, q)
+			match = fmt.Sprintf("(%s LIKE ? OR ? <%% %s)", source.Column, source.Column)
+			matchArgs = []interface{}{contains, q}
+		case fuzzyOnly:
+			continue
+		default:
+			match = fmt.Sprintf("%s LIKE ?", source.Column)
+			matchArgs = []interface{}{contains}
+		}
+
+		parts = append(parts, fmt.Sprintf("(SELECT '%s'::text AS type, %s, %s AS score FROM %s WHERE %s%s ORDER BY score DESC, label LIMIT %d)",
+			source.Type, selectSQL, score, source.From, match, visible, suggestPerType))
+		args = append(args, selectArgs...)
+		args = append(args, scoreArgs...)
+		args = append(args, matchArgs...)
+		args = append(args, visibleArgs...)
+	}
+	if len(parts) == 0 {
+		return []SearchSuggestion{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SuggestTimeout)
+	defer cancel()
+
+	var rows []SearchSuggestion
+	args = append(args, limit)
+	if err := s.db.WithContext(ctx).Raw(strings.Join(parts, " UNION ALL ")+" ORDER BY score DESC, label LIMIT ?", args...).
+		Scan(&rows).Error; err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if rows == nil {
+		rows = []SearchSuggestion{}
+	}
+	return rows, nil
+}
+
+// suggestVisibilitySQL visibilité des sources d'autocomplétion : celle de la recherche globale pour les contenus,
+// les tags existants et les personnes présentes dans l'annuaire
+func suggestVisibilitySQL(suggestType string, scope SearchScope, now time.Time) (string, []interface{}, bool) {
+	switch suggestType {
+	case SearchTypeTag:
+		return " AND t.deleted_at IS NULL", nil, true
+	case SearchTypePerson:
+		listed, args := directoryListedSQL(scope.directoryViewer())
+		return " AND " + listed, args, true
+	}
+	return visibilitySQL(suggestType, scope, now)
+}
+
+// personSuggestSelect colonnes d'une suggestion de personne : poste et avatar selon ses réglages de confidentialité
+func personSuggestSelect(viewer DirectoryViewer) (string, []interface{}) {
+	jobVisible, jobArgs := profileFieldVisibleSQL(models.ProfileFieldJobTitle, viewer)
+	avatarVisible, avatarArgs := profileFieldVisibleSQL(models.ProfileFieldAvatar, viewer)
+	sql := "u.id, trim(coalesce(u.first_name, '') || ' ' || coalesce(u.last_name, '')) AS label, " +
+		"CASE WHEN " + jobVisible + " THEN COALESCE(u.job_title, '') ELSE '' END AS sublabel, '' AS slug, '' AS url, '' AS icon, " +
+		"CASE WHEN " + avatarVisible + " THEN COALESCE(u.avatar_url, '') ELSE '' END AS avatar_url"
+	return sql, append(jobArgs, avatarArgs...)
+}
+
+// didYouMean propose, quand une recherche ne donne rien, les contenus visibles les plus proches
+// et la requête dont chaque terme est remplacé par le mot le plus proche de leurs libellés
+func (s *SearchService) didYouMean(query string, terms []string, scope SearchScope) (string, []SearchSuggestion) {
+	if !s.trigram || len(terms) == 0 {
+		return "", nil
+	}
+	suggestions, err := s.suggestions(context.Background(), normalizeSuggestQuery(query), nil, suggestPerType, true, scope)
+	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			log.Printf("[Search] Erreur des suggestions pour %q: %v", query, err)
+		}
+		return "", nil
+	}
+	if len(suggestions) == 0 {
+		return "", nil
+	}
+
+	var words []string
+	for _, suggestion := range suggestions {
+		words = append(words, SearchTerms(suggestion.Label)...)
+	}
+
+	corrected := make([]string, len(terms))
+	changed := false
+	for i, term := range terms {
+		corrected[i] = term
+		best := didYouMeanMinSimilarity
+		for _, word := range words {
+			if word == term {
+				corrected[i] = term
+				break
+			}
+			if similarity := trigramSimilarity(term, word); similarity > best {
+				best = similarity
+				corrected[i] = word
+			}
+		}
+		if corrected[i] != term {
+			changed = true
+		}
+	}
+	if !changed {
+		return "", suggestions
+	}
+	return strings.Join(corrected, " "), suggestions
+}
+
+// normalizeSuggestQuery met la saisie en minuscules et réduit les espaces
+func normalizeSuggestQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+// escapeLike échappe les caractères spéciaux d'un motif LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// trigramSimilarity similarité de deux mots calculée comme pg_trgm : trigrammes communs / trigrammes distincts
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for trigram := range ta {
+		if tb[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// trigrams trigrammes d'un mot précédé de deux espaces et suivi d'un espace
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
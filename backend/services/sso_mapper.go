@@ -9,6 +9,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// SCIMProvider est la valeur de User.SSOProvider pour les comptes provisionnés via SCIM
+const SCIMProvider = "scim"
+
 // SSOMapper gère le mapping et la synchronisation des utilisateurs SSO
 type SSOMapper struct {
 	db     *gorm.DB
@@ -31,6 +34,13 @@ type SSOUserInfo struct {
 	LastName  string
 	Groups    []string
 	SSOID     string
+	Manager   *string // Responsable (email, identifiant ou identifiant SSO) ; nil = non synchronisé
+
+	// Paramètres propres à la source d'identité (vides = configuration SSO globale / Authentik)
+	Provider      string   // authentik, saml:<nom>, ldap...
+	AdminGroups   []string // Groupes donnant le rôle admin
+	DefaultRole   string   // Rôle par défaut
+	ManagerSource string   // Origine du rattachement hiérarchique (models.ManagerSource*)
 }
 
 // SyncUser crée ou met à jour un utilisateur à partir des informations SSO
@@ -42,6 +52,11 @@ func (m *SSOMapper) SyncUser(info *SSOUserInfo) (*models.User, error) {
 
 	isNewUser := result.Error == gorm.ErrRecordNotFound
 
+	provider := info.Provider
+	if provider == "" {
+		provider = "authentik"
+	}
+
 	if isNewUser {
 		// Créer un nouvel utilisateur
 		log.Printf("[SSO] Création d'un nouvel utilisateur: %s (%s)", info.Username, info.Email)
@@ -52,9 +67,9 @@ func (m *SSOMapper) SyncUser(info *SSOUserInfo) (*models.User, error) {
 			FirstName:   info.FirstName,
 			LastName:    info.LastName,
 			Password:    "", // Pas de mot de passe pour SSO
-			Role:        m.determineRole(info.Groups),
+			Role:        m.determineRole(info),
 			IsActive:    true,
-			SSOProvider: "authentik",
+			SSOProvider: provider,
 			SSOID:       info.SSOID,
 		}
 
@@ -62,6 +77,7 @@ func (m *SSOMapper) SyncUser(info *SSOUserInfo) (*models.User, error) {
 			log.Printf("[SSO] Erreur lors de la création de l'utilisateur: %v", err)
 			return nil, err
 		}
+		go NewWebhookService(m.db).UserCreated(&user, provider)
 	} else if result.Error != nil {
 		return nil, result.Error
 	} else {
@@ -70,9 +86,12 @@ func (m *SSOMapper) SyncUser(info *SSOUserInfo) (*models.User, error) {
 
 		user.FirstName = info.FirstName
 		user.LastName = info.LastName
-		user.Role = m.determineRole(info.Groups)
-		user.SSOProvider = "authentik"
-		user.SSOID = info.SSOID
+		user.Role = m.determineRole(info)
+		// Un compte provisionné par SCIM le reste : la connexion SSO ne le rend pas à la source JIT
+		if user.SSOProvider != SCIMProvider {
+			user.SSOProvider = provider
+			user.SSOID = info.SSOID
+		}
 		user.IsActive = true
 
 		if err := m.db.Save(&user).Error; err != nil {
@@ -87,6 +106,13 @@ func (m *SSOMapper) SyncUser(info *SSOUserInfo) (*models.User, error) {
 		return nil, err
 	}
 
+	// Rattachement hiérarchique transmis par le fournisseur d'identité
+	if info.Manager != nil {
+		if err := NewOrgService(m.db).SyncManagerReference(user.ID, *info.Manager, info.ManagerSource); err != nil {
+			log.Printf("[SSO] Responsable de %s non appliqué: %v", user.Email, err)
+		}
+	}
+
 	// Recharger l'utilisateur avec les groupes et les groupes administrés
 	if err := m.db.Preload("Groups").Preload("AdminOfGroups").First(&user, user.ID).Error; err != nil {
 		return nil, err
@@ -96,10 +122,15 @@ func (m *SSOMapper) SyncUser(info *SSOUserInfo) (*models.User, error) {
 }
 
 // determineRole détermine le rôle de l'utilisateur basé sur ses groupes Authentik
-func (m *SSOMapper) determineRole(authentikGroups []string) string {
+func (m *SSOMapper) determineRole(info *SSOUserInfo) string {
+	adminGroups := m.config.SSO.AdminGroups
+	if len(info.AdminGroups) > 0 {
+		adminGroups = info.AdminGroups
+	}
+
 	// Vérifier si l'utilisateur appartient à un groupe admin
-	for _, authentikGroup := range authentikGroups {
-		for _, adminGroup := range m.config.SSO.AdminGroups {
+	for _, authentikGroup := range info.Groups {
+		for _, adminGroup := range adminGroups {
 			if strings.EqualFold(authentikGroup, adminGroup) {
 				log.Printf("[SSO] Utilisateur attribué au rôle admin (groupe: %s)", authentikGroup)
 				return "admin"
@@ -108,9 +139,18 @@ func (m *SSOMapper) determineRole(authentikGroups []string) string {
 	}
 
 	// Par défaut, rôle user
+	if info.DefaultRole != "" {
+		return info.DefaultRole
+	}
 	return m.config.SSO.DefaultRole
 }
 
+// SyncGroups synchronise les groupes d'un utilisateur à partir des groupes d'un fournisseur
+// d'identité externe (OIDC, SAML, LDAP...), avec les mêmes règles que pour Authentik
+func (m *SSOMapper) SyncGroups(user *models.User, externalGroups []string) error {
+	return m.syncGroups(user, externalGroups)
+}
+
 // syncGroups synchronise les groupes de l'utilisateur avec ceux d'Authentik
 func (m *SSOMapper) syncGroups(user *models.User, authentikGroups []string) error {
 	var airboardGroups []models.Group
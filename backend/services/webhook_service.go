@@ -0,0 +1,443 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"airboard/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	webhookMaxAttempts      = 8                // Tentatives avant abandon d'une livraison
+	webhookBaseDelay        = 30 * time.Second // Délai avant la 2e tentative, doublé à chaque échec
+	webhookMaxDelay         = 6 * time.Hour
+	webhookDisableThreshold = 20              // Échecs consécutifs avant désactivation automatique
+	webhookClaimLease       = 2 * time.Minute // Réservation d'une livraison en cours d'envoi
+	webhookRetention        = 30 * 24 * time.Hour
+	webhookMaxResponseBody  = 4 << 10
+)
+
+var ErrWebhookDisabled = errors.New("webhook désactivé")
+
+// WebhookPayload corps JSON envoyé aux webhooks.
+// Le champ "text" permet l'utilisation directe avec les webhooks entrants Teams et Mattermost.
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Text      string      `json:"text"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService gère les webhooks sortants : file de livraisons persistée, signature et tentatives
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+// NewWebhookService crée une nouvelle instance de WebhookService
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		db:     db,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GenerateWebhookSecret génère une clé de signature
+func GenerateWebhookSecret() (string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + secret, nil
+}
+
+// SignWebhookPayload calcule la signature HMAC-SHA256 de "<timestamp>.<corps>"
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatch met en file l'événement pour chaque webhook actif abonné.
+// L'envoi est assuré par le worker ; l'appelant n'est jamais bloqué par une destination lente.
+func (s *WebhookService) Dispatch(event, text string, data interface{}) {
+	var webhooks []models.Webhook
+	if err := s.db.Where("is_active = ?", true).Find(&webhooks).Error; err != nil {
+		log.Printf("[Webhook] Erreur chargement des webhooks: %v", err)
+		return
+	}
+
+	var subscribers []models.Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event) {
+			subscribers = append(subscribers, webhook)
+		}
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	eventID, payload, err := buildWebhookPayload(event, text, data)
+	if err != nil {
+		log.Printf("[Webhook] Erreur sérialisation de l'événement %s: %v", event, err)
+		return
+	}
+
+	now := time.Now()
+	for _, webhook := range subscribers {
+		delivery := models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       payload,
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.db.Create(&delivery).Error; err != nil {
+			log.Printf("[Webhook] Erreur mise en file de %s pour le webhook %d: %v", event, webhook.ID, err)
+		}
+	}
+}
+
+// NewsPublished signale la publication d'un article
+func (s *WebhookService) NewsPublished(news *models.News) {
+	s.Dispatch(models.WebhookEventNewsPublished, "Nouvel article : "+news.Title, map[string]interface{}{
+		"id":               news.ID,
+		"slug":             news.Slug,
+		"title":            news.Title,
+		"summary":          news.Summary,
+		"author":           news.Author.Username,
+		"published_at":     news.PublishedAt,
+		"target_group_ids": groupIDs(news.TargetGroups),
+	})
+}
+
+// EventCreated signale la publication d'un événement
+func (s *WebhookService) EventCreated(event *models.Event) {
+	s.Dispatch(models.WebhookEventEventCreated, "Nouvel événement : "+event.Title, map[string]interface{}{
+		"id":               event.ID,
+		"slug":             event.Slug,
+		"title":            event.Title,
+		"start_date":       event.StartDate,
+		"end_date":         event.EndDate,
+		"is_all_day":       event.IsAllDay,
+		"location":         event.Location,
+		"author":           event.Author.Username,
+		"target_group_ids": groupIDs(event.TargetGroups),
+	})
+}
+
+// PollClosed signale la clôture d'un sondage avec ses résultats
+func (s *WebhookService) PollClosed(poll *models.Poll) {
+	var options []models.PollOption
+	s.db.Where("poll_id = ?", poll.ID).Order("\"order\"").Find(&options)
+
+	results := make([]map[string]interface{}, 0, len(options))
+	for _, option := range options {
+		var votes int64
+		s.db.Model(&models.PollVote{}).Where("poll_option_id = ?", option.ID).Count(&votes)
+		results = append(results, map[string]interface{}{
+			"id":         option.ID,
+			"text":       option.Text,
+			"vote_count": votes,
+		})
+	}
+	s.Dispatch(models.WebhookEventPollClosed, "Sondage clôturé : "+poll.Title, map[string]interface{}{
+		"id":      poll.ID,
+		"title":   poll.Title,
+		"options": results,
+	})
+}
+
+// AnnouncementCreated signale la création d'une annonce active
+func (s *WebhookService) AnnouncementCreated(announcement *models.Announcement) {
+	s.Dispatch(models.WebhookEventAnnouncementCreated, "Nouvelle annonce : "+announcement.Title, map[string]interface{}{
+		"id":         announcement.ID,
+		"title":      announcement.Title,
+		"content":    announcement.Content,
+		"type":       announcement.Type,
+		"priority":   announcement.Priority,
+		"start_date": announcement.StartDate,
+		"end_date":   announcement.EndDate,
+	})
+}
+
+// UserCreated signale la création d'un compte ; source indique l'origine (admin, register, oauth, saml, ldap, scim, sso)
+func (s *WebhookService) UserCreated(user *models.User, source string) {
+	s.Dispatch(models.WebhookEventUserCreated, "Nouvel utilisateur : "+user.Username, map[string]interface{}{
+		"id":           user.ID,
+		"username":     user.Username,
+		"email":        user.Email,
+		"first_name":   user.FirstName,
+		"last_name":    user.LastName,
+		"role":         user.Role,
+		"is_active":    user.IsActive,
+		"sso_provider": user.SSOProvider,
+		"source":       source,
+	})
+}
+
+// CommentFlagged signale un commentaire signalé lors de la modération
+func (s *WebhookService) CommentFlagged(comment *models.Comment) {
+	s.Dispatch(models.WebhookEventCommentFlagged, "Commentaire signalé", map[string]interface{}{
+		"id":           comment.ID,
+		"content":      comment.Content,
+		"author_id":    comment.UserID,
+		"entity_type":  comment.EntityType,
+		"entity_id":    comment.EntityID,
+		"is_approved":  comment.IsApproved,
+		"moderated_by": comment.ModeratedBy,
+	})
+}
+
+// Ping envoie immédiatement un événement de test et retourne la livraison journalisée
+func (s *WebhookService) Ping(webhook *models.Webhook) (*models.WebhookDelivery, error) {
+	eventID, payload, err := buildWebhookPayload(models.WebhookEventPing, "Test du webhook "+webhook.Name, map[string]interface{}{"webhook_id": webhook.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   eventID,
+		Event:     models.WebhookEventPing,
+		Payload:   payload,
+		Status:    models.WebhookDeliveryPending,
+		Attempts:  1,
+	}
+	if err := s.db.Create(delivery).Error; err != nil {
+		return nil, err
+	}
+
+	// Le ping ne participe pas à la désactivation automatique et n'est pas retenté
+	s.send(webhook, delivery)
+	if delivery.Status != models.WebhookDeliverySuccess {
+		delivery.Status = models.WebhookDeliveryFailed
+	}
+	s.db.Model(delivery).
+		Select("status", "response_status", "response_body", "error", "duration_ms", "delivered_at").
+		Updates(delivery)
+	return delivery, nil
+}
+
+// Replay remet en file le contenu d'une livraison existante (nouvelle entrée du journal)
+func (s *WebhookService) Replay(original *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	var webhook models.Webhook
+	if err := s.db.First(&webhook, original.WebhookID).Error; err != nil {
+		return nil, err
+	}
+	if !webhook.IsActive {
+		return nil, ErrWebhookDisabled
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		ReplayOfID:    &original.ID,
+	}
+	if err := s.db.Create(delivery).Error; err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ProcessDue envoie les livraisons arrivées à échéance et retourne leur nombre
+func (s *WebhookService) ProcessDue() int {
+	var due []models.WebhookDelivery
+	s.db.Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id AND webhooks.is_active = ?", true).
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+		Order("webhook_deliveries.next_attempt_at").
+		Limit(50).
+		Find(&due)
+
+	processed := 0
+	for i := range due {
+		delivery := &due[i]
+
+		// Réservation optimiste : une seule instance traite la tentative
+		lease := time.Now().Add(webhookClaimLease)
+		result := s.db.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.WebhookDeliveryPending, delivery.Attempts).
+			Updates(map[string]interface{}{"attempts": delivery.Attempts + 1, "next_attempt_at": lease})
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		delivery.Attempts++
+
+		var webhook models.Webhook
+		if err := s.db.First(&webhook, delivery.WebhookID).Error; err != nil || !webhook.IsActive {
+			continue
+		}
+
+		s.send(&webhook, delivery)
+		s.schedule(&webhook, delivery)
+		processed++
+	}
+	return processed
+}
+
+// StartWorker lance le traitement de la file de livraisons et la purge du journal
+func (s *WebhookService) StartWorker() {
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		lastPurge := time.Time{}
+
+		for range ticker.C {
+			s.ProcessDue()
+
+			if time.Since(lastPurge) > time.Hour {
+				lastPurge = time.Now()
+				result := s.db.Where("status <> ? AND created_at < ?", models.WebhookDeliveryPending, time.Now().Add(-webhookRetention)).
+					Delete(&models.WebhookDelivery{})
+				if result.Error != nil {
+					log.Printf("[Webhook] Erreur purge du journal des livraisons: %v", result.Error)
+				}
+			}
+		}
+	}()
+}
+
+// send effectue une tentative d'envoi et renseigne le résultat sur la livraison (sans la sauvegarder)
+func (s *WebhookService) send(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Airboard-Webhook/1.0")
+	req.Header.Set("X-Airboard-Event", delivery.Event)
+	req.Header.Set("X-Airboard-Delivery", delivery.EventID)
+	req.Header.Set("X-Airboard-Timestamp", timestamp)
+	req.Header.Set("X-Airboard-Signature", SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = string(respBody)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliverySuccess
+		delivery.DeliveredAt = &now
+		return
+	}
+	delivery.Error = fmt.Sprintf("réponse HTTP %d", resp.StatusCode)
+}
+
+// schedule enregistre le résultat d'une tentative, planifie la suivante et met à jour l'état du webhook
+func (s *WebhookService) schedule(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	now := time.Now()
+
+	if delivery.Status == models.WebhookDeliverySuccess {
+		delivery.NextAttemptAt = nil
+		s.db.Model(webhook).Updates(map[string]interface{}{"consecutive_failures": 0, "last_delivery_at": now})
+	} else {
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(webhookBackoff(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		}
+		s.recordFailure(webhook, now)
+	}
+
+	s.db.Model(delivery).
+		Select("status", "next_attempt_at", "response_status", "response_body", "error", "duration_ms", "delivered_at").
+		Updates(delivery)
+}
+
+// recordFailure incrémente le compteur d'échecs et désactive le webhook au-delà du seuil
+func (s *WebhookService) recordFailure(webhook *models.Webhook, now time.Time) {
+	s.db.Model(webhook).Updates(map[string]interface{}{
+		"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+		"last_delivery_at":     now,
+	})
+	s.db.Select("consecutive_failures").First(webhook, webhook.ID)
+
+	if webhook.ConsecutiveFailures >= webhookDisableThreshold && webhook.IsActive {
+		reason := fmt.Sprintf("Désactivé automatiquement après %d échecs consécutifs", webhook.ConsecutiveFailures)
+		s.db.Model(webhook).Updates(map[string]interface{}{
+			"is_active":       false,
+			"disabled_at":     now,
+			"disabled_reason": reason,
+		})
+		log.Printf("[Webhook] %s (webhook %d: %s)", reason, webhook.ID, webhook.Name)
+	}
+}
+
+// webhookBackoff retourne le délai avant la tentative suivante (exponentiel, plafonné)
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseDelay
+	for i := 1; i < attempts && delay < webhookMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxDelay {
+		delay = webhookMaxDelay
+	}
+	return delay
+}
+
+// buildWebhookPayload sérialise l'événement avec un identifiant unique
+func buildWebhookPayload(event, text string, data interface{}) (string, string, error) {
+	eventID, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := json.Marshal(WebhookPayload{
+		ID:        eventID,
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Text:      text,
+		Data:      data,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return eventID, string(body), nil
+}
+
+func groupIDs(groups []models.Group) []uint {
+	ids := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
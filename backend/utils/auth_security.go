@@ -5,27 +5,14 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
-	"sync"
-	"time"
 )
 
-// AuthSecurityManager gère la sécurité d'authentification
-type AuthSecurityManager struct {
-	failedAttempts  map[string]FailedAttempt // IP/username -> FailedAttempt
-	mu              sync.RWMutex
-	lockoutDuration time.Duration
-	maxAttempts     int
-}
-
-// FailedAttempt représente une tentative d'authentification échouée
-type FailedAttempt struct {
-	Count       int
-	FirstAt     time.Time
-	LastAt      time.Time
-	LockedUntil time.Time
-}
+// AuthSecurityManager gère la sécurité d'authentification (politique de mots de passe, secrets, hashage).
+// Le verrouillage après échecs de connexion est persisté en base par services.LoginSecurityService.
+type AuthSecurityManager struct{}
 
 // PasswordPolicy définit la politique de mots de passe
 type PasswordPolicy struct {
@@ -41,16 +28,12 @@ type PasswordPolicy struct {
 
 // NewAuthSecurityManager crée un nouveau gestionnaire de sécurité d'authentification
 func NewAuthSecurityManager() *AuthSecurityManager {
-	return &AuthSecurityManager{
-		failedAttempts:  make(map[string]FailedAttempt),
-		lockoutDuration: 30 * time.Minute, // 30 minutes de lockout
-		maxAttempts:     5,                // 5 tentatives max
-	}
+	return &AuthSecurityManager{}
 }
 
-// ValidatePassword valide un mot de passe selon la politique
-func (asm *AuthSecurityManager) ValidatePassword(password string) error {
-	policy := &PasswordPolicy{
+// DefaultPasswordPolicy retourne la politique de mots de passe par défaut
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
 		MinLength:      8,
 		MaxLength:      128,
 		RequireUpper:   true,
@@ -63,12 +46,15 @@ func (asm *AuthSecurityManager) ValidatePassword(password string) error {
 			"qwerty", "letmein", "welcome", "monkey", "dragon",
 		},
 	}
+}
 
-	return asm.validatePasswordWithPolicy(password, policy)
+// ValidatePassword valide un mot de passe selon la politique par défaut
+func (asm *AuthSecurityManager) ValidatePassword(password string) error {
+	return asm.ValidatePasswordWithPolicy(password, DefaultPasswordPolicy())
 }
 
-// validatePasswordWithPolicy valide un mot de passe selon une politique donnée
-func (asm *AuthSecurityManager) validatePasswordWithPolicy(password string, policy *PasswordPolicy) error {
+// ValidatePasswordWithPolicy valide un mot de passe selon une politique donnée
+func (asm *AuthSecurityManager) ValidatePasswordWithPolicy(password string, policy *PasswordPolicy) error {
 	if len(password) < policy.MinLength {
 		return fmt.Errorf("mot de passe trop court (minimum %d caractères)", policy.MinLength)
 	}
@@ -136,80 +122,6 @@ func (asm *AuthSecurityManager) validatePasswordWithPolicy(password string, poli
 	return nil
 }
 
-// CheckFailedLogin vérifie si une tentative de connexion a échoué
-func (asm *AuthSecurityManager) CheckFailedLogin(identifier string) (bool, time.Duration) {
-	asm.mu.RLock()
-	defer asm.mu.RUnlock()
-
-	attempt, exists := asm.failedAttempts[identifier]
-	if !exists {
-		return false, 0
-	}
-
-	// Vérifier si encore locké
-	if time.Now().Before(attempt.LockedUntil) {
-		remaining := attempt.LockedUntil.Sub(time.Now())
-		return true, remaining
-	}
-
-	// Si le lockout a expiré, nettoyer
-	if time.Now().After(attempt.LockedUntil) {
-		delete(asm.failedAttempts, identifier)
-	}
-
-	return false, 0
-}
-
-// RecordFailedLogin enregistre une tentative de connexion échouée
-func (asm *AuthSecurityManager) RecordFailedLogin(identifier string) (bool, time.Duration) {
-	asm.mu.Lock()
-	defer asm.mu.Unlock()
-
-	now := time.Now()
-	attempt, exists := asm.failedAttempts[identifier]
-
-	if !exists {
-		attempt = FailedAttempt{
-			Count:   1,
-			FirstAt: now,
-			LastAt:  now,
-		}
-	} else {
-		// Réinitialiser si le lockout a expiré
-		if now.After(attempt.LockedUntil) {
-			attempt = FailedAttempt{
-				Count:   1,
-				FirstAt: now,
-				LastAt:  now,
-			}
-		} else {
-			attempt.Count++
-			attempt.LastAt = now
-		}
-	}
-
-	// Appliquer le lockout si nécessaire
-	if attempt.Count >= asm.maxAttempts {
-		attempt.LockedUntil = now.Add(asm.lockoutDuration)
-	}
-
-	asm.failedAttempts[identifier] = attempt
-
-	if attempt.Count >= asm.maxAttempts {
-		return true, asm.lockoutDuration
-	}
-
-	return false, 0
-}
-
-// RecordSuccessfulLogin enregistre une connexion réussie et nettoie les tentatives échouées
-func (asm *AuthSecurityManager) RecordSuccessfulLogin(identifier string) {
-	asm.mu.Lock()
-	defer asm.mu.Unlock()
-
-	delete(asm.failedAttempts, identifier)
-}
-
 // GenerateSecureSecret génère un secret JWT sécurisé
 func (asm *AuthSecurityManager) GenerateSecureSecret() (string, error) {
 	bytes := make([]byte, 64) // 512 bits pour une sécurité renforcée
@@ -343,32 +255,26 @@ func (asm *AuthSecurityManager) GenerateSecureToken(length int) (string, error)
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-// CleanupFailedAttempts nettoie les tentatives échouées expirées
-func (asm *AuthSecurityManager) CleanupFailedAttempts() {
-	asm.mu.Lock()
-	defer asm.mu.Unlock()
-
-	now := time.Now()
-	for identifier, attempt := range asm.failedAttempts {
-		// Supprimer les tentatives de plus de 24h
-		if now.Sub(attempt.FirstAt) > 24*time.Hour {
-			delete(asm.failedAttempts, identifier)
-		}
+// RedactQueryParams masque la valeur des paramètres de query string sensibles (jetons de flux,
+// jetons WebSocket) d'un chemin de requête avant sa journalisation
+func RedactQueryParams(path string, names ...string) string {
+	base, query, found := strings.Cut(path, "?")
+	if !found || query == "" {
+		return path
 	}
-}
-
-// GetAuthStats retourne des statistiques d'authentification
-func (asm *AuthSecurityManager) GetAuthStats() (total, locked, attempts int) {
-	asm.mu.RLock()
-	defer asm.mu.RUnlock()
 
-	now := time.Now()
-	for _, attempt := range asm.failedAttempts {
-		total++
-		if now.Before(attempt.LockedUntil) {
-			locked++
+	parts := strings.Split(query, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				parts[i] = key + "=REDACTED"
+				break
+			}
 		}
-		attempts += attempt.Count
 	}
-	return
+	return base + "?" + strings.Join(parts, "&")
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"net/url"
@@ -21,6 +22,7 @@ type OAuthState struct {
 	Provider  string    `json:"provider"`
 	ClientID  string    `json:"client_id"`
 	Nonce     string    `json:"nonce"`
+	Verifier  string    `json:"-"` // code_verifier PKCE (RFC 7636), jamais exposé
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 	Used      bool      `json:"used"`
@@ -68,6 +70,51 @@ func (osm *OAuthStateManager) GenerateState(provider, clientID string) (string,
 	return state, nonce, nil
 }
 
+// GenerateStateWithPKCE génère un état OAuth et un code_verifier PKCE associé.
+// Retourne le state, le nonce et le code_challenge (S256) à transmettre au fournisseur.
+func (osm *OAuthStateManager) GenerateStateWithPKCE(provider, clientID string) (string, string, string, error) {
+	state, nonce, err := osm.GenerateState(provider, clientID)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	verifier, challenge, err := GeneratePKCEPair()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	osm.mu.Lock()
+	if oauthState, ok := osm.states[state]; ok {
+		oauthState.Verifier = verifier
+		osm.states[state] = oauthState
+	}
+	osm.mu.Unlock()
+
+	return state, nonce, challenge, nil
+}
+
+// GetCodeVerifier retourne le code_verifier PKCE associé à un state (vide si absent)
+func (osm *OAuthStateManager) GetCodeVerifier(state string) string {
+	osm.mu.RLock()
+	defer osm.mu.RUnlock()
+
+	return osm.states[state].Verifier
+}
+
+// GeneratePKCEPair génère un code_verifier et son code_challenge S256 (RFC 7636)
+func GeneratePKCEPair() (string, string, error) {
+	verifierBytes := make([]byte, 32)
+	if _, err := rand.Read(verifierBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate PKCE verifier: %w", err)
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(verifierBytes)
+
+	hash := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	return verifier, challenge, nil
+}
+
 // ValidateState valide un état OAuth reçu
 func (osm *OAuthStateManager) ValidateState(state, expectedProvider, expectedClientID string) (string, error) {
 	// Validation de base
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SCIMFilterColumn décrit la colonne SQL correspondant à un attribut SCIM filtrable
+type SCIMFilterColumn struct {
+	Column    string // Expression SQL (ex: "users.email")
+	IsBoolean bool   // Attribut booléen (active)
+}
+
+// ParseSCIMFilter convertit un filtre SCIM (RFC 7644 §3.4.2.2) en clause WHERE paramétrée.
+// Opérateurs supportés : eq, ne, co, sw, ew, pr, gt, ge, lt, le, and, or, not et parenthèses.
+// Les comparaisons de chaînes sont insensibles à la casse.
+func ParseSCIMFilter(filter string, columns map[string]SCIMFilterColumn) (string, []interface{}, error) {
+	tokens, err := tokenizeSCIMFilter(filter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	p := &scimFilterParser{tokens: tokens, columns: columns}
+	clause, err := p.parseOr()
+	if err != nil {
+		return "", nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return "", nil, fmt.Errorf("jeton inattendu: %s", p.tokens[p.pos].value)
+	}
+	return clause, p.args, nil
+}
+
+type scimToken struct {
+	value  string
+	quoted bool
+}
+
+func tokenizeSCIMFilter(filter string) ([]scimToken, error) {
+	var tokens []scimToken
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, scimToken{value: string(r)})
+			i++
+		case r == '"':
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("chaîne non terminée dans le filtre")
+			}
+			tokens = append(tokens, scimToken{value: sb.String(), quoted: true})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				i++
+			}
+			tokens = append(tokens, scimToken{value: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+type scimFilterParser struct {
+	tokens  []scimToken
+	pos     int
+	columns map[string]SCIMFilterColumn
+	args    []interface{}
+}
+
+func (p *scimFilterParser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].value, keyword)
+}
+
+func (p *scimFilterParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " OR " + right + ")"
+	}
+	return left, nil
+}
+
+func (p *scimFilterParser) parseAnd() (string, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return "", err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " AND " + right + ")"
+	}
+	return left, nil
+}
+
+func (p *scimFilterParser) parseFactor() (string, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		inner, err := p.parseFactor()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	}
+
+	if p.peekKeyword("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if !p.peekKeyword(")") {
+			return "", fmt.Errorf("parenthèse fermante manquante")
+		}
+		p.pos++
+		return "(" + inner + ")", nil
+	}
+
+	if p.pos+1 >= len(p.tokens) {
+		return "", fmt.Errorf("expression de filtre incomplète")
+	}
+
+	attr := p.tokens[p.pos].value
+	op := strings.ToLower(p.tokens[p.pos+1].value)
+	p.pos += 2
+
+	// Les URN d'extension préfixent l'attribut (ex: urn:...:User:userName)
+	column, ok := p.columns[strings.ToLower(attr)]
+	if !ok {
+		if idx := strings.LastIndex(attr, ":"); idx >= 0 {
+			column, ok = p.columns[strings.ToLower(attr[idx+1:])]
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("attribut non filtrable: %s", attr)
+	}
+
+	if op == "pr" {
+		if column.IsBoolean {
+			return column.Column + " IS NOT NULL", nil
+		}
+		return "(" + column.Column + " IS NOT NULL AND " + column.Column + " <> '')", nil
+	}
+
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("valeur manquante pour %s", attr)
+	}
+	valueToken := p.tokens[p.pos]
+	p.pos++
+
+	if column.IsBoolean {
+		value := strings.EqualFold(valueToken.value, "true")
+		switch op {
+		case "eq":
+			p.args = append(p.args, value)
+			return column.Column + " = ?", nil
+		case "ne":
+			p.args = append(p.args, value)
+			return column.Column + " <> ?", nil
+		default:
+			return "", fmt.Errorf("opérateur %s non supporté pour %s", op, attr)
+		}
+	}
+
+	value := strings.ToLower(valueToken.value)
+	lowered := "LOWER(" + column.Column + ")"
+	like := strings.NewReplacer("%", "\\%", "_", "\\_").Replace(value)
+
+	switch op {
+	case "eq":
+		p.args = append(p.args, value)
+		return lowered + " = ?", nil
+	case "ne":
+		p.args = append(p.args, value)
+		return lowered + " <> ?", nil
+	case "co":
+		p.args = append(p.args, "%"+like+"%")
+		return lowered + " LIKE ?", nil
+	case "sw":
+		p.args = append(p.args, like+"%")
+		return lowered + " LIKE ?", nil
+	case "ew":
+		p.args = append(p.args, "%"+like)
+		return lowered + " LIKE ?", nil
+	case "gt", "ge", "lt", "le":
+		sqlOps := map[string]string{"gt": ">", "ge": ">=", "lt": "<", "le": "<="}
+		p.args = append(p.args, value)
+		return lowered + " " + sqlOps[op] + " ?", nil
+	default:
+		return "", fmt.Errorf("opérateur inconnu: %s", op)
+	}
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// TiptapNode nœud d'un document Tiptap (format JSON de ProseMirror)
+type TiptapNode struct {
+	Type    string                 `json:"type"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Content []TiptapNode           `json:"content,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Marks   []TiptapMark           `json:"marks,omitempty"`
+}
+
+// TiptapMark mise en forme appliquée à un nœud texte (gras, lien, ...)
+type TiptapMark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// ParseTiptap analyse un document Tiptap. Un contenu qui n'est pas du JSON Tiptap (texte brut des anciens
+// contenus) est converti en un document d'un paragraphe par ligne.
+func ParseTiptap(content string) TiptapNode {
+	var doc TiptapNode
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Unmarshal([]byte(trimmed), &doc) == nil && doc.Type != "" {
+		return doc
+	}
+
+	doc = TiptapNode{Type: "doc"}
+	for _, line := range strings.Split(trimmed, "\n") {
+		paragraph := TiptapNode{Type: "paragraph"}
+		if line = strings.TrimSpace(line); line != "" {
+			paragraph.Content = []TiptapNode{{Type: "text", Text: line}}
+		}
+		if trimmed != "" {
+			doc.Content = append(doc.Content, paragraph)
+		}
+	}
+	return doc
+}
+
+// IsBlock indique si le nœud contient des blocs (liste, citation, tableau...) plutôt que du texte
+func (n *TiptapNode) IsBlock() bool {
+	for _, child := range n.Content {
+		if child.Type != "text" && child.Type != "hardBreak" && child.Type != "mention" && child.Type != "emoji" {
+			return true
+		}
+	}
+	return false
+}
+
+// PlainText retourne le texte du nœud et de ses descendants, blocs séparés par un saut de ligne
+func (n *TiptapNode) PlainText() string {
+	var b strings.Builder
+	n.writeText(&b)
+	return strings.TrimSpace(b.String())
+}
+
+func (n *TiptapNode) writeText(b *strings.Builder) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+		return
+	case "hardBreak":
+		b.WriteString("\n")
+		return
+	}
+	for i := range n.Content {
+		n.Content[i].writeText(b)
+	}
+	if n.Type != "doc" && !n.IsBlock() {
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Opérations de différence
+const (
+	DiffEqual   = "equal"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// maxDiffCells limite la table LCS (au-delà, les séquences sont considérées entièrement remplacées)
+const maxDiffCells = 4_000_000
+
+// TiptapDiffOp différence entre deux blocs d'un document Tiptap
+type TiptapDiffOp struct {
+	Op           string         `json:"op"` // equal, added, removed, changed
+	NodeType     string         `json:"node_type"`
+	OldIndex     *int           `json:"old_index,omitempty"`
+	NewIndex     *int           `json:"new_index,omitempty"`
+	Old          *TiptapNode    `json:"old,omitempty"`
+	New          *TiptapNode    `json:"new,omitempty"`
+	AttrsChanged bool           `json:"attrs_changed,omitempty"` // Attributs du bloc (niveau de titre, alignement...) ou mises en forme modifiés
+	Text         []TextDiffOp   `json:"text,omitempty"`          // Différence mot à mot des blocs de texte modifiés
+	Children     []TiptapDiffOp `json:"children,omitempty"`      // Différence des blocs imbriqués (listes, citations, tableaux)
+}
+
+// TextDiffOp segment de texte ajouté, supprimé ou inchangé
+type TextDiffOp struct {
+	Op   string `json:"op"` // equal, added, removed
+	Text string `json:"text"`
+}
+
+// DiffTiptap compare deux documents Tiptap bloc par bloc. Les blocs supprimés puis ajoutés au même endroit
+// avec le même type sont rapprochés en une modification (différence mot à mot ou des blocs imbriqués).
+func DiffTiptap(oldContent, newContent string) []TiptapDiffOp {
+	oldDoc := ParseTiptap(oldContent)
+	newDoc := ParseTiptap(newContent)
+	return diffNodes(oldDoc.Content, newDoc.Content)
+}
+
+// CountDiffOps compte les blocs ajoutés, supprimés et modifiés (hors blocs imbriqués)
+func CountDiffOps(ops []TiptapDiffOp) (added, removed, changed int) {
+	for _, op := range ops {
+		switch op.Op {
+		case DiffAdded:
+			added++
+		case DiffRemoved:
+			removed++
+		case DiffChanged:
+			changed++
+		}
+	}
+	return added, removed, changed
+}
+
+func diffNodes(oldNodes, newNodes []TiptapNode) []TiptapDiffOp {
+	oldKeys := make([]string, len(oldNodes))
+	for i := range oldNodes {
+		oldKeys[i] = nodeKey(&oldNodes[i])
+	}
+	newKeys := make([]string, len(newNodes))
+	for i := range newNodes {
+		newKeys[i] = nodeKey(&newNodes[i])
+	}
+
+	var ops []TiptapDiffOp
+	var removed, added []int
+	flush := func() {
+		// Rapprochement des blocs supprimés/ajoutés de même type en modifications
+		i, j := 0, 0
+		for i < len(removed) || j < len(added) {
+			switch {
+			case i < len(removed) && j < len(added) && oldNodes[removed[i]].Type == newNodes[added[j]].Type:
+				ops = append(ops, changedOp(&oldNodes[removed[i]], &newNodes[added[j]], removed[i], added[j]))
+				i++
+				j++
+			case i < len(removed):
+				oi := removed[i]
+				ops = append(ops, TiptapDiffOp{Op: DiffRemoved, NodeType: oldNodes[oi].Type, OldIndex: intPtr(oi), Old: &oldNodes[oi]})
+				i++
+			default:
+				nj := added[j]
+				ops = append(ops, TiptapDiffOp{Op: DiffAdded, NodeType: newNodes[nj].Type, NewIndex: intPtr(nj), New: &newNodes[nj]})
+				j++
+			}
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	for _, step := range lcs(oldKeys, newKeys) {
+		switch step.op {
+		case DiffRemoved:
+			removed = append(removed, step.oldIndex)
+		case DiffAdded:
+			added = append(added, step.newIndex)
+		default:
+			flush()
+			ops = append(ops, TiptapDiffOp{
+				Op:       DiffEqual,
+				NodeType: newNodes[step.newIndex].Type,
+				OldIndex: intPtr(step.oldIndex),
+				NewIndex: intPtr(step.newIndex),
+				New:      &newNodes[step.newIndex],
+			})
+		}
+	}
+	flush()
+	return ops
+}
+
+// changedOp décrit la modification d'un bloc
+func changedOp(oldNode, newNode *TiptapNode, oldIndex, newIndex int) TiptapDiffOp {
+	op := TiptapDiffOp{
+		Op:           DiffChanged,
+		NodeType:     newNode.Type,
+		OldIndex:     intPtr(oldIndex),
+		NewIndex:     intPtr(newIndex),
+		Old:          oldNode,
+		New:          newNode,
+		AttrsChanged: !reflect.DeepEqual(oldNode.Attrs, newNode.Attrs) || !sameMarks(oldNode, newNode),
+	}
+	if oldNode.IsBlock() || newNode.IsBlock() {
+		op.Children = diffNodes(oldNode.Content, newNode.Content)
+	} else {
+		op.Text = DiffText(oldNode.PlainText(), newNode.PlainText())
+	}
+	return op
+}
+
+// DiffText compare deux textes mot à mot
+func DiffText(oldText, newText string) []TextDiffOp {
+	oldWords := splitWords(oldText)
+	newWords := splitWords(newText)
+
+	var ops []TextDiffOp
+	appendOp := func(op, text string) {
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += text
+			return
+		}
+		ops = append(ops, TextDiffOp{Op: op, Text: text})
+	}
+	for _, step := range lcs(oldWords, newWords) {
+		switch step.op {
+		case DiffRemoved:
+			appendOp(DiffRemoved, oldWords[step.oldIndex])
+		case DiffAdded:
+			appendOp(DiffAdded, newWords[step.newIndex])
+		default:
+			appendOp(DiffEqual, newWords[step.newIndex])
+		}
+	}
+	return ops
+}
+
+// splitWords découpe un texte en mots en conservant les espaces et la ponctuation comme éléments distincts
+func splitWords(text string) []string {
+	var words []string
+	var current strings.Builder
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			current.WriteRune(r)
+			continue
+		}
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+		words = append(words, string(r))
+	}
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+	return words
+}
+
+type lcsStep struct {
+	op       string
+	oldIndex int
+	newIndex int
+}
+
+// lcs calcule le script d'édition (plus longue sous-séquence commune) entre deux séquences
+func lcs(a, b []string) []lcsStep {
+	// Préfixe et suffixe communs traités sans table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	steps := make([]lcsStep, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		steps = append(steps, lcsStep{op: DiffEqual, oldIndex: i, newIndex: i})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	n, m := len(midA), len(midB)
+	if n*m > maxDiffCells {
+		for i := 0; i < n; i++ {
+			steps = append(steps, lcsStep{op: DiffRemoved, oldIndex: prefix + i})
+		}
+		for j := 0; j < m; j++ {
+			steps = append(steps, lcsStep{op: DiffAdded, newIndex: prefix + j})
+		}
+	} else {
+		// table[i][j] = longueur de la LCS de midA[i:] et midB[j:]
+		table := make([][]int, n+1)
+		for i := range table {
+			table[i] = make([]int, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					table[i][j] = table[i+1][j+1] + 1
+				} else if table[i+1][j] >= table[i][j+1] {
+					table[i][j] = table[i+1][j]
+				} else {
+					table[i][j] = table[i][j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && midA[i] == midB[j]:
+				steps = append(steps, lcsStep{op: DiffEqual, oldIndex: prefix + i, newIndex: prefix + j})
+				i++
+				j++
+			case j < m && (i == n || table[i][j+1] > table[i+1][j]):
+				steps = append(steps, lcsStep{op: DiffAdded, newIndex: prefix + j})
+				j++
+			default:
+				steps = append(steps, lcsStep{op: DiffRemoved, oldIndex: prefix + i})
+				i++
+			}
+		}
+	}
+
+	for k := suffix; k > 0; k-- {
+		steps = append(steps, lcsStep{op: DiffEqual, oldIndex: len(a) - k, newIndex: len(b) - k})
+	}
+	return steps
+}
+
+// nodeKey représentation canonique d'un nœud (json.Marshal trie les clés des attributs)
+func nodeKey(node *TiptapNode) string {
+	encoded, _ := json.Marshal(node)
+	return string(encoded)
+}
+
+// sameMarks indique si les mises en forme des textes de deux blocs sont identiques
+func sameMarks(a, b *TiptapNode) bool {
+	return reflect.DeepEqual(collectMarks(a, nil), collectMarks(b, nil))
+}
+
+func collectMarks(node *TiptapNode, marks []string) []string {
+	for _, mark := range node.Marks {
+		encoded, _ := json.Marshal(mark)
+		marks = append(marks, string(encoded))
+	}
+	for i := range node.Content {
+		marks = collectMarks(&node.Content[i], marks)
+	}
+	return marks
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,393 @@
+package utils
+
+import (
+	"html"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Vitesse de lecture utilisée pour estimer le temps de lecture
+const (
+	readingWordsPerMinute = 200
+	readingSecondsPerImg  = 12
+	maxTiptapRenderDepth  = 64 // Les nœuds plus profonds sont ignorés (document malveillant)
+)
+
+// Schémas autorisés pour les liens et les images ; les URL relatives sont toujours acceptées
+var (
+	tiptapLinkSchemes   = map[string]bool{"http": true, "https": true, "mailto": true, "tel": true}
+	tiptapImageSchemes  = map[string]bool{"http": true, "https": true}
+	tiptapAlignments    = map[string]bool{"left": true, "center": true, "right": true, "justify": true}
+	codeLanguagePattern = regexp.MustCompile(`^[A-Za-z0-9_+#-]{1,32}$`)
+)
+
+// Nœuds rendus par une simple balise ; les nœuds absents de la liste (et non traités dans renderNode)
+// sont remplacés par leur contenu
+var tiptapBlockTags = map[string]string{
+	"blockquote":  "blockquote",
+	"bulletList":  "ul",
+	"listItem":    "li",
+	"taskList":    "ul",
+	"taskItem":    "li",
+	"tableRow":    "tr",
+	"tableCell":   "td",
+	"tableHeader": "th",
+}
+
+// Marques autorisées et balise correspondante (le lien est traité à part)
+var tiptapMarkTags = map[string]string{
+	"bold":        "strong",
+	"italic":      "em",
+	"strike":      "s",
+	"underline":   "u",
+	"code":        "code",
+	"highlight":   "mark",
+	"subscript":   "sub",
+	"superscript": "sup",
+}
+
+// TiptapHTMLOptions options de rendu HTML
+type TiptapHTMLOptions struct {
+	BaseURL string // Préfixe des liens et images relatifs (emails, flux) ; vide : laissés relatifs
+}
+
+// TiptapToHTML rend un document Tiptap en HTML assaini : seuls les nœuds, marques, attributs et schémas
+// d'URL de la liste blanche sont conservés, tout le texte est échappé
+func TiptapToHTML(content string, opts TiptapHTMLOptions) string {
+	doc := ParseTiptap(content)
+	r := tiptapRenderer{opts: opts}
+	if opts.BaseURL != "" {
+		r.base, _ = url.Parse(strings.TrimRight(opts.BaseURL, "/") + "/")
+	}
+	var b strings.Builder
+	r.renderNode(&b, &doc, 0)
+	return b.String()
+}
+
+// TiptapToText rend un document Tiptap en texte brut (emails texte, indexation de la recherche)
+func TiptapToText(content string) string {
+	doc := ParseTiptap(content)
+	var b strings.Builder
+	writePlain(&b, &doc, "", 0)
+	return collapseBlankLines(b.String())
+}
+
+// TiptapExcerpt retourne le début du texte d'un document, coupé sur un mot et suivi de "…" s'il est tronqué
+func TiptapExcerpt(content string, maxRunes int) string {
+	text := strings.Join(strings.Fields(TiptapToText(content)), " ")
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	runes := []rune(text)[:maxRunes]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > maxRunes/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:.-") + "…"
+}
+
+// TiptapReadingTime estime le temps de lecture en minutes (0 pour un document vide)
+func TiptapReadingTime(content string) int {
+	doc := ParseTiptap(content)
+	words := len(strings.Fields(TiptapToText(content)))
+	images := countNodes(&doc, "image", 0)
+	if words == 0 && images == 0 {
+		return 0
+	}
+	seconds := float64(words)*60/readingWordsPerMinute + float64(images*readingSecondsPerImg)
+	return int(math.Max(1, math.Ceil(seconds/60)))
+}
+
+type tiptapRenderer struct {
+	opts TiptapHTMLOptions
+	base *url.URL
+}
+
+func (r *tiptapRenderer) renderNode(b *strings.Builder, n *TiptapNode, depth int) {
+	if depth > maxTiptapRenderDepth {
+		return
+	}
+
+	switch n.Type {
+	case "text":
+		r.renderText(b, n)
+		return
+	case "hardBreak":
+		b.WriteString("<br>")
+		return
+	case "horizontalRule":
+		b.WriteString("<hr>")
+		return
+	case "image":
+		r.renderImage(b, n)
+		return
+	case "paragraph":
+		b.WriteString("<p" + alignAttr(n) + ">")
+		r.renderChildren(b, n, depth)
+		b.WriteString("</p>")
+		return
+	case "heading":
+		level := attrInt(n.Attrs, "level", 1)
+		if level < 1 || level > 6 {
+			level = 2
+		}
+		tag := "h" + strconv.Itoa(level)
+		b.WriteString("<" + tag + alignAttr(n) + ">")
+		r.renderChildren(b, n, depth)
+		b.WriteString("</" + tag + ">")
+		return
+	case "orderedList":
+		b.WriteString("<ol")
+		if start := attrInt(n.Attrs, "start", 1); start != 1 {
+			b.WriteString(` start="` + strconv.Itoa(start) + `"`)
+		}
+		b.WriteString(">")
+		r.renderChildren(b, n, depth)
+		b.WriteString("</ol>")
+		return
+	case "codeBlock":
+		b.WriteString("<pre><code")
+		if lang, _ := n.Attrs["language"].(string); codeLanguagePattern.MatchString(lang) {
+			b.WriteString(` class="language-` + lang + `"`)
+		}
+		b.WriteString(">")
+		b.WriteString(html.EscapeString(n.PlainText()))
+		b.WriteString("</code></pre>")
+		return
+	case "table":
+		b.WriteString("<table><tbody>")
+		r.renderChildren(b, n, depth)
+		b.WriteString("</tbody></table>")
+		return
+	case "tableCell", "tableHeader":
+		tag := tiptapBlockTags[n.Type]
+		b.WriteString("<" + tag)
+		for _, attr := range []string{"colspan", "rowspan"} {
+			if span := attrInt(n.Attrs, attr, 1); span > 1 && span <= 100 {
+				b.WriteString(" " + attr + `="` + strconv.Itoa(span) + `"`)
+			}
+		}
+		b.WriteString(">")
+		r.renderChildren(b, n, depth)
+		b.WriteString("</" + tag + ">")
+		return
+	}
+
+	if tag, ok := tiptapBlockTags[n.Type]; ok {
+		b.WriteString("<" + tag + ">")
+		r.renderChildren(b, n, depth)
+		b.WriteString("</" + tag + ">")
+		return
+	}
+
+	// doc et nœuds inconnus : seul le contenu est conservé
+	r.renderChildren(b, n, depth)
+}
+
+func (r *tiptapRenderer) renderChildren(b *strings.Builder, n *TiptapNode, depth int) {
+	for i := range n.Content {
+		r.renderNode(b, &n.Content[i], depth+1)
+	}
+}
+
+func (r *tiptapRenderer) renderText(b *strings.Builder, n *TiptapNode) {
+	var closing []string
+	for _, mark := range n.Marks {
+		if mark.Type == "link" {
+			href, _ := mark.Attrs["href"].(string)
+			safe, ok := r.safeURL(href, tiptapLinkSchemes)
+			if !ok {
+				continue
+			}
+			b.WriteString(`<a href="` + html.EscapeString(safe) + `" rel="noopener noreferrer nofollow" target="_blank">`)
+			closing = append(closing, "</a>")
+			continue
+		}
+		if tag, ok := tiptapMarkTags[mark.Type]; ok {
+			b.WriteString("<" + tag + ">")
+			closing = append(closing, "</"+tag+">")
+		}
+	}
+	b.WriteString(html.EscapeString(n.Text))
+	for i := len(closing) - 1; i >= 0; i-- {
+		b.WriteString(closing[i])
+	}
+}
+
+func (r *tiptapRenderer) renderImage(b *strings.Builder, n *TiptapNode) {
+	src, _ := n.Attrs["src"].(string)
+	safe, ok := r.safeURL(src, tiptapImageSchemes)
+	if !ok {
+		return
+	}
+	b.WriteString(`<img src="` + html.EscapeString(safe) + `"`)
+	for _, attr := range []string{"alt", "title"} {
+		if value, _ := n.Attrs[attr].(string); value != "" {
+			b.WriteString(" " + attr + `="` + html.EscapeString(value) + `"`)
+		}
+	}
+	b.WriteString(">")
+}
+
+// safeURL valide une URL de lien ou d'image et résout les URL relatives sur BaseURL
+func (r *tiptapRenderer) safeURL(raw string, schemes map[string]bool) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	u, err := url.Parse(raw) // Rejette les caractères de contrôle (ex. "java\tscript:")
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme != "" {
+		if !schemes[strings.ToLower(u.Scheme)] {
+			return "", false
+		}
+		return u.String(), true
+	}
+	if u.Host != "" {
+		// URL sans schéma ("//exemple.fr") : forcée en https
+		u.Scheme = "https"
+		return u.String(), true
+	}
+	if r.base != nil && !strings.HasPrefix(raw, "#") {
+		return r.base.ResolveReference(u).String(), true
+	}
+	return u.String(), true
+}
+
+func alignAttr(n *TiptapNode) string {
+	align, _ := n.Attrs["textAlign"].(string)
+	if align == "" || align == "left" || !tiptapAlignments[align] {
+		return ""
+	}
+	return ` style="text-align: ` + align + `"`
+}
+
+// attrInt lit un attribut numérique (les nombres JSON sont décodés en float64)
+func attrInt(attrs map[string]interface{}, key string, fallback int) int {
+	switch v := attrs[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return fallback
+}
+
+// writePlain écrit le texte d'un nœud : un bloc par ligne, puces pour les listes, cellules séparées par des tabulations
+func writePlain(b *strings.Builder, n *TiptapNode, prefix string, depth int) {
+	if depth > maxTiptapRenderDepth {
+		return
+	}
+
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+		return
+	case "hardBreak":
+		b.WriteString("\n" + prefix)
+		return
+	case "image":
+		if alt, _ := n.Attrs["alt"].(string); alt != "" {
+			b.WriteString(prefix + alt + "\n")
+		}
+		return
+	case "codeBlock":
+		b.WriteString(prefix + n.PlainText() + "\n\n")
+		return
+	case "bulletList", "taskList", "orderedList":
+		start := attrInt(n.Attrs, "start", 1)
+		for i := range n.Content {
+			marker := "- "
+			if n.Type == "orderedList" {
+				marker = strconv.Itoa(start+i) + ". "
+			}
+			b.WriteString(prefix + marker)
+			writeListItem(b, &n.Content[i], prefix+strings.Repeat(" ", len(marker)), depth+1)
+		}
+		b.WriteString("\n")
+		return
+	case "tableRow":
+		for i := range n.Content {
+			if i > 0 {
+				b.WriteString("\t")
+			}
+			b.WriteString(strings.Join(strings.Fields(n.Content[i].PlainText()), " "))
+		}
+		b.WriteString("\n")
+		return
+	case "blockquote":
+		prefix += "> "
+	}
+
+	if n.Type != "doc" && !n.IsBlock() {
+		b.WriteString(prefix)
+		for i := range n.Content {
+			writePlain(b, &n.Content[i], prefix, depth+1)
+		}
+		b.WriteString("\n\n")
+		return
+	}
+	for i := range n.Content {
+		writePlain(b, &n.Content[i], prefix, depth+1)
+	}
+}
+
+// writeListItem écrit un élément de liste : le premier paragraphe suit la puce, les blocs suivants sont indentés
+func writeListItem(b *strings.Builder, item *TiptapNode, indent string, depth int) {
+	for i := range item.Content {
+		child := &item.Content[i]
+		if i == 0 && !child.IsBlock() && child.Type != "bulletList" && child.Type != "orderedList" && child.Type != "taskList" {
+			for j := range child.Content {
+				writePlain(b, &child.Content[j], indent, depth+1)
+			}
+			b.WriteString("\n")
+			continue
+		}
+		writePlain(b, child, indent, depth+1)
+	}
+	if len(item.Content) == 0 {
+		b.WriteString("\n")
+	}
+}
+
+func countNodes(n *TiptapNode, nodeType string, depth int) int {
+	if depth > maxTiptapRenderDepth {
+		return 0
+	}
+	count := 0
+	if n.Type == nodeType {
+		count++
+	}
+	for i := range n.Content {
+		count += countNodes(&n.Content[i], nodeType, depth+1)
+	}
+	return count
+}
+
+// collapseBlankLines supprime les espaces de fin de ligne et limite les lignes vides consécutives à une
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" || strings.TrimSpace(line) == ">" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
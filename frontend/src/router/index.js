@@ -18,6 +18,7 @@ const NewsManagement = () => import('@/views/admin/NewsManagement.vue')
 const NewsEditor = () => import('@/views/admin/NewsEditor.vue')
 const PollsManagement = () => import('@/views/admin/PollsManagement.vue')
 const MediaManagement = () => import('@/views/admin/MediaManagement.vue')
+const AcknowledgementReport = () => import('@/views/admin/AcknowledgementReport.vue')
 
 // Group Admin views
 const GroupAdminDashboard = () => import('@/views/group-admin/GroupAdminDashboard.vue')
@@ -52,6 +53,9 @@ const CommentModeration = () => import('@/views/admin/CommentModeration.vue')
 
 // Search
 const SearchPage = () => import('@/views/SearchPage.vue')
+const DirectoryPage = () => import('@/views/DirectoryPage.vue')
+const PersonProfile = () => import('@/views/PersonProfile.vue')
+const OrgChart = () => import('@/views/OrgChart.vue')
 
 // Error views
 const NotFound = () => import('@/views/errors/NotFound.vue')
@@ -89,6 +93,33 @@ const routes = [
       title: 'Recherche'
     }
   },
+  {
+    path: '/directory',
+    name: 'Directory',
+    component: DirectoryPage,
+    meta: {
+      requiresAuth: true,
+      title: 'Annuaire'
+    }
+  },
+  {
+    path: '/directory/:id',
+    name: 'PersonProfile',
+    component: PersonProfile,
+    meta: {
+      requiresAuth: true,
+      title: 'Profil'
+    }
+  },
+  {
+    path: '/org-chart',
+    name: 'OrgChart',
+    component: OrgChart,
+    meta: {
+      requiresAuth: true,
+      title: 'Organigramme'
+    }
+  },
   {
     path: '/news',
     name: 'NewsCenter',
@@ -290,6 +321,16 @@ const routes = [
       title: 'Announcements'
     }
   },
+  {
+    path: '/admin/acknowledgements/:type(news|announcements)/:id',
+    name: 'AdminAcknowledgementReport',
+    component: AcknowledgementReport,
+    meta: {
+      requiresAuth: true,
+      requiresAdmin: true,
+      title: 'Acknowledgement Report'
+    }
+  },
   {
     path: '/admin/news',
     name: 'AdminNews',
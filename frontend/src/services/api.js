@@ -24,6 +24,45 @@ const processQueue = (error, token = null) => {
   failedQueue = []
 }
 
+// Token CSRF signé par le serveur : mis en cache jusqu'à son expiration, plusieurs onglets
+// peuvent chacun détenir leur propre token valide
+let csrfToken = null
+let csrfExpiresAt = 0
+let csrfPromise = null
+
+// Routes modifiantes hors session (pas de contrôle CSRF côté serveur)
+const CSRF_EXEMPT_URLS = ['/auth/login', '/auth/register', '/auth/refresh', '/auth/csrf-token', '/auth/saml/exchange', '/auth/oauth/']
+
+const requiresCsrf = (config) => {
+  const method = (config.method || 'get').toLowerCase()
+  if (!['post', 'put', 'patch', 'delete'].includes(method)) return false
+  return !CSRF_EXEMPT_URLS.some(url => config.url?.startsWith(url))
+}
+
+const getCsrfToken = async () => {
+  // Renouveler une minute avant l'expiration
+  if (csrfToken && Date.now() < csrfExpiresAt - 60000) {
+    return csrfToken
+  }
+  if (!csrfPromise) {
+    csrfPromise = api.post('/auth/csrf-token')
+      .then(response => {
+        csrfToken = response.data.csrf_token
+        csrfExpiresAt = new Date(response.data.expires_at).getTime()
+        return csrfToken
+      })
+      .finally(() => {
+        csrfPromise = null
+      })
+  }
+  return csrfPromise
+}
+
+export const resetCsrfToken = () => {
+  csrfToken = null
+  csrfExpiresAt = 0
+}
+
 // Logs pour le développement
 if (import.meta.env.DEV) {
   api.interceptors.request.use(
@@ -56,11 +95,21 @@ if (import.meta.env.DEV) {
 export function setupInterceptors(router, logoutCallback) {
   // Intercepteur de requête pour ajouter le token
   api.interceptors.request.use(
-    (config) => {
+    async (config) => {
       const token = localStorage.getItem('airboard_token')
       if (token) {
         config.headers.Authorization = `Bearer ${token}`
         console.log('🔑 Ajout du token Authorization:', config.url)
+
+        // Token CSRF pour les requêtes modifiantes
+        if (requiresCsrf(config)) {
+          try {
+            config.headers['X-CSRF-Token'] = await getCsrfToken()
+          } catch (csrfError) {
+            // La requête part sans token : le serveur répondra 401 (session expirée) ou 403
+            console.error('❌ Impossible d\'obtenir un token CSRF:', csrfError.response?.data || csrfError.message)
+          }
+        }
       } else {
         console.log('⚠️ Aucun token trouvé pour:', config.url)
       }
@@ -83,6 +132,13 @@ export function setupInterceptors(router, logoutCallback) {
 
       console.log('❌ Erreur API:', error.response?.status, originalRequest?.url, error.response?.data)
 
+      // Token CSRF expiré ou émis pour un autre utilisateur ou une autre session : en obtenir un nouveau et rejouer une fois
+      if (error.response?.status === 403 && error.response?.data?.error === 'csrf_invalid' && !originalRequest._csrfRetry) {
+        originalRequest._csrfRetry = true
+        resetCsrfToken()
+        return api(originalRequest)
+      }
+
       // Si erreur 401 et pas déjà une tentative de refresh
       if (error.response?.status === 401 && !originalRequest._retry) {
         // Ignorer les requêtes de refresh qui échouent
@@ -189,6 +245,32 @@ export const authService = {
     return response.data
   },
 
+  // Données personnelles (RGPD)
+  async getPrivacyRequests() {
+    const response = await api.get('/privacy/requests')
+    return response.data
+  },
+
+  async requestDataExport() {
+    const response = await api.post('/privacy/export')
+    return response.data
+  },
+
+  async requestErasure(password, reason) {
+    const response = await api.post('/privacy/erasure', { password, reason })
+    return response.data
+  },
+
+  async downloadDataExport(id) {
+    const response = await api.get(`/privacy/requests/${id}/download`, { responseType: 'blob' })
+    return response.data
+  },
+
+  async stopImpersonation() {
+    const response = await api.post('/auth/impersonation/stop')
+    return response.data
+  },
+
   async changePassword(oldPassword, newPassword) {
     const response = await api.post('/auth/change-password', {
       old_password: oldPassword,
@@ -232,6 +314,62 @@ export const authService = {
   async ssoAutoLogin() {
     const response = await api.get('/auth/sso/auto-login')
     return response.data
+  },
+
+  // RSS/Atom feed token
+  async getFeedToken() {
+    const response = await api.get('/auth/feed-token')
+    return response.data
+  },
+
+  async regenerateFeedToken() {
+    const response = await api.post('/auth/feed-token')
+    return response.data
+  },
+
+  async revokeFeedToken() {
+    const response = await api.delete('/auth/feed-token')
+    return response.data
+  },
+
+  // Confidentialité du profil dans l'annuaire
+  async getProfilePrivacy() {
+    const response = await api.get('/auth/profile/privacy')
+    return response.data
+  },
+
+  async updateProfilePrivacy(settings) {
+    const response = await api.put('/auth/profile/privacy', settings)
+    return response.data
+  }
+}
+
+// Directory Service
+export const directoryService = {
+  async listPeople(params = {}) {
+    const response = await api.get('/directory/people', { params })
+    return response.data
+  },
+
+  async getPerson(id) {
+    const response = await api.get(`/directory/people/${id}`)
+    return response.data
+  },
+
+  // Organigramme
+  async getOrgChart(params = {}) {
+    const response = await api.get('/directory/org-chart', { params })
+    return response.data
+  },
+
+  async getChainOfCommand(id) {
+    const response = await api.get(`/directory/people/${id}/chain`)
+    return response.data.chain || []
+  },
+
+  async getDirectReports(id) {
+    const response = await api.get(`/directory/people/${id}/reports`)
+    return response.data.reports || []
   }
 }
 
@@ -316,6 +454,71 @@ export const adminService = {
     return response.data
   },
 
+  // Organigramme
+  async setUserManager(id, managerId) {
+    const response = await api.put(`/admin/users/${id}/manager`, { manager_id: managerId })
+    return response.data
+  },
+
+  async exportManagers() {
+    const response = await api.get('/admin/org/managers/export', { responseType: 'blob' })
+    return response.data
+  },
+
+  async importManagers(file, dryRun = false) {
+    const formData = new FormData()
+    formData.append('file', file)
+    const response = await api.post('/admin/org/managers/import', formData, {
+      params: { dry_run: dryRun },
+      headers: {
+        'Content-Type': 'multipart/form-data'
+      }
+    })
+    return response.data
+  },
+
+  // Usurpation d'identité (support)
+  async startImpersonation(id, data) {
+    const response = await api.post(`/admin/users/${id}/impersonate`, data)
+    return response.data
+  },
+
+  async getImpersonations(params = {}) {
+    const response = await api.get('/admin/impersonations', { params })
+    return response.data
+  },
+
+  async endImpersonation(id) {
+    const response = await api.delete(`/admin/impersonations/${id}`)
+    return response.data
+  },
+
+  // Demandes RGPD
+  async getPrivacyRequests(params = {}) {
+    const response = await api.get('/admin/privacy/requests', { params })
+    return response.data
+  },
+
+  async approvePrivacyRequest(id, comment = '') {
+    const response = await api.post(`/admin/privacy/requests/${id}/approve`, { comment })
+    return response.data
+  },
+
+  async rejectPrivacyRequest(id, comment = '') {
+    const response = await api.post(`/admin/privacy/requests/${id}/reject`, { comment })
+    return response.data
+  },
+
+  async exportUserData(id) {
+    const response = await api.post(`/admin/users/${id}/privacy/export`)
+    return response.data
+  },
+
+  async eraseUserData(id, comment = '') {
+    const response = await api.post(`/admin/users/${id}/privacy/erasure`, { comment })
+    return response.data
+  },
+
   // Groups
   async getGroups() {
     const response = await api.get('/admin/groups')
@@ -532,6 +735,39 @@ export const announcementsService = {
   }
 }
 
+// Mandatory-read acknowledgements Service (type: 'news' | 'announcements')
+export const acknowledgementsService = {
+  // User - Get content waiting for the user's acknowledgement
+  async getPending() {
+    const response = await api.get('/acknowledgements/pending')
+    return response.data
+  },
+
+  // User - Acknowledge ("I have read and understood")
+  async acknowledge(type, id) {
+    const response = await api.post(`/${type}/${id}/acknowledge`)
+    return response.data
+  },
+
+  // Admin - Compliance report (status, group_id, page, page_size)
+  async getReport(type, id, params = {}) {
+    const response = await api.get(`/admin/${type}/${id}/acknowledgements`, { params })
+    return response.data
+  },
+
+  // Admin - Export compliance report as CSV
+  async exportReport(type, id, params = {}) {
+    const response = await api.get(`/admin/${type}/${id}/acknowledgements/export`, { params, responseType: 'blob' })
+    return response.data
+  },
+
+  // Admin - Remind users who have not acknowledged yet
+  async remind(type, id) {
+    const response = await api.post(`/admin/${type}/${id}/acknowledgements/remind`)
+    return response.data
+  }
+}
+
 // News Hub Service
 export const newsService = {
   // User - Get news with filters and pagination
@@ -540,6 +776,12 @@ export const newsService = {
     return response.data
   },
 
+  // User - Get personalized recommendations (with reasons)
+  async getForYou(params = {}) {
+    const response = await api.get('/news/for-you', { params })
+    return response.data
+  },
+
   // User - Get news by slug
   async getNewsBySlug(slug) {
     const response = await api.get(`/news/article/${slug}`)
@@ -612,6 +854,83 @@ export const newsService = {
     return response.data
   },
 
+  // Editor - Revision history
+  async getRevisions(id) {
+    const response = await api.get(`/editor/news/${id}/revisions`)
+    return response.data
+  },
+
+  async getRevision(id, revisionId) {
+    const response = await api.get(`/editor/news/${id}/revisions/${revisionId}`)
+    return response.data
+  },
+
+  async diffRevisions(id, params = {}) {
+    const response = await api.get(`/editor/news/${id}/revisions/diff`, { params })
+    return response.data
+  },
+
+  async restoreRevision(id, revisionId) {
+    const response = await api.post(`/editor/news/${id}/revisions/${revisionId}/restore`)
+    return response.data
+  },
+
+  // Editor - Editorial workflow
+  async getWorkflow(id) {
+    const response = await api.get(`/editor/news/${id}/workflow`)
+    return response.data
+  },
+
+  async getReviewers() {
+    const response = await api.get('/editor/news/reviewers')
+    return response.data
+  },
+
+  async getReviewQueue() {
+    const response = await api.get('/editor/news/review-queue')
+    return response.data
+  },
+
+  async submitNews(id, data = {}) {
+    const response = await api.post(`/editor/news/${id}/submit`, data)
+    return response.data
+  },
+
+  async assignReviewers(id, reviewerIds) {
+    const response = await api.put(`/editor/news/${id}/reviewers`, { reviewer_ids: reviewerIds })
+    return response.data
+  },
+
+  async approveNews(id, comment = '') {
+    const response = await api.post(`/editor/news/${id}/approve`, { comment })
+    return response.data
+  },
+
+  async requestChanges(id, comment) {
+    const response = await api.post(`/editor/news/${id}/request-changes`, { comment })
+    return response.data
+  },
+
+  async publishNews(id, publishAt = null) {
+    const response = await api.post(`/editor/news/${id}/publish`, publishAt ? { publish_at: publishAt } : {})
+    return response.data
+  },
+
+  async unscheduleNews(id) {
+    const response = await api.post(`/editor/news/${id}/unschedule`)
+    return response.data
+  },
+
+  async archiveNews(id) {
+    const response = await api.post(`/editor/news/${id}/archive`)
+    return response.data
+  },
+
+  async unarchiveNews(id) {
+    const response = await api.post(`/editor/news/${id}/unarchive`)
+    return response.data
+  },
+
   // Editor - Create tag
   async createTag(data) {
     const response = await api.post('/editor/news/tags', data)
@@ -908,6 +1227,26 @@ export const editorEventsService = {
   async deleteEvent(id) {
     const response = await api.delete(`/editor/events/${id}`)
     return response.data
+  },
+
+  async getRevisions(id) {
+    const response = await api.get(`/editor/events/${id}/revisions`)
+    return response.data
+  },
+
+  async getRevision(id, revisionId) {
+    const response = await api.get(`/editor/events/${id}/revisions/${revisionId}`)
+    return response.data
+  },
+
+  async diffRevisions(id, params = {}) {
+    const response = await api.get(`/editor/events/${id}/revisions/diff`, { params })
+    return response.data
+  },
+
+  async restoreRevision(id, revisionId) {
+    const response = await api.post(`/editor/events/${id}/revisions/${revisionId}/restore`)
+    return response.data
   }
 }
 
@@ -1348,6 +1687,7 @@ export const updateAdminMedia = (id, data) => api.put(`/admin/media/${id}`, data
 export const deleteAdminMedia = (id) => api.delete(`/admin/media/${id}`)
 
 // ==================== Search ====================
-export const globalSearch = (q, type = '') => api.get('/search', { params: { q, ...(type ? { type } : {}) } })
+export const globalSearch = (q, type = '', page = 1) => api.get('/search', { params: { q, ...(type ? { type } : {}), ...(page > 1 ? { page } : {}) } })
+export const searchSuggest = (q, params = {}) => api.get('/search/suggest', { params: { q, ...params } })
 
 export default api
\ No newline at end of file
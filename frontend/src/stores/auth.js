@@ -1,6 +1,6 @@
 import { defineStore } from 'pinia'
 import { ref, computed } from 'vue'
-import { authService } from '@/services/api'
+import { authService, adminService } from '@/services/api'
 
 export const useAuthStore = defineStore('auth', () => {
   // État
@@ -42,6 +42,7 @@ export const useAuthStore = defineStore('auth', () => {
     return hasAdminOfGroups || hasManagedGroupIds
   })
   const isEditor = computed(() => user.value?.role === 'editor')
+  const isImpersonating = computed(() => !!user.value?.impersonation)
   const canManageContent = computed(() => {
     const hasAdminOfGroups = user.value?.admin_of_groups && user.value.admin_of_groups.length > 0
     const hasManagedGroupIds = user.value?.managed_group_ids && user.value.managed_group_ids.length > 0
@@ -192,6 +193,48 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
+  // Usurpation d'identité : le jeton et le profil de l'administrateur sont conservés pour être restaurés
+  // (le refresh token reste celui de l'administrateur, aucun n'étant émis pour la session usurpée)
+  const startImpersonation = async (userId, reason, durationMinutes) => {
+    const response = await adminService.startImpersonation(userId, {
+      reason,
+      duration_minutes: durationMinutes
+    })
+
+    localStorage.setItem('airboard_impersonator_token', token.value)
+    localStorage.setItem('airboard_impersonator_user', JSON.stringify(user.value))
+
+    token.value = response.token
+    user.value = response.user
+    localStorage.setItem('airboard_token', response.token)
+    localStorage.setItem('airboard_user', JSON.stringify(response.user))
+    return response
+  }
+
+  const stopImpersonation = async () => {
+    try {
+      await authService.stopImpersonation()
+    } catch (error) {
+      // Session déjà expirée ou arrêtée : restaurer quand même le compte administrateur
+      console.log('Session d\'usurpation déjà terminée:', error.message)
+    }
+
+    const impersonatorToken = localStorage.getItem('airboard_impersonator_token')
+    const impersonatorUser = localStorage.getItem('airboard_impersonator_user')
+    localStorage.removeItem('airboard_impersonator_token')
+    localStorage.removeItem('airboard_impersonator_user')
+
+    if (impersonatorToken && impersonatorUser) {
+      token.value = impersonatorToken
+      user.value = JSON.parse(impersonatorUser)
+      localStorage.setItem('airboard_token', impersonatorToken)
+      localStorage.setItem('airboard_user', impersonatorUser)
+      await updateProfile()
+    } else {
+      logout()
+    }
+  }
+
   const saveProfile = async (profileData) => {
     try {
       isLoading.value = true
@@ -314,6 +357,7 @@ export const useAuthStore = defineStore('auth', () => {
     isAdmin,
     isGroupAdmin,
     isEditor,
+    isImpersonating,
     canManageContent,
     managedGroupIds,
     userInitials,
@@ -333,6 +377,8 @@ export const useAuthStore = defineStore('auth', () => {
     setRefreshToken,
     updateTokens,
     autoLoginSSO,
+    startImpersonation,
+    stopImpersonation,
     gamificationProfile,
     fetchGamificationProfile,
   }